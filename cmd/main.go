@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"log"
 	"os"
 	"os/signal"
@@ -13,9 +15,11 @@ import (
 	"github.com/latoulicious/HKTM/internal/config"
 	"github.com/latoulicious/HKTM/internal/handlers"
 	"github.com/latoulicious/HKTM/internal/presence"
+	"github.com/latoulicious/HKTM/internal/selftest"
+	"github.com/latoulicious/HKTM/internal/session"
 	"github.com/latoulicious/HKTM/pkg/common"
 	"github.com/latoulicious/HKTM/pkg/database"
-	"github.com/latoulicious/HKTM/pkg/uma"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
 )
 
 func main() {
@@ -34,6 +38,21 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// The cache and pipeline metrics share one SQLite file
+	databaseConfig := database.DefaultDatabaseConfig()
+	databasePath := databaseConfig.DatabasePath
+
+	// Verify external dependencies before touching Discord
+	deps := selftest.DefaultDependencies()
+	deps.Migrate = func(ctx context.Context) error {
+		return verifyMigrations(ctx, databasePath)
+	}
+	results := selftest.NewRunner(deps).SelfTest(context.Background())
+	selftest.LogResults(results)
+	if failures := selftest.CriticalFailures(results); len(failures) > 0 && cfg.SelfTestFailFast {
+		log.Fatalf("Startup self-test failed: %d critical check(s) failed", len(failures))
+	}
+
 	// Create a new Discord session using the provided token
 	dg, err := discordgo.New("Bot " + cfg.DiscordToken)
 	if err != nil {
@@ -46,22 +65,133 @@ func main() {
 	// Set the presence manager in the commands package
 	commands.SetPresenceManager(presenceManager)
 
+	// Apply configured embed colors and footer
+	commands.SetEmbedTheme(commands.EmbedThemeFromConfig(cfg))
+
 	// Initialize database for caching
-	db, err := database.NewDatabase("uma_cache.db")
+	db, err := database.NewDatabase(databasePath)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer db.Close()
 
+	// Log whenever an expired cache entry is served during an upstream outage
+	db.SetStaleHook(func(cacheType, key string, expiredFor time.Duration) {
+		log.Printf("served_stale: cache=%s key=%q expired_for=%v", cacheType, key, expiredFor.Round(time.Second))
+	})
+
 	// Start cache cleanup goroutine
 	db.StartCacheCleanup(1 * time.Hour)
 
+	// Keep query plans fresh as the cache grows
+	db.SetMaintenanceReindex(cfg.DBMaintenanceReindex)
+	db.StartMaintenance(cfg.DBMaintenanceInterval)
+
+	// Store pipeline metrics and events for !pipeline and !history; the bot
+	// still plays without them, so a failure here is not fatal
+	metricsRepo, err := db.OpenMetricsRepository(databaseConfig)
+	if err != nil {
+		log.Printf("Warning: pipeline metrics disabled: %v", err)
+	} else {
+		// Closed before the database so buffered metrics are flushed
+		defer metricsRepo.Close()
+		commands.SetMetricsRepository(metricsRepo)
+
+		// Record each track's pipeline as a session, closing any left open
+		// by a previous run
+		sessions := database.NewSessionManager(metricsRepo, databaseConfig)
+		if err := sessions.Start(); err != nil {
+			log.Printf("Warning: pipeline sessions disabled: %v", err)
+		} else {
+			defer sessions.Stop()
+			commands.SetSessionManager(sessions)
+		}
+	}
+
+	// Load and validate the pipeline config, with any per-source profiles
+	pipelineConfig := pipeline.DefaultPipelineConfig()
+	pipelineConfig.LoadFromEnvironment()
+	if path := os.Getenv("PIPELINE_PROFILES_FILE"); path != "" {
+		if err := pipelineConfig.LoadProfiles(path); err != nil {
+			log.Fatalf("Invalid pipeline config: %v", err)
+		}
+	}
+	if err := pipelineConfig.Validate(); err != nil {
+		log.Fatalf("Invalid pipeline config: %v", err)
+	}
+
+	// Tag the bot's logs and stored queue metrics with the static fields, so
+	// instances sharing a log sink or database can be told apart, and store
+	// the configured gauges smoothed as well as raw
+	if len(pipelineConfig.Logging.StaticFields) > 0 {
+		pipeline.NewStdLogAdapter(pipeline.NewStructuredLogger(pipelineConfig.Logging)).SetAsStdLogger()
+	}
+	if metricsRepo != nil {
+		var recorder pipeline.MetricRecorder = pipeline.NewStaticTagRecorder(
+			pipelineConfig.Logging.StaticFields,
+			database.NewPipelineRecorder(metricsRepo, "bot"),
+		)
+		if len(pipelineConfig.Metrics.GaugeSmoothing) > 0 {
+			recorder = pipeline.NewSmoothingRecorder(pipelineConfig.Metrics.GaugeSmoothing, recorder)
+		}
+		commands.SetQueueMetricRecorder(recorder)
+	}
+
+	// Restrict which hosts sources may be streamed from
+	commands.SetHostPolicy(pipeline.NewHostPolicy(
+		pipelineConfig.StreamAcquisition.AllowedHosts,
+		pipelineConfig.StreamAcquisition.BlockedHosts,
+	))
+
+	// Start ffmpeg with each source kind's reconnect and buffer settings, and
+	// the audio filters the config asks for
+	common.SetStreamConfig(pipelineConfig)
+
+	// Loudnorm levels each stream itself, so tracks needn't be probed for a gain
+	if pipelineConfig.Audio.NormalizeLoudness {
+		common.SetDefaultLoudnessAnalyzer(nil)
+	}
+
+	// Encode in the configured Opus application mode
+	if err := common.SetOpusApplication(pipelineConfig.Opus.Application); err != nil {
+		log.Fatalf("Invalid pipeline config: %v", err)
+	}
+	common.SetOpusOptions(common.OpusOptions{
+		Bitrate:         pipelineConfig.EncoderConfig().Bitrate,
+		FrameDuration:   pipelineConfig.Audio.OpusFrameDuration,
+		Passthrough:     pipelineConfig.Features.Passthrough,
+		AdaptiveBitrate: pipelineConfig.Opus.AdaptiveMode,
+		MinBitrate:      pipelineConfig.Opus.MinBitrate,
+		MaxBitrate:      pipelineConfig.Opus.MaxBitrate,
+		FEC:             pipelineConfig.Opus.FEC,
+		PacketLoss:      pipelineConfig.Opus.PacketLoss,
+		DTX:             pipelineConfig.Opus.DTX,
+	})
+	if pipelineConfig.Opus.AdaptiveMode && pipelineConfig.Features.Passthrough {
+		log.Println("Warning: opus adaptive mode doesn't apply with PIPELINE_FEATURE_PASSTHROUGH; ffmpeg's bitrate is fixed")
+	}
+	if (pipelineConfig.Opus.FEC || pipelineConfig.Opus.DTX) && !pipelineConfig.Features.Passthrough {
+		log.Println("Warning: opus fec/dtx are ignored without PIPELINE_FEATURE_PASSTHROUGH; the built-in encoder can't set them")
+	}
+
+	// Keep paused voice connections open with silence frames
+	if pipelineConfig.Discord.KeepAliveOnPause {
+		common.SetPauseKeepAlive(pipelineConfig.Discord.KeepAliveInterval)
+	}
+
 	// Initialize gametora client with config
 	commands.InitializeGametoraClient(cfg)
 
 	// Initialize UMA commands with database
 	commands.InitializeUmaCommands(db)
 
+	// Rate limit commands per user before they reach upstream APIs
+	cooldowns := handlers.NewCooldownManager(cfg.CommandCooldowns)
+	stopSweeper := make(chan struct{})
+	defer close(stopSweeper)
+	cooldowns.StartSweeper(10*time.Minute, stopSweeper)
+	handlers.SetCommandCooldowns(cooldowns)
+
 	// Register the message handler
 	dg.AddHandler(handlers.MessageHandler)
 
@@ -72,8 +202,24 @@ func main() {
 	dg.AddHandler(handlers.ReactionAddHandler)
 	dg.AddHandler(handlers.ReactionRemoveHandler)
 
-	// Open a websocket connection to Discord and begin listening.
-	err = dg.Open()
+	// Pause playback when everyone leaves the bot's voice channel
+	commands.StartAloneMonitor(dg, cfg.AloneGracePeriod)
+	dg.AddHandler(handlers.VoiceStateUpdateHandler)
+
+	// Skip tracks that fail to play, stopping only after several in a row
+	commands.SetMaxTrackFailures(cfg.MaxTrackFailures)
+
+	// Page long queues in !queue
+	commands.SetQueuePageSize(cfg.QueuePageSize)
+
+	// Bot-wide settings that !config show displays and servers can override
+	commands.SetBotConfig(cfg)
+
+	// Open a websocket connection to Discord and begin listening,
+	// retrying so a transient network hiccup at startup doesn't kill the bot.
+	retryConfig := session.DefaultRetryConfig()
+	retryConfig.MaxAttempts = cfg.SessionOpenAttempts
+	err = session.OpenWithRetry(dg.Open, retryConfig)
 	if err != nil {
 		log.Fatalf("Failed to open Discord session: %v", err)
 	}
@@ -99,8 +245,27 @@ func main() {
 	// Cleanly close down the Discord session.
 	dg.Close()
 
-	// Stop the build ID manager cron job
-	if gametoraClient := uma.GetGametoraClient(); gametoraClient != nil {
-		gametoraClient.StopBuildIDManager()
+	// Stop the build ID manager cron job and release the API clients
+	commands.CloseUmaClients()
+}
+
+// verifyMigrations applies pending migrations to the database at path,
+// proving the SQLite driver works, the file is writable and its schema can
+// be brought up to date with valid migration scripts
+func verifyMigrations(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	migrations, err := database.NewMigrationManagerWithConfig(db, &database.MigrationConfig{ValidateChecksum: true})
+	if err != nil {
+		return err
 	}
+	return migrations.Migrate()
 }
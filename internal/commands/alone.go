@@ -0,0 +1,242 @@
+package commands
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// timerStopper is the part of *time.Timer the alone monitor needs
+type timerStopper interface {
+	Stop() bool
+}
+
+// AloneMonitor pauses playback when the bot is left alone in its voice
+// channel, resumes it when someone rejoins, and disconnects once the grace
+// period runs out. Callbacks are invoked without the monitor's lock held.
+type AloneMonitor struct {
+	grace     time.Duration
+	afterFunc func(d time.Duration, f func()) timerStopper
+
+	onAlone   func(guildID string)
+	onRejoin  func(guildID string)
+	onTimeout func(guildID string)
+
+	mu     sync.Mutex
+	timers map[string]timerStopper // guilds currently alone, by pending disconnect
+}
+
+// NewAloneMonitor creates a monitor with the given grace period. A zero or
+// negative grace disables pausing, except in guilds that override it.
+func NewAloneMonitor(grace time.Duration, onAlone, onRejoin, onTimeout func(guildID string)) *AloneMonitor {
+	return &AloneMonitor{
+		grace: grace,
+		afterFunc: func(d time.Duration, f func()) timerStopper {
+			return time.AfterFunc(d, f)
+		},
+		onAlone:   onAlone,
+		onRejoin:  onRejoin,
+		onTimeout: onTimeout,
+		timers:    make(map[string]timerStopper),
+	}
+}
+
+// Update reports how many listeners share the bot's voice channel in a guild
+func (am *AloneMonitor) Update(guildID string, listeners int) {
+	grace := guildAloneGracePeriod(guildID, am.grace)
+	if grace <= 0 {
+		return
+	}
+
+	am.mu.Lock()
+	timer, alone := am.timers[guildID]
+
+	switch {
+	case listeners == 0 && !alone:
+		am.timers[guildID] = am.afterFunc(grace, func() { am.expire(guildID) })
+		am.mu.Unlock()
+		log.Printf("Left alone in voice for guild %s, pausing for %v", guildID, grace)
+		am.onAlone(guildID)
+
+	case listeners > 0 && alone:
+		timer.Stop()
+		delete(am.timers, guildID)
+		am.mu.Unlock()
+		log.Printf("Listener rejoined voice for guild %s, resuming", guildID)
+		am.onRejoin(guildID)
+
+	default:
+		am.mu.Unlock()
+	}
+}
+
+// Forget drops any pending disconnect for a guild, e.g. after playback stops
+func (am *AloneMonitor) Forget(guildID string) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if timer, ok := am.timers[guildID]; ok {
+		timer.Stop()
+		delete(am.timers, guildID)
+	}
+}
+
+// IsAlone reports whether a guild is waiting out its grace period
+func (am *AloneMonitor) IsAlone(guildID string) bool {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	_, alone := am.timers[guildID]
+	return alone
+}
+
+// expire disconnects a guild whose grace period ran out
+func (am *AloneMonitor) expire(guildID string) {
+	am.mu.Lock()
+	if _, alone := am.timers[guildID]; !alone {
+		// Someone rejoined just as the timer fired
+		am.mu.Unlock()
+		return
+	}
+	delete(am.timers, guildID)
+	am.mu.Unlock()
+
+	log.Printf("Nobody rejoined voice for guild %s, disconnecting", guildID)
+	am.onTimeout(guildID)
+}
+
+// countListeners returns the number of non-bot users in a voice channel
+func countListeners(guild *discordgo.Guild, channelID, botUserID string) int {
+	if guild == nil || channelID == "" {
+		return 0
+	}
+
+	listeners := 0
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != channelID || vs.UserID == botUserID {
+			continue
+		}
+		if vs.Member != nil && vs.Member.User != nil && vs.Member.User.Bot {
+			continue
+		}
+		listeners++
+	}
+	return listeners
+}
+
+var (
+	aloneMonitor   *AloneMonitor
+	aloneMonitorMu sync.RWMutex
+
+	// Guilds whose playback was paused by the alone monitor rather than a user
+	pausedWhileAlone   = make(map[string]bool)
+	pausedWhileAloneMu sync.Mutex
+)
+
+// StartAloneMonitor enables pausing when the bot is left alone, waiting grace
+// for a listener to rejoin before disconnecting. Zero disables it.
+func StartAloneMonitor(s *discordgo.Session, grace time.Duration) {
+	aloneMonitorMu.Lock()
+	defer aloneMonitorMu.Unlock()
+	aloneMonitor = NewAloneMonitor(grace, pauseWhileAlone, resumeAfterAlone, func(guildID string) {
+		disconnectWhileAlone(s, guildID)
+	})
+}
+
+// HandleVoiceStateUpdate re-checks the bot's voice channel after any voice
+// state change in the guild
+func HandleVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	aloneMonitorMu.RLock()
+	monitor := aloneMonitor
+	aloneMonitorMu.RUnlock()
+	if monitor == nil || v.VoiceState == nil {
+		return
+	}
+
+	queue := getQueue(v.GuildID)
+	if queue == nil || !queue.IsPlaying() {
+		monitor.Forget(v.GuildID)
+		return
+	}
+
+	vc := queue.GetVoiceConnection()
+	if vc == nil || s.State == nil || s.State.User == nil {
+		return
+	}
+
+	guild, err := s.State.Guild(v.GuildID)
+	if err != nil {
+		return
+	}
+
+	monitor.Update(v.GuildID, countListeners(guild, vc.ChannelID, s.State.User.ID))
+}
+
+// pauseWhileAlone pauses playback unless a user had already paused it
+func pauseWhileAlone(guildID string) {
+	queue := getQueue(guildID)
+	if queue == nil {
+		return
+	}
+
+	pipeline := queue.GetPipeline()
+	if pipeline == nil || pipeline.IsPaused() {
+		return
+	}
+
+	if err := pipeline.Pause(); err != nil {
+		log.Printf("Failed to pause while alone in guild %s: %v", guildID, err)
+		return
+	}
+
+	pausedWhileAloneMu.Lock()
+	pausedWhileAlone[guildID] = true
+	pausedWhileAloneMu.Unlock()
+}
+
+// resumeAfterAlone resumes playback only if the alone monitor paused it
+func resumeAfterAlone(guildID string) {
+	pausedWhileAloneMu.Lock()
+	paused := pausedWhileAlone[guildID]
+	delete(pausedWhileAlone, guildID)
+	pausedWhileAloneMu.Unlock()
+
+	if !paused {
+		return
+	}
+
+	queue := getQueue(guildID)
+	if queue == nil {
+		return
+	}
+	if pipeline := queue.GetPipeline(); pipeline != nil && pipeline.IsPaused() {
+		if err := pipeline.Resume(); err != nil {
+			log.Printf("Failed to resume after listener rejoined in guild %s: %v", guildID, err)
+		}
+	}
+}
+
+// disconnectWhileAlone stops playback and leaves voice once the grace period ends
+func disconnectWhileAlone(s *discordgo.Session, guildID string) {
+	pausedWhileAloneMu.Lock()
+	delete(pausedWhileAlone, guildID)
+	pausedWhileAloneMu.Unlock()
+
+	queue := getQueue(guildID)
+	if queue == nil {
+		return
+	}
+
+	queue.StopAndCleanupWithReason(common.OutcomeIdleTimeout)
+	queue.ClearHistory()
+
+	if presenceManager != nil {
+		presenceManager.ClearMusicPresence()
+	}
+
+	if channelID := noticeChannelID(s, guildID); channelID != "" {
+		sendIdleDisconnectEmbed(s, channelID, "Everyone left the voice channel. Disconnected to preserve resources.")
+	}
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTimer records the scheduled callback so tests can fire it by hand
+type fakeTimer struct {
+	fire    func()
+	stopped bool
+}
+
+func (ft *fakeTimer) Stop() bool {
+	ft.stopped = true
+	return true
+}
+
+type aloneRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *aloneRecorder) record(event string) func(string) {
+	return func(guildID string) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, event+":"+guildID)
+	}
+}
+
+func newTestAloneMonitor(grace time.Duration) (*AloneMonitor, *aloneRecorder, *[]*fakeTimer) {
+	recorder := &aloneRecorder{}
+	monitor := NewAloneMonitor(grace, recorder.record("alone"), recorder.record("rejoin"), recorder.record("timeout"))
+
+	var timers []*fakeTimer
+	monitor.afterFunc = func(d time.Duration, f func()) timerStopper {
+		timer := &fakeTimer{fire: f}
+		timers = append(timers, timer)
+		return timer
+	}
+	return monitor, recorder, &timers
+}
+
+func TestAloneMonitorPausesThenResumesOnRejoin(t *testing.T) {
+	monitor, recorder, timers := newTestAloneMonitor(time.Minute)
+
+	monitor.Update("guild-1", 2)
+	assert.Empty(t, recorder.events, "listeners present, nothing to do")
+
+	monitor.Update("guild-1", 0)
+	assert.Equal(t, []string{"alone:guild-1"}, recorder.events)
+	assert.True(t, monitor.IsAlone("guild-1"))
+	require.Len(t, *timers, 1)
+
+	// Further updates while alone do not restart the grace period
+	monitor.Update("guild-1", 0)
+	assert.Len(t, *timers, 1)
+
+	monitor.Update("guild-1", 1)
+	assert.Equal(t, []string{"alone:guild-1", "rejoin:guild-1"}, recorder.events)
+	assert.False(t, monitor.IsAlone("guild-1"))
+	assert.True(t, (*timers)[0].stopped)
+
+	// A timer that fires after the rejoin is ignored
+	(*timers)[0].fire()
+	assert.Equal(t, []string{"alone:guild-1", "rejoin:guild-1"}, recorder.events)
+}
+
+func TestAloneMonitorDisconnectsAfterGrace(t *testing.T) {
+	monitor, recorder, timers := newTestAloneMonitor(time.Minute)
+
+	monitor.Update("guild-1", 0)
+	require.Len(t, *timers, 1)
+
+	(*timers)[0].fire()
+	assert.Equal(t, []string{"alone:guild-1", "timeout:guild-1"}, recorder.events)
+	assert.False(t, monitor.IsAlone("guild-1"))
+
+	// Someone joining after the disconnect is not a rejoin
+	monitor.Update("guild-1", 1)
+	assert.Equal(t, []string{"alone:guild-1", "timeout:guild-1"}, recorder.events)
+}
+
+func TestAloneMonitorDisabled(t *testing.T) {
+	monitor, recorder, timers := newTestAloneMonitor(0)
+
+	monitor.Update("guild-1", 0)
+	assert.Empty(t, recorder.events)
+	assert.Empty(t, *timers)
+}
+
+func TestCountListeners(t *testing.T) {
+	guild := &discordgo.Guild{
+		VoiceStates: []*discordgo.VoiceState{
+			{UserID: "bot", ChannelID: "voice-1"},
+			{UserID: "alice", ChannelID: "voice-1"},
+			{UserID: "music-bot", ChannelID: "voice-1", Member: &discordgo.Member{User: &discordgo.User{ID: "music-bot", Bot: true}}},
+			{UserID: "bob", ChannelID: "voice-2"},
+		},
+	}
+
+	assert.Equal(t, 1, countListeners(guild, "voice-1", "bot"))
+	assert.Equal(t, 1, countListeners(guild, "voice-2", "bot"))
+	assert.Equal(t, 0, countListeners(guild, "voice-3", "bot"))
+	assert.Equal(t, 0, countListeners(nil, "voice-1", "bot"))
+}
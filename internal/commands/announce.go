@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// announceChannelKey is the guild setting naming the channel that playback
+// notifications are sent to instead of the command channel
+const announceChannelKey = "announce_channel"
+
+// announceChannelID returns where a guild's playback notifications go: its
+// announce channel if one is set, else the channel the command came from
+func announceChannelID(guildID, commandChannelID string) string {
+	if channelID, ok := guildOverride(guildID, announceChannelKey); ok {
+		return channelID
+	}
+	return commandChannelID
+}
+
+// parseAnnounceChannel accepts a channel mention such as <#123> or a bare
+// channel ID and returns the ID
+func parseAnnounceChannel(raw string) (string, error) {
+	id := strings.TrimSpace(raw)
+	if strings.HasPrefix(id, "<#") && strings.HasSuffix(id, ">") {
+		id = id[2 : len(id)-1]
+	}
+	if id == "" {
+		return "", errors.New("must be a channel mention such as #music")
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return "", fmt.Errorf("%q is not a channel mention", raw)
+		}
+	}
+	return id, nil
+}
+
+// checkAnnounceChannel verifies that a channel is a text channel in the
+// guild, so notifications can't be sent somewhere the server can't see
+func checkAnnounceChannel(channels channelLookup, guildID, channelID string) error {
+	if channels == nil {
+		return errors.New("channels can't be looked up")
+	}
+	channel, err := channels.Channel(channelID)
+	if err != nil || channel.GuildID != guildID || channel.Type != discordgo.ChannelTypeGuildText {
+		return errors.New("not a text channel in this server")
+	}
+	return nil
+}
+
+// SetAnnounceCommand handles `!setannounce <#channel>`, which sends this
+// server's playback notifications to a dedicated channel (server admins and
+// the bot owner only). `!setannounce off` sends them to the command channel
+// again.
+func SetAnnounceCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if m.GuildID == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌ This command can only be used in a server.")
+		return
+	}
+	if !isOwner(m) && !hasAdminPermissions(s, m.GuildID, m.Author.ID) {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "You need Administrator permission to change server settings.", EmbedError)
+		return
+	}
+	if len(args) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!setannounce <#channel>` or `!setannounce off`", EmbedError)
+		return
+	}
+
+	if strings.EqualFold(args[0], "off") || strings.EqualFold(args[0], "default") {
+		setGuildOverride(s, m.GuildID, announceChannelKey, "default")
+		sendEmbedMessage(s, m.ChannelID, "⚙️ Setting Updated", "Playback notifications will be sent to the channel each command was used in.", EmbedSuccess)
+		return
+	}
+
+	channelID, err := setGuildOverride(s, m.GuildID, announceChannelKey, args[0])
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+		return
+	}
+	sendEmbedMessage(s, m.ChannelID, "⚙️ Setting Updated", fmt.Sprintf("Playback notifications will be sent to <#%s>.", channelID), EmbedSuccess)
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChannels is a channelLookup over a fixed set of channels
+type fakeChannels map[string]*discordgo.Channel
+
+func (f fakeChannels) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	if channel, ok := f[channelID]; ok {
+		return channel, nil
+	}
+	return nil, errors.New("unknown channel")
+}
+
+// testChannels has a text channel in guild-1 and guild-2 and a voice
+// channel in guild-1
+var testChannels = fakeChannels{
+	"1234567890": {ID: "1234567890", GuildID: "guild-1", Type: discordgo.ChannelTypeGuildText},
+	"2234567890": {ID: "2234567890", GuildID: "guild-2", Type: discordgo.ChannelTypeGuildText},
+	"3234567890": {ID: "3234567890", GuildID: "guild-1", Type: discordgo.ChannelTypeGuildVoice},
+}
+
+func TestAnnounceChannelID_RoutesToConfiguredChannel(t *testing.T) {
+	resetGuildConfig(t)
+
+	// Without an announce channel notifications follow the command
+	assert.Equal(t, "command-channel", announceChannelID("guild-1", "command-channel"))
+
+	value, err := setGuildOverride(testChannels, "guild-1", announceChannelKey, "<#1234567890>")
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", value)
+
+	assert.Equal(t, "1234567890", announceChannelID("guild-1", "command-channel"))
+	assert.Equal(t, "command-channel", announceChannelID("guild-2", "command-channel"))
+
+	// Clearing it falls back to the command channel again
+	_, err = setGuildOverride(testChannels, "guild-1", announceChannelKey, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "command-channel", announceChannelID("guild-1", "command-channel"))
+}
+
+func TestParseAnnounceChannel(t *testing.T) {
+	for raw, want := range map[string]string{
+		"<#1234567890>": "1234567890",
+		" 1234567890 ":  "1234567890",
+	} {
+		got, err := parseAnnounceChannel(raw)
+		require.NoError(t, err, "value %q", raw)
+		assert.Equal(t, want, got)
+	}
+
+	for _, raw := range []string{"", "<#>", "#music", "<@1234567890>", "music"} {
+		_, err := parseAnnounceChannel(raw)
+		assert.Error(t, err, "value %q", raw)
+	}
+}
+
+func TestSetGuildOverride_AnnounceChannelMustBeGuildTextChannel(t *testing.T) {
+	resetGuildConfig(t)
+
+	for name, raw := range map[string]string{
+		"other guild":   "<#2234567890>",
+		"voice channel": "<#3234567890>",
+		"unknown":       "<#4234567890>",
+	} {
+		_, err := setGuildOverride(testChannels, "guild-1", announceChannelKey, raw)
+		assert.Error(t, err, name)
+	}
+	_, err := setGuildOverride(nil, "guild-1", announceChannelKey, "<#1234567890>")
+	assert.Error(t, err, "without a channel lookup")
+
+	_, ok := guildOverride("guild-1", announceChannelKey)
+	assert.False(t, ok)
+
+	// Imports go through the same check
+	_, err = importGuildSettings(testChannels, "guild-1", `{"version": 1, "settings": {"announce_channel": "2234567890"}}`)
+	assert.Error(t, err)
+}
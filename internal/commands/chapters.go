@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// ChaptersCommand lists the chapters of the current track, marking the one
+// playing now
+func ChaptersCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	guildID := m.GuildID
+
+	// Update activity for idle monitoring
+	updateActivity(guildID)
+
+	queue := getQueue(guildID)
+	if queue == nil || !queue.IsPlaying() {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing is currently playing.", EmbedError)
+		return
+	}
+
+	current := queue.Current()
+	if current == nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", EmbedError)
+		return
+	}
+
+	if len(current.Chapters) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "📑 Chapters", fmt.Sprintf("**%s** has no chapters.", current.Title), EmbedNeutral)
+		return
+	}
+
+	playing := -1
+	if pipeline := queue.GetPipeline(); pipeline != nil {
+		playing = common.ChapterAt(current.Chapters, pipeline.Position())
+	}
+
+	sendEmbedMessage(s, m.ChannelID, "📑 Chapters", fmt.Sprintf("**%s**\n\n%s\n\nUse `!seek chapter <n>` to jump to one.", current.Title, formatChapterList(current.Chapters, playing)), EmbedNeutral)
+}
+
+// formatChapterList renders chapters one per line, 1-based, highlighting the
+// one at index playing (-1 for none)
+func formatChapterList(chapters []common.Chapter, playing int) string {
+	lines := make([]string, 0, len(chapters))
+	for i, chapter := range chapters {
+		line := fmt.Sprintf("%d. `%s` %s", i+1, formatDuration(chapter.Start), chapter.Title)
+		if i == playing {
+			line = fmt.Sprintf("▶️ **%s**", line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// chapterByNumber returns the chapter for a 1-based !seek chapter argument
+func chapterByNumber(chapters []common.Chapter, arg string) (common.Chapter, error) {
+	if len(chapters) == 0 {
+		return common.Chapter{}, fmt.Errorf("this track has no chapters")
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(arg))
+	if err != nil {
+		return common.Chapter{}, fmt.Errorf("invalid chapter number %q", arg)
+	}
+	if n < 1 || n > len(chapters) {
+		return common.Chapter{}, fmt.Errorf("chapter must be between 1 and %d", len(chapters))
+	}
+
+	return chapters[n-1], nil
+}
+
+// seekToChapter handles `!seek chapter <n>`
+func seekToChapter(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!seek chapter <n>` (see `!chapters`)", EmbedError)
+		return
+	}
+
+	queue := getQueue(m.GuildID)
+	if queue == nil || !queue.IsPlaying() {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing is currently playing.", EmbedError)
+		return
+	}
+
+	pipeline := queue.GetPipeline()
+	current := queue.Current()
+	if pipeline == nil || current == nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", EmbedError)
+		return
+	}
+
+	// Only listeners in the bot's voice channel may seek
+	if !requireSameVoiceChannel(s, m, queue) {
+		return
+	}
+
+	chapter, err := chapterByNumber(current.Chapters, args[0])
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Cannot seek: %v", err), EmbedError)
+		return
+	}
+
+	position := chapter.Start
+	if err := pipeline.Seek(position); err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Could not seek: %v", err), EmbedError)
+		return
+	}
+
+	sendEmbedMessage(s, m.ChannelID, "⏩ Seeked", fmt.Sprintf("Jumped to **%s** (%s) in **%s**.", chapter.Title, formatDuration(position), current.Title), EmbedSuccess)
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChapterByNumber(t *testing.T) {
+	chapters := []common.Chapter{
+		{Title: "Intro", Start: 0, End: 30 * time.Second},
+		{Title: "Verse", Start: 30 * time.Second, End: 90 * time.Second},
+		{Title: "Outro", Start: 90 * time.Second, End: 2 * time.Minute},
+	}
+
+	chapter, err := chapterByNumber(chapters, "2")
+	require.NoError(t, err)
+	assert.Equal(t, "Verse", chapter.Title)
+	assert.Equal(t, 30*time.Second, chapter.Start)
+
+	chapter, err = chapterByNumber(chapters, " 3 ")
+	require.NoError(t, err)
+	assert.Equal(t, "Outro", chapter.Title)
+
+	for _, arg := range []string{"0", "4", "-1", "abc", ""} {
+		_, err := chapterByNumber(chapters, arg)
+		assert.Error(t, err, "arg %q", arg)
+	}
+
+	// Tracks without chapters are rejected whatever the number
+	_, err = chapterByNumber(nil, "1")
+	assert.EqualError(t, err, "this track has no chapters")
+}
+
+func TestFormatChapterList(t *testing.T) {
+	chapters := []common.Chapter{
+		{Title: "Intro", Start: 0},
+		{Title: "Verse", Start: 75 * time.Second},
+	}
+
+	list := formatChapterList(chapters, 1)
+	assert.Contains(t, list, "1. `0s` Intro")
+	assert.Contains(t, list, "▶️ **2. `1m 15s` Verse**")
+}
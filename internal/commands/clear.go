@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/latoulicious/HKTM/pkg/common"
@@ -19,13 +18,13 @@ func ClearCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []strin
 	queue := getQueue(guildID)
 
 	if queue == nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", EmbedError)
 		return
 	}
 
 	// Check if queue is empty
 	if queue.Size() == 0 && queue.Current() == nil {
-		sendEmbedMessage(s, m.ChannelID, "📭 Queue Already Empty", "The queue is already empty.", 0x808080)
+		sendEmbedMessage(s, m.ChannelID, "📭 Queue Already Empty", "The queue is already empty.", EmbedNeutral)
 		return
 	}
 
@@ -39,7 +38,7 @@ func ClearCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []strin
 			return
 		case "cancel":
 			// User cancelled the clear
-			sendEmbedMessage(s, m.ChannelID, "❌ Cancelled", "Queue clear operation cancelled.", 0x808080)
+			sendEmbedMessage(s, m.ChannelID, "❌ Cancelled", "Queue clear operation cancelled.", EmbedNeutral)
 			return
 		}
 	}
@@ -49,26 +48,19 @@ func ClearCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []strin
 
 	// If user is not admin and there are multiple songs, ask for confirmation
 	if !hasAdmin && queue.Size() > 3 {
-		embed := &discordgo.MessageEmbed{
-			Title:     "⚠️ Confirm Queue Clear",
-			Color:     0xffa500, // Orange
-			Timestamp: time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae",
+		description := "You're about to clear the entire queue with multiple songs. Are you sure?\n\n" +
+			"Reply with `!clear confirm` to proceed or `!clear cancel` to cancel."
+		embed := theme().NewEmbed(EmbedWarning, "⚠️ Confirm Queue Clear", description, "")
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   "Queue Size",
+				Value:  fmt.Sprintf("%d songs", queue.Size()),
+				Inline: true,
 			},
-			Description: "You're about to clear the entire queue with multiple songs. Are you sure?\n\n" +
-				"Reply with `!clear confirm` to proceed or `!clear cancel` to cancel.",
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "Queue Size",
-					Value:  fmt.Sprintf("%d songs", queue.Size()),
-					Inline: true,
-				},
-				{
-					Name:   "Requested By",
-					Value:  m.Author.Username,
-					Inline: true,
-				},
+			{
+				Name:   "Requested By",
+				Value:  m.Author.Username,
+				Inline: true,
 			},
 		}
 		s.ChannelMessageSendEmbed(m.ChannelID, embed)
@@ -86,27 +78,20 @@ func clearQueueInternal(s *discordgo.Session, m *discordgo.MessageCreate, queue
 
 	// Clear the queue
 	queue.Clear()
+	queue.ClearFailedTracks()
 
 	// Send confirmation embed
-	embed := &discordgo.MessageEmbed{
-		Title:     "🗑️ Queue Cleared",
-		Color:     0x00ff00, // Green
-		Timestamp: time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+	embed := theme().NewEmbed(EmbedSuccess, "🗑️ Queue Cleared", "The queue has been successfully cleared.", "")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Songs Removed",
+			Value:  fmt.Sprintf("%d songs", queueSize),
+			Inline: true,
 		},
-		Description: "The queue has been successfully cleared.",
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Songs Removed",
-				Value:  fmt.Sprintf("%d songs", queueSize),
-				Inline: true,
-			},
-			{
-				Name:   "Cleared By",
-				Value:  m.Author.Username,
-				Inline: true,
-			},
+		{
+			Name:   "Cleared By",
+			Value:  m.Author.Username,
+			Inline: true,
 		},
 	}
 	s.ChannelMessageSendEmbed(m.ChannelID, embed)
@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// ConfigCommand shows the server's effective configuration, and lets server
+// admins override settings for their server. The bot owner also gets the
+// full pipeline configuration.
+func ConfigCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if m.GuildID == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌ This command can only be used in a server.")
+		return
+	}
+
+	if len(args) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "❌ Usage: `!config show` or `!config set <key> <value>`")
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "show":
+		showGuildConfig(s, m)
+		if isOwner(m) {
+			showConfig(s, m)
+		}
+	case "set":
+		setGuildConfig(s, m, args[1:])
+	default:
+		s.ChannelMessageSend(m.ChannelID, "❌ Usage: `!config show` or `!config set <key> <value>`")
+	}
+}
+
+// showConfig sends the effective pipeline configuration with secrets redacted
+func showConfig(s *discordgo.Session, m *discordgo.MessageCreate) {
+	cfg := pipeline.DefaultPipelineConfig()
+	cfg.LoadFromEnvironment()
+	if path := os.Getenv("PIPELINE_PROFILES_FILE"); path != "" {
+		// A bad file already stopped startup, so this only fails if it changed since
+		cfg.LoadProfiles(path)
+	}
+
+	// The dump is too long for a message, so attach it as a file
+	_, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content: "⚙️ Effective pipeline configuration (secrets redacted):",
+		Files: []*discordgo.File{
+			{
+				Name:        "pipeline-config.json",
+				ContentType: "application/json",
+				Reader:      bytes.NewReader([]byte(cfg.String())),
+			},
+		},
+	})
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ Failed to send configuration: %v", err))
+	}
+}
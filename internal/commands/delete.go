@@ -13,13 +13,13 @@ func DeleteCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	// Check if user has manage messages permission
 	hasPermission := hasManageMessagesPermission(s, m.GuildID, m.Author.ID)
 	if !hasPermission {
-		sendEmbedMessage(s, m.ChannelID, "❌ Permission Denied", "You need 'Manage Messages' permission to use this command.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Permission Denied", "You need 'Manage Messages' permission to use this command.", EmbedError)
 		return
 	}
 
 	// Check if number of messages is provided
 	if len(args) == 0 {
-		sendEmbedMessage(s, m.ChannelID, "❌ Invalid Usage", "Usage: `!delete <number>` - Delete the specified number of recent messages.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Invalid Usage", "Usage: `!delete <number>` - Delete the specified number of recent messages.", EmbedError)
 		return
 	}
 
@@ -27,7 +27,7 @@ func DeleteCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	numStr := args[0]
 	num, err := strconv.Atoi(numStr)
 	if err != nil || num <= 0 {
-		sendEmbedMessage(s, m.ChannelID, "❌ Invalid Number", "Please provide a valid positive number of messages to delete.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Invalid Number", "Please provide a valid positive number of messages to delete.", EmbedError)
 		return
 	}
 
@@ -39,7 +39,7 @@ func DeleteCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	// Get recent messages from the channel
 	messages, err := s.ChannelMessages(m.ChannelID, num+1, "", "", "") // +1 to include the command message
 	if err != nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to fetch messages from the channel.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to fetch messages from the channel.", EmbedError)
 		return
 	}
 
@@ -71,7 +71,7 @@ func DeleteCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	if len(messageIDs) > 0 {
 		err = s.ChannelMessagesBulkDelete(m.ChannelID, messageIDs)
 		if err != nil {
-			sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to delete messages. Make sure I have 'Manage Messages' permission.", 0xff0000)
+			sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to delete messages. Make sure I have 'Manage Messages' permission.", EmbedError)
 			return
 		}
 	}
@@ -80,25 +80,17 @@ func DeleteCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	s.ChannelMessageDelete(m.ChannelID, m.ID)
 
 	// Send confirmation message (will be deleted after 5 seconds)
-	embed := &discordgo.MessageEmbed{
-		Title:     "🗑️ Messages Deleted",
-		Color:     0x00ff00, // Green
-		Timestamp: time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+	embed := theme().NewEmbed(EmbedSuccess, "🗑️ Messages Deleted", "Messages have been successfully deleted.", "")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Messages Deleted",
+			Value:  fmt.Sprintf("%d messages", deletedCount),
+			Inline: true,
 		},
-		Description: "Messages have been successfully deleted.",
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Messages Deleted",
-				Value:  fmt.Sprintf("%d messages", deletedCount),
-				Inline: true,
-			},
-			{
-				Name:   "Deleted By",
-				Value:  m.Author.Username,
-				Inline: true,
-			},
+		{
+			Name:   "Deleted By",
+			Value:  m.Author.Username,
+			Inline: true,
 		},
 	}
 
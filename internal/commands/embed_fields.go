@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord's limits on embeds, in characters. An embed over any of them is
+// rejected and the message never appears.
+const (
+	maxEmbedTitleLength       = 256
+	maxEmbedDescriptionLength = 4096
+	maxEmbedFieldNameLength   = 256
+	maxEmbedFieldLength       = 1024
+	maxEmbedFields            = 25
+
+	// maxEmbedTotalLength caps the title, description, field names and
+	// values, footer and author name together
+	maxEmbedTotalLength = 6000
+)
+
+// maxEmbedFieldParts caps how many fields one long value is split across,
+// so a single list can't crowd out the rest of an embed
+const maxEmbedFieldParts = 4
+
+// splitEmbedField returns the fields needed to show value under name,
+// splitting it at line breaks into continuation fields when it is longer
+// than a field allows. Past maxEmbedFieldParts the rest is cut and marked
+// with an ellipsis.
+func splitEmbedField(name, value string, inline bool) []*discordgo.MessageEmbedField {
+	parts := splitFieldValue(value, maxEmbedFieldLength)
+	if len(parts) > maxEmbedFieldParts {
+		parts = parts[:maxEmbedFieldParts]
+		last := maxEmbedFieldParts - 1
+		parts[last] = truncateEventText(parts[last], maxEmbedFieldLength-2) + "\n…"
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, 0, len(parts))
+	for i, part := range parts {
+		fieldName := name
+		if i > 0 {
+			fieldName = name + " (cont.)"
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   truncateEventText(fieldName, maxEmbedFieldNameLength),
+			Value:  part,
+			Inline: inline,
+		})
+	}
+	return fields
+}
+
+// splitFieldValue breaks value into pieces of at most limit runes, cutting
+// between lines. A single line longer than limit is truncated.
+func splitFieldValue(value string, limit int) []string {
+	if utf8.RuneCountInString(value) <= limit {
+		return []string{value}
+	}
+
+	var parts []string
+	var current strings.Builder
+	length := 0
+	flush := func() {
+		if part := strings.TrimRight(current.String(), "\n"); part != "" {
+			parts = append(parts, part)
+		}
+		current.Reset()
+		length = 0
+	}
+
+	for _, line := range strings.SplitAfter(value, "\n") {
+		lineLength := utf8.RuneCountInString(line)
+		if lineLength > limit {
+			line = truncateEventText(strings.TrimRight(line, "\n"), limit)
+			lineLength = limit
+		}
+		if length+lineLength > limit {
+			flush()
+		}
+		current.WriteString(line)
+		length += lineLength
+	}
+	flush()
+
+	if len(parts) == 0 {
+		return []string{truncateEventText(value, limit)}
+	}
+	return parts
+}
+
+// fitEmbed truncates an embed's title, description and fields to Discord's
+// limits, and drops fields past the maximum, so the send can't be rejected
+// for size. While the whole embed is still over the total limit, trailing
+// fields are dropped, then the description is cut. It returns the embed for
+// chaining.
+func fitEmbed(embed *discordgo.MessageEmbed) *discordgo.MessageEmbed {
+	embed.Title = truncateEventText(embed.Title, maxEmbedTitleLength)
+	embed.Description = truncateEventText(embed.Description, maxEmbedDescriptionLength)
+
+	if len(embed.Fields) > maxEmbedFields {
+		embed.Fields = embed.Fields[:maxEmbedFields]
+	}
+	for _, field := range embed.Fields {
+		field.Name = truncateEventText(field.Name, maxEmbedFieldNameLength)
+		field.Value = truncateEventText(field.Value, maxEmbedFieldLength)
+	}
+
+	total := embedLength(embed)
+	for total > maxEmbedTotalLength && len(embed.Fields) > 0 {
+		last := embed.Fields[len(embed.Fields)-1]
+		total -= utf8.RuneCountInString(last.Name) + utf8.RuneCountInString(last.Value)
+		embed.Fields = embed.Fields[:len(embed.Fields)-1]
+	}
+	if over := total - maxEmbedTotalLength; over > 0 {
+		room := utf8.RuneCountInString(embed.Description) - over
+		if room < 1 {
+			room = 1
+		}
+		embed.Description = truncateEventText(embed.Description, room)
+	}
+	return embed
+}
+
+// embedLength counts the characters of an embed that Discord's total limit
+// applies to
+func embedLength(embed *discordgo.MessageEmbed) int {
+	length := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		length += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	if embed.Footer != nil {
+		length += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		length += utf8.RuneCountInString(embed.Author.Name)
+	}
+	return length
+}
@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/uma"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertFieldsFit checks every field against Discord's limits
+func assertFieldsFit(t *testing.T, embed *discordgo.MessageEmbed) {
+	t.Helper()
+	assert.LessOrEqual(t, len(embed.Fields), maxEmbedFields)
+	for _, field := range embed.Fields {
+		assert.LessOrEqual(t, utf8.RuneCountInString(field.Name), maxEmbedFieldNameLength, field.Name)
+		assert.LessOrEqual(t, utf8.RuneCountInString(field.Value), maxEmbedFieldLength, field.Name)
+		assert.NotEmpty(t, field.Value, field.Name)
+	}
+}
+
+func TestSplitEmbedField_ShortValueIsOneField(t *testing.T) {
+	fields := splitEmbedField("Versions", "one\ntwo", true)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "Versions", fields[0].Name)
+	assert.Equal(t, "one\ntwo", fields[0].Value)
+	assert.True(t, fields[0].Inline)
+}
+
+func TestSplitEmbedField_SplitsAtLines(t *testing.T) {
+	var lines []string
+	for i := 0; i < 60; i++ {
+		lines = append(lines, fmt.Sprintf("line %02d %s", i, strings.Repeat("x", 20)))
+	}
+	value := strings.Join(lines, "\n")
+
+	fields := splitEmbedField("Versions", value, false)
+	require.Greater(t, len(fields), 1)
+	assert.Equal(t, "Versions", fields[0].Name)
+	assert.Equal(t, "Versions (cont.)", fields[1].Name)
+
+	// Every line survives whole, in order
+	var joined []string
+	for _, field := range fields {
+		assert.LessOrEqual(t, utf8.RuneCountInString(field.Value), maxEmbedFieldLength)
+		joined = append(joined, field.Value)
+	}
+	assert.Equal(t, value, strings.Join(joined, "\n"))
+}
+
+func TestSplitEmbedField_CapsParts(t *testing.T) {
+	value := strings.Repeat(strings.Repeat("y", 100)+"\n", 100)
+
+	fields := splitEmbedField("Versions", value, false)
+	require.Len(t, fields, maxEmbedFieldParts)
+	last := fields[len(fields)-1].Value
+	assert.True(t, strings.HasSuffix(last, "\n…"))
+	assert.LessOrEqual(t, utf8.RuneCountInString(last), maxEmbedFieldLength)
+}
+
+func TestSplitEmbedField_TruncatesLongLine(t *testing.T) {
+	fields := splitEmbedField("Error", strings.Repeat("é", 3000), false)
+	require.Len(t, fields, 1)
+	assert.Equal(t, maxEmbedFieldLength, utf8.RuneCountInString(fields[0].Value))
+	assert.True(t, strings.HasSuffix(fields[0].Value, "…"))
+}
+
+func TestCreateMultiVersionSupportCardEmbed_ManyVersionsFit(t *testing.T) {
+	var cards []uma.SupportCard
+	for i := 0; i < 40; i++ {
+		cards = append(cards, uma.SupportCard{
+			ID:           30000 + i,
+			TitleEn:      fmt.Sprintf("[Version %d] Kitasan Black", i),
+			Title:        fmt.Sprintf("［バージョン%d］キタサンブラック", i),
+			RarityString: "SSR",
+			Type:         "speed",
+			Gametora:     fmt.Sprintf("%d-kitasan-black", 30000+i),
+		})
+	}
+
+	embed := createMultiVersionSupportCardEmbed(cards)
+	assertFieldsFit(t, embed)
+
+	var versions int
+	for _, field := range embed.Fields {
+		if strings.HasPrefix(field.Name, "📋 All Versions") {
+			versions++
+		}
+	}
+	assert.Greater(t, versions, 1, "expected the version list to continue in more fields")
+}
+
+func TestFitEmbed_TruncatesEverything(t *testing.T) {
+	embed := theme().NewEmbed(EmbedError, strings.Repeat("t", 500), strings.Repeat("d", 5000), "")
+	for i := 0; i < 30; i++ {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  strings.Repeat("n", 300),
+			Value: strings.Repeat("v", 2000),
+		})
+	}
+
+	fitEmbed(embed)
+	assert.Equal(t, maxEmbedTitleLength, utf8.RuneCountInString(embed.Title))
+	assert.Equal(t, maxEmbedDescriptionLength, utf8.RuneCountInString(embed.Description))
+	assertFieldsFit(t, embed)
+	assert.LessOrEqual(t, embedLength(embed), maxEmbedTotalLength)
+	assert.NotEmpty(t, embed.Fields, "expected the fields that fit to be kept")
+}
+
+func TestFitEmbed_KeepsTotalUnderLimit(t *testing.T) {
+	// Every part is within its own limit, but together they are not
+	embed := theme().NewEmbed(EmbedInfo, "Title", strings.Repeat("d", 3000), "")
+	for i := 0; i < 5; i++ {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "Field",
+			Value: strings.Repeat("v", 1000),
+		})
+	}
+
+	fitEmbed(embed)
+	assert.LessOrEqual(t, embedLength(embed), maxEmbedTotalLength)
+	assert.Len(t, embed.Fields, 2, "expected trailing fields dropped")
+	assert.Equal(t, 3000, utf8.RuneCountInString(embed.Description))
+
+	// With nothing left to drop, the description is cut
+	long := &discordgo.MessageEmbed{
+		Description: strings.Repeat("d", 4096),
+		Footer:      &discordgo.MessageEmbedFooter{Text: strings.Repeat("f", 2048)},
+	}
+	fitEmbed(long)
+	assert.Equal(t, maxEmbedTotalLength, embedLength(long))
+}
+
+func TestSearchFailureEmbed_LongErrorFits(t *testing.T) {
+	embed := searchFailureEmbed(searchFailure{
+		reason:  uma.UpstreamError,
+		subject: "Support Card",
+		query:   "kitasan black",
+		err:     errors.New(strings.Repeat("upstream said no. ", 200)),
+	})
+	assertFieldsFit(t, embed)
+}
@@ -0,0 +1,366 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// trimSilenceKey is the setting that skips silence at the start of tracks
+const trimSilenceKey = "trim_silence"
+
+var (
+	errUnknownConfigKey     = errors.New("unknown config key")
+	errConfigNotOverridable = errors.New("cannot be overridden per server")
+)
+
+// guildSetting is a setting shown by !config show. Overridable settings can
+// be changed for one guild with !config set; the rest are bot-wide.
+type guildSetting struct {
+	key         string
+	overridable bool
+
+	// global returns the bot-wide value, redacted where secret
+	global func() string
+	// parse validates an override and returns its normalized form
+	parse func(raw string) (string, error)
+	// check, if set, verifies a parsed override against the guild it is for
+	check func(channels channelLookup, guildID, value string) error
+}
+
+// channelLookup fetches a channel; *discordgo.Session implements it
+type channelLookup interface {
+	Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+}
+
+// guildSettings lists the settings !config knows, in display order
+var guildSettings = []guildSetting{
+	{
+		key:         "max_track_failures",
+		overridable: true,
+		global:      func() string { return strconv.Itoa(getMaxTrackFailures()) },
+		parse:       parseMaxTrackFailures,
+	},
+	{
+		key:         "alone_grace_period",
+		overridable: true,
+		global:      func() string { return botConfig().AloneGracePeriod.String() },
+		parse:       parseAloneGracePeriod,
+	},
+	{
+		key:         announceChannelKey,
+		overridable: true,
+		global:      func() string { return "none" },
+		parse:       parseAnnounceChannel,
+		check:       checkAnnounceChannel,
+	},
+	{
+		key:         trimSilenceKey,
+		overridable: true,
+		global:      func() string { return formatOnOff(botConfig().TrimSilence) },
+		parse:       parseOnOff,
+	},
+	{
+		key:    "silence_threshold_db",
+		global: func() string { return strconv.FormatFloat(silenceThresholdDB(), 'f', -1, 64) },
+	},
+	{
+		key:    "discord_token",
+		global: func() string { return botConfig().DiscordToken },
+	},
+	{
+		key:    "cron_enabled",
+		global: func() string { return strconv.FormatBool(botConfig().CronEnabled) },
+	},
+	{
+		key:    "max_uma_searches",
+		global: func() string { return strconv.Itoa(botConfig().MaxUmaSearches) },
+	},
+}
+
+var (
+	// botCfg is the redacted bot-wide config that guild overrides layer onto
+	botCfg      config.Config
+	botCfgMutex sync.RWMutex
+
+	// guildOverrides holds each guild's normalized overrides by key. They
+	// are kept in memory only, so they reset when the bot restarts;
+	// !settings export saves a copy to import again.
+	guildOverrides      = make(map[string]map[string]string)
+	guildOverridesMutex sync.RWMutex
+)
+
+// SetBotConfig sets the bot-wide config shown by !config show. Secrets are
+// redacted before it is stored.
+func SetBotConfig(cfg *config.Config) {
+	botCfgMutex.Lock()
+	defer botCfgMutex.Unlock()
+	if cfg == nil {
+		botCfg = config.Config{}
+		return
+	}
+	botCfg = cfg.Redacted()
+}
+
+// botConfig returns the redacted bot-wide config
+func botConfig() config.Config {
+	botCfgMutex.RLock()
+	defer botCfgMutex.RUnlock()
+	return botCfg
+}
+
+// findGuildSetting looks up a setting by key
+func findGuildSetting(key string) (guildSetting, bool) {
+	for _, setting := range guildSettings {
+		if setting.key == key {
+			return setting, true
+		}
+	}
+	return guildSetting{}, false
+}
+
+// setGuildOverride validates and stores an override for a guild, looking up
+// channels it names with channels. The value "default" removes the override.
+func setGuildOverride(channels channelLookup, guildID, key, raw string) (string, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	setting, ok := findGuildSetting(key)
+	if !ok {
+		return "", fmt.Errorf("%w %q", errUnknownConfigKey, key)
+	}
+	if !setting.overridable {
+		return "", fmt.Errorf("%q %w", key, errConfigNotOverridable)
+	}
+
+	if strings.EqualFold(strings.TrimSpace(raw), "default") {
+		guildOverridesMutex.Lock()
+		defer guildOverridesMutex.Unlock()
+		delete(guildOverrides[guildID], key)
+		if len(guildOverrides[guildID]) == 0 {
+			delete(guildOverrides, guildID)
+		}
+		return setting.global(), nil
+	}
+
+	value, err := setting.validate(channels, guildID, raw)
+	if err != nil {
+		return "", err
+	}
+	storeGuildOverride(guildID, key, value)
+	return value, nil
+}
+
+// validate parses an override and checks it against the guild, returning
+// its normalized form
+func (setting guildSetting) validate(channels channelLookup, guildID, raw string) (string, error) {
+	value, err := setting.parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid value for %q: %v", setting.key, err)
+	}
+	if setting.check != nil {
+		if err := setting.check(channels, guildID, value); err != nil {
+			return "", fmt.Errorf("invalid value for %q: %v", setting.key, err)
+		}
+	}
+	return value, nil
+}
+
+// storeGuildOverride stores an already validated override
+func storeGuildOverride(guildID, key, value string) {
+	guildOverridesMutex.Lock()
+	defer guildOverridesMutex.Unlock()
+
+	if guildOverrides[guildID] == nil {
+		guildOverrides[guildID] = make(map[string]string)
+	}
+	guildOverrides[guildID][key] = value
+}
+
+// guildOverride returns a guild's override for key, if it has one
+func guildOverride(guildID, key string) (string, bool) {
+	guildOverridesMutex.RLock()
+	defer guildOverridesMutex.RUnlock()
+	value, ok := guildOverrides[guildID][key]
+	return value, ok
+}
+
+// effectiveSetting is one line of a guild's merged config
+type effectiveSetting struct {
+	key         string
+	value       string
+	overridden  bool
+	overridable bool
+}
+
+// effectiveGuildConfig merges a guild's overrides onto the bot-wide config
+func effectiveGuildConfig(guildID string) []effectiveSetting {
+	settings := make([]effectiveSetting, 0, len(guildSettings))
+	for _, setting := range guildSettings {
+		effective := effectiveSetting{
+			key:         setting.key,
+			value:       setting.global(),
+			overridable: setting.overridable,
+		}
+		if value, ok := guildOverride(guildID, setting.key); ok {
+			effective.value = value
+			effective.overridden = true
+		}
+		settings = append(settings, effective)
+	}
+	return settings
+}
+
+// guildMaxTrackFailures returns the consecutive failure limit for a guild
+func guildMaxTrackFailures(guildID string) int {
+	if value, ok := guildOverride(guildID, "max_track_failures"); ok {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return getMaxTrackFailures()
+}
+
+// guildAloneGracePeriod returns how long a guild stays paused while alone,
+// or fallback when it has no override
+func guildAloneGracePeriod(guildID string, fallback time.Duration) time.Duration {
+	if value, ok := guildOverride(guildID, "alone_grace_period"); ok {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+// guildTrimSilence reports whether a guild's tracks start after their
+// leading silence
+func guildTrimSilence(guildID string) bool {
+	if value, ok := guildOverride(guildID, trimSilenceKey); ok {
+		return value == "on"
+	}
+	return botConfig().TrimSilence
+}
+
+// silenceThresholdDB returns the level below which leading audio is trimmed
+func silenceThresholdDB() float64 {
+	if db := botConfig().SilenceThresholdDB; db < 0 {
+		return db
+	}
+	return common.DefaultSilenceThresholdDB
+}
+
+// parseMaxTrackFailures accepts 1 to 20 failures
+func parseMaxTrackFailures(raw string) (string, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("%q is not a whole number", raw)
+	}
+	if n < 1 || n > 20 {
+		return "", errors.New("must be between 1 and 20")
+	}
+	return strconv.Itoa(n), nil
+}
+
+// parseAloneGracePeriod accepts a duration up to an hour; 0 disables pausing
+func parseAloneGracePeriod(raw string) (string, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("%q is not a duration such as 90s or 5m", raw)
+	}
+	if d < 0 || d > time.Hour {
+		return "", errors.New("must be between 0s and 1h")
+	}
+	return d.String(), nil
+}
+
+// parseOnOff accepts on/off, true/false, yes/no or 1/0
+func parseOnOff(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "on", "true", "yes", "1":
+		return "on", nil
+	case "off", "false", "no", "0":
+		return "off", nil
+	}
+	return "", fmt.Errorf("%q is not on or off", raw)
+}
+
+// formatOnOff renders a switch the way parseOnOff normalizes it
+func formatOnOff(enabled bool) string {
+	if enabled {
+		return "on"
+	}
+	return "off"
+}
+
+// overridableConfigKeys returns the keys !config set accepts, sorted
+func overridableConfigKeys() []string {
+	var keys []string
+	for _, setting := range guildSettings {
+		if setting.overridable {
+			keys = append(keys, setting.key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatGuildConfig renders a guild's merged config one setting per line
+func formatGuildConfig(settings []effectiveSetting) string {
+	lines := make([]string, 0, len(settings))
+	for _, setting := range settings {
+		source := "default"
+		switch {
+		case setting.overridden:
+			source = "server override"
+		case !setting.overridable:
+			source = "bot-wide"
+		}
+		lines = append(lines, fmt.Sprintf("`%s` = `%s` (%s)", setting.key, setting.value, source))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showGuildConfig sends the guild's effective config
+func showGuildConfig(s *discordgo.Session, m *discordgo.MessageCreate) {
+	description := formatGuildConfig(effectiveGuildConfig(m.GuildID))
+	description += "\n\nAdmins can change overridable settings with `!config set <key> <value>`."
+	sendEmbedMessage(s, m.ChannelID, "⚙️ Server Configuration", description, EmbedInfo)
+}
+
+// setGuildConfig handles `!config set <key> <value>` (server admins and the
+// bot owner only)
+func setGuildConfig(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if !isOwner(m) && !hasAdminPermissions(s, m.GuildID, m.Author.ID) {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "You need Administrator permission to change server settings.", EmbedError)
+		return
+	}
+
+	if len(args) < 2 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Usage: `!config set <key> <value>`\nOverridable keys: %s", strings.Join(overridableConfigKeys(), ", ")), EmbedError)
+		return
+	}
+
+	value, err := setGuildOverride(s, m.GuildID, args[0], strings.Join(args[1:], " "))
+	if err != nil {
+		if errors.Is(err, errUnknownConfigKey) || errors.Is(err, errConfigNotOverridable) {
+			err = fmt.Errorf("%v. Overridable keys: %s", err, strings.Join(overridableConfigKeys(), ", "))
+		}
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+		return
+	}
+
+	sendEmbedMessage(s, m.ChannelID, "⚙️ Setting Updated", fmt.Sprintf("`%s` is now `%s` for this server.", strings.ToLower(args[0]), value), EmbedSuccess)
+}
+
+// isOwner reports whether the message author is the configured bot owner
+func isOwner(m *discordgo.MessageCreate) bool {
+	ownerID := os.Getenv("BOT_OWNER_ID")
+	return ownerID != "" && m.Author.ID == ownerID
+}
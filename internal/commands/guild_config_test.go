@@ -0,0 +1,148 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resetGuildConfig clears overrides and the bot config after a test
+func resetGuildConfig(t *testing.T) {
+	t.Cleanup(func() {
+		guildOverridesMutex.Lock()
+		guildOverrides = make(map[string]map[string]string)
+		guildOverridesMutex.Unlock()
+		SetBotConfig(nil)
+	})
+}
+
+func TestSetGuildOverride_ValidatesValues(t *testing.T) {
+	resetGuildConfig(t)
+
+	value, err := setGuildOverride(nil, "guild-1", "max_track_failures", " 5 ")
+	require.NoError(t, err)
+	assert.Equal(t, "5", value)
+	assert.Equal(t, 5, guildMaxTrackFailures("guild-1"))
+
+	// Keys are case-insensitive and durations are normalized
+	value, err = setGuildOverride(nil, "guild-1", "ALONE_GRACE_PERIOD", "90s")
+	require.NoError(t, err)
+	assert.Equal(t, "1m30s", value)
+	assert.Equal(t, 90*time.Second, guildAloneGracePeriod("guild-1", time.Minute))
+
+	for _, raw := range []string{"0", "21", "-1", "three", ""} {
+		_, err := setGuildOverride(nil, "guild-1", "max_track_failures", raw)
+		assert.Error(t, err, "value %q", raw)
+	}
+	for _, raw := range []string{"-1s", "2h", "soon"} {
+		_, err := setGuildOverride(nil, "guild-1", "alone_grace_period", raw)
+		assert.Error(t, err, "value %q", raw)
+	}
+
+	// Rejected values leave the previous override in place
+	assert.Equal(t, 5, guildMaxTrackFailures("guild-1"))
+}
+
+func TestSetGuildOverride_RejectsUnknownAndLockedKeys(t *testing.T) {
+	resetGuildConfig(t)
+
+	_, err := setGuildOverride(nil, "guild-1", "volume", "11")
+	assert.ErrorIs(t, err, errUnknownConfigKey)
+
+	_, err = setGuildOverride(nil, "guild-1", "discord_token", "stolen")
+	assert.ErrorIs(t, err, errConfigNotOverridable)
+
+	_, err = setGuildOverride(nil, "guild-1", "max_uma_searches", "100")
+	assert.ErrorIs(t, err, errConfigNotOverridable)
+
+	_, ok := guildOverride("guild-1", "discord_token")
+	assert.False(t, ok)
+}
+
+func TestSetGuildOverride_DefaultRemovesOverride(t *testing.T) {
+	resetGuildConfig(t)
+
+	_, err := setGuildOverride(nil, "guild-1", "max_track_failures", "7")
+	require.NoError(t, err)
+
+	value, err := setGuildOverride(nil, "guild-1", "max_track_failures", "default")
+	require.NoError(t, err)
+	assert.Equal(t, "3", value)
+	assert.Equal(t, getMaxTrackFailures(), guildMaxTrackFailures("guild-1"))
+}
+
+func TestEffectiveGuildConfig_MergesOverridesAndRedacts(t *testing.T) {
+	resetGuildConfig(t)
+	SetBotConfig(&config.Config{
+		DiscordToken:     "super-secret-token",
+		AloneGracePeriod: 5 * time.Minute,
+		MaxUmaSearches:   4,
+	})
+
+	_, err := setGuildOverride(nil, "guild-1", "alone_grace_period", "30s")
+	require.NoError(t, err)
+
+	settings := map[string]effectiveSetting{}
+	for _, setting := range effectiveGuildConfig("guild-1") {
+		settings[setting.key] = setting
+	}
+
+	assert.Equal(t, "30s", settings["alone_grace_period"].value)
+	assert.True(t, settings["alone_grace_period"].overridden)
+	assert.Equal(t, "3", settings["max_track_failures"].value)
+	assert.False(t, settings["max_track_failures"].overridden)
+	assert.Equal(t, "4", settings["max_uma_searches"].value)
+	assert.Equal(t, "***", settings["discord_token"].value)
+
+	// Other guilds only see the bot-wide values
+	for _, setting := range effectiveGuildConfig("guild-2") {
+		assert.False(t, setting.overridden, setting.key)
+		if setting.key == "alone_grace_period" {
+			assert.Equal(t, "5m0s", setting.value)
+		}
+	}
+
+	rendered := formatGuildConfig(effectiveGuildConfig("guild-1"))
+	assert.Contains(t, rendered, "`alone_grace_period` = `30s` (server override)")
+	assert.Contains(t, rendered, "`max_track_failures` = `3` (default)")
+	assert.Contains(t, rendered, "`discord_token` = `***` (bot-wide)")
+	assert.NotContains(t, rendered, "super-secret-token")
+}
+
+func TestAloneMonitor_UsesGuildGraceOverride(t *testing.T) {
+	resetGuildConfig(t)
+
+	// Disabled bot-wide, but one guild opts in
+	monitor, recorder, timers := newTestAloneMonitor(0)
+	_, err := setGuildOverride(nil, "guild-1", "alone_grace_period", "2m")
+	require.NoError(t, err)
+
+	monitor.Update("guild-2", 0)
+	monitor.Update("guild-1", 0)
+
+	assert.Equal(t, []string{"alone:guild-1"}, recorder.events)
+	assert.Len(t, *timers, 1)
+}
+
+func TestGuildTrimSilence_OverridesBotWide(t *testing.T) {
+	resetGuildConfig(t)
+	SetBotConfig(&config.Config{TrimSilence: true})
+
+	value, err := setGuildOverride(nil, "guild-1", "trim_silence", "No")
+	require.NoError(t, err)
+	assert.Equal(t, "off", value)
+	assert.False(t, guildTrimSilence("guild-1"))
+	assert.True(t, guildTrimSilence("guild-2"))
+
+	_, err = setGuildOverride(nil, "guild-1", "trim_silence", "sometimes")
+	assert.Error(t, err)
+
+	// An unset threshold falls back to the default
+	assert.Equal(t, -50.0, silenceThresholdDB())
+	SetBotConfig(&config.Config{SilenceThresholdDB: -35})
+	assert.Equal(t, -35.0, silenceThresholdDB())
+	assert.False(t, guildTrimSilence("guild-2"))
+}
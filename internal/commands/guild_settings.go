@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// guildSettingsVersion is the format version written by !settings export
+	guildSettingsVersion = 1
+
+	// maxSettingsImportLength caps a pasted !settings import blob, in bytes
+	maxSettingsImportLength = 2000
+
+	// maxSettingValueLength caps each value in an imported blob, in bytes
+	maxSettingValueLength = 100
+)
+
+var errInvalidSettingsBlob = errors.New("invalid settings blob")
+
+// guildSettingsBlob is the JSON carried between instances by !settings
+// export and import. Only overridable settings are included, so bot-wide
+// values such as the token never leave the instance.
+type guildSettingsBlob struct {
+	Version  int               `json:"version"`
+	Settings map[string]string `json:"settings"`
+}
+
+// exportGuildSettings returns a guild's overrides as a settings blob
+func exportGuildSettings(guildID string) (string, error) {
+	blob := guildSettingsBlob{Version: guildSettingsVersion, Settings: make(map[string]string)}
+	for _, setting := range guildSettings {
+		if !setting.overridable {
+			continue
+		}
+		if value, ok := guildOverride(guildID, setting.key); ok {
+			blob.Settings[setting.key] = value
+		}
+	}
+
+	data, err := json.Marshal(blob)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode settings: %w", err)
+	}
+	return string(data), nil
+}
+
+// importGuildSettings validates a settings blob and applies its overrides to
+// a guild, looking up channels it names with channels, and returns the
+// stored values. Nothing is applied unless every setting is valid; settings
+// the blob leaves out are unchanged.
+func importGuildSettings(channels channelLookup, guildID, raw string) (map[string]string, error) {
+	if len(raw) > maxSettingsImportLength {
+		return nil, fmt.Errorf("%w: longer than %d bytes", errInvalidSettingsBlob, maxSettingsImportLength)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(trimCodeBlock(raw))))
+	decoder.DisallowUnknownFields()
+	var blob guildSettingsBlob
+	if err := decoder.Decode(&blob); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidSettingsBlob, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: unexpected data after the settings", errInvalidSettingsBlob)
+	}
+	if blob.Version != guildSettingsVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", errInvalidSettingsBlob, blob.Version)
+	}
+
+	keys := make([]string, 0, len(blob.Settings))
+	for key := range blob.Settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Validate everything before storing anything
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		raw := blob.Settings[key]
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		setting, ok := findGuildSetting(key)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", errUnknownConfigKey, key)
+		}
+		if !setting.overridable {
+			return nil, fmt.Errorf("%q %w", key, errConfigNotOverridable)
+		}
+		if len(raw) > maxSettingValueLength {
+			return nil, fmt.Errorf("invalid value for %q: longer than %d bytes", key, maxSettingValueLength)
+		}
+		value, err := setting.validate(channels, guildID, raw)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = value
+	}
+
+	for key, value := range values {
+		storeGuildOverride(guildID, key, value)
+	}
+	return values, nil
+}
+
+// trimCodeBlock removes the Markdown code fence around a pasted blob
+func trimCodeBlock(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "```") && strings.HasSuffix(raw, "```") && len(raw) >= 6 {
+		raw = strings.TrimPrefix(raw[3:len(raw)-3], "json")
+	}
+	return strings.Trim(raw, "` \n")
+}
+
+// SettingsCommand handles `!settings export`, which sends the server's
+// overrides as JSON, and `!settings import <json>`, which applies an
+// exported blob (server admins and the bot owner only)
+func SettingsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if m.GuildID == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌ This command can only be used in a server.")
+		return
+	}
+	if !isOwner(m) && !hasAdminPermissions(s, m.GuildID, m.Author.ID) {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "You need Administrator permission to change server settings.", EmbedError)
+		return
+	}
+	if len(args) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!settings export` or `!settings import <json>`", EmbedError)
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "export":
+		blob, err := exportGuildSettings(m.GuildID)
+		if err != nil {
+			sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+			return
+		}
+		sendEmbedMessage(s, m.ChannelID, "⚙️ Server Settings",
+			fmt.Sprintf("```json\n%s\n```\nPaste this after `!settings import` on another instance to copy these settings.", blob), EmbedInfo)
+	case "import":
+		importSettings(s, m, strings.Join(args[1:], " "))
+	default:
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!settings export` or `!settings import <json>`", EmbedError)
+	}
+}
+
+// importSettings applies a pasted settings blob and reports what changed
+func importSettings(s *discordgo.Session, m *discordgo.MessageCreate, raw string) {
+	if strings.TrimSpace(raw) == "" {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!settings import <json>`, pasting the output of `!settings export`", EmbedError)
+		return
+	}
+
+	values, err := importGuildSettings(s, m.GuildID, raw)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+		return
+	}
+	if len(values) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "⚙️ Settings Imported", "The blob had no settings, so nothing changed.", EmbedInfo)
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("`%s` = `%s`", key, values[key]))
+	}
+	sendEmbedMessage(s, m.ChannelID, "⚙️ Settings Imported", strings.Join(lines, "\n"), EmbedSuccess)
+}
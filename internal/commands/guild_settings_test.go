@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuildSettings_ExportImportRoundTrip(t *testing.T) {
+	resetGuildConfig(t)
+
+	_, err := setGuildOverride(nil, "guild-1", "max_track_failures", "7")
+	require.NoError(t, err)
+	_, err = setGuildOverride(nil, "guild-1", "alone_grace_period", "90s")
+	require.NoError(t, err)
+	_, err = setGuildOverride(testChannels, "guild-1", announceChannelKey, "<#1234567890>")
+	require.NoError(t, err)
+	_, err = setGuildOverride(nil, "guild-1", trimSilenceKey, "on")
+	require.NoError(t, err)
+
+	blob, err := exportGuildSettings("guild-1")
+	require.NoError(t, err)
+	assert.NotContains(t, blob, "discord_token")
+
+	want := make(map[string]string)
+	for _, key := range overridableConfigKeys() {
+		want[key], _ = guildOverride("guild-1", key)
+	}
+
+	// Import into the same guild as if on a fresh instance
+	guildOverridesMutex.Lock()
+	guildOverrides = make(map[string]map[string]string)
+	guildOverridesMutex.Unlock()
+
+	values, err := importGuildSettings(testChannels, "guild-1", blob)
+	require.NoError(t, err)
+	assert.Len(t, values, 4)
+
+	for _, key := range overridableConfigKeys() {
+		got, ok := guildOverride("guild-1", key)
+		assert.True(t, ok, key)
+		assert.Equal(t, want[key], got, key)
+	}
+
+	// Exporting the imported guild gives the same blob
+	again, err := exportGuildSettings("guild-1")
+	require.NoError(t, err)
+	assert.Equal(t, blob, again)
+}
+
+func TestGuildSettings_ImportAcceptsCodeBlock(t *testing.T) {
+	resetGuildConfig(t)
+
+	_, err := importGuildSettings(nil, "guild-1", "```json\n{\"version\": 1, \"settings\": {\"max_track_failures\": \"4\"}}\n```")
+	require.NoError(t, err)
+	assert.Equal(t, 4, guildMaxTrackFailures("guild-1"))
+}
+
+func TestGuildSettings_ImportRejectsBadInput(t *testing.T) {
+	resetGuildConfig(t)
+
+	_, err := setGuildOverride(nil, "guild-1", "max_track_failures", "5")
+	require.NoError(t, err)
+
+	tests := map[string]string{
+		"not json":        `max_track_failures=3`,
+		"wrong type":      `{"version": 1, "settings": {"max_track_failures": 3}}`,
+		"unknown field":   `{"version": 1, "settings": {}, "token": "x"}`,
+		"wrong version":   `{"version": 2, "settings": {"max_track_failures": "3"}}`,
+		"trailing data":   `{"version": 1, "settings": {}} {}`,
+		"unknown key":     `{"version": 1, "settings": {"volume": "11"}}`,
+		"locked key":      `{"version": 1, "settings": {"discord_token": "stolen"}}`,
+		"invalid value":   `{"version": 1, "settings": {"max_track_failures": "3", "alone_grace_period": "2h"}}`,
+		"overlong value":  `{"version": 1, "settings": {"announce_channel": "` + strings.Repeat("1", maxSettingValueLength+1) + `"}}`,
+		"overlong blob":   `{"version": 1, "settings": {}}` + strings.Repeat(" ", maxSettingsImportLength),
+		"missing version": `{"settings": {"max_track_failures": "3"}}`,
+	}
+
+	for name, raw := range tests {
+		_, err := importGuildSettings(nil, "guild-1", raw)
+		assert.Error(t, err, name)
+	}
+
+	// A rejected blob applies nothing, even its valid settings
+	assert.Equal(t, 5, guildMaxTrackFailures("guild-1"))
+	_, ok := guildOverride("guild-1", "alone_grace_period")
+	assert.False(t, ok)
+}
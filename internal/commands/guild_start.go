@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+var (
+	// guildStartLocks serializes the starting of playback per guild, so two
+	// commands arriving together can't each create a pipeline
+	guildStartLocks      = make(map[string]*sync.Mutex)
+	guildStartLocksMutex sync.Mutex
+)
+
+// lockGuildStart takes the guild's start lock and returns its unlock
+func lockGuildStart(guildID string) func() {
+	guildStartLocksMutex.Lock()
+	lock, ok := guildStartLocks[guildID]
+	if !ok {
+		lock = &sync.Mutex{}
+		guildStartLocks[guildID] = lock
+	}
+	guildStartLocksMutex.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// startIfIdle starts the next track unless the guild is already playing, in
+// which case the caller's tracks simply wait in the existing session's
+// queue. It reports whether playback was started.
+func startIfIdle(s *discordgo.Session, m *discordgo.MessageCreate, queue *common.MusicQueue) bool {
+	return runIfIdle(queue, func() { startNextInQueueLocked(s, m, queue) })
+}
+
+// runIfIdle runs start under the guild's start lock if the queue can start
+// playing, making the check and the pipeline creation one step
+func runIfIdle(queue *common.MusicQueue, start func()) bool {
+	unlock := lockGuildStart(queue.GuildID())
+	defer unlock()
+
+	if !queue.CanStartPlaying() {
+		return false
+	}
+	start()
+	return true
+}
+
+// advanceQueue moves on from a pipeline that stopped, starting the next
+// track. Both a skip and the stopped pipeline's monitor advance; whichever
+// comes second finds the pipeline already replaced and does nothing.
+func advanceQueue(s *discordgo.Session, m *discordgo.MessageCreate, queue *common.MusicQueue, from *common.AudioPipeline) {
+	unlock := lockGuildStart(queue.GuildID())
+	defer unlock()
+
+	if queue.GetPipeline() != from {
+		return
+	}
+	queue.SetPipeline(nil)
+	startNextInQueueLocked(s, m, queue)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrentStartsCreateOnePipeline(t *testing.T) {
+	queue := common.NewMusicQueue("guild-start-guild")
+	queue.Add("https://stream.example/one", "One", "tester")
+	queue.Add("https://stream.example/two", "Two", "tester")
+
+	// Stands in for startNextInQueueLocked without a Discord session
+	var created int32
+	start := func() {
+		atomic.AddInt32(&created, 1)
+		item := queue.Next()
+		if !assert.NotNil(t, item) {
+			return
+		}
+		queue.SetPlaying(true)
+
+		pipeline := common.NewAudioPipeline(nil)
+		pipeline.SetStreamer(func(ctx context.Context, streamURL string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		queue.SetPipeline(pipeline)
+		assert.NoError(t, pipeline.PlayStream(item.URL))
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	started := make(chan bool, callers)
+	ready := make(chan struct{})
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ready
+			started <- runIfIdle(queue, start)
+		}()
+	}
+	close(ready)
+	wg.Wait()
+	close(started)
+
+	starts := 0
+	for ok := range started {
+		if ok {
+			starts++
+		}
+	}
+	assert.Equal(t, 1, starts, "only the first start should create a pipeline")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&created))
+	assert.True(t, queue.HasActivePipeline())
+	assert.Equal(t, 1, queue.Size(), "later callers join the session and leave their track queued")
+
+	queue.StopAndCleanup()
+}
+
+func TestGuildStartLocksArePerGuild(t *testing.T) {
+	unlockA := lockGuildStart("guild-start-a")
+	defer unlockA()
+
+	// Another guild isn't held up by guild A's start
+	done := make(chan struct{})
+	go func() {
+		unlock := lockGuildStart("guild-start-b")
+		unlock()
+		close(done)
+	}()
+	<-done
+}
@@ -2,7 +2,6 @@ package commands
 
 import (
 	"strings"
-	"time"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -10,78 +9,88 @@ import (
 // ShowHelpCommand displays all available commands with their descriptions using embeds
 func ShowHelpCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Create embed
-	embed := &discordgo.MessageEmbed{
-		Title: "Here are all the available commands for the bot:",
-		// Description: "Here are all the available commands for the bot:",
-		Color:     0x00ff00, // Green color
-		Timestamp: time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text:    "Hokko Tarumae",
-			IconURL: "https://cdn.discordapp.com/attachments/1378031194356060280/1402891387061403718/footer.gif?ex=68958feb&is=68943e6b&hm=21cdbed6dde8e956c55af9345d23755a617cf20f9f098fde6369a73164b67ca0&", // Replace with custom image URL
+	embed := theme().NewEmbed(EmbedSuccess, "Here are all the available commands for the bot:", "", "")
+	embed.Footer.IconURL = "https://cdn.discordapp.com/attachments/1378031194356060280/1402891387061403718/footer.gif?ex=68958feb&is=68943e6b&hm=21cdbed6dde8e956c55af9345d23755a617cf20f9f098fde6369a73164b67ca0&"
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name: "Music Commands",
+			Value: strings.Join([]string{
+				"• `!play <url>` / `!p <url>` - Play a YouTube video by URL",
+				"• `!p <keywords>` - Search and play a YouTube video",
+				"• `!nowplaying` / `!np` - Show the currently playing track",
+				"• `!queue add <url>` - Add a YouTube video to the queue",
+				"• `!queue list` - List the current queue, paged with ⬅️ ➡️",
+				"• `!queue remove <position>` - Remove a track from the queue",
+				"• `!queue next <position>` - Move a track to play next",
+				"• `!queue retryfailed` - Requeue tracks that were skipped after failing to play",
+				"• `!clear` - Clear the entire queue",
+				"• `!shuffle` - Shuffle the queue",
+				"• `!pause` - Pause the current playback",
+				"• `!resume` - Resume paused playback",
+				"• `!seek <mm:ss|+N|-N>` - Jump to a position in the current track",
+				"• `!seek chapter <n>` - Jump to a chapter of the current track",
+				"• `!chapters` - List the current track's chapters",
+				"• `!move` - Move playback to your voice channel, keeping the track and queue",
+				"• `!skip` - Skip the currently playing track",
+				"• `!stop` - Stop playback and disconnect from voice channel",
+				"• `!replay` - Requeue every track played this session",
+				"• `!replay from <n>` - Requeue the last n finished tracks",
+				"• `!history errors [page]` - Show recent playback errors and recoveries",
+				"• `!config show` - Show this server's effective settings",
+				"• `!config set <key> <value|default>` - Override a setting for this server (admins)",
+				"• `!setannounce <#channel|off>` - Send playback notifications to a dedicated channel (admins)",
+				"• `!settings export` / `!settings import <json>` - Copy this server's settings between instances, or keep them across restarts (admins)",
+			}, "\n"),
+			Inline: false,
 		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name: "Music Commands",
-				Value: strings.Join([]string{
-					"• `!play <url>` / `!p <url>` - Play a YouTube video by URL",
-					"• `!p <keywords>` - Search and play a YouTube video",
-					"• `!nowplaying` / `!np` - Show the currently playing track",
-					"• `!queue add <url>` - Add a YouTube video to the queue",
-					"• `!queue list` - List the current queue",
-					"• `!queue remove <position>` - Remove a track from the queue",
-					"• `!clear` - Clear the entire queue",
-					"• `!shuffle` - Shuffle the queue",
-					"• `!pause` - Pause the current playback",
-					"• `!resume` - Resume paused playback",
-					"• `!skip` - Skip the currently playing track",
-					"• `!stop` - Stop playback and disconnect from voice channel",
-				}, "\n"),
-				Inline: false,
-			},
-			{
-				Name: "Information Commands",
-				Value: strings.Join([]string{
-					"• `!about` - Show bot info, uptime, and stats",
-					"• `!servers` - List servers the bot is connected to (bot owner only)",
-					"• `!help` / `!h` - Show this help message",
-				}, "\n"),
-				Inline: false,
-			},
-			{
-				Name: "Moderation Commands",
-				Value: strings.Join([]string{
-					"• `!delete <number>` - Delete the specified number of recent messages",
-				}, "\n"),
-				Inline: false,
-			},
-			{
-				Name:   "Fun Commands",
-				Value:  "• `!gremlin` - Post a random gremlin image\n• `!uma char <name>` - Search for Uma Musume characters\n• `!uma support <name>` - Search for Uma Musume support cards\n• `!uma skills <name>` - Get skills for a support card",
-				Inline: false,
-			},
-			{
-				Name: "Utility Commands (Bot Owner Only)",
-				Value: strings.Join([]string{
-					"• `!utility cron` - Check cron job status",
-					"• `!utility cron-refresh` - Manually trigger build ID refresh",
-				}, "\n"),
-				Inline: false,
-			},
-			{
-				Name: "Admin Commands (Bot Owner Only)",
-				Value: strings.Join([]string{
-					"• `!leave <server_id>` - Force bot to leave a server by ID",
-				}, "\n"),
-				Inline: false,
-			},
-			{
-				Name: "💡 Tips",
-				Value: strings.Join([]string{
-					"• Join a voice channel **before** using music commands",
-					"• Only **YouTube links and searches** are currently supported",
-				}, "\n"),
-				Inline: false,
-			},
+		{
+			Name: "Information Commands",
+			Value: strings.Join([]string{
+				"• `!about` - Show bot info, uptime, and stats",
+				"• `!servers` - List servers the bot is connected to (bot owner only)",
+				"• `!help` / `!h` - Show this help message",
+			}, "\n"),
+			Inline: false,
+		},
+		{
+			Name: "Moderation Commands",
+			Value: strings.Join([]string{
+				"• `!delete <number>` - Delete the specified number of recent messages",
+			}, "\n"),
+			Inline: false,
+		},
+		{
+			Name:   "Fun Commands",
+			Value:  "• `!gremlin` - Post a random gremlin image\n• `!uma char <name>` - Search for Uma Musume characters\n• `!uma support <name>` - Search for Uma Musume support cards\n• `!uma skills <name> [type] [rarity]` - Get skills for a support card, optionally one version\n• `!uma effect <effect> [rarity]` - Find support cards with an effect, best first\n• `!uma version` - Show the Gametora build ID in use",
+			Inline: false,
+		},
+		{
+			Name: "Utility Commands (Bot Owner Only)",
+			Value: strings.Join([]string{
+				"• `!utility cron` - Check cron job status",
+				"• `!utility cron-refresh` - Manually trigger build ID refresh",
+			}, "\n"),
+			Inline: false,
+		},
+		{
+			Name: "Admin Commands (Bot Owner Only)",
+			Value: strings.Join([]string{
+				"• `!leave <server_id>` - Force bot to leave a server by ID",
+				"• `!config show` - Also attaches the effective pipeline configuration (secrets redacted)",
+				"• `!pipeline flush` - Write buffered pipeline metrics now",
+				"• `!pipeline cleanup` - Run metrics retention cleanup now",
+				"• `!pipeline maintain` - Refresh the database's query planner statistics now",
+				"• `!shutdown-audio` - Stop playback and clear queues in every server",
+			}, "\n"),
+			Inline: false,
+		},
+		{
+			Name: "💡 Tips",
+			Value: strings.Join([]string{
+				"• Join a voice channel **before** using music commands",
+				"• Only **YouTube links and searches** are currently supported",
+			}, "\n"),
+			Inline: false,
 		},
 	}
 
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/database"
+)
+
+const (
+	// historyEventsPerPage is the number of events shown on each page of `!history errors`
+	historyEventsPerPage = 10
+	// historyMaxPages caps how far back `!history errors` can page
+	historyMaxPages = 5
+)
+
+// eventHistorySource is the part of database.MetricsRepository used by `!history`
+type eventHistorySource interface {
+	GetSessionsByGuild(ctx context.Context, guildID string, limit int) ([]*database.PipelineSession, error)
+	GetEvents(ctx context.Context, query *database.EventQuery) ([]*database.PipelineEvent, error)
+}
+
+// HistoryCommand shows recent error and recovery events for the guild's latest pipeline
+func HistoryCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 || strings.ToLower(args[0]) != "errors" {
+		s.ChannelMessageSend(m.ChannelID, "❌ Usage: `!history errors [page]`")
+		return
+	}
+
+	page := 1
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > historyMaxPages {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ Page must be a number from 1 to %d.", historyMaxPages))
+			return
+		}
+		page = n
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s.ChannelMessageSendEmbed(m.ChannelID, errorHistoryEmbed(ctx, getMetricsRepository(), m.GuildID, page))
+}
+
+// errorHistoryEmbed renders one page of error and recovery events for the
+// most recent pipeline session in a guild
+func errorHistoryEmbed(ctx context.Context, repo eventHistorySource, guildID string, page int) *discordgo.MessageEmbed {
+	if repo == nil {
+		return historyErrorEmbed(errMetricsNotInitialized)
+	}
+
+	sessions, err := repo.GetSessionsByGuild(ctx, guildID, 1)
+	if err != nil {
+		return historyErrorEmbed(err)
+	}
+	if len(sessions) == 0 {
+		return theme().NewEmbed(EmbedNeutral, "📜 Pipeline Error History", "No playback sessions have been recorded for this server yet.", "Pipeline History")
+	}
+	pipelineID := sessions[0].PipelineID
+
+	// Fetch one extra event to learn whether there is another page
+	events, err := repo.GetEvents(ctx, &database.EventQuery{
+		PipelineID: pipelineID,
+		EventTypes: []string{"error", "recovery"},
+		Limit:      historyEventsPerPage + 1,
+		Offset:     (page - 1) * historyEventsPerPage,
+	})
+	if err != nil {
+		return historyErrorEmbed(err)
+	}
+
+	hasMore := len(events) > historyEventsPerPage && page < historyMaxPages
+	if len(events) > historyEventsPerPage {
+		events = events[:historyEventsPerPage]
+	}
+
+	if len(events) == 0 {
+		description := "No errors or recoveries were recorded for the latest session. 🎉"
+		if page > 1 {
+			description = fmt.Sprintf("No events on page %d.", page)
+		}
+		return theme().NewEmbed(EmbedNeutral, "📜 Pipeline Error History", description, "Pipeline History")
+	}
+
+	embed := theme().NewEmbed(EmbedInfo, "📜 Pipeline Error History",
+		fmt.Sprintf("Latest session `%s` · page %d", pipelineID, page), "Pipeline History")
+	for _, event := range events {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("%s %s · %s", historyEventIcon(event.EventType), event.EventType, event.Severity),
+			Value: fmt.Sprintf("<t:%d:f> %s", event.Timestamp.Unix(), describeEventData(event.EventData)),
+		})
+	}
+	if hasMore {
+		embed.Footer.Text = theme().FooterText(fmt.Sprintf("Pipeline History · !history errors %d for older events", page+1))
+	}
+	return embed
+}
+
+// historyEventIcon picks an icon for an event type
+func historyEventIcon(eventType string) string {
+	if eventType == "recovery" {
+		return "🔄"
+	}
+	return "⚠️"
+}
+
+// describeEventData summarizes an event payload, preferring its error or
+// message and otherwise listing its fields
+func describeEventData(data map[string]interface{}) string {
+	for _, key := range []string{"error", "message"} {
+		if value, ok := data[key]; ok {
+			return truncateEventText(fmt.Sprint(value), 200)
+		}
+	}
+	if len(data) == 0 {
+		return "No details recorded"
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, data[key]))
+	}
+	return truncateEventText(strings.Join(parts, ", "), 200)
+}
+
+// truncateEventText shortens text to at most limit runes, marking the cut
+func truncateEventText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// historyErrorEmbed reports a failed history lookup
+func historyErrorEmbed(err error) *discordgo.MessageEmbed {
+	return theme().NewEmbed(EmbedError, "❌ History Unavailable", err.Error(), "Pipeline History")
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubEventHistory implements eventHistorySource with canned sessions and events
+type stubEventHistory struct {
+	sessions   []*database.PipelineSession
+	events     []*database.PipelineEvent
+	sessionErr error
+	lastQuery  *database.EventQuery
+}
+
+func (r *stubEventHistory) GetSessionsByGuild(ctx context.Context, guildID string, limit int) ([]*database.PipelineSession, error) {
+	return r.sessions, r.sessionErr
+}
+
+func (r *stubEventHistory) GetEvents(ctx context.Context, query *database.EventQuery) ([]*database.PipelineEvent, error) {
+	r.lastQuery = query
+	start := query.Offset
+	if start > len(r.events) {
+		start = len(r.events)
+	}
+	end := start + query.Limit
+	if end > len(r.events) {
+		end = len(r.events)
+	}
+	return r.events[start:end], nil
+}
+
+func historyEvents(n int) []*database.PipelineEvent {
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	events := make([]*database.PipelineEvent, n)
+	for i := range events {
+		events[i] = &database.PipelineEvent{
+			PipelineID: "pipe-1",
+			EventType:  "error",
+			Severity:   "high",
+			EventData:  map[string]interface{}{"error": "ffmpeg exited"},
+			Timestamp:  at.Add(-time.Duration(i) * time.Minute),
+		}
+	}
+	return events
+}
+
+func TestErrorHistoryEmbed(t *testing.T) {
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	repo := &stubEventHistory{
+		sessions: []*database.PipelineSession{{PipelineID: "pipe-1", GuildID: "guild-1"}},
+		events: []*database.PipelineEvent{
+			{EventType: "error", Severity: "high", EventData: map[string]interface{}{"error": "timeout reading PCM data"}, Timestamp: at},
+			{EventType: "recovery", Severity: "medium", EventData: map[string]interface{}{"attempt": 1, "delay": "2s"}, Timestamp: at.Add(-time.Minute)},
+		},
+	}
+
+	embed := errorHistoryEmbed(context.Background(), repo, "guild-1", 1)
+
+	assert.Equal(t, "pipe-1", repo.lastQuery.PipelineID)
+	assert.ElementsMatch(t, []string{"error", "recovery"}, repo.lastQuery.EventTypes)
+	assert.Equal(t, theme().Color(EmbedInfo), embed.Color)
+	assert.Contains(t, embed.Description, "pipe-1")
+
+	require.Len(t, embed.Fields, 2)
+	assert.Equal(t, "⚠️ error · high", embed.Fields[0].Name)
+	assert.Equal(t, fmt.Sprintf("<t:%d:f> timeout reading PCM data", at.Unix()), embed.Fields[0].Value)
+	assert.Equal(t, "🔄 recovery · medium", embed.Fields[1].Name)
+	assert.Contains(t, embed.Fields[1].Value, "attempt=1, delay=2s")
+	assert.NotContains(t, embed.Footer.Text, "!history errors 2")
+}
+
+func TestErrorHistoryEmbedPaginates(t *testing.T) {
+	repo := &stubEventHistory{
+		sessions: []*database.PipelineSession{{PipelineID: "pipe-1"}},
+		events:   historyEvents(historyEventsPerPage + 3),
+	}
+
+	first := errorHistoryEmbed(context.Background(), repo, "guild-1", 1)
+	assert.Len(t, first.Fields, historyEventsPerPage)
+	assert.Contains(t, first.Footer.Text, "!history errors 2")
+
+	second := errorHistoryEmbed(context.Background(), repo, "guild-1", 2)
+	assert.Equal(t, historyEventsPerPage, repo.lastQuery.Offset)
+	assert.Len(t, second.Fields, 3)
+	assert.NotContains(t, second.Footer.Text, "!history errors 3")
+
+	empty := errorHistoryEmbed(context.Background(), repo, "guild-1", 3)
+	assert.Equal(t, theme().Color(EmbedNeutral), empty.Color)
+	assert.Empty(t, empty.Fields)
+}
+
+func TestErrorHistoryEmbedEmptyStates(t *testing.T) {
+	embed := errorHistoryEmbed(context.Background(), nil, "guild-1", 1)
+	assert.Equal(t, theme().Color(EmbedError), embed.Color)
+	assert.Equal(t, errMetricsNotInitialized.Error(), embed.Description)
+
+	embed = errorHistoryEmbed(context.Background(), &stubEventHistory{sessionErr: errors.New("database is locked")}, "guild-1", 1)
+	assert.Equal(t, "database is locked", embed.Description)
+
+	embed = errorHistoryEmbed(context.Background(), &stubEventHistory{}, "guild-1", 1)
+	assert.Equal(t, theme().Color(EmbedNeutral), embed.Color)
+	assert.Contains(t, embed.Description, "No playback sessions")
+
+	embed = errorHistoryEmbed(context.Background(), &stubEventHistory{
+		sessions: []*database.PipelineSession{{PipelineID: "pipe-1"}},
+	}, "guild-1", 1)
+	assert.Contains(t, embed.Description, "No errors or recoveries")
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// movablePlayer is the part of common.AudioPipeline a channel move drives
+type movablePlayer interface {
+	Position() time.Duration
+	IsPaused() bool
+	Pause() error
+	Resume() error
+	Seek(position time.Duration) error
+	SetVoiceConnection(vc *discordgo.VoiceConnection)
+}
+
+// channelMove is a pipeline.VoiceSession whose Join connects through
+// connect, so the reconnect-and-resume sequence moves playback to a
+// different channel, or rejoins the same one, while the player and queue
+// stay as they are
+type channelMove struct {
+	player     movablePlayer
+	disconnect func() error
+	connect    func(ctx context.Context) (*discordgo.VoiceConnection, error)
+	attach     func(vc *discordgo.VoiceConnection)
+
+	wasPaused bool
+}
+
+// Position implements pipeline.VoiceSession
+func (c *channelMove) Position() time.Duration {
+	return c.player.Position()
+}
+
+// Disconnect implements pipeline.VoiceSession. The player is paused first
+// so no frames are sent to the connection being closed.
+func (c *channelMove) Disconnect() error {
+	c.wasPaused = c.player.IsPaused()
+	if !c.wasPaused {
+		if err := c.player.Pause(); err != nil {
+			return err
+		}
+	}
+	return c.disconnect()
+}
+
+// Join implements pipeline.VoiceSession
+func (c *channelMove) Join(ctx context.Context) error {
+	vc, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	c.player.SetVoiceConnection(vc)
+	c.attach(vc)
+	return nil
+}
+
+// ResumeAt implements pipeline.VoiceSession. A track that was paused before
+// the move stays paused.
+func (c *channelMove) ResumeAt(position time.Duration) error {
+	if err := c.player.Seek(position); err != nil {
+		return err
+	}
+	if c.wasPaused {
+		return nil
+	}
+	return c.player.Resume()
+}
+
+// rejoinSession returns a channelMove that joins the channel playback is
+// already in, for the player to reconnect through when Discord invalidates
+// its voice session
+func rejoinSession(s *discordgo.Session, queue *common.MusicQueue, player movablePlayer) *channelMove {
+	var channelID string
+	return &channelMove{
+		player: player,
+		disconnect: func() error {
+			vc := queue.GetVoiceConnection()
+			if vc == nil {
+				return fmt.Errorf("no voice connection")
+			}
+			channelID = vc.ChannelID
+			return vc.Disconnect()
+		},
+		connect: func(ctx context.Context) (*discordgo.VoiceConnection, error) {
+			return common.JoinVoiceWithRetry(func() (*discordgo.VoiceConnection, error) {
+				return s.ChannelVoiceJoin(queue.GuildID(), channelID, false, true)
+			}, common.DefaultVoiceJoinRetry())
+		},
+		attach: queue.SetVoiceConnection,
+	}
+}
+
+// MoveCommand moves playback to the invoker's voice channel, resuming the
+// current track where it was and keeping the queue
+func MoveCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	guildID := m.GuildID
+
+	// Update activity for idle monitoring
+	updateActivity(guildID)
+
+	queue := getQueue(guildID)
+	if queue == nil || !queue.IsPlaying() {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing is currently playing.", EmbedError)
+		return
+	}
+
+	player := queue.GetPipeline()
+	current := queue.Current()
+	oldVC := queue.GetVoiceConnection()
+	if player == nil || current == nil || oldVC == nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", EmbedError)
+		return
+	}
+
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Could not find this server.", EmbedError)
+		return
+	}
+
+	targetID := userVoiceChannelID(guild, m.Author.ID)
+	if targetID == "" {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "You must be in a voice channel to move the bot.", EmbedError)
+		return
+	}
+	if targetID == oldVC.ChannelID {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "I'm already in your voice channel.", EmbedError)
+		return
+	}
+
+	move := &channelMove{
+		player:     player,
+		disconnect: oldVC.Disconnect,
+		connect: func(ctx context.Context) (*discordgo.VoiceConnection, error) {
+			return common.FindAndJoinUserVoiceChannel(s, m.Author.ID, guildID)
+		},
+		attach: queue.SetVoiceConnection,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := pipeline.ReconnectVoiceSession(ctx, move); err != nil {
+		log.Printf("Failed to move playback in guild %s: %v", guildID, err)
+		queue.StopAndCleanup()
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Could not move playback: %v", err), EmbedError)
+		return
+	}
+
+	channelName := "your channel"
+	if channel, err := s.State.Channel(targetID); err == nil {
+		channelName = "**" + channel.Name + "**"
+	}
+	sendEmbedMessage(s, m.ChannelID, "🔀 Moved", fmt.Sprintf("%s moved playback to %s, resuming **%s** at %s.", m.Author.Username, channelName, current.Title, formatDuration(move.Position())), EmbedSuccess)
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePlayer records how a channel move drives the player
+type fakePlayer struct {
+	calls    []string
+	position time.Duration
+	paused   bool
+	vc       *discordgo.VoiceConnection
+}
+
+func (p *fakePlayer) Position() time.Duration { return p.position }
+func (p *fakePlayer) IsPaused() bool          { return p.paused }
+
+func (p *fakePlayer) Pause() error {
+	p.calls = append(p.calls, "pause")
+	p.paused = true
+	return nil
+}
+
+func (p *fakePlayer) Resume() error {
+	p.calls = append(p.calls, "resume")
+	p.paused = false
+	return nil
+}
+
+func (p *fakePlayer) Seek(position time.Duration) error {
+	p.calls = append(p.calls, "seek")
+	p.position = position
+	return nil
+}
+
+func (p *fakePlayer) SetVoiceConnection(vc *discordgo.VoiceConnection) {
+	p.calls = append(p.calls, "set-vc")
+	p.vc = vc
+}
+
+// newTestMove builds a move to sink that records its voice calls in the
+// player's call list
+func newTestMove(player *fakePlayer, sink *discordgo.VoiceConnection, connectErr error) (*channelMove, *[]*discordgo.VoiceConnection) {
+	attached := &[]*discordgo.VoiceConnection{}
+	return &channelMove{
+		player: player,
+		disconnect: func() error {
+			player.calls = append(player.calls, "disconnect")
+			return nil
+		},
+		connect: func(ctx context.Context) (*discordgo.VoiceConnection, error) {
+			player.calls = append(player.calls, "connect")
+			if connectErr != nil {
+				return nil, connectErr
+			}
+			return sink, nil
+		},
+		attach: func(vc *discordgo.VoiceConnection) {
+			*attached = append(*attached, vc)
+		},
+	}, attached
+}
+
+func TestChannelMove_ReconnectsAndResumes(t *testing.T) {
+	player := &fakePlayer{position: 83 * time.Second}
+	sink := &discordgo.VoiceConnection{ChannelID: "new-channel"}
+	move, attached := newTestMove(player, sink, nil)
+
+	require.NoError(t, pipeline.ReconnectVoiceSession(context.Background(), move))
+
+	assert.Equal(t, []string{"pause", "disconnect", "connect", "set-vc", "seek", "resume"}, player.calls)
+	assert.Same(t, sink, player.vc)
+	assert.Equal(t, []*discordgo.VoiceConnection{sink}, *attached)
+	assert.Equal(t, 83*time.Second, player.position)
+	assert.False(t, player.paused)
+}
+
+func TestChannelMove_KeepsPausedTrackPaused(t *testing.T) {
+	player := &fakePlayer{position: 12 * time.Second, paused: true}
+	move, _ := newTestMove(player, &discordgo.VoiceConnection{}, nil)
+
+	require.NoError(t, pipeline.ReconnectVoiceSession(context.Background(), move))
+
+	assert.Equal(t, []string{"disconnect", "connect", "set-vc", "seek"}, player.calls)
+	assert.True(t, player.paused)
+	assert.Equal(t, 12*time.Second, player.position)
+}
+
+func TestChannelMove_JoinFailure(t *testing.T) {
+	player := &fakePlayer{position: 30 * time.Second}
+	joinErr := errors.New("you must be in a voice channel to play music")
+	move, attached := newTestMove(player, nil, joinErr)
+
+	err := pipeline.ReconnectVoiceSession(context.Background(), move)
+	require.ErrorIs(t, err, joinErr)
+
+	assert.Equal(t, []string{"pause", "disconnect", "connect"}, player.calls)
+	assert.Nil(t, player.vc)
+	assert.Empty(t, *attached)
+}
@@ -39,15 +39,7 @@ func NowPlayingCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 
 // sendNothingPlayingEmbed sends an embed when nothing is playing
 func sendNothingPlayingEmbed(s *discordgo.Session, channelID string) {
-	embed := &discordgo.MessageEmbed{
-		Title:       "🎵 Now Playing",
-		Description: "Nothing is currently playing",
-		Color:       0x808080, // Gray color
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Use /play to start playing music",
-		},
-	}
+	embed := theme().NewEmbed(EmbedNeutral, "🎵 Now Playing", "Nothing is currently playing", "Use /play to start playing music")
 
 	s.ChannelMessageSendEmbed(channelID, embed)
 }
@@ -77,35 +69,27 @@ func sendNowPlayingEmbed(s *discordgo.Session, channelID string, item *common.Qu
 		statusText = "Stopped"
 	}
 
-	embed := &discordgo.MessageEmbed{
-		Title:       "🎵 Now Playing",
-		Description: description,
-		Color:       0x00ff00, // Green color
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Requested by",
-				Value:  item.RequestedBy,
-				Inline: true,
-			},
-			{
-				Name:   "Duration",
-				Value:  durationStr,
-				Inline: true,
-			},
-			{
-				Name:   "Status",
-				Value:  fmt.Sprintf("%s %s", statusEmoji, statusText),
-				Inline: true,
-			},
-			{
-				Name:   "Added to queue",
-				Value:  item.AddedAt.Format("Jan 2, 2006 3:04 PM"),
-				Inline: false,
-			},
+	embed := theme().NewEmbed(EmbedSuccess, "🎵 Now Playing", description, "")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Requested by",
+			Value:  item.RequestedBy,
+			Inline: true,
+		},
+		{
+			Name:   "Duration",
+			Value:  durationStr,
+			Inline: true,
+		},
+		{
+			Name:   "Status",
+			Value:  fmt.Sprintf("%s %s", statusEmoji, statusText),
+			Inline: true,
 		},
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+		{
+			Name:   "Added to queue",
+			Value:  item.AddedAt.Format("Jan 2, 2006 3:04 PM"),
+			Inline: false,
 		},
 	}
 
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// userVoiceChannelID returns the voice channel the user is connected to in the guild
+func userVoiceChannelID(guild *discordgo.Guild, userID string) string {
+	if guild == nil {
+		return ""
+	}
+
+	for _, vs := range guild.VoiceStates {
+		if vs.UserID == userID {
+			return vs.ChannelID
+		}
+	}
+
+	return ""
+}
+
+// checkSameVoiceChannel verifies that the user is in the bot's voice channel
+func checkSameVoiceChannel(guild *discordgo.Guild, userID, botChannelID string) error {
+	if botChannelID == "" {
+		return errors.New("the bot is not connected to a voice channel")
+	}
+
+	userChannelID := userVoiceChannelID(guild, userID)
+	if userChannelID == "" {
+		return errors.New("you must be in a voice channel to use this command")
+	}
+
+	if userChannelID != botChannelID {
+		return errors.New("you must be in the same voice channel as the bot to use this command")
+	}
+
+	return nil
+}
+
+// requireSameVoiceChannel checks the voice channel gate for playback controls
+// and sends an error embed when the invoking user is not allowed.
+func requireSameVoiceChannel(s *discordgo.Session, m *discordgo.MessageCreate, queue *common.MusicQueue) bool {
+	guild, err := s.State.Guild(m.GuildID)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Could not find this server.", EmbedError)
+		return false
+	}
+
+	var botChannelID string
+	if vc := queue.GetVoiceConnection(); vc != nil {
+		botChannelID = vc.ChannelID
+	} else if s.State.User != nil {
+		botChannelID = userVoiceChannelID(guild, s.State.User.ID)
+	}
+
+	if err := checkSameVoiceChannel(guild, m.Author.ID, botChannelID); err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSameVoiceChannel(t *testing.T) {
+	guild := &discordgo.Guild{
+		ID: "guild",
+		VoiceStates: []*discordgo.VoiceState{
+			{UserID: "listener", ChannelID: "music"},
+			{UserID: "elsewhere", ChannelID: "afk"},
+		},
+	}
+
+	assert.NoError(t, checkSameVoiceChannel(guild, "listener", "music"))
+	assert.Error(t, checkSameVoiceChannel(guild, "elsewhere", "music"))
+	assert.Error(t, checkSameVoiceChannel(guild, "not-in-voice", "music"))
+	assert.Error(t, checkSameVoiceChannel(guild, "listener", ""))
+	assert.Error(t, checkSameVoiceChannel(nil, "listener", "music"))
+}
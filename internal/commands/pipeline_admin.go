@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/database"
+)
+
+// metricsMaintainer is the part of database.MetricsRepository used by the
+// pipeline admin commands
+type metricsMaintainer interface {
+	GetBatchProcessorStats() (*database.BatchProcessorStats, error)
+	FlushPendingMetrics() error
+	RunRetentionCleanup(ctx context.Context) (*database.RetentionStats, error)
+}
+
+// databaseMaintainer is the part of database.Database used by `!pipeline maintain`
+type databaseMaintainer interface {
+	Maintain(ctx context.Context) error
+}
+
+// errMetricsNotInitialized is reported when no metrics repository is configured
+var errMetricsNotInitialized = errors.New("metrics repository not initialized")
+
+// errDatabaseNotInitialized is reported when no cache database is configured
+var errDatabaseNotInitialized = errors.New("database not initialized")
+
+var (
+	metricsRepo   database.MetricsRepository
+	metricsRepoMu sync.RWMutex
+)
+
+// SetMetricsRepository sets the repository used by `!pipeline` and `!history`
+func SetMetricsRepository(repo database.MetricsRepository) {
+	metricsRepoMu.Lock()
+	defer metricsRepoMu.Unlock()
+	metricsRepo = repo
+}
+
+// getMetricsRepository returns the configured metrics repository, if any
+func getMetricsRepository() database.MetricsRepository {
+	metricsRepoMu.RLock()
+	defer metricsRepoMu.RUnlock()
+	return metricsRepo
+}
+
+// PipelineCommand handles pipeline maintenance commands (bot owner only)
+func PipelineCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	// Check if the user is the bot owner
+	ownerID := os.Getenv("BOT_OWNER_ID")
+	if ownerID == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌ Bot owner ID not configured.")
+		return
+	}
+
+	if m.Author.ID != ownerID {
+		s.ChannelMessageSend(m.ChannelID, "❌ You don't have permission to use this command.")
+		return
+	}
+
+	if len(args) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "❌ Usage: `!pipeline <flush|cleanup|maintain>`")
+		return
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "flush":
+		s.ChannelMessageSendEmbed(m.ChannelID, flushMetricsEmbed(getMetricsRepository()))
+	case "cleanup":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		s.ChannelMessageSendEmbed(m.ChannelID, retentionCleanupEmbed(ctx, getMetricsRepository()))
+	case "maintain":
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		var db databaseMaintainer
+		if umaDB != nil {
+			db = umaDB
+		}
+		s.ChannelMessageSendEmbed(m.ChannelID, databaseMaintenanceEmbed(ctx, db))
+	default:
+		s.ChannelMessageSend(m.ChannelID, "❌ Usage: `!pipeline <flush|cleanup|maintain>`")
+	}
+}
+
+// flushMetricsEmbed forces buffered metrics to be written and reports how many were flushed
+func flushMetricsEmbed(repo metricsMaintainer) *discordgo.MessageEmbed {
+	if repo == nil {
+		return pipelineAdminErrorEmbed("❌ Metrics Flush Failed", errMetricsNotInitialized)
+	}
+
+	before, err := repo.GetBatchProcessorStats()
+	if err != nil {
+		return pipelineAdminErrorEmbed("❌ Metrics Flush Failed", err)
+	}
+
+	start := time.Now()
+	if err := repo.FlushPendingMetrics(); err != nil {
+		return pipelineAdminErrorEmbed("❌ Metrics Flush Failed", err)
+	}
+	elapsed := time.Since(start)
+
+	// Metrics still buffered after the flush; a successful flush empties the
+	// buffer, so without fresh stats assume it did. Metrics buffered since
+	// aren't counted as flushed.
+	pending := 0
+	if after, err := repo.GetBatchProcessorStats(); err == nil {
+		pending = after.BufferSize
+	}
+	flushed := before.BufferSize - pending
+	if flushed < 0 {
+		flushed = 0
+	}
+
+	embed := theme().NewEmbed(EmbedSuccess, "✅ Metrics Flushed", "Buffered metrics were handed to the batch writer.", "Pipeline Maintenance")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "📤 Flushed", Value: fmt.Sprintf("%d", flushed), Inline: true},
+		{Name: "📥 Still Queued", Value: fmt.Sprintf("%d", pending), Inline: true},
+		{Name: "⏱️ Duration", Value: elapsed.Round(time.Millisecond).String(), Inline: true},
+	}
+	return embed
+}
+
+// retentionCleanupEmbed runs the retention policies and reports what was removed
+func retentionCleanupEmbed(ctx context.Context, repo metricsMaintainer) *discordgo.MessageEmbed {
+	if repo == nil {
+		return pipelineAdminErrorEmbed("❌ Retention Cleanup Failed", errMetricsNotInitialized)
+	}
+
+	start := time.Now()
+	stats, err := repo.RunRetentionCleanup(ctx)
+	if err != nil {
+		return pipelineAdminErrorEmbed("❌ Retention Cleanup Failed", err)
+	}
+	elapsed := time.Since(start)
+
+	var cleaned int64
+	for _, result := range stats.LastPolicyResults {
+		cleaned += result.RecordsCleaned
+	}
+
+	embed := theme().NewEmbed(EmbedSuccess, "🧹 Retention Cleanup Complete", "Retention policies were applied to the metrics tables.", "Pipeline Maintenance")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "🗑️ Records Cleaned", Value: fmt.Sprintf("%d", cleaned), Inline: true},
+		{Name: "📋 Policies Run", Value: fmt.Sprintf("%d", len(stats.LastPolicyResults)), Inline: true},
+		{Name: "⏱️ Duration", Value: elapsed.Round(time.Millisecond).String(), Inline: true},
+	}
+
+	var failed []string
+	for name, result := range stats.LastPolicyResults {
+		if result.Error != "" {
+			failed = append(failed, fmt.Sprintf("• %s: %s", name, result.Error))
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		embed.Color = theme().Color(EmbedWarning)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "⚠️ Failed Policies",
+			Value: strings.Join(failed, "\n"),
+		})
+	}
+	return embed
+}
+
+// databaseMaintenanceEmbed analyzes the database and reports how long it took
+func databaseMaintenanceEmbed(ctx context.Context, db databaseMaintainer) *discordgo.MessageEmbed {
+	if db == nil {
+		return pipelineAdminErrorEmbed("❌ Database Maintenance Failed", errDatabaseNotInitialized)
+	}
+
+	start := time.Now()
+	if err := db.Maintain(ctx); err != nil {
+		return pipelineAdminErrorEmbed("❌ Database Maintenance Failed", err)
+	}
+	elapsed := time.Since(start)
+
+	embed := theme().NewEmbed(EmbedSuccess, "🛠️ Database Maintenance Complete", "Query planner statistics were refreshed.", "Pipeline Maintenance")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "⏱️ Duration", Value: elapsed.Round(time.Millisecond).String(), Inline: true},
+	}
+	return embed
+}
+
+// pipelineAdminErrorEmbed reports a failed maintenance command
+func pipelineAdminErrorEmbed(title string, err error) *discordgo.MessageEmbed {
+	return theme().NewEmbed(EmbedError, title, err.Error(), "Pipeline Maintenance")
+}
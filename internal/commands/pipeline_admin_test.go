@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMetricsRepo implements metricsMaintainer with canned results; once
+// flushed it reports afterStats, if set, instead of stats
+type stubMetricsRepo struct {
+	stats      *database.BatchProcessorStats
+	afterStats *database.BatchProcessorStats
+	statsErr   error
+	flushErr   error
+	retention  *database.RetentionStats
+	cleanupErr error
+	flushed    int
+}
+
+func (r *stubMetricsRepo) GetBatchProcessorStats() (*database.BatchProcessorStats, error) {
+	if r.flushed > 0 && r.afterStats != nil {
+		return r.afterStats, r.statsErr
+	}
+	return r.stats, r.statsErr
+}
+
+func (r *stubMetricsRepo) FlushPendingMetrics() error {
+	r.flushed++
+	return r.flushErr
+}
+
+func (r *stubMetricsRepo) RunRetentionCleanup(ctx context.Context) (*database.RetentionStats, error) {
+	return r.retention, r.cleanupErr
+}
+
+func fieldValue(t *testing.T, fields map[string]string, name string) string {
+	t.Helper()
+	value, ok := fields[name]
+	require.True(t, ok, "missing field %q", name)
+	return value
+}
+
+func TestFlushMetricsEmbed(t *testing.T) {
+	repo := &stubMetricsRepo{
+		stats:      &database.BatchProcessorStats{BufferSize: 42, MetricBufferSize: 7},
+		afterStats: &database.BatchProcessorStats{BufferSize: 3, MetricBufferSize: 9, QueueSize: 1},
+	}
+
+	embed := flushMetricsEmbed(repo)
+	assert.Equal(t, 1, repo.flushed)
+	assert.Equal(t, theme().Color(EmbedSuccess), embed.Color)
+
+	fields := make(map[string]string)
+	for _, field := range embed.Fields {
+		fields[field.Name] = field.Value
+	}
+	assert.Equal(t, "39", fieldValue(t, fields, "📤 Flushed"), "flushed is the buffer's drop across the flush")
+	assert.Equal(t, "3", fieldValue(t, fields, "📥 Still Queued"))
+	assert.NotEmpty(t, fieldValue(t, fields, "⏱️ Duration"))
+}
+
+func TestFlushMetricsEmbedBufferGrew(t *testing.T) {
+	repo := &stubMetricsRepo{
+		stats:      &database.BatchProcessorStats{BufferSize: 2},
+		afterStats: &database.BatchProcessorStats{BufferSize: 5},
+	}
+
+	fields := make(map[string]string)
+	for _, field := range flushMetricsEmbed(repo).Fields {
+		fields[field.Name] = field.Value
+	}
+	assert.Equal(t, "0", fieldValue(t, fields, "📤 Flushed"))
+	assert.Equal(t, "5", fieldValue(t, fields, "📥 Still Queued"))
+}
+
+func TestFlushMetricsEmbedErrors(t *testing.T) {
+	embed := flushMetricsEmbed(nil)
+	assert.Equal(t, theme().Color(EmbedError), embed.Color)
+	assert.Equal(t, errMetricsNotInitialized.Error(), embed.Description)
+
+	repo := &stubMetricsRepo{statsErr: errors.New("batch processor not initialized")}
+	embed = flushMetricsEmbed(repo)
+	assert.Equal(t, "batch processor not initialized", embed.Description)
+	assert.Zero(t, repo.flushed, "flush is not attempted without a batch processor")
+
+	repo = &stubMetricsRepo{stats: &database.BatchProcessorStats{}, flushErr: errors.New("flush timeout")}
+	embed = flushMetricsEmbed(repo)
+	assert.Equal(t, theme().Color(EmbedError), embed.Color)
+	assert.Equal(t, "flush timeout", embed.Description)
+}
+
+func TestRetentionCleanupEmbed(t *testing.T) {
+	repo := &stubMetricsRepo{retention: &database.RetentionStats{
+		LastPolicyResults: map[string]*database.PolicyResult{
+			"old_metrics": {PolicyName: "old_metrics", RecordsCleaned: 120},
+			"old_events":  {PolicyName: "old_events", RecordsCleaned: 30},
+		},
+	}}
+
+	embed := retentionCleanupEmbed(context.Background(), repo)
+	assert.Equal(t, theme().Color(EmbedSuccess), embed.Color)
+
+	fields := make(map[string]string)
+	for _, field := range embed.Fields {
+		fields[field.Name] = field.Value
+	}
+	assert.Equal(t, "150", fieldValue(t, fields, "🗑️ Records Cleaned"))
+	assert.Equal(t, "2", fieldValue(t, fields, "📋 Policies Run"))
+	assert.NotContains(t, fields, "⚠️ Failed Policies")
+}
+
+func TestRetentionCleanupEmbedPolicyFailure(t *testing.T) {
+	repo := &stubMetricsRepo{retention: &database.RetentionStats{
+		LastPolicyResults: map[string]*database.PolicyResult{
+			"old_sessions": {PolicyName: "old_sessions", Error: "database is locked"},
+		},
+	}}
+
+	embed := retentionCleanupEmbed(context.Background(), repo)
+	assert.Equal(t, theme().Color(EmbedWarning), embed.Color)
+	require.Len(t, embed.Fields, 4)
+	assert.Contains(t, embed.Fields[3].Value, "old_sessions: database is locked")
+}
+
+func TestRetentionCleanupEmbedErrors(t *testing.T) {
+	embed := retentionCleanupEmbed(context.Background(), nil)
+	assert.Equal(t, errMetricsNotInitialized.Error(), embed.Description)
+
+	embed = retentionCleanupEmbed(context.Background(), &stubMetricsRepo{cleanupErr: errors.New("retention manager not initialized")})
+	assert.Equal(t, theme().Color(EmbedError), embed.Color)
+	assert.Equal(t, "retention manager not initialized", embed.Description)
+}
+
+// stubMaintainer implements databaseMaintainer
+type stubMaintainer struct {
+	err   error
+	calls int
+}
+
+func (m *stubMaintainer) Maintain(ctx context.Context) error {
+	m.calls++
+	return m.err
+}
+
+func TestDatabaseMaintenanceEmbed(t *testing.T) {
+	db := &stubMaintainer{}
+	embed := databaseMaintenanceEmbed(context.Background(), db)
+	assert.Equal(t, 1, db.calls)
+	assert.Equal(t, theme().Color(EmbedSuccess), embed.Color)
+	require.Len(t, embed.Fields, 1)
+	assert.Equal(t, "⏱️ Duration", embed.Fields[0].Name)
+
+	embed = databaseMaintenanceEmbed(context.Background(), &stubMaintainer{err: errors.New("database is locked")})
+	assert.Equal(t, theme().Color(EmbedError), embed.Color)
+	assert.Contains(t, embed.Description, "database is locked")
+
+	embed = databaseMaintenanceEmbed(context.Background(), nil)
+	assert.Contains(t, embed.Description, errDatabaseNotInitialized.Error())
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/database"
+)
+
+// pipelineSessionStore is the part of database.SessionManager used to record
+// each track's pipeline as a session
+type pipelineSessionStore interface {
+	CreateSession(ctx context.Context, session *database.PipelineSession) error
+	UpdateSession(ctx context.Context, sessionID string, updates *database.SessionUpdate) error
+	EndSessionWithQuality(ctx context.Context, sessionID, finalState string, inputs database.SessionQualityInputs) (float64, error)
+}
+
+var (
+	sessionStore   pipelineSessionStore
+	sessionStoreMu sync.RWMutex
+)
+
+// SetSessionManager sets where playback sessions are recorded. Each track's
+// pipeline becomes a session, and its error and recovery events are stored
+// under it for `!history errors`.
+func SetSessionManager(manager *database.SessionManager) {
+	sessionStoreMu.Lock()
+	defer sessionStoreMu.Unlock()
+	if manager == nil {
+		sessionStore = nil
+		return
+	}
+	sessionStore = manager
+}
+
+// getSessionStore returns the configured session store, if any
+func getSessionStore() pipelineSessionStore {
+	sessionStoreMu.RLock()
+	defer sessionStoreMu.RUnlock()
+	return sessionStore
+}
+
+// pipelineSession is one track's pipeline as recorded in the metrics
+// database. It sits between the pipeline and the event recorder, counting
+// errors for the session row.
+type pipelineSession struct {
+	id       string
+	store    pipelineSessionStore
+	pipeline *common.AudioPipeline
+	events   common.EventSink
+	errors   int64
+}
+
+// startPipelineSession records a session for the pipeline about to play item
+// and routes the pipeline's events to it. It returns nil when sessions are
+// not being recorded.
+func startPipelineSession(guildID, channelID string, item *common.QueueItem, pipeline *common.AudioPipeline) *pipelineSession {
+	store := getSessionStore()
+	if store == nil {
+		return nil
+	}
+
+	streamURL := item.OriginalURL
+	if streamURL == "" {
+		streamURL = item.URL
+	}
+
+	session := &pipelineSession{
+		id:       fmt.Sprintf("pipeline-%d", time.Now().UnixNano()),
+		store:    store,
+		pipeline: pipeline,
+	}
+	if repo := getMetricsRepository(); repo != nil {
+		session.events = database.NewPipelineRecorder(repo, session.id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := store.CreateSession(ctx, &database.PipelineSession{
+		PipelineID: session.id,
+		GuildID:    guildID,
+		ChannelID:  channelID,
+		UserID:     item.RequestedBy,
+		StreamURL:  streamURL,
+		StartedAt:  time.Now(),
+	}); err != nil {
+		log.Printf("Failed to record pipeline session: %v", err)
+		return nil
+	}
+
+	pipeline.SetEventSink(session)
+	return session
+}
+
+// RecordEvent counts errors and passes the event on to the recorder
+func (ps *pipelineSession) RecordEvent(eventType, severity string, data map[string]interface{}) {
+	if eventType == "error" {
+		atomic.AddInt64(&ps.errors, 1)
+	}
+	if ps.events != nil {
+		ps.events.RecordEvent(eventType, severity, data)
+	}
+}
+
+// end records how the session's pipeline ended and scores its quality. It
+// does nothing on a nil session, so callers need not check whether sessions
+// are recorded.
+func (ps *pipelineSession) end(outcome common.Outcome) {
+	if ps == nil {
+		return
+	}
+
+	errors := int(atomic.LoadInt64(&ps.errors))
+	recoveries := outcome.Recoveries
+	quality := ps.pipeline.PlaybackQuality()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := ps.store.UpdateSession(ctx, ps.id, &database.SessionUpdate{
+		TotalErrors:     &errors,
+		TotalRecoveries: &recoveries,
+	}); err != nil {
+		log.Printf("Failed to update pipeline session %s: %v", ps.id, err)
+	}
+
+	score, err := ps.store.EndSessionWithQuality(ctx, ps.id, string(outcome.Reason), database.SessionQualityInputs{
+		Duration:            outcome.Elapsed,
+		Underruns:           int(quality.Underruns),
+		Reconnects:          int(quality.Reconnects),
+		Recoveries:          recoveries,
+		AvgFrameSendLatency: quality.AvgFrameSendLatency,
+	})
+	if err != nil {
+		log.Printf("Failed to end pipeline session %s: %v", ps.id, err)
+		return
+	}
+	log.Printf("Pipeline session %s ended (%s), quality %.1f", ps.id, outcome.Reason, score)
+}
+
+// fail records a pipeline that could not start playing at all
+func (ps *pipelineSession) fail(err error) {
+	if ps == nil {
+		return
+	}
+	ps.RecordEvent("error", "high", map[string]interface{}{"error": err.Error()})
+	ps.end(common.Outcome{Reason: common.OutcomeError, Err: err})
+}
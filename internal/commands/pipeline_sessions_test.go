@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSessionStore implements pipelineSessionStore, keeping what it was given
+type stubSessionStore struct {
+	created []*database.PipelineSession
+	updates map[string]*database.SessionUpdate
+	ended   map[string]string
+	inputs  map[string]database.SessionQualityInputs
+}
+
+func (s *stubSessionStore) CreateSession(ctx context.Context, session *database.PipelineSession) error {
+	s.created = append(s.created, session)
+	return nil
+}
+
+func (s *stubSessionStore) UpdateSession(ctx context.Context, sessionID string, updates *database.SessionUpdate) error {
+	if s.updates == nil {
+		s.updates = make(map[string]*database.SessionUpdate)
+	}
+	s.updates[sessionID] = updates
+	return nil
+}
+
+func (s *stubSessionStore) EndSessionWithQuality(ctx context.Context, sessionID, finalState string, inputs database.SessionQualityInputs) (float64, error) {
+	if s.ended == nil {
+		s.ended = make(map[string]string)
+		s.inputs = make(map[string]database.SessionQualityInputs)
+	}
+	s.ended[sessionID] = finalState
+	s.inputs[sessionID] = inputs
+	return database.ScoreSessionQuality(inputs, database.DefaultQualityWeights()), nil
+}
+
+// withSessionStore installs store for the duration of a test
+func withSessionStore(t *testing.T, store pipelineSessionStore) {
+	sessionStoreMu.Lock()
+	previous := sessionStore
+	sessionStore = store
+	sessionStoreMu.Unlock()
+
+	t.Cleanup(func() {
+		sessionStoreMu.Lock()
+		sessionStore = previous
+		sessionStoreMu.Unlock()
+	})
+}
+
+func TestPipelineSession_RecordsStartAndEnd(t *testing.T) {
+	store := &stubSessionStore{}
+	withSessionStore(t, store)
+
+	item := &common.QueueItem{URL: "https://stream.example/a", OriginalURL: "https://youtu.be/a", RequestedBy: "user-1"}
+	session := startPipelineSession("guild-1", "voice-1", item, common.NewAudioPipeline(nil))
+	require.NotNil(t, session)
+	require.Len(t, store.created, 1)
+
+	created := store.created[0]
+	assert.Equal(t, session.id, created.PipelineID)
+	assert.Equal(t, "guild-1", created.GuildID)
+	assert.Equal(t, "voice-1", created.ChannelID)
+	assert.Equal(t, "https://youtu.be/a", created.StreamURL)
+
+	session.RecordEvent("error", "medium", map[string]interface{}{"error": "timeout"})
+	session.RecordEvent("recovery", "low", nil)
+	session.end(common.Outcome{Reason: common.OutcomeCompleted, Recoveries: 1, Elapsed: 3 * time.Minute})
+
+	update := store.updates[session.id]
+	require.NotNil(t, update)
+	assert.Equal(t, 1, *update.TotalErrors)
+	assert.Equal(t, 1, *update.TotalRecoveries)
+
+	// The session is closed with its quality inputs
+	assert.Equal(t, "completed", store.ended[session.id])
+	inputs := store.inputs[session.id]
+	assert.Equal(t, 3*time.Minute, inputs.Duration)
+	assert.Equal(t, 1, inputs.Recoveries)
+	assert.Zero(t, inputs.Underruns)
+}
+
+func TestPipelineSession_Fail(t *testing.T) {
+	store := &stubSessionStore{}
+	withSessionStore(t, store)
+
+	session := startPipelineSession("guild-1", "voice-1", &common.QueueItem{URL: "https://stream.example/a"}, common.NewAudioPipeline(nil))
+	require.NotNil(t, session)
+	session.fail(errors.New("ffmpeg not found"))
+
+	update := store.updates[session.id]
+	require.NotNil(t, update)
+	assert.Equal(t, 1, *update.TotalErrors)
+	assert.Equal(t, "error", store.ended[session.id])
+}
+
+func TestPipelineSession_Disabled(t *testing.T) {
+	withSessionStore(t, nil)
+
+	session := startPipelineSession("guild-1", "voice-1", &common.QueueItem{}, common.NewAudioPipeline(nil))
+	assert.Nil(t, session)
+
+	// Ending a nil session is a no-op
+	session.end(common.Outcome{Reason: common.OutcomeCompleted})
+	session.fail(errors.New("boom"))
+}
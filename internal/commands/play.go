@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -9,18 +10,56 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
 )
 
 var (
 	// Global pipeline manager to track active streams
 	activePipelines = make(map[string]*common.AudioPipeline)
 	pipelineMutex   sync.RWMutex
+
+	// Hosts sources may be streamed from; nil allows everything
+	hostPolicy *pipeline.HostPolicy
 )
 
+// SetHostPolicy sets the allowlist/blocklist checked before resolving sources
+func SetHostPolicy(policy *pipeline.HostPolicy) {
+	hostPolicy = policy
+}
+
+// resolveSource resolves the stream of a source after checking its host
+// against the host policy, so disallowed sources never reach yt-dlp or
+// ffmpeg. Every command, replay and prefetch path resolves through here.
+func resolveSource(sourceURL string) (streamURL, title string, duration time.Duration, chapters []common.Chapter, err error) {
+	if err := hostPolicy.CheckURL(sourceURL); err != nil {
+		log.Printf("Rejected source %s: %v", sourceURL, err)
+		return "", "", 0, nil, err
+	}
+	return common.GetYouTubeAudioStreamWithChapters(sourceURL)
+}
+
+// reportSourceError tells the channel why resolveSource failed: a rejected
+// host is named, anything else is reported with failure as the description
+func reportSourceError(s *discordgo.Session, channelID string, err error, failure string) {
+	if !errors.Is(err, pipeline.ErrHostNotAllowed) {
+		sendEmbedMessage(s, channelID, "❌ Error", failure, EmbedError)
+		return
+	}
+
+	host := "this host"
+	var pipelineErr *pipeline.PipelineError
+	if errors.As(err, &pipelineErr) {
+		if h, ok := pipelineErr.Context["host"].(string); ok && h != "" {
+			host = "`" + h + "`"
+		}
+	}
+	sendEmbedMessage(s, channelID, "🚫 Source Not Allowed", fmt.Sprintf("Streaming from %s is not allowed on this bot.", host), EmbedError)
+}
+
 // PlayCommand handles the play command with queue integration
 func PlayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
 	if len(args) < 1 {
-		sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Please provide a YouTube URL or search query.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Please provide a YouTube URL or search query.", EmbedError)
 		return
 	}
 
@@ -32,20 +71,22 @@ func PlayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string
 	input := args[0]
 	var url, title string
 	var duration time.Duration
+	var chapters []common.Chapter
 	var videoURL string // Store the video URL for search results
 
 	// Check if input is a URL or search query
 	if common.IsURL(input) {
 		// Input is a URL, use existing logic
-		streamURL, streamTitle, streamDuration, err := common.GetYouTubeAudioStreamWithMetadata(input)
+		streamURL, streamTitle, streamDuration, streamChapters, err := resolveSource(input)
 		if err != nil {
 			log.Printf("Error fetching stream URL: %v", err)
-			sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to get audio stream. Please check the URL.", 0xff0000)
+			reportSourceError(s, m.ChannelID, err, "Failed to get audio stream. Please check the URL.")
 			return
 		}
 		url = streamURL
 		title = streamTitle
 		duration = streamDuration
+		chapters = streamChapters
 		videoURL = input // For direct URLs, use the input as video URL
 	} else {
 		// Input is a search query, search YouTube and get the first result
@@ -56,21 +97,22 @@ func PlayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string
 		foundVideoURL, _, _, searchErr := common.SearchYouTubeAndGetURL(searchQuery)
 		if searchErr != nil {
 			log.Printf("Error searching YouTube: %v", searchErr)
-			sendEmbedMessage(s, m.ChannelID, "❌ Search Error", "Failed to find any videos for your search query.", 0xff0000)
+			sendEmbedMessage(s, m.ChannelID, "❌ Search Error", "Failed to find any videos for your search query.", EmbedError)
 			return
 		}
 
 		// Now get the audio stream from the found video URL
-		streamURL, streamTitle, streamDuration, streamErr := common.GetYouTubeAudioStreamWithMetadata(foundVideoURL)
+		streamURL, streamTitle, streamDuration, streamChapters, streamErr := resolveSource(foundVideoURL)
 		if streamErr != nil {
 			log.Printf("Error fetching stream URL from search result: %v", streamErr)
-			sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to get audio stream from search result.", 0xff0000)
+			reportSourceError(s, m.ChannelID, streamErr, "Failed to get audio stream from search result.")
 			return
 		}
 
 		url = streamURL
 		title = streamTitle
 		duration = streamDuration
+		chapters = streamChapters
 		videoURL = foundVideoURL // Store the found video URL
 	}
 
@@ -86,7 +128,7 @@ func PlayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string
 		videoID = common.ExtractYouTubeVideoID(videoURL)
 		originalURL = videoURL
 		// Use the new method for YouTube videos
-		queue.AddWithYouTubeData(url, originalURL, videoID, title, m.Author.Username, duration)
+		queue.AddWithYouTubeData(url, originalURL, videoID, title, m.Author.Username, duration, chapters...)
 	} else {
 		// Use the original method for non-YouTube URLs
 		queue.Add(url, title, m.Author.Username)
@@ -95,13 +137,12 @@ func PlayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string
 	// Send confirmation with embed
 	queueSize := queue.Size()
 	description := fmt.Sprintf("✅ Added **%s** to queue (Position: %d)", title, queueSize)
-	sendEmbedMessage(s, m.ChannelID, "🎵 Song Added", description, 0x00ff00)
+	sendEmbedMessage(s, m.ChannelID, "🎵 Song Added", description, EmbedSuccess)
 
-	// Check if we should start playing - only if the queue can start playing
-	if queue.CanStartPlaying() {
-		log.Printf("Starting playback for guild %s - queue size: %d, isPlaying: %v, hasPipeline: %v",
-			guildID, queueSize, queue.IsPlaying(), queue.HasActivePipeline())
-		startNextInQueue(s, m, queue)
+	// Start playing unless this guild already is; then the song just waits
+	// in the existing session's queue
+	if startIfIdle(s, m, queue) {
+		log.Printf("Started playback for guild %s - queue size: %d", guildID, queueSize)
 	} else {
 		log.Printf("Song added to queue for guild %s but not starting playback - queue size: %d, isPlaying: %v, hasPipeline: %v",
 			guildID, queueSize, queue.IsPlaying(), queue.HasActivePipeline())
@@ -117,9 +158,9 @@ func StatusCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	pipelineMutex.RUnlock()
 
 	if !exists || !pipeline.IsPlaying() {
-		sendEmbedMessage(s, m.ChannelID, "🔇 No Audio", "No audio is currently playing.", 0x808080)
+		sendEmbedMessage(s, m.ChannelID, "🔇 No Audio", "No audio is currently playing.", EmbedNeutral)
 		return
 	}
 
-	sendEmbedMessage(s, m.ChannelID, "🎵 Audio Playing", "Audio is currently playing.", 0x00ff00)
+	sendEmbedMessage(s, m.ChannelID, "🎵 Audio Playing", "Audio is currently playing.", EmbedSuccess)
 }
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+const (
+	// playbackReadyFrames is how many frames must reach the voice connection
+	// before a track is announced: half a second of audio
+	playbackReadyFrames = 25
+
+	// playbackReadyTimeout is how long a started track has to deliver
+	// playbackReadyFrames before it is treated as failed
+	playbackReadyTimeout = 10 * time.Second
+)
+
+// awaitPlaybackReady waits until a started pipeline has delivered frames
+// frames to the voice connection. If they don't arrive within timeout the
+// pipeline is stopped with common.ErrNoAudioFrames, so the track is reported
+// as failed rather than announced. It returns nil once the track can be
+// announced.
+func awaitPlaybackReady(pipeline *common.AudioPipeline, frames int64, timeout time.Duration) error {
+	err := pipeline.WaitForFrames(frames, timeout)
+	if errors.Is(err, common.ErrNoAudioFrames) {
+		log.Printf("Playback never became ready: %v", err)
+		pipeline.StopWithError(err)
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startWith starts a pipeline that plays through the given streamer
+func startWith(t *testing.T, streamer func(pipeline *common.AudioPipeline) common.Streamer) *common.AudioPipeline {
+	t.Helper()
+	pipeline := common.NewAudioPipeline(nil)
+	pipeline.SetStreamer(streamer(pipeline))
+	require.NoError(t, pipeline.PlayStream("https://stream.example/track"))
+	t.Cleanup(pipeline.Stop)
+	return pipeline
+}
+
+func TestPlaybackReadyFailsWhenFramesAreDropped(t *testing.T) {
+	// Runs like a healthy stream but its frames never reach the voice
+	// connection, as when the sink silently drops them
+	pipeline := startWith(t, func(*common.AudioPipeline) common.Streamer {
+		return func(ctx context.Context, streamURL string) error {
+			<-ctx.Done()
+			return nil
+		}
+	})
+
+	err := awaitPlaybackReady(pipeline, 5, 100*time.Millisecond)
+	assert.ErrorIs(t, err, common.ErrNoAudioFrames)
+	assert.True(t, pipeline.LastFrameAt().IsZero())
+
+	// The pipeline is failed so the monitor reports and skips the track
+	require.Eventually(t, func() bool { return !pipeline.IsPlaying() }, time.Second, 5*time.Millisecond)
+	outcome := pipeline.LastOutcome()
+	assert.Equal(t, common.OutcomeError, outcome.Reason)
+	assert.ErrorIs(t, outcome.Err, common.ErrNoAudioFrames)
+}
+
+func TestPlaybackReadyOnceFramesFlow(t *testing.T) {
+	pipeline := startWith(t, func(pipeline *common.AudioPipeline) common.Streamer {
+		return func(ctx context.Context, streamURL string) error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(time.Millisecond):
+					pipeline.RecordFrame()
+				}
+			}
+		}
+	})
+
+	require.NoError(t, awaitPlaybackReady(pipeline, 5, time.Second))
+	assert.GreaterOrEqual(t, pipeline.FramesDelivered(), int64(5))
+	assert.False(t, pipeline.LastFrameAt().IsZero())
+	assert.True(t, pipeline.IsPlaying())
+}
+
+func TestPlaybackReadyWhenStoppedFirst(t *testing.T) {
+	pipeline := startWith(t, func(*common.AudioPipeline) common.Streamer {
+		return func(ctx context.Context, streamURL string) error {
+			<-ctx.Done()
+			return nil
+		}
+	})
+	pipeline.Stop()
+
+	// A skip during the wait isn't a playback failure
+	err := awaitPlaybackReady(pipeline, 5, time.Second)
+	assert.ErrorIs(t, err, common.ErrPipelineStopped)
+	assert.Equal(t, common.OutcomeUserStopped, pipeline.LastOutcome().Reason)
+}
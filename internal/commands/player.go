@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// ErrNoPlayer is returned by PlayerSnapshot for a guild with no queue
+var ErrNoPlayer = errors.New("no player for this server")
+
+// PlayerSnapshot returns a read-only view of a guild's player for panels
+// and APIs: the current track, pending tracks and pipeline state, all taken
+// at the same moment
+func PlayerSnapshot(guildID string) (*common.Player, error) {
+	queue := getQueue(guildID)
+	if queue == nil {
+		return nil, ErrNoPlayer
+	}
+	return queue.Snapshot(), nil
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayerSnapshot(t *testing.T) {
+	const guildID = "player-snapshot-guild"
+
+	_, err := PlayerSnapshot(guildID)
+	assert.ErrorIs(t, err, ErrNoPlayer)
+
+	queue := getOrCreateQueue(guildID)
+	queue.SetLoudnessAnalyzer(nil)
+	defer func() {
+		queueMutex.Lock()
+		delete(queues, guildID)
+		queueMutex.Unlock()
+	}()
+
+	queue.AddWithYouTubeData("https://stream.example/a", "https://youtu.be/a", "a", "A", "alice", 3*time.Minute)
+	queue.AddWithYouTubeData("https://stream.example/b", "https://youtu.be/b", "b", "B", "bob", 2*time.Minute)
+	queue.Add("https://stream.example/c", "C", "carol")
+
+	player, err := PlayerSnapshot(guildID)
+	require.NoError(t, err)
+	assert.Equal(t, guildID, player.GuildID)
+	assert.Equal(t, common.PlayerIdle, player.State)
+	assert.Nil(t, player.Current)
+	assert.Len(t, player.Pending, 3)
+	assert.Equal(t, common.LoopOff, player.LoopMode)
+
+	require.NotNil(t, queue.Next())
+	queue.SetPlaying(true)
+
+	player, err = PlayerSnapshot(guildID)
+	require.NoError(t, err)
+	assert.Equal(t, common.PlayerStarting, player.State)
+
+	pipeline := startWith(t, func(*common.AudioPipeline) common.Streamer {
+		return func(ctx context.Context, streamURL string) error {
+			<-ctx.Done()
+			return nil
+		}
+	})
+	queue.SetPipeline(pipeline)
+
+	player, err = PlayerSnapshot(guildID)
+	require.NoError(t, err)
+	assert.Equal(t, common.PlayerPlaying, player.State)
+	require.NotNil(t, player.Current)
+	assert.Equal(t, "A", player.Current.Title)
+	assert.Equal(t, "https://youtu.be/a", player.Current.URL)
+	assert.Equal(t, "alice", player.Current.RequestedBy)
+	assert.Equal(t, 3*time.Minute, player.Current.Duration)
+	assert.Equal(t, pipeline.Position(), player.Current.Elapsed)
+
+	require.Len(t, player.Pending, 2)
+	assert.Equal(t, "B", player.Pending[0].Title)
+	assert.Equal(t, "C", player.Pending[1].Title)
+	assert.Equal(t, "https://stream.example/c", player.Pending[1].URL)
+	assert.Equal(t, 5*time.Minute-player.Current.Elapsed, player.Remaining)
+	assert.True(t, player.RemainingApproximate, "C has no known length")
+
+	require.NoError(t, pipeline.Pause())
+	player, err = PlayerSnapshot(guildID)
+	require.NoError(t, err)
+	assert.Equal(t, common.PlayerPaused, player.State)
+
+	data, err := json.Marshal(player)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "paused", decoded["state"])
+	assert.Equal(t, "off", decoded["loop_mode"])
+	assert.Equal(t, "A", decoded["current"].(map[string]any)["title"])
+}
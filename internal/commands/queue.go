@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/latoulicious/HKTM/internal/presence"
 	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
 )
 
 var (
@@ -38,17 +40,35 @@ func updateActivity(guildID string) {
 }
 
 // sendIdleDisconnectEmbed sends an embed when the bot disconnects due to idle timeout
-func sendIdleDisconnectEmbed(s *discordgo.Session, channelID string) {
+func sendIdleDisconnectEmbed(s *discordgo.Session, channelID, reason string) {
 	embed := &discordgo.MessageEmbed{
 		Title:     "⏰ Idle Timeout",
-		Color:     0xffa500, // Orange
-		Timestamp: time.Now().Format(time.RFC3339),
+		Color:     theme().Color(EmbedWarning),
+		Timestamp: theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+			Text: theme().FooterText(""),
 		},
-		Description: "Bot has been idle for 5 minutes. Disconnected from voice channel to preserve resources.\nUse `!play` to start playing again!",
+		Description: reason + "\nUse `!play` to start playing again!",
 	}
-	s.ChannelMessageSendEmbed(channelID, embed)
+	s.ChannelMessageSendEmbed(channelID, fitEmbed(embed))
+}
+
+// noticeChannelID returns the guild's announce channel, or else its first
+// text channel, used for announcements that are not replies to a command
+func noticeChannelID(s *discordgo.Session, guildID string) string {
+	if channelID := announceChannelID(guildID, ""); channelID != "" {
+		return channelID
+	}
+	channels, err := s.GuildChannels(guildID)
+	if err != nil {
+		return ""
+	}
+	for _, channel := range channels {
+		if channel.Type == discordgo.ChannelTypeGuildText {
+			return channel.ID
+		}
+	}
+	return ""
 }
 
 // startIdleMonitor starts monitoring for idle timeouts
@@ -68,26 +88,16 @@ func startIdleMonitor(s *discordgo.Session) {
 					queue := getQueue(guildID)
 					if queue != nil && queue.IsPlaying() {
 						// Stop the queue and clean up resources
-						queue.StopAndCleanup()
+						queue.StopAndCleanupWithReason(common.OutcomeIdleTimeout)
+						queue.ClearHistory()
 
 						// Clear presence
 						if presenceManager != nil {
 							presenceManager.ClearMusicPresence()
 						}
 
-						// Find a text channel to send the embed
-						// We'll use the first available text channel
-						guild, err := s.Guild(guildID)
-						if err == nil && guild != nil {
-							channels, err := s.GuildChannels(guildID)
-							if err == nil {
-								for _, channel := range channels {
-									if channel.Type == discordgo.ChannelTypeGuildText {
-										sendIdleDisconnectEmbed(s, channel.ID)
-										break
-									}
-								}
-							}
+						if channelID := noticeChannelID(s, guildID); channelID != "" {
+							sendIdleDisconnectEmbed(s, channelID, "Bot has been idle for 5 minutes. Disconnected from voice channel to preserve resources.")
 						}
 
 						// Remove from idle tracking
@@ -123,47 +133,52 @@ func QueueCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []strin
 	switch subcommand {
 	case "add":
 		if len(args) < 2 {
-			sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue add <youtube_url>`", 0xff0000)
+			sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue add <youtube_url>`", EmbedError)
 			return
 		}
 		addToQueue(s, m, args[1:])
 	case "remove":
 		if len(args) < 2 {
-			sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue remove <index>`", 0xff0000)
+			sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue remove <index>`", EmbedError)
 			return
 		}
 		removeFromQueue(s, m, args[1:])
+	case "next":
+		if len(args) < 2 {
+			sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue next <index>`", EmbedError)
+			return
+		}
+		playNextInQueue(s, m, args[1:])
 	case "clear":
 		clearQueue(s, m)
 	case "list":
 		showQueue(s, m)
+	case "retryfailed":
+		retryFailedTracks(s, m)
 	default:
-		sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue [add|remove|clear|list] [args...]`", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Usage Error", "Usage: `!queue [add|remove|next|clear|list|retryfailed] [args...]`", EmbedError)
 	}
 }
 
 // sendEmbedMessage is a helper function to send embed messages
-func sendEmbedMessage(s *discordgo.Session, channelID, title, description string, color int) {
-	embed := &discordgo.MessageEmbed{
-		Title:       title,
-		Description: description,
-		Color:       color,
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
-		},
-	}
-	s.ChannelMessageSendEmbed(channelID, embed)
+func sendEmbedMessage(s *discordgo.Session, channelID, title, description string, event EmbedEvent) {
+	s.ChannelMessageSendEmbed(channelID, fitEmbed(theme().NewEmbed(event, title, description, "")))
+}
+
+// sendAnnouncement sends a playback notification to the guild's announce
+// channel, or to channelID if it has none
+func sendAnnouncement(s *discordgo.Session, guildID, channelID, title, description string, event EmbedEvent) {
+	sendEmbedMessage(s, announceChannelID(guildID, channelID), title, description, event)
 }
 
 // sendSongFinishedEmbed sends an embed when a song finishes playing
-func sendSongFinishedEmbed(s *discordgo.Session, channelID, songTitle, requestedBy string) {
+func sendSongFinishedEmbed(s *discordgo.Session, guildID, channelID, songTitle, requestedBy string) {
 	embed := &discordgo.MessageEmbed{
 		Title:     "🎵 Song Finished",
-		Color:     0x00ff00, // Green
-		Timestamp: time.Now().Format(time.RFC3339),
+		Color:     theme().Color(EmbedSuccess),
+		Timestamp: theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+			Text: theme().FooterText(""),
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
@@ -173,31 +188,88 @@ func sendSongFinishedEmbed(s *discordgo.Session, channelID, songTitle, requested
 			},
 		},
 	}
-	s.ChannelMessageSendEmbed(channelID, embed)
+	s.ChannelMessageSendEmbed(announceChannelID(guildID, channelID), fitEmbed(embed))
+}
+
+// sendSongFailedEmbed sends an embed when a song stops because of an unrecoverable
+// error, saying whether playback moves on or stops
+func sendSongFailedEmbed(s *discordgo.Session, guildID, channelID, songTitle string, outcome common.Outcome, next string) {
+	reason := "unknown error"
+	if outcome.Err != nil {
+		reason = outcome.Err.Error()
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:     "❌ Playback Failed",
+		Color:     theme().Color(EmbedError),
+		Timestamp: theme().Timestamp(time.Now()),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: theme().FooterText(""),
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Stopped Playing",
+				Value:  fmt.Sprintf("**%s**\nAfter %s and %d recovery attempt(s)", songTitle, outcome.Elapsed.Round(time.Second), outcome.Recoveries),
+				Inline: false,
+			},
+			{
+				Name:   "Reason",
+				Value:  reason,
+				Inline: false,
+			},
+			{
+				Name:   "Next",
+				Value:  next,
+				Inline: false,
+			},
+		},
+	}
+	s.ChannelMessageSendEmbed(announceChannelID(guildID, channelID), fitEmbed(embed))
+}
+
+// trackFailureNote describes what happens after a failed track
+func trackFailureNote(guildID string, action trackFailureAction, err error) string {
+	switch {
+	case action == skipFailedTrack:
+		return "Skipping to the next track."
+	case common.IsSessionError(err):
+		return "Stopping playback; no other track can play until this is fixed."
+	default:
+		return fmt.Sprintf("Stopping playback after %d failed tracks in a row.", guildMaxTrackFailures(guildID))
+	}
+}
+
+// endFailedSession stops playback after a failure the session can't survive.
+// The queue keeps its remaining tracks.
+func endFailedSession(queue *common.MusicQueue) {
+	queue.StopAndCleanup()
+	if presenceManager != nil {
+		presenceManager.ClearMusicPresence()
+	}
 }
 
 // sendQueueEndedEmbed sends an embed when the queue ends
-func sendQueueEndedEmbed(s *discordgo.Session, channelID string) {
+func sendQueueEndedEmbed(s *discordgo.Session, guildID, channelID string) {
 	embed := &discordgo.MessageEmbed{
 		Title:     "📭 Queue Ended",
-		Color:     0x808080, // Gray
-		Timestamp: time.Now().Format(time.RFC3339),
+		Color:     theme().Color(EmbedNeutral),
+		Timestamp: theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+			Text: theme().FooterText(""),
 		},
 		Description: "All songs in the queue have been played. Add more songs with `!play` or `!queue add`!",
 	}
-	s.ChannelMessageSendEmbed(channelID, embed)
+	s.ChannelMessageSendEmbed(announceChannelID(guildID, channelID), fitEmbed(embed))
 }
 
 // sendSongSkippedEmbed sends an embed when a song is skipped
 func sendSongSkippedEmbed(s *discordgo.Session, channelID, songTitle, requestedBy, skippedBy string) {
 	embed := &discordgo.MessageEmbed{
 		Title:     "⏭️ Song Skipped",
-		Color:     0xffa500, // Orange
-		Timestamp: time.Now().Format(time.RFC3339),
+		Color:     theme().Color(EmbedWarning),
+		Timestamp: theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+			Text: theme().FooterText(""),
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
@@ -212,17 +284,17 @@ func sendSongSkippedEmbed(s *discordgo.Session, channelID, songTitle, requestedB
 			},
 		},
 	}
-	s.ChannelMessageSendEmbed(channelID, embed)
+	s.ChannelMessageSendEmbed(channelID, fitEmbed(embed))
 }
 
 // sendBotStoppedEmbed sends an embed when the bot stops/disconnects
 func sendBotStoppedEmbed(s *discordgo.Session, channelID, stoppedBy string) {
 	embed := &discordgo.MessageEmbed{
 		Title:     "⏹️ Playback Stopped",
-		Color:     0xff0000, // Red
-		Timestamp: time.Now().Format(time.RFC3339),
+		Color:     theme().Color(EmbedError),
+		Timestamp: theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+			Text: theme().FooterText(""),
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
@@ -233,7 +305,7 @@ func sendBotStoppedEmbed(s *discordgo.Session, channelID, stoppedBy string) {
 		},
 		Description: "Music playback has been stopped. Use `!play` to start playing again!",
 	}
-	s.ChannelMessageSendEmbed(channelID, embed)
+	s.ChannelMessageSendEmbed(channelID, fitEmbed(embed))
 }
 
 // addToQueue adds a song to the queue
@@ -248,9 +320,9 @@ func addToQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
 	queue := getOrCreateQueue(guildID)
 
 	// Validate and get stream URL with metadata
-	streamURL, title, duration, err := common.GetYouTubeAudioStreamWithMetadata(url)
+	streamURL, title, duration, chapters, err := resolveSource(url)
 	if err != nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to get audio stream. Please check the URL.", 0xff0000)
+		reportSourceError(s, m.ChannelID, err, "Failed to get audio stream. Please check the URL.")
 		return
 	}
 
@@ -261,7 +333,7 @@ func addToQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
 		videoID = common.ExtractYouTubeVideoID(url)
 		originalURL = url
 		// Use the new method for YouTube videos
-		queue.AddWithYouTubeData(streamURL, originalURL, videoID, title, m.Author.Username, duration)
+		queue.AddWithYouTubeData(streamURL, originalURL, videoID, title, m.Author.Username, duration, chapters...)
 	} else {
 		// Use the original method for non-YouTube URLs
 		queue.Add(streamURL, title, m.Author.Username)
@@ -270,13 +342,12 @@ func addToQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
 	// Send confirmation with embed
 	queueSize := queue.Size()
 	description := fmt.Sprintf("✅ Added **%s** to queue (Position: %d)", title, queueSize)
-	sendEmbedMessage(s, m.ChannelID, "🎵 Song Added", description, 0x00ff00)
+	sendEmbedMessage(s, m.ChannelID, "🎵 Song Added", description, EmbedSuccess)
 
-	// Check if we should start playing - only if the queue can start playing
-	if queue.CanStartPlaying() {
-		log.Printf("Starting playback for guild %s - queue size: %d, isPlaying: %v, hasPipeline: %v",
-			guildID, queueSize, queue.IsPlaying(), queue.HasActivePipeline())
-		startNextInQueue(s, m, queue)
+	// Start playing unless this guild already is; then the song just waits
+	// in the existing session's queue
+	if startIfIdle(s, m, queue) {
+		log.Printf("Started playback for guild %s - queue size: %d", guildID, queueSize)
 	} else {
 		log.Printf("Song added to queue for guild %s but not starting playback - queue size: %d, isPlaying: %v, hasPipeline: %v",
 			guildID, queueSize, queue.IsPlaying(), queue.HasActivePipeline())
@@ -293,7 +364,7 @@ func removeFromQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []st
 	queue := getQueue(guildID)
 
 	if queue == nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", EmbedError)
 		return
 	}
 
@@ -301,7 +372,7 @@ func removeFromQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []st
 	var index int
 	_, err := fmt.Sscanf(args[0], "%d", &index)
 	if err != nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Invalid index. Use `!queue list` to see queue positions.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Invalid index. Use `!queue list` to see queue positions.", EmbedError)
 		return
 	}
 
@@ -310,11 +381,45 @@ func removeFromQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []st
 
 	err = queue.Remove(index)
 	if err != nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
 		return
 	}
 
-	sendEmbedMessage(s, m.ChannelID, "✅ Success", "Removed song from queue.", 0x00ff00)
+	sendEmbedMessage(s, m.ChannelID, "✅ Success", "Removed song from queue.", EmbedSuccess)
+}
+
+// playNextInQueue moves a song so it plays right after the current one
+func playNextInQueue(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	guildID := m.GuildID
+
+	// Update activity
+	updateActivity(guildID)
+
+	queue := getQueue(guildID)
+
+	if queue == nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", EmbedError)
+		return
+	}
+
+	// Parse index
+	var index int
+	_, err := fmt.Sscanf(args[0], "%d", &index)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Invalid index. Use `!queue list` to see queue positions.", EmbedError)
+		return
+	}
+
+	// Adjust for 1-based indexing
+	index--
+
+	item, err := queue.PlayNext(index)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+		return
+	}
+
+	sendEmbedMessage(s, m.ChannelID, "✅ Success", fmt.Sprintf("**%s** will play next.", item.Title), EmbedSuccess)
 }
 
 // clearQueue clears the entire queue
@@ -327,12 +432,41 @@ func clearQueue(s *discordgo.Session, m *discordgo.MessageCreate) {
 	queue := getQueue(guildID)
 
 	if queue == nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", EmbedError)
 		return
 	}
 
 	queue.Clear()
-	sendEmbedMessage(s, m.ChannelID, "✅ Success", "Queue cleared.", 0x00ff00)
+	queue.ClearFailedTracks()
+	sendEmbedMessage(s, m.ChannelID, "✅ Success", "Queue cleared.", EmbedSuccess)
+}
+
+// retryFailedTracks requeues the tracks skipped after failing to play
+func retryFailedTracks(s *discordgo.Session, m *discordgo.MessageCreate) {
+	guildID := m.GuildID
+
+	// Update activity
+	updateActivity(guildID)
+
+	queue := getQueue(guildID)
+	if queue == nil || len(queue.FailedTracks()) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "📭 Nothing to Retry", "No tracks have failed this session.", EmbedNeutral)
+		return
+	}
+
+	requeued, failed := queue.RetryFailedTracks(resolveHistoryStream)
+	if requeued == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("None of the %d failed track(s) could be loaded yet. Try again later.", failed), EmbedError)
+		return
+	}
+
+	description := fmt.Sprintf("🔁 Retrying **%d** failed track(s).", requeued)
+	if failed > 0 {
+		description += fmt.Sprintf("\n⚠️ %d track(s) still could not be loaded and were kept for the next retry.", failed)
+	}
+	sendEmbedMessage(s, m.ChannelID, "🔁 Retry Failed Tracks", description, EmbedSuccess)
+
+	startIfIdle(s, m, queue)
 }
 
 // showQueue shows the current queue
@@ -345,58 +479,61 @@ func showQueue(s *discordgo.Session, m *discordgo.MessageCreate) {
 	queue := getQueue(guildID)
 
 	if queue == nil || (queue.Size() == 0 && queue.Current() == nil) {
-		sendEmbedMessage(s, m.ChannelID, "📭 Queue Empty", "No songs in the queue.", 0x808080)
+		sendEmbedMessage(s, m.ChannelID, "📭 Queue Empty", "No songs in the queue.", EmbedNeutral)
 		return
 	}
 
-	// Create embed for queue display
-	embed := &discordgo.MessageEmbed{
-		Title:     "🎵 Music Queue",
-		Color:     0x0099ff,
-		Timestamp: time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
-		},
+	state := &queuePageState{
+		items:     queue.List(),
+		pageSize:  getQueuePageSize(),
+		channelID: m.ChannelID,
 	}
+	if current := queue.Current(); current != nil {
+		state.nowPlaying = current.Title
+	}
+	remaining, approximate := queue.RemainingEstimate()
+	state.remaining = formatRemaining(remaining, approximate)
 
-	var fields []*discordgo.MessageEmbedField
+	msg, err := s.ChannelMessageSendEmbed(m.ChannelID, queuePageEmbed(state))
+	if err != nil {
+		log.Printf("Error sending queue: %v", err)
+		return
+	}
 
-	// Show currently playing
-	if current := queue.Current(); current != nil {
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "🎶 Now Playing",
-			Value:  fmt.Sprintf(current.Title),
-			Inline: false,
-		})
-	}
-
-	// Show queue items
-	items := queue.List()
-	if len(items) > 0 {
-		var queueText strings.Builder
-		for i, item := range items {
-			queueText.WriteString(fmt.Sprintf("%d. **%s** (Requested by: %s)\n", i+1, item.Title, item.RequestedBy))
-		}
+	// Register paging if there is more than one page
+	if queuePageCount(len(state.items), state.pageSize) > 1 {
+		state.messageID = msg.ID
+		registerQueuePages(state)
+		s.MessageReactionAdd(m.ChannelID, msg.ID, "⬅️")
+		s.MessageReactionAdd(m.ChannelID, msg.ID, "➡️")
+	}
+}
 
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "📋 Up Next",
-			Value:  queueText.String(),
-			Inline: false,
-		})
-	} else {
-		fields = append(fields, &discordgo.MessageEmbedField{
-			Name:   "📋 Up Next",
-			Value:  "No songs in queue.",
-			Inline: false,
-		})
+// formatRemaining describes the queue's remaining playtime, e.g.
+// "≈ 42 min remaining." when some tracks have an unknown length
+func formatRemaining(remaining time.Duration, approximate bool) string {
+	var amount string
+	rounded := remaining.Round(time.Minute)
+	switch {
+	case remaining <= 0 && approximate:
+		return "Remaining time unknown."
+	case remaining < time.Minute:
+		amount = "< 1 min"
+	case rounded < time.Hour:
+		amount = fmt.Sprintf("%d min", int(rounded.Minutes()))
+	default:
+		amount = fmt.Sprintf("%dh %02dm", int(rounded.Hours()), int(rounded.Minutes())%60)
 	}
 
-	embed.Fields = fields
-	s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	if approximate {
+		return fmt.Sprintf("≈ %s remaining.", amount)
+	}
+	return fmt.Sprintf("%s remaining.", amount)
 }
 
-// startNextInQueue starts playing the next song in the queue
-func startNextInQueue(s *discordgo.Session, m *discordgo.MessageCreate, queue *common.MusicQueue) {
+// startNextInQueueLocked starts playing the next song in the queue. Callers
+// must hold the guild's start lock; see startIfIdle and advanceQueue.
+func startNextInQueueLocked(s *discordgo.Session, m *discordgo.MessageCreate, queue *common.MusicQueue) {
 	// Check if there's already an active pipeline and clean it up
 	if queue.HasActivePipeline() {
 		log.Printf("Cleaning up existing pipeline before starting new one")
@@ -405,13 +542,14 @@ func startNextInQueue(s *discordgo.Session, m *discordgo.MessageCreate, queue *c
 
 	item := queue.Next()
 	if item == nil {
+		queue.MarkDrained()
 		queue.SetPlaying(false)
 		// Clear presence when no more songs
 		if presenceManager != nil {
 			presenceManager.ClearMusicPresence()
 		}
 		// Send queue ended embed
-		sendQueueEndedEmbed(s, m.ChannelID)
+		sendQueueEndedEmbed(s, m.GuildID, m.ChannelID)
 		return
 	}
 
@@ -420,7 +558,11 @@ func startNextInQueue(s *discordgo.Session, m *discordgo.MessageCreate, queue *c
 	// Find user's voice channel and connect
 	vc, err := common.FindAndJoinUserVoiceChannel(s, m.Author.ID, m.GuildID)
 	if err != nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), 0xff0000)
+		title := "❌ Voice Connection Failed"
+		if errors.Is(err, common.ErrNotInVoiceChannel) {
+			title = "❌ Error"
+		}
+		sendEmbedMessage(s, m.ChannelID, title, err.Error(), EmbedError)
 		queue.SetPlaying(false)
 		return
 	}
@@ -428,8 +570,15 @@ func startNextInQueue(s *discordgo.Session, m *discordgo.MessageCreate, queue *c
 	queue.SetVoiceConnection(vc)
 
 	// Create and start the audio pipeline
-	pipeline := common.NewAudioPipeline(vc)
-	queue.SetPipeline(pipeline)
+	player := common.NewAudioPipeline(vc)
+	player.SetGain(queue.TrackGain(item))
+	player.SetSilenceTrim(guildTrimSilence(queue.GuildID()), silenceThresholdDB())
+	player.SetMetricSink(queueMetricRecorder())
+	player.SetSessionEncoder(queue.SessionEncoder())
+	player.SetRecoveryBudget(queue.RecoveryBudget())
+	player.SetVoiceSession(rejoinSession(s, queue, player))
+	session := startPipelineSession(m.GuildID, vc.ChannelID, item, player)
+	queue.SetPipeline(player)
 
 	// Update bot presence to show current song
 	if presenceManager != nil {
@@ -439,39 +588,75 @@ func startNextInQueue(s *discordgo.Session, m *discordgo.MessageCreate, queue *c
 		log.Printf("Warning: presenceManager is nil, cannot update presence")
 	}
 
-	// Send now playing message with embed
-	description := item.Title
-	sendEmbedMessage(s, m.ChannelID, "🎶 Now Playing", description, 0x00ff00)
-
 	// Start streaming
-	err = pipeline.PlayStream(item.URL)
+	err = player.PlayStream(item.URL)
 	if err != nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to start audio playback.", 0xff0000)
-		queue.StopAndCleanup()
-		if presenceManager != nil {
-			presenceManager.ClearMusicPresence()
+		log.Printf("Failed to start playback of %q: %v", item.Title, err)
+		session.fail(err)
+		if errors.Is(err, pipeline.ErrFFmpegNotFound) {
+			sendEmbedMessage(s, m.ChannelID, "❌ Playback Unavailable", "ffmpeg is not installed or not in PATH, so audio can't be played. Ask the bot owner to install it.", EmbedError)
+			endFailedSession(queue)
+			return
 		}
+
+		action := handleTrackFailure(queue, item, err)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Failed to start playback of **%s**: %v\n%s", item.Title, err, trackFailureNote(queue.GuildID(), action, err)), EmbedError)
+		if action == endSession {
+			endFailedSession(queue)
+			return
+		}
+		queue.SetPipeline(nil)
+		startNextInQueueLocked(s, m, queue)
 		return
 	}
 
+	queue.PublishTrackStarted(item)
+
 	// Monitor the pipeline and handle completion
 	go func() {
+		// Announce the track only once audio is reaching the voice channel;
+		// if it never does, the pipeline is failed and reported below
+		if awaitPlaybackReady(player, playbackReadyFrames, playbackReadyTimeout) == nil {
+			sendAnnouncement(s, m.GuildID, m.ChannelID, "🎶 Now Playing", item.Title, EmbedSuccess)
+		}
+
 		// Wait for pipeline to finish
-		for pipeline.IsPlaying() {
+		for player.IsPlaying() {
 			time.Sleep(1 * time.Second)
 		}
 
-		// Only send song finished embed if the song wasn't skipped
-		if !queue.WasSkipped() {
-			sendSongFinishedEmbed(s, m.ChannelID, item.Title, item.RequestedBy)
+		outcome := player.LastOutcome()
+		log.Printf("Track %q ended: %s", item.Title, outcome)
+		session.end(outcome)
+		queue.PublishTrackEnded(item, outcome, queue.WasSkipped())
+
+		switch outcome.Reason {
+		case common.OutcomeIdleTimeout:
+			// The idle monitor already disconnected and announced it
+			queue.SetSkipped(false)
+			return
+		case common.OutcomeError:
+			// One bad track is skipped; the session ends only when the
+			// error would fail every track or too many fail in a row
+			action := handleTrackFailure(queue, item, outcome.Err)
+			sendSongFailedEmbed(s, m.GuildID, m.ChannelID, item.Title, outcome, trackFailureNote(queue.GuildID(), action, outcome.Err))
+			if action == endSession {
+				queue.SetSkipped(false)
+				endFailedSession(queue)
+				return
+			}
+		case common.OutcomeCompleted:
+			queue.ResetTrackFailures()
+			// Only send song finished embed if the song wasn't skipped
+			if !queue.WasSkipped() {
+				sendSongFinishedEmbed(s, m.GuildID, m.ChannelID, item.Title, item.RequestedBy)
+			}
 		}
 
-		// Clean up the pipeline
-		queue.SetPipeline(nil)
 		queue.SetSkipped(false) // Reset the skipped flag
 
-		// Play next song in queue
-		startNextInQueue(s, m, queue)
+		// Play next song in queue, unless a skip already has
+		advanceQueue(s, m, queue, player)
 	}()
 }
 
@@ -485,10 +670,21 @@ func getOrCreateQueue(guildID string) *common.MusicQueue {
 	}
 
 	queue := common.NewMusicQueue(guildID)
+	queue.SetObserver(observeQueue)
+	queue.SetPrefetchResolver(resolveQueuedStream)
 	queues[guildID] = queue
 	return queue
 }
 
+// resolveQueuedStream refreshes the stream URL of a queued YouTube item ahead
+// of playback; direct sources are used as-is
+func resolveQueuedStream(item *common.QueueItem) (string, error) {
+	if item.OriginalURL == "" {
+		return item.URL, nil
+	}
+	return resolveHistoryStream(item)
+}
+
 // getQueue gets a queue for a guild
 func getQueue(guildID string) *common.MusicQueue {
 	queueMutex.RLock()
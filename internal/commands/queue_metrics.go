@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"sync"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+var (
+	// queueMetrics receives queue load gauges; metrics are discarded until a
+	// recorder is configured
+	queueMetrics      pipeline.MetricRecorder = pipeline.NoopMetricRecorder{}
+	queueMetricsMutex sync.RWMutex
+)
+
+// SetQueueMetricRecorder sets the recorder for the queue_depth and
+// guilds_playing gauges. A nil recorder discards them.
+func SetQueueMetricRecorder(recorder pipeline.MetricRecorder) {
+	if recorder == nil {
+		recorder = pipeline.NoopMetricRecorder{}
+	}
+
+	queueMetricsMutex.Lock()
+	queueMetrics = recorder
+	queueMetricsMutex.Unlock()
+}
+
+// queueMetricRecorder returns the configured recorder, which also receives
+// each pipeline's frame buffer metrics
+func queueMetricRecorder() pipeline.MetricRecorder {
+	queueMetricsMutex.RLock()
+	defer queueMetricsMutex.RUnlock()
+	return queueMetrics
+}
+
+// observeQueue reports the queue's depth and the number of guilds with an
+// active pipeline whenever a queue changes
+func observeQueue(queue *common.MusicQueue) {
+	recorder := queueMetricRecorder()
+	recorder.Gauge("queue_depth", float64(queue.Size()), map[string]string{
+		"guild_id": queue.GuildID(),
+	})
+	recorder.Gauge("guilds_playing", float64(countGuildsPlaying()), nil)
+}
+
+// countGuildsPlaying returns how many guild queues currently hold a pipeline
+func countGuildsPlaying() int {
+	queueMutex.RLock()
+	defer queueMutex.RUnlock()
+
+	playing := 0
+	for _, queue := range queues {
+		if queue.GetPipeline() != nil {
+			playing++
+		}
+	}
+	return playing
+}
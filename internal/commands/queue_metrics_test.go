@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type gaugeSample struct {
+	value float64
+	tags  map[string]string
+}
+
+// spyRecorder captures gauges; other metric types are ignored
+type spyRecorder struct {
+	mu     sync.Mutex
+	gauges map[string][]gaugeSample
+}
+
+func newSpyRecorder() *spyRecorder {
+	return &spyRecorder{gauges: make(map[string][]gaugeSample)}
+}
+
+func (r *spyRecorder) Counter(name string, value int64, tags map[string]string)           {}
+func (r *spyRecorder) Histogram(name string, value float64, tags map[string]string)       {}
+func (r *spyRecorder) Timing(name string, duration time.Duration, tags map[string]string) {}
+
+func (r *spyRecorder) Gauge(name string, value float64, tags map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[name] = append(r.gauges[name], gaugeSample{value: value, tags: tags})
+}
+
+func (r *spyRecorder) last(name string) gaugeSample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	samples := r.gauges[name]
+	if len(samples) == 0 {
+		return gaugeSample{value: -1}
+	}
+	return samples[len(samples)-1]
+}
+
+func TestQueueDepthGauge(t *testing.T) {
+	spy := newSpyRecorder()
+	SetQueueMetricRecorder(spy)
+	defer SetQueueMetricRecorder(nil)
+
+	const guildID = "queue-metrics-guild"
+	queue := getOrCreateQueue(guildID)
+	queue.SetLoudnessAnalyzer(nil)
+	defer func() {
+		queueMutex.Lock()
+		delete(queues, guildID)
+		queueMutex.Unlock()
+	}()
+
+	queue.Add("https://example.com/a", "A", "tester")
+	queue.Add("https://example.com/b", "B", "tester")
+	depth := spy.last("queue_depth")
+	assert.Equal(t, 2.0, depth.value)
+	assert.Equal(t, guildID, depth.tags["guild_id"])
+
+	require.NotNil(t, queue.Next())
+	assert.Equal(t, 1.0, spy.last("queue_depth").value)
+
+	require.NoError(t, queue.Remove(0))
+	assert.Equal(t, 0.0, spy.last("queue_depth").value)
+	assert.Equal(t, 0.0, spy.last("guilds_playing").value)
+
+	queue.SetPipeline(common.NewAudioPipeline(nil))
+	assert.Equal(t, 1.0, spy.last("guilds_playing").value)
+
+	queue.SetPipeline(nil)
+	assert.Equal(t, 0.0, spy.last("guilds_playing").value)
+}
@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+const (
+	// DefaultQueuePageSize is how many upcoming tracks !queue shows per page
+	DefaultQueuePageSize = 10
+
+	// maxQueuePageSize keeps a page of readable lines inside one field
+	maxQueuePageSize = 15
+
+	// Bounds on each track title on a queue page, in runes. Titles get what
+	// is left of the field once the page's other text is accounted for.
+	minQueueTitleLength = 16
+	maxQueueTitleLength = 64
+
+	// maxQueueRequesterLength caps each requester name on a queue page
+	maxQueueRequesterLength = 12
+
+	// queueLineOverhead is the most text a queue line adds around its title:
+	// "100. **", "** (Requested by: ", the requester and ")\n"
+	queueLineOverhead = 7 + 18 + maxQueueRequesterLength + 2
+
+	// queuePageLifetime is how long a queue message keeps responding to
+	// its page reactions
+	queuePageLifetime = 10 * time.Minute
+)
+
+var (
+	queuePageSize      = DefaultQueuePageSize
+	queuePageSizeMutex sync.RWMutex
+)
+
+// SetQueuePageSize sets how many upcoming tracks !queue shows per page.
+// Values of 0 or less restore the default; larger values are capped so a
+// page always fits in one embed field.
+func SetQueuePageSize(n int) {
+	if n <= 0 {
+		n = DefaultQueuePageSize
+	}
+	if n > maxQueuePageSize {
+		n = maxQueuePageSize
+	}
+
+	queuePageSizeMutex.Lock()
+	queuePageSize = n
+	queuePageSizeMutex.Unlock()
+}
+
+// getQueuePageSize returns the configured queue page size
+func getQueuePageSize() int {
+	queuePageSizeMutex.RLock()
+	defer queuePageSizeMutex.RUnlock()
+	return queuePageSize
+}
+
+// queuePageState is a paged !queue message. It holds a snapshot of the
+// queue taken when the message was sent.
+type queuePageState struct {
+	nowPlaying string
+	items      []*common.QueueItem
+	remaining  string
+	page       int
+	pageSize   int
+	channelID  string
+	messageID  string
+}
+
+var (
+	queuePages      = make(map[string]*queuePageState)
+	queuePagesMutex sync.Mutex
+)
+
+// queuePageCount returns the number of pages needed for total tracks, at
+// least one
+func queuePageCount(total, pageSize int) int {
+	if total <= 0 || pageSize <= 0 {
+		return 1
+	}
+	return (total + pageSize - 1) / pageSize
+}
+
+// queuePageEmbed renders the state's current page
+func queuePageEmbed(state *queuePageState) *discordgo.MessageEmbed {
+	pages := queuePageCount(len(state.items), state.pageSize)
+	if state.page < 0 {
+		state.page = 0
+	}
+	if state.page >= pages {
+		state.page = pages - 1
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	if state.nowPlaying != "" {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  "🎶 Now Playing",
+			Value: truncateEventText(state.nowPlaying, maxEmbedFieldLength),
+		})
+	}
+
+	upNext := "No songs in queue."
+	if len(state.items) > 0 {
+		start := state.page * state.pageSize
+		end := start + state.pageSize
+		if end > len(state.items) {
+			end = len(state.items)
+		}
+
+		titleLength := queueTitleLength(state.pageSize)
+		var lines strings.Builder
+		for i, item := range state.items[start:end] {
+			lines.WriteString(fmt.Sprintf("%d. **%s** (Requested by: %s)\n",
+				start+i+1,
+				truncateEventText(escapeQueueMarkdown(item.Title), titleLength),
+				truncateEventText(item.RequestedBy, maxQueueRequesterLength)))
+		}
+		upNext = truncateEventText(lines.String(), maxEmbedFieldLength)
+	}
+	fields = append(fields, &discordgo.MessageEmbedField{
+		Name:  "📋 Up Next",
+		Value: upNext,
+	})
+
+	footer := fmt.Sprintf("Page %d/%d, %d songs total.", state.page+1, pages, len(state.items))
+	embed := theme().NewEmbed(EmbedInfo, "🎵 Music Queue", state.remaining, footer)
+	embed.Fields = fields
+	return fitEmbed(embed)
+}
+
+// queueTitleLength returns how many runes each title may use so that a
+// page of pageSize lines fits in one embed field
+func queueTitleLength(pageSize int) int {
+	length := maxEmbedFieldLength/pageSize - queueLineOverhead
+	if length < minQueueTitleLength {
+		return minQueueTitleLength
+	}
+	if length > maxQueueTitleLength {
+		return maxQueueTitleLength
+	}
+	return length
+}
+
+// escapeQueueMarkdown stops a title's asterisks from breaking the bold
+// markup around it
+func escapeQueueMarkdown(title string) string {
+	return strings.ReplaceAll(title, "*", "\\*")
+}
+
+// registerQueuePages makes a sent queue message respond to page reactions
+// until queuePageLifetime has passed
+func registerQueuePages(state *queuePageState) {
+	queuePagesMutex.Lock()
+	queuePages[state.messageID] = state
+	queuePagesMutex.Unlock()
+
+	time.AfterFunc(queuePageLifetime, func() {
+		queuePagesMutex.Lock()
+		if queuePages[state.messageID] == state {
+			delete(queuePages, state.messageID)
+		}
+		queuePagesMutex.Unlock()
+	})
+}
+
+// turnQueuePage moves a registered queue message one page back or forward,
+// wrapping at either end, and returns the new embed. ok is false when the
+// message isn't a paged queue or the reaction isn't a page arrow.
+func turnQueuePage(messageID, reaction string) (*queuePageState, *discordgo.MessageEmbed, bool) {
+	if reaction != "⬅️" && reaction != "➡️" {
+		return nil, nil, false
+	}
+
+	queuePagesMutex.Lock()
+	defer queuePagesMutex.Unlock()
+
+	state, exists := queuePages[messageID]
+	if !exists {
+		return nil, nil, false
+	}
+
+	pages := queuePageCount(len(state.items), state.pageSize)
+	switch reaction {
+	case "⬅️":
+		state.page = (state.page - 1 + pages) % pages
+	case "➡️":
+		state.page = (state.page + 1) % pages
+	}
+	return state, queuePageEmbed(state), true
+}
+
+// HandleQueuePageReaction pages through a !queue message when someone
+// reacts with an arrow
+func HandleQueuePageReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	state, embed, ok := turnQueuePage(r.MessageID, r.Emoji.Name)
+	if !ok {
+		return
+	}
+
+	if _, err := s.ChannelMessageEditEmbed(state.channelID, state.messageID, embed); err != nil {
+		log.Printf("Error updating queue page: %v", err)
+		return
+	}
+
+	// Remove the user's reaction so the same arrow can be used again
+	s.MessageReactionRemove(state.channelID, state.messageID, r.Emoji.Name, r.UserID)
+}
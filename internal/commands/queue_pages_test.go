@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func longQueue(n int) []*common.QueueItem {
+	items := make([]*common.QueueItem, n)
+	for i := range items {
+		items[i] = &common.QueueItem{
+			Title:       fmt.Sprintf("Track %d ", i+1) + strings.Repeat("very long *title* ", 20),
+			RequestedBy: strings.Repeat("requester", 5),
+		}
+	}
+	return items
+}
+
+func TestQueuePageEmbed_LongQueue(t *testing.T) {
+	for _, pageSize := range []int{1, DefaultQueuePageSize, maxQueuePageSize} {
+		state := &queuePageState{
+			nowPlaying: strings.Repeat("now playing ", 200),
+			items:      longQueue(100),
+			remaining:  "5h 00m remaining.",
+			pageSize:   pageSize,
+		}
+		pages := queuePageCount(len(state.items), pageSize)
+		assert.Equal(t, (100+pageSize-1)/pageSize, pages)
+
+		seen := 0
+		for page := 0; page < pages; page++ {
+			state.page = page
+			embed := queuePageEmbed(state)
+
+			assert.LessOrEqual(t, len(embed.Fields), maxEmbedFields)
+			assert.LessOrEqual(t, embedLength(embed), maxEmbedTotalLength)
+			for _, field := range embed.Fields {
+				assert.LessOrEqual(t, utf8.RuneCountInString(field.Value), maxEmbedFieldLength, "page %d field %s", page+1, field.Name)
+			}
+			assert.Contains(t, embed.Footer.Text, fmt.Sprintf("Page %d/%d, 100 songs total.", page+1, pages))
+
+			upNext := embed.Fields[len(embed.Fields)-1].Value
+			lines := strings.Split(strings.TrimSuffix(upNext, "\n"), "\n")
+			assert.False(t, strings.HasSuffix(upNext, "…"), "page %d cut mid-line", page+1)
+			seen += len(lines)
+		}
+		assert.Equal(t, 100, seen, "page size %d", pageSize)
+	}
+}
+
+func TestQueuePageEmbed_EmptyQueue(t *testing.T) {
+	embed := queuePageEmbed(&queuePageState{nowPlaying: "Song", pageSize: DefaultQueuePageSize})
+	require.Len(t, embed.Fields, 2)
+	assert.Equal(t, "No songs in queue.", embed.Fields[1].Value)
+	assert.Contains(t, embed.Footer.Text, "Page 1/1, 0 songs total.")
+}
+
+func TestTurnQueuePage(t *testing.T) {
+	state := &queuePageState{items: longQueue(25), pageSize: 10, messageID: "queue-msg"}
+	registerQueuePages(state)
+	defer func() {
+		queuePagesMutex.Lock()
+		delete(queuePages, "queue-msg")
+		queuePagesMutex.Unlock()
+	}()
+
+	_, embed, ok := turnQueuePage("queue-msg", "➡️")
+	require.True(t, ok)
+	assert.Contains(t, embed.Footer.Text, "Page 2/3")
+
+	// Wraps around at either end
+	_, embed, _ = turnQueuePage("queue-msg", "➡️")
+	_, embed, _ = turnQueuePage("queue-msg", "➡️")
+	assert.Contains(t, embed.Footer.Text, "Page 1/3")
+	_, embed, _ = turnQueuePage("queue-msg", "⬅️")
+	assert.Contains(t, embed.Footer.Text, "Page 3/3")
+	assert.True(t, strings.HasPrefix(embed.Fields[0].Value, "21. "))
+
+	_, _, ok = turnQueuePage("queue-msg", "🔄")
+	assert.False(t, ok)
+	_, _, ok = turnQueuePage("other-msg", "➡️")
+	assert.False(t, ok)
+}
+
+func TestSetQueuePageSize(t *testing.T) {
+	defer SetQueuePageSize(DefaultQueuePageSize)
+
+	SetQueuePageSize(5)
+	assert.Equal(t, 5, getQueuePageSize())
+	SetQueuePageSize(0)
+	assert.Equal(t, DefaultQueuePageSize, getQueuePageSize())
+	SetQueuePageSize(100)
+	assert.Equal(t, maxQueuePageSize, getQueuePageSize())
+}
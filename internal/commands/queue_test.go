@@ -0,0 +1,17 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatRemaining(t *testing.T) {
+	assert.Equal(t, "42 min remaining.", formatRemaining(42*time.Minute+10*time.Second, false))
+	assert.Equal(t, "≈ 42 min remaining.", formatRemaining(42*time.Minute, true))
+	assert.Equal(t, "< 1 min remaining.", formatRemaining(20*time.Second, false))
+	assert.Equal(t, "1h 00m remaining.", formatRemaining(59*time.Minute+45*time.Second, false))
+	assert.Equal(t, "≈ 2h 05m remaining.", formatRemaining(2*time.Hour+5*time.Minute, true))
+	assert.Equal(t, "Remaining time unknown.", formatRemaining(0, true))
+}
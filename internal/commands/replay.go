@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// maxReplayTracks caps how many history items a single replay requeues
+const maxReplayTracks = 25
+
+// ReplayCommand requeues every track played this session in its original
+// order, or with `from <n>` only the last n finished tracks
+func ReplayCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) > 0 && strings.ToLower(args[0]) == "from" {
+		replayFromCommand(s, m, args[1:])
+		return
+	}
+
+	guildID := m.GuildID
+
+	// Update activity for idle monitoring
+	updateActivity(guildID)
+
+	queue := getQueue(guildID)
+	if queue == nil || len(queue.History()) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing has been played this session.", EmbedError)
+		return
+	}
+
+	requeued, failed := queue.ReplayHistory(maxReplayTracks, resolveHistoryStream)
+	if requeued == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to requeue any tracks from history.", EmbedError)
+		return
+	}
+
+	description := fmt.Sprintf("🔁 Requeued **%d** track(s) from this session.", requeued)
+	if failed > 0 {
+		description += fmt.Sprintf("\n⚠️ %d track(s) could not be loaded and were skipped.", failed)
+	}
+	if len(queue.History()) > maxReplayTracks {
+		description += fmt.Sprintf("\nOnly the first %d tracks are replayed.", maxReplayTracks)
+	}
+	sendEmbedMessage(s, m.ChannelID, "🔁 Replay", description, EmbedSuccess)
+
+	startIfIdle(s, m, queue)
+}
+
+// replayFromCommand requeues the last n finished tracks in their original order
+func replayFromCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	updateActivity(m.GuildID)
+
+	if len(args) != 1 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!replay from <n>` to requeue the last n finished tracks.", EmbedError)
+		return
+	}
+
+	queue := getQueue(m.GuildID)
+	var finished int
+	if queue != nil {
+		finished = len(queue.FinishedHistory())
+	}
+	if finished == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No tracks have finished this session.", EmbedError)
+		return
+	}
+
+	limit := finished
+	if limit > maxReplayTracks {
+		limit = maxReplayTracks
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > limit {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Pick a number of tracks from 1 to %d.", limit), EmbedError)
+		return
+	}
+
+	requeued, failed, err := queue.ReplayLast(n, resolveHistoryStream)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", err.Error(), EmbedError)
+		return
+	}
+	if requeued == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Failed to requeue any tracks from history.", EmbedError)
+		return
+	}
+
+	description := fmt.Sprintf("🔁 Requeued **%d** of the last %d finished track(s).", requeued, n)
+	if failed > 0 {
+		description += fmt.Sprintf("\n⚠️ %d track(s) could not be loaded and were skipped.", failed)
+	}
+	sendEmbedMessage(s, m.ChannelID, "🔁 Replay", description, EmbedSuccess)
+
+	startIfIdle(s, m, queue)
+}
+
+// resolveHistoryStream fetches a fresh stream URL, since yt-dlp URLs expire.
+// The source is checked against the host policy again, which may have
+// changed since it was first played.
+func resolveHistoryStream(item *common.QueueItem) (string, error) {
+	source := item.OriginalURL
+	if source == "" {
+		source = item.URL
+	}
+
+	streamURL, _, _, _, err := resolveSource(source)
+	if err != nil {
+		return "", err
+	}
+	return streamURL, nil
+}
@@ -0,0 +1,31 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSource_RejectsBlockedHost(t *testing.T) {
+	SetHostPolicy(pipeline.NewHostPolicy(nil, []string{"blocked.example"}))
+	t.Cleanup(func() { SetHostPolicy(nil) })
+
+	_, _, _, _, err := resolveSource("https://blocked.example/track")
+	assert.True(t, errors.Is(err, pipeline.ErrHostNotAllowed))
+}
+
+func TestResolveHistoryStream_ChecksHostPolicy(t *testing.T) {
+	SetHostPolicy(pipeline.NewHostPolicy([]string{"youtube.com"}, nil))
+	t.Cleanup(func() { SetHostPolicy(nil) })
+
+	// Replays, prefetches and retries resolve through here, so a source
+	// played before the policy changed is rejected too
+	_, err := resolveHistoryStream(&common.QueueItem{OriginalURL: "https://elsewhere.example/track"})
+	assert.True(t, errors.Is(err, pipeline.ErrHostNotAllowed))
+
+	_, err = resolveQueuedStream(&common.QueueItem{URL: "stream", OriginalURL: "https://elsewhere.example/track"})
+	assert.True(t, errors.Is(err, pipeline.ErrHostNotAllowed))
+}
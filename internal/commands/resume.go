@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -13,18 +15,26 @@ func ResumeCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Get queue for this guild
 	queue := getQueue(guildID)
 	if queue == nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this guild.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this guild.", EmbedError)
 		return
 	}
 
 	// Check if there's a pipeline that can be resumed
 	pipeline := queue.GetPipeline()
 	if pipeline == nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", EmbedError)
+		return
+	}
+
+	// Only listeners in the bot's voice channel may resume playback
+	if !requireSameVoiceChannel(s, m, queue) {
+		return
+	}
+
+	if err := pipeline.Resume(); err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Could not resume playback: %v", err), EmbedError)
 		return
 	}
 
-	// For now, resume is not implemented in the current audio pipeline
-	// This would require implementing pause/resume functionality in the AudioPipeline
-	sendEmbedMessage(s, m.ChannelID, "❌ Error", "Resume functionality is not yet implemented.", 0xff0000)
+	sendEmbedMessage(s, m.ChannelID, "▶️ Playback Resumed", fmt.Sprintf("Playback resumed by %s.", m.Author.Username), EmbedSuccess)
 }
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultUmaSearchTimeout is how long an !uma search may take before it is
+// abandoned
+const DefaultUmaSearchTimeout = 15 * time.Second
+
+var (
+	umaSearchTimeout      = DefaultUmaSearchTimeout
+	umaSearchTimeoutMutex sync.RWMutex
+)
+
+// SetUmaSearchTimeout sets how long an !uma search may take before its
+// upstream requests are cancelled. Values of 0 or less restore the default.
+func SetUmaSearchTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultUmaSearchTimeout
+	}
+
+	umaSearchTimeoutMutex.Lock()
+	umaSearchTimeout = timeout
+	umaSearchTimeoutMutex.Unlock()
+}
+
+// getUmaSearchTimeout returns the configured search timeout
+func getUmaSearchTimeout() time.Duration {
+	umaSearchTimeoutMutex.RLock()
+	defer umaSearchTimeoutMutex.RUnlock()
+	return umaSearchTimeout
+}
+
+// messageEditor edits a sent message; *discordgo.Session implements it
+type messageEditor interface {
+	ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
+}
+
+// searchWithTimeout runs search with a deadline of timeout. Halfway there the
+// loading message is edited to say the search is still working. If the
+// deadline passes, search's context is cancelled, abandoning its upstream
+// requests, the loading message is edited to say the search timed out, and
+// ok is false. loading may be nil.
+func searchWithTimeout[T any](editor messageEditor, channelID string, loading *discordgo.Message, timeout time.Duration, search func(ctx context.Context) T) (result T, ok bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan T, 1)
+	go func() {
+		done <- search(ctx)
+	}()
+
+	stillWorking := time.NewTimer(timeout / 2)
+	defer stillWorking.Stop()
+
+	for {
+		select {
+		case result := <-done:
+			return result, true
+		case <-stillWorking.C:
+			editLoadingMessage(editor, channelID, loading, "⏳ Still searching, the Uma Musume API is slow today...")
+		case <-ctx.Done():
+			editLoadingMessage(editor, channelID, loading, fmt.Sprintf("⏱️ The search timed out after %s. The Uma Musume API may be slow; please try again shortly.", timeout))
+			return result, false
+		}
+	}
+}
+
+// editLoadingMessage replaces the text of a loading message, if one was sent
+func editLoadingMessage(editor messageEditor, channelID string, loading *discordgo.Message, content string) {
+	if loading == nil {
+		return
+	}
+	editor.ChannelMessageEdit(channelID, loading.ID, content)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/uma"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingEditor records the messages it is asked to edit
+type recordingEditor struct {
+	mu    sync.Mutex
+	edits []string
+}
+
+func (e *recordingEditor) ChannelMessageEdit(channelID, messageID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.edits = append(e.edits, content)
+	return &discordgo.Message{ID: messageID, ChannelID: channelID, Content: content}, nil
+}
+
+func (e *recordingEditor) contents() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.edits...)
+}
+
+// hangingDoer never answers, returning only once the request is cancelled
+type hangingDoer struct {
+	cancelled chan struct{}
+}
+
+func (d *hangingDoer) Do(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	close(d.cancelled)
+	return nil, req.Context().Err()
+}
+
+func TestSearchWithTimeout_EditsMessageAndCancels(t *testing.T) {
+	doer := &hangingDoer{cancelled: make(chan struct{})}
+	client := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithHTTPDoer(doer))
+	editor := &recordingEditor{}
+	loading := &discordgo.Message{ID: "loading-1"}
+
+	result, ok := searchWithTimeout(editor, "channel-1", loading, 100*time.Millisecond, func(ctx context.Context) *uma.CharacterSearchResult {
+		return client.SearchCharacterContext(ctx, "Oguri Cap")
+	})
+	assert.False(t, ok)
+	assert.Nil(t, result)
+
+	select {
+	case <-doer.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream request was not cancelled")
+	}
+
+	edits := editor.contents()
+	require.Len(t, edits, 2)
+	assert.Contains(t, edits[0], "Still searching")
+	assert.Contains(t, edits[1], "timed out")
+}
+
+func TestSearchWithTimeout_ReturnsFastResult(t *testing.T) {
+	editor := &recordingEditor{}
+
+	result, ok := searchWithTimeout(editor, "channel-1", &discordgo.Message{ID: "loading-1"}, time.Second, func(ctx context.Context) string {
+		return "found"
+	})
+	assert.True(t, ok)
+	assert.Equal(t, "found", result)
+	assert.Empty(t, editor.contents(), "a fast search leaves the loading message alone")
+}
+
+func TestSetUmaSearchTimeout(t *testing.T) {
+	defer SetUmaSearchTimeout(0)
+
+	SetUmaSearchTimeout(3 * time.Second)
+	assert.Equal(t, 3*time.Second, getUmaSearchTimeout())
+
+	SetUmaSearchTimeout(-time.Second)
+	assert.Equal(t, DefaultUmaSearchTimeout, getUmaSearchTimeout())
+}
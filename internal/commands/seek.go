@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// seekTarget is a parsed !seek argument
+type seekTarget struct {
+	offset   time.Duration
+	relative bool // offset is added to the current position
+}
+
+// parseSeekTarget parses "90", "1:30", "1:02:30", or a relative "+30"/"-10"
+// (which may also use the colon form, e.g. "+1:00")
+func parseSeekTarget(arg string) (seekTarget, error) {
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return seekTarget{}, errors.New("missing seek position")
+	}
+
+	var target seekTarget
+	sign := time.Duration(1)
+	switch arg[0] {
+	case '+':
+		target.relative = true
+		arg = arg[1:]
+	case '-':
+		target.relative = true
+		sign = -1
+		arg = arg[1:]
+	}
+
+	parts := strings.Split(arg, ":")
+	if len(parts) > 3 {
+		return seekTarget{}, fmt.Errorf("invalid time %q", arg)
+	}
+
+	var total time.Duration
+	for i, part := range parts {
+		if part == "" || strings.IndexFunc(part, func(r rune) bool { return r < '0' || r > '9' }) != -1 {
+			return seekTarget{}, fmt.Errorf("invalid time %q", arg)
+		}
+
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return seekTarget{}, fmt.Errorf("invalid time %q", arg)
+		}
+
+		// Minutes and seconds after a colon must be below 60
+		if i > 0 && n >= 60 {
+			return seekTarget{}, fmt.Errorf("invalid time %q", arg)
+		}
+
+		total = total*60 + time.Duration(n)*time.Second
+	}
+
+	target.offset = sign * total
+	return target, nil
+}
+
+// resolveSeekPosition turns a target into an absolute position within a track
+// of the given duration
+func resolveSeekPosition(target seekTarget, current, duration time.Duration) (time.Duration, error) {
+	position := target.offset
+	if target.relative {
+		position = current + target.offset
+	}
+
+	if position < 0 {
+		position = 0
+	}
+	if position >= duration {
+		return 0, fmt.Errorf("position %s is past the end of the track (%s)", formatDuration(position), formatDuration(duration))
+	}
+
+	return position, nil
+}
+
+// SeekCommand repositions playback within the current track
+func SeekCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	guildID := m.GuildID
+
+	// Update activity for idle monitoring
+	updateActivity(guildID)
+
+	if len(args) == 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Usage: `!seek <mm:ss>`, `!seek <seconds>`, `!seek +30`, `!seek -10` or `!seek chapter <n>`", EmbedError)
+		return
+	}
+
+	if strings.EqualFold(args[0], "chapter") {
+		seekToChapter(s, m, args[1:])
+		return
+	}
+
+	target, err := parseSeekTarget(args[0])
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Could not parse seek position: %v", err), EmbedError)
+		return
+	}
+
+	// Get queue for this guild
+	queue := getQueue(guildID)
+	if queue == nil || !queue.IsPlaying() {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing is currently playing.", EmbedError)
+		return
+	}
+
+	pipeline := queue.GetPipeline()
+	current := queue.Current()
+	if pipeline == nil || current == nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", EmbedError)
+		return
+	}
+
+	// Only listeners in the bot's voice channel may seek
+	if !requireSameVoiceChannel(s, m, queue) {
+		return
+	}
+
+	// Live streams have no known duration and can't be repositioned
+	if current.Duration <= 0 {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "This track is a live or unseekable stream.", EmbedError)
+		return
+	}
+
+	position, err := resolveSeekPosition(target, pipeline.Position(), current.Duration)
+	if err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Cannot seek: %v", err), EmbedError)
+		return
+	}
+
+	if err := pipeline.Seek(position); err != nil {
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", fmt.Sprintf("Could not seek: %v", err), EmbedError)
+		return
+	}
+
+	sendEmbedMessage(s, m.ChannelID, "⏩ Seeked", fmt.Sprintf("Jumped to %s / %s in **%s**.", formatDuration(position), formatDuration(current.Duration), current.Title), EmbedSuccess)
+}
@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeekTarget(t *testing.T) {
+	accepted := []struct {
+		arg  string
+		want seekTarget
+	}{
+		{"90", seekTarget{offset: 90 * time.Second}},
+		{"0", seekTarget{offset: 0}},
+		{"1:30", seekTarget{offset: 90 * time.Second}},
+		{"01:05", seekTarget{offset: 65 * time.Second}},
+		{"1:02:03", seekTarget{offset: time.Hour + 2*time.Minute + 3*time.Second}},
+		{"+30", seekTarget{offset: 30 * time.Second, relative: true}},
+		{"-10", seekTarget{offset: -10 * time.Second, relative: true}},
+		{"+1:00", seekTarget{offset: time.Minute, relative: true}},
+		{" 45 ", seekTarget{offset: 45 * time.Second}},
+	}
+	for _, tc := range accepted {
+		got, err := parseSeekTarget(tc.arg)
+		require.NoError(t, err, "arg %q", tc.arg)
+		assert.Equal(t, tc.want, got, "arg %q", tc.arg)
+	}
+
+	rejected := []string{"", "+", "-", "abc", "1:60", "1:2:3:4", "1::30", ":30", "1:", "1.5", "1m30s", "+-5", "1:-5"}
+	for _, arg := range rejected {
+		_, err := parseSeekTarget(arg)
+		assert.Error(t, err, "arg %q", arg)
+	}
+}
+
+func TestResolveSeekPosition(t *testing.T) {
+	duration := 3 * time.Minute
+	current := time.Minute
+
+	pos, err := resolveSeekPosition(seekTarget{offset: 90 * time.Second}, current, duration)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, pos)
+
+	pos, err = resolveSeekPosition(seekTarget{offset: 30 * time.Second, relative: true}, current, duration)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, pos)
+
+	// Rewinding past the start clamps to zero
+	pos, err = resolveSeekPosition(seekTarget{offset: -2 * time.Minute, relative: true}, current, duration)
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), pos)
+
+	// Seeking to or past the end is rejected
+	_, err = resolveSeekPosition(seekTarget{offset: duration}, current, duration)
+	assert.Error(t, err)
+	_, err = resolveSeekPosition(seekTarget{offset: 5 * time.Minute, relative: true}, current, duration)
+	assert.Error(t, err)
+}
@@ -20,21 +20,21 @@ func ShuffleCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []str
 	queue := getQueue(guildID)
 
 	if queue == nil {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No queue found for this server.", EmbedError)
 		return
 	}
 
 	// Check if queue has enough songs to shuffle
 	queueSize := queue.Size()
 	if queueSize < 2 {
-		sendEmbedMessage(s, m.ChannelID, "📭 Not Enough Songs", "Need at least 2 songs to shuffle the queue.", 0x808080)
+		sendEmbedMessage(s, m.ChannelID, "📭 Not Enough Songs", "Need at least 2 songs to shuffle the queue.", EmbedNeutral)
 		return
 	}
 
 	// Get current queue items
 	items := queue.List()
 	if len(items) == 0 {
-		sendEmbedMessage(s, m.ChannelID, "📭 Queue Empty", "No songs in queue to shuffle.", 0x808080)
+		sendEmbedMessage(s, m.ChannelID, "📭 Queue Empty", "No songs in queue to shuffle.", EmbedNeutral)
 		return
 	}
 
@@ -48,25 +48,17 @@ func ShuffleCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []str
 	}
 
 	// Create embed for shuffle confirmation
-	embed := &discordgo.MessageEmbed{
-		Title:     "🔀 Queue Shuffled",
-		Color:     0x00ff00, // Green
-		Timestamp: time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae",
+	embed := theme().NewEmbed(EmbedSuccess, "🔀 Queue Shuffled", "The queue has been shuffled successfully!", "")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "Songs Shuffled",
+			Value:  fmt.Sprintf("%d songs", queueSize),
+			Inline: true,
 		},
-		Description: "The queue has been shuffled successfully!",
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "Songs Shuffled",
-				Value:  fmt.Sprintf("%d songs", queueSize),
-				Inline: true,
-			},
-			{
-				Name:   "Shuffled By",
-				Value:  m.Author.Username,
-				Inline: true,
-			},
+		{
+			Name:   "Shuffled By",
+			Value:  m.Author.Username,
+			Inline: true,
 		},
 	}
 
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// ShutdownAudioCommand stops playback in every guild, clearing each queue and
+// leaving voice, while the bot itself stays online (bot owner only)
+func ShutdownAudioCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
+	// Check if the user is the bot owner
+	ownerID := os.Getenv("BOT_OWNER_ID")
+	if ownerID == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌ Bot owner ID not configured.")
+		return
+	}
+
+	if m.Author.ID != ownerID {
+		s.ChannelMessageSend(m.ChannelID, "❌ You don't have permission to use this command.")
+		return
+	}
+
+	stopped := stopAllQueues()
+	idle := disconnectAllVoice(s, func(vc *discordgo.VoiceConnection) {
+		if err := vc.Disconnect(); err != nil {
+			log.Printf("Failed to disconnect from voice in guild %s: %v", vc.GuildID, err)
+			return
+		}
+		log.Printf("Disconnected from voice channel in guild: %s", vc.GuildID)
+	})
+
+	if presenceManager != nil {
+		presenceManager.ClearMusicPresence()
+	}
+
+	if len(stopped) == 0 {
+		message := "Nothing was playing in any server."
+		if idle > 0 {
+			message = fmt.Sprintf("Nothing was playing, but left **%d** idle voice channel(s).", idle)
+		}
+		sendEmbedMessage(s, m.ChannelID, "🛑 Audio Shutdown", message, EmbedSuccess)
+		return
+	}
+
+	sendEmbedMessage(s, m.ChannelID, "🛑 Audio Shutdown", fmt.Sprintf("Stopped playback and cleared the queue in **%d** server(s).", len(stopped)), EmbedSuccess)
+}
+
+// stopAllQueues stops every guild's pipeline, clears its queue and history,
+// and disconnects it from voice. It returns the guilds that had playback or
+// queued tracks, sorted.
+func stopAllQueues() []string {
+	queueMutex.RLock()
+	snapshot := make(map[string]*common.MusicQueue, len(queues))
+	for guildID, queue := range queues {
+		snapshot[guildID] = queue
+	}
+	queueMutex.RUnlock()
+
+	var stopped []string
+	for guildID, queue := range snapshot {
+		active := queue.IsPlaying() || queue.GetPipeline() != nil || queue.Size() > 0
+
+		queue.Clear()
+		queue.StopAndCleanup()
+		queue.ClearHistory()
+
+		if active {
+			stopped = append(stopped, guildID)
+		}
+	}
+
+	sort.Strings(stopped)
+	return stopped
+}
+
+// disconnectAllVoice passes every voice connection the session still holds to
+// disconnect and returns how many there were. Stopped queues drop their own
+// connection, so these are the ones joined without a queue playing on them.
+func disconnectAllVoice(s *discordgo.Session, disconnect func(vc *discordgo.VoiceConnection)) int {
+	s.RLock()
+	connections := make([]*discordgo.VoiceConnection, 0, len(s.VoiceConnections))
+	for _, vc := range s.VoiceConnections {
+		connections = append(connections, vc)
+	}
+	s.RUnlock()
+
+	for _, vc := range connections {
+		disconnect(vc)
+	}
+	return len(connections)
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopAllQueues(t *testing.T) {
+	guildIDs := []string{"shutdown-guild-a", "shutdown-guild-b", "shutdown-guild-c"}
+	const idleGuildID = "shutdown-guild-idle"
+
+	pipelines := make(map[string]*common.AudioPipeline)
+	for _, guildID := range guildIDs {
+		queue := getOrCreateQueue(guildID)
+		queue.SetLoudnessAnalyzer(nil)
+		queue.Add("https://example.com/next", "Next", "tester")
+
+		pipeline := common.NewAudioPipeline(nil)
+		pipelines[guildID] = pipeline
+		queue.SetPipeline(pipeline)
+		queue.SetPlaying(true)
+	}
+	getOrCreateQueue(idleGuildID)
+
+	defer func() {
+		queueMutex.Lock()
+		for _, guildID := range append(guildIDs, idleGuildID) {
+			delete(queues, guildID)
+		}
+		queueMutex.Unlock()
+	}()
+
+	stopped := stopAllQueues()
+	assert.Equal(t, guildIDs, stopped)
+
+	for _, guildID := range guildIDs {
+		queue := getQueue(guildID)
+		assert.False(t, queue.IsPlaying(), guildID)
+		assert.Nil(t, queue.GetPipeline(), guildID)
+		assert.Zero(t, queue.Size(), guildID)
+		assert.Equal(t, common.OutcomeUserStopped, pipelines[guildID].LastOutcome().Reason, guildID)
+	}
+
+	assert.Empty(t, stopAllQueues())
+}
+
+func TestDisconnectAllVoice(t *testing.T) {
+	// An idle connection: joined, but no queue is playing on it
+	idle := &discordgo.VoiceConnection{GuildID: "shutdown-guild-idle", ChannelID: "voice-channel"}
+	s := &discordgo.Session{VoiceConnections: map[string]*discordgo.VoiceConnection{idle.GuildID: idle}}
+
+	var disconnected []*discordgo.VoiceConnection
+	left := disconnectAllVoice(s, func(vc *discordgo.VoiceConnection) {
+		disconnected = append(disconnected, vc)
+	})
+
+	assert.Equal(t, 1, left)
+	assert.Equal(t, []*discordgo.VoiceConnection{idle}, disconnected)
+
+	assert.Zero(t, disconnectAllVoice(&discordgo.Session{}, func(*discordgo.VoiceConnection) {
+		t.Fatal("nothing to disconnect without voice connections")
+	}))
+}
@@ -13,7 +13,12 @@ func SkipCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	// Get queue for this guild
 	queue := getQueue(guildID)
 	if queue == nil || !queue.IsPlaying() {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing is currently playing.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "Nothing is currently playing.", EmbedError)
+		return
+	}
+
+	// Only listeners in the bot's voice channel may skip
+	if !requireSameVoiceChannel(s, m, queue) {
 		return
 	}
 
@@ -26,7 +31,8 @@ func SkipCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	}
 
 	// Stop current pipeline and mark as skipped
-	if pipeline := queue.GetPipeline(); pipeline != nil {
+	pipeline := queue.GetPipeline()
+	if pipeline != nil {
 		// Set a flag to indicate this was a skip operation
 		queue.SetSkipped(true)
 		pipeline.Stop()
@@ -36,9 +42,10 @@ func SkipCommand(s *discordgo.Session, m *discordgo.MessageCreate) {
 	if currentSong != nil {
 		sendSongSkippedEmbed(s, m.ChannelID, songTitle, requestedBy, m.Author.Username)
 	} else {
-		sendEmbedMessage(s, m.ChannelID, "⏭️ Song Skipped", "Current song has been skipped.", 0xffa500)
+		sendEmbedMessage(s, m.ChannelID, "⏭️ Song Skipped", "Current song has been skipped.", EmbedWarning)
 	}
 
-	// Start next song in queue
-	startNextInQueue(s, m, queue)
+	// Start next song in queue, unless the stopped pipeline's monitor
+	// already has
+	advanceQueue(s, m, queue, pipeline)
 }
@@ -15,7 +15,12 @@ func StopCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string
 	// Get queue for this guild
 	queue := getQueue(guildID)
 	if queue == nil || !queue.IsPlaying() {
-		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", 0xff0000)
+		sendEmbedMessage(s, m.ChannelID, "❌ Error", "No audio is currently playing.", EmbedError)
+		return
+	}
+
+	// Only listeners in the bot's voice channel may stop playback
+	if !requireSameVoiceChannel(s, m, queue) {
 		return
 	}
 
@@ -26,6 +31,8 @@ func StopCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string
 
 	// Clear queue and stop playing
 	queue.Clear()
+	queue.ClearHistory()
+	queue.ClearFailedTracks()
 	queue.SetPlaying(false)
 
 	// Clear presence
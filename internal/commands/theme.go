@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/internal/config"
+)
+
+// EmbedEvent names the kind of message an embed reports, which picks its color
+type EmbedEvent string
+
+const (
+	EmbedSuccess EmbedEvent = "success" // completed actions, now playing
+	EmbedError   EmbedEvent = "error"   // failures and usage errors
+	EmbedWarning EmbedEvent = "warning" // skips, idle disconnects
+	EmbedInfo    EmbedEvent = "info"    // listings such as the queue
+	EmbedNeutral EmbedEvent = "neutral" // empty states, queue ended
+)
+
+// EmbedTheme holds the branding applied to the bot's embeds
+type EmbedTheme struct {
+	Colors          map[EmbedEvent]int
+	Footer          string // Base footer text; sections are appended as "Footer | Section"
+	TimestampFormat string
+}
+
+// DefaultEmbedTheme returns the built-in Hokko Tarumae theme
+func DefaultEmbedTheme() EmbedTheme {
+	return EmbedTheme{
+		Colors: map[EmbedEvent]int{
+			EmbedSuccess: 0x00ff00, // Green
+			EmbedError:   0xff0000, // Red
+			EmbedWarning: 0xffa500, // Orange
+			EmbedInfo:    0x0099ff, // Blue
+			EmbedNeutral: 0x808080, // Gray
+		},
+		Footer:          "Hokko Tarumae",
+		TimestampFormat: time.RFC3339,
+	}
+}
+
+// EmbedThemeFromConfig overlays configured colors, footer and timestamp format
+// onto the default theme
+func EmbedThemeFromConfig(cfg *config.Config) EmbedTheme {
+	theme := DefaultEmbedTheme()
+	if cfg == nil {
+		return theme
+	}
+
+	for name, color := range cfg.EmbedColors {
+		theme.Colors[EmbedEvent(name)] = color
+	}
+	if cfg.EmbedFooter != "" {
+		theme.Footer = cfg.EmbedFooter
+	}
+	if cfg.EmbedTimestampFormat != "" {
+		theme.TimestampFormat = cfg.EmbedTimestampFormat
+	}
+	return theme
+}
+
+// Color returns the color for an event, falling back to the default theme
+func (t EmbedTheme) Color(event EmbedEvent) int {
+	if color, ok := t.Colors[event]; ok {
+		return color
+	}
+	return DefaultEmbedTheme().Colors[event]
+}
+
+// FooterText returns the footer, followed by the section name when one is given
+func (t EmbedTheme) FooterText(section string) string {
+	section = strings.TrimSpace(section)
+	switch {
+	case section == "":
+		return t.Footer
+	case t.Footer == "":
+		return section
+	default:
+		return t.Footer + " | " + section
+	}
+}
+
+// Timestamp formats an embed timestamp. Discord expects ISO 8601, so custom
+// formats must stay compatible with it.
+func (t EmbedTheme) Timestamp(at time.Time) string {
+	format := t.TimestampFormat
+	if format == "" {
+		format = time.RFC3339
+	}
+	return at.Format(format)
+}
+
+// NewEmbed builds an embed with the theme's color, timestamp and footer applied
+func (t EmbedTheme) NewEmbed(event EmbedEvent, title, description, section string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: description,
+		Color:       t.Color(event),
+		Timestamp:   t.Timestamp(time.Now()),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: t.FooterText(section),
+		},
+	}
+}
+
+var (
+	embedTheme   = DefaultEmbedTheme()
+	embedThemeMu sync.RWMutex
+)
+
+// SetEmbedTheme sets the theme used by all embed helpers
+func SetEmbedTheme(theme EmbedTheme) {
+	embedThemeMu.Lock()
+	defer embedThemeMu.Unlock()
+	embedTheme = theme
+}
+
+// theme returns the active embed theme
+func theme() EmbedTheme {
+	embedThemeMu.RLock()
+	defer embedThemeMu.RUnlock()
+	return embedTheme
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/uma"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultEmbedThemeKeepsBranding(t *testing.T) {
+	theme := DefaultEmbedTheme()
+
+	assert.Equal(t, 0x00ff00, theme.Color(EmbedSuccess))
+	assert.Equal(t, 0xff0000, theme.Color(EmbedError))
+	assert.Equal(t, "Hokko Tarumae", theme.FooterText(""))
+	assert.Equal(t, "Hokko Tarumae | UMA Cache Statistics", theme.FooterText("UMA Cache Statistics"))
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, "2026-01-02T03:04:05Z", theme.Timestamp(at))
+}
+
+func TestEmbedThemeFromConfig(t *testing.T) {
+	theme := EmbedThemeFromConfig(&config.Config{
+		EmbedColors:          map[string]int{"error": 0x123456},
+		EmbedFooter:          "Tarumae Radio",
+		EmbedTimestampFormat: "2006-01-02T15:04:05.000Z07:00",
+	})
+
+	assert.Equal(t, 0x123456, theme.Color(EmbedError))
+	assert.Equal(t, 0x00ff00, theme.Color(EmbedSuccess), "unset events keep the default color")
+
+	embed := theme.NewEmbed(EmbedError, "❌ Error", "boom", "Uma Musume Character Search")
+	assert.Equal(t, "❌ Error", embed.Title)
+	assert.Equal(t, "boom", embed.Description)
+	assert.Equal(t, 0x123456, embed.Color)
+	require.NotNil(t, embed.Footer)
+	assert.Equal(t, "Tarumae Radio | Uma Musume Character Search", embed.Footer.Text)
+
+	_, err := time.Parse("2006-01-02T15:04:05.000Z07:00", embed.Timestamp)
+	assert.NoError(t, err)
+}
+
+func TestEmbedHelpersUseActiveTheme(t *testing.T) {
+	SetEmbedTheme(EmbedTheme{
+		Colors: map[EmbedEvent]int{EmbedSuccess: 0xabcdef},
+		Footer: "Rebranded",
+	})
+	t.Cleanup(func() { SetEmbedTheme(DefaultEmbedTheme()) })
+
+	embed := createSimplifiedSkillsEmbed(&uma.SimplifiedSupportCard{
+		NameJp:   "テスト",
+		CharName: "Test",
+		Rarity:   3,
+	})
+	require.NotNil(t, embed.Footer)
+	assert.Equal(t, "Rebranded | Data from Gametora API", embed.Footer.Text)
+	assert.NotEmpty(t, embed.Timestamp)
+
+	assert.Equal(t, 0xabcdef, theme().Color(EmbedSuccess))
+	assert.Equal(t, 0xff0000, theme().Color(EmbedError), "missing colors fall back to the default theme")
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"sync"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// DefaultMaxTrackFailures is how many tracks in a row may fail before the
+// playback session ends
+const DefaultMaxTrackFailures = 3
+
+var (
+	maxTrackFailures      = DefaultMaxTrackFailures
+	maxTrackFailuresMutex sync.RWMutex
+)
+
+// SetMaxTrackFailures sets how many consecutive track failures end a
+// session. Values of 0 or less restore the default.
+func SetMaxTrackFailures(n int) {
+	if n <= 0 {
+		n = DefaultMaxTrackFailures
+	}
+
+	maxTrackFailuresMutex.Lock()
+	maxTrackFailures = n
+	maxTrackFailuresMutex.Unlock()
+}
+
+// getMaxTrackFailures returns the configured consecutive failure limit
+func getMaxTrackFailures() int {
+	maxTrackFailuresMutex.RLock()
+	defer maxTrackFailuresMutex.RUnlock()
+	return maxTrackFailures
+}
+
+// trackFailureAction is what the finish monitor does after a track fails
+type trackFailureAction int
+
+const (
+	skipFailedTrack trackFailureAction = iota // announce the failure and play the next track
+	endSession                                // announce the failure and stop playback
+)
+
+// handleTrackFailure counts a failed track against the queue and decides
+// whether the session survives it. Session-level errors end it at once;
+// track-level ones end it only after the guild's consecutive failure limit.
+// Either way the track is remembered for !queue retryfailed.
+func handleTrackFailure(queue *common.MusicQueue, item *common.QueueItem, err error) trackFailureAction {
+	queue.RecordFailedTrack(item)
+	if common.IsSessionError(err) {
+		return endSession
+	}
+	if queue.RecordTrackFailure() >= guildMaxTrackFailures(queue.GuildID()) {
+		return endSession
+	}
+	return skipFailedTrack
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// playOutcome plays a track through streamer and returns how it ended
+func playOutcome(t *testing.T, streamer common.Streamer) common.Outcome {
+	t.Helper()
+	pipeline := common.NewAudioPipeline(nil)
+	pipeline.SetStreamer(streamer)
+	require.NoError(t, pipeline.PlayStream("https://stream.example/track"))
+	defer pipeline.Stop()
+
+	deadline := time.Now().Add(time.Second)
+	for pipeline.LastOutcome().IsZero() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	outcome := pipeline.LastOutcome()
+	require.False(t, outcome.IsZero(), "pipeline did not finish")
+	return outcome
+}
+
+func TestFailingTrackIsSkipped(t *testing.T) {
+	SetMaxTrackFailures(2)
+	defer SetMaxTrackFailures(0)
+
+	good := func(ctx context.Context, streamURL string) error { return nil }
+	bad := func(ctx context.Context, streamURL string) error { return errors.New("unsupported codec") }
+
+	queue := common.NewMusicQueue("track-failures-guild")
+	for _, streamer := range []common.Streamer{good, bad, good, bad, good} {
+		outcome := playOutcome(t, streamer)
+		switch outcome.Reason {
+		case common.OutcomeError:
+			assert.False(t, outcome.SessionFailure())
+			assert.Equal(t, skipFailedTrack, handleTrackFailure(queue, nil, outcome.Err))
+		case common.OutcomeCompleted:
+			queue.ResetTrackFailures()
+		default:
+			t.Fatalf("unexpected outcome %s", outcome)
+		}
+	}
+}
+
+func TestConsecutiveTrackFailuresEndSession(t *testing.T) {
+	SetMaxTrackFailures(3)
+	defer SetMaxTrackFailures(0)
+
+	queue := common.NewMusicQueue("track-failures-guild")
+	trackErr := errors.New("403 forbidden")
+
+	assert.Equal(t, skipFailedTrack, handleTrackFailure(queue, nil, trackErr))
+	assert.Equal(t, skipFailedTrack, handleTrackFailure(queue, nil, trackErr))
+	assert.Equal(t, endSession, handleTrackFailure(queue, nil, trackErr))
+
+	// Ending the session starts the count over
+	queue.StopAndCleanup()
+	assert.Equal(t, skipFailedTrack, handleTrackFailure(queue, nil, trackErr))
+}
+
+func TestSessionErrorEndsSessionImmediately(t *testing.T) {
+	queue := common.NewMusicQueue("track-failures-guild")
+
+	outcome := playOutcome(t, func(ctx context.Context, streamURL string) error {
+		return fmt.Errorf("timeout waiting for voice connection: %w", common.ErrVoiceUnavailable)
+	})
+	require.Equal(t, common.OutcomeError, outcome.Reason)
+	assert.True(t, outcome.SessionFailure())
+	assert.Equal(t, endSession, handleTrackFailure(queue, nil, outcome.Err))
+
+	assert.Equal(t, endSession, handleTrackFailure(queue, nil, fmt.Errorf("start: %w", pipeline.ErrFFmpegNotFound)))
+}
+
+func TestFailedTracksAreKeptForRetry(t *testing.T) {
+	SetMaxTrackFailures(3)
+	defer SetMaxTrackFailures(0)
+
+	queue := common.NewMusicQueue("track-failures-retry-guild")
+	queue.Add("https://stream.example/one", "One", "tester")
+	queue.Add("https://stream.example/two", "Two", "tester")
+
+	bad := func(ctx context.Context, streamURL string) error { return errors.New("503 service unavailable") }
+	for item := queue.Next(); item != nil; item = queue.Next() {
+		outcome := playOutcome(t, bad)
+		require.Equal(t, common.OutcomeError, outcome.Reason)
+		assert.Equal(t, skipFailedTrack, handleTrackFailure(queue, item, outcome.Err))
+	}
+	require.Len(t, queue.FailedTracks(), 2)
+
+	requeued, failed := queue.RetryFailedTracks(func(item *common.QueueItem) (string, error) {
+		return item.URL + "?fresh", nil
+	})
+	assert.Equal(t, 2, requeued)
+	assert.Equal(t, 0, failed)
+	assert.Empty(t, queue.FailedTracks())
+
+	items := queue.List()
+	require.Len(t, items, 2)
+	assert.Equal(t, "One", items[0].Title)
+	assert.Equal(t, "https://stream.example/one?fresh", items[0].URL)
+}
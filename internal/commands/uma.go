@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -25,10 +27,24 @@ func InitializeUmaCommands(db *database.Database) {
 // InitializeGametoraClient initializes the global gametora client with configuration
 func InitializeGametoraClient(cfg interface{}) {
 	if config, ok := cfg.(*config.Config); ok {
+		uma.SetMaxConcurrentSearches(config.MaxUmaSearches, 0)
+		SetUmaSearchTimeout(config.UmaSearchTimeout)
 		gametoraClient = uma.NewGametoraClient(config)
 	}
 }
 
+// CloseUmaClients closes the Uma Musume API clients on shutdown
+func CloseUmaClients() {
+	if err := umaClient.Close(); err != nil {
+		log.Printf("Error closing Uma Musume client: %v", err)
+	}
+	if gametoraClient != nil {
+		if err := gametoraClient.Close(); err != nil {
+			log.Printf("Error closing Gametora client: %v", err)
+		}
+	}
+}
+
 // UmaCommand handles Uma Musume related commands
 func UmaCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
 	if len(args) == 0 {
@@ -45,12 +61,20 @@ func UmaCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
 		SupportCommand(s, m, args[1:])
 	case "skills":
 		SkillsCommand(s, m, args[1:])
+	case "list":
+		SupportListCommand(s, m, args[1:])
+	case "effect":
+		EffectSearchCommand(s, m, args[1:])
 	case "refresh":
 		StableRefreshCommand(s, m, args[1:])
+	case "reload":
+		UmaReloadCommand(s, m, args[1:])
 	case "cache":
 		CacheStatsCommand(s, m, args[1:])
+	case "version":
+		UmaVersionCommand(s, m, args[1:])
 	default:
-		s.ChannelMessageSend(m.ChannelID, "❌ Unknown subcommand.\n\n**Available subcommands:**\n• `char <name>` - Search for a character\n• `support <name>` - Search for a support card (list view)\n• `skills <name>` - Get skills for a support card (Gametora API)\n• `refresh` - Refresh the Gametora API build ID\n• `cache` - Show cache statistics\n\n**Examples:**\n• `!uma char Oguri Cap`\n• `!uma support daring tact`\n• `!uma skills daring tact`\n• `!uma refresh`\n• `!uma cache`")
+		s.ChannelMessageSend(m.ChannelID, "❌ Unknown subcommand.\n\n**Available subcommands:**\n• `char <name>` - Search for a character\n• `support <name>` - Search for a support card (list view)\n• `skills <name> [type] [rarity]` - Get skills for a support card (Gametora API)\n• `list [type] [rarity]` - Browse all support cards\n• `effect <effect> [rarity]` - Find support cards with an effect\n• `refresh` - Refresh the Gametora API build ID\n• `reload` - Reload all Gametora data after a game update\n• `cache` - Show cache statistics\n• `version` - Show the Gametora build ID in use\n\n**Examples:**\n• `!uma char Oguri Cap`\n• `!uma support daring tact`\n• `!uma skills daring tact`\n• `!uma list speed ssr`\n• `!uma effect friendship bonus ssr`\n• `!uma refresh`\n• `!uma cache`\n• `!uma version`")
 	}
 }
 
@@ -68,59 +92,27 @@ func CharacterCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []s
 	// Send a loading message
 	loadingMsg, _ := s.ChannelMessageSend(m.ChannelID, "🔍 Searching for character...")
 
-	// Search for character with caching
-	var result *uma.CharacterSearchResult
-
-	// Check database cache first
-	if umaDB != nil {
-		if cached, err := umaDB.GetCachedCharacterSearch(query); err == nil && cached != nil {
-			result = cached
-		} else {
-			// If not in cache, search using the original client
-			result = umaClient.SearchCharacter(query)
-
-			// Cache the result if found or if it's a valid error response
-			if result != nil {
-				if err := umaDB.CacheCharacterSearch(query, result, 24*time.Hour); err != nil {
-					// Log error but don't fail the request
-					fmt.Printf("Failed to cache character search: %v\n", err)
-				}
-			}
-		}
-	} else {
-		// Fallback to original client if database is not available
-		result = umaClient.SearchCharacter(query)
+	// Search with caching, giving up if upstream is too slow
+	result, ok := searchWithTimeout(s, m.ChannelID, loadingMsg, getUmaSearchTimeout(), func(ctx context.Context) *uma.CharacterSearchResult {
+		return lookupCharacter(ctx, query)
+	})
+	if !ok {
+		return
 	}
 
 	// Delete the loading message
 	s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
 
 	if !result.Found {
-		// Create error embed
-		embed := &discordgo.MessageEmbed{
-			Title:       "❌ Character Not Found",
-			Description: fmt.Sprintf("Could not find character: **%s**", query),
-			Color:       0xff0000, // Red color
-			Timestamp:   time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae | Uma Musume Character Search",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "💡 Tips",
-					Value:  "• Try using the Japanese name\n• Check spelling and try alternative names\n• Try partial names (e.g., 'oguri' for 'Oguri Cap')",
-					Inline: false,
-				},
-			},
-		}
-
-		if result.Error != nil {
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:   "🔧 Error",
-				Value:  result.Error.Error(),
-				Inline: false,
-			})
-		}
+		embed := searchFailureEmbed(searchFailure{
+			reason:  uma.FailureReason(result.Reason, result.Error),
+			subject: "Character",
+			query:   query,
+			filter:  uma.SupportCardFilter{},
+			section: "Uma Musume Character Search",
+			tips:    "• Try using the Japanese name\n• Check spelling and try alternative names\n• Try partial names (e.g., 'oguri' for 'Oguri Cap')",
+			err:     result.Error,
+		})
 
 		s.ChannelMessageSendEmbed(m.ChannelID, embed)
 		return
@@ -137,8 +129,15 @@ func CharacterCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []s
 			// If not in cache, fetch using the original client
 			imagesResult = umaClient.GetCharacterImages(result.Character.ID)
 
+			// Serve an expired entry rather than the error if upstream is down
+			if imagesResult != nil && imagesResult.Error != nil {
+				if stale, err := umaDB.GetStaleCharacterImages(result.Character.ID); err == nil && stale != nil {
+					imagesResult = stale
+				}
+			}
+
 			// Cache the result if found or if it's a valid error response
-			if imagesResult != nil {
+			if imagesResult != nil && !imagesResult.Stale {
 				if err := umaDB.CacheCharacterImages(result.Character.ID, imagesResult, 24*time.Hour); err != nil {
 					// Log error but don't fail the request
 					fmt.Printf("Failed to cache character images: %v\n", err)
@@ -152,6 +151,9 @@ func CharacterCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []s
 
 	// Create success embed with image navigation
 	embed := navigationManager.CreateCharacterEmbed(result.Character, imagesResult, 0)
+	if result.Stale || imagesResult.Stale {
+		markStale(embed)
+	}
 
 	// Send the initial embed
 	msg, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
@@ -193,7 +195,95 @@ func SupportCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []str
 	// Send a loading message
 	loadingMsg, _ := s.ChannelMessageSend(m.ChannelID, "🔍 Searching for support card...")
 
-	// Search for support card with caching
+	// Search with caching, giving up if upstream is too slow
+	result, ok := searchWithTimeout(s, m.ChannelID, loadingMsg, getUmaSearchTimeout(), func(ctx context.Context) *uma.SupportCardSearchResult {
+		return lookupSupportCard(ctx, query)
+	})
+	if !ok {
+		return
+	}
+
+	// Delete the loading message
+	s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
+
+	if !result.Found {
+		embed := searchFailureEmbed(searchFailure{
+			reason:  uma.FailureReason(result.Reason, result.Error),
+			subject: "Support Card",
+			query:   query,
+			filter:  uma.SupportCardFilter{},
+			section: "Uma Musume Support Card Search",
+			tips:    "• Try using the English title\n• Try using the Japanese title\n• Try using the gametora identifier\n• Check spelling and try alternative names",
+			err:     result.Error,
+		})
+
+		s.ChannelMessageSendEmbed(m.ChannelID, embed)
+		return
+	}
+
+	// Create success embed
+	embed := createSupportCardEmbed(result.SupportCard)
+
+	// If there are multiple versions, add them to the embed
+	if len(result.SupportCards) > 1 {
+		embed = createMultiVersionSupportCardEmbed(result.SupportCards)
+	}
+
+	if result.SkippedCount > 0 {
+		markSkippedVersions(embed, result.SkippedCount)
+	}
+	if result.Stale {
+		markStale(embed)
+	}
+
+	// Send the embed
+	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ Failed to send support card information.")
+		return
+	}
+}
+
+// lookupCharacter searches for a character, serving cached and stale
+// results from the database when it is available
+func lookupCharacter(ctx context.Context, query string) *uma.CharacterSearchResult {
+	var result *uma.CharacterSearchResult
+
+	// Check database cache first
+	if umaDB != nil {
+		if cached, err := umaDB.GetCachedCharacterSearch(query); err == nil && cached != nil {
+			result = cached
+		} else {
+			// If not in cache, search using the original client
+			result = umaClient.SearchCharacterContext(ctx, query)
+
+			// Serve an expired entry rather than the error if upstream is down
+			if result != nil && result.Error != nil {
+				if stale, err := umaDB.GetStaleCharacterSearch(query); err == nil && stale != nil {
+					result = stale
+				}
+			}
+
+			// Cache the result if found or if it's a valid error response, but not
+			// a search abandoned at the timeout
+			if result != nil && !result.Stale && ctx.Err() == nil {
+				if err := umaDB.CacheCharacterSearch(query, result, 24*time.Hour); err != nil {
+					// Log error but don't fail the request
+					fmt.Printf("Failed to cache character search: %v\n", err)
+				}
+			}
+		}
+	} else {
+		// Fallback to original client if database is not available
+		result = umaClient.SearchCharacterContext(ctx, query)
+	}
+
+	return result
+}
+
+// lookupSupportCard searches for a support card, serving cached and stale
+// results from the database when it is available
+func lookupSupportCard(ctx context.Context, query string) *uma.SupportCardSearchResult {
 	var result *uma.SupportCardSearchResult
 
 	// Check database cache first
@@ -202,10 +292,18 @@ func SupportCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []str
 			result = cached
 		} else {
 			// If not in cache, search using the original client
-			result = umaClient.SearchSupportCard(query)
+			result = umaClient.SearchSupportCardContext(ctx, query)
 
-			// Cache the result if found or if it's a valid error response
-			if result != nil {
+			// Serve an expired entry rather than the error if upstream is down
+			if result != nil && result.Error != nil {
+				if stale, err := umaDB.GetStaleSupportCardSearch(query); err == nil && stale != nil {
+					result = stale
+				}
+			}
+
+			// Cache the result if found or if it's a valid error response, but not
+			// a search abandoned at the timeout
+			if result != nil && !result.Stale && ctx.Err() == nil {
 				if err := umaDB.CacheSupportCardSearch(query, result, 24*time.Hour); err != nil {
 					// Log error but don't fail the request
 					fmt.Printf("Failed to cache support card search: %v\n", err)
@@ -214,57 +312,112 @@ func SupportCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []str
 		}
 	} else {
 		// Fallback to original client if database is not available
-		result = umaClient.SearchSupportCard(query)
+		result = umaClient.SearchSupportCardContext(ctx, query)
 	}
 
-	// Delete the loading message
-	s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
+	return result
+}
 
-	if !result.Found {
-		// Create error embed
-		embed := &discordgo.MessageEmbed{
-			Title:       "❌ Support Card Not Found",
-			Description: fmt.Sprintf("Could not find support card: **%s**", query),
-			Color:       0xff0000, // Red color
-			Timestamp:   time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae | Uma Musume Support Card Search",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "💡 Tips",
-					Value:  "• Try using the English title\n• Try using the Japanese title\n• Try using the gametora identifier\n• Check spelling and try alternative names",
-					Inline: false,
-				},
-			},
-		}
+// lookupGametoraSkills searches Gametora for a support card, serving cached
+// and stale results from the database when it is available
+func lookupGametoraSkills(ctx context.Context, query string) *uma.SimplifiedGametoraSearchResult {
+	var result *uma.SimplifiedGametoraSearchResult
 
-		if result.Error != nil {
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:   "🔧 Error",
-				Value:  result.Error.Error(),
-				Inline: false,
-			})
+	// Check database cache first
+	if umaDB != nil {
+		if cached, err := umaDB.GetCachedGametoraSkills(query); err == nil && cached != nil {
+			result = cached
+		} else {
+			// If not in cache, search using the Gametora client
+			result = gametoraClient.SearchSimplifiedSupportCardContext(ctx, query)
+
+			// Serve an expired entry rather than the error if upstream is down
+			if result != nil && result.Error != nil {
+				if stale, err := umaDB.GetStaleGametoraSkills(query); err == nil && stale != nil {
+					result = stale
+				}
+			}
+
+			// Cache the result if found or if it's a valid error response, but not
+			// a search abandoned at the timeout
+			if result != nil && !result.Stale && ctx.Err() == nil {
+				if err := umaDB.CacheGametoraSkills(query, result, 24*time.Hour); err != nil {
+					// Log error but don't fail the request
+					fmt.Printf("Failed to cache Gametora skills: %v\n", err)
+				}
+			}
 		}
+	} else {
+		// Fallback to original client if database is not available
+		result = gametoraClient.SearchSimplifiedSupportCardContext(ctx, query)
+	}
 
-		s.ChannelMessageSendEmbed(m.ChannelID, embed)
-		return
+	return result
+}
+
+// searchFailure describes a search that found nothing
+type searchFailure struct {
+	reason  uma.SearchReason
+	subject string // what was searched for, e.g. "Support Card"
+	query   string
+	filter  uma.SupportCardFilter // the filter that excluded every match, if any
+	section string                // footer section
+	tips    string                // search tips shown when nothing matched
+	err     error
+}
+
+// searchFailureEmbed explains why a search found nothing: no match, every
+// match filtered out, or the API being unavailable
+func searchFailureEmbed(failure searchFailure) *discordgo.MessageEmbed {
+	var embed *discordgo.MessageEmbed
+	switch failure.reason {
+	case uma.FilteredOut:
+		embed = theme().NewEmbed(EmbedWarning, "❌ No Matching Version",
+			fmt.Sprintf("No %s version of **%s** was found.", failure.filter, failure.query), failure.section)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "💡 Tips",
+			Value: "• Drop the rarity or type to see every version",
+		})
+	case uma.UpstreamError:
+		embed = theme().NewEmbed(EmbedError, "⚠️ Search Unavailable",
+			fmt.Sprintf("Could not search for %s **%s** right now. Please try again later.", strings.ToLower(failure.subject), failure.query), failure.section)
+	default:
+		embed = theme().NewEmbed(EmbedError, fmt.Sprintf("❌ %s Not Found", failure.subject),
+			fmt.Sprintf("Could not find %s: **%s**", strings.ToLower(failure.subject), failure.query), failure.section)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "💡 Tips",
+			Value: failure.tips,
+		})
 	}
 
-	// Create success embed
-	embed := createSupportCardEmbed(result.SupportCard)
+	if failure.err != nil {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "🔧 Error",
+			Value: failure.err.Error(),
+		})
+	}
+	return fitEmbed(embed)
+}
 
-	// If there are multiple versions, add them to the embed
-	if len(result.SupportCards) > 1 {
-		embed = createMultiVersionSupportCardEmbed(result.SupportCards)
+// markStale footnotes an embed built from an expired cache entry
+func markStale(embed *discordgo.MessageEmbed) {
+	if embed.Footer == nil {
+		embed.Footer = &discordgo.MessageEmbedFooter{}
 	}
+	embed.Footer.Text = strings.TrimSpace(embed.Footer.Text + " (cached, possibly outdated)")
+}
 
-	// Send the embed
-	_, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "❌ Failed to send support card information.")
-		return
+// markSkippedVersions footnotes an embed missing versions whose details
+// could not be fetched
+func markSkippedVersions(embed *discordgo.MessageEmbed, skipped int) {
+	if embed.Footer == nil {
+		embed.Footer = &discordgo.MessageEmbedFooter{}
+	}
+	noun := "versions"
+	if skipped == 1 {
+		noun = "version"
 	}
+	embed.Footer.Text = strings.TrimSpace(fmt.Sprintf("%s (%d %s unavailable)", embed.Footer.Text, skipped, noun))
 }
 
 // createSupportCardEmbed creates an embed for a support card
@@ -287,7 +440,7 @@ func createSupportCardEmbed(supportCard *uma.SupportCard) *discordgo.MessageEmbe
 		Title:       supportCard.TitleEn,
 		Description: supportCard.Title,
 		Color:       color,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: "Data from umapyoi.net",
 		},
@@ -334,69 +487,38 @@ func createSupportCardEmbed(supportCard *uma.SupportCard) *discordgo.MessageEmbe
 func SkillsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
 	// Check if user provided a support card name
 	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "❌ Please provide a support card name to get skills for.\n\n**Usage:** `!uma skills <support card name>`\n**Example:** `!uma skills daring tact`")
+		s.ChannelMessageSend(m.ChannelID, "❌ Please provide a support card name to get skills for.\n\n**Usage:** `!uma skills <support card name> [type] [rarity]`\n**Example:** `!uma skills daring tact ssr`")
 		return
 	}
 
-	// Join the arguments to form the search query
-	query := strings.Join(args, " ")
+	// Split trailing type and rarity arguments from the search query
+	query, filter := uma.SplitSupportCardFilter(args)
 
 	// Send a loading message
 	loadingMsg, _ := s.ChannelMessageSend(m.ChannelID, "🔍 Searching for support card skills using Gametora API...")
 
-	// Search for support card using Gametora API with caching
-	var result *uma.SimplifiedGametoraSearchResult
-
-	// Check database cache first
-	if umaDB != nil {
-		if cached, err := umaDB.GetCachedGametoraSkills(query); err == nil && cached != nil {
-			result = cached
-		} else {
-			// If not in cache, search using the Gametora client
-			result = gametoraClient.SearchSimplifiedSupportCard(query)
-
-			// Cache the result if found or if it's a valid error response
-			if result != nil {
-				if err := umaDB.CacheGametoraSkills(query, result, 24*time.Hour); err != nil {
-					// Log error but don't fail the request
-					fmt.Printf("Failed to cache Gametora skills: %v\n", err)
-				}
-			}
-		}
-	} else {
-		// Fallback to original client if database is not available
-		result = gametoraClient.SearchSimplifiedSupportCard(query)
+	// Search with caching, giving up if upstream is too slow
+	result, ok := searchWithTimeout(s, m.ChannelID, loadingMsg, getUmaSearchTimeout(), func(ctx context.Context) *uma.SimplifiedGametoraSearchResult {
+		return lookupGametoraSkills(ctx, query)
+	})
+	if !ok {
+		return
 	}
 
 	// Delete the loading message
 	s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
 
+	result = result.ApplyFilter(filter)
 	if !result.Found {
-		// Create error embed
-		embed := &discordgo.MessageEmbed{
-			Title:       "❌ Support Card Not Found",
-			Description: fmt.Sprintf("Could not find support card: **%s**", query),
-			Color:       0xff0000, // Red color
-			Timestamp:   time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae | Uma Musume Support Card Skills (Gametora API)",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "💡 Tips",
-					Value:  "• Try using the English title\n• Try using the Japanese title\n• Try using the gametora identifier\n• Check spelling and try alternative names\n• Try partial names",
-					Inline: false,
-				},
-			},
-		}
-
-		if result.Error != nil {
-			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-				Name:   "🔧 Error",
-				Value:  result.Error.Error(),
-				Inline: false,
-			})
-		}
+		embed := searchFailureEmbed(searchFailure{
+			reason:  uma.FailureReason(result.Reason, result.Error),
+			subject: "Support Card",
+			query:   query,
+			filter:  filter,
+			section: "Uma Musume Support Card Skills (Gametora API)",
+			tips:    "• Try using the English title\n• Try using the Japanese title\n• Try using the gametora identifier\n• Check spelling and try alternative names\n• Try partial names",
+			err:     result.Error,
+		})
 
 		s.ChannelMessageSendEmbed(m.ChannelID, embed)
 		return
@@ -415,6 +537,10 @@ func SkillsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 		embed = createSimplifiedSkillsEmbed(result.SupportCard)
 	}
 
+	if result.Stale {
+		markStale(embed)
+	}
+
 	// Send the embed
 	msg, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
 	if err != nil {
@@ -459,6 +585,132 @@ func SkillsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []stri
 	}
 }
 
+// SupportListCommand pages through the cached support card list, optionally
+// filtered by type and rarity
+func SupportListCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	filter, err := uma.ParseSupportCardFilter(args)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ %v.\n\n**Usage:** `!uma list [type] [rarity]`\n**Types:** speed, stamina, power, guts, intelligence, friend, group\n**Rarities:** SSR, SR, R\n**Example:** `!uma list speed ssr`", err))
+		return
+	}
+
+	if gametoraClient == nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ Gametora client is not initialized.")
+		return
+	}
+
+	// Fetches the full list on a cold cache, otherwise served locally
+	loadingMsg, _ := s.ChannelMessageSend(m.ChannelID, "📚 Loading support cards...")
+	cards, err := gametoraClient.GetAllSupportCards()
+	if loadingMsg != nil {
+		s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
+	}
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ Failed to load support cards: %v", err))
+		return
+	}
+
+	filtered := uma.FilterSupportCards(cards, filter)
+	pageSize := uma.DefaultSupportListPageSize
+
+	listManager := navigation.GetSupportCardListManager()
+	embed := listManager.CreateListEmbed(filtered, filter, 0, pageSize)
+
+	msg, err := s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ Failed to send support card list.")
+		return
+	}
+
+	// Register paging if there is more than one page
+	if uma.PageCount(len(filtered), pageSize) > 1 {
+		listManager.RegisterSupportCardList(msg.ID, filtered, filter, pageSize, m.ChannelID)
+		s.MessageReactionAdd(m.ChannelID, msg.ID, "⬅️")
+		s.MessageReactionAdd(m.ChannelID, msg.ID, "➡️")
+	}
+}
+
+// maxEffectSearchResults caps how many cards !uma effect lists
+const maxEffectSearchResults = 15
+
+// EffectSearchCommand lists the support cards offering an effect, best
+// first, optionally only those of one rarity
+func EffectSearchCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	name, rarity := uma.SplitEffectQuery(args)
+	if name == "" {
+		s.ChannelMessageSend(m.ChannelID, "❌ Please provide an effect name.\n\n**Usage:** `!uma effect <effect> [rarity]`\n**Example:** `!uma effect friendship bonus ssr`")
+		return
+	}
+
+	effectType, ok := uma.FindEffectType(name)
+	if !ok {
+		s.ChannelMessageSendEmbed(m.ChannelID, unknownEffectEmbed(name))
+		return
+	}
+
+	if gametoraClient == nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ Gametora client is not initialized.")
+		return
+	}
+
+	// Fetches the full list on a cold cache, otherwise served locally
+	loadingMsg, _ := s.ChannelMessageSend(m.ChannelID, "📚 Loading support cards...")
+	cards, err := gametoraClient.GetAllSupportCards()
+	if loadingMsg != nil {
+		s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
+	}
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("❌ Failed to load support cards: %v", err))
+		return
+	}
+
+	s.ChannelMessageSendEmbed(m.ChannelID, effectSearchEmbed(effectType, rarity, uma.CardsWithEffect(cards, effectType.ID, rarity)))
+}
+
+// unknownEffectEmbed tells the user an effect name wasn't recognized and
+// lists the ones that are
+func unknownEffectEmbed(name string) *discordgo.MessageEmbed {
+	embed := theme().NewEmbed(EmbedError, "❌ Unknown Effect", fmt.Sprintf("There is no support card effect called **%s**.", name), "")
+	embed.Fields = []*discordgo.MessageEmbedField{{
+		Name:  "Known Effects",
+		Value: strings.Join(uma.EffectTypeNames(), ", "),
+	}}
+	return fitEmbed(embed)
+}
+
+// effectSearchEmbed lists the best cards for an effect with their values
+// at max level
+func effectSearchEmbed(effectType uma.EffectType, rarity int, matches []uma.EffectCard) *discordgo.MessageEmbed {
+	subject := effectType.Name
+	if rarity != 0 {
+		subject = fmt.Sprintf("%s (%s)", subject, uma.GetRarityText(rarity))
+	}
+
+	if len(matches) == 0 {
+		return fitEmbed(theme().NewEmbed(EmbedWarning, "🔍 No Cards Found", fmt.Sprintf("No support cards offer **%s**.", subject), "Data from Gametora API"))
+	}
+
+	shown := matches
+	if len(shown) > maxEffectSearchResults {
+		shown = shown[:maxEffectSearchResults]
+	}
+
+	var lines strings.Builder
+	for i, match := range shown {
+		lines.WriteString(fmt.Sprintf("%d. **%s** (%s %s) - %d%s\n",
+			i+1, match.Card.CharName, uma.GetRarityText(match.Card.Rarity), match.Card.Type,
+			match.Effect.MaxValue(), match.Effect.Unit))
+	}
+
+	footer := fmt.Sprintf("Data from Gametora API | Showing %d of %d cards, values at max level", len(shown), len(matches))
+	embed := theme().NewEmbed(EmbedInfo, "🔍 Cards with "+subject, "", footer)
+	embed.Fields = []*discordgo.MessageEmbedField{{
+		Name:  "Best First",
+		Value: lines.String(),
+	}}
+	return fitEmbed(embed)
+}
+
 // createSimplifiedSkillsEmbed creates a simplified embed showing only skills for a support card
 func createSimplifiedSkillsEmbed(supportCard *uma.SimplifiedSupportCard) *discordgo.MessageEmbed {
 	// Determine embed color based on rarity
@@ -479,9 +731,9 @@ func createSimplifiedSkillsEmbed(supportCard *uma.SimplifiedSupportCard) *discor
 		Title:       supportCard.NameJp,
 		Description: fmt.Sprintf("**Character:** %s", supportCard.CharName),
 		Color:       color,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Data from Gametora API | Hokko Tarumae",
+			Text: theme().FooterText("Data from Gametora API"),
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
@@ -521,41 +773,24 @@ func createSimplifiedSkillsEmbed(supportCard *uma.SimplifiedSupportCard) *discor
 		}
 	}
 
-	// Add support hints if available
-	if len(supportCard.Hints.HintSkills) > 0 {
-		var hintsText strings.Builder
-		for i, hint := range supportCard.Hints.HintSkills {
-			hintsText.WriteString(fmt.Sprintf("• %s", hint.NameEn))
-			if i < len(supportCard.Hints.HintSkills)-1 {
-				hintsText.WriteString("\n")
-			}
-		}
-
+	// Add support hints and event skills, grouped by skill type
+	if hints := supportCard.HintSkillList(); len(hints) > 0 {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   fmt.Sprintf("💡 Support Hints (%d)", len(supportCard.Hints.HintSkills)),
-			Value:  hintsText.String(),
+			Name:   fmt.Sprintf("💡 Support Hints (%d)", len(hints)),
+			Value:  uma.FormatSkillGroups(uma.GroupSkillsByType(hints), maxEmbedFieldLength),
 			Inline: false,
 		})
 	}
 
-	// Add event skills if available
-	if len(supportCard.EventSkills) > 0 {
-		var eventsText strings.Builder
-		for i, event := range supportCard.EventSkills {
-			eventsText.WriteString(fmt.Sprintf("• %s", event.NameEn))
-			if i < len(supportCard.EventSkills)-1 {
-				eventsText.WriteString("\n")
-			}
-		}
-
+	if events := supportCard.EventSkillList(); len(events) > 0 {
 		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   fmt.Sprintf("🎉 Event Skills (%d)", len(supportCard.EventSkills)),
-			Value:  eventsText.String(),
+			Name:   fmt.Sprintf("🎉 Event Skills (%d)", len(events)),
+			Value:  uma.FormatSkillGroups(uma.GroupSkillsByType(events), maxEmbedFieldLength),
 			Inline: false,
 		})
 	}
 
-	return embed
+	return fitEmbed(embed)
 }
 
 // StableRefreshCommand refreshes the build ID for the Gametora API
@@ -573,10 +808,10 @@ func StableRefreshCommand(s *discordgo.Session, m *discordgo.MessageCreate, args
 		embed := &discordgo.MessageEmbed{
 			Title:       "❌ Build ID Refresh Failed",
 			Description: fmt.Sprintf("Failed to refresh build ID: **%v**", err),
-			Color:       0xff0000, // Red color
-			Timestamp:   time.Now().Format(time.RFC3339),
+			Color:       theme().Color(EmbedError),
+			Timestamp:   theme().Timestamp(time.Now()),
 			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae | Gametora API Build ID Refresh",
+				Text: theme().FooterText("Gametora API Build ID Refresh"),
 			},
 		}
 		s.ChannelMessageSendEmbed(m.ChannelID, embed)
@@ -587,10 +822,10 @@ func StableRefreshCommand(s *discordgo.Session, m *discordgo.MessageCreate, args
 	embed := &discordgo.MessageEmbed{
 		Title:       "✅ Build ID Refreshed",
 		Description: fmt.Sprintf("Successfully refreshed the build ID for the Gametora API.\n\n**Build ID:** `%s`", buildID),
-		Color:       0x00ff00, // Green color
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Color:       theme().Color(EmbedSuccess),
+		Timestamp:   theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae | Gametora API Build ID Refresh",
+			Text: theme().FooterText("Gametora API Build ID Refresh"),
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
@@ -604,6 +839,62 @@ func StableRefreshCommand(s *discordgo.Session, m *discordgo.MessageCreate, args
 	s.ChannelMessageSendEmbed(m.ChannelID, embed)
 }
 
+// UmaReloadCommand drops the cached Gametora data, then refreshes the build
+// ID and reloads the full supports list
+func UmaReloadCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	loadingMsg, _ := s.ChannelMessageSend(m.ChannelID, "🔄 Reloading Gametora data...")
+
+	var stored int64
+	var storedErr error
+	if umaDB != nil {
+		stored, storedErr = umaDB.ClearGametoraSkillsCache()
+	}
+	result, err := gametoraClient.Reload()
+
+	if loadingMsg != nil {
+		s.ChannelMessageDelete(m.ChannelID, loadingMsg.ID)
+	}
+	s.ChannelMessageSendEmbed(m.ChannelID, umaReloadEmbed(result, stored, storedErr, err))
+}
+
+// umaReloadEmbed reports the outcome of a Gametora reload: the new build ID,
+// how many cards were loaded and how many cached results were dropped
+func umaReloadEmbed(result *uma.ReloadResult, stored int64, storedErr, err error) *discordgo.MessageEmbed {
+	var embed *discordgo.MessageEmbed
+	if err != nil {
+		embed = theme().NewEmbed(EmbedError, "❌ Reload Failed", fmt.Sprintf("Failed to reload Gametora data: **%v**", err), "Gametora Reload")
+	} else {
+		embed = theme().NewEmbed(EmbedSuccess, "✅ Gametora Data Reloaded", fmt.Sprintf("Loaded **%d** support cards.", result.Cards), "Gametora Reload")
+	}
+
+	if result != nil && result.BuildID != "" {
+		buildID := fmt.Sprintf("`%s`", result.BuildID)
+		if result.IsFallback {
+			buildID += " (fallback, data may be outdated)"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "🏗️ Build ID",
+			Value: buildID,
+		})
+	}
+
+	invalidated := "none"
+	if result != nil {
+		invalidated = fmt.Sprintf("%d in memory", result.Invalidated)
+	}
+	switch {
+	case storedErr != nil:
+		invalidated += fmt.Sprintf(", stored results not cleared: %v", storedErr)
+	case stored > 0:
+		invalidated += fmt.Sprintf(", %d stored", stored)
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "🧹 Cache Entries Dropped",
+		Value: invalidated,
+	})
+	return fitEmbed(embed)
+}
+
 // createMultiVersionSupportCardEmbed creates an embed showing all versions of a support card
 func createMultiVersionSupportCardEmbed(supportCards []uma.SupportCard) *discordgo.MessageEmbed {
 	// Use the highest rarity card for the main embed info
@@ -627,7 +918,7 @@ func createMultiVersionSupportCardEmbed(supportCards []uma.SupportCard) *discord
 		Title:       mainCard.TitleEn,
 		Description: mainCard.Title,
 		Color:       color,
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Timestamp:   theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
 			Text: "Data from umapyoi.net",
 		},
@@ -678,13 +969,9 @@ func createMultiVersionSupportCardEmbed(supportCards []uma.SupportCard) *discord
 		}
 	}
 
-	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-		Name:   fmt.Sprintf("📋 All Versions (%d)", len(supportCards)),
-		Value:  versionsText.String(),
-		Inline: false,
-	})
+	embed.Fields = append(embed.Fields, splitEmbedField(fmt.Sprintf("📋 All Versions (%d)", len(supportCards)), versionsText.String(), false)...)
 
-	return embed
+	return fitEmbed(embed)
 }
 
 // CacheStatsCommand shows cache statistics
@@ -704,10 +991,10 @@ func CacheStatsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []
 	embed := &discordgo.MessageEmbed{
 		Title:       "📊 UMA Cache Statistics",
 		Description: "Current cache statistics for UMA data",
-		Color:       0x00ff00, // Green color
-		Timestamp:   time.Now().Format(time.RFC3339),
+		Color:       theme().Color(EmbedSuccess),
+		Timestamp:   theme().Timestamp(time.Now()),
 		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae | UMA Cache Statistics",
+			Text: theme().FooterText("UMA Cache Statistics"),
 		},
 		Fields: []*discordgo.MessageEmbedField{
 			{
@@ -740,6 +1027,11 @@ func CacheStatsCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []
 				Value:  fmt.Sprintf("%d", stats["total_cache"]),
 				Inline: true,
 			},
+			{
+				Name:   "🌐 In-flight Searches",
+				Value:  fmt.Sprintf("%d", uma.InFlightSearches()),
+				Inline: true,
+			},
 		},
 	}
 
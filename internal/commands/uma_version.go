@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// UmaVersionCommand reports the Gametora build ID the bot requests data
+// with, so users can tell whether stale results come from an old build
+func UmaVersionCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if gametoraClient == nil {
+		s.ChannelMessageSend(m.ChannelID, "❌ Gametora client is not initialized.")
+		return
+	}
+
+	embed := buildIDInfoEmbed(gametoraClient.BuildIDInfo())
+	if manager := gametoraClient.GetBuildIDManager(); manager != nil && manager.IsRunning() {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "⏭️ Next Refresh",
+			Value:  fmt.Sprintf("<t:%d:R>", manager.GetNextRun().Unix()),
+			Inline: true,
+		})
+	}
+	s.ChannelMessageSendEmbed(m.ChannelID, embed)
+}
+
+// buildIDInfoEmbed renders the build ID info for !uma version
+func buildIDInfoEmbed(info uma.BuildIDInfo) *discordgo.MessageEmbed {
+	event := EmbedSuccess
+	source := "Fetched from Gametora"
+	description := "The bot is using Gametora's current build."
+	switch {
+	case info.ID == "":
+		event = EmbedNeutral
+		source = "Not fetched yet"
+		description = "No build ID has been fetched yet; the first search will fetch one."
+	case info.IsFallback:
+		event = EmbedWarning
+		source = "⚠️ Fallback"
+		description = "The build ID couldn't be found on Gametora, so a built-in one is used and results may be stale or missing. Try `!uma refresh`."
+	case info.FetchedAt.IsZero():
+		source = "Preset"
+		description = "The bot is using a configured build ID."
+	}
+
+	refreshed := "Never"
+	if !info.FetchedAt.IsZero() {
+		refreshed = fmt.Sprintf("<t:%d:R>", info.FetchedAt.Unix())
+	}
+
+	id := "none"
+	if info.ID != "" {
+		id = fmt.Sprintf("`%s`", info.ID)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "🏷️ Gametora Build",
+		Description: description,
+		Color:       theme().Color(event),
+		Timestamp:   theme().Timestamp(time.Now()),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: theme().FooterText("Gametora API Build ID"),
+		},
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "📦 Build ID", Value: id, Inline: true},
+			{Name: "🔎 Source", Value: source, Inline: true},
+			{Name: "🕒 Last Refreshed", Value: refreshed, Inline: true},
+			{Name: "🗄️ Cache TTL", Value: formatDuration(info.CacheTTL), Inline: true},
+		},
+	}
+}
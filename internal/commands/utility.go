@@ -65,40 +65,32 @@ func CronStatusCommand(s *discordgo.Session, m *discordgo.MessageCreate, args []
 	}
 
 	// Create status embed
-	embed := &discordgo.MessageEmbed{
-		Title:       "⏰ Cron Job Status",
-		Description: "Current status of automated build ID refresh jobs",
-		Color:       0x7289DA, // Discord blue
-		Timestamp:   time.Now().Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: "Hokko Tarumae | Utility Commands",
+	embed := theme().NewEmbed(EmbedInfo, "⏰ Cron Job Status", "Current status of automated build ID refresh jobs", "Utility Commands")
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{
+			Name:   "🔄 Build ID Refresh",
+			Value:  "Active",
+			Inline: true,
 		},
-		Fields: []*discordgo.MessageEmbedField{
-			{
-				Name:   "🔄 Build ID Refresh",
-				Value:  "Active",
-				Inline: true,
-			},
-			{
-				Name:   "📅 Schedule",
-				Value:  buildIDManager.GetSchedule(),
-				Inline: true,
-			},
-			{
-				Name:   "⏭️ Next Run",
-				Value:  nextRunStr,
-				Inline: true,
-			},
-			{
-				Name:   "🏃‍♂️ Currently Running",
-				Value:  fmt.Sprintf("%t", buildIDManager.IsRunning()),
-				Inline: true,
-			},
-			{
-				Name:   "🆔 Current Build ID",
-				Value:  buildID,
-				Inline: true,
-			},
+		{
+			Name:   "📅 Schedule",
+			Value:  buildIDManager.GetSchedule(),
+			Inline: true,
+		},
+		{
+			Name:   "⏭️ Next Run",
+			Value:  nextRunStr,
+			Inline: true,
+		},
+		{
+			Name:   "🏃‍♂️ Currently Running",
+			Value:  fmt.Sprintf("%t", buildIDManager.IsRunning()),
+			Inline: true,
+		},
+		{
+			Name:   "🆔 Current Build ID",
+			Value:  buildID,
+			Inline: true,
 		},
 	}
 
@@ -127,20 +119,12 @@ func CronRefreshCommand(s *discordgo.Session, m *discordgo.MessageCreate, args [
 
 	if err != nil {
 		// Update message with error
-		embed := &discordgo.MessageEmbed{
-			Title:       "❌ Build ID Refresh Failed",
-			Description: "Failed to refresh build ID",
-			Color:       0xff0000, // Red
-			Timestamp:   time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae | Utility Commands",
-			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "🔧 Error",
-					Value:  err.Error(),
-					Inline: false,
-				},
+		embed := theme().NewEmbed(EmbedError, "❌ Build ID Refresh Failed", "Failed to refresh build ID", "Utility Commands")
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   "🔧 Error",
+				Value:  err.Error(),
+				Inline: false,
 			},
 		}
 		s.ChannelMessageEditEmbed(m.ChannelID, msg.ID, embed)
@@ -149,25 +133,17 @@ func CronRefreshCommand(s *discordgo.Session, m *discordgo.MessageCreate, args [
 		newBuildID, _ := client.GetBuildID()
 
 		// Update message with success
-		embed := &discordgo.MessageEmbed{
-			Title:       "✅ Build ID Refresh Complete",
-			Description: "Successfully refreshed build ID",
-			Color:       0x00ff00, // Green
-			Timestamp:   time.Now().Format(time.RFC3339),
-			Footer: &discordgo.MessageEmbedFooter{
-				Text: "Hokko Tarumae | Utility Commands",
+		embed := theme().NewEmbed(EmbedSuccess, "✅ Build ID Refresh Complete", "Successfully refreshed build ID", "Utility Commands")
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   "🆔 New Build ID",
+				Value:  newBuildID,
+				Inline: true,
 			},
-			Fields: []*discordgo.MessageEmbedField{
-				{
-					Name:   "🆔 New Build ID",
-					Value:  newBuildID,
-					Inline: true,
-				},
-				{
-					Name:   "⏰ Refreshed At",
-					Value:  time.Now().Format("2006-01-02 15:04:05"),
-					Inline: true,
-				},
+			{
+				Name:   "⏰ Refreshed At",
+				Value:  time.Now().Format("2006-01-02 15:04:05"),
+				Inline: true,
 			},
 		}
 		s.ChannelMessageEditEmbed(m.ChannelID, msg.ID, embed)
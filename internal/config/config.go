@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,6 +15,96 @@ type Config struct {
 	// Cron configuration
 	CronEnabled  bool
 	CronSchedule string
+	// Discord session open retries
+	SessionOpenAttempts int
+	// Per-user cooldown for each command name
+	CommandCooldowns map[string]time.Duration
+	// Exit at startup when a critical self-test check fails
+	SelfTestFailFast bool
+	// How long to stay paused after everyone leaves voice; zero disables
+	AloneGracePeriod time.Duration
+	// Embed branding; empty values keep the built-in theme
+	EmbedColors          map[string]int
+	EmbedFooter          string
+	EmbedTimestampFormat string
+	// Cap on concurrent Uma Musume API requests; zero keeps the default
+	MaxUmaSearches int
+	// How long an !uma search may take before it is abandoned; zero keeps the default
+	UmaSearchTimeout time.Duration
+	// Tracks in a row that may fail before playback stops; zero keeps the default
+	MaxTrackFailures int
+	// Upcoming tracks per !queue page; zero keeps the default
+	QueuePageSize int
+	// Trim each track's leading silence, and the level below which audio
+	// counts as silent; a zero threshold keeps the default
+	TrimSilence        bool
+	SilenceThresholdDB float64
+	// How often the cache database is analyzed; zero disables, and whether
+	// its indexes are rebuilt too
+	DBMaintenanceInterval time.Duration
+	DBMaintenanceReindex  bool
+}
+
+// DefaultCommandCooldowns returns the cooldowns for commands that hit upstream APIs or the pipeline
+func DefaultCommandCooldowns() map[string]time.Duration {
+	return map[string]time.Duration{
+		"play":  3 * time.Second,
+		"queue": 2 * time.Second,
+		"uma":   5 * time.Second,
+	}
+}
+
+// parseCommandCooldowns parses "play=3s,uma=5s" into per-command durations,
+// overriding the defaults. Invalid entries are ignored; a zero duration
+// disables the cooldown for that command.
+func parseCommandCooldowns(value string) map[string]time.Duration {
+	cooldowns := DefaultCommandCooldowns()
+	for _, entry := range strings.Split(value, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || d < 0 {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		if d == 0 {
+			delete(cooldowns, name)
+			continue
+		}
+		cooldowns[name] = d
+	}
+	return cooldowns
+}
+
+// parseEmbedColors parses "success=#00ff00,error=0xff0000" into colors keyed
+// by event name. Invalid entries are ignored.
+func parseEmbedColors(value string) map[string]int {
+	colors := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		raw = strings.TrimSpace(raw)
+		raw = strings.TrimPrefix(raw, "#")
+		raw = strings.TrimPrefix(strings.ToLower(raw), "0x")
+		color, err := strconv.ParseInt(raw, 16, 32)
+		if err != nil || color < 0 || color > 0xffffff {
+			continue
+		}
+		colors[strings.ToLower(strings.TrimSpace(name))] = int(color)
+	}
+	return colors
+}
+
+// Redacted returns a copy of the config with the Discord token masked
+func (c Config) Redacted() Config {
+	if c.DiscordToken != "" {
+		c.DiscordToken = "***"
+	}
+	return c
 }
 
 var (
@@ -47,10 +140,101 @@ func LoadConfig() (*Config, error) {
 		cronSchedule = schedule
 	}
 
+	sessionOpenAttempts := 5 // Default: 5 attempts
+	if attempts := os.Getenv("SESSION_OPEN_ATTEMPTS"); attempts != "" {
+		if n, err := strconv.Atoi(attempts); err == nil && n > 0 {
+			sessionOpenAttempts = n
+		}
+	}
+
+	commandCooldowns := parseCommandCooldowns(os.Getenv("COMMAND_COOLDOWNS"))
+
+	selfTestFailFast := false // Default: log failures and keep going
+	if failFast := os.Getenv("SELFTEST_FAIL_FAST"); failFast != "" {
+		selfTestFailFast = failFast == "true" || failFast == "1"
+	}
+
+	aloneGracePeriod := 2 * time.Minute // Default: 2 minutes
+	if grace := os.Getenv("ALONE_GRACE_PERIOD"); grace != "" {
+		if d, err := time.ParseDuration(grace); err == nil && d >= 0 {
+			aloneGracePeriod = d
+		}
+	}
+
+	embedColors := parseEmbedColors(os.Getenv("EMBED_COLORS"))
+
+	maxUmaSearches := 0 // Default: the uma package's own limit
+	if max := os.Getenv("UMA_MAX_CONCURRENT_SEARCHES"); max != "" {
+		if n, err := strconv.Atoi(max); err == nil && n > 0 {
+			maxUmaSearches = n
+		}
+	}
+
+	umaSearchTimeout := time.Duration(0) // Default: the commands package's own timeout
+	if timeout := os.Getenv("UMA_SEARCH_TIMEOUT"); timeout != "" {
+		if d, err := time.ParseDuration(timeout); err == nil && d > 0 {
+			umaSearchTimeout = d
+		}
+	}
+
+	maxTrackFailures := 0 // Default: the commands package's own limit
+	if max := os.Getenv("MAX_CONSECUTIVE_TRACK_FAILURES"); max != "" {
+		if n, err := strconv.Atoi(max); err == nil && n > 0 {
+			maxTrackFailures = n
+		}
+	}
+
+	queuePageSize := 0 // Default: the commands package's own page size
+	if size := os.Getenv("QUEUE_PAGE_SIZE"); size != "" {
+		if n, err := strconv.Atoi(size); err == nil && n > 0 {
+			queuePageSize = n
+		}
+	}
+
+	trimSilence := false // Default: play tracks exactly as they are
+	if trim := os.Getenv("TRIM_SILENCE"); trim != "" {
+		trimSilence = trim == "true" || trim == "1"
+	}
+
+	silenceThresholdDB := 0.0 // Default: the common package's own threshold
+	if threshold := os.Getenv("SILENCE_THRESHOLD_DB"); threshold != "" {
+		if db, err := strconv.ParseFloat(threshold, 64); err == nil && db < 0 {
+			silenceThresholdDB = db
+		}
+	}
+
+	dbMaintenanceInterval := 24 * time.Hour // Default: daily
+	if interval := os.Getenv("DB_MAINTENANCE_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil && d >= 0 {
+			dbMaintenanceInterval = d
+		}
+	}
+
+	dbMaintenanceReindex := false // Default: ANALYZE only
+	if reindex := os.Getenv("DB_MAINTENANCE_REINDEX"); reindex != "" {
+		dbMaintenanceReindex = reindex == "true" || reindex == "1"
+	}
+
 	return &Config{
-		DiscordToken: discordToken,
-		OwnerID:      ownerID,
-		CronEnabled:  cronEnabled,
-		CronSchedule: cronSchedule,
+		DiscordToken:         discordToken,
+		OwnerID:              ownerID,
+		CronEnabled:          cronEnabled,
+		CronSchedule:         cronSchedule,
+		SessionOpenAttempts:  sessionOpenAttempts,
+		CommandCooldowns:     commandCooldowns,
+		SelfTestFailFast:     selfTestFailFast,
+		AloneGracePeriod:     aloneGracePeriod,
+		EmbedColors:          embedColors,
+		EmbedFooter:          os.Getenv("EMBED_FOOTER"),
+		EmbedTimestampFormat: os.Getenv("EMBED_TIMESTAMP_FORMAT"),
+		MaxUmaSearches:       maxUmaSearches,
+		UmaSearchTimeout:     umaSearchTimeout,
+		MaxTrackFailures:     maxTrackFailures,
+		QueuePageSize:        queuePageSize,
+		TrimSilence:          trimSilence,
+		SilenceThresholdDB:   silenceThresholdDB,
+
+		DBMaintenanceInterval: dbMaintenanceInterval,
+		DBMaintenanceReindex:  dbMaintenanceReindex,
 	}, nil
 }
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// commandAliases maps short command names to the name cooldowns are configured under
+var commandAliases = map[string]string{
+	"p":  "play",
+	"q":  "queue",
+	"h":  "help",
+	"np": "nowplaying",
+}
+
+// cooldownKey identifies a single user's use of a single command
+type cooldownKey struct {
+	userID  string
+	command string
+}
+
+// CooldownManager rate limits commands per user and per command
+type CooldownManager struct {
+	mu        sync.Mutex
+	durations map[string]time.Duration
+	until     map[cooldownKey]time.Time
+	now       func() time.Time
+}
+
+// NewCooldownManager creates a cooldown manager with the given per-command durations.
+// Commands without an entry are not rate limited.
+func NewCooldownManager(durations map[string]time.Duration) *CooldownManager {
+	cm := &CooldownManager{
+		durations: make(map[string]time.Duration),
+		until:     make(map[cooldownKey]time.Time),
+		now:       time.Now,
+	}
+	for command, d := range durations {
+		cm.durations[command] = d
+	}
+	return cm
+}
+
+// SetCooldown sets the cooldown for a command; zero removes it
+func (cm *CooldownManager) SetCooldown(command string, d time.Duration) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if d <= 0 {
+		delete(cm.durations, command)
+		return
+	}
+	cm.durations[command] = d
+}
+
+// Allow reports whether the user may run the command now. If not, it also
+// returns how long until they can. An allowed call starts a new cooldown.
+func (cm *CooldownManager) Allow(userID, command string) (bool, time.Duration) {
+	if canonical, ok := commandAliases[command]; ok {
+		command = canonical
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	d, ok := cm.durations[command]
+	if !ok {
+		return true, 0
+	}
+
+	now := cm.now()
+	key := cooldownKey{userID: userID, command: command}
+	if until, exists := cm.until[key]; exists && now.Before(until) {
+		return false, until.Sub(now)
+	}
+
+	cm.until[key] = now.Add(d)
+	return true, 0
+}
+
+// Sweep removes expired cooldown entries and returns how many were removed
+func (cm *CooldownManager) Sweep() int {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	now := cm.now()
+	removed := 0
+	for key, until := range cm.until {
+		if !now.Before(until) {
+			delete(cm.until, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// StartSweeper periodically sweeps expired entries until stop is closed
+func (cm *CooldownManager) StartSweeper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if removed := cm.Sweep(); removed > 0 {
+					log.Printf("Swept %d expired command cooldowns", removed)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// commandCooldowns gates command dispatch; nil disables cooldowns
+var commandCooldowns *CooldownManager
+
+// SetCommandCooldowns sets the cooldown manager used by the command handlers
+func SetCommandCooldowns(cm *CooldownManager) {
+	commandCooldowns = cm
+}
+
+// checkCooldown reports whether the command may run and, if not, the wait remaining
+func checkCooldown(userID, command string) (bool, time.Duration) {
+	if commandCooldowns == nil {
+		return true, 0
+	}
+	return commandCooldowns.Allow(userID, command)
+}
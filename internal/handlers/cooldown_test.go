@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestCooldownManager(durations map[string]time.Duration) (*CooldownManager, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cm := NewCooldownManager(durations)
+	cm.now = func() time.Time { return now }
+	return cm, &now
+}
+
+func TestCooldownManagerAllowDeny(t *testing.T) {
+	cm, now := newTestCooldownManager(map[string]time.Duration{"play": 3 * time.Second})
+
+	if ok, _ := cm.Allow("user1", "play"); !ok {
+		t.Fatal("First call should be allowed")
+	}
+
+	ok, remaining := cm.Allow("user1", "play")
+	if ok {
+		t.Fatal("Second call within cooldown should be denied")
+	}
+	if remaining != 3*time.Second {
+		t.Errorf("Expected 3s remaining, got %v", remaining)
+	}
+
+	// Aliases share the canonical command's cooldown
+	if ok, _ := cm.Allow("user1", "p"); ok {
+		t.Error("Alias should be covered by the play cooldown")
+	}
+
+	// Other users and other commands are independent
+	if ok, _ := cm.Allow("user2", "play"); !ok {
+		t.Error("Different user should be allowed")
+	}
+	if ok, _ := cm.Allow("user1", "skip"); !ok {
+		t.Error("Command without a cooldown should always be allowed")
+	}
+
+	*now = now.Add(3 * time.Second)
+	if ok, _ := cm.Allow("user1", "play"); !ok {
+		t.Error("Call after cooldown expired should be allowed")
+	}
+}
+
+func TestCooldownManagerSweep(t *testing.T) {
+	cm, now := newTestCooldownManager(map[string]time.Duration{
+		"play": 2 * time.Second,
+		"uma":  10 * time.Second,
+	})
+
+	cm.Allow("user1", "play")
+	cm.Allow("user1", "uma")
+
+	*now = now.Add(5 * time.Second)
+	if removed := cm.Sweep(); removed != 1 {
+		t.Errorf("Expected 1 expired entry swept, got %d", removed)
+	}
+	if ok, _ := cm.Allow("user1", "uma"); ok {
+		t.Error("Unexpired cooldown should survive the sweep")
+	}
+}
+
+func TestCooldownManagerSetCooldown(t *testing.T) {
+	cm, _ := newTestCooldownManager(nil)
+
+	cm.SetCooldown("uma", 5*time.Second)
+	cm.Allow("user1", "uma")
+	if ok, _ := cm.Allow("user1", "uma"); ok {
+		t.Error("Expected configured cooldown to deny")
+	}
+
+	cm.SetCooldown("uma", 0)
+	if ok, _ := cm.Allow("user1", "uma"); !ok {
+		t.Error("Expected removed cooldown to allow")
+	}
+}
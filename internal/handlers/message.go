@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 	"strings"
 
@@ -41,6 +43,11 @@ func MessageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 		args := strings.Split(m.Content, " ")
 		command := strings.TrimPrefix(args[0], "!")
 
+		if ok, wait := checkCooldown(m.Author.ID, command); !ok {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("⏳ Please wait %d seconds before using `!%s` again.", int(math.Ceil(wait.Seconds())), command))
+			return
+		}
+
 		switch command {
 		case "help", "h":
 			commands.ShowHelpCommand(s, m)
@@ -52,12 +59,20 @@ func MessageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 			commands.ResumeCommand(s, m)
 		case "skip":
 			commands.SkipCommand(s, m)
+		case "seek":
+			commands.SeekCommand(s, m, args[1:])
+		case "chapters":
+			commands.ChaptersCommand(s, m)
+		case "move":
+			commands.MoveCommand(s, m)
 		case "stop":
 			commands.StopCommand(s, m, args[1:])
 		case "servers":
 			commands.ServersCommand(s, m)
 		case "leave":
 			commands.LeaveCommand(s, m, args[1:])
+		case "replay":
+			commands.ReplayCommand(s, m, args[1:])
 		case "queue", "q":
 			commands.QueueCommand(s, m, args[1:])
 		case "clear":
@@ -72,6 +87,18 @@ func MessageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 			commands.GremlinCommand(s, m)
 		case "uma":
 			commands.UmaCommand(s, m, args[1:])
+		case "config":
+			commands.ConfigCommand(s, m, args[1:])
+		case "settings":
+			commands.SettingsCommand(s, m, args[1:])
+		case "setannounce":
+			commands.SetAnnounceCommand(s, m, args[1:])
+		case "history":
+			commands.HistoryCommand(s, m, args[1:])
+		case "pipeline":
+			commands.PipelineCommand(s, m, args[1:])
+		case "shutdown-audio":
+			commands.ShutdownAudioCommand(s, m)
 		case "utility":
 			commands.UtilityCommand(s, m, args[1:])
 		case "delete":
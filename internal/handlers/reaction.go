@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/internal/commands"
 	"github.com/latoulicious/HKTM/pkg/uma/navigation"
 )
 
@@ -24,6 +25,13 @@ func ReactionAddHandler(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
 	// Handle support card version navigation
 	supportCardNavManager := navigation.GetSupportCardNavigationManager()
 	supportCardNavManager.HandleSupportCardReaction(s, r)
+
+	// Handle support card list paging
+	supportCardListManager := navigation.GetSupportCardListManager()
+	supportCardListManager.HandleListReaction(s, r)
+
+	// Handle music queue paging
+	commands.HandleQueuePageReaction(s, r)
 }
 
 // ReactionRemoveHandler handles reaction remove events (for cleanup)
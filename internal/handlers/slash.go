@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"fmt"
 	"log"
+	"math"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/latoulicious/HKTM/internal/commands"
@@ -40,6 +42,14 @@ func handleApplicationCommand(s *discordgo.Session, i *discordgo.InteractionCrea
 
 	var response string
 
+	if ok, wait := checkCooldown(i.Member.User.ID, data.Name); !ok {
+		response = fmt.Sprintf("⏳ Please wait %d seconds before using `/%s` again.", int(math.Ceil(wait.Seconds())), data.Name)
+		if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Content: &response}); err != nil {
+			log.Printf("Error sending interaction response: %v", err)
+		}
+		return
+	}
+
 	switch data.Name {
 	case "play":
 		response = handlePlaySlash(s, i, data)
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/internal/commands"
+)
+
+// VoiceStateUpdateHandler handles voice state changes, pausing playback when
+// the bot is left alone in its channel
+func VoiceStateUpdateHandler(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	if s == nil || v == nil {
+		return
+	}
+
+	commands.HandleVoiceStateUpdate(s, v)
+}
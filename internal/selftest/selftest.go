@@ -0,0 +1,201 @@
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os/exec"
+	"time"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// DefaultTimeout bounds each check so a hung dependency can't stall startup
+const DefaultTimeout = 5 * time.Second
+
+// CheckResult is the outcome of one startup check
+type CheckResult struct {
+	Name     string
+	Status   Status
+	Critical bool // A critical failure means the bot can't do its job
+	Message  string
+	Duration time.Duration
+}
+
+// Endpoint is an upstream API the bot depends on
+type Endpoint struct {
+	Name string
+	URL  string
+}
+
+// HTTPDoer is the subset of *http.Client used for reachability checks
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Dependencies are the hooks the checks use to reach the outside world.
+// Tests replace them with stubs.
+type Dependencies struct {
+	LookPath   func(file string) (string, error)
+	RunCommand func(ctx context.Context, name string, args ...string) error
+	// Migrate applies the database migrations; nil skips the check
+	Migrate func(ctx context.Context) error
+	// HTTPClient probes Endpoints; nil skips the reachability checks
+	HTTPClient HTTPDoer
+	Endpoints  []Endpoint
+	Timeout    time.Duration
+}
+
+// DefaultEndpoints are the upstream APIs used by the uma commands
+var DefaultEndpoints = []Endpoint{
+	{Name: "gametora", URL: "https://gametora.com"},
+	{Name: "umapyoi", URL: "https://umapyoi.net/api"},
+}
+
+// DefaultDependencies returns dependencies backed by the real system
+func DefaultDependencies() Dependencies {
+	return Dependencies{
+		LookPath: exec.LookPath,
+		RunCommand: func(ctx context.Context, name string, args ...string) error {
+			return exec.CommandContext(ctx, name, args...).Run()
+		},
+		HTTPClient: http.DefaultClient,
+		Endpoints:  DefaultEndpoints,
+		Timeout:    DefaultTimeout,
+	}
+}
+
+// Runner runs the startup checks against a set of dependencies
+type Runner struct {
+	deps Dependencies
+}
+
+// NewRunner creates a runner, filling in missing binary hooks and the timeout
+// from DefaultDependencies
+func NewRunner(deps Dependencies) *Runner {
+	defaults := DefaultDependencies()
+	if deps.LookPath == nil {
+		deps.LookPath = defaults.LookPath
+	}
+	if deps.RunCommand == nil {
+		deps.RunCommand = defaults.RunCommand
+	}
+	if deps.Timeout <= 0 {
+		deps.Timeout = defaults.Timeout
+	}
+	return &Runner{deps: deps}
+}
+
+// SelfTest runs every check in order and returns their results
+func (r *Runner) SelfTest(ctx context.Context) []CheckResult {
+	results := []CheckResult{
+		r.run(ctx, "ffmpeg", true, r.checkBinary("ffmpeg", "-version")),
+		r.run(ctx, "yt-dlp", true, r.checkBinary("yt-dlp", "--version")),
+		r.run(ctx, "database", true, r.checkDatabase),
+	}
+
+	for _, endpoint := range r.deps.Endpoints {
+		results = append(results, r.run(ctx, "upstream:"+endpoint.Name, false, r.checkEndpoint(endpoint)))
+	}
+
+	return results
+}
+
+// run executes one check under the per-check timeout and times it
+func (r *Runner) run(ctx context.Context, name string, critical bool, check func(ctx context.Context) (Status, string)) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.deps.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	status, message := check(ctx)
+	return CheckResult{
+		Name:     name,
+		Status:   status,
+		Critical: critical,
+		Message:  message,
+		Duration: time.Since(start),
+	}
+}
+
+// checkBinary verifies a binary is on PATH and runs with the given args
+func (r *Runner) checkBinary(name string, args ...string) func(ctx context.Context) (Status, string) {
+	return func(ctx context.Context) (Status, string) {
+		path, err := r.deps.LookPath(name)
+		if err != nil {
+			return StatusFail, fmt.Sprintf("%s not found in PATH", name)
+		}
+
+		if err := r.deps.RunCommand(ctx, path, args...); err != nil {
+			return StatusFail, fmt.Sprintf("%s found at %s but failed to run: %v", name, path, err)
+		}
+
+		return StatusPass, fmt.Sprintf("%s found at %s", name, path)
+	}
+}
+
+// checkDatabase verifies the migrations apply cleanly
+func (r *Runner) checkDatabase(ctx context.Context) (Status, string) {
+	if r.deps.Migrate == nil {
+		return StatusSkip, "no database configured"
+	}
+
+	if err := r.deps.Migrate(ctx); err != nil {
+		return StatusFail, fmt.Sprintf("migrations failed: %v", err)
+	}
+
+	return StatusPass, "migrations applied"
+}
+
+// checkEndpoint verifies an upstream API answers. Any response below 500
+// counts as reachable; the check is about the network path, not the route.
+func (r *Runner) checkEndpoint(endpoint Endpoint) func(ctx context.Context) (Status, string) {
+	return func(ctx context.Context) (Status, string) {
+		if r.deps.HTTPClient == nil {
+			return StatusSkip, "network checks disabled"
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, endpoint.URL, nil)
+		if err != nil {
+			return StatusFail, fmt.Sprintf("invalid URL %s: %v", endpoint.URL, err)
+		}
+
+		resp, err := r.deps.HTTPClient.Do(req)
+		if err != nil {
+			return StatusFail, fmt.Sprintf("%s unreachable: %v", endpoint.URL, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return StatusFail, fmt.Sprintf("%s returned %s", endpoint.URL, resp.Status)
+		}
+
+		return StatusPass, fmt.Sprintf("%s returned %s", endpoint.URL, resp.Status)
+	}
+}
+
+// CriticalFailures returns the failed checks marked critical
+func CriticalFailures(results []CheckResult) []CheckResult {
+	var failures []CheckResult
+	for _, result := range results {
+		if result.Critical && result.Status == StatusFail {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// LogResults logs one line per check
+func LogResults(results []CheckResult) {
+	for _, result := range results {
+		log.Printf("selftest: check=%s status=%s critical=%t duration=%v message=%q",
+			result.Name, result.Status, result.Critical, result.Duration.Round(time.Millisecond), result.Message)
+	}
+}
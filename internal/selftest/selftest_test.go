@@ -0,0 +1,156 @@
+package selftest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubDoer answers every request with a fixed status or error
+type stubDoer struct {
+	status int
+	err    error
+	block  bool
+}
+
+func (d stubDoer) Do(req *http.Request) (*http.Response, error) {
+	if d.block {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &http.Response{
+		StatusCode: d.status,
+		Status:     http.StatusText(d.status),
+		Body:       io.NopCloser(strings.NewReader("")),
+	}, nil
+}
+
+// healthyDeps returns dependencies where every check passes
+func healthyDeps() Dependencies {
+	return Dependencies{
+		LookPath:   func(file string) (string, error) { return "/usr/bin/" + file, nil },
+		RunCommand: func(ctx context.Context, name string, args ...string) error { return nil },
+		Migrate:    func(ctx context.Context) error { return nil },
+		HTTPClient: stubDoer{status: http.StatusOK},
+		Endpoints:  []Endpoint{{Name: "api", URL: "https://api.example.com"}},
+		Timeout:    time.Second,
+	}
+}
+
+// resultByName finds a check result by name
+func resultByName(t *testing.T, results []CheckResult, name string) CheckResult {
+	t.Helper()
+	for _, result := range results {
+		if result.Name == name {
+			return result
+		}
+	}
+	t.Fatalf("No result for check %s", name)
+	return CheckResult{}
+}
+
+func TestSelfTestAllPass(t *testing.T) {
+	results := NewRunner(healthyDeps()).SelfTest(context.Background())
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Status != StatusPass {
+			t.Errorf("Expected %s to pass, got %s: %s", result.Name, result.Status, result.Message)
+		}
+	}
+	if failures := CriticalFailures(results); len(failures) != 0 {
+		t.Errorf("Expected no critical failures, got %v", failures)
+	}
+}
+
+func TestSelfTestFFmpegMissing(t *testing.T) {
+	deps := healthyDeps()
+	deps.LookPath = func(file string) (string, error) {
+		if file == "ffmpeg" {
+			return "", errors.New("executable file not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+
+	results := NewRunner(deps).SelfTest(context.Background())
+	ffmpeg := resultByName(t, results, "ffmpeg")
+	if ffmpeg.Status != StatusFail || !ffmpeg.Critical {
+		t.Errorf("Expected critical ffmpeg failure, got %+v", ffmpeg)
+	}
+	if resultByName(t, results, "yt-dlp").Status != StatusPass {
+		t.Error("Expected yt-dlp to pass independently")
+	}
+	if failures := CriticalFailures(results); len(failures) != 1 || failures[0].Name != "ffmpeg" {
+		t.Errorf("Expected only ffmpeg as a critical failure, got %v", failures)
+	}
+}
+
+func TestSelfTestBinaryNotRunnable(t *testing.T) {
+	deps := healthyDeps()
+	deps.RunCommand = func(ctx context.Context, name string, args ...string) error {
+		if strings.HasSuffix(name, "yt-dlp") {
+			return errors.New("exit status 127")
+		}
+		return nil
+	}
+
+	result := resultByName(t, NewRunner(deps).SelfTest(context.Background()), "yt-dlp")
+	if result.Status != StatusFail || !strings.Contains(result.Message, "failed to run") {
+		t.Errorf("Expected yt-dlp run failure, got %+v", result)
+	}
+}
+
+func TestSelfTestDatabase(t *testing.T) {
+	deps := healthyDeps()
+	deps.Migrate = func(ctx context.Context) error { return errors.New("no such table") }
+	result := resultByName(t, NewRunner(deps).SelfTest(context.Background()), "database")
+	if result.Status != StatusFail || !result.Critical {
+		t.Errorf("Expected critical database failure, got %+v", result)
+	}
+
+	deps.Migrate = nil
+	result = resultByName(t, NewRunner(deps).SelfTest(context.Background()), "database")
+	if result.Status != StatusSkip {
+		t.Errorf("Expected database check to be skipped, got %+v", result)
+	}
+}
+
+func TestSelfTestUpstream(t *testing.T) {
+	tests := []struct {
+		name   string
+		client HTTPDoer
+		want   Status
+	}{
+		{"reachable", stubDoer{status: http.StatusNotFound}, StatusPass},
+		{"server error", stubDoer{status: http.StatusBadGateway}, StatusFail},
+		{"unreachable", stubDoer{err: errors.New("no such host")}, StatusFail},
+		{"timeout", stubDoer{block: true}, StatusFail},
+		{"disabled", nil, StatusSkip},
+	}
+
+	for _, tt := range tests {
+		deps := healthyDeps()
+		deps.HTTPClient = tt.client
+		deps.Timeout = 20 * time.Millisecond
+
+		results := NewRunner(deps).SelfTest(context.Background())
+		result := resultByName(t, results, "upstream:api")
+		if result.Status != tt.want {
+			t.Errorf("%s: expected %s, got %s (%s)", tt.name, tt.want, result.Status, result.Message)
+		}
+		if result.Critical {
+			t.Errorf("%s: upstream checks should not be critical", tt.name)
+		}
+		if failures := CriticalFailures(results); len(failures) != 0 {
+			t.Errorf("%s: upstream failures should not fail fast, got %v", tt.name, failures)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// RetryConfig controls how OpenWithRetry retries a failed session open
+type RetryConfig struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// DefaultRetryConfig returns the retry settings used at startup
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  5,
+		InitialDelay: 2 * time.Second,
+		MaxDelay:     30 * time.Second,
+	}
+}
+
+// sleep is swapped out in tests to avoid real delays
+var sleep = time.Sleep
+
+// OpenWithRetry calls open until it succeeds or MaxAttempts is reached,
+// doubling the delay between attempts up to MaxDelay
+func OpenWithRetry(open func() error, cfg RetryConfig) error {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	delay := cfg.InitialDelay
+	var err error
+
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		err = open()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Discord session opened on attempt %d/%d", attempt, cfg.MaxAttempts)
+			}
+			return nil
+		}
+
+		log.Printf("Failed to open Discord session (attempt %d/%d): %v", attempt, cfg.MaxAttempts, err)
+
+		if attempt < cfg.MaxAttempts {
+			log.Printf("Retrying in %v", delay)
+			sleep(delay)
+
+			delay *= 2
+			if cfg.MaxDelay > 0 && delay > cfg.MaxDelay {
+				delay = cfg.MaxDelay
+			}
+		}
+	}
+
+	return fmt.Errorf("failed to open session after %d attempts: %w", cfg.MaxAttempts, err)
+}
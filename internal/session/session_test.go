@@ -0,0 +1,59 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOpenWithRetrySucceedsAfterFailures(t *testing.T) {
+	var delays []time.Duration
+	sleep = func(d time.Duration) { delays = append(delays, d) }
+	defer func() { sleep = time.Sleep }()
+
+	calls := 0
+	open := func() error {
+		calls++
+		if calls <= 2 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	err := OpenWithRetry(open, RetryConfig{MaxAttempts: 5, InitialDelay: time.Second, MaxDelay: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 open calls, got %d", calls)
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second}
+	if len(delays) != len(expected) {
+		t.Fatalf("Expected %d backoff sleeps, got %v", len(expected), delays)
+	}
+	for i, d := range expected {
+		if delays[i] != d {
+			t.Errorf("Backoff %d: expected %v, got %v", i, d, delays[i])
+		}
+	}
+}
+
+func TestOpenWithRetryGivesUp(t *testing.T) {
+	sleep = func(time.Duration) {}
+	defer func() { sleep = time.Sleep }()
+
+	openErr := errors.New("gateway unavailable")
+	calls := 0
+	err := OpenWithRetry(func() error {
+		calls++
+		return openErr
+	}, RetryConfig{MaxAttempts: 3, InitialDelay: time.Second})
+
+	if !errors.Is(err, openErr) {
+		t.Errorf("Expected wrapped open error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 open calls, got %d", calls)
+	}
+}
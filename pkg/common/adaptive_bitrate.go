@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// startAdaptiveBitrateLocked puts an adaptive bitrate controller in front of
+// the pipeline's metric sink when options ask for one, so it sees the
+// underrun and stall counters, and steps the encoder's bitrate until the
+// stream ends. Each track starts again at the configured bitrate. Callers
+// hold ap.mu.
+func (ap *AudioPipeline) startAdaptiveBitrateLocked(options OpusOptions) {
+	if !options.AdaptiveBitrate || ap.passthrough {
+		return
+	}
+
+	config := pipeline.OpusConfig{
+		Bitrate:    currentOpusFormat().bitrate,
+		MinBitrate: options.MinBitrate,
+		MaxBitrate: options.MaxBitrate,
+	}
+	controller := pipeline.NewAdaptiveBitrateController(config, ap.setBitrate, metricRecorder(ap.metrics))
+	if ap.events != nil {
+		controller.SetEventRecorder(ap.events)
+	}
+	ap.metrics = controller
+
+	ctx, cancel := context.WithCancel(ap.ctx)
+	ap.stopAdaptive = cancel
+	go controller.Run(ctx, pipeline.DefaultAdaptiveInterval)
+}
+
+// stopAdaptiveBitrate stops the pipeline's controller, if it has one
+func (ap *AudioPipeline) stopAdaptiveBitrate() {
+	ap.mu.RLock()
+	stop := ap.stopAdaptive
+	ap.mu.RUnlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+// setBitrate asks the encode loop to switch to bitrate before its next
+// frame; the encoder isn't safe to change while it is encoding
+func (ap *AudioPipeline) setBitrate(bitrate int) {
+	atomic.StoreInt64(&ap.pendingBitrate, int64(bitrate))
+}
+
+// applyPendingBitrate sets a bitrate requested by setBitrate on the encoder.
+// Only the encode loop calls it.
+func (ap *AudioPipeline) applyPendingBitrate() {
+	if bitrate := atomic.SwapInt64(&ap.pendingBitrate, 0); bitrate > 0 {
+		ap.opusEncoder.SetBitrate(int(bitrate))
+	}
+}
+
+// metricRecorder adapts a MetricSink to a pipeline.MetricRecorder, dropping
+// histograms and timings the sink can't take
+func metricRecorder(metrics MetricSink) pipeline.MetricRecorder {
+	switch recorder := metrics.(type) {
+	case nil:
+		return pipeline.NoopMetricRecorder{}
+	case pipeline.MetricRecorder:
+		return recorder
+	default:
+		return sinkRecorder{recorder}
+	}
+}
+
+// sinkRecorder is a MetricSink as a pipeline.MetricRecorder
+type sinkRecorder struct {
+	MetricSink
+}
+
+// Histogram implements pipeline.MetricRecorder
+func (sinkRecorder) Histogram(string, float64, map[string]string) {}
+
+// Timing implements pipeline.MetricRecorder
+func (sinkRecorder) Timing(string, time.Duration, map[string]string) {}
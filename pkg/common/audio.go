@@ -2,18 +2,25 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
 	"layeh.com/gopus"
 )
 
+// errMaxRestarts ends playback once the stream has failed too many times
+var errMaxRestarts = errors.New("max restarts exceeded")
+
 // AudioPipeline manages the entire audio streaming pipeline
 type AudioPipeline struct {
 	ctx         context.Context
@@ -24,15 +31,92 @@ type AudioPipeline struct {
 	isPlaying   bool
 	mu          sync.RWMutex
 
+	// Pause handling; pausedFor is the total time spent paused before the
+	// current pause, which began at pausedAt. resumedAt is when the last
+	// pause ended.
+	paused     bool
+	resumeChan chan struct{}
+	pausedAt   time.Time
+	pausedFor  time.Duration
+	resumedAt  time.Time
+
+	// Per-track loudness compensation in dB
+	gainDB float64
+
+	// Leading silence trimming; see SetSilenceTrim. trimmedFrames counts the
+	// frames dropped since the last seek.
+	trimSilence        bool
+	silenceThresholdDB float64
+	trimmedFrames      int64
+
+	// Seek handling: ffmpeg starts at startOffset, which framesDelivered had
+	// reached seekFrames when it was set
+	startOffset time.Duration
+	seekFrames  int64
+	seekPending bool
+
 	// Health monitoring
-	lastFrameTime time.Time
-	healthTicker  *time.Ticker
+	healthTicker *time.Ticker
+
+	// Receives the position while playing, closed once the stream loop
+	// exits; see PositionUpdates
+	positionUpdates chan time.Duration
+	positionsClosed bool
+
+	// Frames that reached the voice connection and when the last one did, in
+	// Unix nanoseconds; see RecordFrame
+	framesDelivered int64
+	lastFrameAt     int64
 
 	// Error handling
 	errorChan    chan error
 	restartChan  chan struct{}
-	maxRestarts  int
 	restartCount int
+
+	// The error behind the pending restart signal, for tagging the retry
+	restartErr error
+
+	// Caps restarts per time window, across the pipelines sharing it; see
+	// SetRecoveryBudget
+	recoveryBudget *pipeline.RecoveryBudget
+
+	// Playback source and terminal outcome
+	streamer  Streamer
+	startedAt time.Time
+	outcome   Outcome
+
+	// ffmpeg binary run by the default streamer; empty means "ffmpeg" on PATH
+	ffmpegPath string
+
+	// Frames between the ffmpeg reader and the encoder, and where their
+	// metrics go (nil discards them)
+	frameBuffer *FrameBuffer
+	metrics     MetricSink
+
+	// Where the encoder comes from; nil creates one per pipeline
+	sessionEncoder *SessionEncoder
+
+	// Whether ffmpeg encodes opus itself; set by PlayStream from OpusOptions
+	passthrough bool
+
+	// Adaptive bitrate: the bitrate the encode loop should switch to (0 for
+	// none) and what stops the controller; see startAdaptiveBitrateLocked
+	pendingBitrate int64
+	stopAdaptive   context.CancelFunc
+
+	// Where error and recovery events go; nil discards them
+	events EventSink
+
+	// What reconnects voice after Discord invalidates the session; see
+	// SetVoiceSession
+	voiceSession pipeline.VoiceSession
+
+	// Delivery counters for PlaybackQuality; sendLatency is in nanoseconds
+	// over sendSamples frames
+	underruns   int64
+	reconnects  int64
+	sendLatency int64
+	sendSamples int64
 }
 
 // NewAudioPipeline creates a new audio pipeline
@@ -40,14 +124,62 @@ func NewAudioPipeline(vc *discordgo.VoiceConnection) *AudioPipeline {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &AudioPipeline{
-		ctx:           ctx,
-		cancel:        cancel,
-		voiceConn:     vc,
-		maxRestarts:   3,
-		errorChan:     make(chan error, 10),
-		restartChan:   make(chan struct{}, 1),
-		lastFrameTime: time.Now(),
+		ctx:         ctx,
+		cancel:      cancel,
+		voiceConn:   vc,
+		errorChan:   make(chan error, 10),
+		restartChan: make(chan struct{}, 1),
+
+		recoveryBudget: pipeline.NewRecoveryBudget(time.Now),
+	}
+}
+
+// SetGain sets the volume adjustment, in dB, applied to the stream. It takes
+// effect on the next ffmpeg start, so call it before PlayStream.
+func (ap *AudioPipeline) SetGain(db float64) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.gainDB = db
+}
+
+// SetMetricSink sets where frame buffer metrics are reported. It takes
+// effect on the next ffmpeg start.
+func (ap *AudioPipeline) SetMetricSink(metrics MetricSink) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.metrics = metrics
+}
+
+// FrameBufferStats returns the state of the buffer feeding the encoder, or
+// false if no stream has started
+func (ap *AudioPipeline) FrameBufferStats() (FrameBufferStats, bool) {
+	ap.mu.RLock()
+	buffer := ap.frameBuffer
+	ap.mu.RUnlock()
+
+	if buffer == nil {
+		return FrameBufferStats{}, false
+	}
+	return buffer.Stats(), true
+}
+
+// SetVoiceConnection swaps the voice connection frames are sent to, for
+// moving playback to another channel. The ffmpeg stream keeps running; call
+// Seek afterwards to restart it once the new connection is ready.
+func (ap *AudioPipeline) SetVoiceConnection(vc *discordgo.VoiceConnection) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	if ap.isPlaying && ap.voiceConn != nil && vc != ap.voiceConn {
+		atomic.AddInt64(&ap.reconnects, 1)
 	}
+	ap.voiceConn = vc
+}
+
+// voiceConnection returns the current voice connection
+func (ap *AudioPipeline) voiceConnection() *discordgo.VoiceConnection {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	return ap.voiceConn
 }
 
 // PlayStream starts streaming audio from the given URL
@@ -59,18 +191,31 @@ func (ap *AudioPipeline) PlayStream(streamURL string) error {
 		return fmt.Errorf("pipeline is already playing")
 	}
 
-	// Initialize Opus encoder
-	encoder, err := gopus.NewEncoder(48000, 2, gopus.Audio)
-	if err != nil {
-		return fmt.Errorf("failed to create opus encoder: %v", err)
+	// Fail fast when the default streamer has no ffmpeg to run
+	if ap.streamer == nil {
+		if err := pipeline.CheckFFmpegBinary(ap.ffmpegBinaryLocked()); err != nil {
+			return err
+		}
 	}
-	encoder.SetBitrate(128000) // Higher bitrate for better quality
-	ap.opusEncoder = encoder
+
+	// Initialize Opus encoder, reusing the session's when the format allows;
+	// in passthrough ffmpeg encodes instead
+	options := currentOpusOptions()
+	ap.passthrough = options.Passthrough
+	if !ap.passthrough {
+		if err := ap.acquireEncoderLocked(); err != nil {
+			return err
+		}
+	}
+	ap.startAdaptiveBitrateLocked(options)
 
 	ap.isPlaying = true
+	ap.startedAt = time.Now()
+	addActivePipeline(1)
 
-	// Start health monitoring
+	// Start health monitoring and position updates
 	ap.startHealthMonitoring()
+	ap.startPositionUpdatesLocked()
 
 	// Start the main streaming goroutine
 	go ap.streamLoop(streamURL)
@@ -84,74 +229,169 @@ func (ap *AudioPipeline) PlayStream(streamURL string) error {
 // streamLoop is the main audio streaming loop with restart capability
 func (ap *AudioPipeline) streamLoop(streamURL string) {
 	defer func() {
+		// Released first so the next track can reuse it as soon as this one
+		// stops playing
+		ap.stopAdaptiveBitrate()
+		ap.releaseEncoder()
 		ap.mu.Lock()
 		ap.isPlaying = false
+		ap.closePositionUpdatesLocked()
 		ap.mu.Unlock()
+		addActivePipeline(-1)
 	}()
 
 	// Add a restart mutex to prevent multiple simultaneous restarts
 	var restartMutex sync.Mutex
 
-	for {
-		select {
-		case <-ap.ctx.Done():
-			log.Println("Audio pipeline context cancelled")
-			return
-		case <-ap.restartChan:
-			restartMutex.Lock()
-			if ap.restartCount >= ap.maxRestarts {
-				log.Printf("Max restart attempts (%d) reached, stopping", ap.maxRestarts)
-				ap.errorChan <- fmt.Errorf("max restarts exceeded")
-				restartMutex.Unlock()
+	// The first stream starts right away; later ones wait for the restart
+	// signal sent by the error path below or by errorHandler
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ap.ctx.Done():
+				log.Println("Audio pipeline context cancelled")
+				ap.finish(OutcomeUserStopped, nil)
 				return
+			case <-ap.restartChan:
+				restartMutex.Lock()
+				backoff := recoveryBackoff()
+				if ap.restartCount >= backoff.MaxRetries {
+					log.Printf("Max restart attempts (%d) reached, stopping", backoff.MaxRetries)
+					err := errMaxRestarts
+					ap.recordStreamError(err, false)
+					ap.finish(OutcomeError, err)
+					ap.errorChan <- err
+					restartMutex.Unlock()
+					return
+				}
+				if !ap.spendRecoveryBudget() {
+					err := errRecoveryBudgetExhausted
+					ap.recordStreamError(err, false)
+					ap.finish(OutcomeError, err)
+					ap.errorChan <- err
+					restartMutex.Unlock()
+					return
+				}
+				ap.mu.Lock()
+				ap.restartCount++
+				restarts := ap.restartCount
+				restartErr := ap.restartErr
+				ap.mu.Unlock()
+
+				// An invalidated voice session is reconnected before the
+				// stream restarts; anything else just restarts it
+				strategy := "restart"
+				reconnect := ap.voiceReconnectStrategy(restartErr)
+				if reconnect != nil {
+					strategy = reconnect.Name()
+				}
+
+				delay := backoff.Delay(restarts)
+				log.Printf("Restarting audio pipeline in %v (attempt %d/%d, %s)", delay, restarts, backoff.MaxRetries, strategy)
+				ap.recordRecovery(restarts, backoff.MaxRetries, strategy, streamErrorCategory(restartErr))
+				restartMutex.Unlock()
+
+				select {
+				case <-time.After(delay):
+				case <-ap.ctx.Done():
+					log.Println("Audio pipeline context cancelled")
+					ap.finish(OutcomeUserStopped, nil)
+					return
+				}
+
+				if reconnect != nil {
+					if err := ap.reconnectVoice(reconnect); err != nil {
+						log.Printf("Voice reconnect failed: %v", err)
+						ap.recordStreamError(err, false)
+						ap.finish(OutcomeError, err)
+						ap.errorChan <- err
+						return
+					}
+				}
 			}
-			ap.restartCount++
-			log.Printf("Restarting audio pipeline (attempt %d/%d)", ap.restartCount, ap.maxRestarts)
-			time.Sleep(2 * time.Second) // Brief delay before restart
-			restartMutex.Unlock()
+		} else if ap.ctx.Err() != nil {
+			log.Println("Audio pipeline context cancelled")
+			ap.finish(OutcomeUserStopped, nil)
+			return
 		}
 
-		err := ap.streamAudio(streamURL)
+		err := ap.stream(streamURL)
+		for err == nil && ap.takePendingSeek() {
+			// ffmpeg was stopped to reposition; start it again at the new offset
+			err = ap.stream(streamURL)
+		}
 		if err != nil {
 			log.Printf("Stream error: %v", err)
-			ap.errorChan <- err
 
-			// Check if we should restart
-			if ap.shouldRestart(err) {
+			// Check if we should restart; errorHandler only hears about
+			// errors that end playback, so it doesn't spend a second retry
+			// on the one being restarted from
+			restart := ap.shouldRestart(err)
+			ap.recordStreamError(err, restart)
+			if restart {
 				restartMutex.Lock()
-				select {
-				case ap.restartChan <- struct{}{}:
-				default:
-					// If channel is full, don't send another restart signal
-				}
+				ap.requestRestart(err)
 				restartMutex.Unlock()
 				continue
 			}
+			ap.finish(OutcomeError, err)
+			ap.errorChan <- err
 			return
 		}
 
-		// Normal completion
+		// Normal completion; a Stop that cancelled the stream has already
+		// recorded its own outcome
 		log.Println("Audio stream completed normally")
+		ap.finish(OutcomeCompleted, nil)
 		return
 	}
 }
 
+// stream plays the stream with the configured streamer, defaulting to ffmpeg
+func (ap *AudioPipeline) stream(streamURL string) error {
+	ap.mu.RLock()
+	streamer := ap.streamer
+	ap.mu.RUnlock()
+
+	if streamer != nil {
+		return streamer(ap.ctx, streamURL)
+	}
+	return ap.streamAudio(streamURL)
+}
+
 // streamAudio handles the actual audio streaming
 func (ap *AudioPipeline) streamAudio(streamURL string) error {
-	// Create FFmpeg command with better error handling and buffering
-	cmd := exec.CommandContext(ap.ctx, "ffmpeg",
-		"-reconnect", "1",
-		"-reconnect_streamed", "1",
-		"-reconnect_delay_max", "5",
-		"-i", streamURL,
-		"-f", "s16le",
-		"-acodec", "pcm_s16le",
-		"-ar", "48000",
-		"-ac", "2",
-		"-bufsize", "64k",
-		"-")
+	// Input options, such as reconnecting, and the buffer size come from the
+	// profile for the stream's source kind
+	ffmpegConfig := sourceFFmpegConfig(streamURL)
 
+	ap.mu.RLock()
+	info := &pipeline.StreamInfo{URL: streamURL, StartOffset: ap.startOffset}
+	passthrough := ap.passthrough
+	ap.mu.RUnlock()
+
+	info.AudioFilters = ap.audioFilters(streamURL)
+	args := info.FFmpegArgs(ffmpegConfig.Args)
+	normalized := len(sourceAudioFilters(streamURL)) > 0
+
+	if passthrough {
+		args = append(args, opusOutputArgs()...)
+	} else {
+		args = append(args,
+			"-f", "s16le",
+			"-acodec", "pcm_s16le",
+			"-ar", "48000",
+			"-ac", "2",
+			"-bufsize", ffmpegConfig.BufferSize,
+			"-")
+	}
+
+	binary := ap.ffmpegBinary()
+	cmd := exec.CommandContext(ap.ctx, binary, args...)
+
+	ap.mu.Lock()
 	ap.ffmpegCmd = cmd
+	ap.mu.Unlock()
 
 	// Capture stderr for debugging
 	stderrPipe, err := cmd.StderrPipe()
@@ -171,16 +411,12 @@ func (ap *AudioPipeline) streamAudio(streamURL string) error {
 	// Start FFmpeg
 	log.Println("Starting FFmpeg process...")
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start ffmpeg: %v", err)
+		return ffmpegStartError(binary, err)
 	}
 
-	// Ensure process cleanup
-	defer func() {
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
-		cmd.Wait()
-	}()
+	// Ensure the process is killed and reaped however streaming ends
+	ap.trackFFmpeg(cmd)
+	defer ap.reapFFmpeg(cmd, normalized)
 
 	// Wait for voice connection readiness
 	if err := ap.waitForVoiceReady(); err != nil {
@@ -188,20 +424,68 @@ func (ap *AudioPipeline) streamAudio(streamURL string) error {
 	}
 
 	// Start speaking
-	ap.voiceConn.Speaking(true)
-	defer ap.voiceConn.Speaking(false)
+	vc := ap.voiceConnection()
+	vc.Speaking(true)
+	defer vc.Speaking(false)
 
 	log.Println("Starting audio stream to Discord...")
 
 	// Stream audio with proper buffering and error handling
+	if passthrough {
+		return ap.streamOpusToDiscord(stdout)
+	}
 	return ap.streamPCMToDiscord(stdout)
 }
 
-// streamPCMToDiscord handles the PCM to Opus conversion and Discord streaming
+// opusOutputArgs returns the ffmpeg output arguments for passthrough:
+// libopus packets of the configured frame duration in an Ogg stream, in the
+// format the encoder would use, with the configured FEC and DTX
+func opusOutputArgs() []string {
+	format := currentOpusFormat()
+	options := currentOpusOptions()
+
+	frameDuration := options.FrameDuration
+	if frameDuration <= 0 {
+		frameDuration = pipeline.DiscordOpusFrameDuration
+	}
+	frameMillis := float64(frameDuration) / float64(time.Millisecond)
+
+	args := []string{
+		"-c:a", "libopus",
+		"-b:a", strconv.Itoa(format.bitrate),
+		"-application", opusApplicationName(format.application),
+		"-frame_duration", strconv.FormatFloat(frameMillis, 'f', -1, 64),
+	}
+	if options.FEC {
+		args = append(args, "-fec", "1", "-packet_loss", strconv.Itoa(options.PacketLoss))
+	}
+	if options.DTX {
+		args = append(args, "-dtx", "1")
+	}
+	return append(args,
+		"-ar", strconv.Itoa(format.sampleRate),
+		"-ac", strconv.Itoa(format.channels),
+		"-f", "ogg",
+		"-",
+	)
+}
+
+// streamPCMToDiscord handles the PCM to Opus conversion and Discord streaming.
+// A reader goroutine fills a bounded frame buffer from ffmpeg while this loop
+// encodes and sends; when the buffer is full the reader waits, so ffmpeg
+// back-pressures on its pipe.
 func (ap *AudioPipeline) streamPCMToDiscord(reader io.Reader) error {
-	// Use buffered reader for better performance
-	buffer := make([]byte, 3840) // 960 samples * 2 channels * 2 bytes (20ms at 48kHz)
-	frameCount := 0
+	ap.mu.Lock()
+	buffer := NewFrameBuffer(DefaultFrameBufferSize, ap.metrics)
+	ap.frameBuffer = buffer
+	offset := ap.startOffset
+	ap.mu.Unlock()
+
+	go buffer.Fill(reader)
+	defer buffer.Close()
+
+	started := false
+	trimThreshold := ap.silenceTrimThreshold(offset)
 
 	for {
 		select {
@@ -210,74 +494,133 @@ func (ap *AudioPipeline) streamPCMToDiscord(reader io.Reader) error {
 		default:
 		}
 
-		// Read PCM data with timeout
-		readDone := make(chan int, 1)
-		readErr := make(chan error, 1)
-
-		go func() {
-			n, err := io.ReadFull(reader, buffer)
-			if err != nil {
-				readErr <- err
-				return
+		// Block while paused; the buffer fills and ffmpeg back-pressures on
+		// the unread pipe
+		if ap.IsPaused() {
+			buffer.setHeld(true)
+			resumed := ap.waitWhilePaused()
+			buffer.setHeld(false)
+			if !resumed {
+				return nil
 			}
-			readDone <- n
-		}()
-
-		var n int
-		var err error
+		}
 
-		select {
-		case n = <-readDone:
-		case err = <-readErr:
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// An empty buffer once audio is flowing means ffmpeg fell behind
+		starved := started && buffer.Stats().Occupancy == 0
+		frame, err := buffer.Next(5 * time.Second)
+		if err != nil {
+			if err == io.EOF {
 				log.Println("FFmpeg stream ended normally")
 				return nil
 			}
+			if err == ErrFrameTimeout {
+				return err
+			}
 			return fmt.Errorf("error reading PCM data: %v", err)
-		case <-time.After(5 * time.Second):
-			return fmt.Errorf("timeout reading PCM data")
+		}
+		if starved {
+			ap.noteUnderrun()
 		}
 
-		if n > 0 {
-			// Convert bytes to int16 samples
-			samples := bytesToInt16(buffer[:n])
-
-			// Ensure we have exactly 960 samples per channel for 20ms frames
-			if len(samples) != 1920 { // 960 samples * 2 channels
-				// Pad or truncate to correct size
-				if len(samples) < 1920 {
-					padded := make([]int16, 1920)
-					copy(padded, samples)
-					samples = padded
-				} else {
-					samples = samples[:1920]
-				}
+		// Drop leading silence until the first audible frame
+		if trimThreshold > 0 {
+			if ap.trimFrame(frame, trimThreshold) {
+				continue
 			}
+			trimThreshold = 0
+		}
+		started = true
+
+		// Convert bytes to int16 samples; frames are always 960 samples per
+		// channel, the last one zero-padded
+		samples := bytesToInt16(frame)
+
+		// Encode to Opus
+		ap.applyPendingBitrate()
+		opusData, err := ap.opusEncoder.Encode(samples, 960, len(frame))
+		if err != nil {
+			log.Printf("Opus encoding error: %v", err)
+			continue
+		}
+
+		ap.sendOpus(opusData)
+	}
+}
+
+// streamOpusToDiscord forwards the packets of ffmpeg's Ogg Opus output in
+// passthrough. A reader goroutine queues up to DefaultFrameBufferSize packets;
+// while paused it stops reading, so ffmpeg back-pressures on its pipe.
+func (ap *AudioPipeline) streamOpusToDiscord(reader io.Reader) error {
+	packets := make(chan []byte, DefaultFrameBufferSize)
+	readErr := make(chan error, 1)
 
-			// Encode to Opus
-			opusData, err := ap.opusEncoder.Encode(samples, 960, len(buffer))
+	go func() {
+		defer close(packets)
+		ogg := NewOggOpusReader(reader)
+		for {
+			packet, err := ogg.ReadPacket()
 			if err != nil {
-				log.Printf("Opus encoding error: %v", err)
-				continue
+				readErr <- err
+				return
 			}
-
-			// Send to Discord with non-blocking send
 			select {
-			case ap.voiceConn.OpusSend <- opusData:
-				frameCount++
-				ap.lastFrameTime = time.Now()
+			case packets <- packet:
+			case <-ap.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	started := false
+	for {
+		if ap.IsPaused() && !ap.waitWhilePaused() {
+			return nil
+		}
 
-				// Log progress every 100 frames (2 seconds)
-				if frameCount%100 == 0 {
-					log.Printf("Streamed %d frames", frameCount)
+		starved := started && len(packets) == 0
+
+		select {
+		case <-ap.ctx.Done():
+			return nil
+		case packet, ok := <-packets:
+			if !ok {
+				err := <-readErr
+				if err == io.EOF {
+					log.Println("FFmpeg stream ended normally")
+					return nil
 				}
-			case <-time.After(100 * time.Millisecond):
-				log.Println("Warning: OpusSend channel blocked, skipping frame")
+				return fmt.Errorf("error reading opus data: %v", err)
 			}
+			if starved {
+				ap.noteUnderrun()
+			}
+			started = true
+			ap.sendOpus(packet)
+		case <-time.After(5 * time.Second):
+			return ErrFrameTimeout
 		}
 	}
 }
 
+// sendOpus sends one 20ms opus frame to Discord, skipping it if the voice
+// connection does not take it within 100ms
+func (ap *AudioPipeline) sendOpus(opusData []byte) {
+	start := time.Now()
+	select {
+	case ap.voiceConnection().OpusSend <- opusData:
+		ap.noteSendLatency(time.Since(start))
+		ap.RecordFrame()
+		sent := ap.FramesDelivered()
+
+		// Log progress every 100 frames (2 seconds)
+		if sent%100 == 0 {
+			log.Printf("Streamed %d frames", sent)
+		}
+	case <-time.After(100 * time.Millisecond):
+		log.Println("Warning: OpusSend channel blocked, skipping frame")
+	}
+}
+
 // Error handling
 func (ap *AudioPipeline) errorHandler(_ string) {
 	for {
@@ -289,12 +632,10 @@ func (ap *AudioPipeline) errorHandler(_ string) {
 
 			if ap.shouldRestart(err) {
 				log.Println("Attempting to restart pipeline...")
-				select {
-				case ap.restartChan <- struct{}{}:
-				default:
-				}
+				ap.requestRestart(err)
 			} else {
 				log.Println("Error is not recoverable, stopping pipeline")
+				ap.finish(OutcomeError, err)
 				ap.Stop()
 				return
 			}
@@ -302,12 +643,31 @@ func (ap *AudioPipeline) errorHandler(_ string) {
 	}
 }
 
+// requestRestart signals streamLoop to restart after err, unless a restart
+// is already pending
+func (ap *AudioPipeline) requestRestart(err error) {
+	select {
+	case ap.restartChan <- struct{}{}:
+		ap.mu.Lock()
+		ap.restartErr = err
+		ap.mu.Unlock()
+	default:
+		// If channel is full, don't send another restart signal
+	}
+}
+
 // shouldRestart determines if an error is recoverable
 func (ap *AudioPipeline) shouldRestart(err error) bool {
-	if ap.restartCount >= ap.maxRestarts {
+	if ap.restartCount >= recoveryBackoff().MaxRetries {
 		return false
 	}
 
+	// Voice closes need a reconnect, which only some close codes allow; the
+	// rest, such as 4014 for being disconnected, end playback
+	if isVoiceClose(err) {
+		return ap.voiceReconnectStrategy(err) != nil
+	}
+
 	// Add logic to determine which errors are recoverable
 	errStr := err.Error()
 	recoverableErrors := []string{
@@ -334,10 +694,13 @@ func (ap *AudioPipeline) waitForVoiceReady() error {
 
 	for {
 		select {
+		case <-ap.ctx.Done():
+			// Stopped while waiting; return so ffmpeg is reaped now
+			return ap.ctx.Err()
 		case <-timeout:
-			return fmt.Errorf("timeout waiting for voice connection")
+			return fmt.Errorf("timeout waiting for voice connection: %w", ErrVoiceUnavailable)
 		case <-ticker.C:
-			if ap.voiceConn.Ready {
+			if ap.voiceConnection().Ready {
 				return nil
 			}
 		}
@@ -357,12 +720,19 @@ func (ap *AudioPipeline) consumeStderr(stderr io.ReadCloser) {
 	}
 }
 
-// Stop gracefully stops the audio pipeline
+// Stop gracefully stops the audio pipeline, recording it as stopped by the user
 func (ap *AudioPipeline) Stop() {
+	ap.StopWithReason(OutcomeUserStopped)
+}
+
+// StopWithReason stops the audio pipeline and records why, unless the track
+// had already ended
+func (ap *AudioPipeline) StopWithReason(reason OutcomeReason) {
 	ap.mu.Lock()
 	defer ap.mu.Unlock()
 
 	log.Println("Stopping audio pipeline...")
+	ap.finishLocked(reason, nil)
 	ap.cancel()
 
 	if ap.ffmpegCmd != nil && ap.ffmpegCmd.Process != nil {
@@ -374,6 +744,12 @@ func (ap *AudioPipeline) Stop() {
 	}
 
 	ap.isPlaying = false
+
+	// Back to the start of the track, so Position reads zero
+	ap.startOffset = 0
+	atomic.StoreInt64(&ap.framesDelivered, 0)
+	atomic.StoreInt64(&ap.seekFrames, 0)
+	atomic.StoreInt64(&ap.trimmedFrames, 0)
 }
 
 // IsPlaying returns whether the pipeline is currently playing
@@ -383,6 +759,170 @@ func (ap *AudioPipeline) IsPlaying() bool {
 	return ap.isPlaying
 }
 
+// Pause suspends sending audio frames until Resume is called
+func (ap *AudioPipeline) Pause() error {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	if !ap.isPlaying {
+		return fmt.Errorf("pipeline is not playing")
+	}
+	if ap.paused {
+		return fmt.Errorf("pipeline is already paused")
+	}
+
+	ap.paused = true
+	ap.pausedAt = time.Now()
+	ap.resumeChan = make(chan struct{})
+
+	if ap.voiceConn != nil {
+		ap.voiceConn.Speaking(false)
+	}
+
+	log.Println("Audio pipeline paused")
+	return nil
+}
+
+// Resume continues playback after a Pause
+func (ap *AudioPipeline) Resume() error {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	if !ap.paused {
+		return fmt.Errorf("pipeline is not paused")
+	}
+
+	ap.paused = false
+	ap.resumedAt = time.Now()
+	ap.pausedFor += ap.resumedAt.Sub(ap.pausedAt)
+	ap.pausedAt = time.Time{}
+	close(ap.resumeChan)
+
+	if ap.voiceConn != nil {
+		ap.voiceConn.Speaking(true)
+	}
+
+	log.Println("Audio pipeline resumed")
+	return nil
+}
+
+// Seek repositions playback to the given offset from the start of the track
+// by restarting ffmpeg there
+func (ap *AudioPipeline) Seek(position time.Duration) error {
+	ap.mu.Lock()
+	if !ap.isPlaying {
+		ap.mu.Unlock()
+		return fmt.Errorf("pipeline is not playing")
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	ap.startOffset = position
+	atomic.StoreInt64(&ap.seekFrames, ap.FramesDelivered())
+	atomic.StoreInt64(&ap.trimmedFrames, 0)
+	ap.seekPending = true
+	cmd := ap.ffmpegCmd
+	ap.mu.Unlock()
+
+	// Stopping ffmpeg ends the current stream; streamLoop then restarts it at the new offset
+	if cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+
+	log.Printf("Audio pipeline seeking to %v", position)
+	return nil
+}
+
+// takePendingSeek reports whether the last stream ended because of a seek,
+// clearing the flag
+func (ap *AudioPipeline) takePendingSeek() bool {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	pending := ap.seekPending
+	ap.seekPending = false
+	return pending
+}
+
+// Position returns the current playback position within the track
+func (ap *AudioPipeline) Position() time.Duration {
+	ap.mu.RLock()
+	offset := ap.startOffset
+	ap.mu.RUnlock()
+
+	frames := ap.FramesDelivered() - atomic.LoadInt64(&ap.seekFrames) + atomic.LoadInt64(&ap.trimmedFrames)
+	return offset + time.Duration(frames)*20*time.Millisecond
+}
+
+// Elapsed returns how long the track has been playing, leaving out time
+// spent paused. Once the track ends it stays at its final value.
+func (ap *AudioPipeline) Elapsed() time.Duration {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+
+	if !ap.outcome.IsZero() {
+		return ap.outcome.Elapsed
+	}
+	return ap.elapsedLocked(time.Now())
+}
+
+// elapsedLocked is Elapsed as of now, for callers holding ap.mu
+func (ap *AudioPipeline) elapsedLocked(now time.Time) time.Duration {
+	if ap.startedAt.IsZero() {
+		return 0
+	}
+
+	elapsed := now.Sub(ap.startedAt) - ap.pausedFor
+	if ap.paused {
+		elapsed -= now.Sub(ap.pausedAt)
+	}
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
+// IsPaused returns whether the pipeline is currently paused
+func (ap *AudioPipeline) IsPaused() bool {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	return ap.paused
+}
+
+// waitWhilePaused blocks until the pipeline is resumed, sending keep-alive
+// silence frames meanwhile if SetPauseKeepAlive is on. It returns false if
+// the pipeline was stopped while waiting.
+func (ap *AudioPipeline) waitWhilePaused() bool {
+	ap.mu.RLock()
+	paused := ap.paused
+	resumeChan := ap.resumeChan
+	ap.mu.RUnlock()
+
+	if !paused {
+		return true
+	}
+
+	// Keep the voice connection warm with silence if configured
+	var keepAlive <-chan time.Time
+	if interval := currentPauseKeepAlive(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	for {
+		select {
+		case <-resumeChan:
+			return true
+		case <-ap.ctx.Done():
+			return false
+		case <-keepAlive:
+			ap.sendKeepAlive()
+		}
+	}
+}
+
 // Helper functions
 func bytesToInt16(data []byte) []int16 {
 	samples := make([]int16, len(data)/2)
@@ -421,12 +961,19 @@ func (ap *AudioPipeline) checkHealth() {
 	ap.mu.RLock()
 	defer ap.mu.RUnlock()
 
-	if !ap.isPlaying {
+	if !ap.isPlaying || ap.paused {
 		return
 	}
 
-	// Check if we haven't received frames in a while
-	if time.Since(ap.lastFrameTime) > 10*time.Second {
+	// Check if we haven't received frames in a while, counting from when
+	// playback started or last resumed if no frame has arrived since
+	lastActivity := ap.LastFrameAt()
+	for _, t := range []time.Time{ap.startedAt, ap.resumedAt} {
+		if t.After(lastActivity) {
+			lastActivity = t
+		}
+	}
+	if time.Since(lastActivity) > 10*time.Second {
 		log.Println("Health check failed: no frames received in 10 seconds")
 		ap.errorChan <- fmt.Errorf("stream health check failed: no recent frames")
 	}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Chapter is a titled section of a video, as marked by its uploader
+type Chapter struct {
+	Title string
+	Start time.Duration
+	End   time.Duration
+}
+
+// ytDLPChapter is a chapter as printed by yt-dlp's %(chapters)j
+type ytDLPChapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// ParseChapters parses yt-dlp's JSON chapter list. Videos without chapters
+// print "NA" or "null", which yield no chapters and no error.
+func ParseChapters(raw string) ([]Chapter, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "NA" || raw == "null" {
+		return nil, nil
+	}
+
+	var parsed []ytDLPChapter
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse chapters: %v", err)
+	}
+
+	chapters := make([]Chapter, 0, len(parsed))
+	for i, c := range parsed {
+		title := strings.TrimSpace(c.Title)
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", i+1)
+		}
+		chapters = append(chapters, Chapter{
+			Title: title,
+			Start: secondsToDuration(c.StartTime),
+			End:   secondsToDuration(c.EndTime),
+		})
+	}
+	return chapters, nil
+}
+
+// ChapterAt returns the index of the chapter playing at position, or -1 if
+// position falls outside every chapter
+func ChapterAt(chapters []Chapter, position time.Duration) int {
+	for i, c := range chapters {
+		if position >= c.Start && (position < c.End || c.End <= c.Start) {
+			return i
+		}
+	}
+	return -1
+}
+
+// secondsToDuration converts yt-dlp's fractional seconds
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
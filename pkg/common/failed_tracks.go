@@ -0,0 +1,78 @@
+package common
+
+import "log"
+
+// DefaultMaxFailedTracks is how many skipped tracks a queue remembers for
+// retrying
+const DefaultMaxFailedTracks = 25
+
+// RecordFailedTrack remembers a track that was skipped because it failed to
+// play, so it can be retried later. Past the cap the oldest are forgotten.
+func (mq *MusicQueue) RecordFailedTrack(item *QueueItem) {
+	if item == nil {
+		return
+	}
+
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	mq.failedTracks = append(mq.failedTracks, item)
+	if over := len(mq.failedTracks) - mq.maxFailedTracks; over > 0 {
+		mq.failedTracks = mq.failedTracks[over:]
+	}
+}
+
+// FailedTracks returns a copy of the remembered failed tracks, oldest first
+func (mq *MusicQueue) FailedTracks() []*QueueItem {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+	failed := make([]*QueueItem, len(mq.failedTracks))
+	copy(failed, mq.failedTracks)
+	return failed
+}
+
+// ClearFailedTracks forgets the remembered failed tracks
+func (mq *MusicQueue) ClearFailedTracks() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.failedTracks = nil
+}
+
+// RetryFailedTracks re-enqueues the remembered failed tracks in the order
+// they failed, re-resolving each with resolve since the failure may have
+// been an expired or unreachable stream URL. Tracks that resolve leave the
+// failed list; the rest stay for another try. It returns the number of
+// tracks requeued and the number that failed to resolve.
+func (mq *MusicQueue) RetryFailedTracks(resolve StreamResolver) (int, int) {
+	failed := mq.FailedTracks()
+	if len(failed) == 0 {
+		return 0, 0
+	}
+
+	retried, unresolved := mq.resolveForRequeue(failed, resolve, "retry")
+
+	// Keep unresolved tracks, and any that failed while resolving
+	keep := make(map[*QueueItem]bool, len(unresolved))
+	for _, item := range unresolved {
+		keep[item] = true
+	}
+	attempted := make(map[*QueueItem]bool, len(failed))
+	for _, item := range failed {
+		attempted[item] = true
+	}
+
+	mq.mu.Lock()
+	remaining := mq.failedTracks[:0:0]
+	for _, item := range mq.failedTracks {
+		if keep[item] || !attempted[item] {
+			remaining = append(remaining, item)
+		}
+	}
+	mq.failedTracks = remaining
+	mq.items = append(mq.items, retried...)
+	mq.mu.Unlock()
+	mq.notify()
+
+	log.Printf("Requeued %d failed tracks for guild %s (%d still failing)", len(retried), mq.guildID, len(unresolved))
+	return len(retried), len(unresolved)
+}
@@ -0,0 +1,40 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// SetFFmpegPath sets the ffmpeg binary the pipeline runs. Call it before
+// PlayStream; empty restores the default "ffmpeg" looked up on PATH.
+func (ap *AudioPipeline) SetFFmpegPath(path string) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.ffmpegPath = path
+}
+
+// ffmpegBinary returns the configured ffmpeg binary, defaulting to "ffmpeg"
+func (ap *AudioPipeline) ffmpegBinary() string {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	return ap.ffmpegBinaryLocked()
+}
+
+// ffmpegBinaryLocked is ffmpegBinary for callers already holding ap.mu
+func (ap *AudioPipeline) ffmpegBinaryLocked() string {
+	if ap.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return ap.ffmpegPath
+}
+
+// ffmpegStartError wraps a failed ffmpeg start, turning a missing binary
+// into pipeline.ErrFFmpegNotFound. That error is not recoverable, so the
+// pipeline ends with it instead of restarting.
+func ffmpegStartError(path string, err error) error {
+	if pipeline.IsFFmpegMissing(err) {
+		return fmt.Errorf("%w (looked for %q): %w", pipeline.ErrFFmpegNotFound, path, err)
+	}
+	return fmt.Errorf("failed to start ffmpeg: %v", err)
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"log"
+	"os/exec"
+	"strconv"
+	"sync"
+)
+
+// MetricFFmpegProcesses is a gauge of ffmpeg processes spawned by pipelines
+// that have not been reaped yet
+const MetricFFmpegProcesses = "pipeline.ffmpeg.processes"
+
+// MetricFFmpegCPU counts the CPU time, in milliseconds, each pipeline ffmpeg
+// process used. It is tagged normalize=true or false, so the two series'
+// rates show what loudness normalization costs.
+const MetricFFmpegCPU = "pipeline.ffmpeg.cpu_ms"
+
+// ffmpegProcesses tracks every ffmpeg process the pipelines have started
+// until it is reaped, and how many pipelines are playing. Each pipeline
+// runs at most one ffmpeg at a time, so more processes than pipelines means
+// one was left behind.
+var ffmpegProcesses = struct {
+	mu        sync.Mutex
+	pids      map[int]struct{}
+	pipelines int
+}{pids: make(map[int]struct{})}
+
+// LiveFFmpegProcesses returns how many pipeline ffmpeg processes have been
+// started and not yet reaped
+func LiveFFmpegProcesses() int {
+	ffmpegProcesses.mu.Lock()
+	defer ffmpegProcesses.mu.Unlock()
+	return len(ffmpegProcesses.pids)
+}
+
+// ActiveAudioPipelines returns how many pipelines are playing
+func ActiveAudioPipelines() int {
+	ffmpegProcesses.mu.Lock()
+	defer ffmpegProcesses.mu.Unlock()
+	return ffmpegProcesses.pipelines
+}
+
+// addActivePipeline adjusts the count of playing pipelines by delta
+func addActivePipeline(delta int) {
+	ffmpegProcesses.mu.Lock()
+	ffmpegProcesses.pipelines += delta
+	ffmpegProcesses.mu.Unlock()
+}
+
+// trackFFmpeg records a started ffmpeg process, warning if there are now
+// more processes than playing pipelines
+func (ap *AudioPipeline) trackFFmpeg(cmd *exec.Cmd) {
+	ffmpegProcesses.mu.Lock()
+	ffmpegProcesses.pids[cmd.Process.Pid] = struct{}{}
+	live, pipelines := len(ffmpegProcesses.pids), ffmpegProcesses.pipelines
+	ffmpegProcesses.mu.Unlock()
+
+	if live > pipelines {
+		log.Printf("WARNING: %d ffmpeg processes alive for %d active pipelines; a process may have leaked", live, pipelines)
+	}
+	ap.reportFFmpegProcesses(live)
+}
+
+// reapFFmpeg kills a started ffmpeg process if it is still running, waits
+// for it so it doesn't linger as a zombie, stops tracking it and reports the
+// CPU time it used, tagged with whether it normalized loudness
+func (ap *AudioPipeline) reapFFmpeg(cmd *exec.Cmd, normalized bool) {
+	cmd.Process.Kill()
+	cmd.Wait()
+
+	ffmpegProcesses.mu.Lock()
+	delete(ffmpegProcesses.pids, cmd.Process.Pid)
+	live := len(ffmpegProcesses.pids)
+	ffmpegProcesses.mu.Unlock()
+
+	ap.reportFFmpegProcesses(live)
+
+	if state := cmd.ProcessState; state != nil {
+		ap.mu.RLock()
+		metrics := ap.metrics
+		ap.mu.RUnlock()
+
+		if metrics != nil {
+			cpu := state.UserTime() + state.SystemTime()
+			metrics.Counter(MetricFFmpegCPU, cpu.Milliseconds(), map[string]string{
+				"normalize": strconv.FormatBool(normalized),
+			})
+		}
+	}
+}
+
+// reportFFmpegProcesses sends the live process count to the metric sink
+func (ap *AudioPipeline) reportFFmpegProcesses(live int) {
+	ap.mu.RLock()
+	metrics := ap.metrics
+	ap.mu.RUnlock()
+
+	if metrics != nil {
+		metrics.Gauge(MetricFFmpegProcesses, float64(live), nil)
+	}
+}
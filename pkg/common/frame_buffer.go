@@ -0,0 +1,206 @@
+package common
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// MetricBufferOccupancy is a gauge of the frames waiting to be encoded,
+// sampled once every frameBufferOccupancySample frames. Stalls are counted
+// as pipeline.MetricBufferStalls.
+const MetricBufferOccupancy = "pipeline.buffer.occupancy"
+
+const (
+	// DefaultFrameBufferSize holds one second of 20ms frames
+	DefaultFrameBufferSize = 50
+
+	// pcmFrameSize is one 20ms frame: 960 samples * 2 channels * 2 bytes
+	pcmFrameSize = 3840
+
+	// frameBufferStallThreshold is how long the buffer may stay full before
+	// the encoder counts as falling behind. Paced playback drains a frame
+	// every 20ms, so only a stalled encoder or sender gets near it.
+	frameBufferStallThreshold = time.Second
+
+	// frameBufferOccupancySample is how many frames pass between occupancy
+	// gauges, one a second at 20ms frames
+	frameBufferOccupancySample = 50
+)
+
+// ErrFrameTimeout is returned by Next when no frame arrives in time
+var ErrFrameTimeout = errors.New("timeout reading PCM data")
+
+// MetricSink receives frame buffer metrics. pipeline.MetricRecorder
+// satisfies it.
+type MetricSink interface {
+	Counter(name string, value int64, tags map[string]string)
+	Gauge(name string, value float64, tags map[string]string)
+}
+
+// FrameBufferStats is a snapshot of a frame buffer's state
+type FrameBufferStats struct {
+	Capacity  int   // frames the buffer can hold
+	Occupancy int   // frames waiting now
+	Peak      int   // highest occupancy seen
+	FullWaits int64 // times the reader had to wait for room
+	Stalls    int64 // times the buffer stayed full past the stall threshold
+}
+
+// FrameBuffer is a bounded queue of 20ms PCM frames between the goroutine
+// reading ffmpeg's output and the encoder. When it is full the reader stops
+// reading, so ffmpeg back-pressures on its pipe instead of racing ahead.
+type FrameBuffer struct {
+	frames  chan []byte
+	done    chan struct{}
+	once    sync.Once
+	metrics MetricSink
+
+	err error // why Fill stopped; set before frames is closed
+
+	taken int // frames returned by Next; only the consumer touches it
+
+	held      int32 // nonzero while the consumer is paused on purpose
+	peak      int64
+	fullWaits int64
+	stalls    int64
+}
+
+// NewFrameBuffer creates a buffer holding up to capacity frames, reporting
+// to metrics (which may be nil). Capacities of 0 or less use the default.
+func NewFrameBuffer(capacity int, metrics MetricSink) *FrameBuffer {
+	if capacity <= 0 {
+		capacity = DefaultFrameBufferSize
+	}
+
+	return &FrameBuffer{
+		frames:  make(chan []byte, capacity),
+		done:    make(chan struct{}),
+		metrics: metrics,
+	}
+}
+
+// Fill reads 20ms frames from reader into the buffer until the reader ends
+// or fails, or the buffer is closed. A final short frame is zero-padded.
+func (fb *FrameBuffer) Fill(reader io.Reader) {
+	defer close(fb.frames)
+
+	for {
+		frame := make([]byte, pcmFrameSize)
+		n, err := io.ReadFull(reader, frame)
+		if err == io.ErrUnexpectedEOF && n > 0 {
+			// Play the tail; the next read reports EOF
+			err = nil
+		}
+		if err != nil {
+			fb.err = err
+			return
+		}
+
+		if !fb.push(frame) {
+			return
+		}
+	}
+}
+
+// push queues a frame, waiting while the buffer is full. It returns false if
+// the buffer was closed first.
+func (fb *FrameBuffer) push(frame []byte) bool {
+	select {
+	case fb.frames <- frame:
+		fb.notePeak()
+		return true
+	case <-fb.done:
+		return false
+	default:
+	}
+
+	atomic.AddInt64(&fb.fullWaits, 1)
+
+	stall := time.NewTimer(frameBufferStallThreshold)
+	defer stall.Stop()
+
+	for {
+		select {
+		case fb.frames <- frame:
+			fb.notePeak()
+			return true
+		case <-fb.done:
+			return false
+		case <-stall.C:
+			if atomic.LoadInt32(&fb.held) == 0 {
+				atomic.AddInt64(&fb.stalls, 1)
+				if fb.metrics != nil {
+					fb.metrics.Counter(pipeline.MetricBufferStalls, 1, nil)
+				}
+			}
+			stall.Reset(frameBufferStallThreshold)
+		}
+	}
+}
+
+// notePeak records the current occupancy if it is the highest yet
+func (fb *FrameBuffer) notePeak() {
+	occupancy := int64(len(fb.frames))
+	for {
+		peak := atomic.LoadInt64(&fb.peak)
+		if occupancy <= peak || atomic.CompareAndSwapInt64(&fb.peak, peak, occupancy) {
+			return
+		}
+	}
+}
+
+// Next returns the next frame, waiting up to timeout for one. It returns
+// io.EOF once the reader has ended and every frame has been taken, the
+// reader's error if it failed, or ErrFrameTimeout.
+func (fb *FrameBuffer) Next(timeout time.Duration) ([]byte, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case frame, ok := <-fb.frames:
+		if !ok {
+			if fb.err == nil {
+				return nil, io.EOF
+			}
+			return nil, fb.err
+		}
+		if fb.taken%frameBufferOccupancySample == 0 && fb.metrics != nil {
+			fb.metrics.Gauge(MetricBufferOccupancy, float64(len(fb.frames)), nil)
+		}
+		fb.taken++
+		return frame, nil
+	case <-timer.C:
+		return nil, ErrFrameTimeout
+	}
+}
+
+// Close stops Fill. Frames already buffered are dropped.
+func (fb *FrameBuffer) Close() {
+	fb.once.Do(func() { close(fb.done) })
+}
+
+// setHeld marks the consumer as deliberately not reading, such as while
+// paused, so a full buffer isn't counted as a stall
+func (fb *FrameBuffer) setHeld(held bool) {
+	var v int32
+	if held {
+		v = 1
+	}
+	atomic.StoreInt32(&fb.held, v)
+}
+
+// Stats returns a snapshot of the buffer's state
+func (fb *FrameBuffer) Stats() FrameBufferStats {
+	return FrameBufferStats{
+		Capacity:  cap(fb.frames),
+		Occupancy: len(fb.frames),
+		Peak:      int(atomic.LoadInt64(&fb.peak)),
+		FullWaits: atomic.LoadInt64(&fb.fullWaits),
+		Stalls:    atomic.LoadInt64(&fb.stalls),
+	}
+}
@@ -0,0 +1,59 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+var (
+	// pauseKeepAlive is how often a silence frame is sent while paused; zero
+	// sends none
+	pauseKeepAlive      time.Duration
+	pauseKeepAliveMutex sync.RWMutex
+)
+
+// SetPauseKeepAlive makes paused pipelines send an Opus silence frame every
+// interval, so Discord keeps the voice connection open and resuming is
+// instant. Zero or less turns it off.
+func SetPauseKeepAlive(interval time.Duration) {
+	if interval < 0 {
+		interval = 0
+	}
+
+	pauseKeepAliveMutex.Lock()
+	pauseKeepAlive = interval
+	pauseKeepAliveMutex.Unlock()
+}
+
+// currentPauseKeepAlive returns the keep-alive interval, zero when off
+func currentPauseKeepAlive() time.Duration {
+	pauseKeepAliveMutex.RLock()
+	defer pauseKeepAliveMutex.RUnlock()
+	return pauseKeepAlive
+}
+
+// sendKeepAlive sends one silence frame. It doesn't count as a delivered
+// frame, so the playback position stays where it was paused.
+func (ap *AudioPipeline) sendKeepAlive() {
+	ap.mu.RLock()
+	vc := ap.voiceConn
+	metrics := ap.metrics
+	ap.mu.RUnlock()
+
+	if vc == nil {
+		return
+	}
+
+	select {
+	case vc.OpusSend <- pipeline.OpusSilenceFrame:
+		if metrics != nil {
+			metrics.Counter("pipeline.keepalive_frames", 1, nil)
+		}
+	case <-time.After(100 * time.Millisecond):
+		if metrics != nil {
+			metrics.Counter("pipeline.keepalive_failures", 1, nil)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLoudnessTarget is the mean volume, in dB, tracks are normalized towards
+	DefaultLoudnessTarget = -16.0
+
+	// MaxGainDB caps the compensation applied in either direction so a bad
+	// measurement can't blow out or mute a track
+	MaxGainDB = 12.0
+
+	// DefaultProbeTimeout bounds a single loudness probe
+	DefaultProbeTimeout = 20 * time.Second
+
+	// probeSeconds is how much of the track the probe analyzes
+	probeSeconds = "30"
+
+	// maxConcurrentProbes limits how many ffmpeg probes run at once
+	maxConcurrentProbes = 2
+)
+
+var meanVolumeRe = regexp.MustCompile(`mean_volume:\s*(-?[0-9.]+) dB`)
+
+// LoudnessProbe measures the mean volume of a stream in dB
+type LoudnessProbe func(ctx context.Context, streamURL string) (float64, error)
+
+// ProbeLoudness measures the mean volume of the first seconds of a stream
+// using ffmpeg's volumedetect filter
+func ProbeLoudness(ctx context.Context, streamURL string) (float64, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg",
+		"-hide_banner",
+		"-nostats",
+		"-t", probeSeconds,
+		"-i", streamURL,
+		"-af", "volumedetect",
+		"-vn",
+		"-f", "null",
+		"-")
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return 0, fmt.Errorf("ffmpeg volumedetect failed: %v", err)
+	}
+
+	return parseMeanVolume(stderr.String())
+}
+
+// parseMeanVolume extracts the mean_volume reported by volumedetect
+func parseMeanVolume(output string) (float64, error) {
+	match := meanVolumeRe.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("mean_volume not found in ffmpeg output")
+	}
+
+	level, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid mean_volume %q: %v", match[1], err)
+	}
+
+	return level, nil
+}
+
+// LoudnessAnalyzer probes tracks for loudness and caches the result by video ID
+type LoudnessAnalyzer struct {
+	probe   LoudnessProbe
+	timeout time.Duration
+	target  float64
+	sem     chan struct{}
+
+	mu    sync.RWMutex
+	cache map[string]float64
+}
+
+// NewLoudnessAnalyzer creates an analyzer using the given probe, or
+// ProbeLoudness when probe is nil
+func NewLoudnessAnalyzer(probe LoudnessProbe) *LoudnessAnalyzer {
+	if probe == nil {
+		probe = ProbeLoudness
+	}
+
+	return &LoudnessAnalyzer{
+		probe:   probe,
+		timeout: DefaultProbeTimeout,
+		target:  DefaultLoudnessTarget,
+		sem:     make(chan struct{}, maxConcurrentProbes),
+		cache:   make(map[string]float64),
+	}
+}
+
+var (
+	// defaultLoudnessAnalyzer is shared by all queues so the cache spans guilds
+	defaultLoudnessAnalyzer      = NewLoudnessAnalyzer(nil)
+	defaultLoudnessAnalyzerMutex sync.RWMutex
+)
+
+// SetDefaultLoudnessAnalyzer sets the analyzer queues created afterwards
+// probe new items with; nil disables normalization for them
+func SetDefaultLoudnessAnalyzer(analyzer *LoudnessAnalyzer) {
+	defaultLoudnessAnalyzerMutex.Lock()
+	defer defaultLoudnessAnalyzerMutex.Unlock()
+	defaultLoudnessAnalyzer = analyzer
+}
+
+// currentLoudnessAnalyzer returns the analyzer new queues start with
+func currentLoudnessAnalyzer() *LoudnessAnalyzer {
+	defaultLoudnessAnalyzerMutex.RLock()
+	defer defaultLoudnessAnalyzerMutex.RUnlock()
+	return defaultLoudnessAnalyzer
+}
+
+// Measure returns the loudness of the item, probing it if it isn't cached.
+// The second value is false when the probe failed.
+func (la *LoudnessAnalyzer) Measure(item *QueueItem) (float64, bool) {
+	if item.VideoID != "" {
+		la.mu.RLock()
+		level, ok := la.cache[item.VideoID]
+		la.mu.RUnlock()
+		if ok {
+			return level, true
+		}
+	}
+
+	la.sem <- struct{}{}
+	defer func() { <-la.sem }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), la.timeout)
+	defer cancel()
+
+	level, err := la.probe(ctx, item.URL)
+	if err != nil {
+		log.Printf("Loudness probe failed for '%s': %v", item.Title, err)
+		return 0, false
+	}
+
+	if item.VideoID != "" {
+		la.mu.Lock()
+		la.cache[item.VideoID] = level
+		la.mu.Unlock()
+	}
+
+	return level, true
+}
+
+// GainFor returns the gain in dB that brings level to the target, clamped to ±MaxGainDB
+func (la *LoudnessAnalyzer) GainFor(level float64) float64 {
+	gain := la.target - level
+	if gain > MaxGainDB {
+		return MaxGainDB
+	}
+	if gain < -MaxGainDB {
+		return -MaxGainDB
+	}
+	return gain
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// oggPageHeaderSize is the fixed part of an Ogg page header, before the
+// segment table
+const oggPageHeaderSize = 27
+
+var (
+	oggCapturePattern = []byte("OggS")
+	opusHeadMagic     = []byte("OpusHead")
+	opusTagsMagic     = []byte("OpusTags")
+)
+
+// OggOpusReader reads Opus packets from an Ogg stream, such as ffmpeg writes
+// with -f ogg. The OpusHead and OpusTags header packets are skipped, so every
+// packet returned is audio. Empty packets are skipped too.
+type OggOpusReader struct {
+	reader io.Reader
+
+	// Lacing values of the current page not yet read
+	segments []byte
+}
+
+// NewOggOpusReader creates a reader for the Ogg stream in r
+func NewOggOpusReader(r io.Reader) *OggOpusReader {
+	return &OggOpusReader{reader: r}
+}
+
+// ReadPacket returns the next Opus audio packet. It returns io.EOF when the
+// stream ends between packets and io.ErrUnexpectedEOF when it ends inside
+// one.
+func (o *OggOpusReader) ReadPacket() ([]byte, error) {
+	for {
+		packet, err := o.readRawPacket()
+		if err != nil {
+			return nil, err
+		}
+		if len(packet) == 0 || bytes.HasPrefix(packet, opusHeadMagic) || bytes.HasPrefix(packet, opusTagsMagic) {
+			continue
+		}
+		return packet, nil
+	}
+}
+
+// readRawPacket returns the next packet in the stream, headers included. A
+// packet ends at the first lacing value under 255 and may span pages.
+func (o *OggOpusReader) readRawPacket() ([]byte, error) {
+	var packet []byte
+	for {
+		for len(o.segments) > 0 {
+			size := int(o.segments[0])
+			o.segments = o.segments[1:]
+
+			segment := make([]byte, size)
+			if _, err := io.ReadFull(o.reader, segment); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			packet = append(packet, segment...)
+			if size < 255 {
+				return packet, nil
+			}
+		}
+
+		if err := o.readPageHeader(); err != nil {
+			if err == io.EOF && len(packet) > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
+
+// readPageHeader reads the next page header and loads its segment table
+func (o *OggOpusReader) readPageHeader() error {
+	header := make([]byte, oggPageHeaderSize)
+	if _, err := io.ReadFull(o.reader, header); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return unexpectedEOF(err)
+	}
+	if !bytes.Equal(header[:4], oggCapturePattern) {
+		return fmt.Errorf("invalid ogg page: capture pattern %q", header[:4])
+	}
+	if header[4] != 0 {
+		return fmt.Errorf("unsupported ogg version %d", header[4])
+	}
+
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(o.reader, segments); err != nil {
+		return unexpectedEOF(err)
+	}
+	o.segments = segments
+	return nil
+}
+
+// unexpectedEOF reports a stream that ended partway through a page
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
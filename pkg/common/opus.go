@@ -0,0 +1,111 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"layeh.com/gopus"
+)
+
+var (
+	// opusApplication is the mode new encoders are created in
+	opusApplication      = gopus.Audio
+	opusApplicationMutex sync.RWMutex
+)
+
+// ParseOpusApplication maps an application mode name (voip, audio or
+// lowdelay) to the encoder setting
+func ParseOpusApplication(mode string) (gopus.Application, error) {
+	switch strings.ToLower(mode) {
+	case "voip":
+		return gopus.Voip, nil
+	case "audio", "":
+		return gopus.Audio, nil
+	case "lowdelay":
+		return gopus.RestrictedLowDelay, nil
+	default:
+		return 0, fmt.Errorf("unknown opus application %q (want voip, audio or lowdelay)", mode)
+	}
+}
+
+// SetOpusApplication sets the application mode for encoders created by
+// later PlayStream calls. Empty restores the default, audio.
+func SetOpusApplication(mode string) error {
+	application, err := ParseOpusApplication(mode)
+	if err != nil {
+		return err
+	}
+
+	opusApplicationMutex.Lock()
+	opusApplication = application
+	opusApplicationMutex.Unlock()
+	return nil
+}
+
+// currentOpusApplication returns the mode new encoders are created in
+func currentOpusApplication() gopus.Application {
+	opusApplicationMutex.RLock()
+	defer opusApplicationMutex.RUnlock()
+	return opusApplication
+}
+
+// OpusOptions selects how pipelines produce opus. Bitrate is what they
+// encode at, 128kbps when zero. With Passthrough set, ffmpeg encodes with
+// libopus and pipelines forward its packets as they are, so no encoder of
+// their own is created; FrameDuration sets libopus's frame duration, 20ms
+// when zero.
+//
+// With AdaptiveBitrate set, the pipeline's own encoder steps its bitrate
+// down toward MinBitrate while playback keeps underrunning or stalling, and
+// back up (to at most MaxBitrate) once stable. It has no effect in
+// passthrough, where ffmpeg's bitrate is fixed when it starts.
+//
+// FEC (sized for PacketLoss percent loss) and DTX are passed to libopus in
+// passthrough. The built-in encoder can't set them, so they have no effect
+// without it.
+type OpusOptions struct {
+	Bitrate       int
+	FrameDuration time.Duration
+	Passthrough   bool
+
+	AdaptiveBitrate bool
+	MinBitrate      int
+	MaxBitrate      int
+
+	FEC        bool
+	PacketLoss int
+	DTX        bool
+}
+
+var (
+	opusOptions      OpusOptions
+	opusOptionsMutex sync.RWMutex
+)
+
+// SetOpusOptions sets the options for later PlayStream calls
+func SetOpusOptions(options OpusOptions) {
+	opusOptionsMutex.Lock()
+	opusOptions = options
+	opusOptionsMutex.Unlock()
+}
+
+// currentOpusOptions returns the options new pipelines play with
+func currentOpusOptions() OpusOptions {
+	opusOptionsMutex.RLock()
+	defer opusOptionsMutex.RUnlock()
+	return opusOptions
+}
+
+// opusApplicationName returns the libopus name of an application mode
+func opusApplicationName(application gopus.Application) string {
+	switch application {
+	case gopus.Voip:
+		return "voip"
+	case gopus.RestrictedLowDelay:
+		return "lowdelay"
+	default:
+		return "audio"
+	}
+}
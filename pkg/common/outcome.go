@@ -0,0 +1,171 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// ErrVoiceUnavailable is returned when the voice connection never becomes
+// ready, so no track could be played on it
+var ErrVoiceUnavailable = errors.New("voice connection unavailable")
+
+// OutcomeReason explains why a track stopped playing
+type OutcomeReason string
+
+const (
+	OutcomeCompleted   OutcomeReason = "completed"    // stream reached its end
+	OutcomeUserStopped OutcomeReason = "user_stopped" // stopped or skipped by a command
+	OutcomeIdleTimeout OutcomeReason = "idle_timeout" // stopped by the idle monitor
+	OutcomeError       OutcomeReason = "error"        // failed and could not be recovered
+	OutcomeDrained     OutcomeReason = "drained"      // last track finished with nothing left queued
+)
+
+// Outcome describes how playback of a track ended
+type Outcome struct {
+	Reason     OutcomeReason
+	Err        error         // Terminal error, set when Reason is OutcomeError
+	Elapsed    time.Duration // Time spent playing, not counting pauses
+	Recoveries int           // Stream restarts attempted before the end
+	EndedAt    time.Time
+}
+
+// IsZero reports whether no terminal transition has been recorded yet
+func (o Outcome) IsZero() bool {
+	return o.Reason == ""
+}
+
+// String returns a short human-readable summary of the outcome
+func (o Outcome) String() string {
+	if o.IsZero() {
+		return "still playing"
+	}
+	if o.Err != nil {
+		return fmt.Sprintf("%s after %s: %v", o.Reason, o.Elapsed.Round(time.Second), o.Err)
+	}
+	return fmt.Sprintf("%s after %s", o.Reason, o.Elapsed.Round(time.Second))
+}
+
+// SessionFailure reports whether the track failed for a reason that would
+// fail every following track too, such as a missing ffmpeg binary or a dead
+// voice connection. Other errors are specific to the track's source.
+func (o Outcome) SessionFailure() bool {
+	return o.Reason == OutcomeError && IsSessionError(o.Err)
+}
+
+// IsSessionError reports whether err affects the whole playback session
+// rather than a single track
+func IsSessionError(err error) bool {
+	return errors.Is(err, pipeline.ErrFFmpegNotFound) || errors.Is(err, ErrVoiceUnavailable)
+}
+
+// Streamer plays a stream to completion, returning nil at its natural end.
+// The default streams through ffmpeg to the voice connection; tests and
+// alternative sources can replace it with SetStreamer.
+type Streamer func(ctx context.Context, streamURL string) error
+
+// SetStreamer replaces how the pipeline plays a stream. Call it before
+// PlayStream; nil restores the default ffmpeg streamer.
+func (ap *AudioPipeline) SetStreamer(streamer Streamer) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.streamer = streamer
+}
+
+// LastOutcome returns how the track ended. It is zero until the pipeline
+// reaches a terminal state.
+func (ap *AudioPipeline) LastOutcome() Outcome {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	return ap.outcome
+}
+
+// finish records the terminal outcome. Only the first call wins, so a Stop
+// that cancels the stream is not later reported as a normal completion.
+func (ap *AudioPipeline) finish(reason OutcomeReason, err error) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.finishLocked(reason, err)
+}
+
+// finishLocked is finish for callers already holding ap.mu
+func (ap *AudioPipeline) finishLocked(reason OutcomeReason, err error) {
+	if !ap.outcome.IsZero() {
+		return
+	}
+
+	now := time.Now()
+	ap.outcome = Outcome{
+		Reason:     reason,
+		Err:        err,
+		Elapsed:    ap.elapsedLocked(now),
+		Recoveries: ap.restartCount,
+		EndedAt:    now,
+	}
+}
+
+// LastOutcome returns how the most recent track ended, or a drained outcome
+// once the queue has run out of items
+func (mq *MusicQueue) LastOutcome() Outcome {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	if mq.pipeline != nil {
+		if outcome := mq.pipeline.LastOutcome(); !outcome.IsZero() {
+			return outcome
+		}
+	}
+	return mq.lastOutcome
+}
+
+// MarkDrained records that playback ended because the queue ran out. The
+// elapsed time and recoveries of the final track are carried over.
+func (mq *MusicQueue) MarkDrained() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	outcome := mq.lastOutcome
+	if mq.pipeline != nil {
+		if last := mq.pipeline.LastOutcome(); !last.IsZero() {
+			outcome = last
+		}
+	}
+
+	outcome.Reason = OutcomeDrained
+	outcome.Err = nil
+	if outcome.EndedAt.IsZero() {
+		outcome.EndedAt = time.Now()
+	}
+	mq.lastOutcome = outcome
+}
+
+// rememberOutcomeLocked keeps the outcome of a pipeline that is being dropped
+// from the queue. Callers must hold mq.mu.
+func (mq *MusicQueue) rememberOutcomeLocked() {
+	if mq.pipeline == nil {
+		return
+	}
+	if outcome := mq.pipeline.LastOutcome(); !outcome.IsZero() {
+		mq.lastOutcome = outcome
+	}
+}
+
+// RecordTrackFailure counts a track that ended in an error and returns how
+// many tracks in a row have now failed
+func (mq *MusicQueue) RecordTrackFailure() int {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.failures++
+	return mq.failures
+}
+
+// ResetTrackFailures clears the consecutive failure count, after a track
+// plays through or the session ends
+func (mq *MusicQueue) ResetTrackFailures() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.failures = 0
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// EventSink receives discrete pipeline events, such as stream errors and
+// the restarts that recover from them. Severity is one of low, medium, high
+// or critical. pipeline.EventRecorder satisfies it.
+type EventSink interface {
+	RecordEvent(eventType, severity string, data map[string]interface{})
+}
+
+// SetEventSink sets where the pipeline's error and recovery events are
+// reported. Nil discards them. Call it before PlayStream.
+func (ap *AudioPipeline) SetEventSink(events EventSink) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.events = events
+}
+
+// recordEvent reports an event to the sink, if any
+func (ap *AudioPipeline) recordEvent(eventType, severity string, data map[string]interface{}) {
+	ap.mu.RLock()
+	events := ap.events
+	ap.mu.RUnlock()
+
+	if events != nil {
+		events.RecordEvent(eventType, severity, data)
+	}
+}
+
+// recordStreamError reports a stream error, as medium severity when the
+// pipeline will restart and high when it ends playback
+func (ap *AudioPipeline) recordStreamError(err error, restarting bool) {
+	severity := "high"
+	if restarting {
+		severity = "medium"
+	}
+	ap.recordEvent("error", severity, map[string]interface{}{
+		"error":      err.Error(),
+		"error_type": streamErrorType(err),
+	})
+}
+
+// recordRecovery reports a restart attempt after a stream error, counting
+// it under the recovery backoff policy
+func (ap *AudioPipeline) recordRecovery(attempt, maxAttempts int, strategy string, category pipeline.ErrorCategory) {
+	ap.recordEvent("recovery", "low", map[string]interface{}{
+		"message":  fmt.Sprintf("restarting stream (attempt %d/%d)", attempt, maxAttempts),
+		"attempt":  attempt,
+		"strategy": strategy,
+	})
+
+	ap.mu.RLock()
+	metrics := ap.metrics
+	ap.mu.RUnlock()
+	if metrics != nil {
+		metrics.Counter(pipeline.MetricRecoveryAttempt, 1, map[string]string{
+			"strategy": strategy,
+			"category": category.String(),
+		})
+	}
+}
+
+// streamErrorCategory returns the pipeline error category of a stream error
+// that restarts the stream
+func streamErrorCategory(err error) pipeline.ErrorCategory {
+	switch {
+	case err == nil:
+		return pipeline.CategoryUnknown
+	case errors.Is(err, ErrVoiceUnavailable), contains(err.Error(), "voice connection health check failed"):
+		return pipeline.CategoryVoice
+	case isVoiceClose(err):
+		return pipeline.CategoryVoice
+	default:
+		return pipeline.CategoryStream
+	}
+}
+
+// streamErrorType classifies a stream error for grouping in reports
+func streamErrorType(err error) string {
+	switch {
+	case errors.Is(err, ErrFrameTimeout):
+		return "frame_timeout"
+	case errors.Is(err, pipeline.ErrFFmpegNotFound):
+		return "ffmpeg_not_found"
+	case errors.Is(err, ErrVoiceUnavailable):
+		return "voice_unavailable"
+	case isVoiceClose(err):
+		return "voice_closed"
+	case errors.Is(err, errMaxRestarts):
+		return "max_restarts"
+	case errors.Is(err, errRecoveryBudgetExhausted):
+		return "recovery_budget_exhausted"
+	default:
+		return "stream_error"
+	}
+}
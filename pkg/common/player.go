@@ -0,0 +1,109 @@
+package common
+
+import "time"
+
+// PlayerState summarizes what a guild's player is doing
+type PlayerState string
+
+const (
+	PlayerIdle     PlayerState = "idle"     // Nothing is playing
+	PlayerStarting PlayerState = "starting" // A track is current but its pipeline isn't playing yet
+	PlayerPlaying  PlayerState = "playing"
+	PlayerPaused   PlayerState = "paused"
+)
+
+// LoopMode is how the player repeats tracks. The queue doesn't loop yet, so
+// it is always LoopOff; the field is there so consumers needn't change once
+// it does.
+type LoopMode string
+
+const LoopOff LoopMode = "off"
+
+// PlayerTrack is one track in a Player snapshot
+type PlayerTrack struct {
+	Title       string        `json:"title"`
+	URL         string        `json:"url"` // Original URL when known, otherwise the stream URL
+	RequestedBy string        `json:"requested_by"`
+	AddedAt     time.Time     `json:"added_at"`
+	Duration    time.Duration `json:"duration"` // 0 when unknown
+	Elapsed     time.Duration `json:"elapsed"`  // Only set for the current track
+}
+
+// Player is a read-only, JSON-serializable snapshot of a guild's player
+type Player struct {
+	GuildID  string        `json:"guild_id"`
+	State    PlayerState   `json:"state"`
+	Current  *PlayerTrack  `json:"current,omitempty"`
+	Pending  []PlayerTrack `json:"pending"`
+	LoopMode LoopMode      `json:"loop_mode"`
+	VolumeDB float64       `json:"volume_db"` // Loudness gain applied to the current track
+
+	Remaining            time.Duration `json:"remaining"`
+	RemainingApproximate bool          `json:"remaining_approximate"`
+
+	TakenAt time.Time `json:"taken_at"`
+}
+
+// newPlayerTrack converts a queue item for a snapshot
+func newPlayerTrack(item *QueueItem) PlayerTrack {
+	url := item.OriginalURL
+	if url == "" {
+		url = item.URL
+	}
+	return PlayerTrack{
+		Title:       item.Title,
+		URL:         url,
+		RequestedBy: item.RequestedBy,
+		AddedAt:     item.AddedAt,
+		Duration:    item.Duration,
+	}
+}
+
+// Snapshot returns the player's current state. The queue is copied under
+// its lock and the pipeline is read after it is released, so a pipeline call
+// never holds up the queue.
+func (mq *MusicQueue) Snapshot() *Player {
+	mq.mu.RLock()
+	guildID := mq.guildID
+	current := mq.current
+	pipeline := mq.pipeline
+	starting := mq.isPlaying
+	items := make([]*QueueItem, len(mq.items))
+	copy(items, mq.items)
+	mq.mu.RUnlock()
+
+	player := &Player{
+		GuildID:  guildID,
+		State:    PlayerIdle,
+		Pending:  make([]PlayerTrack, 0, len(items)),
+		LoopMode: LoopOff,
+		TakenAt:  time.Now(),
+	}
+
+	var elapsed time.Duration
+	if current != nil {
+		track := newPlayerTrack(current)
+		player.VolumeDB = current.GainDB
+
+		if pipeline != nil && pipeline.IsPlaying() {
+			elapsed = pipeline.Position()
+			track.Elapsed = elapsed
+			player.State = PlayerPlaying
+			if pipeline.IsPaused() {
+				player.State = PlayerPaused
+			}
+		} else if starting {
+			player.State = PlayerStarting
+		}
+		player.Current = &track
+	}
+
+	pending := make([]time.Duration, len(items))
+	for i, item := range items {
+		player.Pending = append(player.Pending, newPlayerTrack(item))
+		pending[i] = item.Duration
+	}
+	player.Remaining, player.RemainingApproximate = estimateRemaining(current, elapsed, pending)
+
+	return player
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	"time"
+)
+
+// PositionUpdates returns a channel receiving the playback position every
+// Discord.PositionUpdateInterval of the stream config while the pipeline is
+// playing, for progress bars. Nothing is sent while paused. It is buffered to
+// one update, which a newer one replaces if unread, and is closed once the
+// stream ends, so readers can range over it.
+func (ap *AudioPipeline) PositionUpdates() <-chan time.Duration {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	if ap.positionUpdates == nil {
+		ap.positionUpdates = make(chan time.Duration, 1)
+		if ap.positionsClosed {
+			close(ap.positionUpdates)
+		}
+	}
+	return ap.positionUpdates
+}
+
+// startPositionUpdatesLocked publishes the position on the configured
+// interval until the pipeline stops playing. A track played again after the
+// last one ended gets a new channel. Callers hold ap.mu.
+func (ap *AudioPipeline) startPositionUpdatesLocked() {
+	if ap.positionsClosed {
+		ap.positionUpdates = nil
+		ap.positionsClosed = false
+	}
+
+	ticker := time.NewTicker(positionUpdateInterval())
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ap.ctx.Done():
+				return
+			case <-ticker.C:
+				if !ap.publishPosition() {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// closePositionUpdatesLocked closes the PositionUpdates channel once the
+// stream loop exits. Callers hold ap.mu.
+func (ap *AudioPipeline) closePositionUpdatesLocked() {
+	if ap.positionsClosed {
+		return
+	}
+	ap.positionsClosed = true
+	if ap.positionUpdates != nil {
+		close(ap.positionUpdates)
+	}
+}
+
+// publishPosition sends the position to the PositionUpdates channel,
+// replacing an update the reader hasn't taken yet so it never sees a stale
+// position. It returns false once the pipeline has stopped playing. The
+// send happens under ap.mu so it can't race the channel being closed.
+func (ap *AudioPipeline) publishPosition() bool {
+	position := ap.Position()
+
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+
+	if !ap.isPlaying || ap.positionsClosed {
+		return false
+	}
+	if ap.paused || ap.positionUpdates == nil {
+		return true
+	}
+
+	select {
+	case <-ap.positionUpdates:
+	default:
+	}
+	select {
+	case ap.positionUpdates <- position:
+	default:
+	}
+	return true
+}
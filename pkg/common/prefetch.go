@@ -0,0 +1,131 @@
+package common
+
+import (
+	"context"
+	"log"
+)
+
+// prefetchState tracks the background warm-up of the item at the head of the queue
+type prefetchState struct {
+	item      *QueueItem
+	cancel    context.CancelFunc
+	streamURL string
+	ready     bool
+}
+
+// SetPrefetchResolver enables preloading: while something plays, the next
+// item's stream URL is re-resolved with resolve (and its loudness probed if
+// it hasn't been yet) so Next can hand out an already-warm source. Nil
+// disables preloading.
+func (mq *MusicQueue) SetPrefetchResolver(resolve StreamResolver) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	mq.resolver = resolve
+	if resolve == nil {
+		mq.cancelPrefetch()
+	}
+}
+
+// PrefetchedURL returns the preloaded stream URL for item, if its prefetch
+// has completed
+func (mq *MusicQueue) PrefetchedURL(item *QueueItem) (string, bool) {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	if mq.prefetch == nil || mq.prefetch.item != item || !mq.prefetch.ready {
+		return "", false
+	}
+	return mq.prefetch.streamURL, true
+}
+
+// refreshPrefetch keeps the prefetch pointed at the head of the queue,
+// cancelling a prefetch whose item is no longer next. It is called after
+// every queue change.
+func (mq *MusicQueue) refreshPrefetch() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	var head *QueueItem
+	if len(mq.items) > 0 {
+		head = mq.items[0]
+	}
+
+	if mq.prefetch != nil && mq.prefetch.item == head {
+		return
+	}
+	mq.cancelPrefetch()
+
+	if head == nil || mq.resolver == nil || !mq.isPlaying {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state := &prefetchState{item: head, cancel: cancel}
+	mq.prefetch = state
+	go mq.runPrefetch(ctx, state, mq.resolver, mq.loudness)
+}
+
+// runPrefetch resolves and probes the item. Resolvers can't be interrupted,
+// so a cancelled prefetch just discards its result.
+func (mq *MusicQueue) runPrefetch(ctx context.Context, state *prefetchState, resolve StreamResolver, analyzer *LoudnessAnalyzer) {
+	streamURL, err := resolve(state.item)
+	if err != nil {
+		log.Printf("Failed to preload '%s': %v", state.item.Title, err)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	mq.mu.Lock()
+	if mq.prefetch != state {
+		mq.mu.Unlock()
+		return
+	}
+	state.streamURL = streamURL
+	state.ready = true
+	measured := state.item.LoudnessMeasured
+	probe := *state.item
+	mq.mu.Unlock()
+
+	log.Printf("Preloaded '%s' for guild %s", probe.Title, mq.guildID)
+
+	if analyzer == nil || measured {
+		return
+	}
+
+	probe.URL = streamURL
+	level, ok := analyzer.Measure(&probe)
+	if !ok || ctx.Err() != nil {
+		return
+	}
+
+	mq.mu.Lock()
+	state.item.Loudness = level
+	state.item.GainDB = analyzer.GainFor(level)
+	state.item.LoudnessMeasured = true
+	mq.mu.Unlock()
+}
+
+// takePrefetch swaps in the preloaded stream URL if item was prefetched and
+// clears the prefetch. Must be called with the lock held.
+func (mq *MusicQueue) takePrefetch(item *QueueItem) {
+	if mq.prefetch == nil || mq.prefetch.item != item {
+		return
+	}
+
+	if mq.prefetch.ready {
+		item.URL = mq.prefetch.streamURL
+	}
+	mq.cancelPrefetch()
+}
+
+// cancelPrefetch stops any in-flight prefetch. Must be called with the lock held.
+func (mq *MusicQueue) cancelPrefetch() {
+	if mq.prefetch == nil {
+		return
+	}
+	mq.prefetch.cancel()
+	mq.prefetch = nil
+}
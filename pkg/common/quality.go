@@ -0,0 +1,46 @@
+package common
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// PlaybackQuality summarizes how smoothly a pipeline delivered audio, for
+// scoring its session when it ends
+type PlaybackQuality struct {
+	Underruns           int64         // frames that weren't ready when the sender asked for them
+	Reconnects          int64         // times the voice connection was replaced mid-track
+	AvgFrameSendLatency time.Duration // mean time the voice connection took to accept a frame
+}
+
+// PlaybackQuality returns the pipeline's delivery counters so far
+func (ap *AudioPipeline) PlaybackQuality() PlaybackQuality {
+	quality := PlaybackQuality{
+		Underruns:  atomic.LoadInt64(&ap.underruns),
+		Reconnects: atomic.LoadInt64(&ap.reconnects),
+	}
+	if samples := atomic.LoadInt64(&ap.sendSamples); samples > 0 {
+		quality.AvgFrameSendLatency = time.Duration(atomic.LoadInt64(&ap.sendLatency) / samples)
+	}
+	return quality
+}
+
+// noteUnderrun counts a frame the sender had to wait for
+func (ap *AudioPipeline) noteUnderrun() {
+	atomic.AddInt64(&ap.underruns, 1)
+
+	ap.mu.RLock()
+	metrics := ap.metrics
+	ap.mu.RUnlock()
+	if metrics != nil {
+		metrics.Counter(pipeline.MetricUnderruns, 1, nil)
+	}
+}
+
+// noteSendLatency adds the time the voice connection took to accept a frame
+func (ap *AudioPipeline) noteSendLatency(latency time.Duration) {
+	atomic.AddInt64(&ap.sendLatency, int64(latency))
+	atomic.AddInt64(&ap.sendSamples, 1)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
 )
 
 // QueueItem represents a single item in the music queue
@@ -19,30 +20,77 @@ type QueueItem struct {
 	AddedAt     time.Time
 	StartedAt   time.Time
 	Duration    time.Duration
+
+	// Loudness normalization, filled in asynchronously after enqueue
+	Loudness         float64 // Measured mean volume in dB
+	GainDB           float64 // Compensation applied at playback
+	LoudnessMeasured bool
+
+	// Chapter markers from the video's metadata; empty when it has none
+	Chapters []Chapter
 }
 
+// DefaultMaxHistory is the number of played items kept per session
+const DefaultMaxHistory = 100
+
+// QueueObserver is notified after a queue's contents or pipeline change.
+// It is called without the queue lock held.
+type QueueObserver func(mq *MusicQueue)
+
+// StreamResolver returns a fresh stream URL for a previously played item
+type StreamResolver func(item *QueueItem) (string, error)
+
 // MusicQueue manages the queue for a specific guild
 type MusicQueue struct {
 	guildID    string
 	items      []*QueueItem
 	current    *QueueItem
+	history    []*QueueItem // Items played this session, oldest first
+	maxHistory int
 	isPlaying  bool
 	wasSkipped bool // Flag to track if current song was skipped
 	mu         sync.RWMutex
 	voiceConn  *discordgo.VoiceConnection
 	pipeline   *AudioPipeline
+	loudness   *LoudnessAnalyzer
+	observer   QueueObserver
+	resolver   StreamResolver // Preloads the next item; nil disables
+	prefetch   *prefetchState
+
+	lastOutcome Outcome // Outcome of the most recently dropped pipeline
+	failures    int     // Consecutive tracks that ended in an error
+
+	failedTracks    []*QueueItem // Tracks skipped after failing, oldest first
+	maxFailedTracks int
+
+	trackEvents *TrackEventBus // Where playback events are published; nil disables
+
+	encoder *SessionEncoder // Opus encoder shared by the session's pipelines
+
+	recoveries *pipeline.RecoveryBudget // Restart budget shared by the session's pipelines
 }
 
 // NewMusicQueue creates a new music queue for a guild
 func NewMusicQueue(guildID string) *MusicQueue {
 	return &MusicQueue{
-		guildID: guildID,
-		items:   make([]*QueueItem, 0),
+		guildID:    guildID,
+		items:      make([]*QueueItem, 0),
+		maxHistory: DefaultMaxHistory,
+		loudness:   currentLoudnessAnalyzer(),
+
+		maxFailedTracks: DefaultMaxFailedTracks,
+
+		trackEvents: DefaultTrackEvents,
+
+		encoder: NewSessionEncoder(),
+
+		recoveries: pipeline.NewRecoveryBudget(time.Now),
 	}
 }
 
 // Add adds a new item to the queue
 func (mq *MusicQueue) Add(url, title, requestedBy string) {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
 
@@ -54,11 +102,14 @@ func (mq *MusicQueue) Add(url, title, requestedBy string) {
 	}
 
 	mq.items = append(mq.items, item)
+	mq.probeLoudness(item)
 	log.Printf("Added '%s' to queue for guild %s", title, mq.guildID)
 }
 
-// AddWithYouTubeData adds a new item to the queue with YouTube-specific data
-func (mq *MusicQueue) AddWithYouTubeData(url, originalURL, videoID, title, requestedBy string, duration time.Duration) {
+// AddWithYouTubeData adds a new item to the queue with YouTube-specific data,
+// including the video's chapters if it has any
+func (mq *MusicQueue) AddWithYouTubeData(url, originalURL, videoID, title, requestedBy string, duration time.Duration, chapters ...Chapter) {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
 
@@ -70,14 +121,86 @@ func (mq *MusicQueue) AddWithYouTubeData(url, originalURL, videoID, title, reque
 		RequestedBy: requestedBy,
 		AddedAt:     time.Now(),
 		Duration:    duration,
+		Chapters:    chapters,
 	}
 
 	mq.items = append(mq.items, item)
+	mq.probeLoudness(item)
 	log.Printf("Added '%s' (Duration: %v) to queue for guild %s", title, duration, mq.guildID)
 }
 
+// SetLoudnessAnalyzer sets the analyzer used to probe new items; nil disables normalization
+func (mq *MusicQueue) SetLoudnessAnalyzer(analyzer *LoudnessAnalyzer) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.loudness = analyzer
+}
+
+// SetObserver sets the function notified when items are added, removed, or
+// advanced, or when the pipeline changes
+func (mq *MusicQueue) SetObserver(observer QueueObserver) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.observer = observer
+}
+
+// notify re-targets the prefetch and calls the observer, if any. Must be
+// called without the lock held.
+func (mq *MusicQueue) notify() {
+	mq.refreshPrefetch()
+
+	mq.mu.RLock()
+	observer := mq.observer
+	mq.mu.RUnlock()
+
+	if observer != nil {
+		observer(mq)
+	}
+}
+
+// GuildID returns the guild this queue belongs to
+func (mq *MusicQueue) GuildID() string {
+	return mq.guildID
+}
+
+// probeLoudness measures the item in the background and records the gain to
+// apply. The probe works on a copy so the item can change, for example when
+// a prefetch swaps its URL, while it runs. Must be called with the lock held.
+func (mq *MusicQueue) probeLoudness(item *QueueItem) {
+	analyzer := mq.loudness
+	if analyzer == nil {
+		return
+	}
+
+	probe := *item
+	go func() {
+		level, ok := analyzer.Measure(&probe)
+		if !ok {
+			return // Play without compensation
+		}
+
+		mq.mu.Lock()
+		item.Loudness = level
+		item.GainDB = analyzer.GainFor(level)
+		item.LoudnessMeasured = true
+		mq.mu.Unlock()
+	}()
+}
+
+// TrackGain returns the gain compensation for an item, or 0 if it hasn't been measured
+func (mq *MusicQueue) TrackGain(item *QueueItem) float64 {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	if !item.LoudnessMeasured {
+		return 0
+	}
+	return item.GainDB
+}
+
 // Next gets the next item from the queue
 func (mq *MusicQueue) Next() *QueueItem {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
 
@@ -88,9 +211,126 @@ func (mq *MusicQueue) Next() *QueueItem {
 	item := mq.items[0]
 	mq.items = mq.items[1:]
 	mq.current = item
+	mq.takePrefetch(item)
+	mq.addToHistory(item)
 	return item
 }
 
+// addToHistory records a played item, dropping the oldest beyond the cap.
+// Must be called with the lock held.
+func (mq *MusicQueue) addToHistory(item *QueueItem) {
+	mq.history = append(mq.history, item)
+	if len(mq.history) > mq.maxHistory {
+		mq.history = mq.history[len(mq.history)-mq.maxHistory:]
+	}
+}
+
+// History returns the items played this session, oldest first
+func (mq *MusicQueue) History() []*QueueItem {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	result := make([]*QueueItem, len(mq.history))
+	copy(result, mq.history)
+	return result
+}
+
+// ClearHistory forgets the session history
+func (mq *MusicQueue) ClearHistory() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.history = nil
+}
+
+// FinishedHistory returns the played items that have finished, oldest
+// first: the history without the current track while it is still playing
+func (mq *MusicQueue) FinishedHistory() []*QueueItem {
+	mq.mu.RLock()
+	defer mq.mu.RUnlock()
+
+	finished := mq.history
+	if n := len(finished); n > 0 && mq.isPlaying && finished[n-1] == mq.current {
+		finished = finished[:n-1]
+	}
+
+	result := make([]*QueueItem, len(finished))
+	copy(result, finished)
+	return result
+}
+
+// ReplayHistory re-enqueues up to limit items from the session history in
+// their original order. Stream URLs expire, so each item is re-resolved with
+// resolve; items that fail to resolve are skipped. It returns the number of
+// items requeued and the number that failed.
+func (mq *MusicQueue) ReplayHistory(limit int, resolve StreamResolver) (int, int) {
+	history := mq.History()
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return mq.requeueHistory(history, resolve)
+}
+
+// ReplayLast re-enqueues the last n finished items in their original order,
+// re-resolving them like ReplayHistory. n must be between 1 and the number
+// of finished items.
+func (mq *MusicQueue) ReplayLast(n int, resolve StreamResolver) (int, int, error) {
+	finished := mq.FinishedHistory()
+	if n < 1 || n > len(finished) {
+		return 0, 0, fmt.Errorf("invalid replay count: %d (%d finished tracks)", n, len(finished))
+	}
+
+	requeued, failed := mq.requeueHistory(finished[len(finished)-n:], resolve)
+	return requeued, failed, nil
+}
+
+// requeueHistory re-resolves history items and appends those that resolved
+// to the queue, returning how many were requeued and how many failed
+func (mq *MusicQueue) requeueHistory(history []*QueueItem, resolve StreamResolver) (int, int) {
+	replay, unresolved := mq.resolveForRequeue(history, resolve, "replay")
+	failed := len(unresolved)
+
+	mq.mu.Lock()
+	mq.items = append(mq.items, replay...)
+	mq.mu.Unlock()
+	mq.notify()
+
+	log.Printf("Requeued %d items from history for guild %s (%d failed)", len(replay), mq.guildID, failed)
+	return len(replay), failed
+}
+
+// resolveForRequeue re-resolves each item's stream URL with resolve and
+// returns fresh copies of the items that resolved, and the items that
+// didn't. It resolves outside the lock; this may shell out to yt-dlp.
+func (mq *MusicQueue) resolveForRequeue(items []*QueueItem, resolve StreamResolver, purpose string) ([]*QueueItem, []*QueueItem) {
+	resolved := make([]*QueueItem, 0, len(items))
+	var unresolved []*QueueItem
+	for _, item := range items {
+		streamURL, err := resolve(item)
+		if err != nil {
+			log.Printf("Failed to re-resolve '%s' for %s: %v", item.Title, purpose, err)
+			unresolved = append(unresolved, item)
+			continue
+		}
+
+		mq.mu.RLock()
+		resolved = append(resolved, &QueueItem{
+			URL:              streamURL,
+			OriginalURL:      item.OriginalURL,
+			VideoID:          item.VideoID,
+			Title:            item.Title,
+			RequestedBy:      item.RequestedBy,
+			AddedAt:          time.Now(),
+			Duration:         item.Duration,
+			Loudness:         item.Loudness,
+			GainDB:           item.GainDB,
+			LoudnessMeasured: item.LoudnessMeasured,
+			Chapters:         item.Chapters,
+		})
+		mq.mu.RUnlock()
+	}
+	return resolved, unresolved
+}
+
 // Current returns the currently playing item
 func (mq *MusicQueue) Current() *QueueItem {
 	mq.mu.RLock()
@@ -108,6 +348,55 @@ func (mq *MusicQueue) List() []*QueueItem {
 	return result
 }
 
+// RemainingDuration estimates how long the queue will take to play out: the
+// rest of the current track plus every pending track. Tracks of unknown
+// length are left out; see RemainingEstimate.
+func (mq *MusicQueue) RemainingDuration() time.Duration {
+	remaining, _ := mq.RemainingEstimate()
+	return remaining
+}
+
+// RemainingEstimate returns RemainingDuration along with whether it is
+// approximate because some tracks have an unknown length
+func (mq *MusicQueue) RemainingEstimate() (remaining time.Duration, approximate bool) {
+	mq.mu.RLock()
+	current := mq.current
+	pipeline := mq.pipeline
+	pending := make([]time.Duration, len(mq.items))
+	for i, item := range mq.items {
+		pending[i] = item.Duration
+	}
+	mq.mu.RUnlock()
+
+	var elapsed time.Duration
+	if pipeline != nil {
+		elapsed = pipeline.Position()
+	}
+	return estimateRemaining(current, elapsed, pending)
+}
+
+// estimateRemaining adds what is left of the current track, elapsed into
+// it, to the pending tracks' durations, reporting whether any is unknown
+func estimateRemaining(current *QueueItem, elapsed time.Duration, pending []time.Duration) (remaining time.Duration, approximate bool) {
+	if current != nil {
+		if current.Duration <= 0 {
+			approximate = true
+		} else if left := current.Duration - elapsed; left > 0 {
+			remaining += left
+		}
+	}
+
+	for _, duration := range pending {
+		if duration <= 0 {
+			approximate = true
+			continue
+		}
+		remaining += duration
+	}
+
+	return remaining, approximate
+}
+
 // Size returns the number of items in the queue
 func (mq *MusicQueue) Size() int {
 	mq.mu.RLock()
@@ -117,6 +406,7 @@ func (mq *MusicQueue) Size() int {
 
 // Clear clears the entire queue
 func (mq *MusicQueue) Clear() {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
 	mq.items = make([]*QueueItem, 0)
@@ -125,6 +415,7 @@ func (mq *MusicQueue) Clear() {
 
 // Remove removes an item at the specified index
 func (mq *MusicQueue) Remove(index int) error {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
 
@@ -138,11 +429,36 @@ func (mq *MusicQueue) Remove(index int) error {
 	return nil
 }
 
-// SetPlaying sets the playing state
-func (mq *MusicQueue) SetPlaying(playing bool) {
+// PlayNext moves the item at index to the front of the pending list so it
+// plays right after the current song, without interrupting it, and returns
+// the moved item
+func (mq *MusicQueue) PlayNext(index int) (*QueueItem, error) {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
+
+	if index < 0 || index >= len(mq.items) {
+		return nil, fmt.Errorf("invalid index: %d", index)
+	}
+
+	item := mq.items[index]
+	copy(mq.items[1:index+1], mq.items[:index])
+	mq.items[0] = item
+	log.Printf("Moved '%s' to the front of the queue for guild %s", item.Title, mq.guildID)
+	return item, nil
+}
+
+// SetPlaying sets the playing state. Starting playback preloads the next
+// item, whether the current one was taken with Next before or after.
+func (mq *MusicQueue) SetPlaying(playing bool) {
+	mq.mu.Lock()
+	started := playing && !mq.isPlaying
 	mq.isPlaying = playing
+	mq.mu.Unlock()
+
+	if started {
+		mq.refreshPrefetch()
+	}
 }
 
 // IsPlaying returns whether something is currently playing
@@ -190,11 +506,24 @@ func (mq *MusicQueue) GetVoiceConnection() *discordgo.VoiceConnection {
 
 // SetPipeline sets the audio pipeline for this queue
 func (mq *MusicQueue) SetPipeline(pipeline *AudioPipeline) {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
+	mq.rememberOutcomeLocked()
 	mq.pipeline = pipeline
 }
 
+// SessionEncoder returns the opus encoder the queue's pipelines share
+func (mq *MusicQueue) SessionEncoder() *SessionEncoder {
+	return mq.encoder
+}
+
+// RecoveryBudget returns the restart budget the queue's pipelines share, so
+// a flapping stream can't dodge it by moving on to the next track
+func (mq *MusicQueue) RecoveryBudget() *pipeline.RecoveryBudget {
+	return mq.recoveries
+}
+
 // GetPipeline returns the audio pipeline
 func (mq *MusicQueue) GetPipeline() *AudioPipeline {
 	mq.mu.RLock()
@@ -218,11 +547,18 @@ func (mq *MusicQueue) WasSkipped() bool {
 
 // StopAndCleanup safely stops the current pipeline and cleans up resources
 func (mq *MusicQueue) StopAndCleanup() {
+	mq.StopAndCleanupWithReason(OutcomeUserStopped)
+}
+
+// StopAndCleanupWithReason is StopAndCleanup recording why playback stopped
+func (mq *MusicQueue) StopAndCleanupWithReason(reason OutcomeReason) {
+	defer mq.notify()
 	mq.mu.Lock()
 	defer mq.mu.Unlock()
 
 	if mq.pipeline != nil {
-		mq.pipeline.Stop()
+		mq.pipeline.StopWithReason(reason)
+		mq.rememberOutcomeLocked()
 		mq.pipeline = nil
 	}
 
@@ -232,4 +568,5 @@ func (mq *MusicQueue) StopAndCleanup() {
 	}
 
 	mq.isPlaying = false
+	mq.failures = 0
 }
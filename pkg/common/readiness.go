@@ -0,0 +1,79 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrNoAudioFrames is returned when a started pipeline doesn't deliver
+	// enough frames to the voice connection in time
+	ErrNoAudioFrames = errors.New("no audio reached the voice connection")
+
+	// ErrPipelineStopped is returned when a pipeline stops before it is ready
+	ErrPipelineStopped = errors.New("pipeline stopped")
+)
+
+// readinessPollInterval is how often WaitForFrames checks the frame count
+const readinessPollInterval = 20 * time.Millisecond
+
+// RecordFrame records that a frame reached the voice connection. The default
+// streamer calls it for every frame sent; replacement streamers should call
+// it too so readiness and health checks can see audio flowing.
+func (ap *AudioPipeline) RecordFrame() {
+	atomic.AddInt64(&ap.framesDelivered, 1)
+	atomic.StoreInt64(&ap.lastFrameAt, time.Now().UnixNano())
+}
+
+// FramesDelivered returns how many frames have reached the voice connection
+// since the pipeline started, across seeks and restarts. Stop resets it.
+func (ap *AudioPipeline) FramesDelivered() int64 {
+	return atomic.LoadInt64(&ap.framesDelivered)
+}
+
+// LastFrameAt returns when a frame last reached the voice connection, or the
+// zero time if none has
+func (ap *AudioPipeline) LastFrameAt() time.Time {
+	nanos := atomic.LoadInt64(&ap.lastFrameAt)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// WaitForFrames blocks until frames frames have reached the voice connection,
+// returning nil. It returns ErrNoAudioFrames if they don't arrive within
+// timeout, and ErrPipelineStopped if the pipeline stops first.
+func (ap *AudioPipeline) WaitForFrames(frames int64, timeout time.Duration) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if ap.FramesDelivered() >= frames {
+			return nil
+		}
+		if !ap.IsPlaying() {
+			return ErrPipelineStopped
+		}
+
+		select {
+		case <-deadline.C:
+			if ap.FramesDelivered() >= frames {
+				return nil
+			}
+			return fmt.Errorf("%w: %d of %d frames in %s", ErrNoAudioFrames, ap.FramesDelivered(), frames, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopWithError stops the audio pipeline and records it as failed with err,
+// unless the track had already ended
+func (ap *AudioPipeline) StopWithError(err error) {
+	ap.finish(OutcomeError, err)
+	ap.StopWithReason(OutcomeError)
+}
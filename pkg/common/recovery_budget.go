@@ -0,0 +1,51 @@
+package common
+
+import (
+	"errors"
+	"log"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// errRecoveryBudgetExhausted ends playback once restarts have used up the
+// sliding-window recovery budget
+var errRecoveryBudgetExhausted = errors.New("recovery budget exhausted")
+
+// SetRecoveryBudget makes the pipeline count its stream restarts against a
+// budget shared with other pipelines, such as the ones a queue plays in
+// turn. Nil keeps the pipeline's own budget. Call it before PlayStream.
+func (ap *AudioPipeline) SetRecoveryBudget(budget *pipeline.RecoveryBudget) {
+	if budget == nil {
+		return
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.recoveryBudget = budget
+}
+
+// spendRecoveryBudget records a stream restart against the recovery budget.
+// Once the budget is spent it reports the exhaustion and returns false, and
+// the caller ends playback instead of restarting.
+func (ap *AudioPipeline) spendRecoveryBudget() bool {
+	maxRecoveries, window := recoveryBudgetLimits()
+
+	ap.mu.RLock()
+	budget := ap.recoveryBudget
+	metrics := ap.metrics
+	ap.mu.RUnlock()
+
+	if budget.Allow(maxRecoveries, window) {
+		return true
+	}
+
+	log.Printf("Recovery budget exhausted (%d restarts per %v), stopping", maxRecoveries, window)
+	ap.recordEvent("recovery_budget_exhausted", "high", map[string]interface{}{
+		"max_recoveries": maxRecoveries,
+		"window":         window.String(),
+	})
+	if metrics != nil {
+		metrics.Counter(pipeline.MetricRecoveryBudgetExhausted, 1, nil)
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package common
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"layeh.com/gopus"
+)
+
+const (
+	// defaultOpusBitrate is the bitrate pipelines encode at unless
+	// OpusOptions sets one
+	defaultOpusBitrate = 128000
+
+	// MetricEncoderStartLatency is a gauge of how long a track waited for
+	// its opus encoder, in milliseconds. It is tagged reused=true or false,
+	// so the two series show what reuse saves.
+	MetricEncoderStartLatency = "pipeline.encoder.start_ms"
+)
+
+// opusFormat is what an encoder was created for. A session's encoder is only
+// reused by tracks with the same format.
+type opusFormat struct {
+	sampleRate  int
+	channels    int
+	application gopus.Application
+	bitrate     int
+}
+
+// currentOpusFormat returns the format new tracks encode in
+func currentOpusFormat() opusFormat {
+	bitrate := currentOpusOptions().Bitrate
+	if bitrate <= 0 {
+		bitrate = defaultOpusBitrate
+	}
+
+	return opusFormat{
+		sampleRate:  48000,
+		channels:    2,
+		application: currentOpusApplication(),
+		bitrate:     bitrate,
+	}
+}
+
+// newOpusEncoder creates an encoder for format
+func newOpusEncoder(format opusFormat) (*gopus.Encoder, error) {
+	encoder, err := gopus.NewEncoder(format.sampleRate, format.channels, format.application)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create opus encoder: %v", err)
+	}
+	encoder.SetBitrate(format.bitrate)
+	return encoder, nil
+}
+
+// SessionEncoder keeps one opus encoder for the pipelines of a playback
+// session, so each track resets it instead of allocating a new one. A new
+// encoder replaces it when the format changes.
+type SessionEncoder struct {
+	mu      sync.Mutex
+	encoder *gopus.Encoder
+	format  opusFormat
+	inUse   bool
+
+	created int64
+	reused  int64
+}
+
+// NewSessionEncoder creates an empty session encoder; the first track
+// creates its encoder
+func NewSessionEncoder() *SessionEncoder {
+	return &SessionEncoder{}
+}
+
+// acquire returns an encoder for format and whether it is the session's
+// reused encoder. While another pipeline still holds the session's encoder,
+// a fresh one is returned so two streams never share encoder state.
+func (se *SessionEncoder) acquire(format opusFormat) (*gopus.Encoder, bool, error) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	if se.encoder != nil && se.inUse {
+		se.created++
+		encoder, err := newOpusEncoder(format)
+		return encoder, false, err
+	}
+
+	if se.encoder != nil && se.format == format {
+		// The last track may have left it at an adapted bitrate
+		se.encoder.ResetState()
+		se.encoder.SetBitrate(format.bitrate)
+		se.inUse = true
+		se.reused++
+		return se.encoder, true, nil
+	}
+
+	encoder, err := newOpusEncoder(format)
+	if err != nil {
+		return nil, false, err
+	}
+	se.encoder = encoder
+	se.format = format
+	se.inUse = true
+	se.created++
+	return encoder, false, nil
+}
+
+// release hands an encoder back once its track has ended
+func (se *SessionEncoder) release(encoder *gopus.Encoder) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+
+	if encoder == se.encoder {
+		se.inUse = false
+	}
+}
+
+// Stats returns how many encoders the session has created and how many
+// tracks reused one
+func (se *SessionEncoder) Stats() (created, reused int64) {
+	se.mu.Lock()
+	defer se.mu.Unlock()
+	return se.created, se.reused
+}
+
+// SetSessionEncoder makes the pipeline take its encoder from a session
+// instead of creating its own. Call it before PlayStream.
+func (ap *AudioPipeline) SetSessionEncoder(session *SessionEncoder) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.sessionEncoder = session
+}
+
+// OpusEncoder returns the encoder the pipeline is encoding with, or nil
+// before PlayStream
+func (ap *AudioPipeline) OpusEncoder() *gopus.Encoder {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	return ap.opusEncoder
+}
+
+// acquireEncoderLocked sets up the pipeline's encoder, from its session
+// when it has one, and reports how long that took. Callers hold ap.mu.
+func (ap *AudioPipeline) acquireEncoderLocked() error {
+	start := time.Now()
+	format := currentOpusFormat()
+
+	var (
+		encoder *gopus.Encoder
+		reused  bool
+		err     error
+	)
+	if ap.sessionEncoder != nil {
+		encoder, reused, err = ap.sessionEncoder.acquire(format)
+	} else {
+		encoder, err = newOpusEncoder(format)
+	}
+	if err != nil {
+		return err
+	}
+	ap.opusEncoder = encoder
+
+	if ap.metrics != nil {
+		latency := float64(time.Since(start)) / float64(time.Millisecond)
+		ap.metrics.Gauge(MetricEncoderStartLatency, latency, map[string]string{"reused": fmt.Sprint(reused)})
+	}
+	return nil
+}
+
+// releaseEncoder returns the pipeline's encoder to its session
+func (ap *AudioPipeline) releaseEncoder() {
+	ap.mu.RLock()
+	session, encoder := ap.sessionEncoder, ap.opusEncoder
+	ap.mu.RUnlock()
+
+	if session != nil && encoder != nil {
+		session.release(encoder)
+	}
+}
@@ -0,0 +1,77 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSilenceThresholdDB is the level below which leading audio counts as
+// silence when trimming is on
+const DefaultSilenceThresholdDB = -50.0
+
+// SetSilenceTrim turns trimming of a track's leading silence on or off.
+// Thresholds of 0 dB or more restore the default. It takes effect on the
+// next ffmpeg start, so call it before PlayStream.
+//
+// Silent frames are dropped before encoding and counted toward Position, so
+// a later seek or resumed session still lands where the track says. In
+// passthrough the samples never reach the bot, so nothing is trimmed.
+func (ap *AudioPipeline) SetSilenceTrim(enabled bool, thresholdDB float64) {
+	if thresholdDB >= 0 {
+		thresholdDB = DefaultSilenceThresholdDB
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.trimSilence = enabled
+	ap.silenceThresholdDB = thresholdDB
+}
+
+// silenceTrimThreshold returns the sample level below which leading frames
+// are trimmed from a stream starting at offset, or 0 when none are. Silence
+// is only trimmed from the start of a track, never after a seek, so a quiet
+// passage sought into still plays from where it was asked for.
+func (ap *AudioPipeline) silenceTrimThreshold(offset time.Duration) int {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+
+	if !ap.trimSilence || offset != 0 {
+		return 0
+	}
+	return int(math.Pow(10, ap.silenceThresholdDB/20) * math.MaxInt16)
+}
+
+// trimFrame reports whether a frame of s16le PCM is silent below threshold
+// and should be dropped, counting it toward Position if so
+func (ap *AudioPipeline) trimFrame(frame []byte, threshold int) bool {
+	for i := 0; i+1 < len(frame); i += 2 {
+		sample := int(int16(binary.LittleEndian.Uint16(frame[i:])))
+		if sample >= threshold || -sample >= threshold {
+			return false
+		}
+	}
+	atomic.AddInt64(&ap.trimmedFrames, 1)
+	return true
+}
+
+// audioFilters returns the ffmpeg filters to run over streamURL, or nil when
+// it needs none. Configured loudness normalization replaces the per-track
+// gain: loudnorm levels the whole stream, so applying the probed gain too
+// would correct the same loudness twice.
+func (ap *AudioPipeline) audioFilters(streamURL string) []string {
+	if filters := sourceAudioFilters(streamURL); len(filters) > 0 {
+		return filters
+	}
+
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+
+	var filters []string
+	if ap.gainDB != 0 {
+		filters = append(filters, fmt.Sprintf("volume=%.2fdB", ap.gainDB))
+	}
+	return filters
+}
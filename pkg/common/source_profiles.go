@@ -0,0 +1,79 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+var (
+	// streamConfig holds the ffmpeg settings and per-source profiles that
+	// ffmpeg is started with
+	streamConfig      = pipeline.DefaultPipelineConfig()
+	streamConfigMutex sync.RWMutex
+)
+
+// SetStreamConfig sets the config whose ffmpeg settings later streams start
+// with, merged with the profile for each stream's source kind so that, for
+// example, live streams reconnect and pre-buffer more while files don't
+// reconnect. Nil restores the defaults.
+func SetStreamConfig(config *pipeline.PipelineConfig) {
+	if config == nil {
+		config = pipeline.DefaultPipelineConfig()
+	}
+
+	streamConfigMutex.Lock()
+	streamConfig = config
+	streamConfigMutex.Unlock()
+}
+
+// sourceFFmpegConfig returns the ffmpeg settings for streamURL, with its
+// source kind's profile applied
+func sourceFFmpegConfig(streamURL string) pipeline.FFmpegConfig {
+	streamConfigMutex.RLock()
+	defer streamConfigMutex.RUnlock()
+
+	merged, _ := streamConfig.ForSource(streamURL)
+	return merged.FFmpeg
+}
+
+// positionUpdateInterval returns how often playing pipelines publish their
+// position
+func positionUpdateInterval() time.Duration {
+	streamConfigMutex.RLock()
+	defer streamConfigMutex.RUnlock()
+
+	if interval := streamConfig.Discord.PositionUpdateInterval; interval > 0 {
+		return interval
+	}
+	return pipeline.DefaultPositionUpdateInterval
+}
+
+// recoveryBackoff returns the delays between stream restarts and how many
+// restarts a stream gets
+func recoveryBackoff() pipeline.BackoffConfig {
+	streamConfigMutex.RLock()
+	defer streamConfigMutex.RUnlock()
+
+	return streamConfig.Recovery.Backoff
+}
+
+// recoveryBudgetLimits returns how many stream restarts fit in the recovery
+// budget's sliding window, and how long that window is
+func recoveryBudgetLimits() (int, time.Duration) {
+	streamConfigMutex.RLock()
+	defer streamConfigMutex.RUnlock()
+
+	return streamConfig.Recovery.BudgetMaxRecoveries, streamConfig.Recovery.BudgetWindow
+}
+
+// sourceAudioFilters returns the ffmpeg filters the config's audio settings
+// ask for on streamURL, such as loudness normalization
+func sourceAudioFilters(streamURL string) []string {
+	streamConfigMutex.RLock()
+	defer streamConfigMutex.RUnlock()
+
+	merged, _ := streamConfig.ForSource(streamURL)
+	return merged.Audio.Filters()
+}
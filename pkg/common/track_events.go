@@ -0,0 +1,175 @@
+package common
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TrackEventType names a playback event
+type TrackEventType string
+
+const (
+	TrackStarted  TrackEventType = "track_started"  // a track began playing
+	TrackFinished TrackEventType = "track_finished" // a track stopped on its own: completed, failed or timed out
+	TrackSkipped  TrackEventType = "track_skipped"  // a track was skipped by a command
+)
+
+// DefaultTrackEventBuffer is how many events a subscriber may fall behind by
+// before further events are dropped for it
+const DefaultTrackEventBuffer = 64
+
+// TrackEvent describes a playback event for integrations such as scrobblers
+// and webhooks
+type TrackEvent struct {
+	Type        TrackEventType
+	GuildID     string
+	Title       string
+	URL         string // The original page URL when known, otherwise the stream URL
+	VideoID     string
+	RequestedBy string
+	Duration    time.Duration // Zero for live or unknown-length streams
+	StartedAt   time.Time     // When playback of the track began
+	Timestamp   time.Time     // When the event happened
+
+	// Set on TrackFinished and TrackSkipped
+	Outcome Outcome
+}
+
+// TrackEventBus fans playback events out to subscribers. Publishing never
+// blocks: each subscriber has its own buffer, and events that don't fit are
+// dropped for that subscriber alone.
+type TrackEventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]*trackSubscriber
+	nextID      int
+}
+
+// trackSubscriber is one Subscribe call's channel
+type trackSubscriber struct {
+	events  chan TrackEvent
+	dropped int64
+}
+
+// NewTrackEventBus creates an empty event bus
+func NewTrackEventBus() *TrackEventBus {
+	return &TrackEventBus{subscribers: make(map[int]*trackSubscriber)}
+}
+
+// DefaultTrackEvents is the bus every queue publishes to unless given another
+var DefaultTrackEvents = NewTrackEventBus()
+
+// Subscribe returns a channel receiving every event published from now on,
+// and a function that unsubscribes and closes it. buffer of 0 or less uses
+// DefaultTrackEventBuffer.
+func (b *TrackEventBus) Subscribe(buffer int) (<-chan TrackEvent, func()) {
+	if buffer <= 0 {
+		buffer = DefaultTrackEventBuffer
+	}
+
+	sub := &trackSubscriber{events: make(chan TrackEvent, buffer)}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subscribers[id] = sub
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(sub.events)
+		})
+	}
+	return sub.events, unsubscribe
+}
+
+// Publish delivers an event to every subscriber with room for it
+func (b *TrackEventBus) Publish(event TrackEvent) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+		}
+	}
+}
+
+// Dropped returns how many events have been dropped across subscribers
+// because their buffers were full
+func (b *TrackEventBus) Dropped() int64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var dropped int64
+	for _, sub := range b.subscribers {
+		dropped += atomic.LoadInt64(&sub.dropped)
+	}
+	return dropped
+}
+
+// SetTrackEvents sets the bus this queue publishes playback events to. Nil
+// stops publishing.
+func (mq *MusicQueue) SetTrackEvents(bus *TrackEventBus) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+	mq.trackEvents = bus
+}
+
+// PublishTrackStarted announces that item began playing
+func (mq *MusicQueue) PublishTrackStarted(item *QueueItem) {
+	mq.mu.Lock()
+	item.StartedAt = time.Now()
+	mq.mu.Unlock()
+
+	mq.publishTrackEvent(TrackStarted, item, Outcome{})
+}
+
+// PublishTrackEnded announces how item stopped, as TrackSkipped if it was
+// skipped and TrackFinished otherwise
+func (mq *MusicQueue) PublishTrackEnded(item *QueueItem, outcome Outcome, skipped bool) {
+	eventType := TrackFinished
+	if skipped {
+		eventType = TrackSkipped
+	}
+	mq.publishTrackEvent(eventType, item, outcome)
+}
+
+// publishTrackEvent builds the event for item and publishes it
+func (mq *MusicQueue) publishTrackEvent(eventType TrackEventType, item *QueueItem, outcome Outcome) {
+	if item == nil {
+		return
+	}
+
+	mq.mu.RLock()
+	bus := mq.trackEvents
+	event := TrackEvent{
+		Type:        eventType,
+		GuildID:     mq.guildID,
+		Title:       item.Title,
+		URL:         item.URL,
+		VideoID:     item.VideoID,
+		RequestedBy: item.RequestedBy,
+		Duration:    item.Duration,
+		StartedAt:   item.StartedAt,
+		Outcome:     outcome,
+	}
+	if item.OriginalURL != "" {
+		event.URL = item.OriginalURL
+	}
+	mq.mu.RUnlock()
+
+	if bus != nil {
+		bus.Publish(event)
+	}
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -8,8 +9,76 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-// FindAndJoinUserVoiceChannel finds the user's voice channel and joins it with retry logic
+// ErrNotInVoiceChannel is returned when the user to join is not in a voice
+// channel. It is the user's to fix, so joining is not retried.
+var ErrNotInVoiceChannel = errors.New("you must be in a voice channel to play music")
+
+// VoiceJoinRetry controls how JoinVoiceWithRetry retries a failed join
+type VoiceJoinRetry struct {
+	MaxAttempts  int
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+}
+
+// DefaultVoiceJoinRetry returns the retry settings used when starting playback
+func DefaultVoiceJoinRetry() VoiceJoinRetry {
+	return VoiceJoinRetry{
+		MaxAttempts:  3,
+		InitialDelay: time.Second,
+		MaxDelay:     4 * time.Second,
+	}
+}
+
+// FindAndJoinUserVoiceChannel finds the user's voice channel and joins it,
+// retrying transient gateway failures
 func FindAndJoinUserVoiceChannel(s *discordgo.Session, userID, guildID string) (*discordgo.VoiceConnection, error) {
+	return JoinVoiceWithRetry(func() (*discordgo.VoiceConnection, error) {
+		return joinUserVoiceChannel(s, userID, guildID)
+	}, DefaultVoiceJoinRetry())
+}
+
+// JoinVoiceWithRetry calls join until it succeeds or MaxAttempts is reached,
+// doubling the delay between attempts up to MaxDelay. ErrNotInVoiceChannel
+// is returned at once; other errors are treated as transient.
+func JoinVoiceWithRetry(join func() (*discordgo.VoiceConnection, error), cfg VoiceJoinRetry) (*discordgo.VoiceConnection, error) {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+
+	delay := cfg.InitialDelay
+	var err error
+
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		var vc *discordgo.VoiceConnection
+		vc, err = join()
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Joined voice channel on attempt %d/%d", attempt, cfg.MaxAttempts)
+			}
+			return vc, nil
+		}
+		if errors.Is(err, ErrNotInVoiceChannel) {
+			return nil, err
+		}
+
+		log.Printf("Voice join attempt %d/%d failed: %v", attempt, cfg.MaxAttempts, err)
+
+		if attempt < cfg.MaxAttempts {
+			time.Sleep(delay)
+
+			delay *= 2
+			if cfg.MaxDelay > 0 && delay > cfg.MaxDelay {
+				delay = cfg.MaxDelay
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("couldn't connect to voice after %d attempts, Discord may be having trouble; try again shortly: %w", cfg.MaxAttempts, err)
+}
+
+// joinUserVoiceChannel makes a single attempt to join the user's voice
+// channel and wait for the connection to be ready
+func joinUserVoiceChannel(s *discordgo.Session, userID, guildID string) (*discordgo.VoiceConnection, error) {
 	guild, err := s.State.Guild(guildID)
 	if err != nil {
 		return nil, fmt.Errorf("could not find guild: %v", err)
@@ -24,7 +93,7 @@ func FindAndJoinUserVoiceChannel(s *discordgo.Session, userID, guildID string) (
 	}
 
 	if userChannelID == "" {
-		return nil, fmt.Errorf("you must be in a voice channel to play music")
+		return nil, ErrNotInVoiceChannel
 	}
 
 	// Get channel info for logging
@@ -36,24 +105,9 @@ func FindAndJoinUserVoiceChannel(s *discordgo.Session, userID, guildID string) (
 
 	log.Printf("Joining voice channel: %s (%s) in guild: %s", channelName, userChannelID, guildID)
 
-	// Join with retry logic
-	var vc *discordgo.VoiceConnection
-	maxRetries := 3
-
-	for i := 0; i < maxRetries; i++ {
-		vc, err = s.ChannelVoiceJoin(guildID, userChannelID, false, true)
-		if err == nil {
-			break
-		}
-
-		log.Printf("Voice join attempt %d/%d failed: %v", i+1, maxRetries, err)
-		if i < maxRetries-1 {
-			time.Sleep(time.Duration(i+1) * time.Second)
-		}
-	}
-
+	vc, err := s.ChannelVoiceJoin(guildID, userChannelID, false, true)
 	if err != nil {
-		return nil, fmt.Errorf("failed to join voice channel after %d attempts: %v", maxRetries, err)
+		return nil, fmt.Errorf("failed to join voice channel: %v", err)
 	}
 
 	// Wait for connection to be ready with timeout
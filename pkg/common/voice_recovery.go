@@ -0,0 +1,49 @@
+package common
+
+import (
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// SetVoiceSession sets how the pipeline reconnects when Discord invalidates
+// its voice session, for example with close code 4006: the session leaves
+// the channel, joins again and resumes the track where it was. Without one,
+// voice close errors end playback.
+func (ap *AudioPipeline) SetVoiceSession(session pipeline.VoiceSession) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.voiceSession = session
+}
+
+// isVoiceClose reports whether err is Discord closing the voice connection
+func isVoiceClose(err error) bool {
+	_, ok := pipeline.VoiceCloseCode(err)
+	return ok
+}
+
+// voiceReconnectStrategy returns the strategy that recovers from err by
+// reconnecting, or nil if err isn't a voice close a reconnect recovers from
+// or the pipeline has no voice session
+func (ap *AudioPipeline) voiceReconnectStrategy(err error) *pipeline.VoiceReconnectStrategy {
+	ap.mu.RLock()
+	session := ap.voiceSession
+	ap.mu.RUnlock()
+
+	if session == nil || err == nil {
+		return nil
+	}
+
+	strategy := pipeline.NewVoiceReconnectStrategy(session, 1)
+	if !strategy.CanRecover(pipeline.VoiceErrorClassifier{}.Classify(err)) {
+		return nil
+	}
+	return strategy
+}
+
+// reconnectVoice runs strategy's disconnect, rejoin and resume. The resume
+// seeks to where playback was, so the next stream starts there rather than
+// counting as a seek of its own.
+func (ap *AudioPipeline) reconnectVoice(strategy *pipeline.VoiceReconnectStrategy) error {
+	err := strategy.Recover(ap.ctx, nil)
+	ap.takePendingSeek()
+	return err
+}
@@ -73,14 +73,21 @@ func GetYouTubeThumbnailURL(videoID string) string {
 
 // GetYouTubeMetadata extracts both title and duration from a YouTube URL
 func GetYouTubeMetadata(urlStr string) (title string, duration time.Duration, err error) {
+	title, duration, _, err = getYouTubeMetadata(urlStr)
+	return title, duration, err
+}
+
+// getYouTubeMetadata extracts the title, duration and chapter markers
+func getYouTubeMetadata(urlStr string) (title string, duration time.Duration, chapters []Chapter, err error) {
 	log.Printf("Extracting metadata from: %s", urlStr)
 
-	// Use yt-dlp to get both title and duration
+	// Use yt-dlp to get the title, duration and chapters
 	cmd := exec.Command("yt-dlp",
 		"--no-playlist",
 		"--no-warnings",
 		"--print", "title",
 		"--print", "duration",
+		"--print", "%(chapters)j",
 		urlStr)
 
 	var out bytes.Buffer
@@ -88,7 +95,7 @@ func GetYouTubeMetadata(urlStr string) (title string, duration time.Duration, er
 
 	if err := cmd.Run(); err != nil {
 		log.Printf("Failed to get metadata: %v", err)
-		return "Unknown Title", 0, fmt.Errorf("failed to extract metadata: %v", err)
+		return "Unknown Title", 0, nil, fmt.Errorf("failed to extract metadata: %v", err)
 	}
 
 	output := strings.TrimSpace(out.String())
@@ -106,25 +113,41 @@ func GetYouTubeMetadata(urlStr string) (title string, duration time.Duration, er
 			}
 		}
 	}
+	if len(lines) >= 3 {
+		// Chapters are optional; a malformed list just leaves them out
+		if parsed, parseErr := ParseChapters(lines[2]); parseErr == nil {
+			chapters = parsed
+		} else {
+			log.Printf("Ignoring chapters: %v", parseErr)
+		}
+	}
 
 	if title == "" {
 		title = "Unknown Title"
 	}
 
-	log.Printf("Extracted metadata - Title: %s, Duration: %v", title, duration)
-	return title, duration, nil
+	log.Printf("Extracted metadata - Title: %s, Duration: %v, Chapters: %d", title, duration, len(chapters))
+	return title, duration, chapters, nil
 }
 
 // GetYouTubeAudioStreamWithMetadata extracts stream URL, title, and duration
 func GetYouTubeAudioStreamWithMetadata(urlStr string) (streamURL, title string, duration time.Duration, err error) {
+	streamURL, title, duration, _, err = GetYouTubeAudioStreamWithChapters(urlStr)
+	return streamURL, title, duration, err
+}
+
+// GetYouTubeAudioStreamWithChapters extracts stream URL, title, duration and
+// chapter markers. Videos without chapters return none.
+func GetYouTubeAudioStreamWithChapters(urlStr string) (streamURL, title string, duration time.Duration, chapters []Chapter, err error) {
 	log.Printf("Extracting audio stream and metadata from: %s", urlStr)
 
-	// First, get metadata (title and duration)
-	title, duration, metaErr := GetYouTubeMetadata(urlStr)
+	// First, get metadata (title, duration and chapters)
+	title, duration, chapters, metaErr := getYouTubeMetadata(urlStr)
 	if metaErr != nil {
 		log.Printf("Warning: Failed to get metadata: %v", metaErr)
 		title = "Unknown Title"
 		duration = 0
+		chapters = nil
 	}
 
 	// Then get stream URL with multiple fallback strategies
@@ -164,12 +187,12 @@ func GetYouTubeAudioStreamWithMetadata(urlStr string) (streamURL, title string,
 			if len(urls) > 0 && urls[0] != "" {
 				streamURL = urls[0]
 				log.Printf("Successfully extracted stream URL using strategy %d", i+1)
-				return streamURL, title, duration, nil
+				return streamURL, title, duration, chapters, nil
 			}
 		}
 	}
 
-	return "", title, duration, fmt.Errorf("failed to extract audio stream URL after trying all strategies")
+	return "", title, duration, chapters, fmt.Errorf("failed to extract audio stream URL after trying all strategies")
 }
 
 // SearchYouTubeAndGetURL searches for a query on YouTube and returns the first result's URL
@@ -18,6 +18,10 @@ type BuildIDManager struct {
 	isRunning   bool
 	schedule    string
 	config      *config.Config
+
+	// Tracks the initial fetch so Stop can wait for it
+	initial  sync.WaitGroup
+	stopOnce sync.Once
 }
 
 // NewBuildIDManager creates a new build ID manager
@@ -55,7 +59,11 @@ func NewBuildIDManagerWithSchedule(refreshFunc func() error, cfg *config.Config)
 		}
 
 		// Initial build ID fetch
-		go manager.refreshBuildID()
+		manager.initial.Add(1)
+		go func() {
+			defer manager.initial.Done()
+			manager.refreshBuildID()
+		}()
 	} else {
 		log.Println("Cron is disabled, build ID refresh will not be scheduled")
 	}
@@ -91,12 +99,16 @@ func (bm *BuildIDManager) refreshBuildID() {
 	}
 }
 
-// Stop stops the cron scheduler
+// Stop stops the cron scheduler and waits for any refresh in progress to
+// finish. Calling it again does nothing.
 func (bm *BuildIDManager) Stop() {
-	if bm.cron != nil {
-		bm.cron.Stop()
+	bm.stopOnce.Do(func() {
+		if bm.cron != nil {
+			<-bm.cron.Stop().Done()
+		}
+		bm.initial.Wait()
 		log.Println("Build ID manager stopped")
-	}
+	})
 }
 
 // GetNextRun returns the next scheduled run time
@@ -0,0 +1,234 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// backupMetadataExt is appended to a backup's path to name its sidecar
+const backupMetadataExt = ".json"
+
+// BackupStatus is the outcome of verifying a backup
+type BackupStatus string
+
+const (
+	BackupVerified   BackupStatus = "verified"   // Checksum and integrity check pass
+	BackupCorrupt    BackupStatus = "corrupt"    // Checksum mismatch or failed integrity check
+	BackupUnverified BackupStatus = "unverified" // No metadata to verify against, or it couldn't be read
+)
+
+// BackupMetadata is written alongside each backup so it can be verified
+// before it is restored
+type BackupMetadata struct {
+	SchemaVersion int              `json:"schema_version"` // Migration version of the backed up database
+	SourceVersion string           `json:"source_version"` // SQLite version that wrote the backup
+	RowCounts     map[string]int64 `json:"row_counts"`     // Rows per table in the backup
+	SHA256        string           `json:"sha256"`         // Checksum of the backup file
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// backupMetadataPath returns the path of a backup's metadata sidecar
+func backupMetadataPath(backupPath string) string {
+	return backupPath + backupMetadataExt
+}
+
+// writeBackupMetadata records the metadata of a freshly created backup
+func (mm *migrationManager) writeBackupMetadata(backupPath string) error {
+	schemaVersion, err := mm.GetCurrentVersion()
+	if err != nil {
+		return err
+	}
+
+	var sourceVersion string
+	if err := mm.db.QueryRow("SELECT sqlite_version()").Scan(&sourceVersion); err != nil {
+		return fmt.Errorf("failed to get sqlite version: %w", err)
+	}
+
+	checksum, err := fileSHA256(backupPath)
+	if err != nil {
+		return err
+	}
+
+	backup, err := openBackup(backupPath)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	rowCounts, err := tableRowCounts(backup)
+	if err != nil {
+		return err
+	}
+
+	metadata := &BackupMetadata{
+		SchemaVersion: schemaVersion,
+		SourceVersion: sourceVersion,
+		RowCounts:     rowCounts,
+		SHA256:        checksum,
+		CreatedAt:     time.Now(),
+	}
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode backup metadata: %w", err)
+	}
+	if err := os.WriteFile(backupMetadataPath(backupPath), data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup metadata: %w", err)
+	}
+	return nil
+}
+
+// readBackupMetadata loads a backup's sidecar, returning an error wrapping
+// ErrBackupMetadataMissing when it has none
+func readBackupMetadata(backupPath string) (*BackupMetadata, error) {
+	data, err := os.ReadFile(backupMetadataPath(backupPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrBackupMetadataMissing, filepath.Base(backupPath))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup metadata: %w", err)
+	}
+
+	var metadata BackupMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode backup metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// VerifyBackup checks a backup in the backup directory against its metadata:
+// the file's SHA-256 must match the recorded one and SQLite's integrity
+// check must pass. A backup failing either returns an error wrapping
+// ErrBackupCorrupt.
+func (mm *migrationManager) VerifyBackup(filename string) (*BackupMetadata, error) {
+	backupPath := filepath.Join(mm.config.BackupDirectory, filepath.Base(filename))
+
+	metadata, err := readBackupMetadata(backupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := fileSHA256(backupPath)
+	if err != nil {
+		return metadata, err
+	}
+	if checksum != metadata.SHA256 {
+		return metadata, fmt.Errorf("%w: checksum mismatch: recorded=%s, actual=%s", ErrBackupCorrupt, metadata.SHA256, checksum)
+	}
+
+	if err := checkBackupIntegrity(backupPath); err != nil {
+		return metadata, err
+	}
+	return metadata, nil
+}
+
+// backupStatus reports the outcome of VerifyBackup as a status
+func backupStatus(err error) BackupStatus {
+	switch {
+	case err == nil:
+		return BackupVerified
+	case errors.Is(err, ErrBackupCorrupt):
+		return BackupCorrupt
+	default:
+		return BackupUnverified
+	}
+}
+
+// openBackup opens a backup read-only
+func openBackup(backupPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", backupPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	return db, nil
+}
+
+// checkBackupIntegrity runs PRAGMA integrity_check on a backup
+func checkBackupIntegrity(backupPath string) error {
+	backup, err := openBackup(backupPath)
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	rows, err := backup.Query("PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%w: integrity check failed: %v", ErrBackupCorrupt, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("failed to read integrity check: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: integrity check failed: %v", ErrBackupCorrupt, err)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrBackupCorrupt, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// tableRowCounts counts the rows of every user table in db
+func tableRowCounts(db *sql.DB) (map[string]int64, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = append(tables, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	counts := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		var count int64
+		query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, strings.ReplaceAll(table, `"`, `""`))
+		if err := db.QueryRow(query).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to count rows in %s: %w", table, err)
+		}
+		counts[table] = count
+	}
+	return counts, nil
+}
+
+// fileSHA256 returns the hex SHA-256 of a file's contents
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -4,15 +4,29 @@ import "errors"
 
 // Database configuration errors
 var (
-	ErrInvalidDatabasePath            = errors.New("invalid database path")
-	ErrInvalidMaxConnections          = errors.New("invalid max connections")
-	ErrInvalidConnectionTimeout       = errors.New("invalid connection timeout")
-	ErrInvalidMetricsBatchSize        = errors.New("invalid metrics batch size")
-	ErrInvalidMetricsFlushInterval    = errors.New("invalid metrics flush interval")
-	ErrInvalidMetricsRetention        = errors.New("invalid metrics retention")
-	ErrInvalidUMACacheRetention       = errors.New("invalid UMA cache retention")
-	ErrInvalidUMACacheCleanupInterval = errors.New("invalid UMA cache cleanup interval")
-	ErrInvalidSynchronousMode         = errors.New("invalid synchronous mode")
+	ErrInvalidDatabasePath              = errors.New("invalid database path")
+	ErrInvalidMaxConnections            = errors.New("invalid max connections")
+	ErrInvalidConnectionTimeout         = errors.New("invalid connection timeout")
+	ErrInvalidMetricsBatchSize          = errors.New("invalid metrics batch size")
+	ErrInvalidMetricsFlushInterval      = errors.New("invalid metrics flush interval")
+	ErrInvalidMetricsRetention          = errors.New("invalid metrics retention")
+	ErrInvalidMetricsTagCardinality     = errors.New("invalid metrics tag cardinality limit")
+	ErrInvalidMetricsWriteMode          = errors.New("invalid metrics write mode")
+	ErrInvalidMetricsTrickleBatchSize   = errors.New("invalid metrics trickle batch size")
+	ErrInvalidMetricsInsertPoolSize     = errors.New("invalid metrics insert pool size")
+	ErrInvalidMetricsRollupInterval     = errors.New("invalid metrics rollup interval")
+	ErrInvalidMetricsRollupBucket       = errors.New("invalid metrics rollup bucket")
+	ErrInvalidUMACacheRetention         = errors.New("invalid UMA cache retention")
+	ErrInvalidUMACacheCleanupInterval   = errors.New("invalid UMA cache cleanup interval")
+	ErrInvalidUMACacheTTLJitter         = errors.New("invalid UMA cache TTL jitter")
+	ErrInvalidUMACacheStaleGrace        = errors.New("invalid UMA cache stale grace")
+	ErrInvalidSessionQualityWeights     = errors.New("invalid session quality weights")
+	ErrInvalidEventCompressionThreshold = errors.New("invalid event compression threshold")
+	ErrInvalidSlowQueryThreshold        = errors.New("invalid slow query threshold")
+	ErrInvalidStaleSessionThreshold     = errors.New("invalid stale session threshold")
+	ErrInvalidSynchronousMode           = errors.New("invalid synchronous mode")
+	ErrInvalidPageSize                  = errors.New("invalid page size")
+	ErrInvalidMmapSize                  = errors.New("invalid mmap size")
 )
 
 // Database operation errors
@@ -23,6 +37,8 @@ var (
 	ErrTransactionFailed       = errors.New("transaction failed")
 	ErrMigrationFailed         = errors.New("migration failed")
 	ErrBackupFailed            = errors.New("backup failed")
+	ErrBackupCorrupt           = errors.New("backup corrupt")
+	ErrBackupMetadataMissing   = errors.New("backup metadata missing")
 	ErrRestoreFailed           = errors.New("restore failed")
 )
 
@@ -35,6 +51,19 @@ var (
 	ErrInvalidEventType   = errors.New("invalid event type")
 	ErrInvalidSeverity    = errors.New("invalid severity")
 	ErrInvalidAggregation = errors.New("invalid aggregation")
+	ErrInvalidGapInterval = errors.New("invalid gap interval")
+	ErrInvalidTimeSeries  = errors.New("invalid time series range")
+)
+
+// Export errors
+var (
+	ErrUnsupportedSchemaVersion = errors.New("unsupported export schema version")
+)
+
+// Retention policy errors
+var (
+	ErrInvalidRetentionTable  = errors.New("invalid retention table")
+	ErrInvalidRetentionColumn = errors.New("invalid retention timestamp column")
 )
 
 // Migration errors
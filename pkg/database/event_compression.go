@@ -0,0 +1,109 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"fmt"
+	"io"
+)
+
+// DefaultEventCompressionThreshold is the event_data size in bytes above
+// which payloads are gzipped when compression is enabled
+const DefaultEventCompressionThreshold = 4096
+
+// compressEventData gzips data if it is larger than threshold. It reports
+// whether the returned bytes are compressed; small payloads, and payloads
+// that gzip would not shrink, are returned as is.
+func compressEventData(data []byte, threshold int) ([]byte, bool, error) {
+	if len(data) <= threshold {
+		return data, false, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, false, fmt.Errorf("failed to compress event data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to compress event data: %w", err)
+	}
+
+	if buf.Len() >= len(data) {
+		return data, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decodeEventData returns the event_data JSON, decompressing it if the row
+// is marked compressed. Rows written before compression existed are plain.
+func decodeEventData(data []byte, compressed bool) ([]byte, error) {
+	if !compressed {
+		return data, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress event data: %w", err)
+	}
+	defer reader.Close()
+
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress event data: %w", err)
+	}
+	return decoded, nil
+}
+
+// sqlExecer is satisfied by both *sql.DB and *sql.Tx
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// addColumnIfMissing adds a column unless the table already has it. SQLite
+// has no ADD COLUMN IF NOT EXISTS, and both the metrics repository and the
+// migrations may get to the column first.
+func addColumnIfMissing(db sqlExecer, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+
+	exists := false
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to inspect table %s: %w", table, err)
+		}
+		if name == column {
+			exists = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+// addEventCompressedColumn adds the pipeline_events.compressed marker
+func addEventCompressedColumn(db sqlExecer) error {
+	return addColumnIfMissing(db, "pipeline_events", "compressed", "INTEGER NOT NULL DEFAULT 0")
+}
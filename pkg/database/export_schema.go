@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportSchemaVersion is the format version stamped on exported data as
+// "major.minor". Importers accept any minor version of a known major and
+// reject other majors.
+//
+// Version history:
+//   - 1.0: session exports carry exported_at and the sessions array;
+//     analytics carry the SessionAnalytics fields.
+const ExportSchemaVersion = "1.0"
+
+// SessionExport is the JSON document written by ExportSessionsJSON
+type SessionExport struct {
+	SchemaVersion string             `json:"schema_version"`
+	ExportedAt    time.Time          `json:"exported_at"`
+	Sessions      []*PipelineSession `json:"sessions"`
+}
+
+// ExportSessionsJSON writes the sessions started within the time range to w
+// as a versioned SessionExport document
+func (sm *SessionManager) ExportSessionsJSON(ctx context.Context, w io.Writer, startTime, endTime time.Time) error {
+	sessions, err := sm.GetSessionsByTimeRange(ctx, startTime, endTime)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions for export: %w", err)
+	}
+
+	export := SessionExport{
+		SchemaVersion: ExportSchemaVersion,
+		ExportedAt:    time.Now(),
+		Sessions:      sessions,
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return fmt.Errorf("failed to encode session export: %w", err)
+	}
+
+	return nil
+}
+
+// ImportSessionExport reads a SessionExport document, rejecting documents
+// whose major schema version this build does not understand
+func ImportSessionExport(r io.Reader) (*SessionExport, error) {
+	var export SessionExport
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to decode session export: %w", err)
+	}
+
+	if err := CheckExportSchemaVersion(export.SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// CheckExportSchemaVersion returns ErrUnsupportedSchemaVersion unless version
+// shares the major version of ExportSchemaVersion
+func CheckExportSchemaVersion(version string) error {
+	major, err := schemaMajor(version)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrUnsupportedSchemaVersion, version, err)
+	}
+
+	supported, _ := schemaMajor(ExportSchemaVersion)
+	if major != supported {
+		return fmt.Errorf("%w %q: this build reads major version %d", ErrUnsupportedSchemaVersion, version, supported)
+	}
+
+	return nil
+}
+
+// schemaMajor parses the major component of a "major.minor" version
+func schemaMajor(version string) (int, error) {
+	if version == "" {
+		return 0, fmt.Errorf("missing schema_version")
+	}
+
+	majorText, _, _ := strings.Cut(version, ".")
+	major, err := strconv.Atoi(majorText)
+	if err != nil || major < 0 {
+		return 0, fmt.Errorf("malformed schema_version")
+	}
+
+	return major, nil
+}
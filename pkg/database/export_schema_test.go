@@ -0,0 +1,78 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_ExportSessionsJSON_Versioned(t *testing.T) {
+	dbPath := "test_export_schema.db"
+	defer os.Remove(dbPath)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.DatabasePath = dbPath
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	sessionManager := NewSessionManager(repo, config)
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, sessionManager.CreateSession(ctx, &PipelineSession{
+		PipelineID: "schema-session",
+		GuildID:    "guild-1",
+		StartedAt:  now.Add(-time.Minute),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, sessionManager.ExportSessionsJSON(ctx, &buf, now.Add(-time.Hour), now.Add(time.Hour)))
+
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &raw))
+	assert.Equal(t, ExportSchemaVersion, raw["schema_version"])
+
+	imported, err := ImportSessionExport(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, imported.Sessions, 1)
+	assert.Equal(t, "schema-session", imported.Sessions[0].PipelineID)
+
+	analytics, err := sessionManager.GetSessionAnalytics(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ExportSchemaVersion, analytics.SchemaVersion)
+}
+
+func TestImportSessionExport_RejectsUnknownMajor(t *testing.T) {
+	bumped := strings.NewReader(`{"schema_version": "2.0", "sessions": []}`)
+	_, err := ImportSessionExport(bumped)
+	assert.ErrorIs(t, err, ErrUnsupportedSchemaVersion)
+
+	missing := strings.NewReader(`{"sessions": []}`)
+	_, err = ImportSessionExport(missing)
+	assert.ErrorIs(t, err, ErrUnsupportedSchemaVersion)
+
+	newerMinor := strings.NewReader(`{"schema_version": "1.7", "sessions": []}`)
+	_, err = ImportSessionExport(newerMinor)
+	assert.NoError(t, err)
+}
+
+func TestCheckExportSchemaVersion(t *testing.T) {
+	assert.NoError(t, CheckExportSchemaVersion(ExportSchemaVersion))
+	assert.NoError(t, CheckExportSchemaVersion("1"))
+	assert.ErrorIs(t, CheckExportSchemaVersion("0.9"), ErrUnsupportedSchemaVersion)
+	assert.ErrorIs(t, CheckExportSchemaVersion("v1.0"), ErrUnsupportedSchemaVersion)
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// PurgeResult reports how many rows PurgeGuild removed from each table
+type PurgeResult struct {
+	GuildID string           `json:"guild_id"`
+	Deleted map[string]int64 `json:"deleted"`
+}
+
+// Total returns the number of rows removed across all tables
+func (r PurgeResult) Total() int64 {
+	var total int64
+	for _, count := range r.Deleted {
+		total += count
+	}
+	return total
+}
+
+// purgeStatement deletes a guild's rows from one table
+type purgeStatement struct {
+	table string
+	query string
+}
+
+// PurgeGuild deletes everything tied to a guild in a single transaction: its
+// sessions, the metrics and events of those sessions (joined by pipeline_id),
+// and rows of any other table with a guild_id column, such as queue
+// persistence or audit tables.
+func (r *metricsRepository) PurgeGuild(ctx context.Context, guildID string) (PurgeResult, error) {
+	result := PurgeResult{GuildID: guildID, Deleted: make(map[string]int64)}
+	if guildID == "" {
+		return result, fmt.Errorf("guild ID cannot be empty")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to begin purge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	guildSessions := `pipeline_id IN (SELECT pipeline_id FROM pipeline_sessions WHERE guild_id = ?)`
+	deletes := []purgeStatement{
+		{"pipeline_metrics", `DELETE FROM pipeline_metrics WHERE ` + guildSessions},
+		{"pipeline_events", `DELETE FROM pipeline_events WHERE ` + guildSessions},
+	}
+
+	// Discover other guild-scoped tables so new ones are purged without
+	// having to remember this method
+	rows, err := tx.QueryContext(ctx, `
+		SELECT m.name FROM sqlite_master m, pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND p.name = 'guild_id' AND m.name != 'pipeline_sessions'
+		ORDER BY m.name
+	`)
+	if err != nil {
+		return result, fmt.Errorf("failed to find guild tables: %w", err)
+	}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed to find guild tables: %w", err)
+		}
+		quoted := `"` + strings.ReplaceAll(table, `"`, `""`) + `"`
+		deletes = append(deletes, purgeStatement{table, `DELETE FROM ` + quoted + ` WHERE guild_id = ?`})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("failed to find guild tables: %w", err)
+	}
+
+	// Sessions go last; the metric and event deletes select through them
+	deletes = append(deletes, purgeStatement{"pipeline_sessions", `DELETE FROM pipeline_sessions WHERE guild_id = ?`})
+
+	for _, d := range deletes {
+		res, err := tx.ExecContext(ctx, d.query, guildID)
+		if err != nil {
+			return result, fmt.Errorf("failed to purge %s: %w", d.table, err)
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			return result, fmt.Errorf("failed to count purged %s rows: %w", d.table, err)
+		}
+		result.Deleted[d.table] = count
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsRepository_PurgeGuild(t *testing.T) {
+	repo, db, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// A guild-scoped table outside the metrics schema, like queue persistence
+	_, err := db.Exec(`CREATE TABLE queue_items (id INTEGER PRIMARY KEY, guild_id TEXT, title TEXT)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO queue_items (guild_id, title) VALUES ('guild-a', 'one'), ('guild-a', 'two'), ('guild-b', 'three')`)
+	require.NoError(t, err)
+
+	seed := func(guildID, pipelineID string) {
+		require.NoError(t, repo.CreateSession(ctx, &PipelineSession{
+			PipelineID: pipelineID,
+			GuildID:    guildID,
+			StartedAt:  now,
+		}))
+		require.NoError(t, repo.(*metricsRepository).storeBatchMetricsDirect(ctx, []*PipelineMetric{
+			{PipelineID: pipelineID, MetricName: "m", MetricType: "counter", MetricValue: 1, Timestamp: now},
+			{PipelineID: pipelineID, MetricName: "m", MetricType: "counter", MetricValue: 2, Timestamp: now},
+		}))
+		require.NoError(t, repo.StoreEvent(ctx, &PipelineEvent{
+			PipelineID: pipelineID,
+			EventType:  "error",
+			EventData:  map[string]interface{}{"guild": guildID},
+			Severity:   "low",
+			Timestamp:  now,
+		}))
+	}
+	seed("guild-a", "pipeline-a1")
+	seed("guild-a", "pipeline-a2")
+	seed("guild-b", "pipeline-b1")
+
+	result, err := repo.PurgeGuild(ctx, "guild-a")
+	require.NoError(t, err)
+	assert.Equal(t, "guild-a", result.GuildID)
+	assert.Equal(t, int64(2), result.Deleted["pipeline_sessions"])
+	assert.Equal(t, int64(4), result.Deleted["pipeline_metrics"])
+	assert.Equal(t, int64(2), result.Deleted["pipeline_events"])
+	assert.Equal(t, int64(2), result.Deleted["queue_items"])
+	assert.Equal(t, int64(10), result.Total())
+
+	count := func(query string, args ...interface{}) int {
+		var n int
+		require.NoError(t, db.QueryRow(query, args...).Scan(&n))
+		return n
+	}
+
+	// Guild A is gone
+	assert.Zero(t, count(`SELECT COUNT(*) FROM pipeline_sessions WHERE guild_id = 'guild-a'`))
+	assert.Zero(t, count(`SELECT COUNT(*) FROM pipeline_metrics WHERE pipeline_id LIKE 'pipeline-a%'`))
+	assert.Zero(t, count(`SELECT COUNT(*) FROM pipeline_events WHERE pipeline_id LIKE 'pipeline-a%'`))
+
+	// Guild B is untouched
+	assert.Equal(t, 1, count(`SELECT COUNT(*) FROM pipeline_sessions WHERE guild_id = 'guild-b'`))
+	assert.Equal(t, 2, count(`SELECT COUNT(*) FROM pipeline_metrics WHERE pipeline_id = 'pipeline-b1'`))
+	assert.Equal(t, 1, count(`SELECT COUNT(*) FROM pipeline_events WHERE pipeline_id = 'pipeline-b1'`))
+	assert.Equal(t, 1, count(`SELECT COUNT(*) FROM queue_items WHERE guild_id = 'guild-b'`))
+
+	// Purging again is a no-op
+	result, err = repo.PurgeGuild(ctx, "guild-a")
+	require.NoError(t, err)
+	assert.Zero(t, result.Total())
+
+	_, err = repo.PurgeGuild(ctx, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// InsertPool bounds how many metric batch inserts run at once. Batch
+// processors sharing a pool take a slot for each transaction, so the total
+// write concurrency stays at the pool size however many pipelines are
+// recording metrics.
+type InsertPool struct {
+	mu    sync.RWMutex
+	slots chan struct{}
+
+	active int64
+	peak   int64
+	waits  int64
+	total  int64
+}
+
+// InsertPoolStats is a snapshot of an insert pool
+type InsertPoolStats struct {
+	Size   int   `json:"size"`
+	Active int   `json:"active"` // inserts running now
+	Peak   int   `json:"peak"`   // most inserts ever running at once
+	Waits  int64 `json:"waits"`  // inserts that had to wait for a slot
+	Total  int64 `json:"total"`  // inserts run through the pool
+}
+
+// NewInsertPool creates a pool running at most size inserts at once. Sizes
+// below one are treated as one.
+func NewInsertPool(size int) *InsertPool {
+	if size < 1 {
+		size = 1
+	}
+	return &InsertPool{slots: make(chan struct{}, size)}
+}
+
+var (
+	// sharedInsertPool is the process-wide pool sized by
+	// MetricsInsertPoolSize
+	sharedInsertPool      *InsertPool
+	sharedInsertPoolMutex sync.Mutex
+)
+
+// SharedInsertPool returns the process-wide pool, creating it on first use
+// and resizing it to size otherwise. Every repository with a
+// MetricsInsertPoolSize writes through the same pool, so the bound holds
+// across all of them; if their sizes differ, the last one configured wins.
+func SharedInsertPool(size int) *InsertPool {
+	sharedInsertPoolMutex.Lock()
+	defer sharedInsertPoolMutex.Unlock()
+
+	if sharedInsertPool == nil {
+		sharedInsertPool = NewInsertPool(size)
+	} else if sharedInsertPool.Size() != size {
+		sharedInsertPool.resize(size)
+	}
+	return sharedInsertPool
+}
+
+// resize replaces the pool's slots. Inserts already running give their slot
+// back to the slots they took it from.
+func (p *InsertPool) resize(size int) {
+	if size < 1 {
+		size = 1
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.slots = make(chan struct{}, size)
+}
+
+// Do runs insert once a slot is free and returns its error
+func (p *InsertPool) Do(insert func() error) error {
+	p.mu.RLock()
+	slots := p.slots
+	p.mu.RUnlock()
+
+	select {
+	case slots <- struct{}{}:
+	default:
+		atomic.AddInt64(&p.waits, 1)
+		slots <- struct{}{}
+	}
+
+	active := atomic.AddInt64(&p.active, 1)
+	for {
+		peak := atomic.LoadInt64(&p.peak)
+		if active <= peak || atomic.CompareAndSwapInt64(&p.peak, peak, active) {
+			break
+		}
+	}
+	atomic.AddInt64(&p.total, 1)
+
+	defer func() {
+		atomic.AddInt64(&p.active, -1)
+		<-slots
+	}()
+	return insert()
+}
+
+// Size returns how many inserts the pool runs at once
+func (p *InsertPool) Size() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return cap(p.slots)
+}
+
+// Stats returns a snapshot of the pool
+func (p *InsertPool) Stats() InsertPoolStats {
+	return InsertPoolStats{
+		Size:   p.Size(),
+		Active: int(atomic.LoadInt64(&p.active)),
+		Peak:   int(atomic.LoadInt64(&p.peak)),
+		Waits:  atomic.LoadInt64(&p.waits),
+		Total:  atomic.LoadInt64(&p.total),
+	}
+}
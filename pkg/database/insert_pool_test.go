@@ -0,0 +1,167 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertPoolBoundsConcurrency(t *testing.T) {
+	pool := NewInsertPool(3)
+	assert.Equal(t, 3, pool.Size())
+
+	var running, maxRunning int64
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.Do(func() error {
+				now := atomic.AddInt64(&running, 1)
+				for {
+					max := atomic.LoadInt64(&maxRunning)
+					if now <= max || atomic.CompareAndSwapInt64(&maxRunning, max, now) {
+						break
+					}
+				}
+				<-release
+				atomic.AddInt64(&running, -1)
+				return nil
+			})
+		}()
+	}
+
+	// The pool fills up and everyone else waits
+	require.Eventually(t, func() bool {
+		return pool.Stats().Active == 3 && pool.Stats().Waits > 0
+	}, time.Second, 5*time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	stats := pool.Stats()
+	assert.Equal(t, int64(3), atomic.LoadInt64(&maxRunning))
+	assert.Equal(t, 3, stats.Peak)
+	assert.Equal(t, 0, stats.Active)
+	assert.Equal(t, int64(20), stats.Total)
+}
+
+func TestInsertPoolReturnsInsertError(t *testing.T) {
+	pool := NewInsertPool(0)
+	assert.Equal(t, 1, pool.Size())
+
+	err := pool.Do(func() error { return fmt.Errorf("disk full") })
+	assert.EqualError(t, err, "disk full")
+	assert.Equal(t, 0, pool.Stats().Active)
+}
+
+func TestSharedInsertPool(t *testing.T) {
+	pool := SharedInsertPool(4)
+	assert.Same(t, pool, SharedInsertPool(4))
+
+	// A different size resizes the one pool rather than starting another
+	assert.Same(t, pool, SharedInsertPool(5))
+	assert.Equal(t, 5, pool.Size())
+}
+
+func TestInsertPoolResizeWhileRunning(t *testing.T) {
+	pool := NewInsertPool(1)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- pool.Do(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+
+	// The running insert holds the old slot; the new size admits two more
+	pool.resize(2)
+	assert.NoError(t, pool.Do(func() error { return nil }))
+
+	close(release)
+	assert.NoError(t, <-done)
+	assert.Equal(t, 0, pool.Stats().Active)
+	assert.Equal(t, 2, pool.Stats().Size)
+}
+
+func TestInsertPoolAcrossProcessors(t *testing.T) {
+	const (
+		processors      = 8
+		producers       = 4
+		metricsPerWrite = 50
+		poolSize        = 2
+	)
+
+	pool := NewInsertPool(poolSize)
+
+	var all []*MetricsBatchProcessor
+	var counts []func() int
+	for i := 0; i < processors; i++ {
+		processor, db, cleanup := setupTestBatchProcessor(t)
+		defer cleanup()
+
+		processor.SetInsertPool(pool)
+		require.NoError(t, processor.Start())
+		all = append(all, processor)
+		counts = append(counts, func() int {
+			var count int
+			require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM pipeline_metrics").Scan(&count))
+			return count
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i, processor := range all {
+		for j := 0; j < producers; j++ {
+			wg.Add(1)
+			go func(pipeline, producer int, processor *MetricsBatchProcessor) {
+				defer wg.Done()
+				for k := 0; k < metricsPerWrite; k++ {
+					metric := &PipelineMetric{
+						PipelineID:  fmt.Sprintf("pipeline-%d", pipeline),
+						MetricName:  fmt.Sprintf("producer_%d", producer),
+						MetricType:  "counter",
+						MetricValue: float64(k),
+						Timestamp:   time.Now(),
+					}
+					// Back off while inserts queue behind the pool
+					for processor.AddMetric(metric) != nil {
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}(i, j, processor)
+		}
+	}
+	wg.Wait()
+
+	for _, processor := range all {
+		require.NoError(t, processor.Flush())
+	}
+
+	want := producers * metricsPerWrite
+	for i, count := range counts {
+		require.Eventually(t, func() bool { return count() == want },
+			5*time.Second, 20*time.Millisecond, "processor %d", i)
+	}
+
+	stats := pool.Stats()
+	assert.LessOrEqual(t, stats.Peak, poolSize)
+	assert.Greater(t, stats.Total, int64(0))
+	assert.Equal(t, 0, stats.Active)
+
+	processorStats := all[0].GetStats()
+	require.NotNil(t, processorStats.InsertPool)
+	assert.Equal(t, poolSize, processorStats.InsertPool.Size)
+}
@@ -46,10 +46,23 @@ type UMARepository interface {
 	// Gametora operations
 	CacheGametoraSkills(query string, result *uma.SimplifiedGametoraSearchResult, ttl time.Duration) error
 	GetCachedGametoraSkills(query string) (*uma.SimplifiedGametoraSearchResult, error)
+	ClearGametoraSkillsCache() (int64, error)
+
+	// Serve-stale reads, used when a fresh fetch fails
+	GetStaleCharacterSearch(query string) (*uma.CharacterSearchResult, error)
+	GetStaleCharacterImages(characterID int) (*uma.CharacterImagesResult, error)
+	GetStaleSupportCardSearch(query string) (*uma.SupportCardSearchResult, error)
+	GetStaleSupportCardList() (*uma.SupportCardListResult, error)
+	GetStaleGametoraSkills(query string) (*uma.SimplifiedGametoraSearchResult, error)
 
 	// Maintenance
 	CleanExpiredCache() error
 	GetCacheStats() (map[string]int, error)
+
+	// Configuration
+	SetTTLJitter(jitter float64)
+	SetStaleGrace(grace time.Duration)
+	SetStaleHook(hook StaleHook)
 }
 
 // MetricsRepository defines the interface for pipeline metrics operations
@@ -58,7 +71,11 @@ type MetricsRepository interface {
 	StoreMetric(ctx context.Context, metric *PipelineMetric) error
 	StoreBatchMetrics(ctx context.Context, metrics []*PipelineMetric) error
 	GetMetrics(ctx context.Context, query *MetricsQuery) ([]*PipelineMetric, error)
+	GetLatestMetric(ctx context.Context, pipelineID, name string) (*PipelineMetric, error)
+	GetTopMetrics(ctx context.Context, name string, since time.Time, limit int) ([]PipelineMetricTop, error)
+	DetectGaps(ctx context.Context, pipelineID string, expectedInterval, window time.Duration) ([]Gap, error)
 	GetAggregatedMetrics(ctx context.Context, query *AggregationQuery) (*AggregatedMetrics, error)
+	GetTimeSeries(ctx context.Context, query *TimeSeriesQuery) (*TimeSeries, error)
 
 	// Session operations
 	CreateSession(ctx context.Context, session *PipelineSession) error
@@ -69,6 +86,7 @@ type MetricsRepository interface {
 	// Event operations
 	StoreEvent(ctx context.Context, event *PipelineEvent) error
 	GetEvents(ctx context.Context, query *EventQuery) ([]*PipelineEvent, error)
+	IterateEvents(ctx context.Context, query *EventQuery, fn func(*PipelineEvent) error) error
 
 	// Maintenance
 	CleanExpiredMetrics(ctx context.Context, retentionPeriod time.Duration) error
@@ -82,6 +100,11 @@ type MetricsRepository interface {
 	GetRetentionStats() (*RetentionStats, error)
 	RunRetentionCleanup(ctx context.Context) (*RetentionStats, error)
 
+	// Metric rollups
+	GetRollupStats() (*RollupStats, error)
+	RunMetricsRollup(ctx context.Context) (int, error)
+	GetMetricRollups(ctx context.Context, name string, start, end time.Time) ([]*MetricRollup, error)
+
 	// Session analytics and queries
 	GetSessionsByTimeRange(ctx context.Context, startTime, endTime time.Time) ([]*PipelineSession, error)
 	GetSessionsByGuild(ctx context.Context, guildID string, limit int) ([]*PipelineSession, error)
@@ -90,8 +113,12 @@ type MetricsRepository interface {
 	GetSessionsByHour(ctx context.Context) (map[int]int64, error)
 	GetTopErrorTypes(ctx context.Context, limit int) ([]ErrorTypeCount, error)
 	GetOrphanedSessions(ctx context.Context, cutoffTime time.Time) ([]*PipelineSession, error)
+	GetStaleSessions(ctx context.Context, lastActivityBefore time.Time) ([]*StaleSession, error)
 	GetSessionErrorRates(ctx context.Context) (*SessionErrorRates, error)
 
+	// Data deletion
+	PurgeGuild(ctx context.Context, guildID string) (PurgeResult, error)
+
 	// Lifecycle management
 	Close() error
 }
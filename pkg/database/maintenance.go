@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// maintenanceAnalysisLimit caps the rows ANALYZE samples per index, keeping
+// its write lock brief on large tables at the cost of approximate statistics
+const maintenanceAnalysisLimit = 1000
+
+// SetMaintenanceReindex sets whether Maintain also rebuilds every index.
+// REINDEX holds its lock for longer than ANALYZE, so it is off by default.
+func (d *Database) SetMaintenanceReindex(reindex bool) {
+	d.maintenanceReindex = reindex
+}
+
+// Maintain refreshes the statistics SQLite plans queries with, and rebuilds
+// the indexes if SetMaintenanceReindex is on. Each step holds its lock only
+// while it runs, so it is safe alongside normal use.
+func (d *Database) Maintain(ctx context.Context) error {
+	return maintainDB(ctx, d.db, d.maintenanceReindex)
+}
+
+// StartMaintenance starts a background goroutine running Maintain every
+// interval. Intervals of 0 or less disable it.
+func (d *Database) StartMaintenance(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := d.Maintain(context.Background()); err != nil {
+				log.Printf("Database maintenance failed: %v", err)
+			}
+		}
+	}()
+}
+
+// maintainDB runs ANALYZE, and REINDEX when asked, on one connection so the
+// analysis limit applies, logging how long each step took
+func maintainDB(ctx context.Context, db *sql.DB, reindex bool) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for maintenance: %w", err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA analysis_limit = %d", maintenanceAnalysisLimit)); err != nil {
+		return fmt.Errorf("failed to set analysis limit: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze database: %w", err)
+	}
+	analyzed := time.Since(start)
+
+	var reindexed time.Duration
+	if reindex {
+		reindexStart := time.Now()
+		if _, err := conn.ExecContext(ctx, "REINDEX"); err != nil {
+			return fmt.Errorf("failed to reindex database: %w", err)
+		}
+		reindexed = time.Since(reindexStart)
+	}
+
+	if reindex {
+		log.Printf("Database maintenance completed in %v (analyze %v, reindex %v)",
+			time.Since(start).Round(time.Millisecond), analyzed.Round(time.Millisecond), reindexed.Round(time.Millisecond))
+	} else {
+		log.Printf("Database maintenance completed in %v (analyze only)", time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
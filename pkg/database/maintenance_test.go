@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPopulatedDatabase creates a cache database holding some entries
+func setupPopulatedDatabase(t *testing.T) *Database {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	for i := 0; i < 200; i++ {
+		result := &uma.CharacterSearchResult{Query: fmt.Sprintf("character %d", i), Found: true}
+		require.NoError(t, db.CacheCharacterSearch(result.Query, result, time.Hour))
+	}
+	return db
+}
+
+func TestDatabase_Maintain(t *testing.T) {
+	db := setupPopulatedDatabase(t)
+
+	require.NoError(t, db.Maintain(context.Background()))
+
+	// ANALYZE leaves its statistics in sqlite_stat1
+	var stats int
+	require.NoError(t, db.db.QueryRow("SELECT COUNT(*) FROM sqlite_stat1").Scan(&stats))
+	assert.Greater(t, stats, 0)
+
+	// The cache still works afterwards
+	cached, err := db.GetCachedCharacterSearch("character 7")
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.True(t, cached.Found)
+}
+
+func TestDatabase_MaintainWithReindex(t *testing.T) {
+	db := setupPopulatedDatabase(t)
+	db.SetMaintenanceReindex(true)
+
+	require.NoError(t, db.Maintain(context.Background()))
+
+	var integrity string
+	require.NoError(t, db.db.QueryRow("PRAGMA integrity_check").Scan(&integrity))
+	assert.Equal(t, "ok", integrity)
+}
+
+func TestDatabase_MaintainCanceled(t *testing.T) {
+	db := setupPopulatedDatabase(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, db.Maintain(ctx))
+}
@@ -63,11 +63,8 @@ func (dm *databaseManager) connectInternal() error {
 		return nil
 	}
 
-	// Open database connection
-	db, err := sql.Open("sqlite3", dm.buildConnectionString())
-	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
-	}
+	// Open database connection; cache, mmap and page size pragmas are applied per connection
+	db := sql.OpenDB(newPragmaConnector(dm.buildConnectionString(), dm.config))
 
 	// Configure connection pool
 	db.SetMaxOpenConns(dm.config.MaxConnections)
@@ -329,7 +326,6 @@ func (dm *databaseManager) buildConnectionString() string {
 	}
 
 	connStr += fmt.Sprintf("synchronous=%s&", dm.config.SynchronousMode)
-	connStr += fmt.Sprintf("cache_size=%d&", dm.config.CacheSize)
 	connStr += "foreign_keys=ON"
 
 	return connStr
@@ -349,6 +345,8 @@ func (dm *databaseManager) initializeComponents() error {
 	if err != nil {
 		return fmt.Errorf("failed to create UMA repository: %w", err)
 	}
+	umaRepository.SetTTLJitter(dm.config.UMACacheTTLJitter)
+	umaRepository.SetStaleGrace(dm.config.UMACacheStaleGrace)
 	dm.umaRepository = umaRepository
 
 	// Initialize metrics repository
@@ -358,9 +356,35 @@ func (dm *databaseManager) initializeComponents() error {
 	}
 	dm.metricsRepository = metricsRepository
 
+	// Record a served_stale metric whenever the UMA cache falls back to expired data
+	umaRepository.SetStaleHook(dm.recordServedStale)
+
 	return nil
 }
 
+// recordServedStale stores a served_stale counter for an expired UMA cache hit
+func (dm *databaseManager) recordServedStale(cacheType, key string, expiredFor time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	metric := &PipelineMetric{
+		PipelineID:  "uma_cache",
+		MetricName:  "served_stale",
+		MetricType:  "counter",
+		MetricValue: 1,
+		Tags:        map[string]string{"cache": cacheType},
+		Metadata: map[string]interface{}{
+			"key":                 key,
+			"expired_for_seconds": expiredFor.Seconds(),
+		},
+		Timestamp: time.Now(),
+	}
+
+	if err := dm.metricsRepository.StoreMetric(ctx, metric); err != nil {
+		log.Printf("Failed to record served_stale metric: %v", err)
+	}
+}
+
 // startBackgroundTasks starts background maintenance tasks
 func (dm *databaseManager) startBackgroundTasks() {
 	// Start cleanup task
@@ -197,6 +197,45 @@ func TestDatabaseManager_BackupAndRestore(t *testing.T) {
 	dm.Close()
 }
 
+func TestDatabaseManager_Pragmas(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	config := DefaultDatabaseConfig()
+	config.DatabasePath = dbPath
+	config.PageSize = 8192
+	config.CacheSize = -32000
+	config.MmapSize = 32 << 20
+
+	dm, err := NewDatabaseManager(config)
+	require.NoError(t, err)
+	require.NoError(t, dm.Connect())
+
+	readPragmas := func(dm *databaseManager) (pageSize, cacheSize int, mmapSize int64) {
+		require.NoError(t, dm.db.QueryRow("PRAGMA page_size").Scan(&pageSize))
+		require.NoError(t, dm.db.QueryRow("PRAGMA cache_size").Scan(&cacheSize))
+		require.NoError(t, dm.db.QueryRow("PRAGMA mmap_size").Scan(&mmapSize))
+		return pageSize, cacheSize, mmapSize
+	}
+
+	pageSize, cacheSize, mmapSize := readPragmas(dm.(*databaseManager))
+	assert.Equal(t, 8192, pageSize)
+	assert.Equal(t, -32000, cacheSize)
+	assert.Equal(t, int64(32<<20), mmapSize)
+	require.NoError(t, dm.Close())
+
+	// Reopening an existing file keeps its page size but applies the rest
+	config.PageSize = 16384
+	config.CacheSize = -16000
+	dm, err = NewDatabaseManager(config)
+	require.NoError(t, err)
+	require.NoError(t, dm.Connect())
+	defer dm.Close()
+
+	pageSize, cacheSize, _ = readPragmas(dm.(*databaseManager))
+	assert.Equal(t, 8192, pageSize)
+	assert.Equal(t, -16000, cacheSize)
+}
+
 func TestDatabaseConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -247,6 +286,15 @@ func TestDatabaseConfig_Validate(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "invalid page size",
+			config: func() *DatabaseConfig {
+				config := DefaultDatabaseConfig()
+				config.PageSize = 5000
+				return config
+			}(),
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
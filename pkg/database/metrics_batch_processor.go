@@ -29,6 +29,15 @@ type MetricsBatchProcessor struct {
 	maxRetries    int
 	retryDelay    time.Duration
 
+	// Trickle mode spreads large batches over the flush interval
+	writeMode        MetricsWriteMode
+	trickleBatchSize int
+	trickleQueue     chan []*PipelineMetric
+	trickleDone      chan struct{} // nil unless the trickle writer is running
+
+	// Bounds inserts shared with other processors; nil writes unbounded
+	insertPool *InsertPool
+
 	// Control channels
 	stopChan chan struct{}
 	doneChan chan struct{}
@@ -41,10 +50,17 @@ type MetricsBatchProcessor struct {
 	processedCount int64
 	errorCount     int64
 	retryCount     int64
+	subBatchCount  int64
 	statsMutex     sync.RWMutex
 
 	// Prepared statements
 	insertStmt *sql.Stmt
+
+	// Collapses runaway tag values before they reach the database
+	cardinality *cardinalityGuard
+
+	// Logs batch flushes, and the repository's queries, that run too long
+	slowQueries *slowQueryLog
 }
 
 // Logger interface for the batch processor
@@ -88,6 +104,19 @@ func NewMetricsBatchProcessor(db *sql.DB, config *DatabaseConfig) (*MetricsBatch
 		errorRetryQueue: make(chan []*PipelineMetric, 5),
 		stopChan:        make(chan struct{}),
 		doneChan:        make(chan struct{}),
+		cardinality:     newCardinalityGuard(config.MetricsTagCardinalityLimit),
+	}
+
+	processor.slowQueries = newSlowQueryLog(config, processor.logger, processor.AddMetric)
+
+	processor.writeMode = config.MetricsWriteMode.normalize()
+	processor.trickleBatchSize = config.MetricsTrickleBatchSize
+	if processor.trickleBatchSize <= 0 {
+		processor.trickleBatchSize = DefaultMetricsTrickleBatchSize
+	}
+
+	if config.MetricsInsertPoolSize > 0 {
+		processor.insertPool = SharedInsertPool(config.MetricsInsertPoolSize)
 	}
 
 	// Prepare insert statement
@@ -101,6 +130,14 @@ func NewMetricsBatchProcessor(db *sql.DB, config *DatabaseConfig) (*MetricsBatch
 // SetLogger sets a custom logger for the batch processor
 func (p *MetricsBatchProcessor) SetLogger(logger Logger) {
 	p.logger = logger
+	p.slowQueries.logger = logger
+}
+
+// SetInsertPool routes the processor's inserts through pool, bounding them
+// together with every other processor using it. Nil removes the bound. Call
+// it before Start.
+func (p *MetricsBatchProcessor) SetInsertPool(pool *InsertPool) {
+	p.insertPool = pool
 }
 
 // Start begins the batch processing goroutines
@@ -121,10 +158,17 @@ func (p *MetricsBatchProcessor) Start() error {
 	// Start retry processor
 	go p.runRetryProcessor()
 
+	// Start the trickle writer, which paces sub-batches off the processor goroutine
+	if p.writeMode == MetricsWriteTrickle {
+		p.trickleQueue = make(chan []*PipelineMetric, trickleQueueBatches*p.trickleBatchesPerFlush())
+		p.trickleDone = make(chan struct{})
+		go p.runTrickleWriter()
+	}
+
 	p.running = true
 
-	p.logger.Printf("MetricsBatchProcessor started with batch size %d and flush interval %v",
-		p.batchSize, p.flushInterval)
+	p.logger.Printf("MetricsBatchProcessor started with batch size %d, flush interval %v and %s writes",
+		p.batchSize, p.flushInterval, p.writeMode)
 
 	return nil
 }
@@ -154,6 +198,14 @@ func (p *MetricsBatchProcessor) Stop() error {
 		p.logger.Errorf("MetricsBatchProcessor stop timeout")
 	}
 
+	if p.trickleDone != nil {
+		select {
+		case <-p.trickleDone:
+		case <-time.After(5 * time.Second):
+			p.logger.Errorf("MetricsBatchProcessor trickle writer stop timeout")
+		}
+	}
+
 	p.running = false
 
 	// Close prepared statements
@@ -164,8 +216,21 @@ func (p *MetricsBatchProcessor) Stop() error {
 	return nil
 }
 
-// AddMetric adds a metric to the processing queue
+// AddMetric adds a metric to the processing queue. Tags beyond the
+// configured cardinality limit are collapsed to HighCardinalityValue.
 func (p *MetricsBatchProcessor) AddMetric(metric *PipelineMetric) error {
+	tags, collapsed, exceeded := p.cardinality.apply(metric.MetricName, metric.Tags)
+	for _, key := range exceeded {
+		p.logger.Printf("WARNING: metric %q tag %q exceeded %d distinct values; collapsing new values to %q",
+			metric.MetricName, key, p.cardinality.limit, HighCardinalityValue)
+	}
+	if collapsed {
+		// Copy so the caller's metric is left untouched
+		guarded := *metric
+		guarded.Tags = tags
+		metric = &guarded
+	}
+
 	select {
 	case p.metricBuffer <- metric:
 		return nil
@@ -216,7 +281,7 @@ func (p *MetricsBatchProcessor) GetStats() *BatchProcessorStats {
 	bufferSize := len(p.batchBuffer)
 	p.bufferMutex.RUnlock()
 
-	return &BatchProcessorStats{
+	stats := &BatchProcessorStats{
 		ProcessedCount:   p.processedCount,
 		ErrorCount:       p.errorCount,
 		RetryCount:       p.retryCount,
@@ -224,7 +289,16 @@ func (p *MetricsBatchProcessor) GetStats() *BatchProcessorStats {
 		QueueSize:        len(p.processingQueue),
 		RetryQueueSize:   len(p.errorRetryQueue),
 		MetricBufferSize: len(p.metricBuffer),
+		WriteMode:        p.writeMode,
+		SubBatchCount:    p.subBatchCount,
+
+		HighCardinalityDrops: p.cardinality.dropCount(),
+	}
+	if p.insertPool != nil {
+		poolStats := p.insertPool.Stats()
+		stats.InsertPool = &poolStats
 	}
+	return stats
 }
 
 // prepareStatements prepares SQL statements for batch operations
@@ -291,19 +365,7 @@ func (p *MetricsBatchProcessor) runBatchProcessor() {
 	for {
 		select {
 		case batch := <-p.processingQueue:
-			if err := p.processBatch(batch); err != nil {
-				p.logger.Errorf("Failed to process batch: %v", err)
-
-				// Add to retry queue
-				select {
-				case p.errorRetryQueue <- batch:
-				default:
-					p.logger.Errorf("Retry queue full, dropping batch of %d metrics", len(batch))
-					p.incrementErrorCount(int64(len(batch)))
-				}
-			} else {
-				p.incrementProcessedCount(int64(len(batch)))
-			}
+			p.writeBatch(batch)
 
 		case <-p.stopChan:
 			// Process remaining batches
@@ -334,11 +396,21 @@ func (p *MetricsBatchProcessor) runRetryProcessor() {
 	}
 }
 
-// processBatch processes a batch of metrics
+// processBatch processes a batch of metrics, waiting for a slot in the
+// insert pool if there is one
 func (p *MetricsBatchProcessor) processBatch(batch []*PipelineMetric) error {
 	if len(batch) == 0 {
 		return nil
 	}
+	if p.insertPool != nil {
+		return p.insertPool.Do(func() error { return p.insertBatch(batch) })
+	}
+	return p.insertBatch(batch)
+}
+
+// insertBatch writes a batch of metrics in one transaction
+func (p *MetricsBatchProcessor) insertBatch(batch []*PipelineMetric) error {
+	defer p.slowQueries.observe("FlushMetricsBatch", "", time.Now())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -440,4 +512,11 @@ type BatchProcessorStats struct {
 	QueueSize        int   `json:"queue_size"`
 	RetryQueueSize   int   `json:"retry_queue_size"`
 	MetricBufferSize int   `json:"metric_buffer_size"`
+
+	WriteMode     MetricsWriteMode `json:"write_mode"`
+	SubBatchCount int64            `json:"sub_batch_count"` // trickle sub-batches written
+
+	HighCardinalityDrops int64 `json:"high_cardinality_drops"` // tag values collapsed by the cardinality guard
+
+	InsertPool *InsertPoolStats `json:"insert_pool,omitempty"` // shared insert pool, if any
 }
@@ -503,3 +503,197 @@ func TestMetricsBatchProcessor_BufferFull(t *testing.T) {
 		}
 	}
 }
+
+func TestMetricsBatchProcessor_CardinalityGuard(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := &DatabaseConfig{
+		MetricsBatchSize:           5000,
+		MetricsFlushInterval:       time.Hour,
+		MetricsTagCardinalityLimit: 100,
+	}
+	_, err = db.Exec(`CREATE TABLE pipeline_metrics (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		pipeline_id TEXT NOT NULL,
+		metric_name TEXT NOT NULL,
+		metric_type TEXT NOT NULL,
+		metric_value REAL NOT NULL,
+		tags TEXT,
+		metadata TEXT,
+		timestamp DATETIME NOT NULL
+	)`)
+	require.NoError(t, err)
+
+	processor, err := NewMetricsBatchProcessor(db, config)
+	require.NoError(t, err)
+	logger := &testLogger{}
+	processor.SetLogger(logger)
+
+	const total = 3000
+	for i := 0; i < total; i++ {
+		tags := map[string]string{
+			"started_at": fmt.Sprintf("%d", time.Now().UnixNano()+int64(i)),
+			"source":     "youtube",
+		}
+		require.NoError(t, processor.AddMetric(&PipelineMetric{
+			PipelineID:  "test-pipeline-1",
+			MetricName:  "stream_latency",
+			MetricType:  "gauge",
+			MetricValue: float64(i),
+			Tags:        tags,
+			Timestamp:   time.Now(),
+		}))
+
+		if i >= config.MetricsTagCardinalityLimit {
+			assert.NotEqual(t, HighCardinalityValue, tags["started_at"], "caller's tags must not be mutated")
+		}
+	}
+
+	stats := processor.GetStats()
+	assert.Equal(t, int64(total-config.MetricsTagCardinalityLimit), stats.HighCardinalityDrops)
+
+	// Drain the buffer and check what would be written
+	distinct := make(map[string]struct{})
+	collapsed := 0
+	for i := 0; i < total; i++ {
+		metric := <-processor.metricBuffer
+		assert.Equal(t, "youtube", metric.Tags["source"], "low-cardinality tags pass through")
+		if metric.Tags["started_at"] == HighCardinalityValue {
+			collapsed++
+		}
+		distinct[metric.Tags["started_at"]] = struct{}{}
+	}
+	assert.Equal(t, total-config.MetricsTagCardinalityLimit, collapsed)
+	assert.Len(t, distinct, config.MetricsTagCardinalityLimit+1)
+
+	// The warning is logged once per metric and tag
+	warnings := 0
+	for _, message := range logger.GetMessages() {
+		if strings.Contains(message, "exceeded") {
+			warnings++
+			assert.Contains(t, message, "started_at")
+		}
+	}
+	assert.Equal(t, 1, warnings)
+}
+
+func TestMetricsBatchProcessor_CardinalityGuardDisabled(t *testing.T) {
+	guard := newCardinalityGuard(0)
+	for i := 0; i < 50; i++ {
+		tags := map[string]string{"id": fmt.Sprintf("%d", i)}
+		got, collapsed, exceeded := guard.apply("metric", tags)
+		assert.False(t, collapsed)
+		assert.Empty(t, exceeded)
+		assert.Equal(t, tags, got)
+	}
+	assert.Zero(t, guard.dropCount())
+}
+
+func TestMetricsBatchProcessor_CardinalityGuardExemptTags(t *testing.T) {
+	guard := newCardinalityGuard(10)
+	for i := 0; i < 50; i++ {
+		tags := map[string]string{
+			"pipeline_id": fmt.Sprintf("pipeline-%d", i),
+			"config_hash": fmt.Sprintf("%012d", i),
+		}
+		got, collapsed, exceeded := guard.apply("metric", tags)
+		assert.False(t, collapsed)
+		assert.Empty(t, exceeded)
+		assert.Equal(t, tags, got)
+	}
+	assert.Zero(t, guard.dropCount())
+}
+
+func TestMetricsBatchProcessor_TrickleMode(t *testing.T) {
+	processor, db, cleanup := setupTestBatchProcessor(t)
+	defer cleanup()
+
+	processor.writeMode = MetricsWriteTrickle
+	processor.trickleBatchSize = 3
+
+	err := processor.Start()
+	require.NoError(t, err)
+
+	// A full batch of 10 is written as 4 sub-batches over the flush interval
+	for i := 0; i < 10; i++ {
+		err = processor.AddMetric(&PipelineMetric{
+			PipelineID:  "test-pipeline-1",
+			MetricName:  fmt.Sprintf("metric_%d", i),
+			MetricType:  "counter",
+			MetricValue: float64(i),
+			Tags:        map[string]string{},
+			Metadata:    map[string]interface{}{},
+			Timestamp:   time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool {
+		return processor.GetStats().ProcessedCount == 10
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var count int
+	err = db.QueryRow("SELECT COUNT(*) FROM pipeline_metrics WHERE pipeline_id = ?",
+		"test-pipeline-1").Scan(&count)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, count)
+
+	stats := processor.GetStats()
+	assert.Equal(t, MetricsWriteTrickle, stats.WriteMode)
+	assert.Equal(t, int64(4), stats.SubBatchCount)
+	assert.Equal(t, int64(0), stats.ErrorCount)
+}
+
+func TestMetricsBatchProcessor_TricklePacingDoesNotBlockProcessor(t *testing.T) {
+	processor, db, cleanup := setupTestBatchProcessor(t)
+	defer cleanup()
+
+	// Pace so slowly that only the first sub-batch is written during the test
+	processor.writeMode = MetricsWriteTrickle
+	processor.trickleBatchSize = 3
+	processor.flushInterval = time.Hour
+
+	require.NoError(t, processor.Start())
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, processor.AddMetric(&PipelineMetric{
+			PipelineID:  "test-pipeline-1",
+			MetricName:  fmt.Sprintf("metric_%d", i),
+			MetricType:  "counter",
+			MetricValue: float64(i),
+			Timestamp:   time.Now(),
+		}))
+	}
+
+	// Both batches leave the processing queue while the writer is pacing
+	require.Eventually(t, func() bool {
+		return len(processor.processingQueue) == 0 && len(processor.trickleQueue) == 7
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(3), processor.GetStats().ProcessedCount)
+
+	// Stopping writes the rest without waiting out the pacing
+	require.NoError(t, processor.Stop())
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM pipeline_metrics").Scan(&count))
+	assert.Equal(t, 20, count)
+	assert.Equal(t, int64(8), processor.GetStats().SubBatchCount)
+}
+
+func TestMetricsBatchProcessor_WriteModeDefaults(t *testing.T) {
+	processor, _, cleanup := setupTestBatchProcessor(t)
+	defer cleanup()
+
+	assert.Equal(t, MetricsWriteOneShot, processor.GetStats().WriteMode)
+	assert.Equal(t, DefaultMetricsTrickleBatchSize, processor.trickleBatchSize)
+
+	chunks := splitBatch(make([]*PipelineMetric, 7), 3)
+	require.Len(t, chunks, 3)
+	assert.Len(t, chunks[2], 1)
+
+	config := DefaultDatabaseConfig()
+	config.MetricsWriteMode = "burst"
+	assert.ErrorIs(t, config.Validate(), ErrInvalidMetricsWriteMode)
+}
@@ -0,0 +1,111 @@
+package database
+
+import "sync"
+
+// HighCardinalityValue replaces tag values once a tag exceeds its cardinality limit
+const HighCardinalityValue = "<high-cardinality>"
+
+// DefaultMetricsTagCardinalityLimit is the number of distinct values a tag may
+// take per metric name before new values are collapsed
+const DefaultMetricsTagCardinalityLimit = 1000
+
+// cardinalityExemptTags are tag keys never collapsed: they identify what a
+// metric belongs to, and reports group by them, so a collapsed value would
+// merge unrelated pipelines or config versions
+var cardinalityExemptTags = map[string]bool{
+	"pipeline_id": true,
+	"config_hash": true,
+}
+
+// cardinalityGuard tracks distinct tag values per metric name and collapses
+// values beyond the limit, so a tag fed unbounded data (timestamps, IDs) can't
+// grow the metrics table without bound. Values seen before the limit was hit
+// keep passing through unchanged.
+type cardinalityGuard struct {
+	limit int
+
+	mu     sync.Mutex
+	seen   map[string]map[string]map[string]struct{} // metric name -> tag key -> values
+	warned map[string]map[string]bool                // metric name -> tag key
+	drops  int64
+}
+
+// newCardinalityGuard creates a guard; a limit of zero or less disables it
+func newCardinalityGuard(limit int) *cardinalityGuard {
+	return &cardinalityGuard{
+		limit:  limit,
+		seen:   make(map[string]map[string]map[string]struct{}),
+		warned: make(map[string]map[string]bool),
+	}
+}
+
+// apply returns the metric's tags with high-cardinality values collapsed and
+// whether anything changed; the map is copied only when it does. The last
+// return value lists tag keys that crossed the limit for the first time, for a
+// one-off warning.
+func (g *cardinalityGuard) apply(metricName string, tags map[string]string) (map[string]string, bool, []string) {
+	if g.limit <= 0 || len(tags) == 0 {
+		return tags, false, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	byTag, ok := g.seen[metricName]
+	if !ok {
+		byTag = make(map[string]map[string]struct{})
+		g.seen[metricName] = byTag
+	}
+
+	var collapsed map[string]string
+	var newlyExceeded []string
+	for key, value := range tags {
+		if cardinalityExemptTags[key] {
+			continue
+		}
+
+		values, ok := byTag[key]
+		if !ok {
+			values = make(map[string]struct{})
+			byTag[key] = values
+		}
+
+		if _, known := values[value]; known {
+			continue
+		}
+		if len(values) < g.limit {
+			values[value] = struct{}{}
+			continue
+		}
+
+		// Over the limit: collapse this value
+		if collapsed == nil {
+			collapsed = make(map[string]string, len(tags))
+			for k, v := range tags {
+				collapsed[k] = v
+			}
+		}
+		collapsed[key] = HighCardinalityValue
+		g.drops++
+
+		if !g.warned[metricName][key] {
+			if g.warned[metricName] == nil {
+				g.warned[metricName] = make(map[string]bool)
+			}
+			g.warned[metricName][key] = true
+			newlyExceeded = append(newlyExceeded, key)
+		}
+	}
+
+	if collapsed == nil {
+		return tags, false, newlyExceeded
+	}
+	return collapsed, true, newlyExceeded
+}
+
+// dropCount returns how many tag values have been collapsed
+func (g *cardinalityGuard) dropCount() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.drops
+}
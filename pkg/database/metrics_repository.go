@@ -17,6 +17,7 @@ type metricsRepository struct {
 	// Enhanced components
 	batchProcessor   *MetricsBatchProcessor
 	retentionManager *MetricsRetentionManager
+	rollupManager    *MetricsRollupManager
 	sessionQueries   *SessionQueryExtensions
 
 	// Prepared statements for performance
@@ -58,6 +59,11 @@ func NewMetricsRepository(db *sql.DB, config *DatabaseConfig) (MetricsRepository
 	retentionManager := NewMetricsRetentionManager(db, config)
 	repo.retentionManager = retentionManager
 
+	// Initialize rollup manager
+	if config == nil || config.MetricsRollupEnabled {
+		repo.rollupManager = NewMetricsRollupManager(db, config)
+	}
+
 	// Initialize session query extensions
 	repo.sessionQueries = NewSessionQueryExtensions(db)
 
@@ -71,6 +77,14 @@ func NewMetricsRepository(db *sql.DB, config *DatabaseConfig) (MetricsRepository
 		return nil, fmt.Errorf("failed to start retention manager: %w", err)
 	}
 
+	if repo.rollupManager != nil {
+		if err := repo.rollupManager.Start(); err != nil {
+			repo.retentionManager.Stop()
+			repo.batchProcessor.Stop()
+			return nil, fmt.Errorf("failed to start rollup manager: %w", err)
+		}
+	}
+
 	return repo, nil
 }
 
@@ -104,6 +118,20 @@ func (r *metricsRepository) initializeTables() error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
 
+		`CREATE TABLE IF NOT EXISTS pipeline_metric_rollups (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			bucket_start DATETIME NOT NULL,
+			bucket_size_seconds INTEGER NOT NULL,
+			metric_name TEXT NOT NULL,
+			sample_count INTEGER NOT NULL,
+			sum_value REAL NOT NULL,
+			min_value REAL NOT NULL,
+			max_value REAL NOT NULL,
+			avg_value REAL NOT NULL,
+			updated_at DATETIME NOT NULL,
+			UNIQUE(bucket_start, bucket_size_seconds, metric_name)
+		)`,
+
 		`CREATE TABLE IF NOT EXISTS pipeline_events (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			pipeline_id TEXT NOT NULL,
@@ -133,6 +161,16 @@ func (r *metricsRepository) initializeTables() error {
 		}
 	}
 
+	// Compression marker for event_data, also added by migration 5
+	if err := addEventCompressedColumn(r.db); err != nil {
+		return err
+	}
+
+	// Session quality score, also added by migration 7
+	if err := addSessionQualityColumn(r.db); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -160,8 +198,8 @@ func (r *metricsRepository) prepareStatements() error {
 
 	// Prepare insert event statement
 	r.insertEventStmt, err = r.db.Prepare(`
-		INSERT INTO pipeline_events (pipeline_id, event_type, event_data, severity, timestamp)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO pipeline_events (pipeline_id, event_type, event_data, severity, timestamp, compressed)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare insert event statement: %w", err)
@@ -170,7 +208,10 @@ func (r *metricsRepository) prepareStatements() error {
 	// Prepare update session statement
 	r.updateSessionStmt, err = r.db.Prepare(`
 		UPDATE pipeline_sessions 
-		SET ended_at = ?, final_state = ?, total_errors = ?, total_recoveries = ?
+		SET ended_at = ?, final_state = ?,
+		    total_errors = COALESCE(?, total_errors),
+		    total_recoveries = COALESCE(?, total_recoveries),
+		    quality_score = COALESCE(?, quality_score)
 		WHERE pipeline_id = ?
 	`)
 	if err != nil {
@@ -285,6 +326,7 @@ func (r *metricsRepository) storeBatchMetricsDirect(ctx context.Context, metrics
 // GetMetrics retrieves metrics based on query parameters
 func (r *metricsRepository) GetMetrics(ctx context.Context, query *MetricsQuery) ([]*PipelineMetric, error) {
 	sqlQuery, args := r.buildMetricsQuery(query)
+	defer r.batchProcessor.slowQueries.observe("GetMetrics", sqlQuery, time.Now())
 
 	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
@@ -294,45 +336,166 @@ func (r *metricsRepository) GetMetrics(ctx context.Context, query *MetricsQuery)
 
 	var metrics []*PipelineMetric
 	for rows.Next() {
-		metric := &PipelineMetric{}
-		var tagsJSON, metadataJSON string
-
-		err := rows.Scan(
-			&metric.ID,
-			&metric.PipelineID,
-			&metric.MetricName,
-			&metric.MetricType,
-			&metric.MetricValue,
-			&tagsJSON,
-			&metadataJSON,
-			&metric.Timestamp,
-			&metric.CreatedAt,
-		)
+		metric, err := scanMetric(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan metric: %w", err)
+			return nil, err
 		}
+		metrics = append(metrics, metric)
+	}
 
-		if err := json.Unmarshal([]byte(tagsJSON), &metric.Tags); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating metrics: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// GetLatestMetric returns the most recent value of a metric for a pipeline,
+// or ErrMetricNotFound if none has been recorded. The lookup is served by the
+// (pipeline_id, metric_name) index.
+func (r *metricsRepository) GetLatestMetric(ctx context.Context, pipelineID, name string) (*PipelineMetric, error) {
+	query := `
+		SELECT id, pipeline_id, metric_name, metric_type, metric_value, tags, metadata, timestamp, created_at
+		FROM pipeline_metrics
+		WHERE pipeline_id = ? AND metric_name = ?
+		ORDER BY timestamp DESC, id DESC
+		LIMIT 1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pipelineID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest metric: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error reading latest metric: %w", err)
 		}
+		return nil, ErrMetricNotFound
+	}
 
-		if err := json.Unmarshal([]byte(metadataJSON), &metric.Metadata); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	return scanMetric(rows)
+}
+
+// GetTopMetrics ranks pipelines by their highest value of a metric since the
+// given time, largest first. The name and timestamp filter is served by the
+// idx_pipeline_metrics_name_timestamp index. A limit of 0 or less means 10.
+func (r *metricsRepository) GetTopMetrics(ctx context.Context, name string, since time.Time, limit int) ([]PipelineMetricTop, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	query := `
+		SELECT pipeline_id, MAX(metric_value) AS value, COUNT(*) AS samples
+		FROM pipeline_metrics
+		WHERE metric_name = ? AND timestamp >= ?
+		GROUP BY pipeline_id
+		ORDER BY value DESC, pipeline_id
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, name, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var top []PipelineMetricTop
+	for rows.Next() {
+		var entry PipelineMetricTop
+		if err := rows.Scan(&entry.PipelineID, &entry.Value, &entry.Samples); err != nil {
+			return nil, fmt.Errorf("failed to scan top metric: %w", err)
 		}
+		top = append(top, entry)
+	}
 
-		metrics = append(metrics, metric)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top metrics: %w", err)
+	}
+
+	return top, nil
+}
+
+// DetectGaps finds where a pipeline went quiet over the last window: every
+// pair of consecutive metric timestamps further apart than expectedInterval.
+// A gap in a session that was never ended usually means the process crashed.
+func (r *metricsRepository) DetectGaps(ctx context.Context, pipelineID string, expectedInterval, window time.Duration) ([]Gap, error) {
+	if expectedInterval <= 0 || window <= 0 {
+		return nil, ErrInvalidGapInterval
+	}
+
+	query := `
+		SELECT timestamp
+		FROM pipeline_metrics
+		WHERE pipeline_id = ? AND timestamp >= ?
+		ORDER BY timestamp
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pipelineID, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric timestamps: %w", err)
+	}
+	defer rows.Close()
+
+	var gaps []Gap
+	var previous time.Time
+	for rows.Next() {
+		var timestamp time.Time
+		if err := rows.Scan(&timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan metric timestamp: %w", err)
+		}
+
+		if !previous.IsZero() {
+			if elapsed := timestamp.Sub(previous); elapsed > expectedInterval {
+				gaps = append(gaps, Gap{Start: previous, End: timestamp, Duration: elapsed})
+			}
+		}
+		previous = timestamp
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating metrics: %w", err)
+		return nil, fmt.Errorf("error iterating metric timestamps: %w", err)
 	}
 
-	return metrics, nil
+	return gaps, nil
+}
+
+// scanMetric scans a pipeline_metrics row into a PipelineMetric
+func scanMetric(rows *sql.Rows) (*PipelineMetric, error) {
+	metric := &PipelineMetric{}
+	var tagsJSON, metadataJSON string
+
+	err := rows.Scan(
+		&metric.ID,
+		&metric.PipelineID,
+		&metric.MetricName,
+		&metric.MetricType,
+		&metric.MetricValue,
+		&tagsJSON,
+		&metadataJSON,
+		&metric.Timestamp,
+		&metric.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan metric: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(tagsJSON), &metric.Tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(metadataJSON), &metric.Metadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+
+	return metric, nil
 }
 
 // GetAggregatedMetrics retrieves aggregated metrics
 func (r *metricsRepository) GetAggregatedMetrics(ctx context.Context, query *AggregationQuery) (*AggregatedMetrics, error) {
 	sqlQuery, args := r.buildAggregationQuery(query)
+	defer r.batchProcessor.slowQueries.observe("GetAggregatedMetrics", sqlQuery, time.Now())
 
 	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
@@ -403,6 +566,7 @@ func (r *metricsRepository) UpdateSession(ctx context.Context, sessionID string,
 		updates.FinalState,
 		updates.TotalErrors,
 		updates.TotalRecoveries,
+		updates.QualityScore,
 		sessionID,
 	)
 
@@ -417,7 +581,7 @@ func (r *metricsRepository) UpdateSession(ctx context.Context, sessionID string,
 func (r *metricsRepository) GetSession(ctx context.Context, sessionID string) (*PipelineSession, error) {
 	query := `
 		SELECT pipeline_id, guild_id, channel_id, user_id, stream_url, started_at, ended_at, 
-		       final_state, total_errors, total_recoveries, created_at
+		       final_state, total_errors, total_recoveries, quality_score, created_at
 		FROM pipeline_sessions 
 		WHERE pipeline_id = ?
 	`
@@ -425,6 +589,7 @@ func (r *metricsRepository) GetSession(ctx context.Context, sessionID string) (*
 	session := &PipelineSession{}
 	var finalState sql.NullString
 	var totalErrors, totalRecoveries sql.NullInt64
+	var qualityScore sql.NullFloat64
 	err := r.db.QueryRowContext(ctx, query, sessionID).Scan(
 		&session.PipelineID,
 		&session.GuildID,
@@ -436,6 +601,7 @@ func (r *metricsRepository) GetSession(ctx context.Context, sessionID string) (*
 		&finalState,
 		&totalErrors,
 		&totalRecoveries,
+		&qualityScore,
 		&session.CreatedAt,
 	)
 
@@ -455,6 +621,9 @@ func (r *metricsRepository) GetSession(ctx context.Context, sessionID string) (*
 	if totalRecoveries.Valid {
 		session.TotalRecoveries = int(totalRecoveries.Int64)
 	}
+	if qualityScore.Valid {
+		session.QualityScore = &qualityScore.Float64
+	}
 
 	return session, nil
 }
@@ -463,7 +632,7 @@ func (r *metricsRepository) GetSession(ctx context.Context, sessionID string) (*
 func (r *metricsRepository) GetActiveSessions(ctx context.Context) ([]*PipelineSession, error) {
 	query := `
 		SELECT pipeline_id, guild_id, channel_id, user_id, stream_url, started_at, ended_at, 
-		       final_state, total_errors, total_recoveries, created_at
+		       final_state, total_errors, total_recoveries, quality_score, created_at
 		FROM pipeline_sessions 
 		WHERE ended_at IS NULL
 		ORDER BY started_at DESC
@@ -480,6 +649,7 @@ func (r *metricsRepository) GetActiveSessions(ctx context.Context) ([]*PipelineS
 		session := &PipelineSession{}
 		var finalState sql.NullString
 		var totalErrors, totalRecoveries sql.NullInt64
+		var qualityScore sql.NullFloat64
 		err := rows.Scan(
 			&session.PipelineID,
 			&session.GuildID,
@@ -491,6 +661,7 @@ func (r *metricsRepository) GetActiveSessions(ctx context.Context) ([]*PipelineS
 			&finalState,
 			&totalErrors,
 			&totalRecoveries,
+			&qualityScore,
 			&session.CreatedAt,
 		)
 		if err != nil {
@@ -506,6 +677,9 @@ func (r *metricsRepository) GetActiveSessions(ctx context.Context) ([]*PipelineS
 		if totalRecoveries.Valid {
 			session.TotalRecoveries = int(totalRecoveries.Int64)
 		}
+		if qualityScore.Valid {
+			session.QualityScore = &qualityScore.Float64
+		}
 
 		sessions = append(sessions, session)
 	}
@@ -524,12 +698,27 @@ func (r *metricsRepository) StoreEvent(ctx context.Context, event *PipelineEvent
 		return fmt.Errorf("failed to marshal event data: %w", err)
 	}
 
+	// Large payloads are stored as a gzip blob when compression is enabled
+	var eventData interface{} = string(eventDataJSON)
+	compressed := false
+	if r.config != nil && r.config.EventCompression {
+		var data []byte
+		data, compressed, err = compressEventData(eventDataJSON, r.config.EventCompressionThreshold)
+		if err != nil {
+			return err
+		}
+		if compressed {
+			eventData = data
+		}
+	}
+
 	_, err = r.insertEventStmt.ExecContext(ctx,
 		event.PipelineID,
 		event.EventType,
-		string(eventDataJSON),
+		eventData,
 		event.Severity,
 		event.Timestamp,
+		compressed,
 	)
 
 	if err != nil {
@@ -542,6 +731,7 @@ func (r *metricsRepository) StoreEvent(ctx context.Context, event *PipelineEvent
 // GetEvents retrieves events based on query parameters
 func (r *metricsRepository) GetEvents(ctx context.Context, query *EventQuery) ([]*PipelineEvent, error) {
 	sqlQuery, args := r.buildEventQuery(query)
+	defer r.batchProcessor.slowQueries.observe("GetEvents", sqlQuery, time.Now())
 
 	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
 	if err != nil {
@@ -551,24 +741,9 @@ func (r *metricsRepository) GetEvents(ctx context.Context, query *EventQuery) ([
 
 	var events []*PipelineEvent
 	for rows.Next() {
-		event := &PipelineEvent{}
-		var eventDataJSON string
-
-		err := rows.Scan(
-			&event.ID,
-			&event.PipelineID,
-			&event.EventType,
-			&eventDataJSON,
-			&event.Severity,
-			&event.Timestamp,
-			&event.CreatedAt,
-		)
+		event, err := scanEvent(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
-		}
-
-		if err := json.Unmarshal([]byte(eventDataJSON), &event.EventData); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+			return nil, err
 		}
 
 		events = append(events, event)
@@ -581,6 +756,72 @@ func (r *metricsRepository) GetEvents(ctx context.Context, query *EventQuery) ([
 	return events, nil
 }
 
+// IterateEvents streams events matching the query to fn one row at a time
+// instead of loading them all into memory. Iteration stops at the first
+// error returned by fn, which is returned as is, or when ctx is cancelled.
+func (r *metricsRepository) IterateEvents(ctx context.Context, query *EventQuery, fn func(*PipelineEvent) error) error {
+	sqlQuery, args := r.buildEventQuery(query)
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating events: %w", err)
+	}
+
+	return nil
+}
+
+// scanEvent scans a row produced by buildEventQuery into a PipelineEvent
+func scanEvent(rows *sql.Rows) (*PipelineEvent, error) {
+	event := &PipelineEvent{}
+	var eventData []byte
+	var compressed bool
+
+	err := rows.Scan(
+		&event.ID,
+		&event.PipelineID,
+		&event.EventType,
+		&eventData,
+		&event.Severity,
+		&event.Timestamp,
+		&event.CreatedAt,
+		&compressed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan event: %w", err)
+	}
+
+	eventDataJSON, err := decodeEventData(eventData, compressed)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(eventDataJSON, &event.EventData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+
+	return event, nil
+}
+
 // CleanExpiredMetrics removes metrics older than the retention period
 func (r *metricsRepository) CleanExpiredMetrics(ctx context.Context, retentionPeriod time.Duration) error {
 	cutoffTime := time.Now().Add(-retentionPeriod)
@@ -798,7 +1039,7 @@ func (r *metricsRepository) buildAggregationQuery(query *AggregationQuery) (stri
 // buildEventQuery builds a SQL query for events retrieval
 func (r *metricsRepository) buildEventQuery(query *EventQuery) (string, []interface{}) {
 	sqlQuery := `
-		SELECT id, pipeline_id, event_type, event_data, severity, timestamp, created_at
+		SELECT id, pipeline_id, event_type, event_data, severity, timestamp, created_at, compressed
 		FROM pipeline_events
 		WHERE 1=1
 	`
@@ -884,6 +1125,30 @@ func (r *metricsRepository) RunRetentionCleanup(ctx context.Context) (*Retention
 	return r.retentionManager.RunCleanup(ctx)
 }
 
+// GetRollupStats returns statistics from the rollup manager
+func (r *metricsRepository) GetRollupStats() (*RollupStats, error) {
+	if r.rollupManager == nil {
+		return nil, fmt.Errorf("rollup manager not initialized")
+	}
+	return r.rollupManager.GetStats(), nil
+}
+
+// RunMetricsRollup manually triggers a rollup of the latest buckets
+func (r *metricsRepository) RunMetricsRollup(ctx context.Context) (int, error) {
+	if r.rollupManager == nil {
+		return 0, fmt.Errorf("rollup manager not initialized")
+	}
+	return r.rollupManager.RunRollup(ctx)
+}
+
+// GetMetricRollups returns pre-aggregated rows for a metric name within [start, end)
+func (r *metricsRepository) GetMetricRollups(ctx context.Context, name string, start, end time.Time) ([]*MetricRollup, error) {
+	if r.rollupManager == nil {
+		return nil, fmt.Errorf("rollup manager not initialized")
+	}
+	return r.rollupManager.GetRollups(ctx, name, start, end)
+}
+
 // Close gracefully shuts down the metrics repository and its components
 func (r *metricsRepository) Close() error {
 	var errors []string
@@ -902,6 +1167,13 @@ func (r *metricsRepository) Close() error {
 		}
 	}
 
+	// Stop rollup manager
+	if r.rollupManager != nil {
+		if err := r.rollupManager.Stop(); err != nil {
+			errors = append(errors, fmt.Sprintf("rollup manager stop error: %v", err))
+		}
+	}
+
 	// Close prepared statements
 	if r.insertMetricStmt != nil {
 		if err := r.insertMetricStmt.Close(); err != nil {
@@ -991,6 +1263,11 @@ func (r *metricsRepository) GetOrphanedSessions(ctx context.Context, cutoffTime
 	return r.sessionQueries.GetOrphanedSessions(ctx, cutoffTime)
 }
 
+// GetStaleSessions returns active sessions with no activity since lastActivityBefore
+func (r *metricsRepository) GetStaleSessions(ctx context.Context, lastActivityBefore time.Time) ([]*StaleSession, error) {
+	return r.sessionQueries.GetStaleSessions(ctx, lastActivityBefore)
+}
+
 // GetSessionErrorRates calculates error rates for sessions
 func (r *metricsRepository) GetSessionErrorRates(ctx context.Context) (*SessionErrorRates, error) {
 	return r.sessionQueries.GetSessionErrorRates(ctx)
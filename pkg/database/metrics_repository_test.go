@@ -3,7 +3,9 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -100,6 +102,163 @@ func TestMetricsRepository_StoreAndGetMetric(t *testing.T) {
 	assert.Equal(t, metric.Metadata["source"], retrieved.Metadata["source"])
 }
 
+func TestMetricsRepository_GetLatestMetric(t *testing.T) {
+	repo, _, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour)
+
+	// Store out of order so the newest row is neither first nor last inserted
+	var metrics []*PipelineMetric
+	for _, offset := range []int{2, 5, 1, 3} {
+		metrics = append(metrics, &PipelineMetric{
+			PipelineID:  "latest-pipeline",
+			MetricName:  "queue_depth",
+			MetricType:  "gauge",
+			MetricValue: float64(offset),
+			Tags:        map[string]string{},
+			Metadata:    map[string]interface{}{},
+			Timestamp:   base.Add(time.Duration(offset) * time.Minute),
+		})
+	}
+	metrics = append(metrics, &PipelineMetric{
+		PipelineID:  "latest-pipeline",
+		MetricName:  "other_metric",
+		MetricType:  "gauge",
+		MetricValue: 99,
+		Tags:        map[string]string{},
+		Metadata:    map[string]interface{}{},
+		Timestamp:   base.Add(10 * time.Minute),
+	})
+	require.NoError(t, repo.(*metricsRepository).storeBatchMetricsDirect(ctx, metrics))
+
+	latest, err := repo.GetLatestMetric(ctx, "latest-pipeline", "queue_depth")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, latest.MetricValue)
+	assert.Equal(t, "queue_depth", latest.MetricName)
+
+	_, err = repo.GetLatestMetric(ctx, "latest-pipeline", "missing_metric")
+	assert.ErrorIs(t, err, ErrMetricNotFound)
+}
+
+func TestMetricsRepository_GetTopMetrics(t *testing.T) {
+	repo, _, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	since := time.Now().Add(-24 * time.Hour)
+
+	errorCount := func(pipelineID string, value float64, at time.Time) *PipelineMetric {
+		return &PipelineMetric{
+			PipelineID:  pipelineID,
+			MetricName:  "error_count",
+			MetricType:  "counter",
+			MetricValue: value,
+			Tags:        map[string]string{},
+			Metadata:    map[string]interface{}{},
+			Timestamp:   at,
+		}
+	}
+
+	recent := time.Now().Add(-time.Hour)
+	metrics := []*PipelineMetric{
+		errorCount("pipeline-a", 3, recent),
+		errorCount("pipeline-a", 7, recent.Add(time.Minute)),
+		errorCount("pipeline-b", 12, recent),
+		errorCount("pipeline-c", 1, recent),
+		errorCount("pipeline-d", 5, recent),
+		// Outside the window, so its high value doesn't count
+		errorCount("pipeline-e", 50, since.Add(-time.Hour)),
+		errorCount("pipeline-c", 40, since.Add(-time.Hour)),
+	}
+	metrics = append(metrics, &PipelineMetric{
+		PipelineID:  "pipeline-f",
+		MetricName:  "queue_depth",
+		MetricType:  "gauge",
+		MetricValue: 100,
+		Tags:        map[string]string{},
+		Metadata:    map[string]interface{}{},
+		Timestamp:   recent,
+	})
+	require.NoError(t, repo.(*metricsRepository).storeBatchMetricsDirect(ctx, metrics))
+
+	top, err := repo.GetTopMetrics(ctx, "error_count", since, 3)
+	require.NoError(t, err)
+	require.Len(t, top, 3)
+	assert.Equal(t, []PipelineMetricTop{
+		{PipelineID: "pipeline-b", Value: 12, Samples: 1},
+		{PipelineID: "pipeline-a", Value: 7, Samples: 2},
+		{PipelineID: "pipeline-d", Value: 5, Samples: 1},
+	}, top)
+
+	all, err := repo.GetTopMetrics(ctx, "error_count", since, 0)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, "pipeline-c", all[3].PipelineID)
+	assert.Equal(t, 1.0, all[3].Value)
+
+	none, err := repo.GetTopMetrics(ctx, "missing_metric", since, 10)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestMetricsRepository_DetectGaps(t *testing.T) {
+	repo, _, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+
+	sample := func(pipelineID string, at time.Time) *PipelineMetric {
+		return &PipelineMetric{
+			PipelineID:  pipelineID,
+			MetricName:  "frames_sent",
+			MetricType:  "counter",
+			MetricValue: 1,
+			Tags:        map[string]string{},
+			Metadata:    map[string]interface{}{},
+			Timestamp:   at,
+		}
+	}
+
+	// One sample a minute, with the process down for 20 minutes and again for 5
+	var metrics []*PipelineMetric
+	for _, minute := range []int{0, 1, 2, 3, 23, 24, 25, 30, 31} {
+		metrics = append(metrics, sample("gapped-pipeline", start.Add(time.Duration(minute)*time.Minute)))
+	}
+	// Another pipeline's samples don't fill the gaps
+	metrics = append(metrics, sample("other-pipeline", start.Add(10*time.Minute)))
+	// Outside the window
+	metrics = append(metrics, sample("gapped-pipeline", start.Add(-time.Hour)))
+	require.NoError(t, repo.(*metricsRepository).storeBatchMetricsDirect(ctx, metrics))
+
+	gaps, err := repo.DetectGaps(ctx, "gapped-pipeline", 2*time.Minute, 3*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, gaps, 2)
+
+	assert.True(t, gaps[0].Start.Equal(start.Add(3*time.Minute)))
+	assert.True(t, gaps[0].End.Equal(start.Add(23*time.Minute)))
+	assert.Equal(t, 20*time.Minute, gaps[0].Duration)
+
+	assert.True(t, gaps[1].Start.Equal(start.Add(25*time.Minute)))
+	assert.True(t, gaps[1].End.Equal(start.Add(30*time.Minute)))
+	assert.Equal(t, 5*time.Minute, gaps[1].Duration)
+
+	// A longer expected interval tolerates the shorter gap
+	gaps, err = repo.DetectGaps(ctx, "gapped-pipeline", 10*time.Minute, 3*time.Hour)
+	require.NoError(t, err)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, 20*time.Minute, gaps[0].Duration)
+
+	gaps, err = repo.DetectGaps(ctx, "other-pipeline", time.Minute, 3*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, gaps)
+
+	_, err = repo.DetectGaps(ctx, "gapped-pipeline", 0, time.Hour)
+	assert.ErrorIs(t, err, ErrInvalidGapInterval)
+}
+
 func TestMetricsRepository_StoreBatchMetrics(t *testing.T) {
 	repo, _, cleanup := setupTestMetricsRepository(t)
 	defer cleanup()
@@ -206,6 +365,91 @@ func TestMetricsRepository_GetAggregatedMetrics(t *testing.T) {
 	assert.NotEmpty(t, aggregated.Results)
 }
 
+func TestMetricsRepository_GetTimeSeries(t *testing.T) {
+	repo, _, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour).Truncate(time.Minute)
+
+	sample := func(minute int, value float64) *PipelineMetric {
+		return &PipelineMetric{
+			PipelineID:  "sparse-pipeline",
+			MetricName:  "buffer_health",
+			MetricType:  "gauge",
+			MetricValue: value,
+			Tags:        map[string]string{},
+			Metadata:    map[string]interface{}{},
+			Timestamp:   start.Add(time.Duration(minute) * time.Minute),
+		}
+	}
+
+	// Samples in 3 of 10 five-minute buckets, plus one past the end
+	metrics := []*PipelineMetric{
+		sample(1, 10), sample(3, 20),
+		sample(17, 40),
+		sample(45, 5), sample(46, 7), sample(49, 9),
+		sample(50, 100),
+	}
+	require.NoError(t, repo.(*metricsRepository).storeBatchMetricsDirect(ctx, metrics))
+
+	query := &TimeSeriesQuery{
+		PipelineID:  "sparse-pipeline",
+		MetricName:  "buffer_health",
+		Aggregation: "avg",
+		Start:       start,
+		End:         start.Add(50 * time.Minute),
+		Interval:    5 * time.Minute,
+	}
+
+	series, err := repo.GetTimeSeries(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, series.Points, 10, "every bucket should be present")
+	for i, point := range series.Points {
+		assert.True(t, point.Timestamp.Equal(start.Add(time.Duration(i)*5*time.Minute)), "bucket %d", i)
+	}
+
+	require.NotNil(t, series.Points[0].Value)
+	assert.Equal(t, 15.0, *series.Points[0].Value)
+	assert.Equal(t, 2, series.Points[0].Samples)
+	require.NotNil(t, series.Points[3].Value)
+	assert.Equal(t, 40.0, *series.Points[3].Value)
+	require.NotNil(t, series.Points[9].Value)
+	assert.Equal(t, 7.0, *series.Points[9].Value)
+
+	// Empty buckets are null without a fill
+	assert.Nil(t, series.Points[1].Value)
+	assert.Equal(t, 0, series.Points[1].Samples)
+
+	// and take the fill when one is given
+	zero := 0.0
+	query.Fill = &zero
+	query.Aggregation = "max"
+	series, err = repo.GetTimeSeries(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, series.Points, 10)
+	for i, point := range series.Points {
+		require.NotNil(t, point.Value, "bucket %d", i)
+	}
+	assert.Equal(t, 0.0, *series.Points[5].Value)
+	assert.Equal(t, 9.0, *series.Points[9].Value)
+
+	// Counts are zero in empty buckets either way
+	query.Fill = nil
+	query.Aggregation = "count"
+	series, err = repo.GetTimeSeries(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, *series.Points[9].Value)
+	assert.Equal(t, 0.0, *series.Points[4].Value)
+
+	_, err = repo.GetTimeSeries(ctx, &TimeSeriesQuery{MetricName: "buffer_health", Start: start, End: start, Interval: time.Minute})
+	assert.ErrorIs(t, err, ErrInvalidTimeSeries)
+	_, err = repo.GetTimeSeries(ctx, &TimeSeriesQuery{MetricName: "buffer_health", Start: start, End: start.Add(time.Hour)})
+	assert.ErrorIs(t, err, ErrInvalidTimeSeries)
+	_, err = repo.GetTimeSeries(ctx, &TimeSeriesQuery{MetricName: "buffer_health", Start: start, End: start.Add(time.Hour), Interval: time.Minute, Aggregation: "median"})
+	assert.ErrorIs(t, err, ErrInvalidAggregation)
+}
+
 func TestMetricsRepository_SessionManagement(t *testing.T) {
 	repo, _, cleanup := setupTestMetricsRepository(t)
 	defer cleanup()
@@ -299,6 +543,120 @@ func TestMetricsRepository_EventManagement(t *testing.T) {
 	assert.Equal(t, event.EventData["error_message"], retrieved.EventData["error_message"])
 }
 
+func TestMetricsRepository_EventCompression(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.EventCompression = true
+	config.EventCompressionThreshold = 512
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+
+	// A legacy row written before the compressed column was populated
+	_, err = db.Exec(`INSERT INTO pipeline_events (pipeline_id, event_type, event_data, severity, timestamp)
+		VALUES ('compress-pipeline', 'legacy', '{"note":"plain"}', 'low', ?)`, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	stackTrace := strings.Repeat("goroutine 1 [running]:\nmain.play()\n\t/app/audio.go:42 +0x1d\n", 200)
+	large := &PipelineEvent{
+		PipelineID: "compress-pipeline",
+		EventType:  "error",
+		EventData:  map[string]interface{}{"stack": stackTrace},
+		Severity:   "high",
+		Timestamp:  time.Now(),
+	}
+	require.NoError(t, repo.StoreEvent(ctx, large))
+
+	small := &PipelineEvent{
+		PipelineID: "compress-pipeline",
+		EventType:  "state_change",
+		EventData:  map[string]interface{}{"to": "streaming"},
+		Severity:   "low",
+		Timestamp:  time.Now().Add(time.Second),
+	}
+	require.NoError(t, repo.StoreEvent(ctx, small))
+
+	// Only the large payload is compressed, and it takes less space on disk
+	var storedSize int
+	var compressed bool
+	require.NoError(t, db.QueryRow(`SELECT length(event_data), compressed FROM pipeline_events WHERE event_type = 'error'`).Scan(&storedSize, &compressed))
+	assert.True(t, compressed)
+	assert.Less(t, storedSize, len(stackTrace)/10)
+
+	require.NoError(t, db.QueryRow(`SELECT compressed FROM pipeline_events WHERE event_type = 'state_change'`).Scan(&compressed))
+	assert.False(t, compressed)
+
+	events, err := repo.GetEvents(ctx, &EventQuery{PipelineID: "compress-pipeline", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	byType := make(map[string]*PipelineEvent)
+	for _, event := range events {
+		byType[event.EventType] = event
+	}
+	assert.Equal(t, stackTrace, byType["error"].EventData["stack"])
+	assert.Equal(t, "streaming", byType["state_change"].EventData["to"])
+	assert.Equal(t, "plain", byType["legacy"].EventData["note"])
+}
+
+func TestMetricsRepository_IterateEvents(t *testing.T) {
+	repo, _, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	base := time.Now()
+	for i := 0; i < 5; i++ {
+		err := repo.StoreEvent(ctx, &PipelineEvent{
+			PipelineID: "test-pipeline-1",
+			EventType:  "info",
+			EventData:  map[string]interface{}{"index": i},
+			Severity:   "low",
+			Timestamp:  base.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	query := &EventQuery{PipelineID: "test-pipeline-1"}
+
+	// Callback is invoked once per row
+	var seen []int64
+	err := repo.IterateEvents(ctx, query, func(event *PipelineEvent) error {
+		assert.Equal(t, "test-pipeline-1", event.PipelineID)
+		seen = append(seen, event.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, seen, 5)
+
+	// Returning an error halts iteration early
+	errStop := errors.New("stop")
+	calls := 0
+	err = repo.IterateEvents(ctx, query, func(event *PipelineEvent) error {
+		calls++
+		if calls == 2 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 2, calls)
+
+	// A cancelled context stops iteration
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = repo.IterateEvents(cancelled, query, func(event *PipelineEvent) error {
+		t.Fatal("callback should not run with a cancelled context")
+		return nil
+	})
+	assert.Error(t, err)
+}
+
 func TestMetricsRepository_CleanExpiredMetrics(t *testing.T) {
 	repo, _, cleanup := setupTestMetricsRepository(t)
 	defer cleanup()
@@ -387,3 +745,47 @@ func stringPtr(s string) *string {
 func intPtr(i int) *int {
 	return &i
 }
+
+func TestMetricsRepository_TopErrorTypesCountsCompressedEvents(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.EventCompression = true
+	config.EventCompressionThreshold = 512
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+	stackTrace := strings.Repeat("goroutine 1 [running]:\nmain.play()\n", 100)
+	store := func(errorType, stack string) {
+		require.NoError(t, repo.StoreEvent(ctx, &PipelineEvent{
+			PipelineID: "top-errors",
+			EventType:  "error",
+			EventData:  map[string]interface{}{"error_type": errorType, "stack": stack},
+			Severity:   "high",
+			Timestamp:  time.Now(),
+		}))
+	}
+
+	// Large payloads are compressed and must still be counted
+	store("ffmpeg", stackTrace)
+	store("ffmpeg", stackTrace)
+	store("ffmpeg", "")
+	store("network", "")
+	store("network", "")
+	store("voice", stackTrace)
+
+	var compressed int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pipeline_events WHERE compressed = 1`).Scan(&compressed))
+	require.Equal(t, 3, compressed)
+
+	top, err := repo.GetTopErrorTypes(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []ErrorTypeCount{
+		{ErrorType: "ffmpeg", Count: 3},
+		{ErrorType: "network", Count: 2},
+	}, top)
+}
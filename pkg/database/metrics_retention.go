@@ -31,7 +31,21 @@ type MetricsRetentionManager struct {
 	statsMutex      sync.RWMutex
 }
 
-// RetentionPolicy defines a cleanup policy for metrics
+// retentionTableColumns is the allowlist of tables and timestamp columns that
+// retention policies may target. Policy identifiers are interpolated into SQL,
+// so anything outside this list is rejected by AddPolicy.
+var retentionTableColumns = map[string][]string{
+	"pipeline_metrics":        {"timestamp", "created_at"},
+	"pipeline_events":         {"timestamp", "created_at"},
+	"pipeline_sessions":       {"started_at", "ended_at", "created_at"},
+	"pipeline_metric_rollups": {"bucket_start", "updated_at"},
+}
+
+// RetentionPolicy defines a cleanup policy for metrics.
+//
+// TableName and TimestampColumn are validated against a fixed allowlist.
+// Conditions are appended verbatim to the WHERE clause and are NOT validated;
+// they must only ever come from trusted code, never from user input.
 type RetentionPolicy struct {
 	Name            string        `json:"name"`
 	Description     string        `json:"description"`
@@ -82,8 +96,28 @@ func (m *MetricsRetentionManager) SetLogger(logger Logger) {
 	m.logger = logger
 }
 
-// AddPolicy adds a custom retention policy
-func (m *MetricsRetentionManager) AddPolicy(policy RetentionPolicy) {
+// Validate checks that the policy targets a known table and timestamp column
+func (p RetentionPolicy) Validate() error {
+	columns, ok := retentionTableColumns[p.TableName]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrInvalidRetentionTable, p.TableName)
+	}
+
+	for _, column := range columns {
+		if column == p.TimestampColumn {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q on table %q", ErrInvalidRetentionColumn, p.TimestampColumn, p.TableName)
+}
+
+// AddPolicy adds a custom retention policy after validating its identifiers
+func (m *MetricsRetentionManager) AddPolicy(policy RetentionPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid retention policy %q: %w", policy.Name, err)
+	}
+
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -103,6 +137,7 @@ func (m *MetricsRetentionManager) AddPolicy(policy RetentionPolicy) {
 	}
 
 	m.logger.Printf("Added retention policy: %s (priority: %d)", policy.Name, policy.Priority)
+	return nil
 }
 
 // RemovePolicy removes a retention policy by name
@@ -403,6 +438,15 @@ func getDefaultRetentionPolicies(config *DatabaseConfig) []RetentionPolicy {
 			Priority:        4,
 			Enabled:         true,
 		},
+		{
+			Name:            "rollups_retention",
+			Description:     "Clean up old metric rollups",
+			RetentionPeriod: config.MetricsRetention * 4, // Summaries outlive raw metrics
+			TableName:       "pipeline_metric_rollups",
+			TimestampColumn: "bucket_start",
+			Priority:        5,
+			Enabled:         true,
+		},
 	}
 
 	return policies
@@ -56,6 +56,19 @@ func setupTestRetentionManager(t *testing.T) (*MetricsRetentionManager, *sql.DB,
 			total_recoveries INTEGER DEFAULT 0,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 		)`,
+		`CREATE TABLE pipeline_metric_rollups (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			bucket_start DATETIME NOT NULL,
+			bucket_size_seconds INTEGER NOT NULL,
+			metric_name TEXT NOT NULL,
+			sample_count INTEGER NOT NULL,
+			sum_value REAL NOT NULL,
+			min_value REAL NOT NULL,
+			max_value REAL NOT NULL,
+			avg_value REAL NOT NULL,
+			updated_at DATETIME NOT NULL,
+			UNIQUE(bucket_start, bucket_size_seconds, metric_name)
+		)`,
 	}
 
 	for _, query := range queries {
@@ -86,7 +99,7 @@ func TestNewMetricsRetentionManager(t *testing.T) {
 
 	policies := manager.GetPolicies()
 	assert.NotEmpty(t, policies)
-	assert.Len(t, policies, 4) // Default policies
+	assert.Len(t, policies, 5) // Default policies
 
 	// Check default policies
 	policyNames := make(map[string]bool)
@@ -119,7 +132,7 @@ func TestMetricsRetentionManager_AddRemovePolicy(t *testing.T) {
 		Enabled:         true,
 	}
 
-	manager.AddPolicy(customPolicy)
+	require.NoError(t, manager.AddPolicy(customPolicy))
 
 	policies := manager.GetPolicies()
 	assert.Len(t, policies, initialCount+1)
@@ -140,6 +153,33 @@ func TestMetricsRetentionManager_AddRemovePolicy(t *testing.T) {
 	assert.False(t, removed)
 }
 
+func TestMetricsRetentionManager_AddPolicyRejectsInvalidIdentifiers(t *testing.T) {
+	manager, _, cleanup := setupTestRetentionManager(t)
+	defer cleanup()
+
+	initialCount := len(manager.GetPolicies())
+
+	err := manager.AddPolicy(RetentionPolicy{
+		Name:            "bad_table",
+		RetentionPeriod: time.Hour,
+		TableName:       "pipeline_metrics; DROP TABLE pipeline_sessions",
+		TimestampColumn: "timestamp",
+		Enabled:         true,
+	})
+	assert.ErrorIs(t, err, ErrInvalidRetentionTable)
+
+	err = manager.AddPolicy(RetentionPolicy{
+		Name:            "bad_column",
+		RetentionPeriod: time.Hour,
+		TableName:       "pipeline_events",
+		TimestampColumn: "started_at",
+		Enabled:         true,
+	})
+	assert.ErrorIs(t, err, ErrInvalidRetentionColumn)
+
+	assert.Len(t, manager.GetPolicies(), initialCount)
+}
+
 func TestMetricsRetentionManager_StartStop(t *testing.T) {
 	manager, _, cleanup := setupTestRetentionManager(t)
 	defer cleanup()
@@ -235,6 +275,29 @@ func TestMetricsRetentionManager_RunCleanup(t *testing.T) {
 	assert.True(t, found, "Should log cleanup start")
 }
 
+func TestMetricsRetentionManager_CleansRollups(t *testing.T) {
+	manager, db, cleanup := setupTestRetentionManager(t)
+	defer cleanup()
+
+	// Rollups outlive raw metrics: kept for four times MetricsRetention
+	for _, age := range []time.Duration{48 * time.Hour, 10 * 24 * time.Hour} {
+		bucket := time.Now().Add(-age).Truncate(time.Hour)
+		_, err := db.Exec(`
+			INSERT INTO pipeline_metric_rollups (bucket_start, bucket_size_seconds, metric_name, sample_count,
+				sum_value, min_value, max_value, avg_value, updated_at)
+			VALUES (?, 3600, 'latency_ms', 1, 1, 1, 1, 1, ?)
+		`, bucket, bucket)
+		require.NoError(t, err)
+	}
+
+	_, err := manager.RunCleanup(context.Background())
+	require.NoError(t, err)
+
+	var rollupCount int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM pipeline_metric_rollups").Scan(&rollupCount))
+	assert.Equal(t, 1, rollupCount)
+}
+
 func TestMetricsRetentionManager_PolicyExecution(t *testing.T) {
 	manager, db, cleanup := setupTestRetentionManager(t)
 	defer cleanup()
@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default rollup schedule used when the configuration leaves it unset
+const (
+	DefaultMetricsRollupInterval = 5 * time.Minute
+	DefaultMetricsRollupBucket   = time.Hour
+)
+
+// MetricRollup holds pre-aggregated values for one metric name over one bucket
+type MetricRollup struct {
+	BucketStart time.Time     `json:"bucket_start"`
+	BucketSize  time.Duration `json:"bucket_size"`
+	MetricName  string        `json:"metric_name"`
+	Count       int64         `json:"count"`
+	Sum         float64       `json:"sum"`
+	Min         float64       `json:"min"`
+	Max         float64       `json:"max"`
+	Avg         float64       `json:"avg"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// RollupStats holds statistics about rollup operations
+type RollupStats struct {
+	LastRunTime   time.Time `json:"last_run_time"`
+	LastUpserted  int       `json:"last_upserted"`
+	TotalUpserted int64     `json:"total_upserted"`
+	Runs          int64     `json:"runs"`
+}
+
+// MetricsRollupManager periodically aggregates raw pipeline metrics into
+// fixed-size buckets so chart queries can read pre-aggregated rows.
+//
+// Each run recomputes the current bucket and the one before it, so samples
+// flushed late into a just-closed bucket are still picked up. Rows are upserted
+// on (bucket_start, bucket_size_seconds, metric_name), which makes a run
+// idempotent: repeating it replaces the aggregates rather than duplicating them.
+type MetricsRollupManager struct {
+	db     *sql.DB
+	config *DatabaseConfig
+	logger Logger
+
+	interval time.Duration
+	bucket   time.Duration
+	now      func() time.Time
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+
+	// State
+	running bool
+	mutex   sync.RWMutex
+
+	// Statistics
+	lastRunTime   time.Time
+	lastUpserted  int
+	totalUpserted int64
+	runs          int64
+	statsMutex    sync.RWMutex
+}
+
+// NewMetricsRollupManager creates a new metrics rollup manager
+func NewMetricsRollupManager(db *sql.DB, config *DatabaseConfig) *MetricsRollupManager {
+	if config == nil {
+		config = DefaultDatabaseConfig()
+	}
+
+	interval := config.MetricsRollupInterval
+	if interval <= 0 {
+		interval = DefaultMetricsRollupInterval
+	}
+	bucket := config.MetricsRollupBucket
+	if bucket <= 0 {
+		bucket = DefaultMetricsRollupBucket
+	}
+
+	return &MetricsRollupManager{
+		db:       db,
+		config:   config,
+		logger:   &defaultLogger{},
+		interval: interval,
+		bucket:   bucket,
+		now:      time.Now,
+		stopChan: make(chan struct{}),
+		doneChan: make(chan struct{}),
+	}
+}
+
+// SetLogger sets a custom logger for the rollup manager
+func (m *MetricsRollupManager) SetLogger(logger Logger) {
+	m.logger = logger
+}
+
+// Start begins the rollup manager background process
+func (m *MetricsRollupManager) Start() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.running {
+		return fmt.Errorf("rollup manager is already running")
+	}
+
+	m.running = true
+	go m.runRollupLoop()
+
+	m.logger.Printf("MetricsRollupManager started with interval %v and bucket %v", m.interval, m.bucket)
+	return nil
+}
+
+// Stop gracefully shuts down the rollup manager
+func (m *MetricsRollupManager) Stop() error {
+	m.mutex.Lock()
+	if !m.running {
+		m.mutex.Unlock()
+		return nil
+	}
+	m.mutex.Unlock()
+
+	close(m.stopChan)
+
+	select {
+	case <-m.doneChan:
+		m.logger.Printf("MetricsRollupManager stopped gracefully")
+	case <-time.After(30 * time.Second):
+		m.logger.Errorf("MetricsRollupManager stop timeout")
+	}
+
+	m.mutex.Lock()
+	m.running = false
+	m.mutex.Unlock()
+
+	return nil
+}
+
+// RunRollup aggregates the latest buckets and returns the number of rows upserted
+//
+// Bucket bounds are bound in local time, as metrics are stored: SQLite
+// compares the stored timestamps as text, so bounds in another zone would
+// select the wrong rows.
+func (m *MetricsRollupManager) RunRollup(ctx context.Context) (int, error) {
+	current := m.now().Truncate(m.bucket).Local()
+	previous := current.Add(-m.bucket)
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin rollup transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upserted := 0
+	for _, start := range []time.Time{previous, current} {
+		n, err := m.rollupBucket(ctx, tx, start)
+		if err != nil {
+			return 0, err
+		}
+		upserted += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit rollup: %w", err)
+	}
+
+	m.statsMutex.Lock()
+	m.lastRunTime = m.now()
+	m.lastUpserted = upserted
+	m.totalUpserted += int64(upserted)
+	m.runs++
+	m.statsMutex.Unlock()
+
+	return upserted, nil
+}
+
+// GetRollups returns rollup rows for a metric name whose buckets start within [start, end)
+func (m *MetricsRollupManager) GetRollups(ctx context.Context, name string, start, end time.Time) ([]*MetricRollup, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT bucket_start, bucket_size_seconds, metric_name, sample_count,
+		       sum_value, min_value, max_value, avg_value, updated_at
+		FROM pipeline_metric_rollups
+		WHERE metric_name = ? AND bucket_size_seconds = ?
+		  AND bucket_start >= ? AND bucket_start < ?
+		ORDER BY bucket_start ASC
+	`, name, int64(m.bucket/time.Second), start.Local(), end.Local())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rollups: %w", err)
+	}
+	defer rows.Close()
+
+	var rollups []*MetricRollup
+	for rows.Next() {
+		var (
+			rollup        MetricRollup
+			bucketSeconds int64
+		)
+		if err := rows.Scan(
+			&rollup.BucketStart,
+			&bucketSeconds,
+			&rollup.MetricName,
+			&rollup.Count,
+			&rollup.Sum,
+			&rollup.Min,
+			&rollup.Max,
+			&rollup.Avg,
+			&rollup.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan rollup: %w", err)
+		}
+		rollup.BucketSize = time.Duration(bucketSeconds) * time.Second
+		rollups = append(rollups, &rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// GetStats returns current rollup statistics
+func (m *MetricsRollupManager) GetStats() *RollupStats {
+	m.statsMutex.RLock()
+	defer m.statsMutex.RUnlock()
+
+	return &RollupStats{
+		LastRunTime:   m.lastRunTime,
+		LastUpserted:  m.lastUpserted,
+		TotalUpserted: m.totalUpserted,
+		Runs:          m.runs,
+	}
+}
+
+// runRollupLoop runs the main rollup loop
+func (m *MetricsRollupManager) runRollupLoop() {
+	defer close(m.doneChan)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+			if _, err := m.RunRollup(ctx); err != nil {
+				m.logger.Errorf("Scheduled rollup failed: %v", err)
+			}
+			cancel()
+
+		case <-m.stopChan:
+			return
+		}
+	}
+}
+
+// rollupBucket aggregates a single bucket and upserts one row per metric name
+func (m *MetricsRollupManager) rollupBucket(ctx context.Context, tx *sql.Tx, start time.Time) (int, error) {
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO pipeline_metric_rollups (
+			bucket_start, bucket_size_seconds, metric_name, sample_count,
+			sum_value, min_value, max_value, avg_value, updated_at
+		)
+		SELECT ?, ?, metric_name, COUNT(*),
+		       SUM(metric_value), MIN(metric_value), MAX(metric_value), AVG(metric_value), ?
+		FROM pipeline_metrics
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY metric_name
+		ON CONFLICT(bucket_start, bucket_size_seconds, metric_name) DO UPDATE SET
+			sample_count = excluded.sample_count,
+			sum_value = excluded.sum_value,
+			min_value = excluded.min_value,
+			max_value = excluded.max_value,
+			avg_value = excluded.avg_value,
+			updated_at = excluded.updated_at
+	`, start, int64(m.bucket/time.Second), m.now().Local(), start, start.Add(m.bucket))
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up bucket %s: %w", start.Format(time.RFC3339), err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rollup rows: %w", err)
+	}
+
+	return int(affected), nil
+}
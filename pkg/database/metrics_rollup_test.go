@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRollupManager(t *testing.T, now time.Time) (*MetricsRollupManager, *sql.DB) {
+	// Metrics are stored in local time; run away from UTC so bounds bound
+	// in the wrong zone select the wrong rows
+	local := time.Local
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := NewMetricsRepository(db, DefaultDatabaseConfig())
+	require.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	manager := NewMetricsRollupManager(db, DefaultDatabaseConfig())
+	manager.now = func() time.Time { return now }
+	return manager, db
+}
+
+func insertRollupTestMetric(t *testing.T, db *sql.DB, name string, value float64, ts time.Time) {
+	_, err := db.Exec(`
+		INSERT INTO pipeline_metrics (pipeline_id, metric_name, metric_type, metric_value, timestamp)
+		VALUES (?, ?, 'gauge', ?, ?)
+	`, "pipeline-1", name, value, ts.Local())
+	require.NoError(t, err)
+}
+
+func TestMetricsRollupManager_RunRollupIsIdempotent(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC)
+	manager, db := setupTestRollupManager(t, now)
+	ctx := context.Background()
+
+	insertRollupTestMetric(t, db, "latency_ms", 10, now.Add(-20*time.Minute))
+	insertRollupTestMetric(t, db, "latency_ms", 30, now.Add(-10*time.Minute))
+	insertRollupTestMetric(t, db, "errors", 1, now.Add(-5*time.Minute))
+
+	upserted, err := manager.RunRollup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, upserted)
+
+	// A second cycle with a new sample must update the existing row
+	insertRollupTestMetric(t, db, "latency_ms", 50, now.Add(-1*time.Minute))
+
+	upserted, err = manager.RunRollup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, upserted)
+
+	var rows int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pipeline_metric_rollups`).Scan(&rows))
+	assert.Equal(t, 2, rows, "repeated cycles must upsert rather than duplicate")
+
+	bucket := now.Truncate(time.Hour)
+	rollups, err := manager.GetRollups(ctx, "latency_ms", bucket, bucket.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+
+	rollup := rollups[0]
+	assert.True(t, rollup.BucketStart.Equal(bucket))
+	assert.Equal(t, time.Hour, rollup.BucketSize)
+	assert.Equal(t, int64(3), rollup.Count)
+	assert.Equal(t, 90.0, rollup.Sum)
+	assert.Equal(t, 10.0, rollup.Min)
+	assert.Equal(t, 50.0, rollup.Max)
+	assert.Equal(t, 30.0, rollup.Avg)
+
+	stats := manager.GetStats()
+	assert.Equal(t, int64(2), stats.Runs)
+	assert.Equal(t, int64(4), stats.TotalUpserted)
+}
+
+func TestMetricsRollupManager_PreviousBucket(t *testing.T) {
+	now := time.Date(2026, 3, 1, 11, 2, 0, 0, time.UTC)
+	manager, db := setupTestRollupManager(t, now)
+	ctx := context.Background()
+
+	// Late sample for the bucket that just closed, plus one too old to revisit
+	insertRollupTestMetric(t, db, "latency_ms", 20, now.Add(-5*time.Minute))
+	insertRollupTestMetric(t, db, "latency_ms", 99, now.Add(-3*time.Hour))
+
+	upserted, err := manager.RunRollup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, upserted)
+
+	previous := now.Truncate(time.Hour).Add(-time.Hour)
+	rollups, err := manager.GetRollups(ctx, "latency_ms", now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+	assert.True(t, rollups[0].BucketStart.Equal(previous))
+	assert.Equal(t, int64(1), rollups[0].Count)
+}
+
+func TestDatabaseConfig_ValidateRollup(t *testing.T) {
+	config := DefaultDatabaseConfig()
+	config.MetricsRollupInterval = 0
+	assert.ErrorIs(t, config.Validate(), ErrInvalidMetricsRollupInterval)
+
+	config = DefaultDatabaseConfig()
+	config.MetricsRollupBucket = 1500 * time.Millisecond
+	assert.ErrorIs(t, config.Validate(), ErrInvalidMetricsRollupBucket)
+
+	config.MetricsRollupEnabled = false
+	assert.NoError(t, config.Validate())
+}
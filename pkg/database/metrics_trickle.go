@@ -0,0 +1,147 @@
+package database
+
+import "time"
+
+// MetricsWriteMode controls how the batch processor writes a flushed batch
+type MetricsWriteMode string
+
+const (
+	// MetricsWriteOneShot writes each batch in a single transaction
+	MetricsWriteOneShot MetricsWriteMode = "oneshot"
+	// MetricsWriteTrickle splits large batches into sub-batches paced across
+	// the flush interval, trading latency for smaller write spikes
+	MetricsWriteTrickle MetricsWriteMode = "trickle"
+)
+
+// DefaultMetricsTrickleBatchSize is the number of metrics written per
+// sub-batch in trickle mode
+const DefaultMetricsTrickleBatchSize = 25
+
+// valid reports whether the mode is known; empty means one-shot
+func (m MetricsWriteMode) valid() bool {
+	return m == "" || m == MetricsWriteOneShot || m == MetricsWriteTrickle
+}
+
+// normalize returns the effective mode, defaulting to one-shot
+func (m MetricsWriteMode) normalize() MetricsWriteMode {
+	if m == MetricsWriteTrickle {
+		return MetricsWriteTrickle
+	}
+	return MetricsWriteOneShot
+}
+
+// splitBatch cuts a batch into sub-batches of at most size metrics
+func splitBatch(batch []*PipelineMetric, size int) [][]*PipelineMetric {
+	if size <= 0 || len(batch) <= size {
+		return [][]*PipelineMetric{batch}
+	}
+
+	chunks := make([][]*PipelineMetric, 0, (len(batch)+size-1)/size)
+	for start := 0; start < len(batch); start += size {
+		end := start + size
+		if end > len(batch) {
+			end = len(batch)
+		}
+		chunks = append(chunks, batch[start:end])
+	}
+	return chunks
+}
+
+// trickleQueueBatches is how many full batches of sub-batches the trickle
+// writer may have waiting before the processor writes them itself
+const trickleQueueBatches = 10
+
+// writeBatch writes a batch according to the configured write mode. In
+// trickle mode the batch is split and handed to the trickle writer, so the
+// processor goroutine never waits on the pacing; if the writer is that far
+// behind, sub-batches are written straight away instead.
+func (p *MetricsBatchProcessor) writeBatch(batch []*PipelineMetric) {
+	if p.writeMode != MetricsWriteTrickle || len(batch) <= p.trickleBatchSize {
+		p.writeSubBatch(batch)
+		return
+	}
+
+	for _, chunk := range splitBatch(batch, p.trickleBatchSize) {
+		select {
+		case p.trickleQueue <- chunk:
+		default:
+			p.writeSubBatch(chunk)
+			p.incrementSubBatchCount()
+		}
+	}
+}
+
+// trickleBatchesPerFlush is the number of sub-batches a full batch splits into
+func (p *MetricsBatchProcessor) trickleBatchesPerFlush() int {
+	if p.batchSize <= p.trickleBatchSize {
+		return 1
+	}
+	return (p.batchSize + p.trickleBatchSize - 1) / p.trickleBatchSize
+}
+
+// runTrickleWriter writes queued sub-batches one at a time, pausing between
+// them so a full batch is spread over the flush interval. On stop it waits
+// for the processor to hand over its last batches, then writes everything
+// left without pausing.
+func (p *MetricsBatchProcessor) runTrickleWriter() {
+	defer close(p.trickleDone)
+
+	pace := p.flushInterval / time.Duration(p.trickleBatchesPerFlush())
+	for {
+		select {
+		case chunk := <-p.trickleQueue:
+			p.writeSubBatch(chunk)
+			p.incrementSubBatchCount()
+
+		case <-p.stopChan:
+			p.drainTrickleQueue()
+			return
+		}
+
+		select {
+		case <-time.After(pace):
+		case <-p.stopChan:
+			p.drainTrickleQueue()
+			return
+		}
+	}
+}
+
+// drainTrickleQueue writes the remaining sub-batches once the processor has stopped
+func (p *MetricsBatchProcessor) drainTrickleQueue() {
+	<-p.doneChan
+	for {
+		select {
+		case chunk := <-p.trickleQueue:
+			p.writeSubBatch(chunk)
+			p.incrementSubBatchCount()
+		default:
+			return
+		}
+	}
+}
+
+// writeSubBatch writes one transaction's worth of metrics, queueing it for
+// retry on failure
+func (p *MetricsBatchProcessor) writeSubBatch(batch []*PipelineMetric) {
+	if err := p.processBatch(batch); err != nil {
+		p.logger.Errorf("Failed to process batch: %v", err)
+
+		// Add to retry queue
+		select {
+		case p.errorRetryQueue <- batch:
+		default:
+			p.logger.Errorf("Retry queue full, dropping batch of %d metrics", len(batch))
+			p.incrementErrorCount(int64(len(batch)))
+		}
+		return
+	}
+	p.incrementProcessedCount(int64(len(batch)))
+}
+
+// incrementSubBatchCount safely increments the trickle sub-batch count
+func (p *MetricsBatchProcessor) incrementSubBatchCount() {
+	p.statsMutex.Lock()
+	p.subBatchCount++
+	p.statsMutex.Unlock()
+}
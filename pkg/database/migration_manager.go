@@ -26,6 +26,9 @@ type migrationScript struct {
 	UpSQL       string
 	DownSQL     string
 	Checksum    string
+
+	// UpFunc runs after UpSQL for changes SQL alone can't express idempotently
+	UpFunc func(tx *sql.Tx) error
 }
 
 // MigrationConfig holds configuration for the migration manager
@@ -366,6 +369,67 @@ func (mm *migrationManager) loadMigrations() {
 		`,
 	}
 
+	// Migration 5: Compressed event payloads
+	mm.migrations[5] = &migrationScript{
+		Version:     5,
+		Name:        "add_event_compression",
+		Description: "Add compressed marker for gzipped event_data",
+		UpSQL: `
+			-- Adds pipeline_events.compressed INTEGER NOT NULL DEFAULT 0.
+			-- The column is added by UpFunc because the metrics repository
+			-- may already have created it.
+		`,
+		UpFunc: func(tx *sql.Tx) error {
+			return addEventCompressedColumn(tx)
+		},
+		DownSQL: `
+			ALTER TABLE pipeline_events DROP COLUMN compressed;
+		`,
+	}
+
+	// Migration 6: Pre-aggregated metric rollups
+	mm.migrations[6] = &migrationScript{
+		Version:     6,
+		Name:        "add_metric_rollups",
+		Description: "Add bucketed per-name metric aggregates for charting",
+		UpSQL: `
+			CREATE TABLE IF NOT EXISTS pipeline_metric_rollups (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				bucket_start DATETIME NOT NULL,
+				bucket_size_seconds INTEGER NOT NULL,
+				metric_name TEXT NOT NULL,
+				sample_count INTEGER NOT NULL,
+				sum_value REAL NOT NULL,
+				min_value REAL NOT NULL,
+				max_value REAL NOT NULL,
+				avg_value REAL NOT NULL,
+				updated_at DATETIME NOT NULL,
+				UNIQUE(bucket_start, bucket_size_seconds, metric_name)
+			);
+		`,
+		DownSQL: `
+			DROP TABLE IF EXISTS pipeline_metric_rollups;
+		`,
+	}
+
+	// Migration 7: Session quality score
+	mm.migrations[7] = &migrationScript{
+		Version:     7,
+		Name:        "add_session_quality_score",
+		Description: "Add 0-100 quality score computed when a session ends",
+		UpSQL: `
+			-- Adds pipeline_sessions.quality_score REAL.
+			-- The column is added by UpFunc because the metrics repository
+			-- may already have created it.
+		`,
+		UpFunc: func(tx *sql.Tx) error {
+			return addSessionQualityColumn(tx)
+		},
+		DownSQL: `
+			ALTER TABLE pipeline_sessions DROP COLUMN quality_score;
+		`,
+	}
+
 	// Calculate checksums for all migrations
 	for _, migration := range mm.migrations {
 		migration.Checksum = mm.calculateChecksum(migration.UpSQL)
@@ -668,6 +732,12 @@ func (mm *migrationManager) runMigration(version int, up bool) error {
 		return fmt.Errorf("failed to execute migration SQL: %w", err)
 	}
 
+	if up && migration.UpFunc != nil {
+		if err := migration.UpFunc(tx); err != nil {
+			return fmt.Errorf("failed to execute migration: %w", err)
+		}
+	}
+
 	// Update migration tracking
 	if up {
 		// Record migration as applied
@@ -732,6 +802,10 @@ func (mm *migrationManager) createBackup(backupPath string) error {
 		return fmt.Errorf("failed to create backup: %w", err)
 	}
 
+	if err := mm.writeBackupMetadata(backupPath); err != nil {
+		return fmt.Errorf("failed to record backup metadata: %w", err)
+	}
+
 	log.Printf("Database backup created: %s", backupPath)
 	return nil
 }
@@ -880,12 +954,16 @@ func (mm *migrationManager) cleanupOldBackups() error {
 		} else {
 			log.Printf("Removed old backup: %s", backupPath)
 		}
+		if err := os.Remove(backupMetadataPath(backupPath)); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove old backup metadata %s: %v", backupPath, err)
+		}
 	}
 
 	return nil
 }
 
-// GetBackupInfo returns information about available backups
+// GetBackupInfo returns information about available backups, each verified
+// against its metadata
 func (mm *migrationManager) GetBackupInfo() ([]*BackupInfo, error) {
 	if !mm.config.BackupEnabled {
 		return nil, fmt.Errorf("backup is not enabled")
@@ -911,6 +989,13 @@ func (mm *migrationManager) GetBackupInfo() ([]*BackupInfo, error) {
 				CreatedAt:   info.ModTime(),
 				Description: mm.parseBackupDescription(file.Name()),
 			}
+
+			metadata, err := mm.VerifyBackup(file.Name())
+			backup.Metadata = metadata
+			backup.Status = backupStatus(err)
+			if err != nil {
+				backup.VerifyError = err.Error()
+			}
 			backups = append(backups, backup)
 		}
 	}
@@ -948,4 +1033,8 @@ type BackupInfo struct {
 	Size        int64     `json:"size"`
 	CreatedAt   time.Time `json:"created_at"`
 	Description string    `json:"description"`
+
+	Metadata    *BackupMetadata `json:"metadata,omitempty"` // nil when the backup has no readable sidecar
+	Status      BackupStatus    `json:"status"`
+	VerifyError string          `json:"verify_error,omitempty"`
 }
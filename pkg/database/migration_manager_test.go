@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -533,3 +534,173 @@ func TestMigrationManagerErrors(t *testing.T) {
 		assert.Equal(t, latestVersion, currentVersion) // Should be at latest version
 	})
 }
+
+func TestMigrationManager_EventCompressionColumn(t *testing.T) {
+	tempDir := t.TempDir()
+	db, err := sql.Open("sqlite3", filepath.Join(tempDir, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := &MigrationConfig{
+		BackupEnabled:    false,
+		BackupDirectory:  filepath.Join(tempDir, "backups"),
+		BackupRetention:  3,
+		ValidateChecksum: true,
+	}
+
+	hasCompressed := func() bool {
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('pipeline_events') WHERE name = 'compressed'`).Scan(&count))
+		return count == 1
+	}
+
+	// The metrics repository may create the column before migrations run
+	repo, err := NewMetricsRepository(db, DefaultDatabaseConfig())
+	require.NoError(t, err)
+	defer repo.Close()
+	require.True(t, hasCompressed())
+
+	mm, err := NewMigrationManagerWithConfig(db, config)
+	require.NoError(t, err)
+	require.NoError(t, mm.MigrateTo(5))
+	assert.True(t, hasCompressed())
+
+	require.NoError(t, mm.RollbackTo(4))
+	assert.False(t, hasCompressed())
+
+	require.NoError(t, mm.MigrateTo(5))
+	assert.True(t, hasCompressed())
+}
+
+func TestMigrationManager_BackupVerification(t *testing.T) {
+	tempDir := t.TempDir()
+	backupDir := filepath.Join(tempDir, "backups")
+	db, err := sql.Open("sqlite3", filepath.Join(tempDir, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := &MigrationConfig{
+		BackupEnabled:    true,
+		BackupDirectory:  backupDir,
+		BackupRetention:  3,
+		ValidateChecksum: true,
+	}
+
+	migrator, err := NewMigrationManagerWithConfig(db, config)
+	require.NoError(t, err)
+	mm := migrator.(*migrationManager)
+	require.NoError(t, mm.MigrateTo(1))
+
+	for i := 0; i < 200; i++ {
+		_, err := db.Exec("INSERT INTO uma_cache (cache_key, data, type, expires_at) VALUES (?, ?, ?, ?)",
+			fmt.Sprintf("key_%d", i), "some cached data to fill a few pages", "test_type", time.Now().Add(time.Hour))
+		require.NoError(t, err)
+	}
+
+	backupPath := filepath.Join(backupDir, "manual_backup.db")
+	require.NoError(t, mm.createBackup(backupPath))
+
+	t.Run("Metadata", func(t *testing.T) {
+		metadata, err := mm.VerifyBackup("manual_backup.db")
+		require.NoError(t, err)
+		assert.Equal(t, 1, metadata.SchemaVersion)
+		assert.NotEmpty(t, metadata.SourceVersion)
+		assert.Equal(t, int64(200), metadata.RowCounts["uma_cache"])
+		assert.Len(t, metadata.SHA256, 64)
+
+		backups, err := mm.GetBackupInfo()
+		require.NoError(t, err)
+		require.NotEmpty(t, backups)
+		for _, backup := range backups {
+			assert.Equal(t, BackupVerified, backup.Status, backup.Filename)
+			assert.Empty(t, backup.VerifyError)
+			assert.NotNil(t, backup.Metadata)
+		}
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		data, err := os.ReadFile(backupPath)
+		require.NoError(t, err)
+		corruptPath := filepath.Join(backupDir, "corrupt_backup.db")
+		require.NoError(t, os.WriteFile(corruptPath, data, 0644))
+		sidecar, err := os.ReadFile(backupMetadataPath(backupPath))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(backupMetadataPath(corruptPath), sidecar, 0644))
+
+		_, err = mm.VerifyBackup("corrupt_backup.db")
+		require.NoError(t, err, "an identical copy verifies")
+
+		// Scribble over the page after the header
+		file, err := os.OpenFile(corruptPath, os.O_WRONLY, 0)
+		require.NoError(t, err)
+		_, err = file.WriteAt(make([]byte, 512), int64(len(data)/2))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		_, err = mm.VerifyBackup("corrupt_backup.db")
+		assert.ErrorIs(t, err, ErrBackupCorrupt)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+
+		backups, err := mm.GetBackupInfo()
+		require.NoError(t, err)
+		statuses := make(map[string]BackupStatus)
+		for _, backup := range backups {
+			statuses[backup.Filename] = backup.Status
+		}
+		assert.Equal(t, BackupCorrupt, statuses["corrupt_backup.db"])
+		assert.Equal(t, BackupVerified, statuses["manual_backup.db"])
+	})
+
+	t.Run("IntegrityCheck", func(t *testing.T) {
+		corruptPath := filepath.Join(backupDir, "bad_pages.db")
+		data, err := os.ReadFile(backupPath)
+		require.NoError(t, err)
+
+		// Corrupt every page after the first, then record the damaged file's
+		// checksum so only the integrity check can catch it
+		pageSize := int(data[16])<<8 | int(data[17])
+		for offset := pageSize; offset+64 <= len(data); offset += pageSize {
+			copy(data[offset:offset+64], make([]byte, 64))
+		}
+		require.NoError(t, os.WriteFile(corruptPath, data, 0644))
+
+		metadata, err := readBackupMetadata(backupPath)
+		require.NoError(t, err)
+		metadata.SHA256, err = fileSHA256(corruptPath)
+		require.NoError(t, err)
+		sidecar, err := json.Marshal(metadata)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(backupMetadataPath(corruptPath), sidecar, 0644))
+
+		_, err = mm.VerifyBackup("bad_pages.db")
+		assert.ErrorIs(t, err, ErrBackupCorrupt)
+	})
+
+	t.Run("MissingMetadata", func(t *testing.T) {
+		data, err := os.ReadFile(backupPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(backupDir, "legacy_backup.db"), data, 0644))
+
+		_, err = mm.VerifyBackup("legacy_backup.db")
+		assert.ErrorIs(t, err, ErrBackupMetadataMissing)
+
+		backups, err := mm.GetBackupInfo()
+		require.NoError(t, err)
+		for _, backup := range backups {
+			if backup.Filename == "legacy_backup.db" {
+				assert.Equal(t, BackupUnverified, backup.Status)
+				assert.Nil(t, backup.Metadata)
+			}
+		}
+	})
+
+	t.Run("CleanupRemovesMetadata", func(t *testing.T) {
+		require.NoError(t, os.Chtimes(backupPath, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+		require.NoError(t, mm.cleanupOldBackups())
+
+		_, err := os.Stat(backupPath)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(backupMetadataPath(backupPath))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
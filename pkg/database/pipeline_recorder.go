@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// PipelineRecorder stores pipeline metrics and events through a
+// MetricsRepository. It satisfies the pipeline package's MetricRecorder and
+// EventRecorder interfaces, so the pipeline can persist to SQLite without
+// depending on this package.
+type PipelineRecorder struct {
+	repo       MetricsRepository
+	pipelineID string
+	timeout    time.Duration
+}
+
+// NewPipelineRecorder creates a recorder backed by the metrics repository.
+// Metrics without a pipeline_id tag are stored under the given default pipeline ID.
+func NewPipelineRecorder(repo MetricsRepository, pipelineID string) *PipelineRecorder {
+	return &PipelineRecorder{
+		repo:       repo,
+		pipelineID: pipelineID,
+		timeout:    5 * time.Second,
+	}
+}
+
+// Counter stores a counter increment
+func (r *PipelineRecorder) Counter(name string, value int64, tags map[string]string) {
+	r.store(name, "counter", float64(value), tags)
+}
+
+// Gauge stores a gauge value
+func (r *PipelineRecorder) Gauge(name string, value float64, tags map[string]string) {
+	r.store(name, "gauge", value, tags)
+}
+
+// Histogram stores a histogram observation
+func (r *PipelineRecorder) Histogram(name string, value float64, tags map[string]string) {
+	r.store(name, "histogram", value, tags)
+}
+
+// Timing stores a duration in milliseconds
+func (r *PipelineRecorder) Timing(name string, duration time.Duration, tags map[string]string) {
+	r.store(name, "timing", float64(duration.Milliseconds()), tags)
+}
+
+// store converts the measurement into a metric and persists it
+func (r *PipelineRecorder) store(name, metricType string, value float64, tags map[string]string) {
+	pipelineID := r.pipelineID
+	if id, ok := tags["pipeline_id"]; ok && id != "" {
+		pipelineID = id
+	}
+
+	metric := &PipelineMetric{
+		PipelineID:  pipelineID,
+		MetricName:  name,
+		MetricType:  metricType,
+		MetricValue: value,
+		Tags:        tags,
+		Timestamp:   time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.repo.StoreMetric(ctx, metric); err != nil {
+		log.Printf("Failed to store metric %s: %v", name, err)
+	}
+}
+
+// RecordEvent stores a pipeline event
+func (r *PipelineRecorder) RecordEvent(eventType, severity string, data map[string]interface{}) {
+	event := &PipelineEvent{
+		PipelineID: r.pipelineID,
+		EventType:  eventType,
+		EventData:  data,
+		Severity:   severity,
+		Timestamp:  time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	if err := r.repo.StoreEvent(ctx, event); err != nil {
+		log.Printf("Failed to store event %s: %v", eventType, err)
+	}
+}
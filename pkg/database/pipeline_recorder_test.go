@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineRecorder_StoresMetricsAndEvents(t *testing.T) {
+	repo, _, cleanup := setupTestMetricsRepository(t)
+	defer cleanup()
+
+	recorder := NewPipelineRecorder(repo, "default-pipeline")
+	recorder.Counter("pipeline.starts", 1, map[string]string{"pipeline_id": "pipeline-1"})
+	recorder.Timing("pipeline.uptime", 1500*time.Millisecond, nil)
+	recorder.RecordEvent("recovery_budget_exhausted", "high", map[string]interface{}{"max_recoveries": 3})
+	require.NoError(t, repo.FlushPendingMetrics())
+
+	// Flushed batches are written in the background
+	ctx := context.Background()
+	var starts *PipelineMetric
+	require.Eventually(t, func() bool {
+		var err error
+		starts, err = repo.GetLatestMetric(ctx, "pipeline-1", "pipeline.starts")
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "counter", starts.MetricType)
+	assert.Equal(t, 1.0, starts.MetricValue)
+
+	uptime, err := repo.GetLatestMetric(ctx, "default-pipeline", "pipeline.uptime")
+	require.NoError(t, err)
+	assert.Equal(t, "timing", uptime.MetricType)
+	assert.Equal(t, 1500.0, uptime.MetricValue)
+
+	events, err := repo.GetEvents(ctx, &EventQuery{PipelineID: "default-pipeline"})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "recovery_budget_exhausted", events[0].EventType)
+	assert.Equal(t, "high", events[0].Severity)
+}
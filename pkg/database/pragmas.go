@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// DefaultPageSize matches SQLite's own default and the block size of most
+	// filesystems; larger pages only help with big BLOB-heavy rows
+	DefaultPageSize = 4096
+	// DefaultMmapSize lets SQLite read the hot part of the metrics tables
+	// through memory-mapped I/O instead of read() calls
+	DefaultMmapSize int64 = 64 << 20 // 64MB
+)
+
+// validPageSize reports whether size is a page size SQLite accepts: a power
+// of two from 512 to 65536, or zero to keep SQLite's default
+func validPageSize(size int) bool {
+	if size == 0 {
+		return true
+	}
+	return size >= 512 && size <= 65536 && size&(size-1) == 0
+}
+
+// isNewDatabase reports whether the database file has not been created yet
+func isNewDatabase(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	return info.Size() == 0
+}
+
+// pragmaConnector opens SQLite connections and applies the configured
+// pragmas to each one. The page size is only set for a brand new database
+// file; SQLite ignores it once the first table exists.
+type pragmaConnector struct {
+	dsn    string
+	driver *sqlite3.SQLiteDriver
+}
+
+// newPragmaConnector creates a connector applying the config's pragmas
+func newPragmaConnector(dsn string, config *DatabaseConfig) *pragmaConnector {
+	pragmas := []string{
+		fmt.Sprintf("PRAGMA cache_size = %d", config.CacheSize),
+		fmt.Sprintf("PRAGMA mmap_size = %d", config.MmapSize),
+	}
+	if config.PageSize > 0 && isNewDatabase(config.DatabasePath) {
+		// Must run before anything is written to the file
+		pragmas = append([]string{fmt.Sprintf("PRAGMA page_size = %d", config.PageSize)}, pragmas...)
+	}
+
+	return &pragmaConnector{
+		dsn: dsn,
+		driver: &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				for _, pragma := range pragmas {
+					if _, err := conn.Exec(pragma, nil); err != nil {
+						return fmt.Errorf("failed to apply %q: %w", pragma, err)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// Connect implements driver.Connector
+func (c *pragmaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver implements driver.Connector
+func (c *pragmaConnector) Driver() driver.Driver {
+	return c.driver
+}
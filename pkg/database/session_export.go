@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// sessionCSVHeader is the header row written by ExportAnalyticsCSV
+var sessionCSVHeader = []string{"id", "guild", "duration", "final_state", "errors", "recoveries"}
+
+// ExportAnalyticsCSV writes the sessions started within the time range to w as
+// CSV, one row per session after a header row. Sessions are identified by
+// pipeline ID. Duration is in whole seconds and left empty for sessions that
+// have not ended.
+func (sm *SessionManager) ExportAnalyticsCSV(ctx context.Context, w io.Writer, startTime, endTime time.Time) error {
+	sessions, err := sm.GetSessionsByTimeRange(ctx, startTime, endTime)
+	if err != nil {
+		return fmt.Errorf("failed to get sessions for export: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(sessionCSVHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, session := range sessions {
+		duration := ""
+		if session.EndedAt != nil {
+			duration = strconv.FormatInt(int64(session.EndedAt.Sub(session.StartedAt).Seconds()), 10)
+		}
+
+		record := []string{
+			session.PipelineID,
+			session.GuildID,
+			duration,
+			session.FinalState,
+			strconv.Itoa(session.TotalErrors),
+			strconv.Itoa(session.TotalRecoveries),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row for session %s: %w", session.PipelineID, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return nil
+}
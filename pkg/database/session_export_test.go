@@ -0,0 +1,76 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_ExportAnalyticsCSV(t *testing.T) {
+	dbPath := "test_session_export.db"
+	defer os.Remove(dbPath)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.DatabasePath = dbPath
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	sessionManager := NewSessionManager(repo, config)
+	ctx := context.Background()
+	now := time.Now()
+
+	ended := &PipelineSession{
+		PipelineID: "export-ended",
+		GuildID:    "guild-1",
+		StartedAt:  now.Add(-10 * time.Minute),
+	}
+	require.NoError(t, sessionManager.CreateSession(ctx, ended))
+	endedAt := ended.StartedAt.Add(90 * time.Second)
+	finalState := `failed, "voice" lost`
+	errorsCount, recoveries := 3, 2
+	require.NoError(t, sessionManager.UpdateSession(ctx, ended.PipelineID, &SessionUpdate{
+		EndedAt:         &endedAt,
+		FinalState:      &finalState,
+		TotalErrors:     &errorsCount,
+		TotalRecoveries: &recoveries,
+	}))
+
+	active := &PipelineSession{
+		PipelineID: "export-active",
+		GuildID:    "guild-2",
+		StartedAt:  now.Add(-5 * time.Minute),
+	}
+	require.NoError(t, sessionManager.CreateSession(ctx, active))
+
+	outOfRange := &PipelineSession{
+		PipelineID: "export-old",
+		GuildID:    "guild-3",
+		StartedAt:  now.Add(-48 * time.Hour),
+	}
+	require.NoError(t, sessionManager.CreateSession(ctx, outOfRange))
+
+	var buf bytes.Buffer
+	require.NoError(t, sessionManager.ExportAnalyticsCSV(ctx, &buf, now.Add(-time.Hour), now))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"id", "guild", "duration", "final_state", "errors", "recoveries"},
+		{"export-active", "guild-2", "", "", "0", "0"},
+		{"export-ended", "guild-1", "90", finalState, "3", "2"},
+	}, records)
+}
@@ -42,6 +42,7 @@ type SessionAnalyticsCache struct {
 
 // SessionAnalytics represents session analytics data
 type SessionAnalytics struct {
+	SchemaVersion          string           `json:"schema_version"`
 	TotalSessions          int64            `json:"total_sessions"`
 	ActiveSessions         int64            `json:"active_sessions"`
 	CompletedSessions      int64            `json:"completed_sessions"`
@@ -81,6 +82,11 @@ func NewSessionManager(metricsRepo MetricsRepository, config *DatabaseConfig) *S
 
 // Start starts the session manager and its background tasks
 func (sm *SessionManager) Start() error {
+	// Close sessions left open by a previous run before loading the rest
+	if _, err := sm.RepairStaleSessions(context.Background()); err != nil {
+		log.Printf("Failed to repair stale sessions: %v", err)
+	}
+
 	// Load active sessions from database
 	if err := sm.loadActiveSessions(); err != nil {
 		return fmt.Errorf("failed to load active sessions: %w", err)
@@ -155,6 +161,9 @@ func (sm *SessionManager) UpdateSession(ctx context.Context, sessionID string, u
 		if updates.TotalRecoveries != nil {
 			session.TotalRecoveries = *updates.TotalRecoveries
 		}
+		if updates.QualityScore != nil {
+			session.QualityScore = updates.QualityScore
+		}
 	}
 	sm.sessionMutex.Unlock()
 
@@ -208,6 +217,7 @@ func (sm *SessionManager) GetSessionAnalytics(ctx context.Context) (*SessionAnal
 	sm.analyticsCache.mutex.RLock()
 	if time.Since(sm.analyticsCache.lastUpdate) < sm.analyticsCache.cacheDuration {
 		analytics := &SessionAnalytics{
+			SchemaVersion:          ExportSchemaVersion,
 			TotalSessions:          sm.analyticsCache.totalSessions,
 			ActiveSessions:         sm.analyticsCache.activeSessions,
 			AverageSessionDuration: sm.analyticsCache.averageSessionDuration,
@@ -306,6 +316,7 @@ func (sm *SessionManager) runCleanupTask() {
 // generateSessionAnalytics generates comprehensive session analytics
 func (sm *SessionManager) generateSessionAnalytics(ctx context.Context) (*SessionAnalytics, error) {
 	analytics := &SessionAnalytics{
+		SchemaVersion:   ExportSchemaVersion,
 		SessionsByState: make(map[string]int64),
 		SessionsByHour:  make(map[int]int64),
 	}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// SessionQualityMetric is the metric name the quality score is emitted under
+const SessionQualityMetric = "session_quality_score"
+
+// Points at which each quality signal costs its full weight
+const (
+	qualityUnderrunsPerMinuteCeiling = 1.0                    // one underrun a minute is audibly broken
+	qualityReconnectCeiling          = 3                      // reconnects per session
+	qualityRecoveryCeiling           = 5                      // recoveries per session
+	qualityLatencyTarget             = 20 * time.Millisecond  // one Opus frame; no penalty at or below
+	qualityLatencyCeiling            = 100 * time.Millisecond // five frames behind
+)
+
+// QualityWeights sets how many of the 100 points each signal can take away.
+// The defaults sum to 100, so a session maxing out every signal scores 0.
+type QualityWeights struct {
+	Underruns  float64 `json:"underruns" yaml:"underruns"`
+	Reconnects float64 `json:"reconnects" yaml:"reconnects"`
+	Recoveries float64 `json:"recoveries" yaml:"recoveries"`
+	Latency    float64 `json:"latency" yaml:"latency"`
+}
+
+// DefaultQualityWeights weights underruns highest since listeners hear them directly
+func DefaultQualityWeights() QualityWeights {
+	return QualityWeights{
+		Underruns:  40,
+		Reconnects: 20,
+		Recoveries: 20,
+		Latency:    20,
+	}
+}
+
+// validate reports whether every weight is non-negative
+func (w QualityWeights) validate() bool {
+	return w.Underruns >= 0 && w.Reconnects >= 0 && w.Recoveries >= 0 && w.Latency >= 0
+}
+
+// SessionQualityInputs are the per-session signals the quality score is derived from
+type SessionQualityInputs struct {
+	Duration            time.Duration // time spent playing
+	Underruns           int
+	Reconnects          int
+	Recoveries          int
+	AvgFrameSendLatency time.Duration
+}
+
+// ScoreSessionQuality returns a 0–100 score for a session. Each signal is
+// scaled to 0–1 against its ceiling and costs up to its weight:
+//
+//	score = 100 − Σ weight × min(1, signal / ceiling)
+//
+// Underruns are measured per minute of playback, reconnects and recoveries
+// per session, and latency only counts above one 20ms frame. The result is
+// clamped to 0–100 and rounded to one decimal place.
+func ScoreSessionQuality(inputs SessionQualityInputs, weights QualityWeights) float64 {
+	var underrunRate float64
+	if minutes := inputs.Duration.Minutes(); minutes > 0 {
+		underrunRate = float64(inputs.Underruns) / minutes
+	} else if inputs.Underruns > 0 {
+		underrunRate = qualityUnderrunsPerMinuteCeiling
+	}
+
+	latencyOver := inputs.AvgFrameSendLatency - qualityLatencyTarget
+	latencyRange := qualityLatencyCeiling - qualityLatencyTarget
+
+	penalty := weights.Underruns*saturate(underrunRate/qualityUnderrunsPerMinuteCeiling) +
+		weights.Reconnects*saturate(float64(inputs.Reconnects)/qualityReconnectCeiling) +
+		weights.Recoveries*saturate(float64(inputs.Recoveries)/qualityRecoveryCeiling) +
+		weights.Latency*saturate(float64(latencyOver)/float64(latencyRange))
+
+	score := math.Max(0, math.Min(100, 100-penalty))
+	return math.Round(score*10) / 10
+}
+
+// saturate clamps a ratio to 0–1
+func saturate(ratio float64) float64 {
+	return math.Max(0, math.Min(1, ratio))
+}
+
+// EndSessionWithQuality ends a session, storing its quality score on the
+// session row and emitting it as a gauge
+func (sm *SessionManager) EndSessionWithQuality(ctx context.Context, sessionID, finalState string, inputs SessionQualityInputs) (float64, error) {
+	weights := DefaultQualityWeights()
+	if sm.config != nil {
+		weights = sm.config.SessionQualityWeights
+	}
+	score := ScoreSessionQuality(inputs, weights)
+
+	now := time.Now()
+	updates := &SessionUpdate{
+		EndedAt:      &now,
+		FinalState:   &finalState,
+		QualityScore: &score,
+	}
+	if err := sm.UpdateSession(ctx, sessionID, updates); err != nil {
+		return score, err
+	}
+
+	if err := sm.metricsRepo.StoreMetric(ctx, &PipelineMetric{
+		PipelineID:  sessionID,
+		MetricName:  SessionQualityMetric,
+		MetricType:  "gauge",
+		MetricValue: score,
+		Timestamp:   now,
+	}); err != nil {
+		return score, fmt.Errorf("failed to emit quality score: %w", err)
+	}
+
+	return score, nil
+}
+
+// addSessionQualityColumn adds the pipeline_sessions.quality_score column
+func addSessionQualityColumn(db sqlExecer) error {
+	return addColumnIfMissing(db, "pipeline_sessions", "quality_score", "REAL")
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreSessionQuality(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  SessionQualityInputs
+		weights QualityWeights
+		want    float64
+	}{
+		{
+			name:    "clean session",
+			inputs:  SessionQualityInputs{Duration: 10 * time.Minute, AvgFrameSendLatency: 10 * time.Millisecond},
+			weights: DefaultQualityWeights(),
+			want:    100,
+		},
+		{
+			name: "rough session",
+			inputs: SessionQualityInputs{
+				Duration:            10 * time.Minute,
+				Underruns:           5, // 0.5/min: 20 points
+				Reconnects:          1, // 1/3: 6.67 points
+				Recoveries:          2, // 2/5: 8 points
+				AvgFrameSendLatency: 60 * time.Millisecond,
+			},
+			weights: DefaultQualityWeights(),
+			want:    55.3,
+		},
+		{
+			name: "broken session bottoms out",
+			inputs: SessionQualityInputs{
+				Duration:            time.Minute,
+				Underruns:           10,
+				Reconnects:          5,
+				Recoveries:          9,
+				AvgFrameSendLatency: 200 * time.Millisecond,
+			},
+			weights: DefaultQualityWeights(),
+			want:    0,
+		},
+		{
+			name:    "underruns before any playback cost the full weight",
+			inputs:  SessionQualityInputs{Underruns: 1},
+			weights: DefaultQualityWeights(),
+			want:    60,
+		},
+		{
+			name:    "custom weights",
+			inputs:  SessionQualityInputs{Duration: 10 * time.Minute, Underruns: 2, Reconnects: 3},
+			weights: QualityWeights{Underruns: 100},
+			want:    80,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ScoreSessionQuality(tt.inputs, tt.weights))
+		})
+	}
+}
+
+func TestEndSessionWithQuality(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.MetricsFlushInterval = 50 * time.Millisecond
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+	sm := NewSessionManager(repo, config)
+	require.NoError(t, sm.CreateSession(ctx, &PipelineSession{
+		PipelineID: "quality-pipeline",
+		GuildID:    "guild-1",
+		StartedAt:  time.Now().Add(-10 * time.Minute),
+	}))
+
+	errors, recoveries := 3, 2
+	require.NoError(t, sm.UpdateSession(ctx, "quality-pipeline", &SessionUpdate{
+		TotalErrors:     &errors,
+		TotalRecoveries: &recoveries,
+	}))
+
+	score, err := sm.EndSessionWithQuality(ctx, "quality-pipeline", "completed", SessionQualityInputs{
+		Duration:   10 * time.Minute,
+		Underruns:  5,
+		Recoveries: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 72.0, score)
+
+	session, err := repo.GetSession(ctx, "quality-pipeline")
+	require.NoError(t, err)
+	require.NotNil(t, session.QualityScore)
+	assert.Equal(t, 72.0, *session.QualityScore)
+	assert.Equal(t, "completed", session.FinalState)
+	assert.Equal(t, 3, session.TotalErrors, "ending the session keeps the counts")
+	assert.Equal(t, 2, session.TotalRecoveries)
+
+	// Later updates without a score keep the stored one
+	require.NoError(t, sm.EndSession(ctx, "quality-pipeline", "completed"))
+	session, err = repo.GetSession(ctx, "quality-pipeline")
+	require.NoError(t, err)
+	require.NotNil(t, session.QualityScore)
+	assert.Equal(t, 72.0, *session.QualityScore)
+
+	require.Eventually(t, func() bool {
+		metric, err := repo.GetLatestMetric(ctx, "quality-pipeline", SessionQualityMetric)
+		return err == nil && metric.MetricValue == 72.0
+	}, 2*time.Second, 20*time.Millisecond)
+}
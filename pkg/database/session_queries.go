@@ -3,7 +3,9 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -22,6 +24,7 @@ func scanSession(rows *sql.Rows) (*PipelineSession, error) {
 	session := &PipelineSession{}
 	var finalState sql.NullString
 	var totalErrors, totalRecoveries sql.NullInt64
+	var qualityScore sql.NullFloat64
 
 	err := rows.Scan(
 		&session.PipelineID,
@@ -34,6 +37,7 @@ func scanSession(rows *sql.Rows) (*PipelineSession, error) {
 		&finalState,
 		&totalErrors,
 		&totalRecoveries,
+		&qualityScore,
 		&session.CreatedAt,
 	)
 	if err != nil {
@@ -49,6 +53,9 @@ func scanSession(rows *sql.Rows) (*PipelineSession, error) {
 	if totalRecoveries.Valid {
 		session.TotalRecoveries = int(totalRecoveries.Int64)
 	}
+	if qualityScore.Valid {
+		session.QualityScore = &qualityScore.Float64
+	}
 
 	return session, nil
 }
@@ -57,7 +64,7 @@ func scanSession(rows *sql.Rows) (*PipelineSession, error) {
 func (sq *SessionQueryExtensions) GetSessionsByTimeRange(ctx context.Context, startTime, endTime time.Time) ([]*PipelineSession, error) {
 	query := `
 		SELECT pipeline_id, guild_id, channel_id, user_id, stream_url, started_at, ended_at, 
-		       final_state, total_errors, total_recoveries, created_at
+		       final_state, total_errors, total_recoveries, quality_score, created_at
 		FROM pipeline_sessions 
 		WHERE started_at >= ? AND started_at <= ?
 		ORDER BY started_at DESC
@@ -89,7 +96,7 @@ func (sq *SessionQueryExtensions) GetSessionsByTimeRange(ctx context.Context, st
 func (sq *SessionQueryExtensions) GetSessionsByGuild(ctx context.Context, guildID string, limit int) ([]*PipelineSession, error) {
 	query := `
 		SELECT pipeline_id, guild_id, channel_id, user_id, stream_url, started_at, ended_at, 
-		       final_state, total_errors, total_recoveries, created_at
+		       final_state, total_errors, total_recoveries, quality_score, created_at
 		FROM pipeline_sessions 
 		WHERE guild_id = ?
 		ORDER BY started_at DESC
@@ -229,7 +236,9 @@ func (sq *SessionQueryExtensions) GetSessionsByHour(ctx context.Context) (map[in
 	return hourCount, nil
 }
 
-// GetTopErrorTypes returns the most common error types from events
+// GetTopErrorTypes returns the most common error types from events.
+// Plain payloads are counted in SQL; compressed ones can't be inspected
+// there, so they are decoded and counted here.
 func (sq *SessionQueryExtensions) GetTopErrorTypes(ctx context.Context, limit int) ([]ErrorTypeCount, error) {
 	query := `
 		SELECT 
@@ -237,39 +246,95 @@ func (sq *SessionQueryExtensions) GetTopErrorTypes(ctx context.Context, limit in
 			COUNT(*) as count
 		FROM pipeline_events 
 		WHERE event_type = 'error' 
+		AND compressed = 0
 		AND json_extract(event_data, '$.error_type') IS NOT NULL
 		GROUP BY json_extract(event_data, '$.error_type')
-		ORDER BY count DESC
-		LIMIT ?
 	`
 
-	rows, err := sq.db.QueryContext(ctx, query, limit)
+	rows, err := sq.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query top error types: %w", err)
 	}
 	defer rows.Close()
 
-	var errorTypes []ErrorTypeCount
+	counts := make(map[string]int64)
 	for rows.Next() {
 		var errorType ErrorTypeCount
 		if err := rows.Scan(&errorType.ErrorType, &errorType.Count); err != nil {
 			return nil, fmt.Errorf("failed to scan error type: %w", err)
 		}
-		errorTypes = append(errorTypes, errorType)
+		counts[errorType.ErrorType] += errorType.Count
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating error types: %w", err)
 	}
 
+	if err := sq.countCompressedErrorTypes(ctx, counts); err != nil {
+		return nil, err
+	}
+
+	errorTypes := make([]ErrorTypeCount, 0, len(counts))
+	for errorType, count := range counts {
+		errorTypes = append(errorTypes, ErrorTypeCount{ErrorType: errorType, Count: count})
+	}
+	sort.Slice(errorTypes, func(i, j int) bool {
+		if errorTypes[i].Count != errorTypes[j].Count {
+			return errorTypes[i].Count > errorTypes[j].Count
+		}
+		return errorTypes[i].ErrorType < errorTypes[j].ErrorType
+	})
+	if limit >= 0 && len(errorTypes) > limit {
+		errorTypes = errorTypes[:limit]
+	}
+
 	return errorTypes, nil
 }
 
+// countCompressedErrorTypes adds the error types of compressed error events
+// to counts
+func (sq *SessionQueryExtensions) countCompressedErrorTypes(ctx context.Context, counts map[string]int64) error {
+	rows, err := sq.db.QueryContext(ctx, `
+		SELECT event_data FROM pipeline_events
+		WHERE event_type = 'error' AND compressed = 1
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query compressed error events: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return fmt.Errorf("failed to scan compressed error event: %w", err)
+		}
+
+		decoded, err := decodeEventData(data, true)
+		if err != nil {
+			return err
+		}
+		var event struct {
+			ErrorType interface{} `json:"error_type"`
+		}
+		if err := json.Unmarshal(decoded, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal error event: %w", err)
+		}
+		if event.ErrorType != nil {
+			counts[fmt.Sprint(event.ErrorType)]++
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating compressed error events: %w", err)
+	}
+	return nil
+}
+
 // GetOrphanedSessions returns sessions that have been active for too long
 func (sq *SessionQueryExtensions) GetOrphanedSessions(ctx context.Context, cutoffTime time.Time) ([]*PipelineSession, error) {
 	query := `
 		SELECT pipeline_id, guild_id, channel_id, user_id, stream_url, started_at, ended_at, 
-		       final_state, total_errors, total_recoveries, created_at
+		       final_state, total_errors, total_recoveries, quality_score, created_at
 		FROM pipeline_sessions 
 		WHERE started_at < ? AND ended_at IS NULL
 		ORDER BY started_at ASC
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultSlowQueryThreshold is how long a repository query may run before it
+// is logged as slow
+const DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+// MetricSlowQuery is the timing metric recorded for each slow query, tagged
+// with the query name
+const MetricSlowQuery = "db.slow_query"
+
+// slowQueryLog times repository queries and logs, and records a metric for,
+// each one that runs past the threshold. A nil log or zero threshold
+// observes nothing.
+type slowQueryLog struct {
+	threshold time.Duration
+	logSQL    bool
+	logger    Logger
+	record    func(metric *PipelineMetric) error // nil skips the metric
+}
+
+// newSlowQueryLog creates a slow query log from the config's settings
+func newSlowQueryLog(config *DatabaseConfig, logger Logger, record func(*PipelineMetric) error) *slowQueryLog {
+	l := &slowQueryLog{
+		threshold: DefaultSlowQueryThreshold,
+		logger:    logger,
+		record:    record,
+	}
+	if config != nil {
+		l.threshold = config.SlowQueryThreshold
+		l.logSQL = config.SlowQueryLogSQL
+	}
+	return l
+}
+
+// observe reports the named query if it has run longer than the threshold
+// since started. It is meant to be deferred:
+//
+//	defer r.slowQueries.observe("GetMetrics", sqlQuery, time.Now())
+func (l *slowQueryLog) observe(name, query string, started time.Time) {
+	if l == nil || l.threshold <= 0 {
+		return
+	}
+
+	elapsed := time.Since(started)
+	if elapsed < l.threshold {
+		return
+	}
+
+	if l.logSQL && query != "" {
+		l.logger.Printf("WARNING: slow query name=%s duration=%s threshold=%s sql=%q",
+			name, elapsed.Round(time.Microsecond), l.threshold, compactSQL(query))
+	} else {
+		l.logger.Printf("WARNING: slow query name=%s duration=%s threshold=%s",
+			name, elapsed.Round(time.Microsecond), l.threshold)
+	}
+
+	if l.record == nil {
+		return
+	}
+	err := l.record(&PipelineMetric{
+		PipelineID:  "database",
+		MetricName:  MetricSlowQuery,
+		MetricType:  "timing",
+		MetricValue: float64(elapsed.Milliseconds()),
+		Tags:        map[string]string{"query": name},
+		Timestamp:   time.Now(),
+	})
+	if err != nil {
+		l.logger.Printf("Failed to record slow query metric: %v", err)
+	}
+}
+
+// compactSQL collapses the whitespace in a query onto one line
+func compactSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
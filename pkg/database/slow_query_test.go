@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupSlowQueryRepository creates a repository with the given slow query
+// settings, logging to the returned logger
+func setupSlowQueryRepository(t *testing.T, threshold time.Duration, logSQL bool) (*metricsRepository, *testLogger) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	config := DefaultDatabaseConfig()
+	config.MetricsFlushInterval = 50 * time.Millisecond
+	config.MetricsBatchSize = 10
+	config.SlowQueryThreshold = threshold
+	config.SlowQueryLogSQL = logSQL
+
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		repo.Close()
+		db.Close()
+	})
+
+	logger := &testLogger{}
+	r := repo.(*metricsRepository)
+	r.batchProcessor.SetLogger(logger)
+	return r, logger
+}
+
+// slowQueryLines returns the logged slow query lines
+func slowQueryLines(logger *testLogger) []string {
+	var lines []string
+	for _, message := range logger.GetMessages() {
+		if strings.Contains(message, "slow query") {
+			lines = append(lines, message)
+		}
+	}
+	return lines
+}
+
+func TestSlowQueryLog_LogsAndRecordsSlowQueries(t *testing.T) {
+	// Any real query takes longer than a nanosecond
+	repo, logger := setupSlowQueryRepository(t, time.Nanosecond, false)
+	ctx := context.Background()
+
+	_, err := repo.GetMetrics(ctx, &MetricsQuery{PipelineID: "pipeline-1"})
+	require.NoError(t, err)
+	_, err = repo.GetEvents(ctx, &EventQuery{PipelineID: "pipeline-1"})
+	require.NoError(t, err)
+
+	lines := slowQueryLines(logger)
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "name=GetMetrics")
+	assert.Contains(t, lines[0], "duration=")
+	assert.Contains(t, lines[0], "threshold=1ns")
+	assert.NotContains(t, lines[0], "sql=", "SQL text is off by default")
+	assert.Contains(t, lines[1], "name=GetEvents")
+
+	// Each slow query is also recorded as a metric once the batch flushes
+	require.Eventually(t, func() bool {
+		var count int
+		err := repo.db.QueryRow(`SELECT COUNT(*) FROM pipeline_metrics WHERE metric_name = ? AND tags LIKE '%GetMetrics%'`, MetricSlowQuery).Scan(&count)
+		return err == nil && count == 1
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestSlowQueryLog_IncludesSQLWhenEnabled(t *testing.T) {
+	repo, logger := setupSlowQueryRepository(t, time.Nanosecond, true)
+
+	_, err := repo.GetEvents(context.Background(), &EventQuery{PipelineID: "pipeline-1"})
+	require.NoError(t, err)
+
+	lines := slowQueryLines(logger)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "name=GetEvents")
+	assert.Contains(t, lines[0], `sql="SELECT`)
+	assert.NotContains(t, lines[0], "\n")
+}
+
+func TestSlowQueryLog_IgnoresFastQueries(t *testing.T) {
+	repo, logger := setupSlowQueryRepository(t, time.Minute, true)
+
+	_, err := repo.GetMetrics(context.Background(), &MetricsQuery{PipelineID: "pipeline-1"})
+	require.NoError(t, err)
+
+	assert.Empty(t, slowQueryLines(logger))
+}
+
+func TestSlowQueryLog_ArtificiallySlowQuery(t *testing.T) {
+	logger := &testLogger{}
+	var recorded []*PipelineMetric
+	l := newSlowQueryLog(&DatabaseConfig{SlowQueryThreshold: 100 * time.Millisecond}, logger, func(m *PipelineMetric) error {
+		recorded = append(recorded, m)
+		return nil
+	})
+
+	// A query that started a second ago is well past the threshold
+	l.observe("FlushMetricsBatch", "", time.Now().Add(-time.Second))
+	// One that only just started is not
+	l.observe("GetEvents", "SELECT 1", time.Now())
+
+	lines := slowQueryLines(logger)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "name=FlushMetricsBatch")
+
+	require.Len(t, recorded, 1)
+	assert.Equal(t, MetricSlowQuery, recorded[0].MetricName)
+	assert.Equal(t, "FlushMetricsBatch", recorded[0].Tags["query"])
+	assert.GreaterOrEqual(t, recorded[0].MetricValue, float64(1000))
+
+	// Disabled logs observe nothing
+	var disabled *slowQueryLog
+	disabled.observe("GetMetrics", "", time.Now().Add(-time.Hour))
+	newSlowQueryLog(&DatabaseConfig{}, logger, nil).observe("GetMetrics", "", time.Now().Add(-time.Hour))
+	assert.Len(t, slowQueryLines(logger), 1)
+}
+
+func TestDatabaseConfig_RejectsNegativeSlowQueryThreshold(t *testing.T) {
+	config := DefaultDatabaseConfig()
+	config.SlowQueryThreshold = -time.Second
+	assert.ErrorIs(t, config.Validate(), ErrInvalidSlowQueryThreshold)
+}
@@ -13,7 +13,12 @@ import (
 
 // Database represents the SQLite database for caching UMA data
 type Database struct {
-	db *sql.DB
+	staleReader
+	db        *sql.DB
+	ttlJitter float64
+
+	// Whether Maintain rebuilds indexes as well as analyzing
+	maintenanceReindex bool
 }
 
 // CacheEntry represents a cached item in the database
@@ -38,7 +43,22 @@ func NewDatabase(dbPath string) (*Database, error) {
 		return nil, fmt.Errorf("failed to initialize database: %v", err)
 	}
 
-	return &Database{db: db}, nil
+	return &Database{
+		staleReader: staleReader{db: db, staleGrace: DefaultStaleGrace},
+		db:          db,
+		ttlJitter:   uma.DefaultTTLJitter,
+	}, nil
+}
+
+// SetTTLJitter sets the ± fraction applied to cache TTLs; zero disables jitter
+func (d *Database) SetTTLJitter(jitter float64) {
+	d.ttlJitter = jitter
+}
+
+// OpenMetricsRepository creates a metrics repository sharing this database's
+// connection, so pipeline metrics live in the same file as the cache
+func (d *Database) OpenMetricsRepository(config *DatabaseConfig) (MetricsRepository, error) {
+	return NewMetricsRepository(d.db, config)
 }
 
 // initDatabase creates the necessary tables
@@ -149,9 +169,9 @@ func (d *Database) Close() error {
 	return d.db.Close()
 }
 
-// CleanExpiredCache removes expired cache entries
+// CleanExpiredCache removes cache entries that expired beyond the stale grace window
 func (d *Database) CleanExpiredCache() error {
-	now := time.Now()
+	cutoff := d.cleanupCutoff(time.Now())
 
 	queries := []string{
 		"DELETE FROM uma_cache WHERE expires_at < ?",
@@ -163,7 +183,7 @@ func (d *Database) CleanExpiredCache() error {
 	}
 
 	for _, query := range queries {
-		if _, err := d.db.Exec(query, now); err != nil {
+		if _, err := d.db.Exec(query, cutoff); err != nil {
 			return fmt.Errorf("failed to clean expired cache: %v", err)
 		}
 	}
@@ -178,7 +198,7 @@ func (d *Database) CacheCharacterSearch(query string, result *uma.CharacterSearc
 		return fmt.Errorf("failed to marshal character search result: %v", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, d.ttlJitter))
 
 	sqlQuery := `
 	INSERT OR REPLACE INTO character_search_cache (query, character_id, character_data, expires_at)
@@ -225,7 +245,7 @@ func (d *Database) CacheCharacterImages(characterID int, result *uma.CharacterIm
 		return fmt.Errorf("failed to marshal character images result: %v", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, d.ttlJitter))
 
 	query := `
 	INSERT OR REPLACE INTO character_images_cache (character_id, images_data, expires_at)
@@ -267,7 +287,7 @@ func (d *Database) CacheSupportCardSearch(query string, result *uma.SupportCardS
 		return fmt.Errorf("failed to marshal support card search result: %v", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, d.ttlJitter))
 
 	sqlQuery := `
 	INSERT OR REPLACE INTO support_card_search_cache (query, support_cards_data, expires_at)
@@ -309,7 +329,7 @@ func (d *Database) CacheSupportCardList(result *uma.SupportCardListResult, ttl t
 		return fmt.Errorf("failed to marshal support card list result: %v", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, d.ttlJitter))
 
 	query := `
 	INSERT OR REPLACE INTO support_card_list_cache (list_data, expires_at)
@@ -353,7 +373,7 @@ func (d *Database) CacheGametoraSkills(query string, result *uma.SimplifiedGamet
 		return fmt.Errorf("failed to marshal Gametora skills result: %v", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, d.ttlJitter))
 
 	sqlQuery := `
 	INSERT OR REPLACE INTO gametora_skills_cache (query, skills_data, expires_at)
@@ -388,6 +408,16 @@ func (d *Database) GetCachedGametoraSkills(query string) (*uma.SimplifiedGametor
 	return &result, nil
 }
 
+// ClearGametoraSkillsCache removes every cached Gametora skills result,
+// expired or not, and returns how many were removed
+func (d *Database) ClearGametoraSkillsCache() (int64, error) {
+	result, err := d.db.Exec("DELETE FROM gametora_skills_cache")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear Gametora skills cache: %v", err)
+	}
+	return result.RowsAffected()
+}
+
 // GetCacheStats returns cache statistics
 func (d *Database) GetCacheStats() (map[string]int, error) {
 	stats := make(map[string]int)
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultStaleSessionThreshold is how long an active session may go without
+// a metric or event before startup closes it as timed out
+const DefaultStaleSessionThreshold = 30 * time.Minute
+
+// StaleSession is an active session that has stopped reporting, such as one
+// left open when the bot crashed
+type StaleSession struct {
+	Session      *PipelineSession
+	LastActivity time.Time // latest metric or event, or the start if there are none
+}
+
+// GetStaleSessions returns active sessions that started before
+// lastActivityBefore and have recorded no metric or event since
+func (sq *SessionQueryExtensions) GetStaleSessions(ctx context.Context, lastActivityBefore time.Time) ([]*StaleSession, error) {
+	query := `
+		SELECT pipeline_id, guild_id, channel_id, user_id, stream_url, started_at, ended_at,
+		       final_state, total_errors, total_recoveries, quality_score, created_at
+		FROM pipeline_sessions s
+		WHERE ended_at IS NULL AND started_at < ?
+		  AND NOT EXISTS (SELECT 1 FROM pipeline_metrics m WHERE m.pipeline_id = s.pipeline_id AND m.timestamp >= ?)
+		  AND NOT EXISTS (SELECT 1 FROM pipeline_events e WHERE e.pipeline_id = s.pipeline_id AND e.timestamp >= ?)
+		ORDER BY started_at ASC
+	`
+
+	rows, err := sq.db.QueryContext(ctx, query, lastActivityBefore, lastActivityBefore, lastActivityBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale sessions: %w", err)
+	}
+
+	var sessions []*PipelineSession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	stale := make([]*StaleSession, 0, len(sessions))
+	for _, session := range sessions {
+		lastActivity, err := sq.lastSessionActivity(ctx, session)
+		if err != nil {
+			return nil, err
+		}
+		stale = append(stale, &StaleSession{Session: session, LastActivity: lastActivity})
+	}
+
+	return stale, nil
+}
+
+// lastSessionActivity returns the time of a session's latest metric or
+// event, or its start time if it has neither
+func (sq *SessionQueryExtensions) lastSessionActivity(ctx context.Context, session *PipelineSession) (time.Time, error) {
+	last := session.StartedAt
+	for _, table := range []string{"pipeline_metrics", "pipeline_events"} {
+		query := fmt.Sprintf(`SELECT timestamp FROM %s WHERE pipeline_id = ? ORDER BY timestamp DESC LIMIT 1`, table)
+
+		var timestamp time.Time
+		err := sq.db.QueryRowContext(ctx, query, session.PipelineID).Scan(&timestamp)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to query last activity for session %s: %w", session.PipelineID, err)
+		}
+		if timestamp.After(last) {
+			last = timestamp
+		}
+	}
+	return last, nil
+}
+
+// RepairStaleSessions closes every active session with no metric or event
+// within the config's StaleSessionThreshold, marking it timed out as of its
+// last activity. It returns how many sessions were repaired. Unlike
+// CleanupOrphanedSessions, which ends long-running sessions periodically,
+// this is meant to run once at startup, when no session can still be live.
+func (sm *SessionManager) RepairStaleSessions(ctx context.Context) (int, error) {
+	threshold := DefaultStaleSessionThreshold
+	if sm.config != nil {
+		threshold = sm.config.StaleSessionThreshold
+	}
+	if threshold <= 0 {
+		return 0, nil
+	}
+
+	stale, err := sm.metricsRepo.GetStaleSessions(ctx, time.Now().Add(-threshold))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get stale sessions: %w", err)
+	}
+
+	repaired := 0
+	for _, s := range stale {
+		endedAt := s.LastActivity
+		finalState := "timeout"
+		updates := &SessionUpdate{
+			EndedAt:    &endedAt,
+			FinalState: &finalState,
+		}
+
+		if err := sm.UpdateSession(ctx, s.Session.PipelineID, updates); err != nil {
+			log.Printf("Failed to repair stale session %s: %v", s.Session.PipelineID, err)
+			continue
+		}
+		repaired++
+	}
+
+	if len(stale) > 0 {
+		log.Printf("Repaired %d of %d stale sessions idle for over %v", repaired, len(stale), threshold)
+	}
+	return repaired, nil
+}
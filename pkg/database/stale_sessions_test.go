@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_StartRepairsStaleSessions(t *testing.T) {
+	dbPath := "test_stale_sessions.db"
+	defer os.Remove(dbPath)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.DatabasePath = dbPath
+	config.StaleSessionThreshold = 30 * time.Minute
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	seed := func(id string, startedAt time.Time) {
+		require.NoError(t, repo.CreateSession(ctx, &PipelineSession{
+			PipelineID: id,
+			GuildID:    "guild-1",
+			StartedAt:  startedAt,
+		}))
+	}
+
+	// Crashed mid-stream: last metric an hour ago
+	lastMetric := now.Add(-time.Hour).Truncate(time.Second)
+	seed("stale-metrics", now.Add(-2*time.Hour))
+	require.NoError(t, repo.StoreMetric(ctx, &PipelineMetric{
+		PipelineID: "stale-metrics", MetricName: "latency", MetricType: "gauge", MetricValue: 1, Timestamp: lastMetric,
+	}))
+
+	// Crashed mid-stream: last event 45 minutes ago, after an older metric
+	lastEvent := now.Add(-45 * time.Minute).Truncate(time.Second)
+	seed("stale-events", now.Add(-3*time.Hour))
+	require.NoError(t, repo.StoreMetric(ctx, &PipelineMetric{
+		PipelineID: "stale-events", MetricName: "latency", MetricType: "gauge", MetricValue: 1, Timestamp: now.Add(-2 * time.Hour),
+	}))
+	require.NoError(t, repo.StoreEvent(ctx, &PipelineEvent{
+		PipelineID: "stale-events", EventType: "error", Severity: "high", Timestamp: lastEvent,
+	}))
+
+	// Never reported anything
+	silentStart := now.Add(-time.Hour).Truncate(time.Second)
+	seed("stale-silent", silentStart)
+
+	// Old but still reporting
+	seed("live-reporting", now.Add(-2*time.Hour))
+	require.NoError(t, repo.StoreMetric(ctx, &PipelineMetric{
+		PipelineID: "live-reporting", MetricName: "latency", MetricType: "gauge", MetricValue: 1, Timestamp: now.Add(-time.Minute),
+	}))
+
+	// Just started
+	seed("live-new", now.Add(-time.Minute))
+
+	require.NoError(t, repo.FlushPendingMetrics())
+	require.Eventually(t, func() bool {
+		var stored int
+		return db.QueryRow("SELECT COUNT(*) FROM pipeline_metrics").Scan(&stored) == nil && stored == 3
+	}, 2*time.Second, 10*time.Millisecond)
+
+	sm := NewSessionManager(repo, config)
+	require.NoError(t, sm.Start())
+	defer sm.Stop()
+
+	for id, endedAt := range map[string]time.Time{
+		"stale-metrics": lastMetric,
+		"stale-events":  lastEvent,
+		"stale-silent":  silentStart,
+	} {
+		session, err := repo.GetSession(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "timeout", session.FinalState, id)
+		if assert.NotNil(t, session.EndedAt, id) {
+			assert.WithinDuration(t, endedAt, *session.EndedAt, time.Second, id)
+		}
+	}
+
+	active, err := sm.GetActiveSessions(ctx)
+	require.NoError(t, err)
+	var ids []string
+	for _, session := range active {
+		ids = append(ids, session.PipelineID)
+	}
+	assert.ElementsMatch(t, []string{"live-reporting", "live-new"}, ids)
+
+	// Nothing is left to repair
+	repaired, err := sm.RepairStaleSessions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, repaired)
+}
+
+func TestSessionManager_RepairStaleSessionsDisabled(t *testing.T) {
+	dbPath := "test_stale_sessions_disabled.db"
+	defer os.Remove(dbPath)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	config := DefaultDatabaseConfig()
+	config.DatabasePath = dbPath
+	config.StaleSessionThreshold = 0
+	repo, err := NewMetricsRepository(db, config)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+	require.NoError(t, repo.CreateSession(ctx, &PipelineSession{
+		PipelineID: "old",
+		GuildID:    "guild-1",
+		StartedAt:  time.Now().Add(-24 * time.Hour),
+	}))
+
+	sm := NewSessionManager(repo, config)
+	repaired, err := sm.RepairStaleSessions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, repaired)
+
+	session, err := repo.GetSession(ctx, "old")
+	require.NoError(t, err)
+	assert.Nil(t, session.EndedAt)
+}
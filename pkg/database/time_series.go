@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// maxTimeSeriesBuckets caps the points a time series query may return
+const maxTimeSeriesBuckets = 10000
+
+// GetTimeSeries aggregates a metric into buckets of query.Interval from
+// query.Start up to query.End, returning a point for every bucket so charts
+// stay evenly spaced. Buckets without samples take query.Fill, or stay null
+// when it is nil.
+func (r *metricsRepository) GetTimeSeries(ctx context.Context, query *TimeSeriesQuery) (*TimeSeries, error) {
+	if query.Interval <= 0 || !query.End.After(query.Start) {
+		return nil, ErrInvalidTimeSeries
+	}
+	buckets := int((query.End.Sub(query.Start) + query.Interval - 1) / query.Interval)
+	if buckets > maxTimeSeriesBuckets {
+		return nil, fmt.Errorf("%w: %d buckets, at most %d", ErrInvalidTimeSeries, buckets, maxTimeSeriesBuckets)
+	}
+
+	aggregation := query.Aggregation
+	if aggregation == "" {
+		aggregation = "avg"
+	}
+	switch aggregation {
+	case "sum", "avg", "min", "max", "count":
+	default:
+		return nil, fmt.Errorf("%w %q", ErrInvalidAggregation, query.Aggregation)
+	}
+
+	sqlQuery := `
+		SELECT metric_value, timestamp
+		FROM pipeline_metrics
+		WHERE metric_name = ? AND timestamp >= ? AND timestamp < ?
+	`
+	args := []interface{}{query.MetricName, query.Start, query.End}
+
+	if query.PipelineID != "" {
+		sqlQuery += " AND pipeline_id = ?"
+		args = append(args, query.PipelineID)
+	}
+
+	if query.MetricType != "" {
+		sqlQuery += " AND metric_type = ?"
+		args = append(args, query.MetricType)
+	}
+
+	defer r.batchProcessor.slowQueries.observe("GetTimeSeries", sqlQuery, time.Now())
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time series: %w", err)
+	}
+	defer rows.Close()
+
+	// Running aggregates per bucket
+	sums := make([]float64, buckets)
+	mins := make([]float64, buckets)
+	maxs := make([]float64, buckets)
+	samples := make([]int, buckets)
+
+	for rows.Next() {
+		var value float64
+		var timestamp time.Time
+		if err := rows.Scan(&value, &timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan time series sample: %w", err)
+		}
+
+		i := int(timestamp.Sub(query.Start) / query.Interval)
+		if i < 0 || i >= buckets {
+			continue
+		}
+		if samples[i] == 0 {
+			mins[i], maxs[i] = value, value
+		}
+		sums[i] += value
+		mins[i] = math.Min(mins[i], value)
+		maxs[i] = math.Max(maxs[i], value)
+		samples[i]++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating time series samples: %w", err)
+	}
+
+	series := &TimeSeries{
+		MetricName:  query.MetricName,
+		Aggregation: aggregation,
+		Interval:    query.Interval,
+		Points:      make([]TimeSeriesPoint, buckets),
+	}
+	for i := range series.Points {
+		point := TimeSeriesPoint{
+			Timestamp: query.Start.Add(time.Duration(i) * query.Interval),
+			Samples:   samples[i],
+		}
+
+		var value float64
+		switch {
+		case samples[i] == 0 && aggregation != "count":
+			if query.Fill != nil {
+				fill := *query.Fill
+				point.Value = &fill
+			}
+			series.Points[i] = point
+			continue
+		case aggregation == "sum":
+			value = sums[i]
+		case aggregation == "avg":
+			value = sums[i] / float64(samples[i])
+		case aggregation == "min":
+			value = mins[i]
+		case aggregation == "max":
+			value = maxs[i]
+		case aggregation == "count":
+			value = float64(samples[i])
+		}
+		point.Value = &value
+		series.Points[i] = point
+	}
+
+	return series, nil
+}
@@ -17,14 +17,51 @@ type DatabaseConfig struct {
 	MetricsFlushInterval time.Duration `json:"metrics_flush_interval" yaml:"metrics_flush_interval"`
 	MetricsRetention     time.Duration `json:"metrics_retention" yaml:"metrics_retention"`
 
+	// Distinct values a tag may take per metric name before new values are
+	// collapsed to HighCardinalityValue; zero disables the guard
+	MetricsTagCardinalityLimit int `json:"metrics_tag_cardinality_limit" yaml:"metrics_tag_cardinality_limit"`
+
+	// How flushed batches are written; trickle mode spreads batches larger
+	// than MetricsTrickleBatchSize over the flush interval
+	MetricsWriteMode        MetricsWriteMode `json:"metrics_write_mode" yaml:"metrics_write_mode"`
+	MetricsTrickleBatchSize int              `json:"metrics_trickle_batch_size" yaml:"metrics_trickle_batch_size"`
+
+	// Batch inserts running at once across every repository in the process,
+	// through a shared InsertPool; zero lets each repository write on its own
+	MetricsInsertPoolSize int `json:"metrics_insert_pool_size" yaml:"metrics_insert_pool_size"`
+
+	// Metrics rollup settings
+	MetricsRollupEnabled  bool          `json:"metrics_rollup_enabled" yaml:"metrics_rollup_enabled"`
+	MetricsRollupInterval time.Duration `json:"metrics_rollup_interval" yaml:"metrics_rollup_interval"` // how often the latest buckets are recomputed
+	MetricsRollupBucket   time.Duration `json:"metrics_rollup_bucket" yaml:"metrics_rollup_bucket"`     // width of each pre-aggregated bucket
+
 	// UMA cache settings
 	UMACacheRetention       time.Duration `json:"uma_cache_retention" yaml:"uma_cache_retention"`
 	UMACacheCleanupInterval time.Duration `json:"uma_cache_cleanup_interval" yaml:"uma_cache_cleanup_interval"`
+	UMACacheTTLJitter       float64       `json:"uma_cache_ttl_jitter" yaml:"uma_cache_ttl_jitter"`   // ± fraction applied to cache TTLs
+	UMACacheStaleGrace      time.Duration `json:"uma_cache_stale_grace" yaml:"uma_cache_stale_grace"` // how long expired entries may be served on upstream failure
+
+	// Weights of the per-session quality score signals
+	SessionQualityWeights QualityWeights `json:"session_quality_weights" yaml:"session_quality_weights"`
+
+	// Event storage settings
+	EventCompression          bool `json:"event_compression" yaml:"event_compression"`                     // gzip large event_data payloads
+	EventCompressionThreshold int  `json:"event_compression_threshold" yaml:"event_compression_threshold"` // payload size in bytes above which to compress
+
+	// Slow query logging; a zero threshold disables it
+	SlowQueryThreshold time.Duration `json:"slow_query_threshold" yaml:"slow_query_threshold"`
+	SlowQueryLogSQL    bool          `json:"slow_query_log_sql" yaml:"slow_query_log_sql"` // include the SQL text in the log line
+
+	// Sessions still active at startup with no metric or event for this long
+	// are closed as timed out; zero disables the repair
+	StaleSessionThreshold time.Duration `json:"stale_session_threshold" yaml:"stale_session_threshold"`
 
 	// Performance settings
 	WALMode         bool   `json:"wal_mode" yaml:"wal_mode"`
 	SynchronousMode string `json:"synchronous_mode" yaml:"synchronous_mode"`
-	CacheSize       int    `json:"cache_size" yaml:"cache_size"`
+	CacheSize       int    `json:"cache_size" yaml:"cache_size"` // pages, or KiB when negative; applied per connection
+	PageSize        int    `json:"page_size" yaml:"page_size"`   // bytes; only applied when the database file is created
+	MmapSize        int64  `json:"mmap_size" yaml:"mmap_size"`   // bytes of memory-mapped I/O per connection; zero disables
 
 	// Backup settings
 	BackupEnabled   bool          `json:"backup_enabled" yaml:"backup_enabled"`
@@ -43,12 +80,37 @@ func DefaultDatabaseConfig() *DatabaseConfig {
 		MetricsFlushInterval: 30 * time.Second,
 		MetricsRetention:     7 * 24 * time.Hour, // 7 days
 
+		MetricsTagCardinalityLimit: DefaultMetricsTagCardinalityLimit,
+
+		MetricsWriteMode:        MetricsWriteOneShot,
+		MetricsTrickleBatchSize: DefaultMetricsTrickleBatchSize,
+
+		MetricsInsertPoolSize: 0,
+
+		MetricsRollupEnabled:  true,
+		MetricsRollupInterval: DefaultMetricsRollupInterval,
+		MetricsRollupBucket:   DefaultMetricsRollupBucket,
+
 		UMACacheRetention:       24 * time.Hour, // 1 day
 		UMACacheCleanupInterval: 1 * time.Hour,  // 1 hour
+		UMACacheTTLJitter:       0.1,            // ±10%
+		UMACacheStaleGrace:      DefaultStaleGrace,
+
+		SessionQualityWeights: DefaultQualityWeights(),
+
+		EventCompression:          false,
+		EventCompressionThreshold: DefaultEventCompressionThreshold,
+
+		SlowQueryThreshold: DefaultSlowQueryThreshold,
+		SlowQueryLogSQL:    false,
+
+		StaleSessionThreshold: DefaultStaleSessionThreshold,
 
 		WALMode:         true,
 		SynchronousMode: "NORMAL",
 		CacheSize:       -64000, // 64MB
+		PageSize:        DefaultPageSize,
+		MmapSize:        DefaultMmapSize,
 
 		BackupEnabled:   false,
 		BackupInterval:  24 * time.Hour, // Daily
@@ -76,15 +138,57 @@ func (c *DatabaseConfig) Validate() error {
 	if c.MetricsRetention <= 0 {
 		return ErrInvalidMetricsRetention
 	}
+	if c.MetricsTagCardinalityLimit < 0 {
+		return ErrInvalidMetricsTagCardinality
+	}
+	if !c.MetricsWriteMode.valid() {
+		return ErrInvalidMetricsWriteMode
+	}
+	if c.MetricsTrickleBatchSize < 0 {
+		return ErrInvalidMetricsTrickleBatchSize
+	}
+	if c.MetricsInsertPoolSize < 0 {
+		return ErrInvalidMetricsInsertPoolSize
+	}
+	if c.MetricsRollupEnabled && c.MetricsRollupInterval <= 0 {
+		return ErrInvalidMetricsRollupInterval
+	}
+	if c.MetricsRollupEnabled && (c.MetricsRollupBucket < time.Second || c.MetricsRollupBucket%time.Second != 0) {
+		return ErrInvalidMetricsRollupBucket
+	}
 	if c.UMACacheRetention <= 0 {
 		return ErrInvalidUMACacheRetention
 	}
 	if c.UMACacheCleanupInterval <= 0 {
 		return ErrInvalidUMACacheCleanupInterval
 	}
+	if c.UMACacheTTLJitter < 0 || c.UMACacheTTLJitter >= 1 {
+		return ErrInvalidUMACacheTTLJitter
+	}
+	if c.UMACacheStaleGrace < 0 {
+		return ErrInvalidUMACacheStaleGrace
+	}
+	if !c.SessionQualityWeights.validate() {
+		return ErrInvalidSessionQualityWeights
+	}
+	if c.EventCompressionThreshold < 0 {
+		return ErrInvalidEventCompressionThreshold
+	}
+	if c.SlowQueryThreshold < 0 {
+		return ErrInvalidSlowQueryThreshold
+	}
+	if c.StaleSessionThreshold < 0 {
+		return ErrInvalidStaleSessionThreshold
+	}
 	if c.SynchronousMode != "OFF" && c.SynchronousMode != "NORMAL" && c.SynchronousMode != "FULL" {
 		return ErrInvalidSynchronousMode
 	}
+	if !validPageSize(c.PageSize) {
+		return ErrInvalidPageSize
+	}
+	if c.MmapSize < 0 {
+		return ErrInvalidMmapSize
+	}
 	return nil
 }
 
@@ -149,6 +253,20 @@ type PipelineMetric struct {
 	CreatedAt   time.Time              `json:"created_at"`
 }
 
+// PipelineMetricTop is one pipeline's entry in a top-N ranking of a metric
+type PipelineMetricTop struct {
+	PipelineID string  `json:"pipeline_id"`
+	Value      float64 `json:"value"`   // highest value in the window
+	Samples    int64   `json:"samples"` // data points in the window
+}
+
+// Gap is a stretch of time in which a pipeline recorded no metrics
+type Gap struct {
+	Start    time.Time     `json:"start"` // timestamp of the last metric before the gap
+	End      time.Time     `json:"end"`   // timestamp of the first metric after it
+	Duration time.Duration `json:"duration"`
+}
+
 // PipelineSession represents a pipeline session
 type PipelineSession struct {
 	ID              string     `json:"id"`
@@ -162,6 +280,7 @@ type PipelineSession struct {
 	FinalState      string     `json:"final_state,omitempty"`
 	TotalErrors     int        `json:"total_errors"`
 	TotalRecoveries int        `json:"total_recoveries"`
+	QualityScore    *float64   `json:"quality_score,omitempty"` // 0–100, set when the session ends
 	CreatedAt       time.Time  `json:"created_at"`
 }
 
@@ -169,8 +288,9 @@ type PipelineSession struct {
 type SessionUpdate struct {
 	EndedAt         *time.Time `json:"ended_at,omitempty"`
 	FinalState      *string    `json:"final_state,omitempty"`
-	TotalErrors     *int       `json:"total_errors,omitempty"`
-	TotalRecoveries *int       `json:"total_recoveries,omitempty"`
+	TotalErrors     *int       `json:"total_errors,omitempty"`     // left unchanged when nil
+	TotalRecoveries *int       `json:"total_recoveries,omitempty"` // left unchanged when nil
+	QualityScore    *float64   `json:"quality_score,omitempty"`    // left unchanged when nil
 }
 
 // PipelineEvent represents a pipeline event
@@ -224,6 +344,34 @@ type AggregatedMetricPoint struct {
 	Timestamp time.Time         `json:"timestamp"`
 }
 
+// TimeSeriesQuery asks for a metric aggregated into evenly spaced buckets
+// from Start up to End
+type TimeSeriesQuery struct {
+	PipelineID  string        `json:"pipeline_id,omitempty"`
+	MetricName  string        `json:"metric_name"`
+	MetricType  string        `json:"metric_type,omitempty"`
+	Aggregation string        `json:"aggregation"` // sum, avg, min, max, count
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Interval    time.Duration `json:"interval"`
+	Fill        *float64      `json:"fill,omitempty"` // value of empty buckets; nil leaves them null
+}
+
+// TimeSeries is a metric with one point per bucket in the queried range
+type TimeSeries struct {
+	MetricName  string            `json:"metric_name"`
+	Aggregation string            `json:"aggregation"`
+	Interval    time.Duration     `json:"interval"`
+	Points      []TimeSeriesPoint `json:"points"`
+}
+
+// TimeSeriesPoint is one bucket of a time series
+type TimeSeriesPoint struct {
+	Timestamp time.Time `json:"timestamp"` // start of the bucket
+	Value     *float64  `json:"value"`     // nil when the bucket is empty and there is no fill
+	Samples   int       `json:"samples"`
+}
+
 // EventQuery represents a query for events
 type EventQuery struct {
 	PipelineID string     `json:"pipeline_id,omitempty"`
@@ -11,7 +11,9 @@ import (
 
 // umaRepository implements the UMARepository interface
 type umaRepository struct {
-	db *sql.DB
+	staleReader
+	db        *sql.DB
+	ttlJitter float64
 }
 
 // NewUMARepository creates a new UMA repository
@@ -21,7 +23,9 @@ func NewUMARepository(db *sql.DB) (UMARepository, error) {
 	}
 
 	repo := &umaRepository{
-		db: db,
+		staleReader: staleReader{db: db, staleGrace: DefaultStaleGrace},
+		db:          db,
+		ttlJitter:   uma.DefaultTTLJitter,
 	}
 
 	// Initialize UMA-specific tables
@@ -32,6 +36,11 @@ func NewUMARepository(db *sql.DB) (UMARepository, error) {
 	return repo, nil
 }
 
+// SetTTLJitter sets the ± fraction applied to cache TTLs; zero disables jitter
+func (r *umaRepository) SetTTLJitter(jitter float64) {
+	r.ttlJitter = jitter
+}
+
 // initializeTables creates the UMA cache tables if they don't exist
 func (r *umaRepository) initializeTables() error {
 	queries := []string{
@@ -114,7 +123,7 @@ func (r *umaRepository) CacheCharacterSearch(query string, result *uma.Character
 		return fmt.Errorf("failed to marshal character search result: %w", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, r.ttlJitter))
 
 	sqlQuery := `
 	INSERT OR REPLACE INTO character_search_cache (query, character_id, character_data, expires_at)
@@ -165,7 +174,7 @@ func (r *umaRepository) CacheCharacterImages(characterID int, result *uma.Charac
 		return fmt.Errorf("failed to marshal character images result: %w", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, r.ttlJitter))
 
 	query := `
 	INSERT OR REPLACE INTO character_images_cache (character_id, images_data, expires_at)
@@ -211,7 +220,7 @@ func (r *umaRepository) CacheSupportCardSearch(query string, result *uma.Support
 		return fmt.Errorf("failed to marshal support card search result: %w", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, r.ttlJitter))
 
 	sqlQuery := `
 	INSERT OR REPLACE INTO support_card_search_cache (query, support_cards_data, expires_at)
@@ -257,7 +266,7 @@ func (r *umaRepository) CacheSupportCardList(result *uma.SupportCardListResult,
 		return fmt.Errorf("failed to marshal support card list result: %w", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, r.ttlJitter))
 
 	query := `
 	INSERT OR REPLACE INTO support_card_list_cache (list_data, expires_at)
@@ -305,7 +314,7 @@ func (r *umaRepository) CacheGametoraSkills(query string, result *uma.Simplified
 		return fmt.Errorf("failed to marshal Gametora skills result: %w", err)
 	}
 
-	expiresAt := time.Now().Add(ttl)
+	expiresAt := time.Now().Add(uma.JitterTTL(ttl, r.ttlJitter))
 
 	sqlQuery := `
 	INSERT OR REPLACE INTO gametora_skills_cache (query, skills_data, expires_at)
@@ -344,9 +353,19 @@ func (r *umaRepository) GetCachedGametoraSkills(query string) (*uma.SimplifiedGa
 	return &result, nil
 }
 
-// CleanExpiredCache removes expired cache entries
+// ClearGametoraSkillsCache removes every cached Gametora skills result,
+// expired or not, and returns how many were removed
+func (r *umaRepository) ClearGametoraSkillsCache() (int64, error) {
+	result, err := r.db.Exec("DELETE FROM gametora_skills_cache")
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear Gametora skills cache: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// CleanExpiredCache removes cache entries that expired beyond the stale grace window
 func (r *umaRepository) CleanExpiredCache() error {
-	now := time.Now()
+	cutoff := r.cleanupCutoff(time.Now())
 
 	queries := []string{
 		"DELETE FROM uma_cache WHERE expires_at < ?",
@@ -358,7 +377,7 @@ func (r *umaRepository) CleanExpiredCache() error {
 	}
 
 	for _, query := range queries {
-		if _, err := r.db.Exec(query, now); err != nil {
+		if _, err := r.db.Exec(query, cutoff); err != nil {
 			return fmt.Errorf("failed to clean expired cache: %w", err)
 		}
 	}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
@@ -201,6 +202,16 @@ func TestUMARepository_GametoraSkills(t *testing.T) {
 	notFound, err := repo.GetCachedGametoraSkills("non-existent")
 	assert.NoError(t, err)
 	assert.Nil(t, notFound)
+
+	// Test clearing
+	require.NoError(t, repo.CacheGametoraSkills("another skill", result, ttl))
+	cleared, err := repo.ClearGametoraSkillsCache()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cleared)
+
+	cached, err = repo.GetCachedGametoraSkills(query)
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
 }
 
 func TestUMARepository_CleanExpiredCache(t *testing.T) {
@@ -275,3 +286,120 @@ func TestNewUMARepository_NilDB(t *testing.T) {
 	assert.Nil(t, repo)
 	assert.Contains(t, err.Error(), "database connection is nil")
 }
+
+func TestUMARepository_TTLJitter(t *testing.T) {
+	repo, cleanup := setupTestUMARepository(t)
+	defer cleanup()
+
+	repo.SetTTLJitter(0.2)
+	ttl := 10 * time.Hour
+
+	before := time.Now()
+	for i := 0; i < 20; i++ {
+		result := &uma.SupportCardSearchResult{Found: true, Query: "card"}
+		require.NoError(t, repo.CacheSupportCardSearch(fmt.Sprintf("card-%d", i), result, ttl))
+	}
+	after := time.Now()
+
+	db := repo.(*umaRepository).db
+	rows, err := db.Query("SELECT expires_at FROM support_card_search_cache")
+	require.NoError(t, err)
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var expiresAt time.Time
+		require.NoError(t, rows.Scan(&expiresAt))
+		assert.False(t, expiresAt.Before(before.Add(8*time.Hour)), "expiry %v below jitter band", expiresAt)
+		assert.False(t, expiresAt.After(after.Add(12*time.Hour)), "expiry %v above jitter band", expiresAt)
+		count++
+	}
+	assert.Equal(t, 20, count)
+}
+
+func TestUMARepository_ServeStale(t *testing.T) {
+	newResult := func() *uma.CharacterSearchResult {
+		return &uma.CharacterSearchResult{
+			Found:     true,
+			Character: &uma.Character{ID: 123, NameEn: "Test Character"},
+		}
+	}
+
+	t.Run("fresh hit", func(t *testing.T) {
+		repo, cleanup := setupTestUMARepository(t)
+		defer cleanup()
+
+		hookCalls := 0
+		repo.SetStaleHook(func(string, string, time.Duration) { hookCalls++ })
+		require.NoError(t, repo.CacheCharacterSearch("fresh", newResult(), time.Hour))
+
+		cached, err := repo.GetStaleCharacterSearch("fresh")
+		require.NoError(t, err)
+		require.NotNil(t, cached)
+		assert.False(t, cached.Stale)
+		assert.Equal(t, 0, hookCalls)
+	})
+
+	t.Run("stale within grace", func(t *testing.T) {
+		repo, cleanup := setupTestUMARepository(t)
+		defer cleanup()
+
+		repo.SetTTLJitter(0)
+		repo.SetStaleGrace(24 * time.Hour)
+
+		var hookType, hookKey string
+		var hookAge time.Duration
+		repo.SetStaleHook(func(cacheType, key string, expiredFor time.Duration) {
+			hookType, hookKey, hookAge = cacheType, key, expiredFor
+		})
+
+		require.NoError(t, repo.CacheCharacterSearch("stale", newResult(), -time.Hour))
+
+		// The normal read ignores the expired entry
+		fresh, err := repo.GetCachedCharacterSearch("stale")
+		require.NoError(t, err)
+		assert.Nil(t, fresh)
+
+		cached, err := repo.GetStaleCharacterSearch("stale")
+		require.NoError(t, err)
+		require.NotNil(t, cached)
+		assert.True(t, cached.Stale)
+		assert.Equal(t, 123, cached.Character.ID)
+
+		assert.Equal(t, "character_search", hookType)
+		assert.Equal(t, "stale", hookKey)
+		assert.GreaterOrEqual(t, hookAge, time.Hour)
+
+		// Cleanup keeps entries that are still inside the grace window
+		require.NoError(t, repo.CleanExpiredCache())
+		cached, err = repo.GetStaleCharacterSearch("stale")
+		require.NoError(t, err)
+		assert.NotNil(t, cached)
+	})
+
+	t.Run("expired beyond grace", func(t *testing.T) {
+		repo, cleanup := setupTestUMARepository(t)
+		defer cleanup()
+
+		repo.SetTTLJitter(0)
+		repo.SetStaleGrace(time.Hour)
+		require.NoError(t, repo.CacheCharacterSearch("old", newResult(), -2*time.Hour))
+
+		cached, err := repo.GetStaleCharacterSearch("old")
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		repo, cleanup := setupTestUMARepository(t)
+		defer cleanup()
+
+		repo.SetTTLJitter(0)
+		repo.SetStaleGrace(0)
+		require.NoError(t, repo.CacheCharacterSearch("stale", newResult(), -time.Minute))
+
+		cached, err := repo.GetStaleCharacterSearch("stale")
+		require.NoError(t, err)
+		assert.Nil(t, cached)
+	})
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// DefaultStaleGrace is how long past expiry a UMA cache entry may still be
+// served when the upstream API is unavailable
+const DefaultStaleGrace = 72 * time.Hour
+
+// StaleHook is called whenever an expired cache entry is served. expiredFor
+// is how long ago the entry expired.
+type StaleHook func(cacheType, key string, expiredFor time.Duration)
+
+// staleReader provides the serve-stale reads shared by the UMA cache
+// implementations. Expired rows are kept for the grace window by
+// CleanExpiredCache so they can be returned here when a fresh fetch fails.
+type staleReader struct {
+	db         *sql.DB
+	staleGrace time.Duration
+	staleHook  StaleHook
+}
+
+// SetStaleGrace sets how long past expiry entries may be served; zero disables serve-stale
+func (s *staleReader) SetStaleGrace(grace time.Duration) {
+	s.staleGrace = grace
+}
+
+// SetStaleHook sets the hook called when an expired entry is served
+func (s *staleReader) SetStaleHook(hook StaleHook) {
+	s.staleHook = hook
+}
+
+// cleanupCutoff returns the expiry time before which entries can be deleted
+func (s *staleReader) cleanupCutoff(now time.Time) time.Time {
+	return now.Add(-s.staleGrace)
+}
+
+// readStale loads the newest row for the query that expired no longer than
+// the grace window ago and decodes it into dest. It reports whether a row
+// was found and whether that row was actually expired.
+func (s *staleReader) readStale(cacheType, key, query string, dest interface{}, args ...interface{}) (found, stale bool, err error) {
+	if s.staleGrace <= 0 {
+		return false, false, nil
+	}
+
+	now := time.Now()
+	args = append(args, now.Add(-s.staleGrace))
+
+	var data string
+	var expiresAt time.Time
+	err = s.db.QueryRow(query, args...).Scan(&data, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, fmt.Errorf("failed to get stale %s: %w", cacheType, err)
+	}
+
+	if err := json.Unmarshal([]byte(data), dest); err != nil {
+		return false, false, fmt.Errorf("failed to unmarshal stale %s: %w", cacheType, err)
+	}
+
+	stale = expiresAt.Before(now)
+	if stale && s.staleHook != nil {
+		s.staleHook(cacheType, key, now.Sub(expiresAt))
+	}
+
+	return true, stale, nil
+}
+
+// GetStaleCharacterSearch retrieves a character search result that may have
+// expired within the grace window
+func (s *staleReader) GetStaleCharacterSearch(query string) (*uma.CharacterSearchResult, error) {
+	var result uma.CharacterSearchResult
+	found, stale, err := s.readStale("character_search", query, `
+	SELECT character_data, expires_at FROM character_search_cache
+	WHERE query = ? AND expires_at > ?
+	`, &result, query)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	result.Stale = stale
+	return &result, nil
+}
+
+// GetStaleCharacterImages retrieves character images that may have expired
+// within the grace window
+func (s *staleReader) GetStaleCharacterImages(characterID int) (*uma.CharacterImagesResult, error) {
+	var result uma.CharacterImagesResult
+	found, stale, err := s.readStale("character_images", fmt.Sprintf("%d", characterID), `
+	SELECT images_data, expires_at FROM character_images_cache
+	WHERE character_id = ? AND expires_at > ?
+	`, &result, characterID)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	result.Stale = stale
+	return &result, nil
+}
+
+// GetStaleSupportCardSearch retrieves a support card search result that may
+// have expired within the grace window
+func (s *staleReader) GetStaleSupportCardSearch(query string) (*uma.SupportCardSearchResult, error) {
+	var result uma.SupportCardSearchResult
+	found, stale, err := s.readStale("support_card_search", query, `
+	SELECT support_cards_data, expires_at FROM support_card_search_cache
+	WHERE query = ? AND expires_at > ?
+	`, &result, query)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	result.Stale = stale
+	return &result, nil
+}
+
+// GetStaleSupportCardList retrieves the support card list that may have
+// expired within the grace window
+func (s *staleReader) GetStaleSupportCardList() (*uma.SupportCardListResult, error) {
+	var result uma.SupportCardListResult
+	found, stale, err := s.readStale("support_card_list", "", `
+	SELECT list_data, expires_at FROM support_card_list_cache
+	WHERE expires_at > ?
+	ORDER BY created_at DESC
+	LIMIT 1
+	`, &result)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	result.Stale = stale
+	return &result, nil
+}
+
+// GetStaleGametoraSkills retrieves a Gametora skills search result that may
+// have expired within the grace window
+func (s *staleReader) GetStaleGametoraSkills(query string) (*uma.SimplifiedGametoraSearchResult, error) {
+	var result uma.SimplifiedGametoraSearchResult
+	found, stale, err := s.readStale("gametora_skills", query, `
+	SELECT skills_data, expires_at FROM gametora_skills_cache
+	WHERE query = ? AND expires_at > ?
+	`, &result, query)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	result.Stale = stale
+	return &result, nil
+}
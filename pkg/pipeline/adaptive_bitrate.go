@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Metrics the adaptive bitrate controller watches
+const (
+	MetricUnderruns    = "pipeline.buffer.underruns" // counter: frames not ready when due
+	MetricFramesBehind = "pipeline.frames_behind"    // gauge: frames queued behind real time
+	MetricBufferStalls = "pipeline.buffer.stalls"    // counter: times the frame buffer stayed full
+)
+
+const (
+	// DefaultAdaptiveInterval is how often playback health is judged
+	DefaultAdaptiveInterval = 5 * time.Second
+
+	// adaptiveTroubleWindows consecutive troubled intervals step the bitrate
+	// down; adaptiveStableWindows clean ones step it back up
+	adaptiveTroubleWindows = 3
+	adaptiveStableWindows  = 6
+
+	// adaptiveFramesBehindLimit is how far behind (100ms of 20ms frames)
+	// playback may fall before an interval counts as troubled
+	adaptiveFramesBehindLimit = 5
+
+	// adaptiveStepFactor scales the bitrate on each step down
+	adaptiveStepFactor = 0.75
+)
+
+// AdaptiveBitrateController lowers the Opus bitrate while playback keeps
+// underrunning, falling behind, or leaving the frame buffer full because the
+// encoder can't keep up, and raises it back once playback has been
+// stable for a while. It watches metrics by acting as the pipeline's
+// MetricRecorder, forwarding everything to the recorder it wraps.
+type AdaptiveBitrateController struct {
+	next  MetricRecorder
+	apply func(bitrate int)
+
+	mu      sync.Mutex
+	events  EventRecorder
+	target  int // configured bitrate, restored when stable
+	min     int
+	current int
+
+	underruns    int64   // underruns and buffer stalls since the last evaluation
+	framesBehind float64 // highest seen since the last evaluation
+	troubled     int     // consecutive troubled evaluations
+	stable       int     // consecutive stable evaluations
+}
+
+// NewAdaptiveBitrateController creates a controller starting at config's
+// bitrate and staying within its MinBitrate and MaxBitrate. apply is called
+// with each new bitrate; metrics are forwarded to next.
+func NewAdaptiveBitrateController(config OpusConfig, apply func(bitrate int), next MetricRecorder) *AdaptiveBitrateController {
+	if next == nil {
+		next = NoopMetricRecorder{}
+	}
+
+	target := config.Bitrate
+	if config.MaxBitrate > 0 && target > config.MaxBitrate {
+		target = config.MaxBitrate
+	}
+	min := config.MinBitrate
+	if min <= 0 || min > target {
+		min = target
+	}
+
+	return &AdaptiveBitrateController{
+		next:    next,
+		apply:   apply,
+		target:  target,
+		min:     min,
+		current: target,
+	}
+}
+
+// SetEventRecorder sets where bitrate changes are recorded. Nil disables it.
+func (c *AdaptiveBitrateController) SetEventRecorder(events EventRecorder) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = events
+}
+
+// Bitrate returns the bitrate currently applied
+func (c *AdaptiveBitrateController) Bitrate() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+// Counter implements MetricRecorder
+func (c *AdaptiveBitrateController) Counter(name string, value int64, tags map[string]string) {
+	if name == MetricUnderruns || name == MetricBufferStalls {
+		c.mu.Lock()
+		c.underruns += value
+		c.mu.Unlock()
+	}
+	c.next.Counter(name, value, tags)
+}
+
+// Gauge implements MetricRecorder
+func (c *AdaptiveBitrateController) Gauge(name string, value float64, tags map[string]string) {
+	if name == MetricFramesBehind {
+		c.mu.Lock()
+		if value > c.framesBehind {
+			c.framesBehind = value
+		}
+		c.mu.Unlock()
+	}
+	c.next.Gauge(name, value, tags)
+}
+
+// Histogram implements MetricRecorder
+func (c *AdaptiveBitrateController) Histogram(name string, value float64, tags map[string]string) {
+	c.next.Histogram(name, value, tags)
+}
+
+// Timing implements MetricRecorder
+func (c *AdaptiveBitrateController) Timing(name string, duration time.Duration, tags map[string]string) {
+	c.next.Timing(name, duration, tags)
+}
+
+// Run evaluates playback every interval until ctx is done
+func (c *AdaptiveBitrateController) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultAdaptiveInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Evaluate()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Evaluate judges the interval since the last call and steps the bitrate
+// down after sustained trouble or up after sustained stability. It returns
+// the bitrate in effect afterwards.
+func (c *AdaptiveBitrateController) Evaluate() int {
+	c.mu.Lock()
+
+	trouble := c.underruns > 0 || c.framesBehind >= adaptiveFramesBehindLimit
+	c.underruns = 0
+	c.framesBehind = 0
+
+	if trouble {
+		c.troubled++
+		c.stable = 0
+	} else {
+		c.stable++
+		c.troubled = 0
+	}
+
+	from := c.current
+	reason := ""
+	switch {
+	case c.troubled >= adaptiveTroubleWindows && c.current > c.min:
+		c.current = int(float64(c.current) * adaptiveStepFactor)
+		if c.current < c.min {
+			c.current = c.min
+		}
+		c.troubled = 0
+		reason = "sustained_underruns"
+	case c.stable >= adaptiveStableWindows && c.current < c.target:
+		c.current = int(float64(c.current) / adaptiveStepFactor)
+		if c.current > c.target {
+			c.current = c.target
+		}
+		c.stable = 0
+		reason = "stable"
+	}
+
+	to := c.current
+	events := c.events
+	c.mu.Unlock()
+
+	if reason == "" {
+		return to
+	}
+
+	if c.apply != nil {
+		c.apply(to)
+	}
+	c.next.Gauge("pipeline.opus.bitrate", float64(to), nil)
+	if events != nil {
+		events.RecordEvent("bitrate_changed", "low", map[string]interface{}{
+			"from":   from,
+			"to":     to,
+			"reason": reason,
+		})
+	}
+	return to
+}
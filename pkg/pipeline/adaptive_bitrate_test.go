@@ -0,0 +1,139 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adaptiveTestConfig() OpusConfig {
+	config := DefaultPipelineConfig().Opus
+	config.AdaptiveMode = true
+	config.Bitrate = 128000
+	config.MinBitrate = 64000
+	config.MaxBitrate = 256000
+	return config
+}
+
+func TestAdaptiveBitrate_StepsDownOnSustainedUnderruns(t *testing.T) {
+	var applied []int
+	spy := &spyRecorder{}
+	events := &spyEventRecorder{}
+	controller := NewAdaptiveBitrateController(adaptiveTestConfig(), func(bitrate int) {
+		applied = append(applied, bitrate)
+	}, spy)
+	controller.SetEventRecorder(events)
+
+	// A single bad interval is not sustained trouble
+	controller.Counter(MetricUnderruns, 2, nil)
+	assert.Equal(t, 128000, controller.Evaluate())
+	assert.Equal(t, 128000, controller.Evaluate())
+
+	for i := 0; i < adaptiveTroubleWindows; i++ {
+		controller.Counter(MetricUnderruns, 1, nil)
+		controller.Evaluate()
+	}
+	assert.Equal(t, 96000, controller.Bitrate())
+
+	// Falling behind counts as trouble too
+	for i := 0; i < adaptiveTroubleWindows; i++ {
+		controller.Gauge(MetricFramesBehind, 8, nil)
+		controller.Evaluate()
+	}
+	assert.Equal(t, 72000, controller.Bitrate())
+
+	// The floor holds no matter how long trouble lasts
+	for i := 0; i < 4*adaptiveTroubleWindows; i++ {
+		controller.Counter(MetricUnderruns, 1, nil)
+		controller.Evaluate()
+	}
+	assert.Equal(t, 64000, controller.Bitrate())
+
+	assert.Equal(t, []int{96000, 72000, 64000}, applied)
+	require.Len(t, events.events, 3)
+	assert.Equal(t, "bitrate_changed", events.events[0].eventType)
+	assert.Equal(t, 128000, events.events[0].data["from"])
+	assert.Equal(t, 96000, events.events[0].data["to"])
+	assert.Equal(t, "sustained_underruns", events.events[0].data["reason"])
+
+	// Watched metrics still reach the wrapped recorder
+	assert.Equal(t, 1+adaptiveTroubleWindows+4*adaptiveTroubleWindows, spy.count("counter", MetricUnderruns))
+	assert.Equal(t, 3, spy.count("gauge", "pipeline.opus.bitrate"))
+}
+
+func TestAdaptiveBitrate_StepsDownOnBufferStalls(t *testing.T) {
+	controller := NewAdaptiveBitrateController(adaptiveTestConfig(), nil, nil)
+
+	// A frame buffer that stays full means the encoder can't keep up
+	for i := 0; i < adaptiveTroubleWindows; i++ {
+		controller.Counter(MetricBufferStalls, 1, nil)
+		controller.Evaluate()
+	}
+	assert.Equal(t, 96000, controller.Bitrate())
+}
+
+func TestAdaptiveBitrate_RestoresWhenStable(t *testing.T) {
+	var applied []int
+	controller := NewAdaptiveBitrateController(adaptiveTestConfig(), func(bitrate int) {
+		applied = append(applied, bitrate)
+	}, nil)
+
+	for i := 0; i < adaptiveTroubleWindows; i++ {
+		controller.Counter(MetricUnderruns, 1, nil)
+		controller.Evaluate()
+	}
+	require.Equal(t, 96000, controller.Bitrate())
+
+	// Frames slightly behind are within tolerance
+	for i := 0; i < adaptiveStableWindows-1; i++ {
+		controller.Gauge(MetricFramesBehind, 2, nil)
+		controller.Evaluate()
+	}
+	assert.Equal(t, 96000, controller.Bitrate())
+
+	controller.Evaluate()
+	assert.Equal(t, 128000, controller.Bitrate())
+
+	// Never above the configured bitrate
+	for i := 0; i < 2*adaptiveStableWindows; i++ {
+		controller.Evaluate()
+	}
+	assert.Equal(t, 128000, controller.Bitrate())
+	assert.Equal(t, []int{96000, 128000}, applied)
+}
+
+func TestAdaptiveBitrate_DisabledByDefault(t *testing.T) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	require.NoError(t, err)
+	assert.Nil(t, manager.EnableAdaptiveBitrate(func(int) {}))
+
+	config := DefaultPipelineConfig()
+	config.Opus.AdaptiveMode = true
+	manager, err = NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	defer manager.Stop()
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+	controller := manager.EnableAdaptiveBitrate(func(int) {})
+	require.NotNil(t, controller)
+
+	manager.metrics.RecordUnderrun()
+	assert.Equal(t, 1, spy.count("counter", MetricUnderruns))
+}
+
+func TestAdaptiveBitrate_Validation(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Opus.AdaptiveMode = true
+	assert.NoError(t, config.Validate())
+
+	config.Opus.MinBitrate = 0
+	assert.Error(t, config.Validate())
+
+	config.Opus.MinBitrate = 192000
+	assert.Error(t, config.Validate())
+
+	config.Opus.AdaptiveMode = false
+	assert.NoError(t, config.Validate())
+}
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"time"
+)
+
+// Recovery backoff defaults: retries wait 1s, 2s and 4s before the pipeline
+// fails
+const (
+	DefaultBackoffInitialDelay = time.Second
+	DefaultBackoffMaxDelay     = 30 * time.Second
+	DefaultBackoffMultiplier   = 2.0
+	DefaultBackoffMaxRetries   = 3
+)
+
+// MetricRecoveryAttempt counts recovery retries made under the backoff
+// policy, tagged with the strategy and the category of the error being
+// recovered from
+const MetricRecoveryAttempt = "pipeline.recovery_attempt"
+
+// Delay returns the wait before the nth retry, counting from 1: InitialDelay
+// grown by Multiplier for each retry before it, capped at MaxDelay
+func (b BackoffConfig) Delay(retry int) time.Duration {
+	delay := float64(b.InitialDelay)
+	for i := 1; i < retry && delay < float64(b.MaxDelay); i++ {
+		delay *= b.Multiplier
+	}
+	if delay > float64(b.MaxDelay) {
+		return b.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// retriesWithBackoff reports whether recovering from errors of a category
+// follows the backoff policy. Network and stream errors tend to clear up
+// given time; other categories retry as often as their strategy allows.
+func retriesWithBackoff(category ErrorCategory) bool {
+	return category == CategoryNetwork || category == CategoryStream
+}
+
+// waitRecoveryBackoff logs and counts a retry, then waits out its delay. It
+// returns false if the pipeline stopped in the meantime.
+func (apm *AudioPipelineManager) waitRecoveryBackoff(strategy string, category ErrorCategory, attempt int, delay time.Duration) bool {
+	apm.logger.Info("Retrying pipeline recovery after backoff",
+		String("strategy", strategy),
+		String("category", category.String()),
+		Int("attempt", attempt),
+		Duration("delay", delay),
+	)
+	apm.metrics.RecordPipelineCounter(MetricRecoveryAttempt, 1, map[string]string{
+		"strategy": strategy,
+		"category": category.String(),
+	})
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-apm.ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,150 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStrategy never recovers and counts how often it tried
+type failingStrategy struct {
+	calls int32
+}
+
+func (s *failingStrategy) Name() string                       { return "failing" }
+func (s *failingStrategy) CanRecover(err *PipelineError) bool { return true }
+func (s *failingStrategy) Recover(ctx context.Context, pipeline PipelineManager) error {
+	atomic.AddInt32(&s.calls, 1)
+	return errors.New("still down")
+}
+func (s *failingStrategy) Priority() int    { return 10 }
+func (s *failingStrategy) MaxAttempts() int { return 1 }
+
+func TestBackoffConfig_Delay(t *testing.T) {
+	backoff := BackoffConfig{
+		InitialDelay: 100 * time.Millisecond,
+		MaxDelay:     time.Second,
+		Multiplier:   3,
+	}
+
+	assert.Equal(t, 100*time.Millisecond, backoff.Delay(1))
+	assert.Equal(t, 300*time.Millisecond, backoff.Delay(2))
+	assert.Equal(t, 900*time.Millisecond, backoff.Delay(3))
+	assert.Equal(t, time.Second, backoff.Delay(4))
+	assert.Equal(t, time.Second, backoff.Delay(100))
+}
+
+func TestManager_RetriesNetworkErrorsWithBackoff(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Recovery.Backoff = BackoffConfig{
+		InitialDelay: 5 * time.Millisecond,
+		MaxDelay:     12 * time.Millisecond,
+		Multiplier:   2,
+		MaxRetries:   3,
+	}
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+	strategy := &failingStrategy{}
+	manager.AddRecoveryStrategy(strategy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	manager.ReportError(errors.New("connection reset by peer"), CategoryNetwork, SeverityMedium)
+
+	require.Eventually(t, func() bool {
+		return manager.GetState() == StateFailed
+	}, time.Second, 5*time.Millisecond)
+
+	// The first attempt plus MaxRetries, ignoring the strategy's own limit
+	assert.Equal(t, int32(4), atomic.LoadInt32(&strategy.calls))
+
+	retries := spy.named("counter", MetricRecoveryAttempt)
+	require.Len(t, retries, 3)
+	for _, retry := range retries {
+		assert.Equal(t, "network", retry.tags["category"])
+		assert.Equal(t, "failing", retry.tags["strategy"])
+	}
+
+	// Waits of 5ms, 10ms and 12ms, capped at MaxDelay
+	durations := spy.named("histogram", MetricRecoveryDuration)
+	require.Len(t, durations, 1)
+	assert.GreaterOrEqual(t, durations[0].value, 27.0)
+}
+
+func TestManager_RetriesOtherErrorsWithoutBackoff(t *testing.T) {
+	manager, spy := startRecoveringManager(t, &failingStrategy{})
+
+	manager.ReportError(errors.New("ffmpeg exited"), CategoryProcess, SeverityHigh)
+
+	require.Eventually(t, func() bool {
+		return manager.GetState() == StateFailed
+	}, time.Second, 5*time.Millisecond)
+	assert.Empty(t, spy.named("counter", MetricRecoveryAttempt))
+}
+
+func TestManager_StopInterruptsBackoff(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Recovery.Backoff.InitialDelay = time.Hour
+	config.Recovery.Backoff.MaxDelay = time.Hour
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+
+	strategy := &failingStrategy{}
+	manager.AddRecoveryStrategy(strategy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	manager.ReportError(errors.New("stream ended early"), CategoryStream, SeverityMedium)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&strategy.calls) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, manager.Stop())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&strategy.calls))
+	assert.NotEqual(t, StateFailed, manager.GetState())
+}
+
+func TestBackoffFromEnvironment(t *testing.T) {
+	t.Setenv("PIPELINE_RECOVERY_BACKOFF_INITIAL_DELAY", "500ms")
+	t.Setenv("PIPELINE_RECOVERY_BACKOFF_MAX_DELAY", "10s")
+	t.Setenv("PIPELINE_RECOVERY_BACKOFF_MULTIPLIER", "1.5")
+	t.Setenv("PIPELINE_RECOVERY_BACKOFF_MAX_RETRIES", "5")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.Equal(t, BackoffConfig{
+		InitialDelay: 500 * time.Millisecond,
+		MaxDelay:     10 * time.Second,
+		Multiplier:   1.5,
+		MaxRetries:   5,
+	}, config.Recovery.Backoff)
+	require.NoError(t, config.Validate())
+}
+
+func TestPipelineConfig_ValidateBackoff(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Recovery.Backoff.Multiplier = 0.5
+	assert.Error(t, config.Validate())
+
+	config = DefaultPipelineConfig()
+	config.Recovery.Backoff.MaxDelay = config.Recovery.Backoff.InitialDelay / 2
+	assert.Error(t, config.Validate())
+
+	config = DefaultPipelineConfig()
+	config.Recovery.Backoff.MaxRetries = -1
+	assert.Error(t, config.Validate())
+
+	config = DefaultPipelineConfig()
+	config.Recovery.Backoff.Multiplier = 1
+	config.Recovery.Backoff.MaxDelay = config.Recovery.Backoff.InitialDelay
+	assert.NoError(t, config.Validate())
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,11 +13,17 @@ type PipelineConfig struct {
 	StreamAcquisition StreamAcquisitionConfig `json:"stream_acquisition"`
 	FFmpeg           FFmpegConfig            `json:"ffmpeg"`
 	Opus             OpusConfig              `json:"opus"`
+	Audio            AudioConfig             `json:"audio"`
 	Health           HealthConfig            `json:"health"`
 	Recovery         RecoveryConfig          `json:"recovery"`
 	Resources        ResourceConfig          `json:"resources"`
 	Logging          LoggingConfig           `json:"logging"`
+	Metrics          MetricsConfig           `json:"metrics"`
 	Discord          DiscordConfig           `json:"discord"`
+	Features         Features                `json:"features"`
+	
+	// Settings merged over the rest when a stream of that kind starts
+	Profiles map[SourceKind]ConfigProfile `json:"profiles,omitempty"`
 }
 
 // StreamAcquisitionConfig contains configuration for stream acquisition
@@ -27,6 +34,8 @@ type StreamAcquisitionConfig struct {
 	ValidationTimeout time.Duration `json:"validation_timeout"`
 	UserAgent        string        `json:"user_agent"`
 	Strategies       []string      `json:"strategies"`
+	AllowedHosts     []string      `json:"allowed_hosts"`
+	BlockedHosts     []string      `json:"blocked_hosts"`
 }
 
 // FFmpegConfig contains configuration for FFmpeg processing
@@ -46,9 +55,39 @@ type OpusConfig struct {
 	Bitrate          int  `json:"bitrate"`
 	Complexity       int  `json:"complexity"`
 	FrameSize        int  `json:"frame_size"`
-	AdaptiveMode     bool `json:"adaptive_mode"`
+	AdaptiveMode     bool `json:"adaptive_mode"` // step bitrate down on sustained underruns
 	MaxBitrate       int  `json:"max_bitrate"`
 	MinBitrate       int  `json:"min_bitrate"`
+	
+	// Encoder tuning: voip, audio or lowdelay
+	Application string `json:"application"`
+	
+	// Loss resilience. FEC embeds a low bitrate copy of each frame in the
+	// next for the receiver to recover from; it is sized by the expected
+	// PacketLoss percentage. DTX stops sending frames during silence.
+	FEC        bool `json:"fec"`
+	PacketLoss int  `json:"packet_loss"`
+	DTX        bool `json:"dtx"`
+}
+
+// Opus application modes, trading quality against latency
+const (
+	OpusApplicationVoIP     = "voip"     // tuned for speech intelligibility
+	OpusApplicationAudio    = "audio"    // highest fidelity; the default for music
+	OpusApplicationLowDelay = "lowdelay" // lowest latency, no speech-only modes
+)
+
+// AudioConfig contains configuration for processing the decoded audio
+type AudioConfig struct {
+	// EBU R128 loudness normalization through FFmpeg's loudnorm filter,
+	// towards TargetLUFS integrated loudness
+	NormalizeLoudness bool    `json:"normalize_loudness"`
+	TargetLUFS        float64 `json:"target_lufs"`
+	
+	// Opus encoder overrides, e.g. 64kbps for voice-heavy streams or
+	// 128kbps for music. Zero keeps the Opus section's bitrate and frame size.
+	OpusBitrate       int           `json:"opus_bitrate"`
+	OpusFrameDuration time.Duration `json:"opus_frame_duration"`
 }
 
 // HealthConfig contains configuration for health monitoring
@@ -68,6 +107,23 @@ type RecoveryConfig struct {
 	InitialDelay     time.Duration `json:"initial_delay"`
 	MaxDelay         time.Duration `json:"max_delay"`
 	Strategies       []string      `json:"strategies"`
+	
+	// Budget caps recoveries to BudgetMaxRecoveries per BudgetWindow across
+	// the pipeline's lifetime, or across a queue's tracks for the player's
+	// stream restarts; zero disables the cap
+	BudgetMaxRecoveries int           `json:"budget_max_recoveries"`
+	BudgetWindow        time.Duration `json:"budget_window"`
+	
+	// Backoff spaces out the retries of network and stream errors
+	Backoff BackoffConfig `json:"backoff"`
+}
+
+// BackoffConfig contains the exponential backoff between recovery retries
+type BackoffConfig struct {
+	InitialDelay time.Duration `json:"initial_delay"` // wait before the first retry
+	MaxDelay     time.Duration `json:"max_delay"`     // longest wait between retries
+	Multiplier   float64       `json:"multiplier"`    // growth of the wait per retry
+	MaxRetries   int           `json:"max_retries"`   // retries after the first attempt before failing
 }
 
 // ResourceConfig contains configuration for resource management
@@ -88,15 +144,30 @@ type LoggingConfig struct {
 	EnableTracing    bool   `json:"enable_tracing"`
 	RotateSize       int64  `json:"rotate_size"`
 	RotateCount      int    `json:"rotate_count"`
+	MaxRecordSize    int    `json:"max_record_size"` // bytes; larger records have their biggest field values truncated, 0 disables
+	
+	// Fields such as instance, env or region attached to every log record
+	// and tagged onto every pipeline metric, to tell instances apart
+	StaticFields map[string]string `json:"static_fields,omitempty"`
+}
+
+// MetricsConfig contains configuration for exported metrics
+type MetricsConfig struct {
+	// Smoothing factors, from 0 to 1, of gauges also exported as an
+	// exponential moving average under their name plus ".ema"
+	GaugeSmoothing map[string]float64 `json:"gauge_smoothing,omitempty"`
 }
 
 // DiscordConfig contains configuration for Discord integration
 type DiscordConfig struct {
-	ReconnectAttempts int           `json:"reconnect_attempts"`
-	ReconnectDelay    time.Duration `json:"reconnect_delay"`
-	SpeakingTimeout   time.Duration `json:"speaking_timeout"`
-	BufferSize        int           `json:"buffer_size"`
-	SendTimeout       time.Duration `json:"send_timeout"`
+	ReconnectAttempts      int           `json:"reconnect_attempts"`
+	ReconnectDelay         time.Duration `json:"reconnect_delay"`
+	SpeakingTimeout        time.Duration `json:"speaking_timeout"`
+	BufferSize             int           `json:"buffer_size"`
+	SendTimeout            time.Duration `json:"send_timeout"`
+	KeepAliveOnPause       bool          `json:"keep_alive_on_pause"` // send silence frames while paused
+	KeepAliveInterval      time.Duration `json:"keep_alive_interval"`
+	PositionUpdateInterval time.Duration `json:"position_update_interval"` // how often PositionUpdates ticks
 }
 
 // DefaultPipelineConfig returns a configuration with sensible defaults
@@ -111,7 +182,7 @@ func DefaultPipelineConfig() *PipelineConfig {
 			Strategies:        []string{"yt-dlp-default", "yt-dlp-fallback"},
 		},
 		FFmpeg: FFmpegConfig{
-			BinaryPath:  "ffmpeg",
+			BinaryPath:  DefaultFFmpegBinary,
 			BufferSize:  "64k",
 			Timeout:     30 * time.Second,
 			MaxRestarts: 3,
@@ -132,9 +203,14 @@ func DefaultPipelineConfig() *PipelineConfig {
 			Bitrate:      128000,
 			Complexity:   10,
 			FrameSize:    960,
-			AdaptiveMode: true,
+			AdaptiveMode: false,
 			MaxBitrate:   256000,
 			MinBitrate:   64000,
+			Application:  OpusApplicationAudio,
+		},
+		Audio: AudioConfig{
+			NormalizeLoudness: false,
+			TargetLUFS:        DefaultLoudnessTargetLUFS,
 		},
 		Health: HealthConfig{
 			Enabled:          true,
@@ -148,12 +224,20 @@ func DefaultPipelineConfig() *PipelineConfig {
 			},
 		},
 		Recovery: RecoveryConfig{
-			Enabled:         true,
-			MaxAttempts:     3,
-			BackoffStrategy: "exponential",
-			InitialDelay:    1 * time.Second,
-			MaxDelay:        30 * time.Second,
-			Strategies:      []string{"quick-retry", "stream-refresh", "process-restart"},
+			Enabled:             true,
+			MaxAttempts:         3,
+			BackoffStrategy:     "exponential",
+			InitialDelay:        1 * time.Second,
+			MaxDelay:            30 * time.Second,
+			Strategies:          []string{"quick-retry", "stream-refresh", "process-restart"},
+			BudgetMaxRecoveries: 10,
+			BudgetWindow:        10 * time.Minute,
+			Backoff: BackoffConfig{
+				InitialDelay: DefaultBackoffInitialDelay,
+				MaxDelay:     DefaultBackoffMaxDelay,
+				Multiplier:   DefaultBackoffMultiplier,
+				MaxRetries:   DefaultBackoffMaxRetries,
+			},
 		},
 		Resources: ResourceConfig{
 			MaxCPUUsage:     80.0,
@@ -170,14 +254,19 @@ func DefaultPipelineConfig() *PipelineConfig {
 			EnableTracing: false,
 			RotateSize:    10 * 1024 * 1024, // 10MB
 			RotateCount:   5,
+			MaxRecordSize: DefaultMaxLogRecordSize,
 		},
 		Discord: DiscordConfig{
-			ReconnectAttempts: 3,
-			ReconnectDelay:    2 * time.Second,
-			SpeakingTimeout:   10 * time.Second,
-			BufferSize:        100,
-			SendTimeout:       100 * time.Millisecond,
+			ReconnectAttempts:      3,
+			ReconnectDelay:         2 * time.Second,
+			SpeakingTimeout:        10 * time.Second,
+			BufferSize:             100,
+			SendTimeout:            100 * time.Millisecond,
+			KeepAliveOnPause:       false,
+			KeepAliveInterval:      DefaultKeepAliveInterval,
+			PositionUpdateInterval: DefaultPositionUpdateInterval,
 		},
+		Profiles: DefaultProfiles(),
 	}
 }
 
@@ -196,6 +285,14 @@ func (c *PipelineConfig) LoadFromEnvironment() {
 		}
 	}
 	
+	if val := os.Getenv("PIPELINE_STREAM_ALLOWED_HOSTS"); val != "" {
+		c.StreamAcquisition.AllowedHosts = strings.Split(val, ",")
+	}
+	
+	if val := os.Getenv("PIPELINE_STREAM_BLOCKED_HOSTS"); val != "" {
+		c.StreamAcquisition.BlockedHosts = strings.Split(val, ",")
+	}
+	
 	// FFmpeg
 	if val := os.Getenv("PIPELINE_FFMPEG_PATH"); val != "" {
 		c.FFmpeg.BinaryPath = val
@@ -220,6 +317,48 @@ func (c *PipelineConfig) LoadFromEnvironment() {
 		}
 	}
 	
+	if val := os.Getenv("PIPELINE_OPUS_ADAPTIVE_MODE"); val != "" {
+		c.Opus.AdaptiveMode = val == "true" || val == "1"
+	}
+	
+	if val := os.Getenv("PIPELINE_OPUS_APPLICATION"); val != "" {
+		c.Opus.Application = strings.ToLower(val)
+	}
+	
+	if val := os.Getenv("PIPELINE_OPUS_FEC"); val != "" {
+		c.Opus.FEC = val == "true" || val == "1"
+	}
+	
+	if val := os.Getenv("PIPELINE_OPUS_PACKET_LOSS"); val != "" {
+		if loss, err := strconv.Atoi(val); err == nil {
+			c.Opus.PacketLoss = loss
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_OPUS_DTX"); val != "" {
+		c.Opus.DTX = val == "true" || val == "1"
+	}
+	
+	// Audio; setting a target turns normalization on
+	if val := os.Getenv("PIPELINE_AUDIO_NORMALIZE_LUFS"); val != "" {
+		if lufs, err := strconv.ParseFloat(val, 64); err == nil {
+			c.Audio.NormalizeLoudness = true
+			c.Audio.TargetLUFS = lufs
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_AUDIO_OPUS_BITRATE"); val != "" {
+		if bitrate, err := strconv.Atoi(val); err == nil {
+			c.Audio.OpusBitrate = bitrate
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_AUDIO_OPUS_FRAME_DURATION"); val != "" {
+		if duration, err := time.ParseDuration(val); err == nil {
+			c.Audio.OpusFrameDuration = duration
+		}
+	}
+	
 	// Health
 	if val := os.Getenv("PIPELINE_HEALTH_ENABLED"); val != "" {
 		c.Health.Enabled = val == "true" || val == "1"
@@ -242,6 +381,42 @@ func (c *PipelineConfig) LoadFromEnvironment() {
 		}
 	}
 	
+	if val := os.Getenv("PIPELINE_RECOVERY_BUDGET_MAX"); val != "" {
+		if max, err := strconv.Atoi(val); err == nil {
+			c.Recovery.BudgetMaxRecoveries = max
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_RECOVERY_BUDGET_WINDOW"); val != "" {
+		if window, err := time.ParseDuration(val); err == nil {
+			c.Recovery.BudgetWindow = window
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_RECOVERY_BACKOFF_INITIAL_DELAY"); val != "" {
+		if delay, err := time.ParseDuration(val); err == nil {
+			c.Recovery.Backoff.InitialDelay = delay
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_RECOVERY_BACKOFF_MAX_DELAY"); val != "" {
+		if delay, err := time.ParseDuration(val); err == nil {
+			c.Recovery.Backoff.MaxDelay = delay
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_RECOVERY_BACKOFF_MULTIPLIER"); val != "" {
+		if multiplier, err := strconv.ParseFloat(val, 64); err == nil {
+			c.Recovery.Backoff.Multiplier = multiplier
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_RECOVERY_BACKOFF_MAX_RETRIES"); val != "" {
+		if retries, err := strconv.Atoi(val); err == nil {
+			c.Recovery.Backoff.MaxRetries = retries
+		}
+	}
+	
 	// Resources
 	if val := os.Getenv("PIPELINE_MAX_CPU_USAGE"); val != "" {
 		if cpu, err := strconv.ParseFloat(val, 64); err == nil {
@@ -255,6 +430,23 @@ func (c *PipelineConfig) LoadFromEnvironment() {
 		}
 	}
 	
+	// Discord
+	if val := os.Getenv("PIPELINE_DISCORD_KEEP_ALIVE_ON_PAUSE"); val != "" {
+		c.Discord.KeepAliveOnPause = val == "true" || val == "1"
+	}
+	
+	if val := os.Getenv("PIPELINE_DISCORD_KEEP_ALIVE_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil {
+			c.Discord.KeepAliveInterval = interval
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_DISCORD_POSITION_INTERVAL"); val != "" {
+		if interval, err := time.ParseDuration(val); err == nil {
+			c.Discord.PositionUpdateInterval = interval
+		}
+	}
+	
 	// Logging
 	if val := os.Getenv("PIPELINE_LOG_LEVEL"); val != "" {
 		c.Logging.Level = val
@@ -263,6 +455,23 @@ func (c *PipelineConfig) LoadFromEnvironment() {
 	if val := os.Getenv("PIPELINE_LOG_FORMAT"); val != "" {
 		c.Logging.Format = val
 	}
+	
+	if val := os.Getenv("PIPELINE_LOG_MAX_RECORD_SIZE"); val != "" {
+		if size, err := strconv.Atoi(val); err == nil {
+			c.Logging.MaxRecordSize = size
+		}
+	}
+	
+	if val := os.Getenv("PIPELINE_LOG_STATIC_FIELDS"); val != "" {
+		c.Logging.StaticFields = parseStaticFields(val)
+	}
+	
+	if val := os.Getenv("PIPELINE_METRICS_GAUGE_SMOOTHING"); val != "" {
+		c.Metrics.GaugeSmoothing = parseGaugeSmoothing(val)
+	}
+	
+	// Feature flags
+	c.Features = loadFeatures(os.Environ())
 }
 
 // Validate validates the configuration and returns any errors
@@ -278,6 +487,14 @@ func (c *PipelineConfig) Validate() error {
 		errors = append(errors, "stream acquisition retry_delay must be >= 0")
 	}
 	
+	for _, hosts := range [][]string{c.StreamAcquisition.AllowedHosts, c.StreamAcquisition.BlockedHosts} {
+		for _, pattern := range normalizeHostPatterns(hosts) {
+			if err := validateHostPattern(pattern); err != nil {
+				errors = append(errors, "stream acquisition "+err.Error())
+			}
+		}
+	}
+	
 	// Validate FFmpeg
 	if c.FFmpeg.BinaryPath == "" {
 		errors = append(errors, "ffmpeg binary_path cannot be empty")
@@ -304,6 +521,54 @@ func (c *PipelineConfig) Validate() error {
 		errors = append(errors, "opus complexity must be between 0 and 10")
 	}
 	
+	if c.Opus.AdaptiveMode && (c.Opus.MinBitrate <= 0 || c.Opus.MinBitrate > c.EncoderConfig().Bitrate || c.Opus.MaxBitrate < c.Opus.MinBitrate) {
+		errors = append(errors, "opus adaptive_mode requires 0 < min_bitrate <= bitrate and max_bitrate >= min_bitrate")
+	}
+	
+	validOpusApplications := map[string]bool{
+		OpusApplicationVoIP: true, OpusApplicationAudio: true, OpusApplicationLowDelay: true,
+	}
+	if !validOpusApplications[c.Opus.Application] {
+		errors = append(errors, "opus application must be one of: voip, audio, lowdelay")
+	}
+	
+	if c.Opus.PacketLoss < 0 || c.Opus.PacketLoss > 100 {
+		errors = append(errors, "opus packet_loss must be between 0 and 100")
+	}
+	
+	// FEC and DTX both work through Opus's SILK layer, which lowdelay turns off
+	if c.Opus.FEC && c.Opus.Application == OpusApplicationLowDelay {
+		errors = append(errors, "opus fec is not available in the lowdelay application")
+	}
+	
+	if c.Opus.FEC && c.Opus.PacketLoss == 0 {
+		errors = append(errors, "opus fec requires packet_loss > 0")
+	}
+	
+	if c.Opus.DTX && c.Opus.Application == OpusApplicationLowDelay {
+		errors = append(errors, "opus dtx is not available in the lowdelay application")
+	}
+	
+	// Validate audio
+	if c.Audio.NormalizeLoudness && (c.Audio.TargetLUFS < MinLoudnessTargetLUFS || c.Audio.TargetLUFS > MaxLoudnessTargetLUFS) {
+		errors = append(errors, fmt.Sprintf("audio target_lufs must be between %.0f and %.0f", MinLoudnessTargetLUFS, MaxLoudnessTargetLUFS))
+	}
+	
+	if c.Audio.OpusBitrate != 0 && (c.Audio.OpusBitrate < MinOpusBitrate || c.Audio.OpusBitrate > MaxOpusBitrate) {
+		errors = append(errors, fmt.Sprintf("audio opus_bitrate must be between %d and %d", MinOpusBitrate, MaxOpusBitrate))
+	}
+	
+	if c.Audio.OpusFrameDuration != 0 && !isOpusFrameDuration(c.Audio.OpusFrameDuration) {
+		errors = append(errors, "audio opus_frame_duration must be one of: 2.5ms, 5ms, 10ms, 20ms, 40ms, 60ms")
+	} else if c.Audio.OpusFrameDuration != 0 && c.Audio.OpusFrameDuration != DiscordOpusFrameDuration {
+		errors = append(errors, fmt.Sprintf("audio opus_frame_duration %s is not supported: Discord voice only plays %s frames", c.Audio.OpusFrameDuration, DiscordOpusFrameDuration))
+	}
+	
+	// FEC is carried by Opus's SILK layer, which only codes frames of 10ms or more
+	if c.Opus.FEC && c.Audio.OpusFrameDuration != 0 && c.Audio.OpusFrameDuration < 10*time.Millisecond {
+		errors = append(errors, "opus fec requires an audio opus_frame_duration of at least 10ms")
+	}
+	
 	// Validate health
 	if c.Health.CheckInterval <= 0 {
 		errors = append(errors, "health check_interval must be > 0")
@@ -322,6 +587,39 @@ func (c *PipelineConfig) Validate() error {
 		errors = append(errors, "recovery initial_delay must be >= 0")
 	}
 	
+	if c.Recovery.BudgetMaxRecoveries < 0 {
+		errors = append(errors, "recovery budget_max_recoveries must be >= 0")
+	}
+	
+	if c.Recovery.BudgetMaxRecoveries > 0 && c.Recovery.BudgetWindow <= 0 {
+		errors = append(errors, "recovery budget_window must be > 0 when a budget is set")
+	}
+	
+	if c.Recovery.Backoff.InitialDelay < 0 {
+		errors = append(errors, "recovery backoff initial_delay must be >= 0")
+	}
+	
+	if c.Recovery.Backoff.MaxDelay < c.Recovery.Backoff.InitialDelay {
+		errors = append(errors, "recovery backoff max_delay must be >= initial_delay")
+	}
+	
+	if c.Recovery.Backoff.Multiplier < 1 {
+		errors = append(errors, "recovery backoff multiplier must be >= 1")
+	}
+	
+	if c.Recovery.Backoff.MaxRetries < 0 {
+		errors = append(errors, "recovery backoff max_retries must be >= 0")
+	}
+	
+	// Validate Discord
+	if c.Discord.KeepAliveOnPause && c.Discord.KeepAliveInterval <= 0 {
+		errors = append(errors, "discord keep_alive_interval must be > 0 when keep_alive_on_pause is set")
+	}
+	
+	if c.Discord.PositionUpdateInterval <= 0 {
+		errors = append(errors, "discord position_update_interval must be > 0")
+	}
+	
 	// Validate resources
 	if c.Resources.MaxCPUUsage < 0 || c.Resources.MaxCPUUsage > 100 {
 		errors = append(errors, "resources max_cpu_usage must be between 0 and 100")
@@ -345,6 +643,17 @@ func (c *PipelineConfig) Validate() error {
 	if !validLogFormats[c.Logging.Format] {
 		errors = append(errors, "logging format must be one of: json, text, console")
 	}
+	if c.Logging.MaxRecordSize < 0 {
+		errors = append(errors, "logging max_record_size must be >= 0")
+	}
+	
+	errors = append(errors, c.Logging.validateStaticFields()...)
+	
+	// Validate metrics
+	errors = append(errors, c.Metrics.validateGaugeSmoothing()...)
+	
+	// Validate profiles
+	errors = append(errors, c.validateProfiles()...)
 	
 	if len(errors) > 0 {
 		return fmt.Errorf("configuration validation failed: %v", errors)
@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldChange describes a single configuration field that differs between
+// two configs. Field is the dotted JSON path, e.g. "opus.bitrate".
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Diff returns the fields that differ between c and other, in declaration
+// order. Values come from the redacted configs, so a changed secret is
+// reported without revealing either value.
+func (c PipelineConfig) Diff(other PipelineConfig) []FieldChange {
+	var changes []FieldChange
+	diffValues("",
+		reflect.ValueOf(c), reflect.ValueOf(other),
+		reflect.ValueOf(c.Redacted()), reflect.ValueOf(other.Redacted()),
+		&changes)
+	return changes
+}
+
+// diffValues walks the raw values to detect changes and reports the matching
+// redacted values
+func diffValues(path string, oldRaw, newRaw, oldRedacted, newRedacted reflect.Value, changes *[]FieldChange) {
+	if oldRaw.Kind() == reflect.Struct {
+		t := oldRaw.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			diffValues(joinFieldPath(path, fieldName(field)),
+				oldRaw.Field(i), newRaw.Field(i),
+				oldRedacted.Field(i), newRedacted.Field(i),
+				changes)
+		}
+		return
+	}
+
+	if reflect.DeepEqual(oldRaw.Interface(), newRaw.Interface()) {
+		return
+	}
+
+	*changes = append(*changes, FieldChange{
+		Field: path,
+		Old:   oldRedacted.Interface(),
+		New:   newRedacted.Interface(),
+	})
+}
+
+// fieldName returns the JSON name of a struct field, falling back to the Go name
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" {
+		if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// joinFieldPath appends name to a dotted path
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineConfigDiff(t *testing.T) {
+	oldConfig := DefaultPipelineConfig()
+	newConfig := DefaultPipelineConfig()
+
+	newConfig.Opus.Bitrate = 96000
+	newConfig.Recovery.MaxDelay = time.Minute
+	newConfig.Health.Checks = append(newConfig.Health.Checks, "latency")
+	newConfig.FFmpeg.ReconnectOptions = map[string]string{"auth_token": "new-secret"}
+
+	changes := oldConfig.Diff(*newConfig)
+	byField := make(map[string]FieldChange, len(changes))
+	for _, change := range changes {
+		byField[change.Field] = change
+	}
+	require.Len(t, byField, 4, "unexpected changes: %+v", changes)
+
+	assert.Equal(t, oldConfig.Opus.Bitrate, byField["opus.bitrate"].Old)
+	assert.Equal(t, 96000, byField["opus.bitrate"].New)
+
+	assert.Equal(t, oldConfig.Recovery.MaxDelay, byField["recovery.max_delay"].Old)
+	assert.Equal(t, time.Minute, byField["recovery.max_delay"].New)
+
+	assert.Contains(t, byField["health.checks"].New, "latency")
+
+	// Secrets are reported as changed but never revealed
+	secret, ok := byField["ffmpeg.reconnect_options"]
+	require.True(t, ok)
+	assert.NotContains(t, secret.New, "new-secret")
+	assert.Equal(t, map[string]string{"auth_token": RedactedValue}, secret.New)
+}
+
+func TestPipelineConfigDiffIdentical(t *testing.T) {
+	config := DefaultPipelineConfig()
+	assert.Empty(t, config.Diff(*DefaultPipelineConfig()))
+}
+
+func TestReloadConfig(t *testing.T) {
+	manager, err := NewAudioPipelineManager(DefaultPipelineConfig(), NullLogger())
+	require.NoError(t, err)
+
+	events := &spyEventRecorder{}
+	manager.SetEventRecorder(events)
+
+	newConfig := DefaultPipelineConfig()
+	newConfig.Opus.Bitrate = 64000
+
+	changes, err := manager.ReloadConfig(newConfig)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "opus.bitrate", changes[0].Field)
+
+	require.Len(t, events.events, 1)
+	assert.Equal(t, "config_reloaded", events.events[0].eventType)
+	assert.Equal(t, changes, events.events[0].data["changes"])
+
+	// Invalid configs are rejected and not applied
+	invalid := DefaultPipelineConfig()
+	invalid.Opus.SampleRate = 0
+	_, err = manager.ReloadConfig(invalid)
+	assert.Error(t, err)
+	assert.Len(t, events.events, 1)
+}
+
+type recordedEvent struct {
+	eventType string
+	severity  string
+	data      map[string]interface{}
+}
+
+type spyEventRecorder struct {
+	events []recordedEvent
+}
+
+func (s *spyEventRecorder) RecordEvent(eventType, severity string, data map[string]interface{}) {
+	s.events = append(s.events, recordedEvent{eventType, severity, data})
+}
@@ -75,6 +75,20 @@
 //
 // The metrics system supports counters, gauges, histograms, and timing measurements.
 // Metrics are tagged and can be aggregated for monitoring and alerting.
+// Every pipeline metric is also forwarded to a MetricRecorder set with
+// SetMetricRecorder; the default discards them, database.PipelineRecorder
+// persists them through the database metrics repository, and StatsDRecorder
+// sends them to a StatsD or DogStatsD agent over UDP.
+//
+// With Opus.AdaptiveMode on, EnableAdaptiveBitrate wraps the recorder in an
+// AdaptiveBitrateController that steps the bitrate down on sustained
+// underruns and back up once playback is stable. The bot's player,
+// common.AudioPipeline, runs one per track when common.OpusOptions has
+// AdaptiveBitrate set.
+//
+// For alerting, a WebhookNotifier set with SetEventRecorder POSTs high and
+// critical events to a webhook as JSON, rate limited and retried in the
+// background, while forwarding every event to the recorder it wraps.
 //
 // # Error Handling
 //
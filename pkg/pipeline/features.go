@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// featureEnvPrefix marks environment variables that toggle experimental features
+const featureEnvPrefix = "PIPELINE_FEATURE_"
+
+// Features holds experimental feature toggles. Every flag defaults to off
+// and is enabled with PIPELINE_FEATURE_<NAME>=true.
+type Features struct {
+	Passthrough bool `json:"passthrough"` // Have FFmpeg emit Opus directly instead of PCM
+}
+
+// loadFeatures reads feature flags from KEY=VALUE environment entries.
+// Unknown flags are ignored and unparsable values leave the flag off.
+func loadFeatures(environ []string) Features {
+	var features Features
+	for _, entry := range environ {
+		key, value, found := strings.Cut(entry, "=")
+		if !found || !strings.HasPrefix(key, featureEnvPrefix) {
+			continue
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToLower(strings.TrimPrefix(key, featureEnvPrefix)) {
+		case "passthrough":
+			features.Passthrough = enabled
+		}
+	}
+	return features
+}
+
+// Active returns the names of the enabled flags in sorted order
+func (f Features) Active() []string {
+	active := make([]string, 0, 1)
+	if f.Passthrough {
+		active = append(active, "passthrough")
+	}
+	sort.Strings(active)
+	return active
+}
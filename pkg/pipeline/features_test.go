@@ -0,0 +1,33 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFeatures(t *testing.T) {
+	features := loadFeatures([]string{
+		"PIPELINE_FEATURE_PASSTHROUGH=1",
+		"PIPELINE_FEATURE_TELEPORT=true",
+		"PIPELINE_LOG_LEVEL=debug",
+	})
+
+	assert.True(t, features.Passthrough)
+	assert.Equal(t, []string{"passthrough"}, features.Active())
+
+	features = loadFeatures([]string{"PIPELINE_FEATURE_PASSTHROUGH=maybe"})
+	assert.False(t, features.Passthrough, "unparsable values stay off")
+
+	assert.Equal(t, Features{}, loadFeatures(nil), "unset flags default off")
+}
+
+func TestLoadFromEnvironment_Features(t *testing.T) {
+	t.Setenv("PIPELINE_FEATURE_PASSTHROUGH", "true")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+
+	assert.True(t, config.Features.Passthrough)
+	assert.Contains(t, config.String(), `"passthrough": true`)
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+)
+
+// DefaultFFmpegBinary is the binary name looked up on PATH when no explicit
+// FFmpeg.BinaryPath is configured
+const DefaultFFmpegBinary = "ffmpeg"
+
+// ErrFFmpegNotFound is returned when the ffmpeg binary cannot be executed
+var ErrFFmpegNotFound = errors.New("ffmpeg not installed or not in PATH")
+
+// CheckFFmpegBinary reports whether path resolves to an executable,
+// returning an error wrapping ErrFFmpegNotFound when it does not
+func CheckFFmpegBinary(path string) error {
+	if _, err := exec.LookPath(path); err != nil {
+		return fmt.Errorf("%w (looked for %q): %w", ErrFFmpegNotFound, path, err)
+	}
+	return nil
+}
+
+// IsFFmpegMissing reports whether err comes from trying to run an ffmpeg
+// binary that isn't there: a failed PATH lookup, or a configured path that
+// doesn't exist
+func IsFFmpegMissing(err error) bool {
+	if errors.Is(err, ErrFFmpegNotFound) || errors.Is(err, exec.ErrNotFound) {
+		return true
+	}
+
+	// exec reports a missing absolute path as a fork/exec PathError
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr) && pathErr.Op == "fork/exec" && errors.Is(pathErr.Err, fs.ErrNotExist)
+}
+
+// FFmpegErrorClassifier classifies a missing ffmpeg binary as a critical
+// system error that no retry will fix; everything else is unknown and
+// medium severity
+type FFmpegErrorClassifier struct{}
+
+// Classify implements ErrorClassifier. Missing-binary errors are rewrapped
+// with ErrFFmpegNotFound so the message says what to fix.
+func (c FFmpegErrorClassifier) Classify(err error) *PipelineError {
+	if IsFFmpegMissing(err) && !errors.Is(err, ErrFFmpegNotFound) {
+		err = fmt.Errorf("%w: %w", ErrFFmpegNotFound, err)
+	}
+	return NewPipelineError(err, c.GetCategory(err), c.GetSeverity(err))
+}
+
+// IsRetryable implements ErrorClassifier
+func (c FFmpegErrorClassifier) IsRetryable(err error) bool {
+	return !IsFFmpegMissing(err)
+}
+
+// GetSeverity implements ErrorClassifier
+func (c FFmpegErrorClassifier) GetSeverity(err error) ErrorSeverity {
+	if IsFFmpegMissing(err) {
+		return SeverityCritical
+	}
+	return SeverityMedium
+}
+
+// GetCategory implements ErrorClassifier
+func (c FFmpegErrorClassifier) GetCategory(err error) ErrorCategory {
+	if IsFFmpegMissing(err) {
+		return CategorySystem
+	}
+	return CategoryUnknown
+}
@@ -0,0 +1,55 @@
+package pipeline
+
+import (
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFFmpegErrorClassifier_MissingBinary(t *testing.T) {
+	missing := map[string]string{
+		"not on PATH":  "hktm-ffmpeg-does-not-exist",
+		"missing file": filepath.Join(t.TempDir(), "ffmpeg"),
+	}
+
+	for name, path := range missing {
+		t.Run(name, func(t *testing.T) {
+			startErr := exec.Command(path, "-version").Start()
+			require.Error(t, startErr)
+
+			classified := FFmpegErrorClassifier{}.Classify(startErr)
+			assert.Equal(t, CategorySystem, classified.Category)
+			assert.Equal(t, SeverityCritical, classified.Severity)
+			assert.False(t, classified.Retryable)
+			assert.ErrorIs(t, classified, ErrFFmpegNotFound)
+			assert.Contains(t, classified.Error(), "ffmpeg not installed or not in PATH")
+		})
+	}
+}
+
+func TestFFmpegErrorClassifier_OtherErrors(t *testing.T) {
+	classified := FFmpegErrorClassifier{}.Classify(errors.New("exit status 1"))
+	assert.Equal(t, CategoryUnknown, classified.Category)
+	assert.Equal(t, SeverityMedium, classified.Severity)
+	assert.True(t, classified.Retryable)
+	assert.NotErrorIs(t, classified, ErrFFmpegNotFound)
+}
+
+func TestNewAudioPipelineManager_MissingFFmpegPath(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.FFmpeg.BinaryPath = filepath.Join(t.TempDir(), "ffmpeg")
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.Error(t, err)
+	assert.Nil(t, manager)
+	assert.ErrorIs(t, err, ErrFFmpegNotFound)
+
+	var pipelineErr *PipelineError
+	require.ErrorAs(t, err, &pipelineErr)
+	assert.Equal(t, CategorySystem, pipelineErr.Category)
+	assert.Equal(t, SeverityCritical, pipelineErr.Severity)
+}
@@ -0,0 +1,27 @@
+package pipeline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// ConfigHashTag is the metric tag carrying the config fingerprint
+const ConfigHashTag = "config_hash"
+
+// fingerprintLength is the number of hex characters kept from the hash
+const fingerprintLength = 12
+
+// Fingerprint returns a short, stable hash of the configuration so metrics
+// can be segmented by config version. It is computed over the redacted
+// config, so secrets neither leak into it nor change it.
+func (c PipelineConfig) Fingerprint() string {
+	// encoding/json sorts map keys, which keeps the encoding stable
+	data, err := json.Marshal(pipelineConfigJSON(c.Redacted()))
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:fingerprintLength]
+}
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineConfigFingerprint(t *testing.T) {
+	base := DefaultPipelineConfig()
+	hash := base.Fingerprint()
+	assert.Len(t, hash, fingerprintLength)
+
+	// Stable for an identical config
+	assert.Equal(t, hash, DefaultPipelineConfig().Fingerprint())
+	assert.Equal(t, hash, base.Fingerprint())
+
+	// Changes when a relevant field changes
+	changed := DefaultPipelineConfig()
+	changed.Opus.Bitrate = 96000
+	assert.NotEqual(t, hash, changed.Fingerprint())
+
+	// Secrets are redacted before hashing, so rotating one keeps the hash
+	withToken := DefaultPipelineConfig()
+	withToken.FFmpeg.Args = append(withToken.FFmpeg.Args, "-headers", "token-one")
+	rotated := DefaultPipelineConfig()
+	rotated.FFmpeg.Args = append(rotated.FFmpeg.Args, "-headers", "token-two")
+	assert.Equal(t, withToken.Fingerprint(), rotated.Fingerprint())
+}
+
+func TestManager_TagsMetricsWithConfigHash(t *testing.T) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	require.NoError(t, err)
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+	require.NoError(t, manager.Stop())
+
+	hash := manager.ConfigHash()
+	assert.Equal(t, DefaultPipelineConfig().Fingerprint(), hash)
+
+	spy.mu.Lock()
+	require.NotEmpty(t, spy.calls)
+	for _, call := range spy.calls {
+		assert.Equal(t, hash, call.tags[ConfigHashTag], "metric %s", call.name)
+	}
+	spy.mu.Unlock()
+
+	// Reloading re-tags subsequent metrics
+	config := DefaultPipelineConfig()
+	config.Opus.Bitrate = 96000
+	_, err = manager.ReloadConfig(config)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, manager.ConfigHash())
+
+	spy.mu.Lock()
+	last := spy.calls[len(spy.calls)-1]
+	spy.mu.Unlock()
+	assert.Equal(t, "pipeline.config_reloads", last.name)
+	assert.Equal(t, manager.ConfigHash(), last.tags[ConfigHashTag])
+}
@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrHostNotAllowed is wrapped by errors for sources on a disallowed host
+var ErrHostNotAllowed = errors.New("host not allowed")
+
+// HostPolicy decides which hosts may be streamed from. Patterns are exact
+// hostnames or wildcards like "*.mycdn.com", which match any subdomain but
+// not the bare domain. The blocklist wins over the allowlist, and an empty
+// allowlist allows every host that isn't blocked.
+type HostPolicy struct {
+	allow []string
+	block []string
+}
+
+// NewHostPolicy creates a policy from allow and block patterns
+func NewHostPolicy(allow, block []string) *HostPolicy {
+	return &HostPolicy{
+		allow: normalizeHostPatterns(allow),
+		block: normalizeHostPatterns(block),
+	}
+}
+
+// normalizeHostPatterns lowercases patterns and drops empty entries
+func normalizeHostPatterns(patterns []string) []string {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	return normalized
+}
+
+// validateHostPattern reports whether a pattern is an exact host or a
+// leading "*." wildcard
+func validateHostPattern(pattern string) error {
+	rest := strings.TrimPrefix(pattern, "*.")
+	if rest == "" || strings.Contains(rest, "*") || strings.ContainsAny(rest, "/:") {
+		return fmt.Errorf("invalid host pattern %q", pattern)
+	}
+	return nil
+}
+
+// matchHost reports whether host matches the pattern
+func matchHost(pattern, host string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// Allowed reports whether streaming from host is permitted
+func (p *HostPolicy) Allowed(host string) bool {
+	if p == nil {
+		return true
+	}
+
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range p.block {
+		if matchHost(pattern, host) {
+			return false
+		}
+	}
+
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.allow {
+		if matchHost(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckURL returns a stream-category error if the URL's host is not allowed.
+// URLs without a scheme, such as "www.youtube.com/watch?v=...", are accepted.
+func (p *HostPolicy) CheckURL(rawURL string) error {
+	if p == nil || (len(p.allow) == 0 && len(p.block) == 0) {
+		return nil
+	}
+
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return p.reject("", fmt.Errorf("%w: cannot determine host", ErrHostNotAllowed))
+	}
+
+	host := u.Hostname()
+	if !p.Allowed(host) {
+		return p.reject(host, fmt.Errorf("%w: %s", ErrHostNotAllowed, host))
+	}
+	return nil
+}
+
+// reject wraps err as a non-retryable stream error
+func (p *HostPolicy) reject(host string, err error) *PipelineError {
+	pipelineErr := NewPipelineError(err, CategoryStream, SeverityMedium)
+	pipelineErr.Retryable = false
+	pipelineErr.Context["host"] = host
+	return pipelineErr
+}
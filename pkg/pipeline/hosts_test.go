@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPolicy_DefaultAllowsAll(t *testing.T) {
+	policy := NewHostPolicy(nil, nil)
+	assert.NoError(t, policy.CheckURL("https://www.youtube.com/watch?v=abc"))
+	assert.NoError(t, policy.CheckURL("https://anything.example.org/a.mp3"))
+
+	var nilPolicy *HostPolicy
+	assert.NoError(t, nilPolicy.CheckURL("https://anything.example.org/a.mp3"))
+}
+
+func TestHostPolicy_Allowlist(t *testing.T) {
+	policy := NewHostPolicy([]string{"www.youtube.com", "youtu.be"}, nil)
+
+	assert.NoError(t, policy.CheckURL("https://www.youtube.com/watch?v=abc"))
+	assert.NoError(t, policy.CheckURL("youtu.be/abc"))
+	assert.NoError(t, policy.CheckURL("https://WWW.YouTube.com:443/watch?v=abc"))
+
+	err := policy.CheckURL("https://evil.example.com/stream")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrHostNotAllowed))
+
+	var pipelineErr *PipelineError
+	require.True(t, errors.As(err, &pipelineErr))
+	assert.Equal(t, CategoryStream, pipelineErr.Category)
+	assert.False(t, pipelineErr.Retryable)
+	assert.Equal(t, "evil.example.com", pipelineErr.Context["host"])
+}
+
+func TestHostPolicy_Blocklist(t *testing.T) {
+	policy := NewHostPolicy(nil, []string{"bad.example.com"})
+
+	assert.Error(t, policy.CheckURL("https://bad.example.com/a.mp3"))
+	assert.NoError(t, policy.CheckURL("https://good.example.com/a.mp3"))
+
+	// Block wins over allow
+	policy = NewHostPolicy([]string{"*.example.com"}, []string{"bad.example.com"})
+	assert.Error(t, policy.CheckURL("https://bad.example.com/a.mp3"))
+	assert.NoError(t, policy.CheckURL("https://good.example.com/a.mp3"))
+}
+
+func TestHostPolicy_Wildcards(t *testing.T) {
+	policy := NewHostPolicy([]string{"*.mycdn.com"}, nil)
+
+	assert.True(t, policy.Allowed("a.mycdn.com"))
+	assert.True(t, policy.Allowed("deep.edge.mycdn.com"))
+	assert.False(t, policy.Allowed("mycdn.com"), "wildcards only match subdomains")
+	assert.False(t, policy.Allowed("notmycdn.com"))
+	assert.False(t, policy.Allowed("mycdn.com.evil.net"))
+}
+
+func TestPipelineConfig_ValidateHostPatterns(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.StreamAcquisition.AllowedHosts = []string{"*.mycdn.com", "youtube.com"}
+	assert.NoError(t, config.Validate())
+
+	config.StreamAcquisition.BlockedHosts = []string{"cdn.*.com"}
+	assert.Error(t, config.Validate())
+}
@@ -46,6 +46,21 @@ type MetricsCollector interface {
 	RecordTiming(name string, duration time.Duration, tags map[string]string)
 }
 
+// MetricRecorder is the sink the pipeline emits metrics through. Implementations
+// can forward to a database, Prometheus, stdout, or discard everything.
+type MetricRecorder interface {
+	Counter(name string, value int64, tags map[string]string)
+	Gauge(name string, value float64, tags map[string]string)
+	Histogram(name string, value float64, tags map[string]string)
+	Timing(name string, duration time.Duration, tags map[string]string)
+}
+
+// EventRecorder is the sink for discrete pipeline events such as config
+// reloads. Severity is one of low, medium, high, or critical.
+type EventRecorder interface {
+	RecordEvent(eventType, severity string, data map[string]interface{})
+}
+
 // PipelineManager defines the interface for the main pipeline manager
 type PipelineManager interface {
 	Start(ctx context.Context, streamURL string) error
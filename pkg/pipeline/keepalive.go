@@ -0,0 +1,78 @@
+package pipeline
+
+import "time"
+
+// DefaultKeepAliveInterval is how often a silence frame is sent while paused.
+// It only needs to be well inside the window after which Discord treats an
+// idle voice connection as gone.
+const DefaultKeepAliveInterval = 5 * time.Second
+
+// OpusSilenceFrame is the three byte Opus packet Discord recommends for silence
+var OpusSilenceFrame = []byte{0xF8, 0xFF, 0xFE}
+
+// pauseKeepAlive sends silence frames on a fixed interval until stopped
+type pauseKeepAlive struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// SetDiscordStreamer sets where encoded frames, including pause keep-alive
+// silence frames, are sent
+func (apm *AudioPipelineManager) SetDiscordStreamer(streamer DiscordStreamer) {
+	apm.stateMutex.Lock()
+	defer apm.stateMutex.Unlock()
+	apm.discordStreamer = streamer
+}
+
+// startKeepAliveLocked starts the silence heartbeat if it is enabled and a
+// streamer is set. Callers hold stateMutex.
+func (apm *AudioPipelineManager) startKeepAliveLocked() {
+	if !apm.config.Discord.KeepAliveOnPause || apm.discordStreamer == nil || apm.keepAlive != nil {
+		return
+	}
+
+	keepAlive := &pauseKeepAlive{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	apm.keepAlive = keepAlive
+
+	go apm.runKeepAlive(keepAlive, apm.discordStreamer, apm.config.Discord.KeepAliveInterval)
+}
+
+// stopKeepAliveLocked stops the silence heartbeat and waits for its last
+// send to finish. Callers hold stateMutex.
+func (apm *AudioPipelineManager) stopKeepAliveLocked() {
+	if apm.keepAlive == nil {
+		return
+	}
+
+	close(apm.keepAlive.stop)
+	<-apm.keepAlive.done
+	apm.keepAlive = nil
+}
+
+// runKeepAlive sends a silence frame every interval. Failed sends are logged
+// and counted; the next tick tries again.
+func (apm *AudioPipelineManager) runKeepAlive(keepAlive *pauseKeepAlive, streamer DiscordStreamer, interval time.Duration) {
+	defer close(keepAlive.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := streamer.SendOpusFrame(OpusSilenceFrame); err != nil {
+				apm.logger.Debug("Failed to send keep-alive silence frame", Error(err))
+				apm.metrics.RecordPipelineCounter("pipeline.keepalive_failures", 1, nil)
+				continue
+			}
+			apm.metrics.RecordPipelineCounter("pipeline.keepalive_frames", 1, nil)
+		case <-keepAlive.stop:
+			return
+		case <-apm.ctx.Done():
+			return
+		}
+	}
+}
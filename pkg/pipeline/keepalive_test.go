@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink is a DiscordStreamer that records the frames it is sent
+type recordingSink struct {
+	mu     sync.Mutex
+	frames [][]byte
+	sentAt []time.Time
+}
+
+func (s *recordingSink) Start(ctx context.Context) error          { return nil }
+func (s *recordingSink) Stop() error                              { return nil }
+func (s *recordingSink) IsConnected() bool                        { return true }
+func (s *recordingSink) GetConnectionMetrics() *ConnectionMetrics { return nil }
+
+func (s *recordingSink) SendOpusFrame(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frames = append(s.frames, data)
+	s.sentAt = append(s.sentAt, time.Now())
+	return nil
+}
+
+func (s *recordingSink) Frames() ([][]byte, []time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([][]byte(nil), s.frames...), append([]time.Time(nil), s.sentAt...)
+}
+
+func newKeepAliveManager(t *testing.T, enabled bool, interval time.Duration) (*AudioPipelineManager, *recordingSink) {
+	t.Helper()
+	config := DefaultPipelineConfig()
+	config.Discord.KeepAliveOnPause = enabled
+	config.Discord.KeepAliveInterval = interval
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	sink := &recordingSink{}
+	manager.SetDiscordStreamer(sink)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+	return manager, sink
+}
+
+func TestKeepAliveOnPause_SendsSilenceAtInterval(t *testing.T) {
+	interval := 20 * time.Millisecond
+	manager, sink := newKeepAliveManager(t, true, interval)
+
+	require.NoError(t, manager.Pause())
+	time.Sleep(10*interval + interval/2)
+	require.NoError(t, manager.Resume())
+
+	frames, sentAt := sink.Frames()
+	// Ten intervals elapsed; allow for scheduler jitter on slow machines
+	assert.GreaterOrEqual(t, len(frames), 6)
+	assert.LessOrEqual(t, len(frames), 11)
+	for _, frame := range frames {
+		assert.Equal(t, OpusSilenceFrame, frame)
+	}
+	for i := 1; i < len(sentAt); i++ {
+		assert.GreaterOrEqual(t, sentAt[i].Sub(sentAt[i-1]), interval/2, "frames %d and %d", i-1, i)
+	}
+
+	// Resume stops the heartbeat
+	time.Sleep(5 * interval)
+	after, _ := sink.Frames()
+	assert.Len(t, after, len(frames))
+}
+
+func TestKeepAliveOnPause_StopEndsHeartbeat(t *testing.T) {
+	interval := 10 * time.Millisecond
+	manager, sink := newKeepAliveManager(t, true, interval)
+
+	require.NoError(t, manager.Pause())
+	time.Sleep(3 * interval)
+	require.NoError(t, manager.Stop())
+
+	frames, _ := sink.Frames()
+	time.Sleep(5 * interval)
+	after, _ := sink.Frames()
+	assert.Len(t, after, len(frames))
+}
+
+func TestKeepAliveOnPause_Disabled(t *testing.T) {
+	interval := 10 * time.Millisecond
+	manager, sink := newKeepAliveManager(t, false, interval)
+
+	require.NoError(t, manager.Pause())
+	time.Sleep(5 * interval)
+	require.NoError(t, manager.Resume())
+
+	frames, _ := sink.Frames()
+	assert.Empty(t, frames)
+}
+
+func TestKeepAliveInterval_Validation(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Discord.KeepAliveOnPause = true
+	config.Discord.KeepAliveInterval = 0
+	assert.Error(t, config.Validate())
+
+	config.Discord.KeepAliveOnPause = false
+	assert.NoError(t, config.Validate())
+}
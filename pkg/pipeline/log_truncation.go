@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxLogRecordSize is the largest serialized log record, in
+	// bytes, written before field values are truncated
+	DefaultMaxLogRecordSize = 64 * 1024
+
+	// TruncatedMarker ends a field value cut short to fit a record in the
+	// maximum size
+	TruncatedMarker = "…(truncated)"
+
+	// TruncatedField is set to true on records with truncated field values
+	TruncatedField = "log_truncated"
+
+	// maxTruncationPasses bounds the shrinking of a record, which can take
+	// more than one pass when escaping grows a value's serialized form
+	maxTruncationPasses = 32
+)
+
+// render serializes an entry in the logger's format
+func (l *StructuredLogger) render(entry LogEntry) string {
+	if l.format != "json" {
+		return l.formatText(entry)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Sprintf("ERROR: Failed to marshal log entry: %v\n", err)
+	}
+	return string(data) + "\n"
+}
+
+// fitRecord truncates the largest field values of an entry, one at a time,
+// until it serializes within maxSize, and flags it with TruncatedField. It
+// returns the serialized entry, which can still be over the limit when the
+// message or field names alone exceed it.
+func (l *StructuredLogger) fitRecord(entry LogEntry, maxSize int) string {
+	output := l.render(entry)
+	if maxSize <= 0 || len(output) <= maxSize {
+		return output
+	}
+
+	// Work on a copy; the fields map may be shared with the caller's values
+	fields := make(map[string]interface{}, len(entry.Fields)+1)
+	for k, v := range entry.Fields {
+		fields[k] = v
+	}
+	entry.Fields = fields
+	entry.Fields[TruncatedField] = true
+
+	for pass := 0; ; pass++ {
+		output = l.render(entry)
+		excess := len(output) - maxSize
+		if excess <= 0 || pass == maxTruncationPasses {
+			return output
+		}
+
+		key, text := l.largestField(entry.Fields)
+		if key == "" {
+			return output
+		}
+
+		// A value truncated on an earlier pass keeps a single marker
+		if strings.HasSuffix(text, TruncatedMarker) {
+			text = strings.TrimSuffix(text, TruncatedMarker)
+		} else {
+			excess += len(TruncatedMarker)
+		}
+		keep := len(text) - excess
+		if keep < 0 {
+			keep = 0
+		}
+		entry.Fields[key] = truncateUTF8(text, keep) + TruncatedMarker
+	}
+}
+
+// largestField returns the key and text of the field value taking up the
+// most room, skipping values already cut down to the marker
+func (l *StructuredLogger) largestField(fields map[string]interface{}) (string, string) {
+	var largestKey, largestText string
+	for key, value := range fields {
+		if key == TruncatedField {
+			continue
+		}
+		text := l.fieldText(value)
+		if len(text) > len(largestText) && text != TruncatedMarker {
+			largestKey, largestText = key, text
+		}
+	}
+	return largestKey, largestText
+}
+
+// fieldText returns a field value as the text it is written as
+func (l *StructuredLogger) fieldText(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	if l.format == "json" {
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// truncateUTF8 cuts text to at most n bytes without splitting a character
+func truncateUTF8(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
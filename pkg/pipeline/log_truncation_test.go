@@ -0,0 +1,97 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type oversizedPayload struct {
+	Samples []int  `json:"samples"`
+	Note    string `json:"note"`
+}
+
+func newTruncatingLogger(format string, maxRecordSize int) (*StructuredLogger, *bytes.Buffer) {
+	logger := NewStructuredLogger(LoggingConfig{
+		Level:         "info",
+		Format:        format,
+		MaxRecordSize: maxRecordSize,
+	})
+	var buf bytes.Buffer
+	logger.output = &buf
+	return logger, &buf
+}
+
+func TestStructuredLogger_TruncatesOversizedJSONRecord(t *testing.T) {
+	logger, buf := newTruncatingLogger("json", 1024)
+
+	payload := oversizedPayload{Samples: make([]int, 5000), Note: strings.Repeat("é", 100)}
+	logger.Info("dumping state", Any("data", payload), String("track", "one"))
+
+	assert.LessOrEqual(t, buf.Len(), 1024)
+
+	var entry LogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "dumping state", entry.Message)
+	assert.Equal(t, true, entry.Fields[TruncatedField])
+	assert.Equal(t, "one", entry.Fields["track"])
+
+	data, ok := entry.Fields["data"].(string)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(data, TruncatedMarker))
+	assert.Equal(t, 1, strings.Count(data, TruncatedMarker))
+	assert.True(t, strings.HasPrefix(data, `{"samples":[0,0,0`))
+}
+
+func TestStructuredLogger_TruncatesOversizedTextRecord(t *testing.T) {
+	logger, buf := newTruncatingLogger("text", 512)
+
+	logger.Info("dumping state", String("blob", strings.Repeat("x", 10000)), Int("count", 3))
+
+	output := buf.String()
+	assert.LessOrEqual(t, len(output), 512)
+	assert.Contains(t, output, "dumping state")
+	assert.Contains(t, output, TruncatedMarker)
+	assert.Contains(t, output, TruncatedField+"=true")
+	assert.Contains(t, output, "count=3")
+}
+
+func TestStructuredLogger_LeavesRecordsWithinLimit(t *testing.T) {
+	logger, buf := newTruncatingLogger("json", 1024)
+
+	logger.Info("small", String("track", "one"))
+
+	var entry LogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.NotContains(t, entry.Fields, TruncatedField)
+	assert.Equal(t, "one", entry.Fields["track"])
+}
+
+func TestStructuredLogger_MaxRecordSizeDisabled(t *testing.T) {
+	logger, buf := newTruncatingLogger("json", 0)
+
+	blob := strings.Repeat("x", 100000)
+	logger.Info("unbounded", String("blob", blob))
+
+	var entry LogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, blob, entry.Fields["blob"])
+	assert.NotContains(t, entry.Fields, TruncatedField)
+}
+
+func TestMaxRecordSizeFromEnvironment(t *testing.T) {
+	config := DefaultPipelineConfig()
+	assert.Equal(t, DefaultMaxLogRecordSize, config.Logging.MaxRecordSize)
+
+	t.Setenv("PIPELINE_LOG_MAX_RECORD_SIZE", "2048")
+	config.LoadFromEnvironment()
+	assert.Equal(t, 2048, config.Logging.MaxRecordSize)
+	require.NoError(t, config.Validate())
+
+	config.Logging.MaxRecordSize = -1
+	assert.Error(t, config.Validate())
+}
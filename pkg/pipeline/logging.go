@@ -1,7 +1,6 @@
 package pipeline
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -103,9 +102,11 @@ type StructuredLogger struct {
 	fields     map[string]interface{}
 	mu         sync.RWMutex
 	enableCaller bool
+	maxRecordSize int // serialized bytes before field values are truncated; 0 is unlimited
 }
 
-// NewStructuredLogger creates a new structured logger
+// NewStructuredLogger creates a new structured logger. The config's static
+// fields are attached to every record it and its With loggers write.
 func NewStructuredLogger(config LoggingConfig) *StructuredLogger {
 	level := parseLogLevel(config.Level)
 	
@@ -120,12 +121,18 @@ func NewStructuredLogger(config LoggingConfig) *StructuredLogger {
 		}
 	}
 	
+	fields := make(map[string]interface{}, len(config.StaticFields))
+	for k, v := range config.StaticFields {
+		fields[k] = v
+	}
+	
 	return &StructuredLogger{
 		level:        level,
 		format:       config.Format,
 		output:       output,
-		fields:       make(map[string]interface{}),
+		fields:       fields,
 		enableCaller: true,
+		maxRecordSize: config.MaxRecordSize,
 	}
 }
 
@@ -200,6 +207,7 @@ func (l *StructuredLogger) With(fields ...Field) Logger {
 		output:       l.output,
 		fields:       newFields,
 		enableCaller: l.enableCaller,
+		maxRecordSize: l.maxRecordSize,
 	}
 }
 
@@ -233,20 +241,8 @@ func (l *StructuredLogger) log(level LogLevel, msg string, fields ...Field) {
 		}
 	}
 	
-	// Format and write the log entry
-	var output string
-	switch l.format {
-	case "json":
-		if data, err := json.Marshal(entry); err == nil {
-			output = string(data) + "\n"
-		} else {
-			output = fmt.Sprintf("ERROR: Failed to marshal log entry: %v\n", err)
-		}
-	case "text", "console":
-		output = l.formatText(entry)
-	default:
-		output = l.formatText(entry)
-	}
+	// Format the log entry, truncating oversized field values, and write it
+	output := l.fitRecord(entry, l.maxRecordSize)
 	
 	l.output.Write([]byte(output))
 }
@@ -315,9 +311,10 @@ func (l *StructuredLogger) EnableCaller(enable bool) {
 // DefaultLogger creates a default logger for the pipeline
 func DefaultLogger() Logger {
 	config := LoggingConfig{
-		Level:  "info",
-		Format: "text",
-		Output: "stdout",
+		Level:         "info",
+		Format:        "text",
+		Output:        "stdout",
+		MaxRecordSize: DefaultMaxLogRecordSize,
 	}
 	return NewStructuredLogger(config)
 }
@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+)
+
+// Integrated loudness targets for normalization, in LUFS. -16 suits music
+// streamed to headphones and speakers alike; loudnorm accepts -70 to -5.
+const (
+	DefaultLoudnessTargetLUFS = -16.0
+	MinLoudnessTargetLUFS     = -70.0
+	MaxLoudnessTargetLUFS     = -5.0
+)
+
+// MetricEncodeLatency is a histogram of how long each frame took to encode,
+// in milliseconds
+const MetricEncodeLatency = "pipeline.encode.latency_ms"
+
+// LoudnormFilter returns the FFmpeg filter that normalizes audio to
+// targetLUFS integrated loudness, with a -1.5 dBTP true peak ceiling and
+// loudnorm's default loudness range
+func LoudnormFilter(targetLUFS float64) string {
+	return fmt.Sprintf("loudnorm=I=%.1f:TP=-1.5:LRA=11", targetLUFS)
+}
+
+// Filters returns the FFmpeg audio filters the config asks for, in the order
+// they run, or nil when it needs none
+func (c AudioConfig) Filters() []string {
+	var filters []string
+	if c.NormalizeLoudness {
+		filters = append(filters, LoudnormFilter(c.TargetLUFS))
+	}
+	return filters
+}
+
+// EncodeFrame encodes a frame of PCM with the pipeline's encoder and records
+// how long it took under MetricEncodeLatency
+func (apm *AudioPipelineManager) EncodeFrame(pcm []int16, frameSize int) ([]byte, error) {
+	apm.stateMutex.RLock()
+	encoder := apm.audioEncoder
+	apm.stateMutex.RUnlock()
+
+	if encoder == nil {
+		return nil, fmt.Errorf("no audio encoder set")
+	}
+
+	start := time.Now()
+	frame, err := encoder.Encode(pcm, frameSize)
+	if err != nil {
+		return nil, err
+	}
+
+	latency := float64(time.Since(start)) / float64(time.Millisecond)
+	apm.metrics.RecordPipelineHistogram(MetricEncodeLatency, latency, nil)
+	return frame, nil
+}
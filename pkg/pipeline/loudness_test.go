@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioConfig_LoudnessValidation(t *testing.T) {
+	config := DefaultPipelineConfig()
+	assert.False(t, config.Audio.NormalizeLoudness)
+	assert.Equal(t, DefaultLoudnessTargetLUFS, config.Audio.TargetLUFS)
+
+	config.Audio.NormalizeLoudness = true
+	for _, lufs := range []float64{-70, -23, -16, -5} {
+		config.Audio.TargetLUFS = lufs
+		assert.NoError(t, config.Validate(), "%v LUFS", lufs)
+	}
+	for _, lufs := range []float64{-71, -4, 0} {
+		config.Audio.TargetLUFS = lufs
+		assert.Error(t, config.Validate(), "%v LUFS", lufs)
+	}
+
+	config.Audio.NormalizeLoudness = false
+	assert.NoError(t, config.Validate(), "the target is ignored while normalization is off")
+}
+
+func TestAudioConfig_LoadNormalizeLUFS(t *testing.T) {
+	t.Setenv("PIPELINE_AUDIO_NORMALIZE_LUFS", "-23")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.True(t, config.Audio.NormalizeLoudness)
+	assert.Equal(t, -23.0, config.Audio.TargetLUFS)
+}
+
+func TestAudioConfig_InvalidLUFSFailsManager(t *testing.T) {
+	t.Setenv("PIPELINE_AUDIO_NORMALIZE_LUFS", "3")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	_, err := NewAudioPipelineManager(config, NullLogger())
+	assert.Error(t, err)
+}
+
+func TestAudioConfig_Filters(t *testing.T) {
+	assert.Nil(t, AudioConfig{TargetLUFS: -16}.Filters())
+	assert.Equal(t, []string{"loudnorm=I=-16.0:TP=-1.5:LRA=11"},
+		AudioConfig{NormalizeLoudness: true, TargetLUFS: -16}.Filters())
+}
+
+func TestSeek_KeepsLoudnessFilter(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Audio.NormalizeLoudness = true
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	processor := &fakeProcessor{}
+	manager.SetStreamProcessor(processor)
+	require.NoError(t, manager.Start(context.Background(), "/music/track.flac"))
+	require.NoError(t, manager.Seek(context.Background(), time.Minute))
+
+	assert.Equal(t, []string{LoudnormFilter(DefaultLoudnessTargetLUFS)}, processor.filters)
+}
+
+func TestEncodeFrame_RecordsLatency(t *testing.T) {
+	manager, err := NewAudioPipelineManager(DefaultPipelineConfig(), NullLogger())
+	require.NoError(t, err)
+
+	_, err = manager.EncodeFrame(make([]int16, 1920), 960)
+	assert.Error(t, err, "no encoder set yet")
+
+	require.NoError(t, manager.SetAudioEncoder(&spyEncoder{}))
+	_, err = manager.EncodeFrame(make([]int16, 1920), 960)
+	require.NoError(t, err)
+
+	assert.Len(t, manager.metrics.GetMetricsByName(MetricEncodeLatency), 1)
+}
@@ -12,6 +12,10 @@ type AudioPipelineManager struct {
 	// Configuration
 	config *PipelineConfig
 	
+	// Config for the current stream: config with the source's profile merged
+	// over it, nil until started
+	streamConfig *PipelineConfig
+	
 	// Core components (interfaces to be implemented in later tasks)
 	streamAcquisition StreamAcquisition
 	streamProcessor   StreamProcessor
@@ -21,6 +25,10 @@ type AudioPipelineManager struct {
 	// Management components (interfaces to be implemented in later tasks)
 	healthChecker   []HealthCheck
 	recoveryManager RecoveryStrategy
+	recoveryStrategies []RecoveryStrategy
+	recoveryMutex      sync.RWMutex
+	recoveryBudget     *RecoveryBudget
+	recovering         bool // whether runRecovery is running; guarded by recoveryMutex
 	errorClassifier ErrorClassifier
 	userNotifier    UserNotifier
 	resourceManager ResourceManager
@@ -29,8 +37,17 @@ type AudioPipelineManager struct {
 	state      PipelineState
 	stateMutex sync.RWMutex
 	
+	// The stream being played and how far into it playback is
+	streamURL      string
+	position       playbackPosition
+	positionTicker *positionTicker
+	
+	// Silence heartbeat while paused, nil when not running
+	keepAlive *pauseKeepAlive
+	
 	// Monitoring and logging
 	metrics *PipelineMetricsCollector
+	events  EventRecorder
 	logger  Logger
 	
 	// Control channels
@@ -57,6 +74,14 @@ func NewAudioPipelineManager(config *PipelineConfig, logger Logger) (*AudioPipel
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 	
+	// An explicitly configured binary must exist; the default is only
+	// looked up on PATH when a stream starts
+	if config.FFmpeg.BinaryPath != DefaultFFmpegBinary {
+		if err := CheckFFmpegBinary(config.FFmpeg.BinaryPath); err != nil {
+			return nil, NewPipelineError(err, CategorySystem, SeverityCritical)
+		}
+	}
+	
 	if logger == nil {
 		logger = DefaultLogger()
 	}
@@ -66,21 +91,27 @@ func NewAudioPipelineManager(config *PipelineConfig, logger Logger) (*AudioPipel
 	ctx, cancel := context.WithCancel(context.Background())
 	
 	manager := &AudioPipelineManager{
-		config:      config,
-		state:       StateIdle,
-		metrics:     NewPipelineMetricsCollector(pipelineID, logger),
-		logger:      logger.With(String("component", "pipeline_manager"), String("pipeline_id", pipelineID)),
-		controlChan: make(chan ControlMessage, 100),
-		errorChan:   make(chan *PipelineError, 100),
-		stateChan:   make(chan StateChange, 100),
-		ctx:         ctx,
-		cancel:      cancel,
-		pipelineID:  pipelineID,
+		config:         config,
+		state:          StateIdle,
+		metrics:        NewPipelineMetricsCollector(pipelineID, logger),
+		logger:         logger.With(String("component", "pipeline_manager"), String("pipeline_id", pipelineID)),
+		controlChan:    make(chan ControlMessage, 100),
+		errorChan:      make(chan *PipelineError, 100),
+		stateChan:      make(chan StateChange, 100),
+		ctx:            ctx,
+		cancel:         cancel,
+		pipelineID:     pipelineID,
+		recoveryBudget: NewRecoveryBudget(time.Now),
 	}
 	
+	manager.metrics.SetConfigHash(config.Fingerprint())
+	manager.metrics.SetStaticTags(config.Logging.StaticFields)
+	
 	manager.logger.Info("Created new audio pipeline manager",
 		String("pipeline_id", pipelineID),
+		String("config_hash", config.Fingerprint()),
 		Any("config", config),
+		Any("features", config.Features.Active()),
 	)
 	
 	return manager, nil
@@ -95,7 +126,12 @@ func (apm *AudioPipelineManager) Start(ctx context.Context, streamURL string) er
 		return fmt.Errorf("pipeline is not in idle state, current state: %s", apm.state)
 	}
 	
-	apm.logger.Info("Starting audio pipeline", String("stream_url", streamURL))
+	// Tune the config for the kind of source being streamed
+	var kind SourceKind
+	apm.streamConfig, kind = apm.config.ForSource(streamURL)
+	apm.streamURL = streamURL
+	
+	apm.logger.Info("Starting audio pipeline", String("stream_url", streamURL), String("source_kind", string(kind)))
 	
 	// Record start time
 	apm.startTime = time.Now()
@@ -106,10 +142,14 @@ func (apm *AudioPipelineManager) Start(ctx context.Context, streamURL string) er
 	// Start control loop
 	go apm.controlLoop()
 	
+	apm.metrics.RecordPipelineCounter("pipeline.starts", 1, nil)
+	
 	// TODO: In later tasks, this will initialize and start all components
 	// For now, we just simulate the initialization
 	apm.logger.Info("Pipeline initialization complete")
 	apm.changeState(StateStreaming, "initialization complete")
+	apm.position.reset(true)
+	apm.startPositionTickerLocked()
 	
 	return nil
 }
@@ -127,11 +167,26 @@ func (apm *AudioPipelineManager) Stop() error {
 	
 	apm.changeState(StateStopping, "stop requested")
 	
+	apm.stopKeepAliveLocked()
+	apm.stopPositionTickerLocked()
+	apm.position.reset(false)
+	
 	// Cancel context to stop all operations
 	apm.cancel()
 	
+	if apm.streamProcessor != nil && apm.streamProcessor.IsRunning() {
+		if err := apm.streamProcessor.Stop(); err != nil {
+			apm.logger.Warn("Failed to stop stream processor", Error(err))
+		}
+	}
+	
 	// TODO: In later tasks, this will properly stop all components
 	
+	apm.metrics.RecordPipelineCounter("pipeline.stops", 1, nil)
+	if !apm.startTime.IsZero() {
+		apm.metrics.RecordPipelineTiming("pipeline.uptime", time.Since(apm.startTime), nil)
+	}
+	
 	apm.changeState(StateIdle, "pipeline stopped")
 	
 	return nil
@@ -148,6 +203,10 @@ func (apm *AudioPipelineManager) Pause() error {
 	
 	apm.logger.Info("Pausing audio pipeline")
 	apm.changeState(StatePaused, "pause requested")
+	apm.position.hold()
+	
+	// Keep the voice connection warm so Resume is instant
+	apm.startKeepAliveLocked()
 	
 	// TODO: Implement pause functionality in later tasks
 	
@@ -164,7 +223,9 @@ func (apm *AudioPipelineManager) Resume() error {
 	}
 	
 	apm.logger.Info("Resuming audio pipeline")
+	apm.stopKeepAliveLocked()
 	apm.changeState(StateStreaming, "resume requested")
+	apm.position.run()
 	
 	// TODO: Implement resume functionality in later tasks
 	
@@ -188,6 +249,11 @@ func (apm *AudioPipelineManager) GetMetrics() *PipelineMetrics {
 	// TODO: In later tasks, populate metrics from actual components
 	// For now, return basic metrics
 	metrics.LastUpdated = snapshot.Timestamp
+	metrics.Position = apm.Position()
+	metrics.FramesSent, metrics.BytesSent = apm.position.counts()
+	metrics.AudioQuality.Application = apm.config.Opus.Application
+	metrics.AudioQuality.FEC = apm.config.Opus.FEC
+	metrics.AudioQuality.DTX = apm.config.Opus.DTX
 	
 	return metrics
 }
@@ -200,6 +266,136 @@ func (apm *AudioPipelineManager) IsHealthy() bool {
 	return state == StateStreaming || state == StateIdle
 }
 
+// SetMetricRecorder sets the recorder that pipeline metrics are emitted through.
+// Passing nil restores the no-op recorder. Gauges with a configured smoothing
+// factor reach it both raw and smoothed.
+func (apm *AudioPipelineManager) SetMetricRecorder(recorder MetricRecorder) {
+	apm.stateMutex.RLock()
+	smoothing := apm.config.Metrics.GaugeSmoothing
+	apm.stateMutex.RUnlock()
+	
+	if recorder != nil && len(smoothing) > 0 {
+		recorder = NewSmoothingRecorder(smoothing, recorder)
+	}
+	apm.metrics.SetRecorder(recorder)
+}
+
+// EnableAdaptiveBitrate starts stepping the Opus bitrate with playback
+// health when the config's adaptive mode is on, calling apply with each new
+// bitrate. The controller wraps the current metric recorder, so set that
+// first. It returns nil when adaptive mode is off.
+func (apm *AudioPipelineManager) EnableAdaptiveBitrate(apply func(bitrate int)) *AdaptiveBitrateController {
+	apm.stateMutex.RLock()
+	opus := apm.config.EncoderConfig()
+	events := apm.events
+	apm.stateMutex.RUnlock()
+	
+	if !opus.AdaptiveMode {
+		return nil
+	}
+	
+	controller := NewAdaptiveBitrateController(opus, apply, apm.metrics.getRecorder())
+	controller.SetEventRecorder(events)
+	apm.metrics.SetRecorder(controller)
+	
+	go controller.Run(apm.ctx, DefaultAdaptiveInterval)
+	
+	return controller
+}
+
+// SetEventRecorder sets the recorder that pipeline events are emitted through.
+// Passing nil disables event recording.
+func (apm *AudioPipelineManager) SetEventRecorder(recorder EventRecorder) {
+	apm.stateMutex.Lock()
+	defer apm.stateMutex.Unlock()
+	apm.events = recorder
+}
+
+// SetAudioEncoder configures an encoder from the Opus config and makes it the
+// pipeline's encoder. It fails without replacing the current encoder if the
+// encoder can't apply the configured FEC or DTX.
+func (apm *AudioPipelineManager) SetAudioEncoder(encoder AudioEncoder) error {
+	if encoder == nil {
+		return fmt.Errorf("encoder cannot be nil")
+	}
+	
+	apm.stateMutex.Lock()
+	defer apm.stateMutex.Unlock()
+	
+	if err := ConfigureEncoder(encoder, apm.config.EncoderConfig()); err != nil {
+		return fmt.Errorf("failed to configure encoder: %w", err)
+	}
+	apm.audioEncoder = encoder
+	return nil
+}
+
+// GetConfig returns the active pipeline configuration
+func (apm *AudioPipelineManager) GetConfig() *PipelineConfig {
+	apm.stateMutex.RLock()
+	defer apm.stateMutex.RUnlock()
+	return apm.config
+}
+
+// StreamConfig returns the config for the current stream, with its source's
+// profile merged over the base config. Before Start it is the base config.
+func (apm *AudioPipelineManager) StreamConfig() *PipelineConfig {
+	apm.stateMutex.RLock()
+	defer apm.stateMutex.RUnlock()
+	return apm.streamConfigLocked()
+}
+
+// streamConfigLocked is StreamConfig for callers holding the state lock
+func (apm *AudioPipelineManager) streamConfigLocked() *PipelineConfig {
+	if apm.streamConfig == nil {
+		return apm.config
+	}
+	return apm.streamConfig
+}
+
+// ReloadConfig validates and applies a new configuration, returning the
+// fields that changed. The diff is logged and emitted as a config_reloaded
+// event so config drift can be audited.
+func (apm *AudioPipelineManager) ReloadConfig(config *PipelineConfig) ([]FieldChange, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	
+	apm.stateMutex.Lock()
+	changes := apm.config.Diff(*config)
+	apm.config = config
+	events := apm.events
+	apm.stateMutex.Unlock()
+	
+	apm.metrics.SetConfigHash(config.Fingerprint())
+	apm.metrics.SetStaticTags(config.Logging.StaticFields)
+	
+	apm.logger.Info("Pipeline configuration reloaded",
+		Int("changed_fields", len(changes)),
+		String("config_hash", config.Fingerprint()),
+		Any("changes", changes),
+	)
+	
+	apm.metrics.RecordPipelineCounter("pipeline.config_reloads", 1, nil)
+	
+	if events != nil {
+		events.RecordEvent("config_reloaded", "low", map[string]interface{}{
+			"changes": changes,
+		})
+	}
+	
+	return changes, nil
+}
+
+// ConfigHash returns the fingerprint of the active configuration, as tagged
+// onto the pipeline's metrics
+func (apm *AudioPipelineManager) ConfigHash() string {
+	return apm.GetConfig().Fingerprint()
+}
+
 // GetPipelineID returns the unique pipeline identifier
 func (apm *AudioPipelineManager) GetPipelineID() string {
 	return apm.pipelineID
@@ -290,6 +486,22 @@ func (apm *AudioPipelineManager) handleError(err *PipelineError) {
 	// Record error metric
 	apm.metrics.RecordError(err.Err.Error(), err.Category)
 	
+	// Hand the error to the best matching recovery strategy, if any
+	if strategy := apm.selectRecoveryStrategy(err); strategy != nil {
+		// One recovery at a time; errors reported while one runs are
+		// usually fallout from the same failure
+		if !apm.beginRecovery() {
+			apm.logger.Info("Recovery already in progress, not starting another", Error(err.Err))
+			return
+		}
+		if !apm.spendRecoveryBudget() {
+			apm.endRecovery()
+			return
+		}
+		go apm.runRecovery(strategy, err)
+		return
+	}
+	
 	// TODO: In later tasks, implement error recovery logic
 	if err.Severity == SeverityCritical {
 		apm.logger.Error("Critical error, stopping pipeline")
@@ -297,6 +509,152 @@ func (apm *AudioPipelineManager) handleError(err *PipelineError) {
 	}
 }
 
+// AddRecoveryStrategy registers a strategy consulted when errors are reported
+func (apm *AudioPipelineManager) AddRecoveryStrategy(strategy RecoveryStrategy) {
+	apm.recoveryMutex.Lock()
+	defer apm.recoveryMutex.Unlock()
+	apm.recoveryStrategies = append(apm.recoveryStrategies, strategy)
+}
+
+// selectRecoveryStrategy picks the registered strategy for an error
+func (apm *AudioPipelineManager) selectRecoveryStrategy(err *PipelineError) RecoveryStrategy {
+	apm.recoveryMutex.RLock()
+	defer apm.recoveryMutex.RUnlock()
+	return SelectRecoveryStrategy(err, apm.recoveryStrategies)
+}
+
+// beginRecovery claims the right to run a recovery, returning false if one
+// is already running
+func (apm *AudioPipelineManager) beginRecovery() bool {
+	apm.recoveryMutex.Lock()
+	defer apm.recoveryMutex.Unlock()
+	
+	if apm.recovering {
+		return false
+	}
+	apm.recovering = true
+	return true
+}
+
+// endRecovery releases the claim taken by beginRecovery. runRecovery
+// releases it under stateMutex along with its last state change, so an error
+// reported once the state is visible can start the next recovery.
+func (apm *AudioPipelineManager) endRecovery() {
+	apm.recoveryMutex.Lock()
+	defer apm.recoveryMutex.Unlock()
+	apm.recovering = false
+}
+
+// changeStateIfRecoveringLocked changes state only while the pipeline is
+// still recovering, so a recovery finishing after Stop doesn't undo it.
+// Callers hold stateMutex.
+func (apm *AudioPipelineManager) changeStateIfRecoveringLocked(newState PipelineState, reason string) bool {
+	if apm.state != StateRecovering {
+		return false
+	}
+	apm.changeState(newState, reason)
+	return true
+}
+
+// spendRecoveryBudget records a recovery against the sliding-window budget.
+// Once the budget is spent the pipeline fails instead of thrashing.
+func (apm *AudioPipelineManager) spendRecoveryBudget() bool {
+	recovery := apm.GetConfig().Recovery
+	if apm.recoveryBudget.Allow(recovery.BudgetMaxRecoveries, recovery.BudgetWindow) {
+		return true
+	}
+	
+	apm.logger.Error("Recovery budget exhausted, failing pipeline",
+		Int("max_recoveries", recovery.BudgetMaxRecoveries),
+		Duration("window", recovery.BudgetWindow),
+	)
+	apm.metrics.RecordPipelineCounter(MetricRecoveryBudgetExhausted, 1, nil)
+	
+	apm.stateMutex.Lock()
+	defer apm.stateMutex.Unlock()
+	
+	if apm.state == StateFailed {
+		return false
+	}
+	
+	if apm.events != nil {
+		apm.events.RecordEvent("recovery_budget_exhausted", "high", map[string]interface{}{
+			"max_recoveries": recovery.BudgetMaxRecoveries,
+			"window":         recovery.BudgetWindow.String(),
+		})
+	}
+	apm.changeState(StateFailed, "recovery budget exhausted")
+	return false
+}
+
+// runRecovery attempts recovery with the strategy up to its attempt limit.
+// Network and stream errors instead follow the configured backoff, waiting
+// longer before each retry until MaxRetries runs out.
+func (apm *AudioPipelineManager) runRecovery(strategy RecoveryStrategy, err *PipelineError) {
+	name := fmt.Sprintf("%T", strategy)
+	if named, ok := strategy.(interface{ Name() string }); ok {
+		name = named.Name()
+	}
+	
+	// A stopped pipeline stays stopped
+	apm.stateMutex.Lock()
+	if apm.state == StateIdle || apm.state == StateStopping || apm.ctx.Err() != nil {
+		apm.endRecovery()
+		apm.stateMutex.Unlock()
+		apm.logger.Info("Pipeline stopped, skipping recovery", String("strategy", name))
+		return
+	}
+	apm.changeState(StateRecovering, fmt.Sprintf("%s recovery: %s", name, err.Err.Error()))
+	apm.stateMutex.Unlock()
+	recoveringSince := time.Now()
+	
+	attempts := strategy.MaxAttempts()
+	backoff := apm.GetConfig().Recovery.Backoff
+	useBackoff := retriesWithBackoff(err.Category)
+	if useBackoff {
+		attempts = 1 + backoff.MaxRetries
+	}
+	
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if useBackoff && attempt > 1 {
+			if !apm.waitRecoveryBackoff(name, err.Category, attempt, backoff.Delay(attempt-1)) {
+				apm.endRecovery()
+				return
+			}
+		}
+		
+		apm.logger.Info("Attempting pipeline recovery",
+			String("strategy", name),
+			Int("attempt", attempt),
+		)
+		
+		lastErr = strategy.Recover(apm.ctx, apm)
+		if lastErr == nil {
+			apm.metrics.RecordPipelineCounter("pipeline.recoveries", 1, map[string]string{"strategy": name})
+			
+			apm.stateMutex.Lock()
+			apm.endRecovery()
+			apm.changeStateIfRecoveringLocked(StateStreaming, fmt.Sprintf("recovered by %s", name))
+			apm.stateMutex.Unlock()
+			apm.metrics.RecordRecoveryOutcome(name, err.Category, time.Since(recoveringSince), true)
+			return
+		}
+		
+		apm.logger.Warn("Recovery attempt failed",
+			String("strategy", name),
+			Int("attempt", attempt),
+			Error(lastErr),
+		)
+	}
+	
+	apm.stateMutex.Lock()
+	apm.endRecovery()
+	apm.changeStateIfRecoveringLocked(StateFailed, fmt.Sprintf("%s recovery failed: %v", name, lastErr))
+	apm.stateMutex.Unlock()
+	apm.metrics.RecordRecoveryOutcome(name, err.Category, time.Since(recoveringSince), false)
+}
+
 // handleStateChange processes state changes
 func (apm *AudioPipelineManager) handleStateChange(change StateChange) {
 	apm.logger.Debug("Processing state change",
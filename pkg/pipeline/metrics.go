@@ -269,6 +269,11 @@ func (c *BasicMetricsCollector) copyTags(tags map[string]string) map[string]stri
 type PipelineMetricsCollector struct {
 	*BasicMetricsCollector
 	pipelineID string
+	recorder   MetricRecorder
+	recorderMu sync.RWMutex
+	configHash string
+	hashMu     sync.RWMutex
+	staticTags map[string]string
 }
 
 // NewPipelineMetricsCollector creates a new pipeline-specific metrics collector
@@ -276,40 +281,92 @@ func NewPipelineMetricsCollector(pipelineID string, logger Logger) *PipelineMetr
 	return &PipelineMetricsCollector{
 		BasicMetricsCollector: NewBasicMetricsCollector(logger),
 		pipelineID:           pipelineID,
+		recorder:             NoopMetricRecorder{},
 	}
 }
 
+// SetRecorder sets the external recorder that pipeline metrics are forwarded to
+func (c *PipelineMetricsCollector) SetRecorder(recorder MetricRecorder) {
+	if recorder == nil {
+		recorder = NoopMetricRecorder{}
+	}
+	c.recorderMu.Lock()
+	c.recorder = recorder
+	c.recorderMu.Unlock()
+}
+
+// getRecorder returns the current external recorder
+func (c *PipelineMetricsCollector) getRecorder() MetricRecorder {
+	c.recorderMu.RLock()
+	defer c.recorderMu.RUnlock()
+	return c.recorder
+}
+
+// SetConfigHash sets the config fingerprint tagged onto every pipeline metric.
+// An empty hash omits the tag.
+func (c *PipelineMetricsCollector) SetConfigHash(hash string) {
+	c.hashMu.Lock()
+	c.configHash = hash
+	c.hashMu.Unlock()
+}
+
+// SetStaticTags sets tags, such as the instance name, added to every
+// pipeline metric. Tags passed with a metric take precedence.
+func (c *PipelineMetricsCollector) SetStaticTags(tags map[string]string) {
+	c.hashMu.Lock()
+	c.staticTags = c.copyTags(tags)
+	c.hashMu.Unlock()
+}
+
 // RecordPipelineCounter records a counter with pipeline tags
 func (c *PipelineMetricsCollector) RecordPipelineCounter(name string, value int64, tags map[string]string) {
 	pipelineTags := c.addPipelineTags(tags)
 	c.RecordCounter(name, value, pipelineTags)
+	c.getRecorder().Counter(name, value, pipelineTags)
 }
 
 // RecordPipelineGauge records a gauge with pipeline tags
 func (c *PipelineMetricsCollector) RecordPipelineGauge(name string, value float64, tags map[string]string) {
 	pipelineTags := c.addPipelineTags(tags)
 	c.RecordGauge(name, value, pipelineTags)
+	c.getRecorder().Gauge(name, value, pipelineTags)
 }
 
 // RecordPipelineHistogram records a histogram with pipeline tags
 func (c *PipelineMetricsCollector) RecordPipelineHistogram(name string, value float64, tags map[string]string) {
 	pipelineTags := c.addPipelineTags(tags)
 	c.RecordHistogram(name, value, pipelineTags)
+	c.getRecorder().Histogram(name, value, pipelineTags)
 }
 
 // RecordPipelineTiming records a timing with pipeline tags
 func (c *PipelineMetricsCollector) RecordPipelineTiming(name string, duration time.Duration, tags map[string]string) {
 	pipelineTags := c.addPipelineTags(tags)
 	c.RecordTiming(name, duration, pipelineTags)
+	c.getRecorder().Timing(name, duration, pipelineTags)
 }
 
 // addPipelineTags adds pipeline-specific tags to the provided tags
 func (c *PipelineMetricsCollector) addPipelineTags(tags map[string]string) map[string]string {
 	pipelineTags := make(map[string]string)
 	
+	// Add static tags
+	c.hashMu.RLock()
+	for k, v := range c.staticTags {
+		pipelineTags[k] = v
+	}
+	c.hashMu.RUnlock()
+	
 	// Add pipeline ID
 	pipelineTags["pipeline_id"] = c.pipelineID
 	
+	// Add config fingerprint
+	c.hashMu.RLock()
+	if c.configHash != "" {
+		pipelineTags[ConfigHashTag] = c.configHash
+	}
+	c.hashMu.RUnlock()
+	
 	// Copy existing tags
 	for k, v := range tags {
 		pipelineTags[k] = v
@@ -335,6 +392,16 @@ func (c *PipelineMetricsCollector) RecordEncodingTime(duration time.Duration) {
 	c.RecordPipelineTiming("pipeline.encoding.time", duration, nil)
 }
 
+// RecordUnderrun records a frame that was not ready when it was due
+func (c *PipelineMetricsCollector) RecordUnderrun() {
+	c.RecordPipelineCounter(MetricUnderruns, 1, nil)
+}
+
+// RecordFramesBehind records how many frames playback is behind real time
+func (c *PipelineMetricsCollector) RecordFramesBehind(frames int) {
+	c.RecordPipelineGauge(MetricFramesBehind, float64(frames), nil)
+}
+
 // RecordError records an error metric
 func (c *PipelineMetricsCollector) RecordError(errorType string, category ErrorCategory) {
 	tags := map[string]string{
@@ -353,6 +420,34 @@ func (c *PipelineMetricsCollector) RecordRecoveryAttempt(strategy string, succes
 	c.RecordPipelineCounter("pipeline.recovery.attempts", 1, tags)
 }
 
+// Recovery outcome metrics, tagged with the strategy and the category of the
+// error that started the recovery
+const (
+	MetricRecoveryDuration  = "pipeline.recovery.duration_ms" // histogram: time from Recovering to Streaming or Failed
+	MetricRecoverySucceeded = "pipeline.recovery.succeeded"   // counter: recoveries that resumed streaming
+	MetricRecoveryFailed    = "pipeline.recovery.failed"      // counter: recoveries that gave up and failed the pipeline
+)
+
+// RecordRecoveryOutcome records how long a recovery took and whether it
+// resumed streaming or failed the pipeline
+func (c *PipelineMetricsCollector) RecordRecoveryOutcome(strategy string, category ErrorCategory, duration time.Duration, success bool) {
+	outcome, counter := "succeeded", MetricRecoverySucceeded
+	if !success {
+		outcome, counter = "failed", MetricRecoveryFailed
+	}
+	
+	tags := map[string]string{
+		"strategy": strategy,
+		"category": category.String(),
+		"outcome":  outcome,
+	}
+	c.RecordPipelineHistogram(MetricRecoveryDuration, float64(duration.Nanoseconds())/1e6, tags)
+	c.RecordPipelineCounter(counter, 1, map[string]string{
+		"strategy": strategy,
+		"category": category.String(),
+	})
+}
+
 // RecordResourceUsage records resource usage metrics
 func (c *PipelineMetricsCollector) RecordResourceUsage(cpuUsage float64, memoryUsage int64, networkBandwidth int64) {
 	c.RecordPipelineGauge("pipeline.resources.cpu_usage", cpuUsage, nil)
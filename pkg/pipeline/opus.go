@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Opus bitrate limits, in bits per second
+const (
+	MinOpusBitrate = 8000
+	MaxOpusBitrate = 510000
+)
+
+// DiscordOpusFrameDuration is the only frame duration Discord voice plays
+// back at the right speed: discordgo paces and timestamps every packet it
+// sends as a 20ms frame
+const DiscordOpusFrameDuration = 20 * time.Millisecond
+
+// opusFrameDurations are the frame durations Opus can encode
+var opusFrameDurations = []time.Duration{
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	40 * time.Millisecond,
+	60 * time.Millisecond,
+}
+
+// isOpusFrameDuration reports whether Opus can encode frames of duration
+func isOpusFrameDuration(duration time.Duration) bool {
+	for _, valid := range opusFrameDurations {
+		if duration == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// EncoderConfig returns the Opus config encoders are created with: the Opus
+// section with the audio section's bitrate and frame duration overrides
+// applied, the frame duration as a frame size in samples per channel
+func (c *PipelineConfig) EncoderConfig() OpusConfig {
+	opus := c.Opus
+	if c.Audio.OpusBitrate > 0 {
+		opus.Bitrate = c.Audio.OpusBitrate
+	}
+	if c.Audio.OpusFrameDuration > 0 {
+		opus.FrameSize = int(int64(opus.SampleRate) * int64(c.Audio.OpusFrameDuration) / int64(time.Second))
+	}
+	return opus
+}
+
+// ErrOpusResilienceUnsupported is returned by ConfigureEncoder when FEC or
+// DTX is enabled but the encoder can't apply them
+var ErrOpusResilienceUnsupported = errors.New("encoder does not support opus fec/dtx")
+
+// OpusResilienceEncoder is implemented by encoders that can apply Opus loss
+// resilience settings
+type OpusResilienceEncoder interface {
+	AudioEncoder
+	SetInbandFEC(enabled bool, packetLossPercent int) error
+	SetDTX(enabled bool) error
+}
+
+// ConfigureEncoder applies the Opus config's bitrate, complexity, FEC and DTX
+// settings to an encoder. Encoders without resilience support are accepted
+// as long as FEC and DTX are off.
+func ConfigureEncoder(encoder AudioEncoder, opus OpusConfig) error {
+	if err := encoder.SetBitrate(opus.Bitrate); err != nil {
+		return fmt.Errorf("failed to set bitrate: %w", err)
+	}
+	if err := encoder.SetComplexity(opus.Complexity); err != nil {
+		return fmt.Errorf("failed to set complexity: %w", err)
+	}
+
+	resilient, ok := encoder.(OpusResilienceEncoder)
+	if !ok {
+		if opus.FEC || opus.DTX {
+			return ErrOpusResilienceUnsupported
+		}
+		return nil
+	}
+
+	if err := resilient.SetInbandFEC(opus.FEC, opus.PacketLoss); err != nil {
+		return fmt.Errorf("failed to set fec: %w", err)
+	}
+	if err := resilient.SetDTX(opus.DTX); err != nil {
+		return fmt.Errorf("failed to set dtx: %w", err)
+	}
+	return nil
+}
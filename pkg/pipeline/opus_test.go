@@ -0,0 +1,232 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpusApplication_Validation(t *testing.T) {
+	config := DefaultPipelineConfig()
+	assert.Equal(t, OpusApplicationAudio, config.Opus.Application)
+	assert.NoError(t, config.Validate())
+
+	for _, mode := range []string{OpusApplicationVoIP, OpusApplicationAudio, OpusApplicationLowDelay} {
+		config.Opus.Application = mode
+		assert.NoError(t, config.Validate(), mode)
+	}
+
+	for _, mode := range []string{"", "music", "restricted_lowdelay"} {
+		config.Opus.Application = mode
+		assert.Error(t, config.Validate(), mode)
+	}
+}
+
+func TestOpusApplication_Environment(t *testing.T) {
+	t.Setenv("PIPELINE_OPUS_APPLICATION", "LowDelay")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.Equal(t, OpusApplicationLowDelay, config.Opus.Application)
+	assert.NoError(t, config.Validate())
+}
+
+func TestOpusApplication_InMetrics(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Opus.Application = OpusApplicationVoIP
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	assert.Equal(t, OpusApplicationVoIP, manager.GetMetrics().AudioQuality.Application)
+}
+
+// spyEncoder records the settings applied to it. Without resilience it
+// stands in for an encoder that only supports bitrate and complexity.
+type spyEncoder struct {
+	bitrate    int
+	complexity int
+	fec        bool
+	packetLoss int
+	dtx        bool
+}
+
+func (e *spyEncoder) Encode(pcmData []int16, frameSize int) ([]byte, error) { return nil, nil }
+func (e *spyEncoder) SetBitrate(bitrate int) error                          { e.bitrate = bitrate; return nil }
+func (e *spyEncoder) SetComplexity(complexity int) error                    { e.complexity = complexity; return nil }
+func (e *spyEncoder) GetEncodingMetrics() map[string]interface{}            { return nil }
+
+type spyResilienceEncoder struct{ spyEncoder }
+
+func (e *spyResilienceEncoder) SetInbandFEC(enabled bool, packetLossPercent int) error {
+	e.fec, e.packetLoss = enabled, packetLossPercent
+	return nil
+}
+
+func (e *spyResilienceEncoder) SetDTX(enabled bool) error {
+	e.dtx = enabled
+	return nil
+}
+
+func TestOpusResilience_Validation(t *testing.T) {
+	config := DefaultPipelineConfig()
+	assert.False(t, config.Opus.FEC)
+	assert.False(t, config.Opus.DTX)
+
+	config.Opus.FEC = true
+	assert.Error(t, config.Validate(), "fec needs an expected packet loss")
+
+	config.Opus.PacketLoss = 10
+	config.Opus.DTX = true
+	assert.NoError(t, config.Validate())
+
+	config.Opus.Application = OpusApplicationLowDelay
+	err := config.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "opus fec is not available")
+	assert.Contains(t, err.Error(), "opus dtx is not available")
+
+	config.Opus.Application = OpusApplicationAudio
+	config.Opus.PacketLoss = 101
+	assert.Error(t, config.Validate())
+}
+
+func TestOpusResilience_Environment(t *testing.T) {
+	t.Setenv("PIPELINE_OPUS_FEC", "true")
+	t.Setenv("PIPELINE_OPUS_PACKET_LOSS", "15")
+	t.Setenv("PIPELINE_OPUS_DTX", "1")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.True(t, config.Opus.FEC)
+	assert.Equal(t, 15, config.Opus.PacketLoss)
+	assert.True(t, config.Opus.DTX)
+	assert.NoError(t, config.Validate())
+}
+
+func TestSetAudioEncoder_AppliesResilienceFlags(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Opus.FEC = true
+	config.Opus.PacketLoss = 20
+	config.Opus.DTX = true
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+
+	encoder := &spyResilienceEncoder{}
+	require.NoError(t, manager.SetAudioEncoder(encoder))
+	assert.Equal(t, config.Opus.Bitrate, encoder.bitrate)
+	assert.Equal(t, config.Opus.Complexity, encoder.complexity)
+	assert.True(t, encoder.fec)
+	assert.Equal(t, 20, encoder.packetLoss)
+	assert.True(t, encoder.dtx)
+
+	quality := manager.GetMetrics().AudioQuality
+	assert.True(t, quality.FEC)
+	assert.True(t, quality.DTX)
+}
+
+func TestSetAudioEncoder_RejectsUnsupportedResilience(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Opus.DTX = true
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+
+	err = manager.SetAudioEncoder(&spyEncoder{})
+	assert.ErrorIs(t, err, ErrOpusResilienceUnsupported)
+
+	// Plain encoders are fine while FEC and DTX are off
+	assert.NoError(t, ConfigureEncoder(&spyEncoder{}, DefaultPipelineConfig().Opus))
+}
+
+func TestOpusTuning_Validation(t *testing.T) {
+	config := DefaultPipelineConfig()
+	assert.Zero(t, config.Audio.OpusBitrate)
+	assert.Zero(t, config.Audio.OpusFrameDuration)
+
+	for _, bitrate := range []int{MinOpusBitrate, 64000, 128000, MaxOpusBitrate} {
+		config.Audio.OpusBitrate = bitrate
+		assert.NoError(t, config.Validate(), bitrate)
+	}
+	for _, bitrate := range []int{-1, 7999, 510001} {
+		config.Audio.OpusBitrate = bitrate
+		assert.Error(t, config.Validate(), bitrate)
+	}
+	config.Audio.OpusBitrate = 0
+
+	config.Audio.OpusFrameDuration = DiscordOpusFrameDuration
+	assert.NoError(t, config.Validate())
+	for _, duration := range []time.Duration{time.Millisecond, 15 * time.Millisecond, 120 * time.Millisecond} {
+		config.Audio.OpusFrameDuration = duration
+		err := config.Validate()
+		require.Error(t, err, duration)
+		assert.Contains(t, err.Error(), "must be one of", duration)
+	}
+
+	// Durations Opus can encode but Discord can't play are rejected too
+	for _, duration := range opusFrameDurations {
+		if duration == DiscordOpusFrameDuration {
+			continue
+		}
+		config.Audio.OpusFrameDuration = duration
+		err := config.Validate()
+		require.Error(t, err, duration)
+		assert.Contains(t, err.Error(), "Discord voice only plays 20ms frames", duration)
+	}
+}
+
+func TestOpusTuning_InvalidCombinationsFailManager(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Opus.FEC = true
+	config.Opus.PacketLoss = 10
+	config.Audio.OpusFrameDuration = 5 * time.Millisecond
+	_, err := NewAudioPipelineManager(config, NullLogger())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "opus fec requires", "fec needs frames of 10ms or more")
+
+	config.Audio.OpusFrameDuration = 20 * time.Millisecond
+	_, err = NewAudioPipelineManager(config, NullLogger())
+	assert.NoError(t, err)
+
+	config = DefaultPipelineConfig()
+	config.Opus.AdaptiveMode = true
+	config.Audio.OpusBitrate = 32000
+	_, err = NewAudioPipelineManager(config, NullLogger())
+	assert.Error(t, err, "the overridden bitrate is below the adaptive minimum")
+}
+
+func TestOpusTuning_Environment(t *testing.T) {
+	t.Setenv("PIPELINE_AUDIO_OPUS_BITRATE", "64000")
+	t.Setenv("PIPELINE_AUDIO_OPUS_FRAME_DURATION", "20ms")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.Equal(t, 64000, config.Audio.OpusBitrate)
+	assert.Equal(t, 20*time.Millisecond, config.Audio.OpusFrameDuration)
+	assert.NoError(t, config.Validate())
+
+	opus := config.EncoderConfig()
+	assert.Equal(t, 64000, opus.Bitrate)
+	assert.Equal(t, 960, opus.FrameSize)
+
+	// The frame size follows the duration, even for ones Validate rejects
+	config.Audio.OpusFrameDuration = 2500 * time.Microsecond
+	assert.Equal(t, 120, config.EncoderConfig().FrameSize)
+}
+
+func TestSetAudioEncoder_AppliesOpusTuning(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Audio.OpusBitrate = 64000
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+
+	encoder := &spyEncoder{}
+	require.NoError(t, manager.SetAudioEncoder(encoder))
+	assert.Equal(t, 64000, encoder.bitrate)
+
+	config.Audio.OpusBitrate = 0
+	assert.Equal(t, config.Opus.Bitrate, config.EncoderConfig().Bitrate, "zero keeps the opus section's bitrate")
+	assert.Equal(t, config.Opus.FrameSize, config.EncoderConfig().FrameSize)
+}
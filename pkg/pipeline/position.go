@@ -0,0 +1,213 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultPositionUpdateInterval is how often PositionUpdates ticks while
+// streaming
+const DefaultPositionUpdateInterval = time.Second
+
+// playbackPosition tracks how far into the stream playback is from the audio
+// sent: the offset the stream started or was last sought to, plus the
+// duration of the frames sent since. It has its own lock so frames can be
+// counted without the state lock.
+type playbackPosition struct {
+	mu        sync.Mutex
+	base      time.Duration // where the stream started or was last sought to
+	sent      time.Duration // audio sent since base
+	frames    int64         // frames sent since the stream started
+	bytes     int64         // bytes sent since the stream started
+	epoch     uint64        // bumped on every move, so frames in flight across a seek aren't counted after it
+	streaming bool          // whether PositionUpdates ticks
+
+	updates chan time.Duration // nil until PositionUpdates is first called
+}
+
+// at returns the position
+func (p *playbackPosition) at() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.base + p.sent
+}
+
+// counts returns the frames and bytes sent since the stream started
+func (p *playbackPosition) counts() (frames, bytes int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.frames, p.bytes
+}
+
+// reset returns to the start of a stream, streaming or not
+func (p *playbackPosition) reset(streaming bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.base, p.sent = 0, 0
+	p.frames, p.bytes = 0, 0
+	p.epoch++
+	p.streaming = streaming
+}
+
+// seek moves the position, counting frames from there
+func (p *playbackPosition) seek(position time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.base, p.sent = position, 0
+	p.epoch++
+	p.streaming = true
+}
+
+// run starts PositionUpdates ticking
+func (p *playbackPosition) run() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.streaming = true
+}
+
+// hold stops PositionUpdates ticking
+func (p *playbackPosition) hold() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.streaming = false
+}
+
+// mark returns the current epoch, to be passed to advance once a frame is sent
+func (p *playbackPosition) mark() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.epoch
+}
+
+// advance counts a sent frame of duration and size. The frame only moves
+// the position if it didn't move in the meantime.
+func (p *playbackPosition) advance(epoch uint64, duration time.Duration, size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.frames++
+	p.bytes += int64(size)
+	if epoch == p.epoch {
+		p.sent += duration
+	}
+}
+
+// subscribe returns the updates channel, creating it on first use
+func (p *playbackPosition) subscribe() <-chan time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.updates == nil {
+		p.updates = make(chan time.Duration, 1)
+	}
+	return p.updates
+}
+
+// publish sends the position to the updates channel while streaming,
+// replacing an update the reader hasn't taken yet so it never sees a
+// stale position
+func (p *playbackPosition) publish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.updates == nil || !p.streaming {
+		return
+	}
+
+	select {
+	case <-p.updates:
+	default:
+	}
+	p.updates <- p.base + p.sent
+}
+
+// positionTicker publishes the position on a fixed interval until stopped
+type positionTicker struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Position returns how far into the stream playback is, from the frames
+// sent with SendFrame since the stream started or was last sought. It is
+// zero after Stop.
+func (apm *AudioPipelineManager) Position() time.Duration {
+	return apm.position.at()
+}
+
+// PositionUpdates returns a channel receiving the position every
+// Discord.PositionUpdateInterval while streaming, for progress bars. It is
+// buffered to one update, which a newer one replaces if unread, and is
+// never closed.
+func (apm *AudioPipelineManager) PositionUpdates() <-chan time.Duration {
+	return apm.position.subscribe()
+}
+
+// SendFrame sends an encoded frame through the Discord streamer and counts
+// it towards the position. Frames can only be sent while streaming.
+func (apm *AudioPipelineManager) SendFrame(frame []byte) error {
+	apm.stateMutex.RLock()
+	state := apm.state
+	streamer := apm.discordStreamer
+	opus := apm.streamConfigLocked().EncoderConfig()
+	apm.stateMutex.RUnlock()
+
+	if state != StateStreaming {
+		return fmt.Errorf("cannot send frame in state: %s", state)
+	}
+	if streamer == nil {
+		return fmt.Errorf("no discord streamer set")
+	}
+
+	epoch := apm.position.mark()
+	if err := streamer.SendOpusFrame(frame); err != nil {
+		return err
+	}
+
+	duration := time.Duration(opus.FrameSize) * time.Second / time.Duration(opus.SampleRate)
+	apm.position.advance(epoch, duration, len(frame))
+	return nil
+}
+
+// startPositionTickerLocked starts publishing position updates. Callers
+// hold stateMutex.
+func (apm *AudioPipelineManager) startPositionTickerLocked() {
+	if apm.positionTicker != nil {
+		return
+	}
+
+	ticker := &positionTicker{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	apm.positionTicker = ticker
+
+	go apm.runPositionTicker(ticker, apm.config.Discord.PositionUpdateInterval)
+}
+
+// stopPositionTickerLocked stops publishing position updates and waits for
+// the last one. Callers hold stateMutex.
+func (apm *AudioPipelineManager) stopPositionTickerLocked() {
+	if apm.positionTicker == nil {
+		return
+	}
+
+	close(apm.positionTicker.stop)
+	<-apm.positionTicker.done
+	apm.positionTicker = nil
+}
+
+// runPositionTicker publishes the position every interval. It only takes the
+// position's lock, so stopping it under the state lock can't deadlock.
+func (apm *AudioPipelineManager) runPositionTicker(ticker *positionTicker, interval time.Duration) {
+	defer close(ticker.done)
+
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ticker.stop:
+			return
+		case <-tick.C:
+			apm.position.publish()
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPositionManager(t *testing.T, config *PipelineConfig) (*AudioPipelineManager, *recordingSink) {
+	t.Helper()
+	if config == nil {
+		config = DefaultPipelineConfig()
+	}
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	sink := &recordingSink{}
+	manager.SetDiscordStreamer(sink)
+	manager.SetStreamProcessor(&fakeProcessor{})
+	require.NoError(t, manager.Start(context.Background(), "/music/track.flac"))
+	return manager, sink
+}
+
+func TestPosition_FromFramesSent(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Opus.FrameSize = 1920 // 40ms
+	manager, sink := newPositionManager(t, config)
+	assert.Zero(t, manager.Position())
+
+	for i := 0; i < 25; i++ {
+		require.NoError(t, manager.SendFrame(make([]byte, 100)))
+	}
+	assert.Equal(t, time.Second, manager.Position())
+
+	frames, _ := sink.Frames()
+	assert.Len(t, frames, 25)
+	metrics := manager.GetMetrics()
+	assert.Equal(t, int64(25), metrics.FramesSent)
+	assert.Equal(t, int64(2500), metrics.BytesSent)
+}
+
+func TestPosition_SeekAndStop(t *testing.T) {
+	manager, _ := newPositionManager(t, nil)
+	require.NoError(t, manager.SendFrame([]byte{1}))
+
+	require.NoError(t, manager.Seek(context.Background(), 2*time.Minute))
+	assert.Equal(t, 2*time.Minute, manager.Position())
+	require.NoError(t, manager.SendFrame([]byte{1}))
+	assert.Equal(t, 2*time.Minute+20*time.Millisecond, manager.Position())
+
+	require.NoError(t, manager.Stop())
+	assert.Zero(t, manager.Position())
+	assert.Zero(t, manager.GetMetrics().FramesSent)
+	assert.Error(t, manager.SendFrame([]byte{1}), "frames aren't sent once stopped")
+}
+
+func TestPosition_ConcurrentSendsAndReads(t *testing.T) {
+	manager, _ := newPositionManager(t, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, manager.SendFrame([]byte{1}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				manager.Position()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 200*20*time.Millisecond, manager.Position())
+}
+
+func TestPositionUpdates_TickWhileStreaming(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Discord.PositionUpdateInterval = 10 * time.Millisecond
+	manager, _ := newPositionManager(t, config)
+	updates := manager.PositionUpdates()
+	assert.Equal(t, updates, manager.PositionUpdates(), "every caller shares the channel")
+
+	require.NoError(t, manager.Seek(context.Background(), time.Minute))
+	select {
+	case position := <-updates:
+		assert.GreaterOrEqual(t, position, time.Duration(0))
+	case <-time.After(time.Second):
+		t.Fatal("no position update while streaming")
+	}
+
+	// Let any update in flight land, then nothing more arrives while paused
+	require.NoError(t, manager.Pause())
+	select {
+	case <-updates:
+	default:
+	}
+	select {
+	case position := <-updates:
+		t.Fatalf("position update %s while paused", position)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, manager.Resume())
+	require.NoError(t, manager.SendFrame([]byte{1}))
+	assert.Eventually(t, func() bool {
+		select {
+		case position := <-updates:
+			return position == time.Minute+20*time.Millisecond
+		default:
+			return false
+		}
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestPositionUpdates_Config(t *testing.T) {
+	t.Setenv("PIPELINE_DISCORD_POSITION_INTERVAL", "250ms")
+
+	config := DefaultPipelineConfig()
+	assert.Equal(t, DefaultPositionUpdateInterval, config.Discord.PositionUpdateInterval)
+	config.LoadFromEnvironment()
+	assert.Equal(t, 250*time.Millisecond, config.Discord.PositionUpdateInterval)
+
+	config.Discord.PositionUpdateInterval = 0
+	assert.Error(t, config.Validate())
+}
@@ -0,0 +1,212 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SourceKind is the kind of source a stream comes from, used to pick the
+// config profile merged over the base config when the stream starts
+type SourceKind string
+
+const (
+	SourceLive  SourceKind = "live"  // an HTTP stream with no end, such as internet radio
+	SourceMusic SourceKind = "music" // a track resolved from a music site such as YouTube
+	SourceFile  SourceKind = "file"  // a local file
+)
+
+// musicHosts are the hosts whose streams are tracks rather than live radio,
+// including the CDNs their resolved stream URLs point at
+var musicHosts = []string{
+	"youtube.com", "*.youtube.com", "youtu.be", "*.googlevideo.com",
+	"soundcloud.com", "*.soundcloud.com", "*.sndcdn.com",
+}
+
+// ResolveSourceKind works out what kind of source streamURL is: a local path
+// or file URL is a file, a music site is music, and any other network
+// stream is live
+func ResolveSourceKind(streamURL string) SourceKind {
+	parsed, err := url.Parse(streamURL)
+	if err != nil || parsed.Scheme == "" || parsed.Scheme == "file" {
+		return SourceFile
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, pattern := range musicHosts {
+		if matchHost(pattern, host) {
+			return SourceMusic
+		}
+	}
+	return SourceLive
+}
+
+// ConfigProfile holds the settings tuned for one kind of source. Unset
+// fields leave the base config's value alone.
+type ConfigProfile struct {
+	Reconnect  *bool  `json:"reconnect,omitempty"`   // pass FFmpeg its reconnect options
+	BufferSize string `json:"buffer_size,omitempty"` // FFmpeg pre-buffer, e.g. "512k"
+}
+
+// DefaultProfiles returns the built-in profiles: live streams reconnect and
+// pre-buffer more, and files skip the network reconnect options
+func DefaultProfiles() map[SourceKind]ConfigProfile {
+	on, off := true, false
+	return map[SourceKind]ConfigProfile{
+		SourceLive: {Reconnect: &on, BufferSize: "512k"},
+		SourceFile: {Reconnect: &off},
+	}
+}
+
+// defaultReconnectOptions are the FFmpeg reconnect options a profile turns on
+var defaultReconnectOptions = map[string]string{
+	"reconnect":           "1",
+	"reconnect_streamed":  "1",
+	"reconnect_delay_max": "5",
+}
+
+// LoadProfiles reads profiles from a JSON file keyed by source kind, such
+// as {"live": {"reconnect": true, "buffer_size": "1M"}}. Each profile in the
+// file replaces the one for its kind; kinds it leaves out keep theirs.
+func (c *PipelineConfig) LoadProfiles(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var profiles map[SourceKind]ConfigProfile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return fmt.Errorf("failed to parse profiles %s: %w", path, err)
+	}
+
+	merged := make(map[SourceKind]ConfigProfile, len(c.Profiles)+len(profiles))
+	for kind, profile := range c.Profiles {
+		merged[kind] = profile
+	}
+	for kind, profile := range profiles {
+		merged[kind] = profile
+	}
+	c.Profiles = merged
+	return nil
+}
+
+// validateProfiles reports profiles for unknown source kinds
+func (c *PipelineConfig) validateProfiles() []string {
+	var errors []string
+	for kind := range c.Profiles {
+		switch kind {
+		case SourceLive, SourceMusic, SourceFile:
+		default:
+			errors = append(errors, fmt.Sprintf("profile %q is not a source kind (live, music, file)", kind))
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
+// ForSource returns a copy of the config with the profile for streamURL's
+// source kind merged over it, and the kind. The receiver is left unchanged.
+func (c *PipelineConfig) ForSource(streamURL string) (*PipelineConfig, SourceKind) {
+	kind := ResolveSourceKind(streamURL)
+	merged := c.clone()
+
+	profile, ok := c.Profiles[kind]
+	if !ok {
+		return merged, kind
+	}
+
+	if profile.Reconnect != nil {
+		merged.FFmpeg.Args = withoutReconnectArgs(merged.FFmpeg.Args)
+		merged.FFmpeg.ReconnectOptions = nil
+		if *profile.Reconnect {
+			merged.FFmpeg.ReconnectOptions = make(map[string]string, len(defaultReconnectOptions))
+			for key, value := range defaultReconnectOptions {
+				merged.FFmpeg.ReconnectOptions[key] = value
+			}
+			merged.FFmpeg.Args = append(reconnectArgs(merged.FFmpeg.ReconnectOptions), merged.FFmpeg.Args...)
+		}
+	}
+	if profile.BufferSize != "" {
+		merged.FFmpeg.BufferSize = profile.BufferSize
+	}
+	return merged, kind
+}
+
+// reconnectArgs renders reconnect options as FFmpeg arguments in a stable
+// order
+func reconnectArgs(options map[string]string) []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		args = append(args, "-"+key, options[key])
+	}
+	return args
+}
+
+// withoutReconnectArgs drops every -reconnect* option and its value
+func withoutReconnectArgs(args []string) []string {
+	kept := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "-reconnect") {
+			i++ // skip the value
+			continue
+		}
+		kept = append(kept, args[i])
+	}
+	return kept
+}
+
+// clone returns a deep copy of the config
+func (c *PipelineConfig) clone() *PipelineConfig {
+	copied := *c
+
+	copied.StreamAcquisition.Strategies = append([]string(nil), c.StreamAcquisition.Strategies...)
+	copied.StreamAcquisition.AllowedHosts = append([]string(nil), c.StreamAcquisition.AllowedHosts...)
+	copied.StreamAcquisition.BlockedHosts = append([]string(nil), c.StreamAcquisition.BlockedHosts...)
+	copied.FFmpeg.Args = append([]string(nil), c.FFmpeg.Args...)
+	copied.FFmpeg.ReconnectOptions = copyStringMap(c.FFmpeg.ReconnectOptions)
+	copied.Health.Checks = append([]string(nil), c.Health.Checks...)
+	if c.Health.AlertThresholds != nil {
+		copied.Health.AlertThresholds = make(map[string]float64, len(c.Health.AlertThresholds))
+		for key, value := range c.Health.AlertThresholds {
+			copied.Health.AlertThresholds[key] = value
+		}
+	}
+	copied.Recovery.Strategies = append([]string(nil), c.Recovery.Strategies...)
+	copied.Logging.StaticFields = copyStringMap(c.Logging.StaticFields)
+	copied.Profiles = copyProfiles(c.Profiles)
+
+	return &copied
+}
+
+// copyStringMap copies a string map, keeping nil as nil
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(m))
+	for key, value := range m {
+		copied[key] = value
+	}
+	return copied
+}
+
+// copyProfiles copies a profile map, keeping nil as nil
+func copyProfiles(profiles map[SourceKind]ConfigProfile) map[SourceKind]ConfigProfile {
+	if profiles == nil {
+		return nil
+	}
+	copied := make(map[SourceKind]ConfigProfile, len(profiles))
+	for kind, profile := range profiles {
+		copied[kind] = profile
+	}
+	return copied
+}
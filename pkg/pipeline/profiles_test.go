@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSourceKind(t *testing.T) {
+	tests := map[string]SourceKind{
+		"https://radio.example.com/stream.mp3":          SourceLive,
+		"http://10.0.0.5:8000/live":                     SourceLive,
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ":   SourceMusic,
+		"https://youtu.be/dQw4w9WgXcQ":                  SourceMusic,
+		"https://rr3---sn-abc.googlevideo.com/playback": SourceMusic,
+		"https://soundcloud.com/artist/track":           SourceMusic,
+		"/music/track.flac":                             SourceFile,
+		"file:///music/track.flac":                      SourceFile,
+	}
+
+	for streamURL, want := range tests {
+		assert.Equal(t, want, ResolveSourceKind(streamURL), streamURL)
+	}
+}
+
+func TestForSourceAppliesReconnectOnlyToLive(t *testing.T) {
+	base := DefaultPipelineConfig()
+	// Start from a base without reconnect so only the profile can add it
+	base.FFmpeg.Args = withoutReconnectArgs(base.FFmpeg.Args)
+	base.FFmpeg.ReconnectOptions = nil
+
+	live, kind := base.ForSource("https://radio.example.com/stream.mp3")
+	assert.Equal(t, SourceLive, kind)
+	assert.Contains(t, live.FFmpeg.Args, "-reconnect")
+	assert.Equal(t, "1", live.FFmpeg.ReconnectOptions["reconnect"])
+	assert.Equal(t, "512k", live.FFmpeg.BufferSize)
+
+	file, kind := base.ForSource("/music/track.flac")
+	assert.Equal(t, SourceFile, kind)
+	assert.NotContains(t, file.FFmpeg.Args, "-reconnect")
+	assert.Empty(t, file.FFmpeg.ReconnectOptions)
+
+	// The base config is left alone
+	assert.NotContains(t, base.FFmpeg.Args, "-reconnect")
+	assert.Equal(t, DefaultPipelineConfig().FFmpeg.BufferSize, base.FFmpeg.BufferSize)
+}
+
+func TestForSourceStripsReconnectForFiles(t *testing.T) {
+	base := DefaultPipelineConfig()
+	require.Contains(t, base.FFmpeg.Args, "-reconnect")
+
+	file, _ := base.ForSource("/music/track.flac")
+	for _, arg := range file.FFmpeg.Args {
+		assert.NotContains(t, arg, "-reconnect")
+	}
+	assert.Contains(t, base.FFmpeg.Args, "-reconnect")
+}
+
+func TestLoadProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"music": {"buffer_size": "256k"}, "live": {"buffer_size": "1M"}}`), 0o644))
+
+	cfg := DefaultPipelineConfig()
+	require.NoError(t, cfg.LoadProfiles(path))
+	require.NoError(t, cfg.Validate())
+
+	music, _ := cfg.ForSource("https://youtu.be/dQw4w9WgXcQ")
+	assert.Equal(t, "256k", music.FFmpeg.BufferSize)
+
+	// The file's live profile replaces the built-in one
+	live, _ := cfg.ForSource("https://radio.example.com/stream.mp3")
+	assert.Equal(t, "1M", live.FFmpeg.BufferSize)
+	assert.Nil(t, cfg.Profiles[SourceLive].Reconnect)
+
+	// Kinds the file leaves out keep their profile
+	assert.NotNil(t, cfg.Profiles[SourceFile].Reconnect)
+}
+
+func TestValidateRejectsUnknownProfile(t *testing.T) {
+	cfg := DefaultPipelineConfig()
+	cfg.Profiles["podcast"] = ConfigProfile{}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "podcast")
+}
@@ -0,0 +1,21 @@
+package pipeline
+
+import (
+	"time"
+)
+
+// NoopMetricRecorder discards all metrics. It is the default recorder so the
+// pipeline can run without any metrics backend configured.
+type NoopMetricRecorder struct{}
+
+// Counter implements MetricRecorder
+func (NoopMetricRecorder) Counter(name string, value int64, tags map[string]string) {}
+
+// Gauge implements MetricRecorder
+func (NoopMetricRecorder) Gauge(name string, value float64, tags map[string]string) {}
+
+// Histogram implements MetricRecorder
+func (NoopMetricRecorder) Histogram(name string, value float64, tags map[string]string) {}
+
+// Timing implements MetricRecorder
+func (NoopMetricRecorder) Timing(name string, duration time.Duration, tags map[string]string) {}
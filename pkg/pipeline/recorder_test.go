@@ -0,0 +1,121 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// spyRecorder records every metric call for assertions
+type spyRecorder struct {
+	mu    sync.Mutex
+	calls []spyCall
+}
+
+type spyCall struct {
+	kind  string
+	name  string
+	value float64
+	tags  map[string]string
+}
+
+func (r *spyRecorder) record(kind, name string, value float64, tags map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, spyCall{kind: kind, name: name, value: value, tags: tags})
+}
+
+func (r *spyRecorder) Counter(name string, value int64, tags map[string]string) {
+	r.record("counter", name, float64(value), tags)
+}
+
+func (r *spyRecorder) Gauge(name string, value float64, tags map[string]string) {
+	r.record("gauge", name, value, tags)
+}
+
+func (r *spyRecorder) Histogram(name string, value float64, tags map[string]string) {
+	r.record("histogram", name, value, tags)
+}
+
+func (r *spyRecorder) Timing(name string, duration time.Duration, tags map[string]string) {
+	r.record("timing", name, float64(duration), tags)
+}
+
+func (r *spyRecorder) count(kind, name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, call := range r.calls {
+		if call.kind == kind && call.name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func TestMetricRecorderReceivesPipelineMetrics(t *testing.T) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	if err != nil {
+		t.Fatalf("Failed to create pipeline manager: %v", err)
+	}
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+
+	if err := manager.Start(context.Background(), "https://example.com/stream"); err != nil {
+		t.Fatalf("Failed to start pipeline: %v", err)
+	}
+
+	manager.ReportError(errors.New("read timeout"), CategoryNetwork, SeverityLow)
+
+	// Errors are handled asynchronously by the control loop
+	deadline := time.Now().Add(time.Second)
+	for spy.count("counter", "pipeline.errors.total") == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := manager.Stop(); err != nil {
+		t.Fatalf("Failed to stop pipeline: %v", err)
+	}
+
+	if got := spy.count("counter", "pipeline.starts"); got != 1 {
+		t.Errorf("Expected 1 pipeline.starts counter, got %d", got)
+	}
+	if got := spy.count("counter", "pipeline.stops"); got != 1 {
+		t.Errorf("Expected 1 pipeline.stops counter, got %d", got)
+	}
+	if got := spy.count("timing", "pipeline.uptime"); got != 1 {
+		t.Errorf("Expected 1 pipeline.uptime timing, got %d", got)
+	}
+	if got := spy.count("counter", "pipeline.errors.total"); got != 1 {
+		t.Errorf("Expected 1 pipeline.errors.total counter, got %d", got)
+	}
+	// Idle -> Initializing -> Streaming -> Stopping -> Idle
+	if got := spy.count("counter", "pipeline.state.changes"); got != 4 {
+		t.Errorf("Expected 4 state change counters, got %d", got)
+	}
+
+	spy.mu.Lock()
+	defer spy.mu.Unlock()
+	for _, call := range spy.calls {
+		if call.tags["pipeline_id"] != manager.GetPipelineID() {
+			t.Errorf("Metric %s missing pipeline_id tag", call.name)
+		}
+	}
+}
+
+func TestNoopMetricRecorderIsDefault(t *testing.T) {
+	collector := NewPipelineMetricsCollector("test-pipeline", NullLogger())
+	if _, ok := collector.getRecorder().(NoopMetricRecorder); !ok {
+		t.Error("Expected no-op recorder by default")
+	}
+
+	collector.SetRecorder(&spyRecorder{})
+	collector.SetRecorder(nil)
+	if _, ok := collector.getRecorder().(NoopMetricRecorder); !ok {
+		t.Error("Expected nil recorder to restore the no-op recorder")
+	}
+}
@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricRecoveryBudgetExhausted counts recoveries refused because the
+// sliding-window budget was spent
+const MetricRecoveryBudgetExhausted = "pipeline.recovery_budget_exhausted"
+
+// RecoveryBudget is a sliding-window counter of recoveries. Backoff spaces
+// individual attempts; the budget stops a flapping stream from recovering
+// indefinitely. It is safe for concurrent use, so the pipelines of one
+// playback session can share it.
+type RecoveryBudget struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	spent []time.Time
+}
+
+// NewRecoveryBudget creates an empty budget using the given clock
+func NewRecoveryBudget(now func() time.Time) *RecoveryBudget {
+	return &RecoveryBudget{now: now}
+}
+
+// Allow records a recovery and reports whether it fits within max
+// recoveries per window. A non-positive max or window disables the cap.
+func (b *RecoveryBudget) Allow(max int, window time.Duration) bool {
+	if max <= 0 || window <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	cutoff := now.Add(-window)
+
+	kept := b.spent[:0]
+	for _, at := range b.spent {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	b.spent = kept
+
+	if len(b.spent) >= max {
+		return false
+	}
+
+	b.spent = append(b.spent, now)
+	return true
+}
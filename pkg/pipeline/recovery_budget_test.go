@@ -0,0 +1,93 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingStrategy always recovers and counts how often it ran
+type countingStrategy struct {
+	calls int32
+}
+
+func (s *countingStrategy) CanRecover(err *PipelineError) bool { return true }
+func (s *countingStrategy) Recover(ctx context.Context, pipeline PipelineManager) error {
+	atomic.AddInt32(&s.calls, 1)
+	return nil
+}
+func (s *countingStrategy) Priority() int    { return 10 }
+func (s *countingStrategy) MaxAttempts() int { return 1 }
+
+func TestRecoveryBudget_SlidingWindow(t *testing.T) {
+	now := time.Unix(0, 0)
+	budget := NewRecoveryBudget(func() time.Time { return now })
+
+	assert.True(t, budget.Allow(2, time.Minute))
+	now = now.Add(30 * time.Second)
+	assert.True(t, budget.Allow(2, time.Minute))
+	assert.False(t, budget.Allow(2, time.Minute))
+
+	// The first recovery ages out of the window
+	now = now.Add(31 * time.Second)
+	assert.True(t, budget.Allow(2, time.Minute))
+	assert.False(t, budget.Allow(2, time.Minute))
+}
+
+func TestRecoveryBudget_Disabled(t *testing.T) {
+	budget := NewRecoveryBudget(time.Now)
+	for i := 0; i < 100; i++ {
+		assert.True(t, budget.Allow(0, time.Minute))
+	}
+}
+
+func TestManager_FailsWhenRecoveryBudgetSpent(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Recovery.BudgetMaxRecoveries = 3
+	config.Recovery.BudgetWindow = time.Hour
+
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	defer manager.Stop()
+
+	events := &spyEventRecorder{}
+	manager.SetEventRecorder(events)
+
+	strategy := &countingStrategy{}
+	manager.AddRecoveryStrategy(strategy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	for i := 1; i <= 3; i++ {
+		manager.ReportError(errors.New("stream flapped"), CategoryStream, SeverityMedium)
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&strategy.calls) == int32(i) && manager.GetState() == StateStreaming
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	manager.ReportError(errors.New("stream flapped"), CategoryStream, SeverityMedium)
+	require.Eventually(t, func() bool {
+		return manager.GetState() == StateFailed
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&strategy.calls))
+	require.Len(t, events.events, 1)
+	assert.Equal(t, "recovery_budget_exhausted", events.events[0].eventType)
+}
+
+func TestPipelineConfig_ValidateRecoveryBudget(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Recovery.BudgetMaxRecoveries = -1
+	assert.Error(t, config.Validate())
+
+	config = DefaultPipelineConfig()
+	config.Recovery.BudgetWindow = 0
+	assert.Error(t, config.Validate())
+
+	config.Recovery.BudgetMaxRecoveries = 0
+	assert.NoError(t, config.Validate())
+}
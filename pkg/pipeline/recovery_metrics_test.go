@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowStrategy takes a while on each attempt, then succeeds or fails
+type slowStrategy struct {
+	delay time.Duration
+	err   error
+}
+
+func (s *slowStrategy) Name() string                       { return "slow" }
+func (s *slowStrategy) CanRecover(err *PipelineError) bool { return true }
+func (s *slowStrategy) Recover(ctx context.Context, pipeline PipelineManager) error {
+	time.Sleep(s.delay)
+	return s.err
+}
+func (s *slowStrategy) Priority() int    { return 10 }
+func (s *slowStrategy) MaxAttempts() int { return 2 }
+
+// named returns the recorded calls of one kind and name
+func (r *spyRecorder) named(kind, name string) []spyCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []spyCall
+	for _, call := range r.calls {
+		if call.kind == kind && call.name == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// startRecoveringManager starts a pipeline recording metrics to a spy, with
+// one recovery strategy registered
+func startRecoveringManager(t *testing.T, strategy RecoveryStrategy) (*AudioPipelineManager, *spyRecorder) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+	manager.AddRecoveryStrategy(strategy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+	return manager, spy
+}
+
+func TestManager_RecordsRecoverySuccess(t *testing.T) {
+	manager, spy := startRecoveringManager(t, &slowStrategy{delay: 20 * time.Millisecond})
+
+	manager.ReportError(errors.New("connection reset by peer"), CategoryNetwork, SeverityMedium)
+
+	require.Eventually(t, func() bool {
+		return len(spy.named("counter", MetricRecoverySucceeded)) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateStreaming, manager.GetState())
+
+	durations := spy.named("histogram", MetricRecoveryDuration)
+	require.Len(t, durations, 1)
+	assert.GreaterOrEqual(t, durations[0].value, 20.0, "duration is in milliseconds")
+	assert.Less(t, durations[0].value, 1000.0)
+	assert.Equal(t, "network", durations[0].tags["category"])
+	assert.Equal(t, "slow", durations[0].tags["strategy"])
+	assert.Equal(t, "succeeded", durations[0].tags["outcome"])
+	assert.Equal(t, manager.GetPipelineID(), durations[0].tags["pipeline_id"])
+
+	succeeded := spy.named("counter", MetricRecoverySucceeded)
+	assert.Equal(t, 1.0, succeeded[0].value)
+	assert.Equal(t, "network", succeeded[0].tags["category"])
+	assert.Empty(t, spy.named("counter", MetricRecoveryFailed))
+}
+
+func TestManager_RecordsRecoveryFailure(t *testing.T) {
+	manager, spy := startRecoveringManager(t, &slowStrategy{delay: 5 * time.Millisecond, err: errors.New("still down")})
+
+	manager.ReportError(errors.New("ffmpeg exited"), CategoryProcess, SeverityHigh)
+
+	require.Eventually(t, func() bool {
+		return len(spy.named("counter", MetricRecoveryFailed)) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, StateFailed, manager.GetState())
+
+	durations := spy.named("histogram", MetricRecoveryDuration)
+	require.Len(t, durations, 1)
+	// Both attempts count towards the time spent recovering
+	assert.GreaterOrEqual(t, durations[0].value, 10.0)
+	assert.Equal(t, "failed", durations[0].tags["outcome"])
+	assert.Equal(t, "process", durations[0].tags["category"])
+	assert.Empty(t, spy.named("counter", MetricRecoverySucceeded))
+}
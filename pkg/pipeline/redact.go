@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces secret values in config dumps
+const RedactedValue = "***"
+
+// secretKeyPatterns mark keys, flags, and map entries whose values must never be emitted
+var secretKeyPatterns = []string{
+	"token", "secret", "password", "passwd", "credential", "cookie", "auth", "apikey", "api_key", "header",
+}
+
+// isSecretKey reports whether a key or flag name looks like it holds a secret
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range secretKeyPatterns {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString masks credentials embedded in a single value, such as
+// "token=abc" pairs or the password part of a URL
+func redactString(value string) string {
+	if key, _, found := strings.Cut(value, "="); found && isSecretKey(key) {
+		return key + "=" + RedactedValue
+	}
+
+	if strings.Contains(value, "://") {
+		if u, err := url.Parse(value); err == nil && u.User != nil {
+			u.User = url.User(RedactedValue)
+			return u.String()
+		}
+	}
+
+	return value
+}
+
+// redactArgs masks the value following a secret-like flag, as well as any
+// argument that embeds credentials directly
+func redactArgs(args []string) []string {
+	if args == nil {
+		return nil
+	}
+
+	redacted := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		switch {
+		case maskNext:
+			redacted[i] = RedactedValue
+			maskNext = false
+		case strings.HasPrefix(arg, "-") && isSecretKey(arg):
+			redacted[i] = arg
+			maskNext = true
+		default:
+			redacted[i] = redactString(arg)
+		}
+	}
+	return redacted
+}
+
+// redactMap masks values for secret-like keys
+func redactMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		if isSecretKey(key) {
+			redacted[key] = RedactedValue
+		} else {
+			redacted[key] = redactString(value)
+		}
+	}
+	return redacted
+}
+
+// Redacted returns a deep copy of the configuration with every secret-like
+// value masked, safe to share in logs and support tickets
+func (c PipelineConfig) Redacted() PipelineConfig {
+	redacted := c
+
+	redacted.StreamAcquisition.Strategies = append([]string(nil), c.StreamAcquisition.Strategies...)
+	redacted.StreamAcquisition.AllowedHosts = append([]string(nil), c.StreamAcquisition.AllowedHosts...)
+	redacted.StreamAcquisition.BlockedHosts = append([]string(nil), c.StreamAcquisition.BlockedHosts...)
+	redacted.StreamAcquisition.UserAgent = redactString(c.StreamAcquisition.UserAgent)
+
+	redacted.FFmpeg.BinaryPath = redactString(c.FFmpeg.BinaryPath)
+	redacted.FFmpeg.Args = redactArgs(c.FFmpeg.Args)
+	redacted.FFmpeg.ReconnectOptions = redactMap(c.FFmpeg.ReconnectOptions)
+
+	redacted.Health.Checks = append([]string(nil), c.Health.Checks...)
+	if c.Health.AlertThresholds != nil {
+		redacted.Health.AlertThresholds = make(map[string]float64, len(c.Health.AlertThresholds))
+		for key, value := range c.Health.AlertThresholds {
+			redacted.Health.AlertThresholds[key] = value
+		}
+	}
+
+	redacted.Recovery.Strategies = append([]string(nil), c.Recovery.Strategies...)
+	redacted.Logging.Output = redactString(c.Logging.Output)
+	redacted.Logging.StaticFields = copyStringMap(c.Logging.StaticFields)
+	redacted.Profiles = copyProfiles(c.Profiles)
+
+	return redacted
+}
+
+// pipelineConfigJSON has the same fields as PipelineConfig but none of its
+// methods, so marshaling it does not recurse into MarshalJSON
+type pipelineConfigJSON PipelineConfig
+
+// MarshalJSON always encodes the redacted configuration
+func (c PipelineConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pipelineConfigJSON(c.Redacted()))
+}
+
+// String returns the redacted configuration as indented JSON
+func (c PipelineConfig) String() string {
+	data, err := json.MarshalIndent(pipelineConfigJSON(c.Redacted()), "", "  ")
+	if err != nil {
+		return "PipelineConfig{<unprintable>}"
+	}
+	return string(data)
+}
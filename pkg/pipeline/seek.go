@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrSeekUnsupported is returned by Seek for live streams, which have no
+// timeline to jump along
+var ErrSeekUnsupported = errors.New("stream does not support seeking")
+
+// ResettableEncoder is implemented by encoders that can clear their state,
+// so a seek doesn't carry audio from before the jump into the frames after it
+type ResettableEncoder interface {
+	Reset() error
+}
+
+// SetStreamProcessor sets the component that runs FFmpeg over the stream.
+// Seek restarts it at the new position.
+func (apm *AudioPipelineManager) SetStreamProcessor(processor StreamProcessor) {
+	apm.stateMutex.Lock()
+	defer apm.stateMutex.Unlock()
+	apm.streamProcessor = processor
+}
+
+// FFmpegArgs returns the FFmpeg arguments reading the stream: inputArgs,
+// such as reconnect options, then -ss for StartOffset when there is one, the
+// input URL and -af for AudioFilters. A StreamProcessor adds its output
+// arguments after them, so a restarted stream begins where StreamInfo says.
+func (s *StreamInfo) FFmpegArgs(inputArgs []string) []string {
+	args := append([]string(nil), inputArgs...)
+	if s.StartOffset > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", s.StartOffset.Seconds()))
+	}
+	args = append(args, "-i", s.URL)
+	if len(s.AudioFilters) > 0 {
+		args = append(args, "-af", strings.Join(s.AudioFilters, ","))
+	}
+	return args
+}
+
+// Seek jumps playback to position by restarting the stream processor with
+// the offset in its StreamInfo, and resetting the encoder. The
+// pipeline stays in the Streaming state throughout; it holds the state lock
+// while seeking, so a concurrent Stop waits for the seek and then stops the
+// restarted stream. Live streams return ErrSeekUnsupported, and a stream
+// that can't be restarted fails the pipeline.
+func (apm *AudioPipelineManager) Seek(ctx context.Context, position time.Duration) error {
+	apm.stateMutex.Lock()
+	defer apm.stateMutex.Unlock()
+
+	if apm.state != StateStreaming {
+		return fmt.Errorf("cannot seek pipeline in state: %s", apm.state)
+	}
+	if kind := ResolveSourceKind(apm.streamURL); kind == SourceLive {
+		return fmt.Errorf("%w: %s streams have no timeline", ErrSeekUnsupported, kind)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	from := apm.position.at()
+	apm.logger.Info("Seeking audio pipeline",
+		Duration("from", from),
+		Duration("to", position),
+	)
+
+	if apm.streamProcessor != nil {
+		if apm.streamProcessor.IsRunning() {
+			if err := apm.streamProcessor.Stop(); err != nil {
+				return fmt.Errorf("failed to stop stream for seek: %w", err)
+			}
+		}
+
+		// The restarted stream lives as long as the pipeline, not the caller
+		info := &StreamInfo{
+			URL:          apm.streamURL,
+			StartOffset:  position,
+			AudioFilters: apm.streamConfigLocked().Audio.Filters(),
+		}
+		if err := apm.streamProcessor.Start(apm.ctx, info); err != nil {
+			apm.position.hold()
+			apm.changeState(StateFailed, fmt.Sprintf("seek failed: %v", err))
+			return fmt.Errorf("failed to restart stream at %s: %w", position, err)
+		}
+	}
+
+	if encoder, ok := apm.audioEncoder.(ResettableEncoder); ok {
+		if err := encoder.Reset(); err != nil {
+			apm.logger.Warn("Failed to reset encoder after seek", Error(err))
+		}
+	}
+
+	apm.position.seek(position)
+	apm.metrics.RecordPipelineCounter("pipeline.seeks", 1, nil)
+	return nil
+}
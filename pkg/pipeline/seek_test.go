@@ -0,0 +1,195 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcessor records the offsets and filters it is started with. Starts
+// block while gate is set, and fail with startErr.
+type fakeProcessor struct {
+	mu       sync.Mutex
+	running  bool
+	offsets  []time.Duration
+	filters  []string
+	gate     chan struct{}
+	started  chan struct{}
+	startErr error
+}
+
+func (p *fakeProcessor) Start(ctx context.Context, info *StreamInfo) error {
+	if p.gate != nil {
+		p.started <- struct{}{}
+		<-p.gate
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.startErr != nil {
+		return p.startErr
+	}
+	p.running = true
+	p.offsets = append(p.offsets, info.StartOffset)
+	p.filters = info.AudioFilters
+	return nil
+}
+
+func (p *fakeProcessor) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running = false
+	return nil
+}
+
+func (p *fakeProcessor) IsRunning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running
+}
+
+func (p *fakeProcessor) GetProcessMetrics() map[string]interface{} { return nil }
+
+// resettableEncoder counts the resets a seek makes
+type resettableEncoder struct {
+	spyEncoder
+	resets int
+}
+
+func (e *resettableEncoder) Reset() error {
+	e.resets++
+	return nil
+}
+
+func newSeekManager(t *testing.T, streamURL string) (*AudioPipelineManager, *fakeProcessor) {
+	t.Helper()
+	manager, err := NewAudioPipelineManager(DefaultPipelineConfig(), NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	processor := &fakeProcessor{}
+	manager.SetStreamProcessor(processor)
+	require.NoError(t, manager.Start(context.Background(), streamURL))
+	return manager, processor
+}
+
+func TestSeek_RestartsStreamAtOffset(t *testing.T) {
+	manager, processor := newSeekManager(t, "https://rr1.googlevideo.com/videoplayback?id=1")
+	encoder := &resettableEncoder{}
+	require.NoError(t, manager.SetAudioEncoder(encoder))
+
+	require.NoError(t, manager.Seek(context.Background(), 90*time.Second))
+
+	assert.Equal(t, StateStreaming, manager.GetState())
+	assert.Equal(t, []time.Duration{90 * time.Second}, processor.offsets)
+	assert.True(t, processor.IsRunning())
+	assert.Equal(t, 1, encoder.resets)
+
+	position := manager.GetMetrics().Position
+	assert.GreaterOrEqual(t, position, 90*time.Second)
+	assert.Less(t, position, 91*time.Second)
+
+	// Negative offsets start from the beginning
+	require.NoError(t, manager.Seek(context.Background(), -time.Second))
+	assert.Equal(t, time.Duration(0), processor.offsets[1])
+}
+
+func TestSeek_PositionHeldWhilePaused(t *testing.T) {
+	manager, _ := newSeekManager(t, "/music/track.mp3")
+	manager.SetDiscordStreamer(&recordingSink{})
+	require.NoError(t, manager.Seek(context.Background(), time.Minute))
+
+	require.NoError(t, manager.Pause())
+	held := manager.Position()
+	assert.Error(t, manager.SendFrame([]byte{1}), "frames aren't sent while paused")
+	assert.Equal(t, held, manager.Position())
+
+	// Seeking is only possible while streaming
+	assert.Error(t, manager.Seek(context.Background(), 0))
+
+	require.NoError(t, manager.Resume())
+	require.NoError(t, manager.SendFrame([]byte{1}))
+	assert.Equal(t, held+20*time.Millisecond, manager.Position())
+}
+
+func TestSeek_LiveStreamUnsupported(t *testing.T) {
+	manager, processor := newSeekManager(t, "https://radio.example/live")
+
+	err := manager.Seek(context.Background(), 30*time.Second)
+	assert.ErrorIs(t, err, ErrSeekUnsupported)
+	assert.Empty(t, processor.offsets)
+	assert.Equal(t, StateStreaming, manager.GetState())
+}
+
+func TestSeek_CancelledContextLeavesStreamAlone(t *testing.T) {
+	manager, processor := newSeekManager(t, "/music/track.mp3")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, manager.Seek(ctx, time.Minute), context.Canceled)
+	assert.Empty(t, processor.offsets)
+}
+
+func TestSeek_FailedRestartFailsPipeline(t *testing.T) {
+	manager, processor := newSeekManager(t, "/music/track.mp3")
+	processor.startErr = errors.New("ffmpeg exited")
+
+	err := manager.Seek(context.Background(), time.Minute)
+	assert.ErrorContains(t, err, "ffmpeg exited")
+	assert.Equal(t, StateFailed, manager.GetState())
+}
+
+func TestSeek_ConcurrentStopWaitsForSeek(t *testing.T) {
+	manager, processor := newSeekManager(t, "/music/track.mp3")
+	processor.gate = make(chan struct{})
+	processor.started = make(chan struct{})
+
+	seekDone := make(chan error)
+	go func() { seekDone <- manager.Seek(context.Background(), time.Minute) }()
+	<-processor.started
+
+	stopDone := make(chan error)
+	go func() { stopDone <- manager.Stop() }()
+
+	// Stop can't run until the seek has finished restarting the stream
+	select {
+	case <-stopDone:
+		t.Fatal("Stop finished while the seek was restarting the stream")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(processor.gate)
+	require.NoError(t, <-seekDone)
+	require.NoError(t, <-stopDone)
+
+	assert.Equal(t, StateIdle, manager.GetState())
+	assert.False(t, processor.IsRunning(), "Stop should stop the restarted stream")
+}
+
+func TestStreamInfo_FFmpegArgs(t *testing.T) {
+	info := &StreamInfo{URL: "/music/track.mp3"}
+	assert.Equal(t, []string{"-re", "-i", "/music/track.mp3"}, info.FFmpegArgs([]string{"-re"}))
+
+	info.StartOffset = 90500 * time.Millisecond
+	info.AudioFilters = []string{"loudnorm=I=-16.0:TP=-1.5:LRA=11", "volume=2.00dB"}
+	assert.Equal(t, []string{
+		"-re",
+		"-ss", "90.500",
+		"-i", "/music/track.mp3",
+		"-af", "loudnorm=I=-16.0:TP=-1.5:LRA=11,volume=2.00dB",
+	}, info.FFmpegArgs([]string{"-re"}))
+}
+
+func TestSeek_PositionResetOnStop(t *testing.T) {
+	manager, _ := newSeekManager(t, "/music/track.mp3")
+	require.NoError(t, manager.Seek(context.Background(), time.Minute))
+	assert.GreaterOrEqual(t, manager.Position(), time.Minute)
+
+	require.NoError(t, manager.Stop())
+	assert.Zero(t, manager.Position())
+}
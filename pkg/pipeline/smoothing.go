@@ -0,0 +1,133 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SmoothedGaugeSuffix is appended to a gauge's name for its smoothed series
+const SmoothedGaugeSuffix = ".ema"
+
+// SmoothingRecorder exports an exponential moving average of chosen gauges
+// alongside the raw values, so jittery gauges such as frames behind give
+// steady dashboards and alerts. Each smoothed gauge is forwarded twice: raw
+// under its own name and smoothed under the name plus SmoothedGaugeSuffix.
+// Every other metric passes through untouched.
+type SmoothingRecorder struct {
+	next    MetricRecorder
+	factors map[string]float64
+
+	mu      sync.Mutex
+	average map[string]float64 // by gauge name and tags
+}
+
+// NewSmoothingRecorder creates a recorder smoothing the gauges in factors,
+// keyed by name, with their smoothing factor. A factor near 0 smooths
+// heavily; 1 follows the raw value. Metrics are forwarded to next.
+func NewSmoothingRecorder(factors map[string]float64, next MetricRecorder) *SmoothingRecorder {
+	if next == nil {
+		next = NoopMetricRecorder{}
+	}
+
+	copied := make(map[string]float64, len(factors))
+	for name, factor := range factors {
+		copied[name] = factor
+	}
+
+	return &SmoothingRecorder{
+		next:    next,
+		factors: copied,
+		average: make(map[string]float64),
+	}
+}
+
+// Counter implements MetricRecorder
+func (r *SmoothingRecorder) Counter(name string, value int64, tags map[string]string) {
+	r.next.Counter(name, value, tags)
+}
+
+// Gauge implements MetricRecorder. The first value of a series seeds its
+// average.
+func (r *SmoothingRecorder) Gauge(name string, value float64, tags map[string]string) {
+	r.next.Gauge(name, value, tags)
+
+	factor, ok := r.factors[name]
+	if !ok {
+		return
+	}
+
+	key := seriesKey(name, tags)
+	r.mu.Lock()
+	average, seen := r.average[key]
+	if seen {
+		average += factor * (value - average)
+	} else {
+		average = value
+	}
+	r.average[key] = average
+	r.mu.Unlock()
+
+	r.next.Gauge(name+SmoothedGaugeSuffix, average, tags)
+}
+
+// Histogram implements MetricRecorder
+func (r *SmoothingRecorder) Histogram(name string, value float64, tags map[string]string) {
+	r.next.Histogram(name, value, tags)
+}
+
+// Timing implements MetricRecorder
+func (r *SmoothingRecorder) Timing(name string, duration time.Duration, tags map[string]string) {
+	r.next.Timing(name, duration, tags)
+}
+
+// seriesKey identifies a metric series by its name and tags, whatever order
+// the tags were added in
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	for _, k := range keys {
+		fmt.Fprintf(&b, ",%s=%s", k, tags[k])
+	}
+	return b.String()
+}
+
+// parseGaugeSmoothing parses comma-separated name=factor pairs such as
+// "pipeline.frames_behind=0.2". Malformed pairs are skipped.
+func parseGaugeSmoothing(raw string) map[string]float64 {
+	factors := make(map[string]float64)
+	for _, pair := range strings.Split(raw, ",") {
+		name, value, found := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			continue
+		}
+		factor, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			continue
+		}
+		factors[name] = factor
+	}
+	return factors
+}
+
+// validateGaugeSmoothing reports smoothing factors outside (0, 1]
+func (c MetricsConfig) validateGaugeSmoothing() []string {
+	var errors []string
+	for name, factor := range c.GaugeSmoothing {
+		if factor <= 0 || factor > 1 {
+			errors = append(errors, fmt.Sprintf("metrics gauge_smoothing factor for %q must be > 0 and <= 1", name))
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// variance returns the population variance of values
+func variance(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return sum / float64(len(values))
+}
+
+func TestSmoothingRecorder_SmoothsNoisyGauge(t *testing.T) {
+	spy := &spyRecorder{}
+	recorder := NewSmoothingRecorder(map[string]float64{MetricFramesBehind: 0.2}, spy)
+
+	// Frames behind jittering between 5 and 15 around a level of 10
+	noise := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		recorder.Gauge(MetricFramesBehind, 5+noise.Float64()*10, nil)
+	}
+
+	raw := spy.named("gauge", MetricFramesBehind)
+	smoothed := spy.named("gauge", MetricFramesBehind+SmoothedGaugeSuffix)
+	require.Len(t, raw, 500, "raw values are still exported")
+	require.Len(t, smoothed, 500)
+
+	var rawValues, smoothedValues []float64
+	for i := range raw {
+		rawValues = append(rawValues, raw[i].value)
+		smoothedValues = append(smoothedValues, smoothed[i].value)
+		assert.GreaterOrEqual(t, smoothed[i].value, 5.0)
+		assert.LessOrEqual(t, smoothed[i].value, 15.0)
+	}
+	assert.Equal(t, rawValues[0], smoothedValues[0], "the first value seeds the average")
+
+	// Once warmed up the average stays near the level and varies far less
+	settled := smoothedValues[50:]
+	for _, v := range settled {
+		assert.InDelta(t, 10, v, 3)
+	}
+	assert.Less(t, variance(settled), variance(rawValues[50:])/4)
+}
+
+func TestSmoothingRecorder_SeriesAndPassthrough(t *testing.T) {
+	spy := &spyRecorder{}
+	recorder := NewSmoothingRecorder(map[string]float64{"pipeline.cpu": 0.5}, spy)
+
+	recorder.Gauge("pipeline.cpu", 10, map[string]string{"pipeline_id": "a"})
+	recorder.Gauge("pipeline.cpu", 50, map[string]string{"pipeline_id": "b"})
+	recorder.Gauge("pipeline.cpu", 20, map[string]string{"pipeline_id": "a"})
+	recorder.Gauge("pipeline.memory", 7, nil)
+	recorder.Counter("pipeline.starts", 1, nil)
+
+	smoothed := spy.named("gauge", "pipeline.cpu"+SmoothedGaugeSuffix)
+	require.Len(t, smoothed, 3)
+	assert.Equal(t, 10.0, smoothed[0].value)
+	assert.Equal(t, 50.0, smoothed[1].value, "each tag set is its own series")
+	assert.Equal(t, 15.0, smoothed[2].value)
+	assert.Equal(t, "a", smoothed[2].tags["pipeline_id"])
+
+	assert.Equal(t, 1, spy.count("gauge", "pipeline.memory"))
+	assert.Equal(t, 0, spy.count("gauge", "pipeline.memory"+SmoothedGaugeSuffix))
+	assert.Equal(t, 1, spy.count("counter", "pipeline.starts"))
+}
+
+func TestSmoothingRecorder_Config(t *testing.T) {
+	t.Setenv("PIPELINE_METRICS_GAUGE_SMOOTHING", "pipeline.frames_behind=0.2, bad, pipeline.cpu=x")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.Equal(t, map[string]float64{MetricFramesBehind: 0.2}, config.Metrics.GaugeSmoothing)
+	assert.NoError(t, config.Validate())
+
+	for _, factor := range []float64{0, -0.1, 1.5} {
+		config.Metrics.GaugeSmoothing = map[string]float64{MetricFramesBehind: factor}
+		assert.Error(t, config.Validate(), "factor %v", factor)
+	}
+}
+
+func TestSmoothingRecorder_ManagerWrapsRecorder(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Metrics.GaugeSmoothing = map[string]float64{MetricFramesBehind: 0.5}
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+	manager.metrics.RecordFramesBehind(4)
+	manager.metrics.RecordFramesBehind(8)
+
+	smoothed := spy.named("gauge", MetricFramesBehind+SmoothedGaugeSuffix)
+	require.Len(t, smoothed, 2)
+	assert.Equal(t, 6.0, smoothed[1].value)
+	assert.Equal(t, 2, spy.count("gauge", MetricFramesBehind))
+}
@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reservedStaticFields are tags the pipeline sets itself, so static fields
+// can't take them
+var reservedStaticFields = map[string]bool{
+	"pipeline_id": true,
+	ConfigHashTag: true,
+	"component":   true,
+}
+
+// parseStaticFields parses comma-separated key=value pairs such as
+// "instance=bot-1,env=prod". A malformed pair is kept with an empty name or
+// value so validation rejects it rather than it being dropped unnoticed.
+func parseStaticFields(raw string) map[string]string {
+	fields := make(map[string]string)
+	for _, pair := range strings.Split(raw, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return fields
+}
+
+// validateStaticFields reports empty, valueless or reserved static fields
+func (c LoggingConfig) validateStaticFields() []string {
+	var errors []string
+	for key, value := range c.StaticFields {
+		switch {
+		case strings.TrimSpace(key) == "":
+			errors = append(errors, "logging static field names must not be empty")
+		case reservedStaticFields[key]:
+			errors = append(errors, fmt.Sprintf("logging static field %q is reserved", key))
+		case strings.TrimSpace(value) == "":
+			errors = append(errors, fmt.Sprintf("logging static field %q must be set as key=value", key))
+		}
+	}
+	sort.Strings(errors)
+	return errors
+}
+
+// StaticTagRecorder adds static tags, such as the instance name, to every
+// metric before forwarding it. Tags passed with a metric take precedence.
+type StaticTagRecorder struct {
+	next MetricRecorder
+	tags map[string]string
+}
+
+// NewStaticTagRecorder creates a recorder adding tags to the metrics it
+// forwards to next
+func NewStaticTagRecorder(tags map[string]string, next MetricRecorder) *StaticTagRecorder {
+	if next == nil {
+		next = NoopMetricRecorder{}
+	}
+	return &StaticTagRecorder{next: next, tags: copyStringMap(tags)}
+}
+
+// Counter implements MetricRecorder
+func (r *StaticTagRecorder) Counter(name string, value int64, tags map[string]string) {
+	r.next.Counter(name, value, r.merge(tags))
+}
+
+// Gauge implements MetricRecorder
+func (r *StaticTagRecorder) Gauge(name string, value float64, tags map[string]string) {
+	r.next.Gauge(name, value, r.merge(tags))
+}
+
+// Histogram implements MetricRecorder
+func (r *StaticTagRecorder) Histogram(name string, value float64, tags map[string]string) {
+	r.next.Histogram(name, value, r.merge(tags))
+}
+
+// Timing implements MetricRecorder
+func (r *StaticTagRecorder) Timing(name string, duration time.Duration, tags map[string]string) {
+	r.next.Timing(name, duration, r.merge(tags))
+}
+
+// merge returns the static tags overlaid with tags
+func (r *StaticTagRecorder) merge(tags map[string]string) map[string]string {
+	if len(r.tags) == 0 {
+		return tags
+	}
+
+	merged := make(map[string]string, len(r.tags)+len(tags))
+	for k, v := range r.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
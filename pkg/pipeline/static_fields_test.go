@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStructuredLogger_StaticFields(t *testing.T) {
+	logger := NewStructuredLogger(LoggingConfig{
+		Level:        "info",
+		Format:       "json",
+		StaticFields: map[string]string{"instance": "bot-1", "env": "prod", "region": "eu"},
+	})
+	var buf bytes.Buffer
+	logger.output = &buf
+
+	logger.With(String("component", "test")).Info("hello", String("track", "one"))
+
+	var entry LogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "bot-1", entry.Fields["instance"])
+	assert.Equal(t, "prod", entry.Fields["env"])
+	assert.Equal(t, "eu", entry.Fields["region"])
+	assert.Equal(t, "one", entry.Fields["track"])
+}
+
+func TestManager_TagsMetricsWithStaticFields(t *testing.T) {
+	config := DefaultPipelineConfig()
+	config.Logging.StaticFields = map[string]string{"instance": "bot-1"}
+	manager, err := NewAudioPipelineManager(config, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Stop() })
+
+	spy := &spyRecorder{}
+	manager.SetMetricRecorder(spy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	starts := spy.named("counter", "pipeline.starts")
+	require.Len(t, starts, 1)
+	assert.Equal(t, "bot-1", starts[0].tags["instance"])
+}
+
+func TestStaticFieldsFromEnvironment(t *testing.T) {
+	t.Setenv("PIPELINE_LOG_STATIC_FIELDS", "instance=bot-1, env = prod,")
+
+	config := DefaultPipelineConfig()
+	config.LoadFromEnvironment()
+	assert.Equal(t, map[string]string{"instance": "bot-1", "env": "prod"}, config.Logging.StaticFields)
+	require.NoError(t, config.Validate())
+
+	config.Logging.StaticFields["pipeline_id"] = "spoofed"
+	assert.Error(t, config.Validate())
+}
+
+func TestStaticFieldsFromEnvironment_RejectsMalformedPairs(t *testing.T) {
+	for _, raw := range []string{"instance=bot-1,broken", "=empty", "instance="} {
+		t.Run(raw, func(t *testing.T) {
+			t.Setenv("PIPELINE_LOG_STATIC_FIELDS", raw)
+
+			config := DefaultPipelineConfig()
+			config.LoadFromEnvironment()
+			assert.Error(t, config.Validate())
+		})
+	}
+}
+
+func TestStaticTagRecorder(t *testing.T) {
+	spy := &spyRecorder{}
+	recorder := NewStaticTagRecorder(map[string]string{"instance": "bot-1", "env": "prod"}, spy)
+
+	recorder.Gauge("queue_depth", 3, map[string]string{"guild_id": "g1", "env": "staging"})
+	recorder.Counter("underruns", 1, nil)
+
+	gauges := spy.named("gauge", "queue_depth")
+	require.Len(t, gauges, 1)
+	assert.Equal(t, map[string]string{"instance": "bot-1", "env": "staging", "guild_id": "g1"}, gauges[0].tags)
+
+	counters := spy.named("counter", "underruns")
+	require.Len(t, counters, 1)
+	assert.Equal(t, map[string]string{"instance": "bot-1", "env": "prod"}, counters[0].tags)
+}
@@ -0,0 +1,252 @@
+package pipeline
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultStatsDMaxPacketSize keeps packets under the 1500 byte Ethernet MTU
+// once IP and UDP headers are added
+const DefaultStatsDMaxPacketSize = 1432
+
+// StatsDConfig configures a StatsDRecorder
+type StatsDConfig struct {
+	Address       string            `json:"address"`         // host:port of the StatsD/DogStatsD agent
+	Prefix        string            `json:"prefix"`          // prepended to every metric name, e.g. "hktm"
+	Tags          map[string]string `json:"tags"`            // sent with every metric
+	FlushInterval time.Duration     `json:"flush_interval"`  // longest a metric waits in the buffer
+	MaxPacketSize int               `json:"max_packet_size"` // bytes per UDP packet
+}
+
+// DefaultStatsDConfig returns a config for a local agent on the standard port
+func DefaultStatsDConfig() StatsDConfig {
+	return StatsDConfig{
+		Address:       "127.0.0.1:8125",
+		FlushInterval: time.Second,
+		MaxPacketSize: DefaultStatsDMaxPacketSize,
+	}
+}
+
+// StatsDRecorder emits metrics over UDP in DogStatsD format. Lines are
+// buffered and sent newline-separated, several to a packet, when the packet
+// is full or the flush interval passes. Send failures are not reported to
+// the caller; the metrics in the lost packet are added to Dropped instead.
+type StatsDRecorder struct {
+	conn          net.Conn
+	prefix        string
+	tags          map[string]string
+	maxPacketSize int
+	logger        Logger
+
+	mu      sync.Mutex
+	buffer  []byte
+	pending int // metrics in buffer
+
+	dropped int64
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	closed   sync.Once
+}
+
+// NewStatsDRecorder creates a recorder sending to config.Address and starts
+// its flush loop. Close flushes what is buffered and stops it.
+func NewStatsDRecorder(config StatsDConfig, logger Logger) (*StatsDRecorder, error) {
+	if config.Address == "" {
+		return nil, errors.New("statsd address cannot be empty")
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.MaxPacketSize <= 0 {
+		config.MaxPacketSize = DefaultStatsDMaxPacketSize
+	}
+	if logger == nil {
+		logger = NullLogger()
+	}
+
+	conn, err := net.Dial("udp", config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open statsd connection: %w", err)
+	}
+
+	r := &StatsDRecorder{
+		conn:          conn,
+		prefix:        strings.TrimSuffix(config.Prefix, "."),
+		tags:          config.Tags,
+		maxPacketSize: config.MaxPacketSize,
+		logger:        logger.With(String("component", "statsd_recorder")),
+		stopChan:      make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.flushLoop(config.FlushInterval)
+
+	return r, nil
+}
+
+// Counter implements MetricRecorder
+func (r *StatsDRecorder) Counter(name string, value int64, tags map[string]string) {
+	r.emit(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Gauge implements MetricRecorder
+func (r *StatsDRecorder) Gauge(name string, value float64, tags map[string]string) {
+	r.emit(name, formatStatsDValue(value), "g", tags)
+}
+
+// Histogram implements MetricRecorder
+func (r *StatsDRecorder) Histogram(name string, value float64, tags map[string]string) {
+	r.emit(name, formatStatsDValue(value), "h", tags)
+}
+
+// Timing implements MetricRecorder. Durations are sent in milliseconds.
+func (r *StatsDRecorder) Timing(name string, duration time.Duration, tags map[string]string) {
+	r.emit(name, formatStatsDValue(float64(duration)/float64(time.Millisecond)), "ms", tags)
+}
+
+// Dropped returns the number of metrics lost to failed sends
+func (r *StatsDRecorder) Dropped() int64 {
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// Flush sends any buffered metrics now
+func (r *StatsDRecorder) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+}
+
+// Close flushes buffered metrics and closes the connection
+func (r *StatsDRecorder) Close() error {
+	var err error
+	r.closed.Do(func() {
+		close(r.stopChan)
+		r.wg.Wait()
+		r.Flush()
+		err = r.conn.Close()
+	})
+	return err
+}
+
+// flushLoop sends the buffer every interval so quiet periods still deliver
+func (r *StatsDRecorder) flushLoop(interval time.Duration) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.Flush()
+		case <-r.stopChan:
+			return
+		}
+	}
+}
+
+// emit formats a metric line and adds it to the buffer, sending the buffer
+// first if the line would not fit in the current packet
+func (r *StatsDRecorder) emit(name, value, metricType string, tags map[string]string) {
+	line := r.formatLine(name, value, metricType, tags)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.buffer) > 0 && len(r.buffer)+1+len(line) > r.maxPacketSize {
+		r.flushLocked()
+	}
+	if len(r.buffer) > 0 {
+		r.buffer = append(r.buffer, '\n')
+	}
+	r.buffer = append(r.buffer, line...)
+	r.pending++
+
+	if len(r.buffer) >= r.maxPacketSize {
+		r.flushLocked()
+	}
+}
+
+// flushLocked sends the buffer as one packet; callers hold r.mu
+func (r *StatsDRecorder) flushLocked() {
+	if len(r.buffer) == 0 {
+		return
+	}
+
+	if _, err := r.conn.Write(r.buffer); err != nil {
+		atomic.AddInt64(&r.dropped, int64(r.pending))
+		r.logger.Debug("Dropped statsd packet", Int("metrics", r.pending), Error(err))
+	}
+
+	r.buffer = r.buffer[:0]
+	r.pending = 0
+}
+
+// formatLine renders one DogStatsD line: prefix.name:value|type|#tag:value,...
+func (r *StatsDRecorder) formatLine(name, value, metricType string, tags map[string]string) string {
+	var b strings.Builder
+	if r.prefix != "" {
+		b.WriteString(sanitizeStatsD(r.prefix))
+		b.WriteByte('.')
+	}
+	b.WriteString(sanitizeStatsD(name))
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(metricType)
+
+	merged := make(map[string]string, len(r.tags)+len(tags))
+	for k, v := range r.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	if len(merged) == 0 {
+		return b.String()
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.WriteString("|#")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(sanitizeStatsD(k))
+		if v := merged[k]; v != "" {
+			b.WriteByte(':')
+			b.WriteString(statsDTagValueReplacer.Replace(v))
+		}
+	}
+	return b.String()
+}
+
+// formatStatsDValue renders a float without exponent or trailing zeros
+func formatStatsDValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Replacers for characters that delimit fields in the wire format. Tag
+// values may contain colons; only the first one in a tag separates the key.
+var (
+	statsDReplacer         = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_")
+	statsDTagValueReplacer = strings.NewReplacer("|", "_", ",", "_", "\n", "_")
+)
+
+// sanitizeStatsD makes a metric name or tag key safe to send
+func sanitizeStatsD(s string) string {
+	return statsDReplacer.Replace(s)
+}
@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listenStatsD starts a UDP listener standing in for the StatsD agent
+func listenStatsD(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readPacket reads one packet from the listener
+func readPacket(t *testing.T, conn *net.UDPConn) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 65536)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	return string(buf[:n])
+}
+
+func newTestStatsDRecorder(t *testing.T, listener *net.UDPConn, mutate func(*StatsDConfig)) *StatsDRecorder {
+	t.Helper()
+	config := DefaultStatsDConfig()
+	config.Address = listener.LocalAddr().String()
+	config.FlushInterval = time.Hour // flushed explicitly by the tests
+	if mutate != nil {
+		mutate(&config)
+	}
+
+	recorder, err := NewStatsDRecorder(config, NullLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { recorder.Close() })
+	return recorder
+}
+
+func TestStatsDRecorder_WireFormat(t *testing.T) {
+	listener := listenStatsD(t)
+	recorder := newTestStatsDRecorder(t, listener, func(c *StatsDConfig) {
+		c.Prefix = "hktm."
+		c.Tags = map[string]string{"env": "test"}
+	})
+
+	tags := map[string]string{"guild": "123", "source": "https://example.com"}
+	cases := []struct {
+		record func()
+		want   string
+	}{
+		{func() { recorder.Counter("frames_sent", 42, tags) }, "hktm.frames_sent:42|c|#env:test,guild:123,source:https://example.com"},
+		{func() { recorder.Gauge("buffer_health", 0.75, tags) }, "hktm.buffer_health:0.75|g|#env:test,guild:123,source:https://example.com"},
+		{func() { recorder.Histogram("frame_size", 160, nil) }, "hktm.frame_size:160|h|#env:test"},
+		{func() { recorder.Timing("send_latency", 1500*time.Microsecond, nil) }, "hktm.send_latency:1.5|ms|#env:test"},
+	}
+
+	for _, tc := range cases {
+		tc.record()
+		recorder.Flush()
+		assert.Equal(t, tc.want, readPacket(t, listener))
+	}
+	assert.Zero(t, recorder.Dropped())
+}
+
+func TestStatsDRecorder_NoTagsAndSanitizing(t *testing.T) {
+	listener := listenStatsD(t)
+	recorder := newTestStatsDRecorder(t, listener, nil)
+
+	recorder.Counter("bad:name|x", 1, map[string]string{"a,b": "c|d", "flag": ""})
+	recorder.Gauge("plain", 3, nil)
+	recorder.Flush()
+
+	assert.Equal(t, "bad_name_x:1|c|#a_b:c_d,flag\nplain:3|g", readPacket(t, listener))
+}
+
+func TestStatsDRecorder_BatchesIntoPackets(t *testing.T) {
+	listener := listenStatsD(t)
+	recorder := newTestStatsDRecorder(t, listener, func(c *StatsDConfig) {
+		c.MaxPacketSize = 64
+	})
+
+	// Each line is 18 bytes, so three fit with separators and the fourth
+	// starts a new packet
+	for i := 0; i < 4; i++ {
+		recorder.Counter("requests_total", 1, nil)
+	}
+	recorder.Flush()
+
+	first := readPacket(t, listener)
+	assert.Equal(t, 3, len(strings.Split(first, "\n")))
+	assert.LessOrEqual(t, len(first), 64)
+	assert.Equal(t, "requests_total:1|c", readPacket(t, listener))
+}
+
+func TestStatsDRecorder_FlushInterval(t *testing.T) {
+	listener := listenStatsD(t)
+	recorder := newTestStatsDRecorder(t, listener, func(c *StatsDConfig) {
+		c.FlushInterval = 20 * time.Millisecond
+	})
+
+	recorder.Gauge("queue_depth", 2, nil)
+	assert.Equal(t, "queue_depth:2|g", readPacket(t, listener))
+}
+
+func TestStatsDRecorder_CountsDroppedMetrics(t *testing.T) {
+	listener := listenStatsD(t)
+	recorder := newTestStatsDRecorder(t, listener, nil)
+
+	// Writes on a closed socket fail; the recorder must not panic or block
+	require.NoError(t, recorder.conn.Close())
+	recorder.Counter("a", 1, nil)
+	recorder.Counter("b", 1, nil)
+	recorder.Flush()
+
+	assert.Equal(t, int64(2), recorder.Dropped())
+}
+
+func TestNewStatsDRecorder_RequiresAddress(t *testing.T) {
+	_, err := NewStatsDRecorder(StatsDConfig{}, nil)
+	assert.Error(t, err)
+}
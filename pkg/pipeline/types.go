@@ -109,6 +109,11 @@ func (pe *PipelineError) Error() string {
 	return pe.Err.Error()
 }
 
+// Unwrap returns the underlying error so errors.Is and errors.As see through it
+func (pe *PipelineError) Unwrap() error {
+	return pe.Err
+}
+
 // NewPipelineError creates a new classified pipeline error
 func NewPipelineError(err error, category ErrorCategory, severity ErrorSeverity) *PipelineError {
 	return &PipelineError{
@@ -152,6 +157,8 @@ type StreamInfo struct {
 	ExpiresAt         time.Time
 	Validated         bool
 	ValidationErrors  []error
+	StartOffset       time.Duration // Where processing starts; FFmpegArgs passes it as -ss
+	AudioFilters      []string      // FFmpeg filters to run over the audio; FFmpegArgs joins them into -af
 }
 
 // QualityMetrics represents audio quality measurements
@@ -160,6 +167,9 @@ type QualityMetrics struct {
 	SampleRate        int
 	Channels          int
 	Complexity        int
+	Application       string // Opus application mode the encoder runs in
+	FEC               bool   // Opus in-band forward error correction is on
+	DTX               bool   // Opus discontinuous transmission is on
 	PacketLoss        float64
 	Jitter            time.Duration
 	LastUpdated       time.Time
@@ -198,6 +208,11 @@ type PipelineMetrics struct {
 	MemoryUsage       int64
 	NetworkBandwidth  int64
 	
+	// Playback position within the stream, and the audio sent to get there
+	Position          time.Duration
+	FramesSent        int64
+	BytesSent         int64
+	
 	// Timestamps
 	LastUpdated       time.Time
 	
@@ -0,0 +1,196 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Discord voice gateway close codes
+const (
+	VoiceCloseSessionNoLongerValid = 4006
+	VoiceCloseSessionTimeout       = 4009
+	VoiceCloseDisconnected         = 4014
+	VoiceCloseServerCrashed        = 4015
+)
+
+// Recovery strategy names
+const (
+	StrategyVoiceReconnect = "voice-reconnect"
+	StrategyQuickRetry     = "quick-retry"
+)
+
+// voiceCloseStrategies maps Discord voice close codes to the strategy that
+// handles them. Codes not listed fall through to generic recovery.
+var voiceCloseStrategies = map[int]string{
+	VoiceCloseSessionNoLongerValid: StrategyVoiceReconnect,
+	VoiceCloseSessionTimeout:       StrategyVoiceReconnect,
+	VoiceCloseServerCrashed:        StrategyVoiceReconnect,
+}
+
+// VoiceCloseError is returned when Discord closes the voice websocket
+type VoiceCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *VoiceCloseError) Error() string {
+	return fmt.Sprintf("voice connection closed with code %d: %s", e.Code, e.Reason)
+}
+
+// closeCodePattern matches the close code in websocket close error messages,
+// e.g. "websocket: close 4006: Session is no longer valid"
+var closeCodePattern = regexp.MustCompile(`close (\d{4})`)
+
+// VoiceCloseCode extracts a Discord voice close code from err
+func VoiceCloseCode(err error) (int, bool) {
+	var closeErr *VoiceCloseError
+	if errors.As(err, &closeErr) {
+		return closeErr.Code, true
+	}
+
+	if err == nil {
+		return 0, false
+	}
+
+	match := closeCodePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, false
+	}
+
+	code, convErr := strconv.Atoi(match[1])
+	if convErr != nil || code < 4000 || code > 4999 {
+		return 0, false
+	}
+	return code, true
+}
+
+// VoiceErrorClassifier classifies Discord voice close errors as high
+// severity voice errors; everything else is unknown and medium severity
+type VoiceErrorClassifier struct{}
+
+// Classify implements ErrorClassifier
+func (c VoiceErrorClassifier) Classify(err error) *PipelineError {
+	pipelineErr := NewPipelineError(err, c.GetCategory(err), c.GetSeverity(err))
+	if code, ok := VoiceCloseCode(err); ok {
+		pipelineErr.Context["close_code"] = code
+		if strategy, ok := voiceCloseStrategies[code]; ok {
+			pipelineErr.Context["strategy"] = strategy
+		}
+	}
+	return pipelineErr
+}
+
+// IsRetryable implements ErrorClassifier. Voice close errors need a full
+// reconnect, not a blind retry.
+func (c VoiceErrorClassifier) IsRetryable(err error) bool {
+	_, ok := VoiceCloseCode(err)
+	return !ok
+}
+
+// GetSeverity implements ErrorClassifier
+func (c VoiceErrorClassifier) GetSeverity(err error) ErrorSeverity {
+	if _, ok := VoiceCloseCode(err); ok {
+		return SeverityHigh
+	}
+	return SeverityMedium
+}
+
+// GetCategory implements ErrorClassifier
+func (c VoiceErrorClassifier) GetCategory(err error) ErrorCategory {
+	if _, ok := VoiceCloseCode(err); ok {
+		return CategoryVoice
+	}
+	return CategoryUnknown
+}
+
+// VoiceSession is the voice connection a reconnect operates on
+type VoiceSession interface {
+	Disconnect() error
+	Join(ctx context.Context) error
+	Position() time.Duration
+	ResumeAt(position time.Duration) error
+}
+
+// VoiceReconnectStrategy recovers from an invalidated voice session by
+// leaving the channel, joining again, and resuming playback where it stopped
+type VoiceReconnectStrategy struct {
+	session     VoiceSession
+	maxAttempts int
+}
+
+// NewVoiceReconnectStrategy creates a reconnect strategy for the session
+func NewVoiceReconnectStrategy(session VoiceSession, maxAttempts int) *VoiceReconnectStrategy {
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return &VoiceReconnectStrategy{session: session, maxAttempts: maxAttempts}
+}
+
+// Name returns the strategy name
+func (s *VoiceReconnectStrategy) Name() string {
+	return StrategyVoiceReconnect
+}
+
+// CanRecover implements RecoveryStrategy
+func (s *VoiceReconnectStrategy) CanRecover(err *PipelineError) bool {
+	if err == nil || err.Category != CategoryVoice {
+		return false
+	}
+
+	code, ok := VoiceCloseCode(err.Err)
+	return ok && voiceCloseStrategies[code] == StrategyVoiceReconnect
+}
+
+// Recover implements RecoveryStrategy
+func (s *VoiceReconnectStrategy) Recover(ctx context.Context, pipeline PipelineManager) error {
+	return ReconnectVoiceSession(ctx, s.session)
+}
+
+// ReconnectVoiceSession leaves the voice channel, joins again and resumes
+// playback at the position it had before leaving. The session decides which
+// channel Join connects to, so the same sequence moves playback to another
+// channel when Join targets a different one.
+func ReconnectVoiceSession(ctx context.Context, session VoiceSession) error {
+	position := session.Position()
+
+	if err := session.Disconnect(); err != nil {
+		return fmt.Errorf("voice reconnect: disconnect failed: %w", err)
+	}
+	if err := session.Join(ctx); err != nil {
+		return fmt.Errorf("voice reconnect: rejoin failed: %w", err)
+	}
+	if err := session.ResumeAt(position); err != nil {
+		return fmt.Errorf("voice reconnect: resume at %v failed: %w", position, err)
+	}
+	return nil
+}
+
+// Priority implements RecoveryStrategy. It outranks generic retries so a
+// dead voice session is never retried in place.
+func (s *VoiceReconnectStrategy) Priority() int {
+	return 100
+}
+
+// MaxAttempts implements RecoveryStrategy
+func (s *VoiceReconnectStrategy) MaxAttempts() int {
+	return s.maxAttempts
+}
+
+// SelectRecoveryStrategy returns the highest priority strategy that can
+// recover from err, or nil if none can
+func SelectRecoveryStrategy(err *PipelineError, strategies []RecoveryStrategy) RecoveryStrategy {
+	var selected RecoveryStrategy
+	for _, strategy := range strategies {
+		if !strategy.CanRecover(err) {
+			continue
+		}
+		if selected == nil || strategy.Priority() > selected.Priority() {
+			selected = strategy
+		}
+	}
+	return selected
+}
@@ -0,0 +1,186 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSink is a DiscordStreamer whose sends fail with a fixed error
+type failingSink struct {
+	err error
+}
+
+func (s *failingSink) Start(ctx context.Context) error          { return nil }
+func (s *failingSink) Stop() error                              { return nil }
+func (s *failingSink) SendOpusFrame(data []byte) error          { return s.err }
+func (s *failingSink) IsConnected() bool                        { return false }
+func (s *failingSink) GetConnectionMetrics() *ConnectionMetrics { return nil }
+
+// fakeVoiceSession records the reconnect sequence
+type fakeVoiceSession struct {
+	mu       sync.Mutex
+	calls    []string
+	position time.Duration
+	resumed  time.Duration
+}
+
+func (s *fakeVoiceSession) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *fakeVoiceSession) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *fakeVoiceSession) Disconnect() error { s.record("disconnect"); return nil }
+func (s *fakeVoiceSession) Join(ctx context.Context) error {
+	s.record("join")
+	return nil
+}
+func (s *fakeVoiceSession) Position() time.Duration { return s.position }
+func (s *fakeVoiceSession) ResumeAt(position time.Duration) error {
+	s.record("resume")
+	s.resumed = position
+	return nil
+}
+
+// retryStrategy stands in for a generic in-place retry
+type retryStrategy struct{}
+
+func (retryStrategy) CanRecover(err *PipelineError) bool                          { return true }
+func (retryStrategy) Recover(ctx context.Context, pipeline PipelineManager) error { return nil }
+func (retryStrategy) Priority() int                                               { return 10 }
+func (retryStrategy) MaxAttempts() int                                            { return 3 }
+
+func TestVoiceCloseCode(t *testing.T) {
+	code, ok := VoiceCloseCode(&VoiceCloseError{Code: VoiceCloseSessionNoLongerValid, Reason: "session no longer valid"})
+	assert.True(t, ok)
+	assert.Equal(t, 4006, code)
+
+	code, ok = VoiceCloseCode(errors.New("websocket: close 4006: Session is no longer valid"))
+	assert.True(t, ok)
+	assert.Equal(t, 4006, code)
+
+	_, ok = VoiceCloseCode(errors.New("connection reset by peer"))
+	assert.False(t, ok)
+}
+
+func TestVoiceReconnect_ChosenFor4006(t *testing.T) {
+	sink := &failingSink{err: &VoiceCloseError{Code: VoiceCloseSessionNoLongerValid, Reason: "session no longer valid"}}
+	sendErr := sink.SendOpusFrame([]byte{0xf8})
+	require.Error(t, sendErr)
+
+	classified := VoiceErrorClassifier{}.Classify(sendErr)
+	assert.Equal(t, CategoryVoice, classified.Category)
+	assert.Equal(t, SeverityHigh, classified.Severity)
+	assert.False(t, classified.Retryable)
+	assert.Equal(t, StrategyVoiceReconnect, classified.Context["strategy"])
+
+	session := &fakeVoiceSession{position: 95 * time.Second}
+	reconnect := NewVoiceReconnectStrategy(session, 2)
+
+	selected := SelectRecoveryStrategy(classified, []RecoveryStrategy{retryStrategy{}, reconnect})
+	require.Same(t, reconnect, selected)
+
+	require.NoError(t, selected.Recover(context.Background(), nil))
+	assert.Equal(t, []string{"disconnect", "join", "resume"}, session.Calls())
+	assert.Equal(t, 95*time.Second, session.resumed)
+
+	// Unrelated failures fall back to the generic retry
+	network := VoiceErrorClassifier{}.Classify(errors.New("connection reset by peer"))
+	assert.Equal(t, retryStrategy{}, SelectRecoveryStrategy(network, []RecoveryStrategy{retryStrategy{}, reconnect}))
+}
+
+func TestManager_RecoversVoiceSession(t *testing.T) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	require.NoError(t, err)
+	defer manager.Stop()
+
+	session := &fakeVoiceSession{position: 30 * time.Second}
+	manager.AddRecoveryStrategy(NewVoiceReconnectStrategy(session, 1))
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	manager.ReportError(&VoiceCloseError{Code: VoiceCloseSessionNoLongerValid}, CategoryVoice, SeverityHigh)
+
+	require.Eventually(t, func() bool {
+		return len(session.Calls()) == 3
+	}, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return manager.GetState() == StateStreaming
+	}, time.Second, 10*time.Millisecond)
+}
+
+// blockingStrategy recovers once release is closed, counting its runs
+type blockingStrategy struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (s *blockingStrategy) CanRecover(err *PipelineError) bool { return true }
+func (s *blockingStrategy) Recover(ctx context.Context, pipeline PipelineManager) error {
+	atomic.AddInt32(&s.calls, 1)
+	<-s.release
+	return nil
+}
+func (s *blockingStrategy) Priority() int    { return 10 }
+func (s *blockingStrategy) MaxAttempts() int { return 1 }
+
+func TestManager_OneRecoveryAtATime(t *testing.T) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	require.NoError(t, err)
+	defer manager.Stop()
+
+	strategy := &blockingStrategy{release: make(chan struct{})}
+	manager.AddRecoveryStrategy(strategy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	for i := 0; i < 3; i++ {
+		manager.ReportError(&VoiceCloseError{Code: VoiceCloseSessionNoLongerValid}, CategoryVoice, SeverityHigh)
+	}
+	require.Eventually(t, func() bool {
+		return manager.GetState() == StateRecovering
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&strategy.calls), "errors during a recovery don't start another")
+
+	close(strategy.release)
+	require.Eventually(t, func() bool {
+		return manager.GetState() == StateStreaming
+	}, time.Second, 5*time.Millisecond)
+
+	// Once it's done, the next error recovers again
+	manager.ReportError(&VoiceCloseError{Code: VoiceCloseSessionNoLongerValid}, CategoryVoice, SeverityHigh)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&strategy.calls) == 2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestManager_RecoveryDoesNotUndoStop(t *testing.T) {
+	manager, err := NewAudioPipelineManager(nil, NullLogger())
+	require.NoError(t, err)
+
+	strategy := &blockingStrategy{release: make(chan struct{})}
+	manager.AddRecoveryStrategy(strategy)
+	require.NoError(t, manager.Start(context.Background(), "https://example.com/stream"))
+
+	manager.ReportError(&VoiceCloseError{Code: VoiceCloseSessionNoLongerValid}, CategoryVoice, SeverityHigh)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&strategy.calls) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, manager.Stop())
+	close(strategy.release)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, StateIdle, manager.GetState(), "the recovery finishing after Stop leaves it stopped")
+}
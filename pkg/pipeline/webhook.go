@@ -0,0 +1,308 @@
+package pipeline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WebhookConfig configures a WebhookNotifier
+type WebhookConfig struct {
+	URL          string        `json:"url"`            // receives each event as a JSON POST
+	PipelineID   string        `json:"pipeline_id"`    // included in every payload
+	MinSeverity  string        `json:"min_severity"`   // low, medium, high or critical
+	MaxPerMinute int           `json:"max_per_minute"` // events sent per minute; the rest are dropped
+	MaxRetries   int           `json:"max_retries"`    // extra attempts after a failed delivery
+	RetryDelay   time.Duration `json:"retry_delay"`    // doubled after each failed attempt
+	Timeout      time.Duration `json:"timeout"`        // per request
+	QueueSize    int           `json:"queue_size"`     // events waiting for delivery
+}
+
+// DefaultWebhookConfig returns a config that alerts on high and critical
+// events, at most 10 a minute
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		MinSeverity:  SeverityHigh.String(),
+		MaxPerMinute: 10,
+		MaxRetries:   3,
+		RetryDelay:   time.Second,
+		Timeout:      5 * time.Second,
+		QueueSize:    100,
+	}
+}
+
+// webhookPayload is the JSON body POSTed for each event. Content makes the
+// payload readable by Discord webhooks as-is.
+type webhookPayload struct {
+	Content    string                 `json:"content"`
+	PipelineID string                 `json:"pipeline_id,omitempty"`
+	EventType  string                 `json:"event_type"`
+	Severity   string                 `json:"severity"`
+	EventData  map[string]interface{} `json:"event_data,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// WebhookNotifier POSTs pipeline events at or above a minimum severity to a
+// webhook, for alerting. It wraps another EventRecorder and forwards every
+// event to it. Delivery happens in the background with retries; events over
+// the rate limit or that don't fit the queue are dropped, so an error storm
+// can neither flood the receiver nor slow the pipeline down.
+type WebhookNotifier struct {
+	config      WebhookConfig
+	minSeverity int
+	next        EventRecorder
+	client      *http.Client
+	logger      Logger
+	now         func() time.Time
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowSent  int
+
+	queue    chan webhookPayload
+	sent     int64
+	dropped  int64
+	failed   int64
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	closed   sync.Once
+}
+
+// NewWebhookNotifier creates a notifier and starts its delivery loop. Events
+// are forwarded to next, which may be nil. Close stops it.
+func NewWebhookNotifier(config WebhookConfig, next EventRecorder, logger Logger) (*WebhookNotifier, error) {
+	if config.URL == "" {
+		return nil, errors.New("webhook URL cannot be empty")
+	}
+
+	defaults := DefaultWebhookConfig()
+	if config.MinSeverity == "" {
+		config.MinSeverity = defaults.MinSeverity
+	}
+	minSeverity := severityRank(config.MinSeverity)
+	if minSeverity < 0 {
+		return nil, fmt.Errorf("invalid webhook min severity %q (want low, medium, high or critical)", config.MinSeverity)
+	}
+	if config.MaxPerMinute <= 0 {
+		config.MaxPerMinute = defaults.MaxPerMinute
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = defaults.RetryDelay
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaults.QueueSize
+	}
+	if logger == nil {
+		logger = NullLogger()
+	}
+
+	n := &WebhookNotifier{
+		config:      config,
+		minSeverity: minSeverity,
+		next:        next,
+		client:      &http.Client{Timeout: config.Timeout},
+		logger:      logger.With(String("component", "webhook_notifier")),
+		now:         time.Now,
+		queue:       make(chan webhookPayload, config.QueueSize),
+		stopChan:    make(chan struct{}),
+	}
+
+	n.wg.Add(1)
+	go n.deliverLoop()
+
+	return n, nil
+}
+
+// RecordEvent implements EventRecorder
+func (n *WebhookNotifier) RecordEvent(eventType, severity string, data map[string]interface{}) {
+	if n.next != nil {
+		n.next.RecordEvent(eventType, severity, data)
+	}
+
+	if severityRank(severity) < n.minSeverity {
+		return
+	}
+	if !n.allow() {
+		atomic.AddInt64(&n.dropped, 1)
+		return
+	}
+
+	payload := webhookPayload{
+		Content:    fmt.Sprintf("[%s] pipeline event %s", severity, eventType),
+		PipelineID: n.config.PipelineID,
+		EventType:  eventType,
+		Severity:   severity,
+		EventData:  redactEventData(data),
+		Timestamp:  n.now(),
+	}
+	if n.config.PipelineID != "" {
+		payload.Content = fmt.Sprintf("[%s] pipeline %s: %s", severity, n.config.PipelineID, eventType)
+	}
+
+	select {
+	case n.queue <- payload:
+	default:
+		atomic.AddInt64(&n.dropped, 1)
+	}
+}
+
+// allow reports whether another event fits in the current minute's budget
+func (n *WebhookNotifier) allow() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.now()
+	if now.Sub(n.windowStart) >= time.Minute {
+		n.windowStart = now
+		n.windowSent = 0
+	}
+	if n.windowSent >= n.config.MaxPerMinute {
+		return false
+	}
+	n.windowSent++
+	return true
+}
+
+// Sent returns the number of events delivered
+func (n *WebhookNotifier) Sent() int64 {
+	return atomic.LoadInt64(&n.sent)
+}
+
+// Dropped returns the number of events discarded by the rate limit or a
+// full queue
+func (n *WebhookNotifier) Dropped() int64 {
+	return atomic.LoadInt64(&n.dropped)
+}
+
+// Failed returns the number of events that could not be delivered after
+// every retry
+func (n *WebhookNotifier) Failed() int64 {
+	return atomic.LoadInt64(&n.failed)
+}
+
+// Close delivers events already queued, giving up on retries, and stops the
+// delivery loop
+func (n *WebhookNotifier) Close() error {
+	n.closed.Do(func() {
+		close(n.stopChan)
+		n.wg.Wait()
+	})
+	return nil
+}
+
+// deliverLoop sends queued events one at a time
+func (n *WebhookNotifier) deliverLoop() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case payload := <-n.queue:
+			n.deliver(payload)
+		case <-n.stopChan:
+			for {
+				select {
+				case payload := <-n.queue:
+					n.deliver(payload)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver POSTs one event, retrying failures with a doubling delay
+func (n *WebhookNotifier) deliver(payload webhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		n.logger.Warn("Failed to encode webhook payload", String("event_type", payload.EventType), Error(err))
+		atomic.AddInt64(&n.failed, 1)
+		return
+	}
+
+	delay := n.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(body)
+		if err == nil {
+			atomic.AddInt64(&n.sent, 1)
+			return
+		}
+
+		if !retry || attempt >= n.config.MaxRetries {
+			n.logger.Warn("Failed to deliver webhook event",
+				String("event_type", payload.EventType),
+				Int("attempts", attempt+1),
+				Error(err))
+			atomic.AddInt64(&n.failed, 1)
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-n.stopChan:
+			atomic.AddInt64(&n.failed, 1)
+			return
+		}
+	}
+}
+
+// post sends body once. It reports whether a failure is worth retrying:
+// network errors, 429s and server errors are; other client errors aren't.
+func (n *WebhookNotifier) post(body []byte) (bool, error) {
+	resp, err := n.client.Post(n.config.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	err = fmt.Errorf("webhook returned %s", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// severityRank orders severities from low (0) to critical (3), or -1 for an
+// unknown one
+func severityRank(severity string) int {
+	for s := SeverityLow; s <= SeverityCritical; s++ {
+		if s.String() == severity {
+			return int(s)
+		}
+	}
+	return -1
+}
+
+// redactEventData masks credentials in string values, such as stream URLs
+// with embedded passwords, before they leave the process
+func redactEventData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if s, ok := value.(string); ok {
+			if isSecretKey(key) {
+				value = RedactedValue
+			} else {
+				value = redactString(s)
+			}
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
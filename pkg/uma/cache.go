@@ -0,0 +1,25 @@
+package uma
+
+import (
+	"math/rand"
+	"time"
+)
+
+// DefaultTTLJitter spreads cache expirations by ±10% so entries written in a
+// burst don't all expire, and get refetched, at the same moment
+const DefaultTTLJitter = 0.1
+
+// JitterTTL returns ttl randomly adjusted by up to ±jitter (a fraction, so
+// 0.1 means ±10%). A jitter of zero or less returns ttl unchanged.
+func JitterTTL(ttl time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+
+	// Uniform in [-jitter, +jitter]
+	offset := (rand.Float64()*2 - 1) * jitter
+	return ttl + time.Duration(float64(ttl)*offset)
+}
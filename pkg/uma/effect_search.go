@@ -0,0 +1,79 @@
+package uma
+
+import (
+	"sort"
+	"strings"
+)
+
+// EffectCard is a support card offering a searched-for effect
+type EffectCard struct {
+	Card   *SimplifiedSupportCard
+	Effect EffectValue
+}
+
+// normalizeEffectName folds case and drops spaces and hyphens, so
+// "friendship-bonus" and "FriendshipBonus" both name Friendship Bonus
+func normalizeEffectName(name string) string {
+	name = strings.ToLower(name)
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(name)
+}
+
+// FindEffectType looks up a known effect type by name, ignoring case, spaces
+// and hyphens
+func FindEffectType(name string) (EffectType, bool) {
+	want := normalizeEffectName(name)
+	if want == "" {
+		return EffectType{}, false
+	}
+	for _, effectType := range effectTypes {
+		if normalizeEffectName(effectType.Name) == want {
+			return effectType, true
+		}
+	}
+	return EffectType{}, false
+}
+
+// EffectTypeNames returns the names of the known effect types, sorted
+func EffectTypeNames() []string {
+	names := make([]string, 0, len(effectTypes))
+	for _, effectType := range effectTypes {
+		names = append(names, effectType.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SplitEffectQuery separates an optional trailing rarity (SSR, SR or R) from
+// an effect name, e.g. ["friendship", "bonus", "ssr"] gives "friendship
+// bonus" and 3. The rarity is 0 when none is given.
+func SplitEffectQuery(args []string) (string, int) {
+	if len(args) > 1 {
+		if rarity := rarityFromText(args[len(args)-1]); rarity > 0 {
+			return strings.Join(args[:len(args)-1], " "), rarity
+		}
+	}
+	return strings.Join(args, " "), 0
+}
+
+// CardsWithEffect returns the cards whose effects include typeID, optionally
+// only those of one rarity, highest max-level value first. Cards with the
+// same value keep their list order.
+func CardsWithEffect(cards []*SimplifiedSupportCard, typeID, rarity int) []EffectCard {
+	var matches []EffectCard
+	for _, card := range cards {
+		if rarity != 0 && card.Rarity != rarity {
+			continue
+		}
+		for _, effect := range DecodeEffects(card.Effects) {
+			if effect.TypeID == typeID && len(effect.Levels) > 0 {
+				matches = append(matches, EffectCard{Card: card, Effect: effect})
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Effect.MaxValue() > matches[j].Effect.MaxValue()
+	})
+	return matches
+}
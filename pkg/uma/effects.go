@@ -0,0 +1,118 @@
+package uma
+
+import "fmt"
+
+// EffectLevels are the card level breakpoints used by Gametora effect arrays.
+// Each effect array is [typeID, valueAtLv1, valueAtLv5, ..., valueAtLv50],
+// with -1 marking a breakpoint where the value does not change.
+var EffectLevels = []int{1, 5, 10, 15, 20, 25, 30, 35, 40, 45, 50}
+
+// EffectType describes a support card effect type
+type EffectType struct {
+	ID   int
+	Name string
+	Unit string
+}
+
+// effectTypes is the maintained table of known support card effect types
+var effectTypes = map[int]EffectType{
+	1:  {ID: 1, Name: "Friendship Bonus", Unit: "%"},
+	2:  {ID: 2, Name: "Mood Effect", Unit: "%"},
+	3:  {ID: 3, Name: "Speed Bonus", Unit: ""},
+	4:  {ID: 4, Name: "Stamina Bonus", Unit: ""},
+	5:  {ID: 5, Name: "Power Bonus", Unit: ""},
+	6:  {ID: 6, Name: "Guts Bonus", Unit: ""},
+	7:  {ID: 7, Name: "Wit Bonus", Unit: ""},
+	8:  {ID: 8, Name: "Training Effectiveness", Unit: "%"},
+	9:  {ID: 9, Name: "Initial Speed", Unit: ""},
+	10: {ID: 10, Name: "Initial Stamina", Unit: ""},
+	11: {ID: 11, Name: "Initial Power", Unit: ""},
+	12: {ID: 12, Name: "Initial Guts", Unit: ""},
+	13: {ID: 13, Name: "Initial Wit", Unit: ""},
+	14: {ID: 14, Name: "Initial Friendship Gauge", Unit: ""},
+	15: {ID: 15, Name: "Race Bonus", Unit: "%"},
+	16: {ID: 16, Name: "Fan Bonus", Unit: "%"},
+	17: {ID: 17, Name: "Hint Levels", Unit: ""},
+	18: {ID: 18, Name: "Hint Frequency", Unit: "%"},
+	19: {ID: 19, Name: "Specialty Priority", Unit: ""},
+	25: {ID: 25, Name: "Event Recovery", Unit: "%"},
+	26: {ID: 26, Name: "Event Effectiveness", Unit: "%"},
+	27: {ID: 27, Name: "Failure Protection", Unit: "%"},
+	28: {ID: 28, Name: "Energy Cost Reduction", Unit: "%"},
+	30: {ID: 30, Name: "Skill Point Bonus", Unit: ""},
+	31: {ID: 31, Name: "Wit Friendship Recovery", Unit: ""},
+}
+
+// EffectLevelValue is the value of an effect at a given card level
+type EffectLevelValue struct {
+	Level int `json:"level"`
+	Value int `json:"value"`
+}
+
+// EffectValue is a decoded support card effect
+type EffectValue struct {
+	TypeID int                `json:"type_id"`
+	Name   string             `json:"name"`
+	Unit   string             `json:"unit,omitempty"`
+	Known  bool               `json:"known"`
+	Levels []EffectLevelValue `json:"levels"`
+}
+
+// MaxValue returns the effect value at the highest listed level
+func (e EffectValue) MaxValue() int {
+	if len(e.Levels) == 0 {
+		return 0
+	}
+	return e.Levels[len(e.Levels)-1].Value
+}
+
+// String formats the effect as "Name: value" at its highest listed level
+func (e EffectValue) String() string {
+	return fmt.Sprintf("%s: %d%s", e.Name, e.MaxValue(), e.Unit)
+}
+
+// GetEffectType looks up an effect type by ID
+func GetEffectType(id int) (EffectType, bool) {
+	effectType, ok := effectTypes[id]
+	return effectType, ok
+}
+
+// DecodeEffects converts raw Gametora effect arrays into labeled values.
+// Unknown type IDs are kept with a placeholder name so no data is lost.
+func DecodeEffects(raw [][]int) []EffectValue {
+	effects := make([]EffectValue, 0, len(raw))
+
+	for _, entry := range raw {
+		if len(entry) == 0 {
+			continue
+		}
+
+		typeID := entry[0]
+		effect := EffectValue{TypeID: typeID}
+
+		if effectType, ok := effectTypes[typeID]; ok {
+			effect.Name = effectType.Name
+			effect.Unit = effectType.Unit
+			effect.Known = true
+		} else {
+			effect.Name = fmt.Sprintf("Unknown Effect (%d)", typeID)
+		}
+
+		for i, value := range entry[1:] {
+			if i >= len(EffectLevels) {
+				break
+			}
+			if value < 0 {
+				continue
+			}
+			effect.Levels = append(effect.Levels, EffectLevelValue{
+				Level: EffectLevels[i],
+				Value: value,
+			})
+		}
+
+		effects = append(effects, effect)
+	}
+
+	return effects
+}
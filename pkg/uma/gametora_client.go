@@ -1,9 +1,11 @@
 package uma
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,19 +14,27 @@ import (
 	"github.com/latoulicious/HKTM/pkg/cron"
 )
 
+// FallbackBuildID is used when the build ID can't be found on Gametora's
+// pages. Requests with it fail once Gametora deploys a new build.
+const FallbackBuildID = "4Lod4e9rq2HCjy-VKjMHJ"
+
 // Global Gametora client instance
 var globalGametoraClient *GametoraClient
 
 // GametoraClient represents the Gametora API client for stable JSON endpoints
 type GametoraClient struct {
 	baseURL        string
-	httpClient     *http.Client
+	httpClient     HTTPDoer
 	cache          map[string]*CacheEntry
 	cacheMutex     sync.RWMutex
 	cacheTTL       time.Duration
+	cacheJitter    float64
 	buildID        string
 	buildMutex     sync.RWMutex
+	buildFetchedAt time.Time // when buildID was last set by a fetch; zero if preset
+	buildFallback  bool      // buildID is FallbackBuildID because a fetch found none
 	buildIDManager *cron.BuildIDManager
+	matcher        Matcher
 }
 
 // GetGametoraClient returns the global Gametora client instance
@@ -49,6 +59,51 @@ func (c *GametoraClient) StopBuildIDManager() {
 	}
 }
 
+// Close stops the build ID manager, waiting for a refresh in progress, and
+// drops the cache and idle connections. A client is meant to live for the
+// whole process and be closed on shutdown; after Close its build ID is no
+// longer refreshed. Closing twice is harmless.
+func (c *GametoraClient) Close() error {
+	c.StopBuildIDManager()
+
+	c.cacheMutex.Lock()
+	c.cache = make(map[string]*CacheEntry)
+	c.cacheMutex.Unlock()
+
+	closeIdleConnections(c.httpClient)
+	return nil
+}
+
+// BuildIDInfo describes the build ID the client requests Gametora data with
+type BuildIDInfo struct {
+	ID         string
+	FetchedAt  time.Time     // zero if the ID was preset and never fetched
+	IsFallback bool          // the ID is FallbackBuildID, so data may be stale
+	CacheTTL   time.Duration // how long fetched data is cached
+}
+
+// BuildIDInfo returns the current build ID and where it came from, without
+// fetching one
+func (c *GametoraClient) BuildIDInfo() BuildIDInfo {
+	c.buildMutex.RLock()
+	defer c.buildMutex.RUnlock()
+	return BuildIDInfo{
+		ID:         c.buildID,
+		FetchedAt:  c.buildFetchedAt,
+		IsFallback: c.buildFallback,
+		CacheTTL:   c.cacheTTL,
+	}
+}
+
+// setBuildID stores a fetched build ID
+func (c *GametoraClient) setBuildID(buildID string, fallback bool) {
+	c.buildMutex.Lock()
+	defer c.buildMutex.Unlock()
+	c.buildID = buildID
+	c.buildFetchedAt = time.Now()
+	c.buildFallback = fallback
+}
+
 // refreshBuildID refreshes the build ID by fetching it from Gametora
 func (c *GametoraClient) refreshBuildID() error {
 	// Clear the current build ID to force a fresh fetch
@@ -62,14 +117,22 @@ func (c *GametoraClient) refreshBuildID() error {
 }
 
 // NewGametoraClient creates a new Gametora API client
-func NewGametoraClient(cfg *config.Config) *GametoraClient {
+func NewGametoraClient(cfg *config.Config, opts ...ClientOption) *GametoraClient {
+	options := newClientOptions("https://gametora.com/_next/data", opts)
+
+	cacheTTL := 30 * time.Minute // Cache for 30 minutes
+	if options.cacheTTL > 0 {
+		cacheTTL = options.cacheTTL
+	}
+
 	client := &GametoraClient{
-		baseURL: "https://gametora.com/_next/data",
-		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
-		},
-		cache:    make(map[string]*CacheEntry),
-		cacheTTL: 30 * time.Minute, // Cache for 30 minutes
+		baseURL:     options.baseURL,
+		httpClient:  options.newHTTPClient(15 * time.Second),
+		cache:       make(map[string]*CacheEntry),
+		cacheTTL:    cacheTTL,
+		cacheJitter: options.ttlJitter,
+		buildID:     options.buildID,
+		matcher:     options.matcher,
 	}
 
 	// Initialize build ID manager with config
@@ -83,6 +146,11 @@ func NewGametoraClient(cfg *config.Config) *GametoraClient {
 
 // GetBuildID fetches the current build ID from Gametora
 func (c *GametoraClient) GetBuildID() (string, error) {
+	return c.getBuildID(context.Background())
+}
+
+// getBuildID is GetBuildID, abandoning the fetch when ctx is done
+func (c *GametoraClient) getBuildID(ctx context.Context) (string, error) {
 	c.buildMutex.RLock()
 	if c.buildID != "" {
 		defer c.buildMutex.RUnlock()
@@ -91,9 +159,9 @@ func (c *GametoraClient) GetBuildID() (string, error) {
 	c.buildMutex.RUnlock()
 
 	// Fetch the main page to get the build ID
-	resp, err := c.httpClient.Get("https://gametora.com/umamusume/supports")
+	resp, err := getContext(ctx, c.httpClient, "https://gametora.com/umamusume/supports")
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch build ID: %v", err)
+		return "", fmt.Errorf("failed to fetch build ID: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -119,9 +187,7 @@ func (c *GametoraClient) GetBuildID() (string, error) {
 			if end != -1 {
 				buildID := bodyStr[start : start+end]
 				if len(buildID) > 10 && len(buildID) < 50 {
-					c.buildMutex.Lock()
-					c.buildID = buildID
-					c.buildMutex.Unlock()
+					c.setBuildID(buildID, false)
 					return buildID, nil
 				}
 			}
@@ -140,9 +206,7 @@ func (c *GametoraClient) GetBuildID() (string, error) {
 				if valueEnd != -1 {
 					buildID := bodyStr[valueStart : valueStart+valueEnd]
 					if len(buildID) > 10 && len(buildID) < 50 {
-						c.buildMutex.Lock()
-						c.buildID = buildID
-						c.buildMutex.Unlock()
+						c.setBuildID(buildID, false)
 						return buildID, nil
 					}
 				}
@@ -151,262 +215,165 @@ func (c *GametoraClient) GetBuildID() (string, error) {
 	}
 
 	// If no build ID found, try a hardcoded one as fallback
-	// This is the build ID from your example
-	fallbackBuildID := "4Lod4e9rq2HCjy-VKjMHJ"
-	c.buildMutex.Lock()
-	c.buildID = fallbackBuildID
-	c.buildMutex.Unlock()
+	c.setBuildID(FallbackBuildID, true)
 
-	return fallbackBuildID, nil
+	return FallbackBuildID, nil
 }
 
-// SearchSimplifiedSupportCard searches for a support card using the Gametora JSON API and returns simplified structure
-func (c *GametoraClient) SearchSimplifiedSupportCard(query string) *SimplifiedGametoraSearchResult {
+// InvalidateCache drops every cached response and returns how many entries
+// were dropped
+func (c *GametoraClient) InvalidateCache() int {
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+
+	dropped := len(c.cache)
+	c.cache = make(map[string]*CacheEntry)
+	return dropped
+}
+
+// ReloadResult summarizes a full reload of Gametora data
+type ReloadResult struct {
+	BuildID     string
+	IsFallback  bool // no build ID was found, so FallbackBuildID is in use
+	Cards       int  // support cards in the reloaded list
+	Invalidated int  // cached entries dropped before reloading
+}
+
+// Reload drops the cache, fetches a fresh build ID and re-fetches the full
+// supports list, caching it again. Use it after a game update, when both
+// the build ID and the card data may have changed.
+func (c *GametoraClient) Reload() (*ReloadResult, error) {
+	result := &ReloadResult{Invalidated: c.InvalidateCache()}
+
+	if err := c.refreshBuildID(); err != nil {
+		return result, fmt.Errorf("failed to refresh build ID: %v", err)
+	}
+	info := c.BuildIDInfo()
+	result.BuildID = info.ID
+	result.IsFallback = info.IsFallback
+
+	cards, err := c.GetAllSupportCards()
+	if err != nil {
+		return result, err
+	}
+	result.Cards = len(cards)
+	return result, nil
+}
+
+// GetAllSupportCards returns every support card from the Gametora supports
+// list in simplified form. The decoded list is cached, so repeated searches
+// and local filtering don't refetch or re-decode the full response.
+func (c *GametoraClient) GetAllSupportCards() ([]*SimplifiedSupportCard, error) {
+	return c.getAllSupportCards(context.Background())
+}
+
+// getAllSupportCards is GetAllSupportCards, abandoning the fetch when ctx is
+// done
+func (c *GametoraClient) getAllSupportCards(ctx context.Context) ([]*SimplifiedSupportCard, error) {
 	// Check cache first
-	cacheKey := fmt.Sprintf("gametora_simplified_support_%s", strings.ToLower(query))
+	cacheKey := "gametora_all_supports"
 	if cached := c.getFromCache(cacheKey); cached != nil {
-		if result, ok := cached.(*SimplifiedGametoraSearchResult); ok {
-			return result
+		if cards, ok := cached.([]*SimplifiedSupportCard); ok {
+			return cards, nil
 		}
 	}
 
 	// Get build ID
-	buildID, err := c.GetBuildID()
+	buildID, err := c.getBuildID(ctx)
 	if err != nil {
-		result := &SimplifiedGametoraSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to get build ID: %v", err),
-			Query: query,
-		}
-		c.setCache(cacheKey, result)
-		return result
+		return nil, fmt.Errorf("failed to get build ID: %w", err)
 	}
 
-	// First, get the list of all support cards
 	supportsURL := fmt.Sprintf("%s/%s/umamusume/supports.json", c.baseURL, buildID)
-	resp, err := c.httpClient.Get(supportsURL)
+	resp, err := getContext(ctx, c.httpClient, supportsURL)
 	if err != nil {
-		result := &SimplifiedGametoraSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to fetch supports list: %v", err),
-			Query: query,
-		}
-		c.setCache(cacheKey, result)
-		return result
+		return nil, fmt.Errorf("failed to fetch supports list: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		result := &SimplifiedGametoraSearchResult{
-			Found: false,
-			Error: fmt.Errorf("supports API returned status code: %d", resp.StatusCode),
-			Query: query,
-		}
-		c.setCache(cacheKey, result)
-		return result
+		return nil, fmt.Errorf("supports API returned status code: %d", resp.StatusCode)
 	}
 
 	var supportsResp GametoraSupportsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&supportsResp); err != nil {
-		result := &SimplifiedGametoraSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to decode supports response: %v", err),
-			Query: query,
-		}
-		c.setCache(cacheKey, result)
-		return result
-	}
-
-	// Find all matches
-	query = strings.ToLower(strings.TrimSpace(query))
-	var allMatches []struct {
-		URLName     string  `json:"url_name"`
-		SupportID   int     `json:"support_id"`
-		CharID      int     `json:"char_id"`
-		CharName    string  `json:"char_name"`
-		NameJp      string  `json:"name_jp"`
-		NameKo      string  `json:"name_ko"`
-		NameTw      string  `json:"name_tw"`
-		Rarity      int     `json:"rarity"`
-		Type        string  `json:"type"`
-		Obtained    string  `json:"obtained"`
-		Release     string  `json:"release"`
-		ReleaseKo   string  `json:"release_ko,omitempty"`
-		ReleaseZhTw string  `json:"release_zh_tw,omitempty"`
-		ReleaseEn   string  `json:"release_en,omitempty"`
-		Effects     [][]int `json:"effects"`
-		Hints       struct {
-			HintSkills []struct {
-				ID     int      `json:"id"`
-				Type   []string `json:"type"`
-				NameEn string   `json:"name_en"`
-				IconID int      `json:"iconid"`
-			} `json:"hint_skills"`
-			HintOthers []struct {
-				HintType  int `json:"hint_type"`
-				HintValue int `json:"hint_value"`
-			} `json:"hint_others"`
-		} `json:"hints"`
-		EventSkills []struct {
-			ID     int      `json:"id"`
-			Type   []string `json:"type"`
-			NameEn string   `json:"name_en"`
-			Rarity int      `json:"rarity"`
-			IconID int      `json:"iconid"`
-		} `json:"event_skills"`
-		Unique *struct {
-			Level   int `json:"level"`
-			Effects []struct {
-				Type   int `json:"type"`
-				Value  int `json:"value"`
-				Value1 int `json:"value_1,omitempty"`
-				Value2 int `json:"value_2,omitempty"`
-				Value3 int `json:"value_3,omitempty"`
-				Value4 int `json:"value_4,omitempty"`
-			} `json:"effects"`
-		} `json:"unique,omitempty"`
+		return nil, fmt.Errorf("failed to decode supports response: %v", err)
 	}
 
+	cards := make([]*SimplifiedSupportCard, 0, len(supportsResp.PageProps.SupportData))
 	for _, support := range supportsResp.PageProps.SupportData {
-		urlName := strings.ToLower(support.URLName)
+		card := SimplifiedSupportCard(support)
+		cards = append(cards, &card)
+	}
 
-		// Simple URL name matching - check if all query words are in the URL name
-		queryWords := strings.Fields(query)
-		allWordsMatch := true
+	c.setCache(cacheKey, cards)
+	return cards, nil
+}
 
-		for _, word := range queryWords {
-			if len(word) > 2 && !strings.Contains(urlName, word) {
-				allWordsMatch = false
-				break
-			}
-		}
+// SearchSimplifiedSupportCard searches for a support card using the Gametora JSON API and returns simplified structure
+func (c *GametoraClient) SearchSimplifiedSupportCard(query string) *SimplifiedGametoraSearchResult {
+	return c.SearchSimplifiedSupportCardContext(context.Background(), query)
+}
 
-		// Only add if all words match in the URL name
-		if allWordsMatch && len(queryWords) > 0 {
-			allMatches = append(allMatches, struct {
-				URLName     string  `json:"url_name"`
-				SupportID   int     `json:"support_id"`
-				CharID      int     `json:"char_id"`
-				CharName    string  `json:"char_name"`
-				NameJp      string  `json:"name_jp"`
-				NameKo      string  `json:"name_ko"`
-				NameTw      string  `json:"name_tw"`
-				Rarity      int     `json:"rarity"`
-				Type        string  `json:"type"`
-				Obtained    string  `json:"obtained"`
-				Release     string  `json:"release"`
-				ReleaseKo   string  `json:"release_ko,omitempty"`
-				ReleaseZhTw string  `json:"release_zh_tw,omitempty"`
-				ReleaseEn   string  `json:"release_en,omitempty"`
-				Effects     [][]int `json:"effects"`
-				Hints       struct {
-					HintSkills []struct {
-						ID     int      `json:"id"`
-						Type   []string `json:"type"`
-						NameEn string   `json:"name_en"`
-						IconID int      `json:"iconid"`
-					} `json:"hint_skills"`
-					HintOthers []struct {
-						HintType  int `json:"hint_type"`
-						HintValue int `json:"hint_value"`
-					} `json:"hint_others"`
-				} `json:"hints"`
-				EventSkills []struct {
-					ID     int      `json:"id"`
-					Type   []string `json:"type"`
-					NameEn string   `json:"name_en"`
-					Rarity int      `json:"rarity"`
-					IconID int      `json:"iconid"`
-				} `json:"event_skills"`
-				Unique *struct {
-					Level   int `json:"level"`
-					Effects []struct {
-						Type   int `json:"type"`
-						Value  int `json:"value"`
-						Value1 int `json:"value_1,omitempty"`
-						Value2 int `json:"value_2,omitempty"`
-						Value3 int `json:"value_3,omitempty"`
-						Value4 int `json:"value_4,omitempty"`
-					} `json:"effects"`
-				} `json:"unique,omitempty"`
-			}{
-				URLName:     support.URLName,
-				SupportID:   support.SupportID,
-				CharID:      support.CharID,
-				CharName:    support.CharName,
-				NameJp:      support.NameJp,
-				NameKo:      support.NameKo,
-				NameTw:      support.NameTw,
-				Rarity:      support.Rarity,
-				Type:        support.Type,
-				Obtained:    support.Obtained,
-				Release:     support.Release,
-				ReleaseKo:   support.ReleaseKo,
-				ReleaseZhTw: support.ReleaseZhTw,
-				ReleaseEn:   support.ReleaseEn,
-				Effects:     support.Effects,
-				Hints:       support.Hints,
-				EventSkills: support.EventSkills,
-				Unique:      support.Unique,
-			})
+// SearchSimplifiedSupportCardContext is SearchSimplifiedSupportCard,
+// abandoning the requests when ctx is done. Abandoned searches aren't cached.
+func (c *GametoraClient) SearchSimplifiedSupportCardContext(ctx context.Context, query string) *SimplifiedGametoraSearchResult {
+	// Check cache first
+	cacheKey := fmt.Sprintf("gametora_simplified_support_%s", strings.ToLower(query))
+	if cached := c.getFromCache(cacheKey); cached != nil {
+		if result, ok := cached.(*SimplifiedGametoraSearchResult); ok {
+			return result
 		}
 	}
 
-	if len(allMatches) == 0 {
+	// Get the full list of support cards, filtered locally below
+	allCards, err := c.getAllSupportCards(ctx)
+	if err != nil {
 		result := &SimplifiedGametoraSearchResult{
-			Found: false,
-			Query: query,
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  err,
+			Query:  query,
+		}
+		if !throttled(err) {
+			c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		}
-		c.setCache(cacheKey, result)
 		return result
 	}
 
-	// Sort matches by rarity (highest first) since we no longer use scores
-	for i := 0; i < len(allMatches)-1; i++ {
-		for j := i + 1; j < len(allMatches); j++ {
-			if allMatches[i].Rarity < allMatches[j].Rarity {
-				allMatches[i], allMatches[j] = allMatches[j], allMatches[i]
-			}
-		}
-	}
+	// Find all matches
+	query = strings.ToLower(strings.TrimSpace(query))
+	matches := rankSupportCards(c.matcher, query, allCards)
 
-	// Convert all matches to simplified structure
-	var simplifiedCards []*SimplifiedSupportCard
-	for _, match := range allMatches {
-		simplifiedCard := &SimplifiedSupportCard{
-			URLName:     match.URLName,
-			SupportID:   match.SupportID,
-			CharID:      match.CharID,
-			CharName:    match.CharName,
-			NameJp:      match.NameJp,
-			NameKo:      match.NameKo,
-			NameTw:      match.NameTw,
-			Rarity:      match.Rarity,
-			Type:        match.Type,
-			Obtained:    match.Obtained,
-			Release:     match.Release,
-			ReleaseKo:   match.ReleaseKo,
-			ReleaseZhTw: match.ReleaseZhTw,
-			ReleaseEn:   match.ReleaseEn,
-			Effects:     match.Effects,
-			Hints:       match.Hints,
-			EventSkills: match.EventSkills,
-			Unique:      match.Unique,
+	if len(matches) == 0 {
+		result := &SimplifiedGametoraSearchResult{
+			Found:  false,
+			Reason: NotFound,
+			Query:  query,
 		}
-		simplifiedCards = append(simplifiedCards, simplifiedCard)
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
+		return result
 	}
 
 	result := &SimplifiedGametoraSearchResult{
 		Found:        true,
-		SupportCard:  simplifiedCards[0], // Best match as primary
-		SupportCards: simplifiedCards,    // All matches
+		SupportCard:  matches[0], // Best match as primary
+		SupportCards: matches,    // All matches
 		Query:        query,
 	}
 
-	c.setCache(cacheKey, result)
+	c.setCacheUnlessCancelled(ctx, cacheKey, result)
 	return result
 }
 
+// setCacheUnlessCancelled stores an item in cache unless ctx is done, so a
+// search abandoned partway isn't served to later callers
+func (c *GametoraClient) setCacheUnlessCancelled(ctx context.Context, key string, data interface{}) {
+	if ctx.Err() == nil {
+		c.setCache(key, data)
+	}
+}
+
 // getFromCache retrieves an item from cache
 func (c *GametoraClient) getFromCache(key string) interface{} {
 	c.cacheMutex.RLock()
@@ -427,7 +394,7 @@ func (c *GametoraClient) setCache(key string, data interface{}) {
 	c.cache[key] = &CacheEntry{
 		Data:      data,
 		Timestamp: time.Now(),
-		TTL:       c.cacheTTL,
+		TTL:       JitterTTL(c.cacheTTL, c.cacheJitter),
 	}
 }
 
@@ -440,3 +407,21 @@ func (c *GametoraClient) GetSupportCardImageURL(urlName string) string {
 	}
 	return ""
 }
+
+// rankSupportCards returns the cards matcher matches, best first and highest
+// rarity first within a score
+func rankSupportCards(matcher Matcher, query string, cards []*SimplifiedSupportCard) []*SimplifiedSupportCard {
+	ranked := RankMatches(matcher, query, cards)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Item.Rarity > ranked[j].Item.Rarity
+	})
+
+	matches := make([]*SimplifiedSupportCard, 0, len(ranked))
+	for _, match := range ranked {
+		matches = append(matches, match.Item)
+	}
+	return matches
+}
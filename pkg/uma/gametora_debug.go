@@ -23,7 +23,7 @@ func (c *GametoraClient) DebugSearchSupportCard(query string) {
 	supportsURL := fmt.Sprintf("%s/%s/umamusume/supports.json", c.baseURL, buildID)
 	fmt.Printf("🌐 Fetching from: %s\n", supportsURL)
 
-	resp, err := c.httpClient.Get(supportsURL)
+	resp, err := get(c.httpClient, supportsURL)
 	if err != nil {
 		fmt.Printf("❌ Failed to fetch supports: %v\n", err)
 		return
@@ -0,0 +1,204 @@
+package uma
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent identifies the bot to upstream APIs so operators can
+// recognise (and whitelist) its traffic.
+const DefaultUserAgent = "HKTM-Bot/1.0 (+https://github.com/latoulicious/Tarumae)"
+
+// HTTPDoer sends HTTP requests. *http.Client implements it; tests can
+// supply canned responses, and wrappers can add retries or rate limits.
+type HTTPDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// clientOptions holds the settings shared by the HTTP API clients
+type clientOptions struct {
+	baseURL   string
+	userAgent string
+	headers   map[string]string
+	ttlJitter float64
+	cacheTTL  time.Duration
+	buildID   string
+	matcher   Matcher
+	doer      HTTPDoer
+
+	supportListRetry RetryPolicy
+}
+
+// ClientOption configures an API client
+type ClientOption func(*clientOptions)
+
+// WithUserAgent sets the User-Agent sent with every request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithHeader adds an extra header sent with every request
+func WithHeader(key, value string) ClientOption {
+	return func(o *clientOptions) {
+		o.headers[key] = value
+	}
+}
+
+// WithBaseURL overrides the API base URL
+func WithBaseURL(baseURL string) ClientOption {
+	return func(o *clientOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithTTLJitter sets the ± fraction applied to cache TTLs; zero disables jitter
+func WithTTLJitter(jitter float64) ClientOption {
+	return func(o *clientOptions) {
+		o.ttlJitter = jitter
+	}
+}
+
+// WithCacheTTL overrides how long responses are cached before jitter
+func WithCacheTTL(ttl time.Duration) ClientOption {
+	return func(o *clientOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithBuildID pins the Gametora build ID instead of scraping it on first use
+func WithBuildID(buildID string) ClientOption {
+	return func(o *clientOptions) {
+		o.buildID = buildID
+	}
+}
+
+// WithMatcher ranks search results with matcher instead of the default
+// SubstringMatcher; nil keeps the default
+func WithMatcher(matcher Matcher) ClientOption {
+	return func(o *clientOptions) {
+		if matcher != nil {
+			o.matcher = matcher
+		}
+	}
+}
+
+// WithSupportListRetry sets the retry policy for the support card list fetch
+func WithSupportListRetry(policy RetryPolicy) ClientOption {
+	return func(o *clientOptions) {
+		o.supportListRetry = policy
+	}
+}
+
+// WithHTTPDoer sends requests through doer instead of the default client.
+// The configured headers are still set; the request timeout and the
+// package's concurrent search limit are left to doer.
+func WithHTTPDoer(doer HTTPDoer) ClientOption {
+	return func(o *clientOptions) {
+		o.doer = doer
+	}
+}
+
+// newClientOptions applies the given options over the defaults
+func newClientOptions(baseURL string, opts []ClientOption) *clientOptions {
+	options := &clientOptions{
+		baseURL:   baseURL,
+		userAgent: DefaultUserAgent,
+		headers:   make(map[string]string),
+		ttlJitter: DefaultTTLJitter,
+		matcher:   SubstringMatcher{},
+
+		supportListRetry: DefaultSupportListRetry(),
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	return options
+}
+
+// newHTTPClient builds an http.Client that applies the configured headers
+// and shares the package's concurrent search limit, or wraps the configured
+// doer so it applies the headers
+func (o *clientOptions) newHTTPClient(timeout time.Duration) HTTPDoer {
+	if o.doer != nil {
+		return &headerDoer{doer: o.doer, userAgent: o.userAgent, headers: o.headers}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &headerTransport{
+			base:      &limitTransport{base: http.DefaultTransport, limiter: searches},
+			userAgent: o.userAgent,
+			headers:   o.headers,
+		},
+	}
+}
+
+// headerTransport is a RoundTripper that sets the User-Agent and extra headers
+type headerTransport struct {
+	base      http.RoundTripper
+	userAgent string
+	headers   map[string]string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(withHeaders(req, t.userAgent, t.headers))
+}
+
+// headerDoer is an HTTPDoer that sets the User-Agent and extra headers
+type headerDoer struct {
+	doer      HTTPDoer
+	userAgent string
+	headers   map[string]string
+}
+
+// Do implements HTTPDoer
+func (d *headerDoer) Do(req *http.Request) (*http.Response, error) {
+	return d.doer.Do(withHeaders(req, d.userAgent, d.headers))
+}
+
+// withHeaders returns a copy of req with the User-Agent and extra headers
+// set; the caller's request must not be modified
+func withHeaders(req *http.Request, userAgent string, headers map[string]string) *http.Request {
+	req = req.Clone(req.Context())
+
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req
+}
+
+// closeIdleConnections closes doer's idle keep-alive connections if it
+// keeps any
+func closeIdleConnections(doer HTTPDoer) {
+	if d, ok := doer.(*headerDoer); ok {
+		doer = d.doer
+	}
+	if closer, ok := doer.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// get sends a GET request for url through doer
+func get(doer HTTPDoer, url string) (*http.Response, error) {
+	return getContext(context.Background(), doer, url)
+}
+
+// getContext sends a GET request for url through doer, cancelled when ctx
+// is done
+func getContext(ctx context.Context, doer HTTPDoer, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return doer.Do(req)
+}
@@ -0,0 +1,139 @@
+package uma
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxConcurrentSearches caps outbound API requests across every
+	// client in the package
+	DefaultMaxConcurrentSearches = 8
+	// DefaultSearchQueueTimeout is how long a request waits for a free slot
+	DefaultSearchQueueTimeout = 10 * time.Second
+)
+
+// ErrSearchQueueTimeout is returned when a request waits too long for a slot
+var ErrSearchQueueTimeout = errors.New("too many searches in progress, try again shortly")
+
+// throttled reports whether err came from waiting too long for a search
+// slot. That says nothing about upstream, so such failures aren't cached.
+func throttled(err error) bool {
+	return errors.Is(err, ErrSearchQueueTimeout)
+}
+
+// searchLimiter is a semaphore shared by all clients. A slot is held from
+// sending a request until its response body is closed.
+type searchLimiter struct {
+	mu           sync.RWMutex
+	slots        chan struct{}
+	queueTimeout time.Duration
+
+	inFlight int64
+}
+
+// searches limits the package's outbound requests
+var searches = newSearchLimiter(DefaultMaxConcurrentSearches, DefaultSearchQueueTimeout)
+
+func newSearchLimiter(max int, queueTimeout time.Duration) *searchLimiter {
+	l := &searchLimiter{}
+	l.configure(max, queueTimeout)
+	return l
+}
+
+// configure replaces the limit. Requests already holding a slot release it
+// to the semaphore they acquired it from.
+func (l *searchLimiter) configure(max int, queueTimeout time.Duration) {
+	if max <= 0 {
+		max = DefaultMaxConcurrentSearches
+	}
+	if queueTimeout <= 0 {
+		queueTimeout = DefaultSearchQueueTimeout
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.slots = make(chan struct{}, max)
+	l.queueTimeout = queueTimeout
+}
+
+// acquire waits for a slot until ctx is done or the queue timeout passes,
+// returning the function that gives the slot back
+func (l *searchLimiter) acquire(ctx context.Context) (func(), error) {
+	l.mu.RLock()
+	slots, queueTimeout := l.slots, l.queueTimeout
+	l.mu.RUnlock()
+
+	timer := time.NewTimer(queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, ErrSearchQueueTimeout
+	}
+
+	atomic.AddInt64(&l.inFlight, 1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			atomic.AddInt64(&l.inFlight, -1)
+			<-slots
+		})
+	}, nil
+}
+
+// SetMaxConcurrentSearches sets how many API requests may be in flight at
+// once across all clients, and how long excess requests queue before
+// failing with ErrSearchQueueTimeout. Values of 0 or less keep the defaults.
+func SetMaxConcurrentSearches(max int, queueTimeout time.Duration) {
+	searches.configure(max, queueTimeout)
+}
+
+// InFlightSearches returns the number of API requests currently holding a slot
+func InFlightSearches() int {
+	return int(atomic.LoadInt64(&searches.inFlight))
+}
+
+// limitTransport is a RoundTripper that takes a search slot per request
+type limitTransport struct {
+	base    http.RoundTripper
+	limiter *searchLimiter
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	release, err := t.limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releasingBody gives the search slot back when the body is closed
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements io.Closer
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.release()
+	return err
+}
@@ -0,0 +1,343 @@
+package uma
+
+import (
+	"sort"
+	"strings"
+)
+
+// Match tiers returned by RankingMatcher, best first. A score of zero means
+// no match. Within a tier, earlier names in SearchNames score slightly
+// higher, so a character's English name beats its Japanese one.
+const (
+	ScoreExact    = 100
+	ScorePrefix   = 80
+	ScoreContains = 60
+	ScoreAllWords = 40
+	ScoreAnyWord  = 20
+	ScoreFuzzy    = 10
+)
+
+// minWordLength is the shortest query word used for word matching, so
+// particles like "of" don't match everything
+const minWordLength = 3
+
+// Indexable is anything search can rank
+type Indexable interface {
+	// SearchNames returns the names a query may match, most important first
+	SearchNames() []string
+}
+
+// Matcher scores how well a candidate matches a query. It returns zero for
+// no match, otherwise a higher score for a better match, along with a short
+// reason such as "prefix".
+type Matcher interface {
+	Score(query string, candidate Indexable) (int, string)
+}
+
+// SearchNames implements Indexable
+func (c Character) SearchNames() []string {
+	return []string{c.NameEn, c.NameJp}
+}
+
+// SearchNames implements Indexable
+func (c SupportCard) SearchNames() []string {
+	return []string{c.TitleEn, c.Title, c.Gametora}
+}
+
+// SearchNames implements Indexable
+func (c *SimplifiedSupportCard) SearchNames() []string {
+	return []string{c.URLName}
+}
+
+// SubstringMatcher is the clients' default matching, by substrings of a
+// candidate's names with word matches as a fallback:
+//
+//   - Characters rank an exact English name first, then an English name
+//     containing the query, a Japanese name containing it, and finally any
+//     query word in either name.
+//   - Support cards match when a title or Gametora name contains the query,
+//     treating spaces and hyphens alike, or contains every word of a
+//     multi-word query.
+//   - Anything else, such as Gametora's cards, matches when one of its names
+//     contains every query word longer than two letters.
+type SubstringMatcher struct{}
+
+// Score implements Matcher
+func (SubstringMatcher) Score(query string, candidate Indexable) (int, string) {
+	query = strings.ToLower(query)
+	switch candidate := candidate.(type) {
+	case Character:
+		return scoreCharacterNames(query, candidate)
+	case SupportCard:
+		return scoreSupportCardTitles(query, candidate)
+	default:
+		return scoreEveryWord(query, candidate.SearchNames())
+	}
+}
+
+// scoreCharacterNames scores a lowercased query against a character's names
+func scoreCharacterNames(query string, character Character) (int, string) {
+	nameEn := strings.ToLower(character.NameEn)
+	nameJp := strings.ToLower(character.NameJp)
+
+	switch {
+	case nameEn == query:
+		return ScoreExact, "exact"
+	case strings.Contains(nameEn, query):
+		return ScoreContains, "contains"
+	case strings.Contains(nameJp, query):
+		return ScoreContains - 1, "contains"
+	}
+
+	for _, word := range strings.Fields(query) {
+		if strings.Contains(nameEn, word) || strings.Contains(nameJp, word) {
+			return ScoreAnyWord, "some words"
+		}
+	}
+	return 0, ""
+}
+
+// scoreSupportCardTitles scores a lowercased query against a support card's
+// titles and Gametora name
+func scoreSupportCardTitles(query string, card SupportCard) (int, string) {
+	gametora := strings.ToLower(card.Gametora)
+	names := []string{strings.ToLower(card.TitleEn), strings.ToLower(card.Title), gametora}
+
+	for _, name := range names {
+		if strings.Contains(name, query) {
+			return ScoreContains, "contains"
+		}
+	}
+
+	// Spaced queries against the hyphenated Gametora name
+	if strings.Contains(query, " ") {
+		if strings.Contains(gametora, strings.ReplaceAll(query, " ", "-")) ||
+			strings.Contains(strings.ReplaceAll(gametora, "-", " "), query) {
+			return ScoreContains, "contains"
+		}
+	}
+
+	words := strings.Fields(query)
+	if len(words) < 2 {
+		return 0, ""
+	}
+	for _, word := range words {
+		found := false
+		for _, name := range names {
+			if strings.Contains(name, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, ""
+		}
+	}
+	return ScoreAllWords, "all words"
+}
+
+// scoreEveryWord scores a lowercased query against names, matching a name
+// that contains every query word longer than two letters
+func scoreEveryWord(query string, names []string) (int, string) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return 0, ""
+	}
+
+	for _, name := range names {
+		name = strings.ToLower(name)
+		matched := true
+		for _, word := range words {
+			if len(word) > 2 && !strings.Contains(name, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return ScoreAllWords, "all words"
+		}
+	}
+	return 0, ""
+}
+
+// RankingMatcher ranks by exact, prefix, substring, then word matches.
+// Hyphens and underscores count as spaces, so "special week" matches the
+// URL name "10001-special-week".
+type RankingMatcher struct{}
+
+// Score implements Matcher
+func (RankingMatcher) Score(query string, candidate Indexable) (int, string) {
+	query = normalizeSearchText(query)
+	if query == "" {
+		return 0, ""
+	}
+
+	best, reason := 0, ""
+	for i, name := range candidate.SearchNames() {
+		score, why := scoreName(query, normalizeSearchText(name))
+		if score == 0 {
+			continue
+		}
+		if score -= min(i, 9); score > best {
+			best, reason = score, why
+		}
+	}
+	return best, reason
+}
+
+// scoreName scores a normalized query against one normalized name
+func scoreName(query, name string) (int, string) {
+	switch {
+	case name == "":
+		return 0, ""
+	case name == query:
+		return ScoreExact, "exact"
+	case strings.HasPrefix(name, query):
+		return ScorePrefix, "prefix"
+	case strings.Contains(name, query):
+		return ScoreContains, "contains"
+	}
+
+	words := searchWords(query)
+	if len(words) == 0 {
+		return 0, ""
+	}
+
+	matched := 0
+	for _, word := range words {
+		if strings.Contains(name, word) {
+			matched++
+		}
+	}
+
+	switch {
+	case matched == len(words):
+		return ScoreAllWords, "all words"
+	case matched > 0:
+		return ScoreAnyWord, "some words"
+	default:
+		return 0, ""
+	}
+}
+
+// FuzzyMatcher extends RankingMatcher with typo tolerance: when nothing
+// matches literally, a query word within MaxDistance edits of a name word
+// still matches, scoring below every literal tier.
+type FuzzyMatcher struct {
+	MaxDistance int
+}
+
+// Score implements Matcher
+func (m FuzzyMatcher) Score(query string, candidate Indexable) (int, string) {
+	if score, reason := (RankingMatcher{}).Score(query, candidate); score > 0 {
+		return score, reason
+	}
+
+	maxDistance := m.MaxDistance
+	if maxDistance <= 0 {
+		maxDistance = 2
+	}
+
+	best := -1
+	for _, queryWord := range searchWords(normalizeSearchText(query)) {
+		for _, name := range candidate.SearchNames() {
+			for _, nameWord := range strings.Fields(normalizeSearchText(name)) {
+				distance := levenshtein(queryWord, nameWord)
+				if distance <= maxDistance && (best < 0 || distance < best) {
+					best = distance
+				}
+			}
+		}
+	}
+
+	if best < 0 {
+		return 0, ""
+	}
+	return ScoreFuzzy - best, "fuzzy"
+}
+
+// normalizeSearchText lowercases text and treats separators as spaces
+func normalizeSearchText(text string) string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("-", " ", "_", " ").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// searchWords returns the query words long enough to match on their own
+func searchWords(query string) []string {
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if len(word) >= minWordLength {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// RankedMatch is a candidate that matched a query
+type RankedMatch[T Indexable] struct {
+	Item   T
+	Score  int
+	Reason string
+}
+
+// RankMatches scores every candidate and returns the matches best first,
+// keeping input order for ties. Partial matches, below ScoreAllWords, are
+// only returned when no candidate matched every query word.
+func RankMatches[T Indexable](matcher Matcher, query string, candidates []T) []RankedMatch[T] {
+	if matcher == nil {
+		matcher = RankingMatcher{}
+	}
+
+	var matches []RankedMatch[T]
+	strong := false
+	for _, candidate := range candidates {
+		score, reason := matcher.Score(query, candidate)
+		if score <= 0 {
+			continue
+		}
+		if score >= ScoreAllWords {
+			strong = true
+		}
+		matches = append(matches, RankedMatch[T]{Item: candidate, Score: score, Reason: reason})
+	}
+
+	if strong {
+		kept := matches[:0]
+		for _, match := range matches {
+			if match.Score >= ScoreAllWords {
+				kept = append(kept, match)
+			}
+		}
+		matches = kept
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
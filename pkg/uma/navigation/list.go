@@ -0,0 +1,182 @@
+package navigation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// SupportCardListState tracks the current page of a support card list
+type SupportCardListState struct {
+	SupportCards []*uma.SimplifiedSupportCard
+	Filter       uma.SupportCardFilter
+	Page         int
+	PageSize     int
+	MessageID    string
+	ChannelID    string
+
+	// Removes the state once the list expires
+	expiry *time.Timer
+}
+
+// DefaultSupportCardListExpiry is how long a list keeps responding to paging
+// reactions after it is posted
+const DefaultSupportCardListExpiry = 15 * time.Minute
+
+// SupportCardListManager manages page navigation for support card list embeds
+type SupportCardListManager struct {
+	activeLists map[string]*SupportCardListState
+	expiry      time.Duration
+	mutex       sync.RWMutex
+}
+
+var supportCardListManager = &SupportCardListManager{
+	activeLists: make(map[string]*SupportCardListState),
+	expiry:      DefaultSupportCardListExpiry,
+}
+
+// GetSupportCardListManager returns the global support card list manager instance
+func GetSupportCardListManager() *SupportCardListManager {
+	return supportCardListManager
+}
+
+// HandleListReaction handles reaction events for support card list paging
+func (slm *SupportCardListManager) HandleListReaction(s *discordgo.Session, r *discordgo.MessageReactionAdd) {
+	// Only handle reactions from the bot's own messages
+	if r.UserID == s.State.User.ID {
+		return
+	}
+
+	// Check if this is a navigation reaction
+	reaction := r.Emoji.Name
+	if reaction != "⬅️" && reaction != "➡️" {
+		return
+	}
+
+	// Get list state
+	slm.mutex.Lock()
+	state, exists := slm.activeLists[r.MessageID]
+	if !exists {
+		slm.mutex.Unlock()
+		return
+	}
+
+	// Handle navigation, wrapping around at either end
+	pages := uma.PageCount(len(state.SupportCards), state.PageSize)
+	switch reaction {
+	case "⬅️":
+		state.Page = (state.Page - 1 + pages) % pages
+	case "➡️":
+		state.Page = (state.Page + 1) % pages
+	}
+	embed := slm.createListEmbed(state)
+	slm.mutex.Unlock()
+
+	// Update the message
+	_, err := s.ChannelMessageEditEmbed(state.ChannelID, state.MessageID, embed)
+	if err != nil {
+		fmt.Printf("Error updating support card list embed: %v\n", err)
+		return
+	}
+
+	// Remove the user's reaction
+	s.MessageReactionRemove(state.ChannelID, state.MessageID, reaction, r.UserID)
+}
+
+// RegisterSupportCardList registers a new paged support card list. It is
+// removed once it expires, after which its reactions are ignored.
+func (slm *SupportCardListManager) RegisterSupportCardList(messageID string, supportCards []*uma.SimplifiedSupportCard, filter uma.SupportCardFilter, pageSize int, channelID string) {
+	slm.mutex.Lock()
+	defer slm.mutex.Unlock()
+
+	if previous, exists := slm.activeLists[messageID]; exists {
+		previous.expiry.Stop()
+	}
+
+	state := &SupportCardListState{
+		SupportCards: supportCards,
+		Filter:       filter,
+		PageSize:     pageSize,
+		MessageID:    messageID,
+		ChannelID:    channelID,
+	}
+	state.expiry = time.AfterFunc(slm.expiry, func() {
+		slm.expire(state)
+	})
+	slm.activeLists[messageID] = state
+}
+
+// SetListExpiry sets how long lists registered from now on stay active
+func (slm *SupportCardListManager) SetListExpiry(expiry time.Duration) {
+	slm.mutex.Lock()
+	defer slm.mutex.Unlock()
+
+	slm.expiry = expiry
+}
+
+// HasSupportCardList reports whether a list is still active for a message
+func (slm *SupportCardListManager) HasSupportCardList(messageID string) bool {
+	slm.mutex.RLock()
+	defer slm.mutex.RUnlock()
+
+	_, exists := slm.activeLists[messageID]
+	return exists
+}
+
+// CleanupSupportCardList removes a support card list state
+func (slm *SupportCardListManager) CleanupSupportCardList(messageID string) {
+	slm.mutex.Lock()
+	defer slm.mutex.Unlock()
+
+	if state, exists := slm.activeLists[messageID]; exists {
+		state.expiry.Stop()
+		delete(slm.activeLists, messageID)
+	}
+}
+
+// expire removes state if it is still the list registered for its message
+func (slm *SupportCardListManager) expire(state *SupportCardListState) {
+	slm.mutex.Lock()
+	defer slm.mutex.Unlock()
+
+	if slm.activeLists[state.MessageID] == state {
+		delete(slm.activeLists, state.MessageID)
+	}
+}
+
+// CreateListEmbed creates the embed for one page of a support card list
+func (slm *SupportCardListManager) CreateListEmbed(supportCards []*uma.SimplifiedSupportCard, filter uma.SupportCardFilter, page, pageSize int) *discordgo.MessageEmbed {
+	return slm.createListEmbed(&SupportCardListState{
+		SupportCards: supportCards,
+		Filter:       filter,
+		Page:         page,
+		PageSize:     pageSize,
+	})
+}
+
+// createListEmbed creates the embed for the state's current page
+func (slm *SupportCardListManager) createListEmbed(state *SupportCardListState) *discordgo.MessageEmbed {
+	pageCards, page := uma.PageSupportCards(state.SupportCards, state.Page, state.PageSize)
+	pages := uma.PageCount(len(state.SupportCards), state.PageSize)
+
+	var description strings.Builder
+	if len(pageCards) == 0 {
+		description.WriteString("No support cards match this filter.")
+	}
+	for _, card := range pageCards {
+		description.WriteString(fmt.Sprintf("**%s** - %s · %s\n", card.NameJp, uma.GetRarityText(card.Rarity), card.Type))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📚 Support Cards (%s)", state.Filter),
+		Description: description.String(),
+		Color:       0x7289DA,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Data from Gametora API | Page %d of %d | %d cards", page+1, pages, len(state.SupportCards)),
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package uma
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy retries a failing request with exponential backoff and jitter
+type RetryPolicy struct {
+	Attempts  int           // total tries, including the first; values below 1 mean 1
+	BaseDelay time.Duration // wait before the first retry, doubled after each one
+	Jitter    float64       // ± fraction applied to each wait, as with JitterTTL
+}
+
+// DefaultSupportListRetry is a small budget for the support card list: three
+// tries within about a second, so searches aren't held up for long
+func DefaultSupportListRetry() RetryPolicy {
+	return RetryPolicy{
+		Attempts:  3,
+		BaseDelay: 250 * time.Millisecond,
+		Jitter:    0.5,
+	}
+}
+
+// permanentError marks a failure that retrying won't fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent wraps err so Do returns it without retrying
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// retryableStatus reports whether an HTTP status is worth retrying
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// Do calls fn until it succeeds, returns a permanent error, or the attempts
+// run out. The last error is returned unwrapped.
+func (p RetryPolicy) Do(fn func() error) error {
+	return p.DoContext(context.Background(), fn)
+}
+
+// DoContext is Do, giving up without further tries once ctx is done
+func (p RetryPolicy) DoContext(ctx context.Context, fn func() error) error {
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := p.BaseDelay
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if attempt < attempts && delay > 0 {
+			select {
+			case <-time.After(JitterTTL(delay, p.Jitter)):
+			case <-ctx.Done():
+				return err
+			}
+			delay *= 2
+		}
+	}
+	return err
+}
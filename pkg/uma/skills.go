@@ -0,0 +1,208 @@
+package uma
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// skillIconURLPattern is where Gametora serves skill icons, keyed by icon ID
+const skillIconURLPattern = "https://gametora.com/images/umamusume/skill_icons/utx_ico_skill_%d.png"
+
+// untypedSkillGroup collects skills Gametora gives no type tags
+const untypedSkillGroup = "other"
+
+// Skill is a support card hint or event skill
+type Skill struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Types  []string `json:"types"`
+	IconID int      `json:"icon_id"`
+}
+
+// IconURL returns the skill's Gametora icon, or "" if it has none
+func (s Skill) IconURL() string {
+	return SkillIconURL(s.IconID)
+}
+
+// SkillIconURL maps a Gametora skill icon ID to its image URL, returning ""
+// for a missing ID
+func SkillIconURL(iconID int) string {
+	if iconID <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(skillIconURLPattern, iconID)
+}
+
+// SkillGroup is the skills sharing a primary type tag
+type SkillGroup struct {
+	Type   string
+	Skills []Skill
+}
+
+// GroupSkillsByType groups skills by their first type tag. Groups are
+// sorted by type with untyped skills last; skills keep their input order.
+func GroupSkillsByType(skills []Skill) []SkillGroup {
+	index := make(map[string]int)
+	var groups []SkillGroup
+	for _, skill := range skills {
+		skillType := untypedSkillGroup
+		if len(skill.Types) > 0 && skill.Types[0] != "" {
+			skillType = skill.Types[0]
+		}
+
+		i, ok := index[skillType]
+		if !ok {
+			i = len(groups)
+			index[skillType] = i
+			groups = append(groups, SkillGroup{Type: skillType})
+		}
+		groups[i].Skills = append(groups[i].Skills, skill)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (groups[i].Type == untypedSkillGroup) != (groups[j].Type == untypedSkillGroup) {
+			return groups[j].Type == untypedSkillGroup
+		}
+		return groups[i].Type < groups[j].Type
+	})
+	return groups
+}
+
+// skillTypeNames maps Gametora's skill type tags to display names. Tags
+// not listed are shown as they are.
+var skillTypeNames = map[string]string{
+	untypedSkillGroup: "Other",
+	"sp":              "Speed",
+	"ac":              "Acceleration",
+	"re":              "Recovery",
+	"nav":             "Navigation",
+	"vis":             "Vision",
+	"gt":              "Gate",
+	"dbf":             "Debuff",
+}
+
+// SkillTypeName returns the display name of a skill type tag, or the tag
+// itself when it has none
+func SkillTypeName(skillType string) string {
+	if name, ok := skillTypeNames[skillType]; ok {
+		return name
+	}
+	return skillType
+}
+
+// FormatSkillGroups renders groups one per line, each skill linked to its
+// icon, e.g. "**Speed** [Corner Adept](https://…)". Output is capped at
+// limit characters: the group that crosses the limit is cut after the last
+// skill that fits, and a count of the skills left out ends the output.
+func FormatSkillGroups(groups []SkillGroup, limit int) string {
+	total := 0
+	for _, group := range groups {
+		total += len(group.Skills)
+	}
+
+	// Room kept for the trailer in case a later skill doesn't fit
+	reserve := len(skillsOmittedNote(total)) + 1
+
+	var b strings.Builder
+	shown := 0
+	for i, group := range groups {
+		separator := ""
+		if b.Len() > 0 {
+			separator = "\n"
+		}
+
+		room := limit
+		if i < len(groups)-1 {
+			room -= reserve
+		}
+
+		line := formatSkillGroupLine(group, len(group.Skills))
+		if limit <= 0 || b.Len()+len(separator)+len(line) <= room {
+			b.WriteString(separator + line)
+			shown += len(group.Skills)
+			continue
+		}
+
+		// Fit as much of this group as the limit allows, then stop
+		if line, n := fitSkillGroupLine(group, limit-reserve-b.Len()-len(separator)); n > 0 {
+			b.WriteString(separator + line)
+			shown += n
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(skillsOmittedNote(total - shown))
+		return b.String()
+	}
+	return b.String()
+}
+
+// fitSkillGroupLine renders the longest prefix of group's skills that fits
+// in room bytes, returning the line and how many skills it holds. A skill
+// too long for the line on its own is shown without its link and, if need
+// be, with its name cut short.
+func fitSkillGroupLine(group SkillGroup, room int) (string, int) {
+	for n := len(group.Skills) - 1; n > 0; n-- {
+		if line := formatSkillGroupLine(group, n); len(line) <= room {
+			return line, n
+		}
+	}
+
+	header := fmt.Sprintf("**%s** ", SkillTypeName(group.Type))
+	nameRoom := room - len(header)
+	if len(group.Skills) == 0 || nameRoom <= len("…") {
+		return "", 0
+	}
+	return header + truncateSkillName(group.Skills[0].Name, nameRoom), 1
+}
+
+// truncateSkillName cuts name to at most limit bytes, marking the cut
+func truncateSkillName(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+
+	cut := limit - len("…")
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return name[:cut] + "…"
+}
+
+// formatSkillGroupLine renders a line of the group's first n skills
+func formatSkillGroupLine(group SkillGroup, n int) string {
+	names := make([]string, 0, n)
+	for _, skill := range group.Skills[:n] {
+		if url := skill.IconURL(); url != "" {
+			names = append(names, fmt.Sprintf("[%s](%s)", skill.Name, url))
+		} else {
+			names = append(names, skill.Name)
+		}
+	}
+	return fmt.Sprintf("**%s** %s", SkillTypeName(group.Type), strings.Join(names, ", "))
+}
+
+// skillsOmittedNote is the trailer for skills cut by the length limit
+func skillsOmittedNote(n int) string {
+	return fmt.Sprintf("…and %d more", n)
+}
+
+// HintSkillList returns the card's hint skills
+func (c *SimplifiedSupportCard) HintSkillList() []Skill {
+	skills := make([]Skill, 0, len(c.Hints.HintSkills))
+	for _, hint := range c.Hints.HintSkills {
+		skills = append(skills, Skill{ID: hint.ID, Name: hint.NameEn, Types: hint.Type, IconID: hint.IconID})
+	}
+	return skills
+}
+
+// EventSkillList returns the card's event skills
+func (c *SimplifiedSupportCard) EventSkillList() []Skill {
+	skills := make([]Skill, 0, len(c.EventSkills))
+	for _, event := range c.EventSkills {
+		skills = append(skills, Skill{ID: event.ID, Name: event.NameEn, Types: event.Type, IconID: event.IconID})
+	}
+	return skills
+}
@@ -105,8 +105,10 @@ type SimplifiedSupportCard struct {
 // SimplifiedGametoraSearchResult represents the result of a simplified Gametora search
 type SimplifiedGametoraSearchResult struct {
 	Found        bool
+	Reason       SearchReason // why nothing was found; empty when Found
 	SupportCard  *SimplifiedSupportCard
 	SupportCards []*SimplifiedSupportCard // Multiple cards for the same character
 	Error        error
 	Query        string
+	Stale        bool `json:"-"` // Served from an expired cache entry
 }
@@ -0,0 +1,194 @@
+package uma
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultSupportListPageSize is the number of cards shown per list page
+const DefaultSupportListPageSize = 10
+
+// supportTypeAliases maps user-facing type names to Gametora support types
+var supportTypeAliases = map[string]string{
+	"speed":        "speed",
+	"stamina":      "stamina",
+	"power":        "power",
+	"guts":         "guts",
+	"intelligence": "intelligence",
+	"int":          "intelligence",
+	"wit":          "intelligence",
+	"wisdom":       "intelligence",
+	"friend":       "friend",
+	"group":        "group",
+}
+
+// SupportCardFilter narrows a support card list by type and rarity.
+// Zero values match everything.
+type SupportCardFilter struct {
+	Type   string // Gametora support type, e.g. "speed"
+	Rarity int    // 1 = R, 2 = SR, 3 = SSR
+}
+
+// ParseSupportCardFilter parses optional type and rarity arguments in any
+// order, e.g. ["speed", "ssr"]
+func ParseSupportCardFilter(args []string) (SupportCardFilter, error) {
+	var filter SupportCardFilter
+	for _, arg := range args {
+		arg = strings.ToLower(strings.TrimSpace(arg))
+		if arg == "" {
+			continue
+		}
+
+		if rarity := rarityFromText(arg); rarity > 0 {
+			if filter.Rarity != 0 {
+				return SupportCardFilter{}, fmt.Errorf("rarity given more than once")
+			}
+			filter.Rarity = rarity
+			continue
+		}
+
+		if supportType, ok := supportTypeAliases[arg]; ok {
+			if filter.Type != "" {
+				return SupportCardFilter{}, fmt.Errorf("type given more than once")
+			}
+			filter.Type = supportType
+			continue
+		}
+
+		return SupportCardFilter{}, fmt.Errorf("unknown type or rarity %q", arg)
+	}
+	return filter, nil
+}
+
+// SplitSupportCardFilter separates trailing type and rarity arguments from
+// a search query, e.g. ["kitasan", "black", "ssr"] gives "kitasan black" and
+// an SSR filter. The first argument always stays in the query.
+func SplitSupportCardFilter(args []string) (string, SupportCardFilter) {
+	split := len(args)
+	var filter SupportCardFilter
+	for i := len(args) - 1; i >= 1; i-- {
+		parsed, err := ParseSupportCardFilter(args[i:])
+		if err != nil {
+			break
+		}
+		split, filter = i, parsed
+	}
+	return strings.Join(args[:split], " "), filter
+}
+
+// rarityFromText converts SSR/SR/R to the Gametora rarity number, or 0
+func rarityFromText(text string) int {
+	switch strings.ToUpper(text) {
+	case "SSR":
+		return 3
+	case "SR":
+		return 2
+	case "R":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Matches reports whether the card passes the filter
+func (f SupportCardFilter) Matches(card *SimplifiedSupportCard) bool {
+	if f.Type != "" && !strings.EqualFold(card.Type, f.Type) {
+		return false
+	}
+	if f.Rarity != 0 && card.Rarity != f.Rarity {
+		return false
+	}
+	return true
+}
+
+// String describes the filter for embed titles
+func (f SupportCardFilter) String() string {
+	var parts []string
+	if f.Rarity != 0 {
+		parts = append(parts, GetRarityText(f.Rarity))
+	}
+	if f.Type != "" {
+		parts = append(parts, f.Type)
+	}
+	if len(parts) == 0 {
+		return "all"
+	}
+	return strings.Join(parts, " ")
+}
+
+// FilterSupportCards returns the cards matching the filter, highest rarity
+// first and otherwise in their original order
+func FilterSupportCards(cards []*SimplifiedSupportCard, filter SupportCardFilter) []*SimplifiedSupportCard {
+	filtered := make([]*SimplifiedSupportCard, 0, len(cards))
+	for _, card := range cards {
+		if filter.Matches(card) {
+			filtered = append(filtered, card)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Rarity > filtered[j].Rarity
+	})
+	return filtered
+}
+
+// ApplyFilter returns the result narrowed to the versions passing the
+// filter, keeping their ranking. A search that found cards none of which
+// pass is reported as FilteredOut.
+func (r *SimplifiedGametoraSearchResult) ApplyFilter(filter SupportCardFilter) *SimplifiedGametoraSearchResult {
+	if !r.Found || filter == (SupportCardFilter{}) {
+		return r
+	}
+
+	filtered := *r
+	filtered.SupportCards = nil
+	for _, card := range r.SupportCards {
+		if filter.Matches(card) {
+			filtered.SupportCards = append(filtered.SupportCards, card)
+		}
+	}
+	if len(filtered.SupportCards) == 0 {
+		filtered.Found = false
+		filtered.Reason = FilteredOut
+		filtered.SupportCard = nil
+		return &filtered
+	}
+	filtered.SupportCard = filtered.SupportCards[0]
+	return &filtered
+}
+
+// PageCount returns the number of pages needed for total items; an empty
+// list still has one (empty) page
+func PageCount(total, pageSize int) int {
+	if pageSize <= 0 {
+		pageSize = DefaultSupportListPageSize
+	}
+	if total <= 0 {
+		return 1
+	}
+	return (total + pageSize - 1) / pageSize
+}
+
+// PageSupportCards returns the cards on a zero-based page, clamping the page
+// into range. It also returns the clamped page.
+func PageSupportCards(cards []*SimplifiedSupportCard, page, pageSize int) ([]*SimplifiedSupportCard, int) {
+	if pageSize <= 0 {
+		pageSize = DefaultSupportListPageSize
+	}
+
+	pages := PageCount(len(cards), pageSize)
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+
+	start := page * pageSize
+	end := start + pageSize
+	if end > len(cards) {
+		end = len(cards)
+	}
+	return cards[start:end], page
+}
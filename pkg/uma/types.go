@@ -18,12 +18,37 @@ type Character struct {
 	ThumbImg        string `json:"thumb_img"`
 }
 
+// SearchReason says why a search found nothing. It is empty when the
+// search succeeded.
+type SearchReason string
+
+const (
+	NotFound      SearchReason = "not_found"      // nothing matched the query
+	FilteredOut   SearchReason = "filtered_out"   // matches exist but a filter excluded them all
+	UpstreamError SearchReason = "upstream_error" // the API could not be reached or answered badly
+)
+
+// FailureReason returns the reason for a failed search, deriving one from
+// err for results cached before reasons were recorded
+func FailureReason(reason SearchReason, err error) SearchReason {
+	switch {
+	case reason != "":
+		return reason
+	case err != nil:
+		return UpstreamError
+	default:
+		return NotFound
+	}
+}
+
 // CharacterSearchResult represents the result of a character search
 type CharacterSearchResult struct {
 	Found     bool
+	Reason    SearchReason // why nothing was found; empty when Found
 	Character *Character
 	Error     error
 	Query     string
+	Stale     bool `json:"-"` // Served from an expired cache entry
 }
 
 // APIResponse represents the response from umapyoi.net API
@@ -60,6 +85,7 @@ type CharacterImagesResult struct {
 	Images  []CharacterImageCategory
 	Error   error
 	CharaID int
+	Stale   bool `json:"-"` // Served from an expired cache entry
 }
 
 // SupportCard represents a support card from the API
@@ -79,10 +105,17 @@ type SupportCard struct {
 // SupportCardSearchResult represents the result of a support card search
 type SupportCardSearchResult struct {
 	Found        bool
+	Reason       SearchReason // why nothing was found; empty when Found
 	SupportCard  *SupportCard
 	SupportCards []SupportCard // Multiple cards for the same character
 	Error        error
 	Query        string
+	Stale        bool `json:"-"` // Served from an expired cache entry
+
+	// Versions whose details could not be fetched; the search still succeeds
+	// with the rest
+	SkippedCount int
+	Errors       []error `json:"-"`
 }
 
 // SupportCardListResult represents the result of fetching support card list
@@ -90,4 +123,6 @@ type SupportCardListResult struct {
 	Found        bool
 	SupportCards []SupportCard
 	Error        error
+	Stale        bool      `json:"-"` // Served from an expired cache entry or the last good list
+	FetchedAt    time.Time `json:"-"` // When the list was fetched from upstream
 }
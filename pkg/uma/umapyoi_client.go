@@ -1,7 +1,9 @@
 package uma
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -12,27 +14,67 @@ import (
 
 // Client represents the Uma Musume API client
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
-	cache      map[string]*CacheEntry
-	cacheMutex sync.RWMutex
-	cacheTTL   time.Duration
+	baseURL     string
+	httpClient  HTTPDoer
+	cache       map[string]*CacheEntry
+	cacheMutex  sync.RWMutex
+	cacheTTL    time.Duration
+	cacheJitter float64
+	matcher     Matcher
+
+	// The support list changes rarely, so the last good copy is kept to
+	// serve when a refresh fails
+	supportListRetry RetryPolicy
+	lastSupportList  *SupportCardListResult
+	supportListMutex sync.RWMutex
 }
 
 // NewClient creates a new Uma Musume API client
-func NewClient() *Client {
+func NewClient(opts ...ClientOption) *Client {
+	options := newClientOptions("https://umapyoi.net/api", opts)
+
+	cacheTTL := 5 * time.Minute // Cache for 5 minutes
+	if options.cacheTTL > 0 {
+		cacheTTL = options.cacheTTL
+	}
+
 	return &Client{
-		baseURL: "https://umapyoi.net/api",
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		cache:    make(map[string]*CacheEntry),
-		cacheTTL: 5 * time.Minute, // Cache for 5 minutes
+		baseURL:     options.baseURL,
+		httpClient:  options.newHTTPClient(10 * time.Second),
+		cache:       make(map[string]*CacheEntry),
+		cacheTTL:    cacheTTL,
+		cacheJitter: options.ttlJitter,
+		matcher:     options.matcher,
+
+		supportListRetry: options.supportListRetry,
 	}
 }
 
+// Close drops the cache, the last good support list and idle connections.
+// The client runs no background work of its own. A client is meant to live
+// for the whole process and be closed on shutdown; one used after Close
+// starts again from an empty cache. Closing twice is harmless.
+func (c *Client) Close() error {
+	c.cacheMutex.Lock()
+	c.cache = make(map[string]*CacheEntry)
+	c.cacheMutex.Unlock()
+
+	c.supportListMutex.Lock()
+	c.lastSupportList = nil
+	c.supportListMutex.Unlock()
+
+	closeIdleConnections(c.httpClient)
+	return nil
+}
+
 // SearchCharacter searches for a character by name
 func (c *Client) SearchCharacter(query string) *CharacterSearchResult {
+	return c.SearchCharacterContext(context.Background(), query)
+}
+
+// SearchCharacterContext is SearchCharacter, abandoning the request when ctx
+// is done. Abandoned searches aren't cached.
+func (c *Client) SearchCharacterContext(ctx context.Context, query string) *CharacterSearchResult {
 	// Check cache first
 	cacheKey := fmt.Sprintf("char_search_%s", strings.ToLower(query))
 	if cached := c.getFromCache(cacheKey); cached != nil {
@@ -43,36 +85,41 @@ func (c *Client) SearchCharacter(query string) *CharacterSearchResult {
 
 	// Make API request
 	url := fmt.Sprintf("%s/v1/character/list", c.baseURL)
-	resp, err := c.httpClient.Get(url)
+	resp, err := getContext(ctx, c.httpClient, url)
 	if err != nil {
 		result := &CharacterSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to fetch character data: %v", err),
-			Query: query,
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("failed to fetch character data: %w", err),
+			Query:  query,
+		}
+		if !throttled(err) {
+			c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		}
-		c.setCache(cacheKey, result)
 		return result
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		result := &CharacterSearchResult{
-			Found: false,
-			Error: fmt.Errorf("API returned status code: %d", resp.StatusCode),
-			Query: query,
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("API returned status code: %d", resp.StatusCode),
+			Query:  query,
 		}
-		c.setCache(cacheKey, result)
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		return result
 	}
 
 	var apiResp APIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
 		result := &CharacterSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to decode API response: %v", err),
-			Query: query,
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("failed to decode API response: %v", err),
+			Query:  query,
 		}
-		c.setCache(cacheKey, result)
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		return result
 	}
 
@@ -84,49 +131,21 @@ func (c *Client) SearchCharacter(query string) *CharacterSearchResult {
 		Character: bestMatch,
 		Query:     query,
 	}
+	if bestMatch == nil {
+		result.Reason = NotFound
+	}
 
-	c.setCache(cacheKey, result)
+	c.setCacheUnlessCancelled(ctx, cacheKey, result)
 	return result
 }
 
 // findBestMatch finds the best character match for the given query
 func (c *Client) findBestMatch(query string, characters []Character) *Character {
-	query = strings.ToLower(query)
-
-	// First, try exact match with English name
-	for _, char := range characters {
-		if strings.ToLower(char.NameEn) == query {
-			return &char
-		}
-	}
-
-	// Then, try contains match with English name
-	for _, char := range characters {
-		if strings.Contains(strings.ToLower(char.NameEn), query) {
-			return &char
-		}
-	}
-
-	// Try Japanese name
-	for _, char := range characters {
-		if strings.Contains(strings.ToLower(char.NameJp), query) {
-			return &char
-		}
-	}
-
-	// Finally, try partial word match
-	queryWords := strings.Fields(query)
-	for _, char := range characters {
-		charNameEn := strings.ToLower(char.NameEn)
-		charNameJp := strings.ToLower(char.NameJp)
-		for _, word := range queryWords {
-			if strings.Contains(charNameEn, word) || strings.Contains(charNameJp, word) {
-				return &char
-			}
-		}
+	matches := RankMatches(c.matcher, query, characters)
+	if len(matches) == 0 {
+		return nil
 	}
-
-	return nil
+	return &matches[0].Item
 }
 
 // GetCharacterImages fetches all images for a character by ID
@@ -141,14 +160,16 @@ func (c *Client) GetCharacterImages(charaID int) *CharacterImagesResult {
 
 	// Make API request
 	url := fmt.Sprintf("%s/v1/character/images/%d", c.baseURL, charaID)
-	resp, err := c.httpClient.Get(url)
+	resp, err := get(c.httpClient, url)
 	if err != nil {
 		result := &CharacterImagesResult{
 			Found:   false,
-			Error:   fmt.Errorf("failed to fetch character images: %v", err),
+			Error:   fmt.Errorf("failed to fetch character images: %w", err),
 			CharaID: charaID,
 		}
-		c.setCache(cacheKey, result)
+		if !throttled(err) {
+			c.setCache(cacheKey, result)
+		}
 		return result
 	}
 	defer resp.Body.Close()
@@ -186,6 +207,12 @@ func (c *Client) GetCharacterImages(charaID int) *CharacterImagesResult {
 
 // SearchSupportCard searches for a support card by name
 func (c *Client) SearchSupportCard(query string) *SupportCardSearchResult {
+	return c.SearchSupportCardContext(context.Background(), query)
+}
+
+// SearchSupportCardContext is SearchSupportCard, abandoning the requests when
+// ctx is done. Abandoned searches aren't cached.
+func (c *Client) SearchSupportCardContext(ctx context.Context, query string) *SupportCardSearchResult {
 	// Check cache first
 	cacheKey := fmt.Sprintf("support_search_%s", strings.ToLower(query))
 	if cached := c.getFromCache(cacheKey); cached != nil {
@@ -195,14 +222,17 @@ func (c *Client) SearchSupportCard(query string) *SupportCardSearchResult {
 	}
 
 	// First, get the list of support cards
-	listResult := c.GetSupportCardList()
+	listResult := c.getSupportCardList(ctx)
 	if !listResult.Found {
 		result := &SupportCardSearchResult{
-			Found: false,
-			Error: listResult.Error,
-			Query: query,
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  listResult.Error,
+			Query:  query,
+		}
+		if !throttled(listResult.Error) {
+			c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		}
-		c.setCache(cacheKey, result)
 		return result
 	}
 
@@ -210,29 +240,45 @@ func (c *Client) SearchSupportCard(query string) *SupportCardSearchResult {
 	matches := c.findAllSupportCardMatches(query, listResult.SupportCards)
 	if len(matches) == 0 {
 		result := &SupportCardSearchResult{
-			Found: false,
-			Query: query,
+			Found:  false,
+			Reason: NotFound,
+			Query:  query,
 		}
-		c.setCache(cacheKey, result)
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		return result
 	}
 
-	// Get detailed information for all matched cards
+	// Get detailed information for all matched cards, skipping versions
+	// whose details can't be fetched
 	var detailedCards []SupportCard
+	var fetchErrors []error
 	for _, match := range matches {
-		detailedResult := c.GetSupportCard(match.ID)
+		detailedResult := c.getSupportCard(ctx, match.ID)
 		if detailedResult.Found && detailedResult.SupportCard != nil {
 			detailedCards = append(detailedCards, *detailedResult.SupportCard)
+			continue
+		}
+
+		err := detailedResult.Error
+		if err == nil {
+			err = fmt.Errorf("no details returned")
 		}
+		fetchErrors = append(fetchErrors, fmt.Errorf("support card %d: %w", match.ID, err))
 	}
 
+	// A version skipped for want of a search slot may load next time
+	cacheable := !throttled(errors.Join(fetchErrors...))
+
 	if len(detailedCards) == 0 {
 		result := &SupportCardSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to fetch detailed information for any matched cards"),
-			Query: query,
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("failed to fetch detailed information for any matched cards"),
+			Query:  query,
+		}
+		if cacheable {
+			c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		}
-		c.setCache(cacheKey, result)
 		return result
 	}
 
@@ -247,14 +293,26 @@ func (c *Client) SearchSupportCard(query string) *SupportCardSearchResult {
 		SupportCard:  &detailedCards[0], // Keep the first one for backward compatibility
 		SupportCards: detailedCards,
 		Query:        query,
+		SkippedCount: len(fetchErrors),
+		Errors:       fetchErrors,
 	}
 
-	c.setCache(cacheKey, result)
+	if cacheable {
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
+	}
 	return result
 }
 
-// GetSupportCardList fetches the list of all support cards
+// GetSupportCardList fetches the list of all support cards. Failed fetches
+// are retried with jitter; if they all fail, the last good list is served
+// marked as stale.
 func (c *Client) GetSupportCardList() *SupportCardListResult {
+	return c.getSupportCardList(context.Background())
+}
+
+// getSupportCardList is GetSupportCardList, abandoning the fetch when ctx is
+// done
+func (c *Client) getSupportCardList(ctx context.Context) *SupportCardListResult {
 	// Check cache first
 	cacheKey := "support_list"
 	if cached := c.getFromCache(cacheKey); cached != nil {
@@ -263,49 +321,86 @@ func (c *Client) GetSupportCardList() *SupportCardListResult {
 		}
 	}
 
-	// Make API request
-	url := fmt.Sprintf("%s/v1/support", c.baseURL)
-	resp, err := c.httpClient.Get(url)
+	supportCards, err := c.fetchSupportCardList(ctx)
 	if err != nil {
-		result := &SupportCardListResult{
-			Found: false,
-			Error: fmt.Errorf("failed to fetch support card list: %v", err),
-		}
-		c.setCache(cacheKey, result)
-		return result
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		result := &SupportCardListResult{
-			Found: false,
-			Error: fmt.Errorf("API returned status code: %d", resp.StatusCode),
+		result := c.staleSupportCardList()
+		if result == nil {
+			result = &SupportCardListResult{
+				Found: false,
+				Error: err,
+			}
 		}
-		c.setCache(cacheKey, result)
-		return result
-	}
-
-	var supportCards []SupportCard
-	if err := json.NewDecoder(resp.Body).Decode(&supportCards); err != nil {
-		result := &SupportCardListResult{
-			Found: false,
-			Error: fmt.Errorf("failed to decode API response: %v", err),
+		if !throttled(err) {
+			c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		}
-		c.setCache(cacheKey, result)
 		return result
 	}
 
 	result := &SupportCardListResult{
 		Found:        true,
 		SupportCards: supportCards,
+		FetchedAt:    time.Now(),
 	}
 
-	c.setCache(cacheKey, result)
+	c.supportListMutex.Lock()
+	c.lastSupportList = result
+	c.supportListMutex.Unlock()
+
+	c.setCacheUnlessCancelled(ctx, cacheKey, result)
 	return result
 }
 
+// fetchSupportCardList requests the support card list, retrying transient failures
+func (c *Client) fetchSupportCardList(ctx context.Context) ([]SupportCard, error) {
+	url := fmt.Sprintf("%s/v1/support", c.baseURL)
+
+	var supportCards []SupportCard
+	err := c.supportListRetry.DoContext(ctx, func() error {
+		resp, err := getContext(ctx, c.httpClient, url)
+		if err != nil {
+			return fmt.Errorf("failed to fetch support card list: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("API returned status code: %d", resp.StatusCode)
+			if !retryableStatus(resp.StatusCode) {
+				return permanent(err)
+			}
+			return err
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&supportCards); err != nil {
+			return permanent(fmt.Errorf("failed to decode API response: %v", err))
+		}
+		return nil
+	})
+
+	return supportCards, err
+}
+
+// staleSupportCardList returns a stale copy of the last good list, or nil if
+// none has been fetched yet
+func (c *Client) staleSupportCardList() *SupportCardListResult {
+	c.supportListMutex.RLock()
+	defer c.supportListMutex.RUnlock()
+
+	if c.lastSupportList == nil {
+		return nil
+	}
+
+	stale := *c.lastSupportList
+	stale.Stale = true
+	return &stale
+}
+
 // GetSupportCard fetches detailed information for a specific support card
 func (c *Client) GetSupportCard(supportID int) *SupportCardSearchResult {
+	return c.getSupportCard(context.Background(), supportID)
+}
+
+// getSupportCard is GetSupportCard, abandoning the request when ctx is done
+func (c *Client) getSupportCard(ctx context.Context, supportID int) *SupportCardSearchResult {
 	// Check cache first
 	cacheKey := fmt.Sprintf("support_detail_%d", supportID)
 	if cached := c.getFromCache(cacheKey); cached != nil {
@@ -316,33 +411,38 @@ func (c *Client) GetSupportCard(supportID int) *SupportCardSearchResult {
 
 	// Make API request
 	url := fmt.Sprintf("%s/v1/support/%d", c.baseURL, supportID)
-	resp, err := c.httpClient.Get(url)
+	resp, err := getContext(ctx, c.httpClient, url)
 	if err != nil {
 		result := &SupportCardSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to fetch support card details: %v", err),
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("failed to fetch support card details: %w", err),
+		}
+		if !throttled(err) {
+			c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		}
-		c.setCache(cacheKey, result)
 		return result
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		result := &SupportCardSearchResult{
-			Found: false,
-			Error: fmt.Errorf("API returned status code: %d", resp.StatusCode),
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("API returned status code: %d", resp.StatusCode),
 		}
-		c.setCache(cacheKey, result)
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		return result
 	}
 
 	var supportCard SupportCard
 	if err := json.NewDecoder(resp.Body).Decode(&supportCard); err != nil {
 		result := &SupportCardSearchResult{
-			Found: false,
-			Error: fmt.Errorf("failed to decode API response: %v", err),
+			Found:  false,
+			Reason: UpstreamError,
+			Error:  fmt.Errorf("failed to decode API response: %v", err),
 		}
-		c.setCache(cacheKey, result)
+		c.setCacheUnlessCancelled(ctx, cacheKey, result)
 		return result
 	}
 
@@ -351,72 +451,22 @@ func (c *Client) GetSupportCard(supportID int) *SupportCardSearchResult {
 		SupportCard: &supportCard,
 	}
 
-	c.setCache(cacheKey, result)
+	c.setCacheUnlessCancelled(ctx, cacheKey, result)
 	return result
 }
 
 // findAllSupportCardMatches finds all support cards that match the query,
 // grouping by character ID to find all versions of the same character's support cards.
 func (c *Client) findAllSupportCardMatches(query string, supportCards []SupportCard) []SupportCard {
-	query = strings.ToLower(query)
 	var matches []SupportCard
 	var matchedCharIDs []int
 
 	// First pass: find all cards that match the query
-	for _, card := range supportCards {
-		titleEn := strings.ToLower(card.TitleEn)
-		titleJp := strings.ToLower(card.Title)
-		gametora := strings.ToLower(card.Gametora)
-
-		// Check if any field contains the query
-		matched := false
-
-		// Direct match (highest priority)
-		if strings.Contains(titleEn, query) || strings.Contains(titleJp, query) || strings.Contains(gametora, query) {
-			matched = true
-		}
-
-		// Handle space-separated queries vs hyphen-separated gametora
-		if !matched && strings.Contains(query, " ") {
-			// Replace spaces with hyphens for gametora matching
-			queryWithHyphens := strings.ReplaceAll(query, " ", "-")
-			if strings.Contains(gametora, queryWithHyphens) {
-				matched = true
-			}
-
-			// Also try replacing hyphens with spaces in gametora
-			gametoraWithSpaces := strings.ReplaceAll(gametora, "-", " ")
-			if strings.Contains(gametoraWithSpaces, query) {
-				matched = true
-			}
-		}
-
-		// Word-by-word matching (only if no direct match found)
-		if !matched {
-			queryWords := strings.Fields(query)
-			// Only do word-by-word matching if we have multiple words
-			if len(queryWords) > 1 {
-				allWordsMatched := true
-				for _, word := range queryWords {
-					wordMatched := false
-					if strings.Contains(titleEn, word) || strings.Contains(titleJp, word) || strings.Contains(gametora, word) {
-						wordMatched = true
-					}
-					if !wordMatched {
-						allWordsMatched = false
-						break
-					}
-				}
-				if allWordsMatched {
-					matched = true
-				}
-			}
-		}
-
-		if matched {
-			matches = append(matches, card)
-			matchedCharIDs = append(matchedCharIDs, card.CharaID)
-		}
+	for _, match := range RankMatches(c.matcher, query, supportCards) {
+		matches = append(matches, match.Item)
+	}
+	for _, card := range matches {
+		matchedCharIDs = append(matchedCharIDs, card.CharaID)
 	}
 
 	// If we found matches, also include all other cards for the same characters
@@ -453,6 +503,14 @@ func (c *Client) getFromCache(key string) interface{} {
 	return nil
 }
 
+// setCacheUnlessCancelled stores an item in cache unless ctx is done, so a
+// search abandoned partway isn't served to later callers
+func (c *Client) setCacheUnlessCancelled(ctx context.Context, key string, data interface{}) {
+	if ctx.Err() == nil {
+		c.setCache(key, data)
+	}
+}
+
 // setCache stores an item in cache
 func (c *Client) setCache(key string, data interface{}) {
 	c.cacheMutex.Lock()
@@ -461,6 +519,6 @@ func (c *Client) setCache(key string, data interface{}) {
 	c.cache[key] = &CacheEntry{
 		Data:      data,
 		Timestamp: time.Now(),
-		TTL:       c.cacheTTL,
+		TTL:       JitterTTL(c.cacheTTL, c.cacheJitter),
 	}
 }
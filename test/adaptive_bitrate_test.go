@@ -0,0 +1,64 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// counterSink totals the counters it receives by name
+type counterSink struct {
+	mu       sync.Mutex
+	counters map[string]int64
+}
+
+func (s *counterSink) Counter(name string, value int64, tags map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.counters == nil {
+		s.counters = make(map[string]int64)
+	}
+	s.counters[name] += value
+}
+
+func (s *counterSink) Gauge(name string, value float64, tags map[string]string) {}
+
+func (s *counterSink) count(name string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.counters[name]
+}
+
+// TestAdaptiveBitrateSeesUnderruns tests that with adaptive bitrate on, the
+// pipeline's underruns still reach its metric sink through the controller
+func TestAdaptiveBitrateSeesUnderruns(t *testing.T) {
+	common.SetOpusOptions(common.OpusOptions{AdaptiveBitrate: true, MinBitrate: 64000, MaxBitrate: 128000})
+	defer common.SetOpusOptions(common.OpusOptions{})
+
+	// Ten frames, a gap long enough to drain them, then ten more
+	fakeFFmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	script := "#!/bin/sh\nhead -c 38400 /dev/zero\nsleep 0.5\nhead -c 38400 /dev/zero\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 100)}
+	sink := &counterSink{}
+	player := common.NewAudioPipeline(vc)
+	player.SetFFmpegPath(fakeFFmpeg)
+	player.SetMetricSink(sink)
+	if err := player.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	defer player.Stop()
+
+	if !waitFor(t, 5*time.Second, func() bool { return sink.count(pipeline.MetricUnderruns) > 0 }) {
+		t.Fatalf("Expected underruns reported to the sink, got quality %+v", player.PlaybackQuality())
+	}
+}
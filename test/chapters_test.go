@@ -0,0 +1,76 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+func TestParseChapters(t *testing.T) {
+	raw := `[{"start_time": 0.0, "title": "Intro", "end_time": 42.5}, {"start_time": 42.5, "title": "Verse", "end_time": 120.0}, {"start_time": 120.0, "title": "", "end_time": 185.0}]`
+
+	chapters, err := common.ParseChapters(raw)
+	if err != nil {
+		t.Fatalf("ParseChapters failed: %v", err)
+	}
+	if len(chapters) != 3 {
+		t.Fatalf("Expected 3 chapters, got %d", len(chapters))
+	}
+
+	if chapters[0].Title != "Intro" || chapters[0].Start != 0 || chapters[0].End != 42500*time.Millisecond {
+		t.Errorf("Unexpected first chapter: %+v", chapters[0])
+	}
+	if chapters[1].Title != "Verse" || chapters[1].Start != 42500*time.Millisecond || chapters[1].End != 2*time.Minute {
+		t.Errorf("Unexpected second chapter: %+v", chapters[1])
+	}
+	// Untitled chapters get a numbered placeholder
+	if chapters[2].Title != "Chapter 3" {
+		t.Errorf("Expected placeholder title, got %q", chapters[2].Title)
+	}
+}
+
+func TestParseChaptersWithoutChapters(t *testing.T) {
+	for _, raw := range []string{"", "NA", "null", " NA \n", "[]"} {
+		chapters, err := common.ParseChapters(raw)
+		if err != nil {
+			t.Errorf("ParseChapters(%q) failed: %v", raw, err)
+		}
+		if len(chapters) != 0 {
+			t.Errorf("ParseChapters(%q) returned %d chapters, want 0", raw, len(chapters))
+		}
+	}
+}
+
+func TestParseChaptersInvalid(t *testing.T) {
+	if _, err := common.ParseChapters("{not json"); err == nil {
+		t.Error("Expected an error for malformed chapter JSON")
+	}
+}
+
+func TestChapterAt(t *testing.T) {
+	chapters := []common.Chapter{
+		{Title: "Intro", Start: 0, End: 30 * time.Second},
+		{Title: "Verse", Start: 30 * time.Second, End: 90 * time.Second},
+	}
+
+	cases := []struct {
+		position time.Duration
+		want     int
+	}{
+		{0, 0},
+		{29 * time.Second, 0},
+		{30 * time.Second, 1},
+		{89 * time.Second, 1},
+		{90 * time.Second, -1},
+	}
+	for _, tc := range cases {
+		if got := common.ChapterAt(chapters, tc.position); got != tc.want {
+			t.Errorf("ChapterAt(%v) = %d, want %d", tc.position, got, tc.want)
+		}
+	}
+
+	if got := common.ChapterAt(nil, time.Second); got != -1 {
+		t.Errorf("ChapterAt with no chapters = %d, want -1", got)
+	}
+}
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// blockingDoer holds every request until released, then fails it
+type blockingDoer struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+// Do implements uma.HTTPDoer
+func (d *blockingDoer) Do(req *http.Request) (*http.Response, error) {
+	select {
+	case d.started <- struct{}{}:
+	default:
+	}
+	<-d.release
+	return nil, errors.New("upstream unavailable")
+}
+
+// TestGametoraClientCloseStopsBackgroundWork tests that Close waits for an
+// in-flight build ID refresh and leaves no goroutines behind
+func TestGametoraClientCloseStopsBackgroundWork(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	doer := &blockingDoer{started: make(chan struct{}, 1), release: make(chan struct{})}
+	cfg := &config.Config{CronEnabled: true, CronSchedule: "0 0 */6 * * *"}
+	client := uma.NewGametoraClient(cfg, uma.WithHTTPDoer(doer))
+
+	select {
+	case <-doer.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the initial build ID refresh to start")
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close() }()
+
+	select {
+	case <-closed:
+		t.Fatal("Expected Close to wait for the refresh in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(doer.release)
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Close returned an error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return after the refresh finished")
+	}
+
+	if !waitFor(t, 2*time.Second, func() bool { return runtime.NumGoroutine() <= before }) {
+		t.Errorf("Expected goroutines to return to %d after Close, got %d", before, runtime.NumGoroutine())
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Expected a second Close to be harmless, got %v", err)
+	}
+}
+
+// TestClientClose tests that Close drops the Umapyoi client's cache
+func TestClientClose(t *testing.T) {
+	doer := &mockDoer{responses: map[string]string{
+		"/api/v1/character/list": `[{"id": 1006, "name_en": "Oguri Cap"}]`,
+	}}
+	client := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithHTTPDoer(doer))
+
+	client.SearchCharacter("Oguri Cap")
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close returned an error: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("Expected a second Close to be harmless, got %v", err)
+	}
+
+	// With the cache dropped the next search goes upstream again
+	requests := len(doer.requests)
+	client.SearchCharacter("Oguri Cap")
+	if len(doer.requests) != requests+1 {
+		t.Errorf("Expected a fresh request after Close, got %d new", len(doer.requests)-requests)
+	}
+}
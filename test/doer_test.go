@@ -0,0 +1,79 @@
+package test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// mockDoer answers requests with canned bodies keyed by URL path
+type mockDoer struct {
+	responses map[string]string
+	requests  []*http.Request
+}
+
+// Do implements uma.HTTPDoer
+func (d *mockDoer) Do(req *http.Request) (*http.Response, error) {
+	d.requests = append(d.requests, req)
+
+	body, ok := d.responses[req.URL.Path]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+// TestClientUsesHTTPDoer tests that an injected doer serves requests without a server
+func TestClientUsesHTTPDoer(t *testing.T) {
+	doer := &mockDoer{responses: map[string]string{
+		"/api/v1/character/list": `[{"id": 1001, "name_en": "Special Week"}, {"id": 1002, "name_en": "Silence Suzuka"}]`,
+	}}
+
+	client := uma.NewClient(
+		uma.WithBaseURL("https://umapyoi.invalid/api"),
+		uma.WithHTTPDoer(doer),
+		uma.WithHeader("X-Bot-Contact", "ops@example.com"),
+	)
+
+	result := client.SearchCharacter("Silence Suzuka")
+	if !result.Found || result.Character.ID != 1002 {
+		t.Fatalf("expected Silence Suzuka from the canned response, got %+v", result)
+	}
+
+	if len(doer.requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(doer.requests))
+	}
+	req := doer.requests[0]
+	if req.Method != http.MethodGet {
+		t.Errorf("expected GET, got %s", req.Method)
+	}
+	if got := req.Header.Get("User-Agent"); got != uma.DefaultUserAgent {
+		t.Errorf("expected default User-Agent on doer requests, got %q", got)
+	}
+	if got := req.Header.Get("X-Bot-Contact"); got != "ops@example.com" {
+		t.Errorf("expected extra header on doer requests, got %q", got)
+	}
+}
+
+// failingDoer fails every request
+type failingDoer struct{ err error }
+
+// Do implements uma.HTTPDoer
+func (d failingDoer) Do(*http.Request) (*http.Response, error) {
+	return nil, d.err
+}
+
+// TestClientReportsDoerErrors tests that transport errors from the doer are surfaced
+func TestClientReportsDoerErrors(t *testing.T) {
+	doerErr := errors.New("circuit open")
+	client := uma.NewClient(uma.WithHTTPDoer(failingDoer{err: doerErr}))
+
+	result := client.GetSupportCard(30001)
+	if result.Found || result.Error == nil || !strings.Contains(result.Error.Error(), "circuit open") {
+		t.Errorf("expected the doer's error, got %+v", result)
+	}
+}
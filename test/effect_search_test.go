@@ -0,0 +1,108 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// effectSupports is a Gametora supports list whose cards have known
+// Friendship Bonus (1) and Training Effectiveness (8) values
+const effectSupports = `{"pageProps": {"supportData": [
+	{"url_name": "30001-special-week", "support_id": 30001, "char_name": "Special Week", "rarity": 3, "type": "speed",
+	 "effects": [[1, 20, -1, -1, -1, 25], [8, 5, -1, -1, -1, 10]]},
+	{"url_name": "30002-kitasan-black", "support_id": 30002, "char_name": "Kitasan Black", "rarity": 3, "type": "speed",
+	 "effects": [[1, 25, -1, -1, -1, -1, -1, -1, -1, -1, -1, 35]]},
+	{"url_name": "20003-oguri-cap", "support_id": 20003, "char_name": "Oguri Cap", "rarity": 2, "type": "power",
+	 "effects": [[1, 15, -1, -1, -1, -1, -1, -1, -1, -1, -1, 30]]},
+	{"url_name": "30004-tokai-teio", "support_id": 30004, "char_name": "Tokai Teio", "rarity": 3, "type": "speed",
+	 "effects": [[8, 10, -1, -1, -1, 15]]}
+]}}`
+
+// TestCardsWithEffect tests finding cards by effect in the cached support
+// list, best first and filtered by rarity
+func TestCardsWithEffect(t *testing.T) {
+	client := uma.NewGametoraClient(&config.Config{CronEnabled: false}, uma.WithBuildID("TestBuild12345"), uma.WithHTTPDoer(&mockDoer{responses: map[string]string{
+		"/_next/data/TestBuild12345/umamusume/supports.json": effectSupports,
+	}}))
+	cards, err := client.GetAllSupportCards()
+	if err != nil {
+		t.Fatalf("GetAllSupportCards failed: %v", err)
+	}
+
+	friendship, ok := uma.FindEffectType("friendship bonus")
+	if !ok {
+		t.Fatal("Expected Friendship Bonus to be a known effect")
+	}
+
+	matches := uma.CardsWithEffect(cards, friendship.ID, 0)
+	want := []struct {
+		name  string
+		value int
+	}{{"Kitasan Black", 35}, {"Oguri Cap", 30}, {"Special Week", 25}}
+	if len(matches) != len(want) {
+		t.Fatalf("Expected %d cards with Friendship Bonus, got %d", len(want), len(matches))
+	}
+	for i, w := range want {
+		if matches[i].Card.CharName != w.name || matches[i].Effect.MaxValue() != w.value {
+			t.Errorf("Match %d: expected %s at %d, got %s at %d", i, w.name, w.value, matches[i].Card.CharName, matches[i].Effect.MaxValue())
+		}
+	}
+
+	ssr := uma.CardsWithEffect(cards, friendship.ID, 3)
+	if len(ssr) != 2 || ssr[0].Card.CharName != "Kitasan Black" || ssr[1].Card.CharName != "Special Week" {
+		t.Errorf("Expected only the SSR cards, got %+v", ssr)
+	}
+
+	training, _ := uma.FindEffectType("Training-Effectiveness")
+	if matches := uma.CardsWithEffect(cards, training.ID, 0); len(matches) != 2 || matches[0].Card.CharName != "Tokai Teio" {
+		t.Errorf("Expected Tokai Teio to lead Training Effectiveness, got %+v", matches)
+	}
+
+	raceBonus, _ := uma.FindEffectType("race bonus")
+	if matches := uma.CardsWithEffect(cards, raceBonus.ID, 0); len(matches) != 0 {
+		t.Errorf("Expected no cards with Race Bonus, got %d", len(matches))
+	}
+}
+
+// TestFindEffectTypeUnknown tests that unknown names are rejected and every
+// known effect is listed
+func TestFindEffectTypeUnknown(t *testing.T) {
+	for _, name := range []string{"", "friendship", "luck"} {
+		if _, ok := uma.FindEffectType(name); ok {
+			t.Errorf("Expected %q not to name an effect", name)
+		}
+	}
+
+	names := uma.EffectTypeNames()
+	for _, name := range names {
+		if _, ok := uma.FindEffectType(name); !ok {
+			t.Errorf("Expected listed effect %q to be found", name)
+		}
+	}
+	if len(names) == 0 || names[0] != "Energy Cost Reduction" {
+		t.Errorf("Expected sorted effect names, got %v", names)
+	}
+}
+
+// TestSplitEffectQuery tests separating a trailing rarity from an effect name
+func TestSplitEffectQuery(t *testing.T) {
+	tests := []struct {
+		args   []string
+		name   string
+		rarity int
+	}{
+		{[]string{"friendship", "bonus"}, "friendship bonus", 0},
+		{[]string{"friendship", "bonus", "SSR"}, "friendship bonus", 3},
+		{[]string{"initial", "speed", "r"}, "initial speed", 1},
+		{[]string{"sr"}, "sr", 0},
+	}
+
+	for _, tt := range tests {
+		name, rarity := uma.SplitEffectQuery(tt.args)
+		if name != tt.name || rarity != tt.rarity {
+			t.Errorf("SplitEffectQuery(%v) = %q, %d; want %q, %d", tt.args, name, rarity, tt.name, tt.rarity)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestDecodeEffects tests decoding of known effect arrays
+func TestDecodeEffects(t *testing.T) {
+	raw := [][]int{
+		{1, 10, -1, 15, -1, 20, -1, -1, 25, -1, -1, 30},
+		{8, 5, -1, -1, -1, 10},
+		{19, 20, -1, -1, -1, -1, -1, -1, -1, -1, -1, 100},
+	}
+
+	effects := uma.DecodeEffects(raw)
+	if len(effects) != 3 {
+		t.Fatalf("Expected 3 effects, got %d", len(effects))
+	}
+
+	friendship := effects[0]
+	if friendship.Name != "Friendship Bonus" || !friendship.Known {
+		t.Errorf("Expected known Friendship Bonus, got %+v", friendship)
+	}
+	expected := []uma.EffectLevelValue{{Level: 1, Value: 10}, {Level: 10, Value: 15}, {Level: 20, Value: 20}, {Level: 35, Value: 25}, {Level: 50, Value: 30}}
+	if len(friendship.Levels) != len(expected) {
+		t.Fatalf("Expected %d levels, got %d", len(expected), len(friendship.Levels))
+	}
+	for i, level := range expected {
+		if friendship.Levels[i] != level {
+			t.Errorf("Level %d: expected %+v, got %+v", i, level, friendship.Levels[i])
+		}
+	}
+
+	if effects[1].Name != "Training Effectiveness" || effects[1].MaxValue() != 10 {
+		t.Errorf("Unexpected training effect: %+v", effects[1])
+	}
+
+	if effects[2].Name != "Specialty Priority" || effects[2].String() != "Specialty Priority: 100" {
+		t.Errorf("Unexpected specialty priority effect: %s", effects[2].String())
+	}
+}
+
+// TestDecodeEffectsUnknownType tests graceful handling of unknown and empty entries
+func TestDecodeEffectsUnknownType(t *testing.T) {
+	effects := uma.DecodeEffects([][]int{{}, {999, 3, 7}})
+	if len(effects) != 1 {
+		t.Fatalf("Expected 1 effect, got %d", len(effects))
+	}
+
+	if effects[0].Known {
+		t.Error("Expected unknown effect to be flagged as not known")
+	}
+	if effects[0].Name != "Unknown Effect (999)" {
+		t.Errorf("Unexpected name: %s", effects[0].Name)
+	}
+	if effects[0].MaxValue() != 7 {
+		t.Errorf("Expected max value 7, got %d", effects[0].MaxValue())
+	}
+
+	if got := uma.DecodeEffects(nil); len(got) != 0 {
+		t.Errorf("Expected no effects for nil input, got %d", len(got))
+	}
+}
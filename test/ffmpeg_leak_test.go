@@ -0,0 +1,111 @@
+//go:build linux
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// childProcesses returns the PIDs of this process's children, zombies
+// included, read from /proc
+func childProcesses(t *testing.T) []int {
+	t.Helper()
+
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		t.Fatalf("Failed to list processes: %v", err)
+	}
+
+	self := os.Getpid()
+	var children []int
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // exited while listing
+		}
+		// Fields after the parenthesized command name: state, ppid, ...
+		fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+		if len(fields) < 2 {
+			continue
+		}
+		if ppid, _ := strconv.Atoi(fields[1]); ppid == self {
+			pid, _ := strconv.Atoi(filepath.Base(filepath.Dir(path)))
+			children = append(children, pid)
+		}
+	}
+	return children
+}
+
+// processMetrics records the ffmpeg process gauge a pipeline reports
+type processMetrics struct {
+	mu       sync.Mutex
+	reported bool
+	live     float64
+}
+
+func (m *processMetrics) Counter(name string, value int64, tags map[string]string) {}
+
+func (m *processMetrics) Gauge(name string, value float64, tags map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name == common.MetricFFmpegProcesses {
+		m.reported = true
+		m.live = value
+	}
+}
+
+func TestFFmpegProcessesReapedOnStop(t *testing.T) {
+	// A stand-in ffmpeg that runs until killed
+	fakeFFmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	if err := os.WriteFile(fakeFFmpeg, []byte("#!/bin/sh\nexec sleep 30\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	before := len(childProcesses(t))
+	metrics := &processMetrics{}
+
+	for i := 0; i < 5; i++ {
+		// Never ready, so the pipeline holds ffmpeg open until stopped
+		pipeline := common.NewAudioPipeline(&discordgo.VoiceConnection{})
+		pipeline.SetFFmpegPath(fakeFFmpeg)
+		pipeline.SetMetricSink(metrics)
+
+		if err := pipeline.PlayStream("https://stream.example/track"); err != nil {
+			t.Fatalf("PlayStream failed: %v", err)
+		}
+		if !waitFor(t, 2*time.Second, func() bool { return common.LiveFFmpegProcesses() == 1 }) {
+			t.Fatalf("Run %d: expected 1 live ffmpeg process, got %d", i+1, common.LiveFFmpegProcesses())
+		}
+		if active := common.ActiveAudioPipelines(); active != 1 {
+			t.Errorf("Expected 1 active pipeline, got %d", active)
+		}
+
+		pipeline.Stop()
+		if !waitFor(t, 2*time.Second, func() bool {
+			return common.LiveFFmpegProcesses() == 0 && common.ActiveAudioPipelines() == 0
+		}) {
+			t.Fatalf("Run %d: ffmpeg not reaped after Stop: %d live, %d active pipelines",
+				i+1, common.LiveFFmpegProcesses(), common.ActiveAudioPipelines())
+		}
+	}
+
+	waitFor(t, 2*time.Second, func() bool { return len(childProcesses(t)) == before })
+	if children := childProcesses(t); len(children) != before {
+		t.Errorf("Expected no lingering children, found %v", children)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if !metrics.reported || metrics.live != 0 {
+		t.Errorf("Expected the process gauge to end at 0, got %v (reported: %v)", metrics.live, metrics.reported)
+	}
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// TestPlayStreamMissingFFmpeg tests that a missing ffmpeg binary fails
+// PlayStream with ErrFFmpegNotFound instead of starting playback
+func TestPlayStreamMissingFFmpeg(t *testing.T) {
+	for name, path := range map[string]string{
+		"not on PATH":  "hktm-ffmpeg-does-not-exist",
+		"missing file": filepath.Join(t.TempDir(), "ffmpeg"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			player := common.NewAudioPipeline(nil)
+			player.SetFFmpegPath(path)
+			t.Cleanup(player.Stop)
+
+			err := player.PlayStream("https://stream.example/track")
+			if !errors.Is(err, pipeline.ErrFFmpegNotFound) {
+				t.Fatalf("Expected ErrFFmpegNotFound, got %v", err)
+			}
+			if player.IsPlaying() {
+				t.Error("Expected pipeline not to be playing")
+			}
+		})
+	}
+}
+
+// TestFFmpegArgsFollowSourceProfile tests that ffmpeg's reconnect options
+// and buffer size come from the profile for the stream's source kind
+func TestFFmpegArgsFollowSourceProfile(t *testing.T) {
+	off := false
+	config := pipeline.DefaultPipelineConfig()
+	config.Profiles[pipeline.SourceMusic] = pipeline.ConfigProfile{Reconnect: &off, BufferSize: "256k"}
+	common.SetStreamConfig(config)
+	defer common.SetStreamConfig(nil)
+
+	tests := map[string]struct {
+		url        string
+		reconnect  bool
+		bufferSize string
+	}{
+		"live":  {url: "https://radio.example/stream", reconnect: true, bufferSize: "512k"},
+		"music": {url: "https://www.youtube.com/watch?v=abc", reconnect: false, bufferSize: "256k"},
+	}
+
+	for name, tt := range tests {
+		dir := t.TempDir()
+		argsFile := filepath.Join(dir, "args")
+		fakeFFmpeg := filepath.Join(dir, "ffmpeg")
+		if err := os.WriteFile(fakeFFmpeg, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0o755); err != nil {
+			t.Fatalf("Failed to write fake ffmpeg: %v", err)
+		}
+
+		player := common.NewAudioPipeline(&discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 10)})
+		player.SetFFmpegPath(fakeFFmpeg)
+		if err := player.PlayStream(tt.url); err != nil {
+			t.Fatalf("%s: PlayStream failed: %v", name, err)
+		}
+
+		var args []byte
+		if !waitFor(t, 5*time.Second, func() bool {
+			args, _ = os.ReadFile(argsFile)
+			return len(args) > 0
+		}) {
+			t.Fatalf("%s: ffmpeg was never started", name)
+		}
+		player.Stop()
+
+		if got := strings.Contains(string(args), "-reconnect 1"); got != tt.reconnect {
+			t.Errorf("%s: expected reconnect %v, got args %q", name, tt.reconnect, args)
+		}
+		if !strings.Contains(string(args), "-bufsize "+tt.bufferSize) {
+			t.Errorf("%s: expected buffer size %s, got args %q", name, tt.bufferSize, args)
+		}
+	}
+}
+
+// TestFFmpegArgsNormalizeLoudness tests that configured loudness
+// normalization runs loudnorm in place of the per-track gain
+func TestFFmpegArgsNormalizeLoudness(t *testing.T) {
+	tests := map[string]struct {
+		normalize bool
+		filter    string
+	}{
+		"gain":      {normalize: false, filter: "-af volume=6.00dB"},
+		"normalize": {normalize: true, filter: "-af " + pipeline.LoudnormFilter(-14)},
+	}
+	defer common.SetStreamConfig(nil)
+
+	for name, tt := range tests {
+		config := pipeline.DefaultPipelineConfig()
+		config.Audio.NormalizeLoudness = tt.normalize
+		config.Audio.TargetLUFS = -14
+		common.SetStreamConfig(config)
+
+		dir := t.TempDir()
+		argsFile := filepath.Join(dir, "args")
+		fakeFFmpeg := filepath.Join(dir, "ffmpeg")
+		if err := os.WriteFile(fakeFFmpeg, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\n"), 0o755); err != nil {
+			t.Fatalf("Failed to write fake ffmpeg: %v", err)
+		}
+
+		player := common.NewAudioPipeline(&discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 10)})
+		player.SetFFmpegPath(fakeFFmpeg)
+		player.SetGain(6)
+		if err := player.PlayStream("https://www.youtube.com/watch?v=abc"); err != nil {
+			t.Fatalf("%s: PlayStream failed: %v", name, err)
+		}
+
+		var args []byte
+		if !waitFor(t, 5*time.Second, func() bool {
+			args, _ = os.ReadFile(argsFile)
+			return len(args) > 0
+		}) {
+			t.Fatalf("%s: ffmpeg was never started", name)
+		}
+		player.Stop()
+
+		if !strings.Contains(string(args), tt.filter+" ") {
+			t.Errorf("%s: expected %q, got args %q", name, tt.filter, args)
+		}
+	}
+}
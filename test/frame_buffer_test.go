@@ -0,0 +1,186 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// pcmSource returns frames 20ms frames of a 440Hz stereo s16le sine wave,
+// the format ffmpeg feeds the encoder
+func pcmSource(frames int) *bytes.Reader {
+	var buf bytes.Buffer
+	samples := frames * 960
+	for i := 0; i < samples; i++ {
+		v := int16(math.Sin(2*math.Pi*440*float64(i)/48000) * 8000)
+		binary.Write(&buf, binary.LittleEndian, v) // left
+		binary.Write(&buf, binary.LittleEndian, v) // right
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+// bufferMetrics records the metrics a frame buffer reports
+type bufferMetrics struct {
+	mu         sync.Mutex
+	occupancy  []float64
+	stallCount int64
+}
+
+func (m *bufferMetrics) Counter(name string, value int64, tags map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name == pipeline.MetricBufferStalls {
+		m.stallCount += value
+	}
+}
+
+func (m *bufferMetrics) Gauge(name string, value float64, tags map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name == common.MetricBufferOccupancy {
+		m.occupancy = append(m.occupancy, value)
+	}
+}
+
+func TestFrameBufferDeliversEveryFrame(t *testing.T) {
+	metrics := &bufferMetrics{}
+	buffer := common.NewFrameBuffer(8, metrics)
+	go buffer.Fill(pcmSource(20))
+
+	// Let the reader fill the buffer before draining it
+	if !waitFor(t, time.Second, func() bool { return buffer.Stats().Occupancy == 8 }) {
+		t.Fatalf("Buffer never filled: %+v", buffer.Stats())
+	}
+
+	frames := 0
+	for {
+		frame, err := buffer.Next(time.Second)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		if len(frame) != 3840 {
+			t.Fatalf("Expected 3840-byte frames, got %d", len(frame))
+		}
+		frames++
+	}
+
+	if frames != 20 {
+		t.Errorf("Expected 20 frames, got %d", frames)
+	}
+
+	stats := buffer.Stats()
+	if stats.Capacity != 8 || stats.Peak != 8 || stats.Occupancy != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	// The reader outpaced the consumer, so it had to wait for room
+	if stats.FullWaits == 0 {
+		t.Error("Expected the reader to wait on a full buffer")
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.occupancy) != 1 {
+		t.Fatalf("Expected one occupancy gauge for the first 20 frames, got %d", len(metrics.occupancy))
+	}
+	if v := metrics.occupancy[0]; v < 0 || v > 8 {
+		t.Errorf("Occupancy %v outside buffer capacity", v)
+	}
+}
+
+func TestFrameBufferSamplesOccupancy(t *testing.T) {
+	metrics := &bufferMetrics{}
+	buffer := common.NewFrameBuffer(200, metrics)
+	go buffer.Fill(pcmSource(120))
+
+	for {
+		if _, err := buffer.Next(time.Second); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.occupancy) != 3 {
+		t.Errorf("Expected a gauge every 50 frames (3 for 120), got %d", len(metrics.occupancy))
+	}
+}
+
+func TestFrameBufferPadsShortFinalFrame(t *testing.T) {
+	buffer := common.NewFrameBuffer(4, nil)
+	go buffer.Fill(bytes.NewReader(make([]byte, 3840+100)))
+
+	for i := 0; i < 2; i++ {
+		frame, err := buffer.Next(time.Second)
+		if err != nil {
+			t.Fatalf("Frame %d: %v", i, err)
+		}
+		if len(frame) != 3840 {
+			t.Errorf("Frame %d: expected 3840 bytes, got %d", i, len(frame))
+		}
+	}
+	if _, err := buffer.Next(time.Second); err != io.EOF {
+		t.Errorf("Expected io.EOF after the last frame, got %v", err)
+	}
+}
+
+func TestFrameBufferReportsReadErrors(t *testing.T) {
+	readErr := errors.New("pipe broken")
+	buffer := common.NewFrameBuffer(4, nil)
+	go buffer.Fill(io.MultiReader(pcmSource(1), &failingReader{err: readErr}))
+
+	if _, err := buffer.Next(time.Second); err != nil {
+		t.Fatalf("Expected the first frame, got %v", err)
+	}
+	if _, err := buffer.Next(time.Second); !errors.Is(err, readErr) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
+
+func TestFrameBufferTimesOutWithoutData(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	buffer := common.NewFrameBuffer(4, nil)
+	go buffer.Fill(pr)
+	defer buffer.Close()
+
+	if _, err := buffer.Next(50 * time.Millisecond); err != common.ErrFrameTimeout {
+		t.Errorf("Expected ErrFrameTimeout, got %v", err)
+	}
+}
+
+func TestFrameBufferCountsStalls(t *testing.T) {
+	metrics := &bufferMetrics{}
+	buffer := common.NewFrameBuffer(2, metrics)
+	go buffer.Fill(pcmSource(10))
+	defer buffer.Close()
+
+	// Nobody drains the buffer, so it stays full past the stall threshold
+	if !waitFor(t, 3*time.Second, func() bool { return buffer.Stats().Stalls > 0 }) {
+		t.Fatalf("Expected a stall, got %+v", buffer.Stats())
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.stallCount == 0 {
+		t.Error("Expected a stall metric")
+	}
+}
+
+// failingReader fails every read with err
+type failingReader struct{ err error }
+
+func (r *failingReader) Read([]byte) (int, error) { return 0, r.err }
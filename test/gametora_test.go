@@ -0,0 +1,111 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// newFixtureGametoraServer serves the supports fixture and counts requests
+func newFixtureGametoraServer(t *testing.T, requests *int32) *httptest.Server {
+	fixture, err := os.ReadFile("testdata/gametora_supports.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path != "/test-build/umamusume/supports.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(fixture)
+	}))
+}
+
+// TestGetAllSupportCards tests that the full list is returned and cached
+func TestGetAllSupportCards(t *testing.T) {
+	var requests int32
+	server := newFixtureGametoraServer(t, &requests)
+	defer server.Close()
+
+	client := uma.NewGametoraClient(createTestConfig(),
+		uma.WithBaseURL(server.URL),
+		uma.WithBuildID("test-build"),
+	)
+
+	cards, err := client.GetAllSupportCards()
+	if err != nil {
+		t.Fatalf("GetAllSupportCards failed: %v", err)
+	}
+	if len(cards) != 3 {
+		t.Fatalf("Expected 3 cards from fixture, got %d", len(cards))
+	}
+	if cards[0].URLName != "30028-kitasan-black" || cards[0].Rarity != 3 {
+		t.Errorf("Unexpected first card: %+v", cards[0])
+	}
+
+	// Second call and a search should both be served from the cached list
+	if _, err := client.GetAllSupportCards(); err != nil {
+		t.Fatalf("Second GetAllSupportCards failed: %v", err)
+	}
+	result := client.SearchSimplifiedSupportCard("kitasan black")
+	if !result.Found || len(result.SupportCards) != 2 {
+		t.Errorf("Expected 2 Kitasan Black matches, got %+v", result)
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected 1 HTTP request, got %d", got)
+	}
+}
+
+// TestBuildIDInfo tests that the build ID info tells a freshly fetched ID
+// apart from the fallback
+func TestBuildIDInfo(t *testing.T) {
+	cfg := &config.Config{CronEnabled: false}
+
+	fetched := &mockDoer{responses: map[string]string{
+		"/umamusume/supports": `<script src="/_next/data/FreshBuild12345/umamusume/supports.json"></script>`,
+	}}
+	client := uma.NewGametoraClient(cfg, uma.WithHTTPDoer(fetched), uma.WithCacheTTL(10*time.Minute))
+
+	if info := client.BuildIDInfo(); info.ID != "" || !info.FetchedAt.IsZero() {
+		t.Fatalf("Expected no build ID before the first fetch, got %+v", info)
+	}
+
+	before := time.Now()
+	if _, err := client.GetBuildID(); err != nil {
+		t.Fatalf("GetBuildID failed: %v", err)
+	}
+	info := client.BuildIDInfo()
+	if info.ID != "FreshBuild12345" || info.IsFallback {
+		t.Errorf("Expected the fetched build ID, got %+v", info)
+	}
+	if info.FetchedAt.Before(before) {
+		t.Errorf("Expected FetchedAt to be set by the fetch, got %v", info.FetchedAt)
+	}
+	if info.CacheTTL != 10*time.Minute {
+		t.Errorf("Expected a 10m cache TTL, got %v", info.CacheTTL)
+	}
+
+	// A page without a build ID falls back to the built-in one
+	empty := &mockDoer{responses: map[string]string{"/umamusume/supports": "<html></html>"}}
+	client = uma.NewGametoraClient(cfg, uma.WithHTTPDoer(empty))
+	if err := client.RefreshBuildID(); err != nil {
+		t.Fatalf("RefreshBuildID failed: %v", err)
+	}
+	info = client.BuildIDInfo()
+	if info.ID != uma.FallbackBuildID || !info.IsFallback {
+		t.Errorf("Expected the fallback build ID, got %+v", info)
+	}
+	if info.FetchedAt.IsZero() {
+		t.Error("Expected FetchedAt to record the fallback refresh")
+	}
+}
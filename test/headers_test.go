@@ -0,0 +1,51 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestClientSendsConfiguredHeaders tests that the user agent and extra headers are sent
+func TestClientSendsConfiguredHeaders(t *testing.T) {
+	var gotUserAgent, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		gotHeader = r.Header.Get("X-Bot-Contact")
+		json.NewEncoder(w).Encode([]uma.Character{})
+	}))
+	defer server.Close()
+
+	client := uma.NewClient(
+		uma.WithBaseURL(server.URL),
+		uma.WithUserAgent("TestBot/2.0"),
+		uma.WithHeader("X-Bot-Contact", "ops@example.com"),
+	)
+	client.SearchCharacter("Special Week")
+
+	if gotUserAgent != "TestBot/2.0" {
+		t.Errorf("Expected User-Agent 'TestBot/2.0', got '%s'", gotUserAgent)
+	}
+	if gotHeader != "ops@example.com" {
+		t.Errorf("Expected X-Bot-Contact header, got '%s'", gotHeader)
+	}
+}
+
+// TestClientDefaultUserAgent tests that a descriptive user agent is sent by default
+func TestClientDefaultUserAgent(t *testing.T) {
+	var gotUserAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserAgent = r.Header.Get("User-Agent")
+		json.NewEncoder(w).Encode([]uma.Character{})
+	}))
+	defer server.Close()
+
+	uma.NewClient(uma.WithBaseURL(server.URL)).SearchCharacter("Special Week")
+
+	if gotUserAgent != uma.DefaultUserAgent {
+		t.Errorf("Expected default User-Agent '%s', got '%s'", uma.DefaultUserAgent, gotUserAgent)
+	}
+}
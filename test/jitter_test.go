@@ -0,0 +1,31 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestJitterTTL tests that jittered TTLs stay within the configured band
+func TestJitterTTL(t *testing.T) {
+	ttl := 30 * time.Minute
+	low, high := 27*time.Minute, 33*time.Minute
+
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 1000; i++ {
+		got := uma.JitterTTL(ttl, 0.1)
+		if got < low || got > high {
+			t.Fatalf("Jittered TTL %v outside band [%v, %v]", got, low, high)
+		}
+		distinct[got] = true
+	}
+
+	if len(distinct) < 2 {
+		t.Error("Expected jittered TTLs to vary")
+	}
+
+	if got := uma.JitterTTL(ttl, 0); got != ttl {
+		t.Errorf("Expected zero jitter to keep TTL %v, got %v", ttl, got)
+	}
+}
@@ -0,0 +1,84 @@
+package test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// TestPauseKeepAliveSendsSilence tests that a paused pipeline sends silence
+// frames when the keep-alive is on, and stops once resumed
+func TestPauseKeepAliveSendsSilence(t *testing.T) {
+	common.SetOpusOptions(common.OpusOptions{Passthrough: true})
+	defer common.SetOpusOptions(common.OpusOptions{})
+	common.SetPauseKeepAlive(20 * time.Millisecond)
+	defer common.SetPauseKeepAlive(0)
+
+	// A fake ffmpeg that streams audio packets until it is killed
+	dir := t.TempDir()
+	streamFile := filepath.Join(dir, "stream.ogg")
+	if err := os.WriteFile(streamFile, oggOpusStream([]byte("audio")), 0o644); err != nil {
+		t.Fatalf("Failed to write stream: %v", err)
+	}
+	fakeFFmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nwhile true; do cat " + streamFile + " || exit 0; done\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte)}
+	player := common.NewAudioPipeline(vc)
+	player.SetFFmpegPath(fakeFFmpeg)
+	if err := player.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	defer player.Stop()
+
+	receive := func() []byte {
+		select {
+		case packet := <-vc.OpusSend:
+			return packet
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for a packet")
+			return nil
+		}
+	}
+
+	if packet := receive(); string(packet) != "audio" {
+		t.Fatalf("Expected audio before pausing, got %q", packet)
+	}
+	if err := player.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	frames := player.FramesDelivered()
+
+	// An audio packet already being sent may still arrive first
+	silent := 0
+	for i := 0; i < 5 && silent < 3; i++ {
+		if bytes.Equal(receive(), pipeline.OpusSilenceFrame) {
+			silent++
+		}
+	}
+	if silent < 3 {
+		t.Fatalf("Expected silence frames while paused, got %d", silent)
+	}
+	if got := player.FramesDelivered(); got > frames+1 {
+		t.Errorf("Expected keep-alive frames not to count as audio, delivered went from %d to %d", frames, got)
+	}
+
+	if err := player.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if packet := receive(); string(packet) == "audio" {
+			return
+		}
+	}
+	t.Error("Expected audio again after resuming")
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// TestLoudnessAnalyzer tests gain calculation and per-video caching with a fake probe
+func TestLoudnessAnalyzer(t *testing.T) {
+	var calls int32
+	analyzer := common.NewLoudnessAnalyzer(func(ctx context.Context, streamURL string) (float64, error) {
+		atomic.AddInt32(&calls, 1)
+		return -20.0, nil
+	})
+
+	item := &common.QueueItem{URL: "https://stream.example/a", VideoID: "video1", Title: "Song"}
+
+	level, ok := analyzer.Measure(item)
+	if !ok {
+		t.Fatal("Expected probe to succeed")
+	}
+	if level != -20.0 {
+		t.Errorf("Expected level -20.0, got %v", level)
+	}
+
+	if gain := analyzer.GainFor(level); gain != common.DefaultLoudnessTarget+20.0 {
+		t.Errorf("Expected gain %v, got %v", common.DefaultLoudnessTarget+20.0, gain)
+	}
+
+	// Same video with a different stream URL should hit the cache
+	analyzer.Measure(&common.QueueItem{URL: "https://stream.example/b", VideoID: "video1"})
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("Expected 1 probe call, got %d", got)
+	}
+
+	// Gain is clamped for extreme measurements
+	if gain := analyzer.GainFor(-60.0); gain != common.MaxGainDB {
+		t.Errorf("Expected clamped gain %v, got %v", common.MaxGainDB, gain)
+	}
+}
+
+// TestQueueLoudnessCompensation tests that enqueued items get a gain from the probe
+func TestQueueLoudnessCompensation(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	queue.SetLoudnessAnalyzer(common.NewLoudnessAnalyzer(func(ctx context.Context, streamURL string) (float64, error) {
+		if streamURL == "https://stream.example/broken" {
+			return 0, errors.New("probe failed")
+		}
+		return -10.0, nil
+	}))
+
+	queue.AddWithYouTubeData("https://stream.example/loud", "", "loud", "Loud Song", "tester", 0)
+	queue.AddWithYouTubeData("https://stream.example/broken", "", "broken", "Broken Song", "tester", 0)
+
+	items := queue.List()
+	loud, broken := items[0], items[1]
+
+	// Probing happens in the background
+	expected := common.DefaultLoudnessTarget + 10.0
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.TrackGain(loud) != expected && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if gain := queue.TrackGain(loud); gain != expected {
+		t.Errorf("Expected gain %v, got %v", expected, gain)
+	}
+
+	// A failed probe falls back to no compensation
+	if gain := queue.TrackGain(broken); gain != 0 {
+		t.Errorf("Expected no gain for failed probe, got %v", gain)
+	}
+}
@@ -0,0 +1,18 @@
+package test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// TestMain keeps queues from shelling out to ffmpeg to probe the loudness of
+// every test item; tests that exercise probing set their own analyzer
+func TestMain(m *testing.M) {
+	common.SetDefaultLoudnessAnalyzer(common.NewLoudnessAnalyzer(func(ctx context.Context, streamURL string) (float64, error) {
+		return common.DefaultLoudnessTarget, nil
+	}))
+	os.Exit(m.Run())
+}
@@ -0,0 +1,262 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestRankingMatcherTiers tests that match tiers rank in the expected order
+func TestRankingMatcherTiers(t *testing.T) {
+	matcher := uma.RankingMatcher{}
+	character := uma.Character{NameEn: "Special Week", NameJp: "スペシャルウィーク"}
+
+	tests := []struct {
+		query  string
+		reason string
+	}{
+		{"special week", "exact"},
+		{"spec", "prefix"},
+		{"week", "contains"},
+		{"week special", "all words"},
+		{"week festival", "some words"},
+	}
+
+	previous := uma.ScoreExact + 1
+	for _, tt := range tests {
+		score, reason := matcher.Score(tt.query, character)
+		if reason != tt.reason {
+			t.Errorf("Query %q: expected reason %q, got %q", tt.query, tt.reason, reason)
+		}
+		if score <= 0 || score >= previous {
+			t.Errorf("Query %q: expected a score below %d, got %d", tt.query, previous, score)
+		}
+		previous = score
+	}
+
+	if score, _ := matcher.Score("oguri cap", character); score != 0 {
+		t.Errorf("Expected no match, got score %d", score)
+	}
+	if score, _ := matcher.Score("an", character); score != 0 {
+		t.Errorf("Expected short words to be ignored, got score %d", score)
+	}
+}
+
+// TestRankingMatcherNamePreference tests that earlier names win within a tier
+func TestRankingMatcherNamePreference(t *testing.T) {
+	matcher := uma.RankingMatcher{}
+	english := uma.Character{NameEn: "Gold Ship", NameJp: "other"}
+	japanese := uma.Character{NameEn: "other", NameJp: "Gold Ship"}
+
+	en, _ := matcher.Score("gold", english)
+	jp, _ := matcher.Score("gold", japanese)
+	if en <= jp {
+		t.Errorf("Expected English name match (%d) to outrank Japanese (%d)", en, jp)
+	}
+}
+
+// TestRankingMatcherSeparators tests that hyphenated URL names match spaced queries
+func TestRankingMatcherSeparators(t *testing.T) {
+	card := &uma.SimplifiedSupportCard{URLName: "10001-special-week"}
+	if score, reason := (uma.RankingMatcher{}).Score("special week", card); reason != "contains" {
+		t.Errorf("Expected contains match, got %q (%d)", reason, score)
+	}
+}
+
+// TestFuzzyMatcher tests typo tolerance below every literal tier
+func TestFuzzyMatcher(t *testing.T) {
+	character := uma.Character{NameEn: "Special Week"}
+
+	if score, _ := (uma.RankingMatcher{}).Score("speshal", character); score != 0 {
+		t.Fatalf("Expected default matcher to miss a typo, got %d", score)
+	}
+
+	fuzzy := uma.FuzzyMatcher{MaxDistance: 2}
+	score, reason := fuzzy.Score("speshal", character)
+	if reason != "fuzzy" || score <= 0 || score >= uma.ScoreAnyWord {
+		t.Errorf("Expected a fuzzy match below literal tiers, got %q (%d)", reason, score)
+	}
+
+	if literal, _ := fuzzy.Score("special", character); literal <= score {
+		t.Errorf("Expected literal match (%d) to outrank fuzzy (%d)", literal, score)
+	}
+	if score, _ := fuzzy.Score("zzzzzzz", character); score != 0 {
+		t.Errorf("Expected no match beyond MaxDistance, got %d", score)
+	}
+}
+
+// TestRankMatches tests ordering and dropping of partial matches
+func TestRankMatches(t *testing.T) {
+	characters := []uma.Character{
+		{NameEn: "Kitasan Black"},
+		{NameEn: "Black Tie"},
+		{NameEn: "Kitasan"},
+	}
+
+	ranked := uma.RankMatches(nil, "kitasan black", characters)
+	if len(ranked) != 1 || ranked[0].Item.NameEn != "Kitasan Black" {
+		t.Fatalf("Expected only the full match, got %+v", ranked)
+	}
+
+	ranked = uma.RankMatches(uma.RankingMatcher{}, "kitasan", characters)
+	if len(ranked) != 2 || ranked[0].Item.NameEn != "Kitasan" || ranked[1].Item.NameEn != "Kitasan Black" {
+		t.Errorf("Expected exact match before prefix match, got %+v", ranked)
+	}
+
+	// Partial matches surface only when nothing matches every word
+	ranked = uma.RankMatches(uma.RankingMatcher{}, "black cat", characters)
+	if len(ranked) != 2 || ranked[0].Reason != "some words" {
+		t.Errorf("Expected partial matches as a fallback, got %+v", ranked)
+	}
+}
+
+// stubMatcher matches only one name, to check injection
+type stubMatcher struct{ name string }
+
+func (m stubMatcher) Score(query string, candidate uma.Indexable) (int, string) {
+	for _, name := range candidate.SearchNames() {
+		if name == m.name {
+			return 1, "stub"
+		}
+	}
+	return 0, ""
+}
+
+// TestInjectedMatcher tests that clients rank with an injected matcher
+func TestInjectedMatcher(t *testing.T) {
+	server := newFixtureGametoraServer(t, new(int32))
+	defer server.Close()
+
+	client := uma.NewGametoraClient(createTestConfig(),
+		uma.WithBaseURL(server.URL),
+		uma.WithBuildID("test-build"),
+		uma.WithMatcher(stubMatcher{name: "30016-super-creek"}),
+	)
+
+	result := client.SearchSimplifiedSupportCard("anything")
+	if !result.Found || len(result.SupportCards) != 1 || result.SupportCard.URLName != "30016-super-creek" {
+		t.Errorf("Expected the injected matcher to pick Super Creek, got %+v", result)
+	}
+}
+
+// TestSubstringMatcherTiers tests that the default matcher's character tiers
+// rank in the expected order
+func TestSubstringMatcherTiers(t *testing.T) {
+	matcher := uma.SubstringMatcher{}
+
+	tests := []struct {
+		character uma.Character
+		reason    string
+	}{
+		{uma.Character{NameEn: "Special Week"}, "exact"},
+		{uma.Character{NameEn: "Special Week (Summer)"}, "contains"},
+		{uma.Character{NameEn: "other", NameJp: "special week"}, "contains"},
+		{uma.Character{NameEn: "Week Festival"}, "some words"},
+	}
+
+	previous := uma.ScoreExact + 1
+	for _, tt := range tests {
+		score, reason := matcher.Score("Special Week", tt.character)
+		if reason != tt.reason {
+			t.Errorf("%+v: expected reason %q, got %q", tt.character, tt.reason, reason)
+		}
+		if score <= 0 || score >= previous {
+			t.Errorf("%+v: expected a score below %d, got %d", tt.character, previous, score)
+		}
+		previous = score
+	}
+
+	if score, _ := matcher.Score("oguri cap", uma.Character{NameEn: "Special Week"}); score != 0 {
+		t.Errorf("Expected no match, got score %d", score)
+	}
+}
+
+// TestSubstringMatcherSupportCards tests the default matcher's support card
+// title and URL name matching
+func TestSubstringMatcherSupportCards(t *testing.T) {
+	matcher := uma.SubstringMatcher{}
+	card := uma.SupportCard{TitleEn: "[Tonight, We Waltz] King Halo", Gametora: "30010-king-halo"}
+
+	for query, want := range map[string]string{
+		"king halo":   "contains",
+		"30010 king":  "contains",
+		"waltz halo":  "all words",
+		"king oguri":  "",
+		"halo":        "contains",
+		"waltz":       "contains",
+		"tonight cap": "",
+	} {
+		if _, reason := matcher.Score(query, card); reason != want {
+			t.Errorf("Query %q: expected reason %q, got %q", query, want, reason)
+		}
+	}
+
+	// Other candidates need every word longer than two letters in a name
+	simplified := &uma.SimplifiedSupportCard{URLName: "30016-super-creek"}
+	if score, _ := matcher.Score("super creek", simplified); score != uma.ScoreAllWords {
+		t.Errorf("Expected an all words match, got score %d", score)
+	}
+	if score, _ := matcher.Score("of super creek", simplified); score != uma.ScoreAllWords {
+		t.Errorf("Expected short words to be ignored, got score %d", score)
+	}
+	if score, _ := matcher.Score("super creek unknown", simplified); score != 0 {
+		t.Errorf("Expected no match without every word, got score %d", score)
+	}
+}
+
+// TestSubstringMatcherByDefault tests that clients without an injected
+// matcher use SubstringMatcher, which needs every query word in a Gametora
+// URL name and ranks higher rarities first
+func TestSubstringMatcherByDefault(t *testing.T) {
+	server := newFixtureGametoraServer(t, new(int32))
+	defer server.Close()
+
+	client := uma.NewGametoraClient(createTestConfig(),
+		uma.WithBaseURL(server.URL),
+		uma.WithBuildID("test-build"),
+	)
+
+	// A ranking matcher would fall back to the cards matching "creek"
+	if result := client.SearchSimplifiedSupportCard("super creek unknown"); result.Found {
+		t.Errorf("Expected no match without every word in the URL name, got %+v", result.SupportCards)
+	}
+	if result := client.SearchSimplifiedSupportCard("super creek"); !result.Found || result.SupportCard.URLName != "30016-super-creek" {
+		t.Errorf("Expected Super Creek, got %+v", result)
+	}
+
+	result := client.SearchSimplifiedSupportCard("kitasan black")
+	if !result.Found || len(result.SupportCards) != 2 ||
+		result.SupportCards[0].Rarity != 3 || result.SupportCards[1].Rarity != 2 {
+		t.Errorf("Expected both Kitasan Black cards, SSR first, got %+v", result.SupportCards)
+	}
+}
+
+// TestSubstringMatcherCharacterTiersThroughClient tests that character
+// search picks the best tier of the default matcher wherever it is listed
+func TestSubstringMatcherCharacterTiersThroughClient(t *testing.T) {
+	// Each character matches "special week" one tier better than the one
+	// before it
+	characters := []uma.Character{
+		{ID: 1, NameEn: "Week Festival", NameJp: "other"},
+		{ID: 2, NameEn: "other", NameJp: "Special Week"},
+		{ID: 3, NameEn: "Special Week (Summer)", NameJp: "other"},
+		{ID: 4, NameEn: "Special Week", NameJp: "other"},
+	}
+
+	for n := len(characters); n > 0; n-- {
+		listed := characters[:n]
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(listed)
+		}))
+
+		client := uma.NewClient(uma.WithBaseURL(server.URL))
+		result := client.SearchCharacter("special week")
+		if !result.Found || result.Character.ID != n {
+			t.Errorf("With %d characters listed: expected character %d, got %+v", n, n, result)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// oggPage builds an Ogg page holding segments, as split by lacing values
+func oggPage(lacing []byte, body []byte) []byte {
+	header := make([]byte, 27)
+	copy(header, "OggS")
+	header[26] = byte(len(lacing))
+	page := append(header, lacing...)
+	return append(page, body...)
+}
+
+// oggOpusStream builds an Ogg Opus stream of the header packets followed by
+// packets, one page each
+func oggOpusStream(packets ...[]byte) []byte {
+	var stream []byte
+	for _, packet := range append([][]byte{[]byte("OpusHead\x01\x02"), []byte("OpusTags")}, packets...) {
+		stream = append(stream, oggPage([]byte{byte(len(packet))}, packet)...)
+	}
+	return stream
+}
+
+// TestOggOpusReaderSkipsHeaders tests that only audio packets are returned
+func TestOggOpusReaderSkipsHeaders(t *testing.T) {
+	reader := common.NewOggOpusReader(bytes.NewReader(oggOpusStream([]byte("one"), []byte("two"))))
+
+	for _, want := range []string{"one", "two"} {
+		packet, err := reader.ReadPacket()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(packet) != want {
+			t.Errorf("Expected packet %q, got %q", want, packet)
+		}
+	}
+	if _, err := reader.ReadPacket(); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end of the stream, got %v", err)
+	}
+}
+
+// TestOggOpusReaderPacketAcrossPages tests that a packet continued on the
+// next page is joined and that several packets share a page
+func TestOggOpusReaderPacketAcrossPages(t *testing.T) {
+	long := bytes.Repeat([]byte{'x'}, 300)
+
+	var stream []byte
+	stream = append(stream, oggPage([]byte{255}, long[:255])...)
+	stream = append(stream, oggPage([]byte{45, 2}, append(long[255:], 'h', 'i'))...)
+
+	reader := common.NewOggOpusReader(bytes.NewReader(stream))
+	packet, err := reader.ReadPacket()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !bytes.Equal(packet, long) {
+		t.Errorf("Expected the %d byte packet joined, got %d bytes", len(long), len(packet))
+	}
+	if packet, err = reader.ReadPacket(); err != nil || string(packet) != "hi" {
+		t.Errorf("Expected packet \"hi\", got %q (%v)", packet, err)
+	}
+}
+
+// TestOggOpusReaderTruncated tests that a stream cut inside a page is an error
+func TestOggOpusReaderTruncated(t *testing.T) {
+	stream := oggOpusStream([]byte("audio"))
+	reader := common.NewOggOpusReader(bytes.NewReader(stream[:len(stream)-2]))
+
+	if _, err := reader.ReadPacket(); err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := common.NewOggOpusReader(strings.NewReader("not an ogg stream at all....")).ReadPacket(); err == nil {
+		t.Error("Expected an error for a stream without Ogg pages")
+	}
+}
+
+// TestPassthroughForwardsFFmpegOpus tests that with passthrough enabled
+// ffmpeg is asked for Ogg Opus and its packets reach the voice connection
+// without being re-encoded
+func TestPassthroughForwardsFFmpegOpus(t *testing.T) {
+	common.SetOpusOptions(common.OpusOptions{Passthrough: true})
+	defer common.SetOpusOptions(common.OpusOptions{})
+
+	dir := t.TempDir()
+	streamFile := filepath.Join(dir, "stream.ogg")
+	argsFile := filepath.Join(dir, "args")
+	if err := os.WriteFile(streamFile, oggOpusStream([]byte("frame-1"), []byte("frame-2")), 0o644); err != nil {
+		t.Fatalf("Failed to write stream: %v", err)
+	}
+	fakeFFmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\ncat " + streamFile + "\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 10)}
+	pipeline := common.NewAudioPipeline(vc)
+	pipeline.SetFFmpegPath(fakeFFmpeg)
+	if err := pipeline.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	defer pipeline.Stop()
+
+	for _, want := range []string{"frame-1", "frame-2"} {
+		select {
+		case packet := <-vc.OpusSend:
+			if string(packet) != want {
+				t.Errorf("Expected packet %q, got %q", want, packet)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %q", want)
+		}
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("Failed to read ffmpeg args: %v", err)
+	}
+	for _, want := range []string{"-c:a libopus", "-frame_duration 20", "-f ogg"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("Expected ffmpeg args to contain %q, got %q", want, args)
+		}
+	}
+	if strings.Contains(string(args), "s16le") {
+		t.Errorf("Expected no PCM output in passthrough, got %q", args)
+	}
+}
+
+// TestPassthroughPassesOpusOptions tests that the configured bitrate, frame
+// duration, FEC and DTX reach ffmpeg's libopus arguments
+func TestPassthroughPassesOpusOptions(t *testing.T) {
+	common.SetOpusOptions(common.OpusOptions{Bitrate: 64000, FrameDuration: 10 * time.Millisecond, Passthrough: true, FEC: true, PacketLoss: 15, DTX: true})
+	defer common.SetOpusOptions(common.OpusOptions{})
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	fakeFFmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 10)}
+	player := common.NewAudioPipeline(vc)
+	player.SetFFmpegPath(fakeFFmpeg)
+	if err := player.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	defer player.Stop()
+
+	var args []byte
+	if !waitFor(t, 5*time.Second, func() bool {
+		args, _ = os.ReadFile(argsFile)
+		return len(args) > 0
+	}) {
+		t.Fatal("ffmpeg was never started")
+	}
+	for _, want := range []string{"-b:a 64000", "-frame_duration 10", "-fec 1 -packet_loss 15", "-dtx 1"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("Expected ffmpeg args to contain %q, got %q", want, args)
+		}
+	}
+}
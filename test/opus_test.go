@@ -0,0 +1,32 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"layeh.com/gopus"
+)
+
+// TestParseOpusApplication tests that mode names map to encoder settings
+func TestParseOpusApplication(t *testing.T) {
+	cases := map[string]gopus.Application{
+		"voip":     gopus.Voip,
+		"audio":    gopus.Audio,
+		"":         gopus.Audio,
+		"lowdelay": gopus.RestrictedLowDelay,
+	}
+	for mode, want := range cases {
+		got, err := common.ParseOpusApplication(mode)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", mode, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%q: expected %d, got %d", mode, want, got)
+		}
+	}
+
+	if err := common.SetOpusApplication("music"); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+}
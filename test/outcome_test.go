@@ -0,0 +1,249 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// playWith starts a pipeline that plays through the given streamer
+func playWith(t *testing.T, streamer common.Streamer) *common.AudioPipeline {
+	t.Helper()
+	pipeline := common.NewAudioPipeline(nil)
+	pipeline.SetStreamer(streamer)
+	if err := pipeline.PlayStream("https://stream.example/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	t.Cleanup(pipeline.Stop)
+	return pipeline
+}
+
+// waitForOutcome waits until the pipeline records a terminal outcome
+func waitForOutcome(t *testing.T, pipeline *common.AudioPipeline, timeout time.Duration) common.Outcome {
+	t.Helper()
+	if !waitFor(t, timeout, func() bool { return !pipeline.LastOutcome().IsZero() }) {
+		t.Fatal("pipeline did not record an outcome")
+	}
+	return pipeline.LastOutcome()
+}
+
+// TestOutcomeCompleted tests that a stream reaching its end is reported as completed
+func TestOutcomeCompleted(t *testing.T) {
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+
+	if outcome := pipeline.LastOutcome(); !outcome.IsZero() {
+		t.Fatalf("expected no outcome while playing, got %s", outcome)
+	}
+
+	outcome := waitForOutcome(t, pipeline, time.Second)
+	if outcome.Reason != common.OutcomeCompleted {
+		t.Fatalf("expected completed, got %s", outcome.Reason)
+	}
+	if outcome.Err != nil || outcome.Recoveries != 0 {
+		t.Errorf("unexpected outcome details: %+v", outcome)
+	}
+	if outcome.Elapsed < 20*time.Millisecond {
+		t.Errorf("expected elapsed of at least 20ms, got %v", outcome.Elapsed)
+	}
+}
+
+// TestOutcomeUserStopped tests that Stop wins over the stream ending because it was cancelled
+func TestOutcomeUserStopped(t *testing.T) {
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	pipeline.Stop()
+
+	outcome := waitForOutcome(t, pipeline, time.Second)
+	if outcome.Reason != common.OutcomeUserStopped {
+		t.Fatalf("expected user_stopped, got %s", outcome.Reason)
+	}
+
+	// The stream returning nil after cancellation must not overwrite it
+	time.Sleep(50 * time.Millisecond)
+	if got := pipeline.LastOutcome().Reason; got != common.OutcomeUserStopped {
+		t.Errorf("outcome changed after stop: %s", got)
+	}
+}
+
+// TestOutcomeIdleTimeout tests that the queue reports an idle stop after dropping the pipeline
+func TestOutcomeIdleTimeout(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	queue.SetPipeline(playWith(t, func(ctx context.Context, streamURL string) error {
+		<-ctx.Done()
+		return nil
+	}))
+
+	queue.StopAndCleanupWithReason(common.OutcomeIdleTimeout)
+
+	if queue.GetPipeline() != nil {
+		t.Fatal("expected pipeline to be cleaned up")
+	}
+	if got := queue.LastOutcome().Reason; got != common.OutcomeIdleTimeout {
+		t.Fatalf("expected idle_timeout, got %s", got)
+	}
+}
+
+// TestOutcomeError tests that an unrecoverable stream error is reported with its cause
+func TestOutcomeError(t *testing.T) {
+	streamErr := errors.New("unsupported codec")
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		return streamErr
+	})
+
+	outcome := waitForOutcome(t, pipeline, time.Second)
+	if outcome.Reason != common.OutcomeError {
+		t.Fatalf("expected error, got %s", outcome.Reason)
+	}
+	if !errors.Is(outcome.Err, streamErr) {
+		t.Errorf("expected terminal error %v, got %v", streamErr, outcome.Err)
+	}
+}
+
+// TestOutcomeCountsRecoveries tests that restarts before completion are reported
+func TestOutcomeCountsRecoveries(t *testing.T) {
+	var calls int32
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return errors.New("timeout reading PCM data")
+		}
+		return nil
+	})
+
+	outcome := waitForOutcome(t, pipeline, 5*time.Second)
+	if outcome.Reason != common.OutcomeCompleted {
+		t.Fatalf("expected completed after recovery, got %s (%v)", outcome.Reason, outcome.Err)
+	}
+	if outcome.Recoveries != 1 {
+		t.Errorf("expected 1 recovery, got %d", outcome.Recoveries)
+	}
+}
+
+// TestOutcomeDrained tests that running out of items keeps the last track's details
+func TestOutcomeDrained(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	queue.SetPipeline(pipeline)
+	completed := waitForOutcome(t, pipeline, time.Second)
+
+	queue.SetPipeline(nil)
+	if got := queue.LastOutcome().Reason; got != common.OutcomeCompleted {
+		t.Fatalf("expected completed before draining, got %s", got)
+	}
+
+	if queue.Next() != nil {
+		t.Fatal("expected empty queue")
+	}
+	queue.MarkDrained()
+
+	outcome := queue.LastOutcome()
+	if outcome.Reason != common.OutcomeDrained {
+		t.Fatalf("expected drained, got %s", outcome.Reason)
+	}
+	if outcome.Elapsed != completed.Elapsed {
+		t.Errorf("expected elapsed %v carried over, got %v", completed.Elapsed, outcome.Elapsed)
+	}
+}
+
+// TestElapsedExcludesPausedTime tests that time spent paused counts towards
+// neither Elapsed nor the outcome, scaled down from 10s playing and 5s paused
+func TestElapsedExcludesPausedTime(t *testing.T) {
+	const unit = 20 * time.Millisecond
+
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	time.Sleep(10 * unit)
+	if err := pipeline.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	frozen := pipeline.Elapsed()
+	time.Sleep(5 * unit)
+	if paused := pipeline.Elapsed(); paused != frozen {
+		t.Errorf("Expected elapsed to stand still while paused, went from %v to %v", frozen, paused)
+	}
+	if err := pipeline.Resume(); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	time.Sleep(2 * unit)
+
+	// 12 units played over 17 units of wall-clock time
+	elapsed := pipeline.Elapsed()
+	if elapsed < 12*unit || elapsed >= 15*unit {
+		t.Errorf("Expected elapsed near %v of playback, got %v", 12*unit, elapsed)
+	}
+
+	pipeline.Stop()
+	outcome := waitForOutcome(t, pipeline, time.Second)
+	if outcome.Elapsed < 12*unit || outcome.Elapsed >= 15*unit {
+		t.Errorf("Expected the outcome to record about %v played, got %v", 12*unit, outcome.Elapsed)
+	}
+	if after := pipeline.Elapsed(); after != outcome.Elapsed {
+		t.Errorf("Expected elapsed to stop at %v once the track ended, got %v", outcome.Elapsed, after)
+	}
+}
+
+// TestRestartBackoff tests that restarts wait out the configured recovery
+// backoff and stop after its retries, counting each one
+func TestRestartBackoff(t *testing.T) {
+	config := pipeline.DefaultPipelineConfig()
+	config.Recovery.Backoff = pipeline.BackoffConfig{
+		InitialDelay: 50 * time.Millisecond,
+		MaxDelay:     100 * time.Millisecond,
+		Multiplier:   2,
+		MaxRetries:   2,
+	}
+	common.SetStreamConfig(config)
+	t.Cleanup(func() { common.SetStreamConfig(nil) })
+
+	var mu sync.Mutex
+	var starts []time.Time
+	metrics := &counterSink{}
+	player := common.NewAudioPipeline(nil)
+	player.SetMetricSink(metrics)
+	player.SetStreamer(func(ctx context.Context, streamURL string) error {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		return errors.New("timeout reading PCM data")
+	})
+	if err := player.PlayStream("https://stream.example/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	t.Cleanup(player.Stop)
+
+	outcome := waitForOutcome(t, player, 2*time.Second)
+	if outcome.Reason != common.OutcomeError || outcome.Recoveries != 2 {
+		t.Fatalf("expected an error after 2 recoveries, got %s after %d (%v)", outcome.Reason, outcome.Recoveries, outcome.Err)
+	}
+	if got := metrics.count(pipeline.MetricRecoveryAttempt); got != 2 {
+		t.Errorf("expected 2 recovery attempts counted, got %d", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(starts) != 3 {
+		t.Fatalf("expected 3 stream starts, got %d", len(starts))
+	}
+	for i, want := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond} {
+		if gap := starts[i+1].Sub(starts[i]); gap < want {
+			t.Errorf("restart %d came after %v, want at least %v", i+1, gap, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// recordedEvent is one event captured by eventLog
+type recordedEvent struct {
+	eventType string
+	severity  string
+	data      map[string]interface{}
+}
+
+// eventLog is an EventSink that keeps every event
+type eventLog struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+func (l *eventLog) RecordEvent(eventType, severity string, data map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, recordedEvent{eventType, severity, data})
+}
+
+func (l *eventLog) snapshot() []recordedEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]recordedEvent(nil), l.events...)
+}
+
+// playWithEvents starts a pipeline that plays through streamer and reports to events
+func playWithEvents(t *testing.T, events common.EventSink, streamer common.Streamer) *common.AudioPipeline {
+	t.Helper()
+	pipeline := common.NewAudioPipeline(nil)
+	pipeline.SetStreamer(streamer)
+	pipeline.SetEventSink(events)
+	if err := pipeline.PlayStream("https://stream.example/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	t.Cleanup(pipeline.Stop)
+	return pipeline
+}
+
+// TestPipelineEventsRecovery tests that a recovered stream error is reported
+// as an error followed by a recovery
+func TestPipelineEventsRecovery(t *testing.T) {
+	events := &eventLog{}
+	var calls int32
+	pipeline := playWithEvents(t, events, func(ctx context.Context, streamURL string) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return common.ErrFrameTimeout
+		}
+		return nil
+	})
+	waitForOutcome(t, pipeline, 5*time.Second)
+
+	got := events.snapshot()
+	if len(got) != 2 {
+		t.Fatalf("Expected an error and a recovery event, got %+v", got)
+	}
+	if got[0].eventType != "error" || got[0].severity != "medium" || got[0].data["error_type"] != "frame_timeout" {
+		t.Errorf("Unexpected error event: %+v", got[0])
+	}
+	if got[1].eventType != "recovery" || got[1].data["attempt"] != 1 {
+		t.Errorf("Unexpected recovery event: %+v", got[1])
+	}
+}
+
+// TestPipelineEventsTerminalError tests that an error ending playback is
+// reported with high severity and no recovery
+func TestPipelineEventsTerminalError(t *testing.T) {
+	events := &eventLog{}
+	pipeline := playWithEvents(t, events, func(ctx context.Context, streamURL string) error {
+		return errors.New("unsupported codec")
+	})
+	waitForOutcome(t, pipeline, time.Second)
+
+	got := events.snapshot()
+	if len(got) != 1 {
+		t.Fatalf("Expected one error event, got %+v", got)
+	}
+	if got[0].eventType != "error" || got[0].severity != "high" || got[0].data["error"] != "unsupported codec" {
+		t.Errorf("Unexpected error event: %+v", got[0])
+	}
+}
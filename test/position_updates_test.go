@@ -0,0 +1,115 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// TestPositionUpdatesWhilePlaying tests that a playing pipeline publishes
+// its position on the configured interval, and stops while paused
+func TestPositionUpdatesWhilePlaying(t *testing.T) {
+	config := pipeline.DefaultPipelineConfig()
+	config.Discord.PositionUpdateInterval = 10 * time.Millisecond
+	common.SetStreamConfig(config)
+	defer common.SetStreamConfig(nil)
+
+	// Ten frames, then nothing until ffmpeg is killed
+	fakeFFmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	script := "#!/bin/sh\nhead -c 38400 /dev/zero\nsleep 3\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 100)}
+	player := common.NewAudioPipeline(vc)
+	player.SetFFmpegPath(fakeFFmpeg)
+	updates := player.PositionUpdates()
+	if err := player.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	defer player.Stop()
+
+	if !waitFor(t, 2*time.Second, func() bool {
+		select {
+		case position := <-updates:
+			return position == 200*time.Millisecond
+		default:
+			return false
+		}
+	}) {
+		t.Fatalf("Expected a position update of 200ms, position is %v", player.Position())
+	}
+
+	if err := player.Pause(); err != nil {
+		t.Fatalf("Pause failed: %v", err)
+	}
+	// Let an update in flight land, then nothing more arrives while paused
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case <-updates:
+	default:
+	}
+	select {
+	case position := <-updates:
+		t.Fatalf("Position update %v while paused", position)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestPositionResetOnStop tests that Stop returns the position to zero and
+// closes the PositionUpdates channel
+func TestPositionResetOnStop(t *testing.T) {
+	fakeFFmpeg := filepath.Join(t.TempDir(), "ffmpeg")
+	// exec so Stop kills the process holding stdout open
+	script := "#!/bin/sh\nhead -c 38400 /dev/zero\nexec sleep 3\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 100)}
+	player := common.NewAudioPipeline(vc)
+	player.SetFFmpegPath(fakeFFmpeg)
+	updates := player.PositionUpdates()
+	if err := player.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return player.Position() == 200*time.Millisecond }) {
+		t.Fatalf("Expected a position of 200ms, got %v", player.Position())
+	}
+	if err := player.Seek(time.Minute); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+
+	player.Stop()
+	if position := player.Position(); position != 0 {
+		t.Errorf("Expected position 0 after Stop, got %v", position)
+	}
+
+	// Draining ends once the stream loop exits and closes the channel
+	done := make(chan struct{})
+	go func() {
+		for range updates {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("PositionUpdates wasn't closed after Stop")
+	}
+
+	select {
+	case _, ok := <-player.PositionUpdates():
+		if ok {
+			t.Error("Expected PositionUpdates to stay closed after the stream ended")
+		}
+	case <-time.After(time.Second):
+		t.Error("PositionUpdates blocked after the stream ended")
+	}
+}
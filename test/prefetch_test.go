@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// waitFor polls cond until it holds or the timeout elapses
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+// TestPrefetchNextTrack tests that the next item is resolved and probed while the current one plays
+func TestPrefetchNextTrack(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+
+	var probedMu sync.Mutex
+	var probed []string
+	queue.SetLoudnessAnalyzer(common.NewLoudnessAnalyzer(func(ctx context.Context, streamURL string) (float64, error) {
+		probedMu.Lock()
+		defer probedMu.Unlock()
+		probed = append(probed, streamURL)
+		return -20, nil
+	}))
+	queue.SetPrefetchResolver(func(item *common.QueueItem) (string, error) {
+		return "https://stream.example/fresh-" + item.Title, nil
+	})
+
+	queue.Add("https://stream.example/expired-1", "one", "tester")
+	queue.Add("https://stream.example/expired-2", "two", "tester")
+
+	// Nothing is preloaded until playback starts
+	next := queue.List()[1]
+	if _, ok := queue.PrefetchedURL(next); ok {
+		t.Fatal("Expected no prefetch while idle")
+	}
+
+	// Taken in the same order as startNextInQueueLocked: the item first,
+	// then playback is marked started
+	current := queue.Next()
+	queue.SetPlaying(true)
+	if current.Title != "one" {
+		t.Fatalf("Expected 'one' to play first, got %s", current.Title)
+	}
+
+	// The next item is warm before the current one finishes
+	if !waitFor(t, time.Second, func() bool {
+		_, ok := queue.PrefetchedURL(next)
+		return ok
+	}) {
+		t.Fatal("Expected next item to be preloaded while the current one plays")
+	}
+	if !waitFor(t, time.Second, func() bool { return queue.TrackGain(next) != 0 }) {
+		t.Error("Expected next item loudness to be probed")
+	}
+
+	item := queue.Next()
+	if item != next {
+		t.Fatalf("Expected the preloaded item, got %s", item.Title)
+	}
+	if item.URL != "https://stream.example/fresh-two" {
+		t.Errorf("Expected preloaded stream URL, got %s", item.URL)
+	}
+}
+
+// TestPrefetchCancelledOnQueueChange tests that a stale prefetch is dropped when the next item changes
+func TestPrefetchCancelledOnQueueChange(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	queue.SetLoudnessAnalyzer(nil)
+
+	release := make(chan struct{})
+	queue.SetPrefetchResolver(func(item *common.QueueItem) (string, error) {
+		if item.Title == "two" {
+			<-release
+		}
+		return fmt.Sprintf("https://stream.example/fresh-%s", item.Title), nil
+	})
+
+	queue.Add("https://stream.example/1", "one", "tester")
+	queue.Add("https://stream.example/2", "two", "tester")
+	queue.Add("https://stream.example/3", "three", "tester")
+	queue.SetPlaying(true)
+	queue.Next()
+
+	stale := queue.List()[0]
+	if err := queue.Remove(0); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	close(release)
+
+	third := queue.List()[0]
+	if !waitFor(t, time.Second, func() bool {
+		_, ok := queue.PrefetchedURL(third)
+		return ok
+	}) {
+		t.Fatal("Expected the new next item to be preloaded")
+	}
+	if _, ok := queue.PrefetchedURL(stale); ok {
+		t.Error("Expected the removed item's prefetch to be discarded")
+	}
+
+	if item := queue.Next(); item.URL != "https://stream.example/fresh-three" {
+		t.Errorf("Expected preloaded stream URL, got %s", item.URL)
+	}
+}
@@ -0,0 +1,33 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestPlaybackQualityCountsReconnects tests that replacing the voice
+// connection mid-track counts as a reconnect, but attaching the first one
+// doesn't
+func TestPlaybackQualityCountsReconnects(t *testing.T) {
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	pipeline.SetVoiceConnection(&discordgo.VoiceConnection{})
+	if got := pipeline.PlaybackQuality().Reconnects; got != 0 {
+		t.Fatalf("Expected no reconnects after the first connection, got %d", got)
+	}
+
+	pipeline.SetVoiceConnection(&discordgo.VoiceConnection{})
+	pipeline.SetVoiceConnection(&discordgo.VoiceConnection{})
+	quality := pipeline.PlaybackQuality()
+	if quality.Reconnects != 2 {
+		t.Errorf("Expected 2 reconnects, got %d", quality.Reconnects)
+	}
+	if quality.Underruns != 0 || quality.AvgFrameSendLatency != 0 {
+		t.Errorf("Expected no delivery counters without frames, got %+v", quality)
+	}
+}
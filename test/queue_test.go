@@ -0,0 +1,298 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// TestReplayHistory tests that played items are requeued in order with fresh stream URLs
+func TestReplayHistory(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	for i := 1; i <= 4; i++ {
+		queue.AddWithYouTubeData(
+			fmt.Sprintf("https://stream.example/expired-%d", i),
+			fmt.Sprintf("https://www.youtube.com/watch?v=video%d", i),
+			fmt.Sprintf("video%d", i),
+			fmt.Sprintf("Song %d", i),
+			"tester",
+			0,
+		)
+	}
+
+	// Play through the whole queue
+	for queue.Next() != nil {
+	}
+
+	if got := len(queue.History()); got != 4 {
+		t.Fatalf("Expected 4 history items, got %d", got)
+	}
+
+	resolve := func(item *common.QueueItem) (string, error) {
+		if item.VideoID == "video3" {
+			return "", fmt.Errorf("video unavailable")
+		}
+		return "https://stream.example/fresh-" + item.VideoID, nil
+	}
+
+	requeued, failed := queue.ReplayHistory(10, resolve)
+	if requeued != 3 || failed != 1 {
+		t.Fatalf("Expected 3 requeued and 1 failed, got %d and %d", requeued, failed)
+	}
+
+	items := queue.List()
+	expected := []string{"Song 1", "Song 2", "Song 4"}
+	for i, title := range expected {
+		if items[i].Title != title {
+			t.Errorf("Position %d: expected %s, got %s", i, title, items[i].Title)
+		}
+		if items[i].URL != "https://stream.example/fresh-"+items[i].VideoID {
+			t.Errorf("Expected fresh stream URL for %s, got %s", title, items[i].URL)
+		}
+	}
+}
+
+// TestRetryFailedTracks tests that failed tracks are requeued with fresh stream
+// URLs, and that tracks still failing are kept for the next retry
+func TestRetryFailedTracks(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	for i := 1; i <= 3; i++ {
+		queue.AddWithYouTubeData(
+			fmt.Sprintf("https://stream.example/expired-%d", i),
+			fmt.Sprintf("https://www.youtube.com/watch?v=video%d", i),
+			fmt.Sprintf("video%d", i),
+			fmt.Sprintf("Song %d", i),
+			"tester",
+			0,
+		)
+	}
+
+	// Every track fails during an outage and is skipped
+	for item := queue.Next(); item != nil; item = queue.Next() {
+		queue.RecordFailedTrack(item)
+	}
+
+	outage := true
+	resolve := func(item *common.QueueItem) (string, error) {
+		if outage && item.VideoID == "video2" {
+			return "", fmt.Errorf("video unavailable")
+		}
+		return "https://stream.example/fresh-" + item.VideoID, nil
+	}
+
+	requeued, failed := queue.RetryFailedTracks(resolve)
+	if requeued != 2 || failed != 1 {
+		t.Fatalf("Expected 2 requeued and 1 failed, got %d and %d", requeued, failed)
+	}
+	items := queue.List()
+	if len(items) != 2 || items[0].Title != "Song 1" || items[1].Title != "Song 3" {
+		t.Fatalf("Expected Song 1 and Song 3 requeued in order, got %v", items)
+	}
+	if items[0].URL != "https://stream.example/fresh-video1" {
+		t.Errorf("Expected a fresh stream URL, got %s", items[0].URL)
+	}
+
+	remaining := queue.FailedTracks()
+	if len(remaining) != 1 || remaining[0].Title != "Song 2" {
+		t.Fatalf("Expected only Song 2 to stay failed, got %v", remaining)
+	}
+
+	// Once the outage is over the last one comes back too
+	outage = false
+	if requeued, failed := queue.RetryFailedTracks(resolve); requeued != 1 || failed != 0 {
+		t.Fatalf("Expected 1 requeued and 0 failed, got %d and %d", requeued, failed)
+	}
+	if len(queue.FailedTracks()) != 0 {
+		t.Error("Expected the failed list to be empty")
+	}
+}
+
+// TestFailedTracksCap tests that only the most recent failures are kept
+func TestFailedTracksCap(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	for i := 1; i <= common.DefaultMaxFailedTracks+5; i++ {
+		queue.RecordFailedTrack(&common.QueueItem{Title: fmt.Sprintf("Song %d", i)})
+	}
+
+	failed := queue.FailedTracks()
+	if len(failed) != common.DefaultMaxFailedTracks {
+		t.Fatalf("Expected %d failed tracks, got %d", common.DefaultMaxFailedTracks, len(failed))
+	}
+	if failed[0].Title != "Song 6" {
+		t.Errorf("Expected the oldest failures to be dropped, got %s first", failed[0].Title)
+	}
+
+	queue.ClearFailedTracks()
+	if len(queue.FailedTracks()) != 0 {
+		t.Error("Expected failed tracks to be cleared")
+	}
+}
+
+// TestReplayHistoryLimit tests that the replay size is capped
+func TestReplayHistoryLimit(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	for i := 1; i <= 5; i++ {
+		queue.Add(fmt.Sprintf("https://stream.example/%d", i), fmt.Sprintf("Song %d", i), "tester")
+		queue.Next()
+	}
+
+	requeued, _ := queue.ReplayHistory(2, func(item *common.QueueItem) (string, error) {
+		return item.URL, nil
+	})
+	if requeued != 2 || queue.Size() != 2 {
+		t.Fatalf("Expected 2 requeued items, got %d (queue size %d)", requeued, queue.Size())
+	}
+	if queue.List()[0].Title != "Song 1" {
+		t.Errorf("Expected replay to start from the first played song, got %s", queue.List()[0].Title)
+	}
+
+	queue.ClearHistory()
+	if len(queue.History()) != 0 {
+		t.Error("Expected history to be cleared")
+	}
+}
+
+// TestReplayLast tests that the last n finished tracks are requeued in their
+// original order, leaving out the track still playing
+func TestReplayLast(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	for i := 1; i <= 5; i++ {
+		queue.Add(fmt.Sprintf("https://stream.example/%d", i), fmt.Sprintf("Song %d", i), "tester")
+		queue.Next()
+	}
+
+	// Song 5 is still playing, so only songs 1 to 4 have finished
+	queue.SetPlaying(true)
+	if got := len(queue.FinishedHistory()); got != 4 {
+		t.Fatalf("Expected 4 finished tracks, got %d", got)
+	}
+
+	for _, n := range []int{0, 5} {
+		if _, _, err := queue.ReplayLast(n, func(item *common.QueueItem) (string, error) { return item.URL, nil }); err == nil {
+			t.Errorf("Expected replaying %d tracks to fail", n)
+		}
+	}
+	if queue.Size() != 0 {
+		t.Fatalf("Expected nothing requeued by invalid counts, got %d", queue.Size())
+	}
+
+	requeued, failed, err := queue.ReplayLast(3, func(item *common.QueueItem) (string, error) {
+		return item.URL + "?fresh", nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if requeued != 3 || failed != 0 {
+		t.Fatalf("Expected 3 requeued and 0 failed, got %d and %d", requeued, failed)
+	}
+
+	items := queue.List()
+	for i, title := range []string{"Song 2", "Song 3", "Song 4"} {
+		if items[i].Title != title {
+			t.Errorf("Position %d: expected %s, got %s", i, title, items[i].Title)
+		}
+		if !strings.HasSuffix(items[i].URL, "?fresh") {
+			t.Errorf("Expected re-resolved stream URL for %s, got %s", title, items[i].URL)
+		}
+	}
+
+	// Once playback stops the last track counts as finished too
+	queue.SetPlaying(false)
+	if got := len(queue.FinishedHistory()); got != 5 {
+		t.Errorf("Expected 5 finished tracks, got %d", got)
+	}
+}
+
+// TestPlayNext tests moving a queued item to the front without touching the current song
+func TestPlayNext(t *testing.T) {
+	queue := common.NewMusicQueue("test-guild")
+	for i := 1; i <= 5; i++ {
+		queue.Add(fmt.Sprintf("https://stream.example/%d", i), fmt.Sprintf("Song %d", i), "tester")
+	}
+	current := queue.Next() // Song 1 is now playing
+
+	moved, err := queue.PlayNext(2)
+	if err != nil {
+		t.Fatalf("PlayNext failed: %v", err)
+	}
+	if moved.Title != "Song 4" {
+		t.Errorf("Expected PlayNext to return Song 4, got %s", moved.Title)
+	}
+
+	expected := []string{"Song 4", "Song 2", "Song 3", "Song 5"}
+	items := queue.List()
+	if len(items) != len(expected) {
+		t.Fatalf("Expected %d items, got %d", len(expected), len(items))
+	}
+	for i, title := range expected {
+		if items[i].Title != title {
+			t.Errorf("Position %d: expected %s, got %s", i, title, items[i].Title)
+		}
+	}
+
+	if queue.Current() != current {
+		t.Error("PlayNext must not change the current song")
+	}
+
+	if _, err := queue.PlayNext(4); err == nil {
+		t.Error("Expected error for out of range index")
+	}
+	if _, err := queue.PlayNext(-1); err == nil {
+		t.Error("Expected error for negative index")
+	}
+}
+
+// TestRemainingDuration tests that unknown track lengths are skipped and flag the estimate
+func TestRemainingDuration(t *testing.T) {
+	queue := common.NewMusicQueue("remaining-guild")
+	queue.SetLoudnessAnalyzer(nil)
+	queue.AddWithYouTubeData("https://stream.example/a", "", "", "Current", "tester", 4*time.Minute)
+	queue.AddWithYouTubeData("https://stream.example/b", "", "", "Known", "tester", 3*time.Minute)
+	queue.Add("https://stream.example/live", "Live stream", "tester")
+	queue.AddWithYouTubeData("https://stream.example/c", "", "", "Also known", "tester", 2*time.Minute+30*time.Second)
+
+	remaining, approximate := queue.RemainingEstimate()
+	if remaining != 9*time.Minute+30*time.Second || !approximate {
+		t.Errorf("Expected ≈9m30s before playback, got %v (approximate=%v)", remaining, approximate)
+	}
+
+	// Start the first track and jump a minute into it
+	queue.Next()
+	pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+		<-ctx.Done()
+		return nil
+	})
+	queue.SetPipeline(pipeline)
+	if err := pipeline.Seek(time.Minute); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+
+	if got := queue.RemainingDuration(); got != 8*time.Minute+30*time.Second {
+		t.Errorf("Expected 8m30s with a minute played, got %v", got)
+	}
+
+	// Without the unknown-length stream the estimate is exact
+	if err := queue.Remove(1); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	remaining, approximate = queue.RemainingEstimate()
+	if remaining != 8*time.Minute+30*time.Second || approximate {
+		t.Errorf("Expected exactly 8m30s, got %v (approximate=%v)", remaining, approximate)
+	}
+}
+
+// TestRemainingDurationUnknownOnly tests a queue where nothing has a known length
+func TestRemainingDurationUnknownOnly(t *testing.T) {
+	queue := common.NewMusicQueue("remaining-unknown-guild")
+	queue.SetLoudnessAnalyzer(nil)
+	queue.Add("https://stream.example/live", "Live stream", "tester")
+
+	remaining, approximate := queue.RemainingEstimate()
+	if remaining != 0 || !approximate {
+		t.Errorf("Expected an unknown estimate, got %v (approximate=%v)", remaining, approximate)
+	}
+}
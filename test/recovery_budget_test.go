@@ -0,0 +1,93 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// playWithBudget starts a pipeline whose stream always fails, restarting
+// against budget, and returns it with a count of its stream starts
+func playWithBudget(t *testing.T, budget *pipeline.RecoveryBudget, events common.EventSink, metrics common.MetricSink) (*common.AudioPipeline, *int32) {
+	t.Helper()
+	var starts int32
+	player := common.NewAudioPipeline(nil)
+	player.SetRecoveryBudget(budget)
+	player.SetEventSink(events)
+	player.SetMetricSink(metrics)
+	player.SetStreamer(func(ctx context.Context, streamURL string) error {
+		atomic.AddInt32(&starts, 1)
+		return errors.New("timeout reading PCM data")
+	})
+	if err := player.PlayStream("https://stream.example/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	t.Cleanup(player.Stop)
+	return player, &starts
+}
+
+// TestRecoveryBudgetEndsPlayback tests that the player stops restarting a
+// failing stream once the recovery budget is spent, well before its backoff
+// runs out of retries, and that the budget carries over to the next
+// pipeline sharing it
+func TestRecoveryBudgetEndsPlayback(t *testing.T) {
+	config := pipeline.DefaultPipelineConfig()
+	config.Recovery.Backoff = pipeline.BackoffConfig{
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+		Multiplier:   1,
+		MaxRetries:   10,
+	}
+	config.Recovery.BudgetMaxRecoveries = 2
+	config.Recovery.BudgetWindow = time.Minute
+	common.SetStreamConfig(config)
+	t.Cleanup(func() { common.SetStreamConfig(nil) })
+
+	budget := pipeline.NewRecoveryBudget(time.Now)
+	events := &eventLog{}
+	metrics := &counterSink{}
+	player, starts := playWithBudget(t, budget, events, metrics)
+
+	outcome := waitForOutcome(t, player, 2*time.Second)
+	if outcome.Reason != common.OutcomeError || outcome.Recoveries != 2 {
+		t.Fatalf("expected an error after 2 recoveries, got %s after %d (%v)", outcome.Reason, outcome.Recoveries, outcome.Err)
+	}
+	if outcome.Err == nil || !strings.Contains(outcome.Err.Error(), "recovery budget exhausted") {
+		t.Errorf("expected the budget to end playback, got %v", outcome.Err)
+	}
+	if got := atomic.LoadInt32(starts); got != 3 {
+		t.Errorf("expected 3 stream starts, got %d", got)
+	}
+	if got := metrics.count(pipeline.MetricRecoveryBudgetExhausted); got != 1 {
+		t.Errorf("expected the exhausted budget counted once, got %d", got)
+	}
+
+	var exhausted []recordedEvent
+	for _, event := range events.snapshot() {
+		if event.eventType == "recovery_budget_exhausted" {
+			exhausted = append(exhausted, event)
+		}
+	}
+	if len(exhausted) != 1 {
+		t.Fatalf("expected one recovery_budget_exhausted event, got %d", len(exhausted))
+	}
+	if exhausted[0].severity != "high" || exhausted[0].data["max_recoveries"] != 2 {
+		t.Errorf("unexpected recovery_budget_exhausted event: %+v", exhausted[0])
+	}
+
+	// The next track shares the spent budget, so its first failure ends it
+	next, nextStarts := playWithBudget(t, budget, nil, nil)
+	outcome = waitForOutcome(t, next, 2*time.Second)
+	if outcome.Reason != common.OutcomeError || outcome.Recoveries != 0 {
+		t.Fatalf("expected an error without recoveries, got %s after %d (%v)", outcome.Reason, outcome.Recoveries, outcome.Err)
+	}
+	if got := atomic.LoadInt32(nextStarts); got != 1 {
+		t.Errorf("expected 1 stream start, got %d", got)
+	}
+}
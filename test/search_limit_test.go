@@ -0,0 +1,103 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestSearchConcurrencyLimit tests that concurrent searches never exceed the limit
+func TestSearchConcurrencyLimit(t *testing.T) {
+	const limit = 3
+	uma.SetMaxConcurrentSearches(limit, 5*time.Second)
+	t.Cleanup(func() { uma.SetMaxConcurrentSearches(0, 0) })
+
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		id, _ := strconv.Atoi(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		json.NewEncoder(w).Encode(uma.SupportCard{ID: id})
+	}))
+	defer server.Close()
+
+	client := uma.NewClient(uma.WithBaseURL(server.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			if result := client.GetSupportCard(id); !result.Found {
+				t.Errorf("support card %d: %v", id, result.Error)
+			}
+		}(30000 + i)
+	}
+	wg.Wait()
+
+	if peak > limit {
+		t.Errorf("expected at most %d concurrent requests, saw %d", limit, peak)
+	}
+	if peak < 2 {
+		t.Errorf("expected requests to run concurrently, saw peak %d", peak)
+	}
+	if n := uma.InFlightSearches(); n != 0 {
+		t.Errorf("expected no searches in flight after completion, got %d", n)
+	}
+}
+
+// TestSearchQueueTimeout tests that a search waiting too long for a slot fails
+func TestSearchQueueTimeout(t *testing.T) {
+	uma.SetMaxConcurrentSearches(1, 20*time.Millisecond)
+	t.Cleanup(func() { uma.SetMaxConcurrentSearches(0, 0) })
+
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+		json.NewEncoder(w).Encode(uma.SupportCard{ID: 1})
+	}))
+	defer server.Close()
+
+	client := uma.NewClient(uma.WithBaseURL(server.URL))
+
+	first := make(chan *uma.SupportCardSearchResult)
+	go func() { first <- client.GetSupportCard(31001) }()
+
+	// Wait for the first request to take the only slot
+	deadline := time.Now().Add(time.Second)
+	for uma.InFlightSearches() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	result := client.GetSupportCard(31002)
+	if result.Found || result.Error == nil || !errors.Is(result.Error, uma.ErrSearchQueueTimeout) {
+		t.Errorf("expected queue timeout, got %+v", result)
+	}
+
+	close(unblock)
+	if result := <-first; !result.Found {
+		t.Errorf("expected first search to succeed, got %v", result.Error)
+	}
+
+	// The throttled failure isn't cached, so a retry reaches upstream
+	if result := client.GetSupportCard(31002); !result.Found {
+		t.Errorf("expected the retry to succeed once a slot is free, got %v", result.Error)
+	}
+}
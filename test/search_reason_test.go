@@ -0,0 +1,134 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// kitasanSupports is a Gametora supports list with SR and R versions of
+// Kitasan Black and no SSR
+const kitasanSupports = `{"pageProps": {"supportData": [
+	{"url_name": "20010-kitasan-black", "support_id": 20010, "char_name": "Kitasan Black", "rarity": 2, "type": "speed"},
+	{"url_name": "10010-kitasan-black", "support_id": 10010, "char_name": "Kitasan Black", "rarity": 1, "type": "speed"}
+]}}`
+
+// TestCharacterSearchReasons tests that failed character searches say why
+func TestCharacterSearchReasons(t *testing.T) {
+	empty := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithHTTPDoer(&mockDoer{responses: map[string]string{
+		"/api/v1/character/list": `[]`,
+	}}))
+	if result := empty.SearchCharacter("Oguri Cap"); result.Found || result.Reason != uma.NotFound {
+		t.Errorf("Expected NotFound for a missing character, got found=%v reason=%q", result.Found, result.Reason)
+	}
+
+	down := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithHTTPDoer(&mockDoer{}))
+	if result := down.SearchCharacter("Oguri Cap"); result.Found || result.Reason != uma.UpstreamError {
+		t.Errorf("Expected UpstreamError when the API fails, got found=%v reason=%q", result.Found, result.Reason)
+	}
+
+	found := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithHTTPDoer(&mockDoer{responses: map[string]string{
+		"/api/v1/character/list": `[{"id": 1006, "name_en": "Oguri Cap"}]`,
+	}}))
+	if result := found.SearchCharacter("Oguri Cap"); !result.Found || result.Reason != "" {
+		t.Errorf("Expected a found character with no reason, got found=%v reason=%q", result.Found, result.Reason)
+	}
+}
+
+// TestSupportCardSearchReasons tests that failed support card searches say why
+func TestSupportCardSearchReasons(t *testing.T) {
+	empty := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithSupportListRetry(fastRetry), uma.WithHTTPDoer(&mockDoer{responses: map[string]string{
+		"/api/v1/support": `[]`,
+	}}))
+	if result := empty.SearchSupportCard("Kitasan Black"); result.Found || result.Reason != uma.NotFound {
+		t.Errorf("Expected NotFound for a missing card, got found=%v reason=%q", result.Found, result.Reason)
+	}
+
+	down := uma.NewClient(uma.WithBaseURL("https://umapyoi.invalid/api"), uma.WithSupportListRetry(fastRetry), uma.WithHTTPDoer(&mockDoer{}))
+	if result := down.SearchSupportCard("Kitasan Black"); result.Found || result.Reason != uma.UpstreamError {
+		t.Errorf("Expected UpstreamError when the API fails, got found=%v reason=%q", result.Found, result.Reason)
+	}
+}
+
+// TestGametoraSearchReasons tests NotFound, UpstreamError and FilteredOut
+// for Gametora support card searches
+func TestGametoraSearchReasons(t *testing.T) {
+	cfg := &config.Config{CronEnabled: false}
+	supportsPath := "/_next/data/TestBuild12345/umamusume/supports.json"
+
+	client := uma.NewGametoraClient(cfg, uma.WithBuildID("TestBuild12345"), uma.WithHTTPDoer(&mockDoer{responses: map[string]string{
+		supportsPath: kitasanSupports,
+	}}))
+
+	result := client.SearchSimplifiedSupportCard("Kitasan Black")
+	if !result.Found || len(result.SupportCards) != 2 {
+		t.Fatalf("Expected both Kitasan Black versions, got %+v", result)
+	}
+
+	ssr := result.ApplyFilter(uma.SupportCardFilter{Rarity: 3})
+	if ssr.Found || ssr.Reason != uma.FilteredOut || ssr.SupportCard != nil {
+		t.Errorf("Expected FilteredOut with no SSR version, got found=%v reason=%q", ssr.Found, ssr.Reason)
+	}
+	if !result.Found || len(result.SupportCards) != 2 {
+		t.Error("Expected filtering to leave the original result untouched")
+	}
+
+	sr := result.ApplyFilter(uma.SupportCardFilter{Rarity: 2})
+	if !sr.Found || len(sr.SupportCards) != 1 || sr.SupportCard.SupportID != 20010 {
+		t.Errorf("Expected only the SR version, got %+v", sr)
+	}
+
+	empty := uma.NewGametoraClient(cfg, uma.WithBuildID("TestBuild12345"), uma.WithHTTPDoer(&mockDoer{responses: map[string]string{
+		supportsPath: `{"pageProps": {"supportData": []}}`,
+	}}))
+	if result := empty.SearchSimplifiedSupportCard("Kitasan Black"); result.Found || result.Reason != uma.NotFound {
+		t.Errorf("Expected NotFound for a missing card, got found=%v reason=%q", result.Found, result.Reason)
+	}
+	// A filter can't turn NotFound into FilteredOut
+	if result := empty.SearchSimplifiedSupportCard("Kitasan Black").ApplyFilter(uma.SupportCardFilter{Rarity: 3}); result.Reason != uma.NotFound {
+		t.Errorf("Expected NotFound to survive filtering, got %q", result.Reason)
+	}
+
+	down := uma.NewGametoraClient(cfg, uma.WithBuildID("TestBuild12345"), uma.WithHTTPDoer(&mockDoer{}))
+	if result := down.SearchSimplifiedSupportCard("Kitasan Black"); result.Found || result.Reason != uma.UpstreamError {
+		t.Errorf("Expected UpstreamError when the API fails, got found=%v reason=%q", result.Found, result.Reason)
+	}
+}
+
+// TestFailureReasonFallback tests the reason derived for results cached
+// before reasons were recorded
+func TestFailureReasonFallback(t *testing.T) {
+	if got := uma.FailureReason("", nil); got != uma.NotFound {
+		t.Errorf("Expected NotFound without an error, got %q", got)
+	}
+	if got := uma.FailureReason("", errors.New("timeout")); got != uma.UpstreamError {
+		t.Errorf("Expected UpstreamError with an error, got %q", got)
+	}
+	if got := uma.FailureReason(uma.FilteredOut, nil); got != uma.FilteredOut {
+		t.Errorf("Expected a recorded reason to be kept, got %q", got)
+	}
+}
+
+// TestSplitSupportCardFilter tests separating trailing filter arguments
+func TestSplitSupportCardFilter(t *testing.T) {
+	tests := []struct {
+		args   []string
+		query  string
+		filter uma.SupportCardFilter
+	}{
+		{[]string{"kitasan", "black"}, "kitasan black", uma.SupportCardFilter{}},
+		{[]string{"kitasan", "black", "ssr"}, "kitasan black", uma.SupportCardFilter{Rarity: 3}},
+		{[]string{"kitasan", "black", "speed", "SR"}, "kitasan black", uma.SupportCardFilter{Type: "speed", Rarity: 2}},
+		{[]string{"ssr"}, "ssr", uma.SupportCardFilter{}},
+		{[]string{"speed", "ssr", "kitasan"}, "speed ssr kitasan", uma.SupportCardFilter{}},
+	}
+
+	for _, tt := range tests {
+		query, filter := uma.SplitSupportCardFilter(tt.args)
+		if query != tt.query || filter != tt.filter {
+			t.Errorf("SplitSupportCardFilter(%v) = %q, %+v; want %q, %+v", tt.args, query, filter, tt.query, tt.filter)
+		}
+	}
+}
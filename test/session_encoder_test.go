@@ -0,0 +1,107 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// latencyMetrics records the encoder start latency gauges a pipeline reports
+type latencyMetrics struct {
+	mu     sync.Mutex
+	reused []string
+}
+
+func (m *latencyMetrics) Counter(name string, value int64, tags map[string]string) {}
+
+func (m *latencyMetrics) Gauge(name string, value float64, tags map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if name == common.MetricEncoderStartLatency {
+		m.reused = append(m.reused, tags["reused"])
+	}
+}
+
+// playSessionTrack plays one short track through the session's encoder and
+// waits for it to stop
+func playSessionTrack(t *testing.T, session *common.SessionEncoder, metrics common.MetricSink) *common.AudioPipeline {
+	t.Helper()
+	pipeline := common.NewAudioPipeline(nil)
+	pipeline.SetSessionEncoder(session)
+	pipeline.SetMetricSink(metrics)
+	pipeline.SetStreamer(func(ctx context.Context, streamURL string) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	if err := pipeline.PlayStream("https://stream.example/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	if !waitFor(t, time.Second, func() bool { return !pipeline.IsPlaying() }) {
+		t.Fatal("track did not finish")
+	}
+	return pipeline
+}
+
+// TestSessionEncoderReusedAcrossTracks tests that same-format tracks share
+// one encoder and a format change replaces it
+func TestSessionEncoderReusedAcrossTracks(t *testing.T) {
+	session := common.NewSessionEncoder()
+	metrics := &latencyMetrics{}
+
+	first := playSessionTrack(t, session, metrics)
+	second := playSessionTrack(t, session, metrics)
+	if first.OpusEncoder() == nil || first.OpusEncoder() != second.OpusEncoder() {
+		t.Fatal("Expected the second track to reuse the first track's encoder")
+	}
+
+	if err := common.SetOpusApplication("voip"); err != nil {
+		t.Fatalf("SetOpusApplication failed: %v", err)
+	}
+	defer common.SetOpusApplication("")
+
+	third := playSessionTrack(t, session, metrics)
+	if third.OpusEncoder() == second.OpusEncoder() {
+		t.Error("Expected a new encoder after the format changed")
+	}
+
+	if created, reused := session.Stats(); created != 2 || reused != 1 {
+		t.Errorf("Expected 2 encoders created and 1 reuse, got %d and %d", created, reused)
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	want := []string{"false", "true", "false"}
+	if len(metrics.reused) != len(want) {
+		t.Fatalf("Expected %d latency samples, got %v", len(want), metrics.reused)
+	}
+	for i := range want {
+		if metrics.reused[i] != want[i] {
+			t.Errorf("Sample %d: expected reused=%s, got %s", i, want[i], metrics.reused[i])
+		}
+	}
+}
+
+// TestSessionEncoderNotSharedWhileBusy tests that overlapping tracks never
+// encode with the same encoder
+func TestSessionEncoderNotSharedWhileBusy(t *testing.T) {
+	session := common.NewSessionEncoder()
+
+	busy := common.NewAudioPipeline(nil)
+	busy.SetSessionEncoder(session)
+	busy.SetStreamer(func(ctx context.Context, streamURL string) error {
+		<-ctx.Done()
+		return nil
+	})
+	if err := busy.PlayStream("https://stream.example/busy"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	defer busy.Stop()
+
+	overlapping := playSessionTrack(t, session, nil)
+	if overlapping.OpusEncoder() == busy.OpusEncoder() {
+		t.Error("Expected a separate encoder while the session's is in use")
+	}
+}
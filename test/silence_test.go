@@ -0,0 +1,68 @@
+package test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// playSilentIntro plays a fake ffmpeg stream of silentFrames silent frames
+// then toneFrames of tone, and waits until every frame sent has arrived
+func playSilentIntro(t *testing.T, trim bool, silentFrames, toneFrames int) *common.AudioPipeline {
+	t.Helper()
+	dir := t.TempDir()
+	toneFile := filepath.Join(dir, "tone.pcm")
+	tone, _ := io.ReadAll(pcmSource(toneFrames))
+	if err := os.WriteFile(toneFile, tone, 0o644); err != nil {
+		t.Fatalf("Failed to write tone: %v", err)
+	}
+	fakeFFmpeg := filepath.Join(dir, "ffmpeg")
+	script := "#!/bin/sh\nhead -c " + strconv.Itoa(silentFrames*3840) + " /dev/zero\ncat " + toneFile + "\n"
+	if err := os.WriteFile(fakeFFmpeg, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+
+	vc := &discordgo.VoiceConnection{Ready: true, OpusSend: make(chan []byte, 100)}
+	player := common.NewAudioPipeline(vc)
+	player.SetFFmpegPath(fakeFFmpeg)
+	player.SetSilenceTrim(trim, common.DefaultSilenceThresholdDB)
+	if err := player.PlayStream("https://example.com/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	t.Cleanup(player.Stop)
+
+	waitForOutcome(t, player, 5*time.Second)
+	return player
+}
+
+// TestSilenceTrimStartsAtFirstSound tests that trimming drops a silent intro
+// without sending it, while Position still counts it so a later seek or
+// resumed session lands in the right place
+func TestSilenceTrimStartsAtFirstSound(t *testing.T) {
+	player := playSilentIntro(t, true, 50, 10)
+
+	if got := player.FramesDelivered(); got != 10 {
+		t.Errorf("Expected only the 10 tone frames sent, got %d", got)
+	}
+	if got := player.Position(); got != 1200*time.Millisecond {
+		t.Errorf("Expected the position to include the trimmed second, got %v", got)
+	}
+}
+
+// TestSilenceTrimOff tests that without trimming the silent intro is sent
+func TestSilenceTrimOff(t *testing.T) {
+	player := playSilentIntro(t, false, 50, 10)
+
+	if got := player.FramesDelivered(); got != 60 {
+		t.Errorf("Expected all 60 frames sent, got %d", got)
+	}
+	if got := player.Position(); got != 1200*time.Millisecond {
+		t.Errorf("Expected a position of 1.2s, got %v", got)
+	}
+}
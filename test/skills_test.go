@@ -0,0 +1,123 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestSkillIconURL tests mapping icon IDs to Gametora image URLs
+func TestSkillIconURL(t *testing.T) {
+	if got := uma.SkillIconURL(10011); got != "https://gametora.com/images/umamusume/skill_icons/utx_ico_skill_10011.png" {
+		t.Errorf("Unexpected icon URL: %s", got)
+	}
+	if got := uma.SkillIconURL(0); got != "" {
+		t.Errorf("Expected no URL for a missing icon, got %s", got)
+	}
+
+	skill := uma.Skill{Name: "Corner Adept", IconID: 20013}
+	if skill.IconURL() != uma.SkillIconURL(20013) {
+		t.Errorf("Expected Skill.IconURL to match SkillIconURL, got %s", skill.IconURL())
+	}
+}
+
+// TestGroupSkillsByType tests grouping by primary type, with untyped skills last
+func TestGroupSkillsByType(t *testing.T) {
+	skills := []uma.Skill{
+		{ID: 1, Name: "Straightaway Adept", Types: []string{"sp", "l_1"}},
+		{ID: 2, Name: "Mysterious Skill"},
+		{ID: 3, Name: "Corner Recovery", Types: []string{"re"}},
+		{ID: 4, Name: "Corner Adept", Types: []string{"sp"}},
+		{ID: 5, Name: "Breath of Fresh Air", Types: []string{"re"}},
+	}
+
+	groups := uma.GroupSkillsByType(skills)
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(groups))
+	}
+
+	expected := []struct {
+		skillType string
+		ids       []int
+	}{
+		{"re", []int{3, 5}},
+		{"sp", []int{1, 4}},
+		{"other", []int{2}},
+	}
+	for i, want := range expected {
+		group := groups[i]
+		if group.Type != want.skillType {
+			t.Errorf("Group %d: expected type %s, got %s", i, want.skillType, group.Type)
+		}
+		if len(group.Skills) != len(want.ids) {
+			t.Fatalf("Group %s: expected %d skills, got %d", group.Type, len(want.ids), len(group.Skills))
+		}
+		for j, id := range want.ids {
+			if group.Skills[j].ID != id {
+				t.Errorf("Group %s skill %d: expected ID %d, got %d", group.Type, j, id, group.Skills[j].ID)
+			}
+		}
+	}
+}
+
+// TestFormatSkillGroups tests rendering groups with icon links and the length cap
+func TestFormatSkillGroups(t *testing.T) {
+	groups := uma.GroupSkillsByType([]uma.Skill{
+		{Name: "Corner Adept", Types: []string{"sp"}, IconID: 20013},
+		{Name: "Mysterious Skill"},
+	})
+
+	got := uma.FormatSkillGroups(groups, 0)
+	want := "**Speed** [Corner Adept](https://gametora.com/images/umamusume/skill_icons/utx_ico_skill_20013.png)\n**Other** Mysterious Skill"
+	if got != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+
+	var many []uma.Skill
+	for _, skillType := range []string{"a", "b", "c", "d", "e", "f"} {
+		many = append(many, uma.Skill{Name: strings.Repeat(skillType, 30), Types: []string{skillType}, IconID: 10011})
+	}
+	capped := uma.FormatSkillGroups(uma.GroupSkillsByType(many), 300)
+	if len(capped) > 300 {
+		t.Errorf("Expected at most 300 bytes, got %d", len(capped))
+	}
+	if !strings.HasSuffix(capped, "more") || !strings.Contains(capped, "**a**") {
+		t.Errorf("Expected the first group and an omitted count, got:\n%s", capped)
+	}
+}
+
+// TestFormatSkillGroupsTruncatesLongGroup tests that a group too long for the
+// limit is cut short rather than dropped
+func TestFormatSkillGroupsTruncatesLongGroup(t *testing.T) {
+	var skills []uma.Skill
+	for i := 0; i < 20; i++ {
+		skills = append(skills, uma.Skill{Name: "Straightaway Adept", Types: []string{"sp"}, IconID: 20013})
+	}
+	got := uma.FormatSkillGroups(uma.GroupSkillsByType(skills), 300)
+	if len(got) > 300 {
+		t.Errorf("Expected at most 300 bytes, got %d", len(got))
+	}
+	if !strings.HasPrefix(got, "**Speed** [Straightaway Adept]") || !strings.HasSuffix(got, "more") {
+		t.Errorf("Expected the group cut short with an omitted count, got:\n%s", got)
+	}
+
+	long := []uma.Skill{{Name: strings.Repeat("é", 200), Types: []string{"sp"}}}
+	got = uma.FormatSkillGroups(uma.GroupSkillsByType(long), 100)
+	if len(got) > 100 || !strings.HasPrefix(got, "**Speed** éé") || !strings.Contains(got, "…") {
+		t.Errorf("Expected the single skill name truncated, got %q", got)
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("Expected valid UTF-8 after truncation, got %q", got)
+	}
+}
+
+// TestSkillTypeName tests mapping type codes to display names
+func TestSkillTypeName(t *testing.T) {
+	for code, want := range map[string]string{"sp": "Speed", "re": "Recovery", "other": "Other", "xyz": "xyz"} {
+		if got := uma.SkillTypeName(code); got != want {
+			t.Errorf("SkillTypeName(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
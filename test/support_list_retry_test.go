@@ -0,0 +1,127 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// fastRetry keeps retry tests quick
+var fastRetry = uma.RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond, Jitter: 0.5}
+
+// supportListServer serves the support list, failing with status whenever fail returns true
+func supportListServer(t *testing.T, calls *int32, fail func(call int32) int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(calls, 1)
+		if status := fail(call); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode([]uma.SupportCard{{ID: 10001, CharaID: 1001}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestSupportCardListFresh tests that a successful fetch is served fresh
+func TestSupportCardListFresh(t *testing.T) {
+	var calls int32
+	server := supportListServer(t, &calls, func(int32) int { return 0 })
+	client := uma.NewClient(uma.WithBaseURL(server.URL), uma.WithSupportListRetry(fastRetry))
+
+	result := client.GetSupportCardList()
+	if !result.Found || len(result.SupportCards) != 1 {
+		t.Fatalf("expected one support card, got %+v", result)
+	}
+	if result.Stale {
+		t.Error("expected a fresh list")
+	}
+	if result.FetchedAt.IsZero() {
+		t.Error("expected FetchedAt to be set")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
+
+// TestSupportCardListRetriesThenSucceeds tests that transient failures are retried
+func TestSupportCardListRetriesThenSucceeds(t *testing.T) {
+	var calls int32
+	server := supportListServer(t, &calls, func(call int32) int {
+		if call < 3 {
+			return http.StatusBadGateway
+		}
+		return 0
+	})
+	client := uma.NewClient(uma.WithBaseURL(server.URL), uma.WithSupportListRetry(fastRetry))
+
+	result := client.GetSupportCardList()
+	if !result.Found || result.Stale {
+		t.Fatalf("expected a fresh list after retries, got %+v", result)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 requests, got %d", calls)
+	}
+}
+
+// TestSupportCardListDoesNotRetryClientErrors tests that 4xx responses fail immediately
+func TestSupportCardListDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := supportListServer(t, &calls, func(int32) int { return http.StatusNotFound })
+	client := uma.NewClient(uma.WithBaseURL(server.URL), uma.WithSupportListRetry(fastRetry))
+
+	result := client.GetSupportCardList()
+	if result.Found || result.Error == nil {
+		t.Fatalf("expected a failure, got %+v", result)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
+
+// TestSupportCardListFallsBackToStale tests that the last good list is served when a refresh fails
+func TestSupportCardListFallsBackToStale(t *testing.T) {
+	var calls int32
+	server := supportListServer(t, &calls, func(call int32) int {
+		if call > 1 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+	client := uma.NewClient(
+		uma.WithBaseURL(server.URL),
+		uma.WithSupportListRetry(fastRetry),
+		uma.WithCacheTTL(10*time.Millisecond),
+		uma.WithTTLJitter(0),
+	)
+
+	fresh := client.GetSupportCardList()
+	if !fresh.Found || fresh.Stale {
+		t.Fatalf("expected a fresh list, got %+v", fresh)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	stale := client.GetSupportCardList()
+	if !stale.Found || len(stale.SupportCards) != 1 {
+		t.Fatalf("expected the last good list, got %+v", stale)
+	}
+	if !stale.Stale {
+		t.Error("expected the list to be marked stale")
+	}
+	if !stale.FetchedAt.Equal(fresh.FetchedAt) {
+		t.Errorf("expected FetchedAt %v from the last good list, got %v", fresh.FetchedAt, stale.FetchedAt)
+	}
+	if calls != 4 {
+		t.Errorf("expected 1 fetch plus 3 failed tries, got %d requests", calls)
+	}
+	if fresh.Stale {
+		t.Error("serving a stale copy must not modify the fresh result")
+	}
+}
@@ -0,0 +1,166 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+	"github.com/latoulicious/HKTM/pkg/uma/navigation"
+)
+
+// makeListCards builds a mixed set of support cards for list tests
+func makeListCards() []*uma.SimplifiedSupportCard {
+	return []*uma.SimplifiedSupportCard{
+		{SupportID: 1, NameJp: "A", Rarity: 1, Type: "speed"},
+		{SupportID: 2, NameJp: "B", Rarity: 3, Type: "speed"},
+		{SupportID: 3, NameJp: "C", Rarity: 2, Type: "stamina"},
+		{SupportID: 4, NameJp: "D", Rarity: 3, Type: "intelligence"},
+		{SupportID: 5, NameJp: "E", Rarity: 3, Type: "speed"},
+	}
+}
+
+// TestParseSupportCardFilter tests type and rarity argument parsing
+func TestParseSupportCardFilter(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    uma.SupportCardFilter
+		wantErr bool
+	}{
+		{args: nil, want: uma.SupportCardFilter{}},
+		{args: []string{"speed"}, want: uma.SupportCardFilter{Type: "speed"}},
+		{args: []string{"SSR"}, want: uma.SupportCardFilter{Rarity: 3}},
+		{args: []string{"speed", "ssr"}, want: uma.SupportCardFilter{Type: "speed", Rarity: 3}},
+		{args: []string{"sr", "Stamina"}, want: uma.SupportCardFilter{Type: "stamina", Rarity: 2}},
+		{args: []string{"wit", "r"}, want: uma.SupportCardFilter{Type: "intelligence", Rarity: 1}},
+		{args: []string{"ur"}, wantErr: true},
+		{args: []string{"speed", "power"}, wantErr: true},
+		{args: []string{"ssr", "sr"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := uma.ParseSupportCardFilter(tt.args)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseSupportCardFilter(%v) expected error, got %+v", tt.args, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseSupportCardFilter(%v) unexpected error: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSupportCardFilter(%v) = %+v, want %+v", tt.args, got, tt.want)
+		}
+	}
+}
+
+// TestFilterSupportCards tests filtering and rarity ordering
+func TestFilterSupportCards(t *testing.T) {
+	cards := makeListCards()
+
+	all := uma.FilterSupportCards(cards, uma.SupportCardFilter{})
+	if len(all) != len(cards) {
+		t.Fatalf("Expected %d cards, got %d", len(cards), len(all))
+	}
+	// SSRs first, original order kept within a rarity
+	wantOrder := []string{"B", "D", "E", "C", "A"}
+	for i, card := range all {
+		if card.NameJp != wantOrder[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, wantOrder[i], card.NameJp)
+		}
+	}
+
+	speedSSR := uma.FilterSupportCards(cards, uma.SupportCardFilter{Type: "speed", Rarity: 3})
+	if len(speedSSR) != 2 || speedSSR[0].NameJp != "B" || speedSSR[1].NameJp != "E" {
+		t.Errorf("Unexpected speed SSR cards: %+v", speedSSR)
+	}
+
+	none := uma.FilterSupportCards(cards, uma.SupportCardFilter{Type: "guts"})
+	if len(none) != 0 {
+		t.Errorf("Expected no guts cards, got %d", len(none))
+	}
+}
+
+// TestPageSupportCards tests page slicing and clamping
+func TestPageSupportCards(t *testing.T) {
+	cards := makeListCards()
+
+	if pages := uma.PageCount(len(cards), 2); pages != 3 {
+		t.Errorf("Expected 3 pages, got %d", pages)
+	}
+	if pages := uma.PageCount(0, 2); pages != 1 {
+		t.Errorf("Expected 1 page for empty list, got %d", pages)
+	}
+
+	page, index := uma.PageSupportCards(cards, 1, 2)
+	if index != 1 || len(page) != 2 || page[0].SupportID != 3 {
+		t.Errorf("Unexpected page 1: index=%d cards=%+v", index, page)
+	}
+
+	page, index = uma.PageSupportCards(cards, 2, 2)
+	if index != 2 || len(page) != 1 || page[0].SupportID != 5 {
+		t.Errorf("Unexpected last page: index=%d cards=%+v", index, page)
+	}
+
+	page, index = uma.PageSupportCards(cards, 9, 2)
+	if index != 2 || len(page) != 1 {
+		t.Errorf("Expected out-of-range page to clamp to last, got index=%d", index)
+	}
+
+	page, index = uma.PageSupportCards(cards, -1, 2)
+	if index != 0 || len(page) != 2 {
+		t.Errorf("Expected negative page to clamp to first, got index=%d", index)
+	}
+
+	page, index = uma.PageSupportCards(nil, 0, 2)
+	if index != 0 || len(page) != 0 {
+		t.Errorf("Expected empty page for empty list, got index=%d len=%d", index, len(page))
+	}
+}
+
+// TestSupportCardListEmbed tests the list embed content
+func TestSupportCardListEmbed(t *testing.T) {
+	cards := uma.FilterSupportCards(makeListCards(), uma.SupportCardFilter{Type: "speed"})
+	manager := navigation.GetSupportCardListManager()
+
+	embed := manager.CreateListEmbed(cards, uma.SupportCardFilter{Type: "speed"}, 0, 2)
+	if !strings.Contains(embed.Title, "speed") {
+		t.Errorf("Expected filter in title, got %q", embed.Title)
+	}
+	if !strings.Contains(embed.Description, "**B** - SSR · speed") {
+		t.Errorf("Expected name, rarity and type per entry, got %q", embed.Description)
+	}
+	if !strings.Contains(embed.Footer.Text, "Page 1 of 2") {
+		t.Errorf("Expected page footer, got %q", embed.Footer.Text)
+	}
+
+	empty := manager.CreateListEmbed(nil, uma.SupportCardFilter{Type: "guts"}, 0, 2)
+	if !strings.Contains(empty.Description, "No support cards") {
+		t.Errorf("Expected empty message, got %q", empty.Description)
+	}
+}
+
+// TestSupportCardListExpires tests that a list stops being tracked once it expires
+func TestSupportCardListExpires(t *testing.T) {
+	manager := navigation.GetSupportCardListManager()
+	manager.SetListExpiry(20 * time.Millisecond)
+	defer manager.SetListExpiry(navigation.DefaultSupportCardListExpiry)
+
+	cards := makeListCards()
+	manager.RegisterSupportCardList("expiring-list", cards, uma.SupportCardFilter{}, 2, "channel")
+	if !manager.HasSupportCardList("expiring-list") {
+		t.Fatal("Expected the list to be active after registering")
+	}
+
+	if !waitFor(t, time.Second, func() bool { return !manager.HasSupportCardList("expiring-list") }) {
+		t.Error("Expected the list to be removed once it expired")
+	}
+
+	manager.RegisterSupportCardList("cleaned-list", cards, uma.SupportCardFilter{}, 2, "channel")
+	manager.CleanupSupportCardList("cleaned-list")
+	if manager.HasSupportCardList("cleaned-list") {
+		t.Error("Expected cleanup to remove the list")
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestSupportCardSearchPartialResults tests that a failed detail fetch for
+// one version is skipped and reported while the others are still returned
+func TestSupportCardSearchPartialResults(t *testing.T) {
+	cards := []uma.SupportCard{
+		{ID: 30001, CharaID: 1001, TitleEn: "Special Week", RarityString: "SSR"},
+		{ID: 20001, CharaID: 1001, TitleEn: "Special Week", RarityString: "SR"},
+		{ID: 10001, CharaID: 1001, TitleEn: "Special Week", RarityString: "R"},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/support":
+			json.NewEncoder(w).Encode(cards)
+		case "/v1/support/20001":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			for _, card := range cards {
+				if r.URL.Path == "/v1/support/"+strconv.Itoa(card.ID) {
+					json.NewEncoder(w).Encode(card)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := uma.NewClient(uma.WithBaseURL(server.URL), uma.WithSupportListRetry(fastRetry))
+	result := client.SearchSupportCard("Special Week")
+
+	if !result.Found {
+		t.Fatalf("expected the search to succeed, got error %v", result.Error)
+	}
+	if len(result.SupportCards) != 2 {
+		t.Fatalf("expected 2 versions, got %d", len(result.SupportCards))
+	}
+	if result.SkippedCount != 1 {
+		t.Errorf("expected 1 skipped version, got %d", result.SkippedCount)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "support card 20001") {
+		t.Errorf("expected the failure for card 20001 to be recorded, got %v", result.Errors)
+	}
+	for _, card := range result.SupportCards {
+		if card.ID == 20001 {
+			t.Error("expected the failed version to be left out")
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// playQueue plays every queued item through a pipeline that ends each
+// stream at once, publishing events the way the playback loop does
+func playQueue(t *testing.T, queue *common.MusicQueue, skipped map[string]bool) {
+	t.Helper()
+	for item := queue.Next(); item != nil; item = queue.Next() {
+		pipeline := playWith(t, func(ctx context.Context, streamURL string) error {
+			return nil
+		})
+		queue.PublishTrackStarted(item)
+		outcome := waitForOutcome(t, pipeline, 2*time.Second)
+		queue.PublishTrackEnded(item, outcome, skipped[item.Title])
+	}
+}
+
+func TestTrackEventsFireInOrder(t *testing.T) {
+	bus := common.NewTrackEventBus()
+	events, unsubscribe := bus.Subscribe(0)
+	defer unsubscribe()
+
+	queue := common.NewMusicQueue("events-guild")
+	queue.SetLoudnessAnalyzer(nil)
+	queue.SetTrackEvents(bus)
+	queue.AddWithYouTubeData("https://stream.example/a", "https://youtube.com/watch?v=aaa", "aaa", "First", "alice", 3*time.Minute)
+	queue.Add("https://stream.example/b", "Second", "bob")
+
+	playQueue(t, queue, map[string]bool{"Second": true})
+
+	want := []struct {
+		eventType common.TrackEventType
+		title     string
+	}{
+		{common.TrackStarted, "First"},
+		{common.TrackFinished, "First"},
+		{common.TrackStarted, "Second"},
+		{common.TrackSkipped, "Second"},
+	}
+
+	var got []common.TrackEvent
+	for range want {
+		select {
+		case event := <-events:
+			got = append(got, event)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected %d events, got %d", len(want), len(got))
+		}
+	}
+
+	for i, w := range want {
+		if got[i].Type != w.eventType || got[i].Title != w.title {
+			t.Errorf("Event %d: expected %s %q, got %s %q", i, w.eventType, w.title, got[i].Type, got[i].Title)
+		}
+		if got[i].GuildID != "events-guild" {
+			t.Errorf("Event %d: expected guild events-guild, got %q", i, got[i].GuildID)
+		}
+		if got[i].Timestamp.IsZero() || got[i].StartedAt.IsZero() {
+			t.Errorf("Event %d: expected timestamps, got %+v", i, got[i])
+		}
+		if i > 0 && got[i].Timestamp.Before(got[i-1].Timestamp) {
+			t.Errorf("Event %d is timestamped before the one preceding it", i)
+		}
+	}
+
+	first := got[0]
+	if first.URL != "https://youtube.com/watch?v=aaa" || first.VideoID != "aaa" || first.RequestedBy != "alice" || first.Duration != 3*time.Minute {
+		t.Errorf("Unexpected track metadata: %+v", first)
+	}
+	if got[1].Outcome.Reason != common.OutcomeCompleted {
+		t.Errorf("Expected the finished event to carry a completed outcome, got %s", got[1].Outcome)
+	}
+	if got[2].URL != "https://stream.example/b" || got[2].RequestedBy != "bob" {
+		t.Errorf("Unexpected metadata for a direct stream: %+v", got[2])
+	}
+}
+
+func TestTrackEventsNeverBlockOnSlowSubscribers(t *testing.T) {
+	bus := common.NewTrackEventBus()
+	_, unsubscribeSlow := bus.Subscribe(1) // never read
+	defer unsubscribeSlow()
+	events, unsubscribe := bus.Subscribe(10)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			bus.Publish(common.TrackEvent{Type: common.TrackStarted, Title: "Track"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+
+	if len(events) != 5 {
+		t.Errorf("Expected the reading subscriber to get 5 events, got %d", len(events))
+	}
+	if dropped := bus.Dropped(); dropped != 4 {
+		t.Errorf("Expected 4 dropped events for the slow subscriber, got %d", dropped)
+	}
+}
+
+func TestTrackEventsUnsubscribeClosesChannel(t *testing.T) {
+	bus := common.NewTrackEventBus()
+	events, unsubscribe := bus.Subscribe(1)
+	unsubscribe()
+	unsubscribe() // safe to call twice
+
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed")
+	}
+	bus.Publish(common.TrackEvent{Type: common.TrackStarted}) // no subscribers left
+}
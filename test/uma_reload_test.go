@@ -0,0 +1,79 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/latoulicious/HKTM/internal/config"
+	"github.com/latoulicious/HKTM/pkg/uma"
+)
+
+// TestGametoraReload tests that Reload drops cached data, picks up a new
+// build ID and reports how many cards the new supports list has
+func TestGametoraReload(t *testing.T) {
+	cfg := &config.Config{CronEnabled: false}
+	doer := &mockDoer{responses: map[string]string{
+		"/_next/data/OldBuild12345/umamusume/supports.json": `{"pageProps": {"supportData": [
+			{"url_name": "10010-kitasan-black", "support_id": 10010, "char_name": "Kitasan Black", "rarity": 1, "type": "speed"}
+		]}}`,
+	}}
+	client := uma.NewGametoraClient(cfg, uma.WithBuildID("OldBuild12345"), uma.WithHTTPDoer(doer))
+
+	cards, err := client.GetAllSupportCards()
+	if err != nil || len(cards) != 1 {
+		t.Fatalf("Expected 1 card before the update, got %d (%v)", len(cards), err)
+	}
+	if result := client.SearchSimplifiedSupportCard("Satono Diamond"); result.Found {
+		t.Fatal("Expected no Satono Diamond before the update")
+	}
+
+	// A game update ships a new build with more cards
+	doer.responses["/umamusume/supports"] = `<script src="/_next/data/NewBuild67890/umamusume/supports.json"></script>`
+	doer.responses["/_next/data/NewBuild67890/umamusume/supports.json"] = `{"pageProps": {"supportData": [
+		{"url_name": "10010-kitasan-black", "support_id": 10010, "char_name": "Kitasan Black", "rarity": 1, "type": "speed"},
+		{"url_name": "30028-kitasan-black", "support_id": 30028, "char_name": "Kitasan Black", "rarity": 3, "type": "speed"},
+		{"url_name": "30029-satono-diamond", "support_id": 30029, "char_name": "Satono Diamond", "rarity": 3, "type": "stamina"}
+	]}}`
+
+	result, err := client.Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if result.BuildID != "NewBuild67890" || result.IsFallback {
+		t.Errorf("Expected the new build ID, got %q (fallback: %v)", result.BuildID, result.IsFallback)
+	}
+	if result.Cards != 3 {
+		t.Errorf("Expected 3 cards after reloading, got %d", result.Cards)
+	}
+	// The supports list and the failed search were both cached
+	if result.Invalidated != 2 {
+		t.Errorf("Expected 2 cache entries dropped, got %d", result.Invalidated)
+	}
+
+	// Searches cached before the reload see the new data
+	if search := client.SearchSimplifiedSupportCard("Satono Diamond"); !search.Found {
+		t.Error("Expected Satono Diamond after reloading")
+	}
+
+	// The reloaded list is cached again
+	requests := len(doer.requests)
+	if cards, _ := client.GetAllSupportCards(); len(cards) != 3 || len(doer.requests) != requests {
+		t.Errorf("Expected the reloaded list from cache, got %d cards and %d new requests", len(cards), len(doer.requests)-requests)
+	}
+}
+
+// TestGametoraReloadFailure tests that a failed reload still returns a
+// summary
+func TestGametoraReloadFailure(t *testing.T) {
+	cfg := &config.Config{CronEnabled: false}
+	client := uma.NewGametoraClient(cfg, uma.WithBuildID("OldBuild12345"), uma.WithHTTPDoer(&mockDoer{}))
+
+	client.GetAllSupportCards()
+
+	result, err := client.Reload()
+	if err == nil {
+		t.Fatal("Expected an error when Gametora is unreachable")
+	}
+	if result == nil || result.Cards != 0 || result.Invalidated != 0 {
+		t.Errorf("Expected no cards and nothing cached to drop, got %+v", result)
+	}
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/latoulicious/HKTM/pkg/common"
+)
+
+// fastJoinRetry keeps voice join retry tests quick
+var fastJoinRetry = common.VoiceJoinRetry{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+// TestJoinVoiceRetriesTransientErrors tests that a gateway hiccup is retried until the join succeeds
+func TestJoinVoiceRetriesTransientErrors(t *testing.T) {
+	want := &discordgo.VoiceConnection{GuildID: "test-guild"}
+	calls := 0
+	vc, err := common.JoinVoiceWithRetry(func() (*discordgo.VoiceConnection, error) {
+		calls++
+		if calls < 3 {
+			return nil, errors.New("websocket: close 1006")
+		}
+		return want, nil
+	}, fastJoinRetry)
+
+	if err != nil {
+		t.Fatalf("expected join to succeed, got %v", err)
+	}
+	if vc != want {
+		t.Errorf("expected the joined connection to be returned")
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
+
+// TestJoinVoiceGivesUp tests that persistent failures stop after MaxAttempts
+func TestJoinVoiceGivesUp(t *testing.T) {
+	gatewayErr := errors.New("voice connection timed out")
+	calls := 0
+	_, err := common.JoinVoiceWithRetry(func() (*discordgo.VoiceConnection, error) {
+		calls++
+		return nil, gatewayErr
+	}, fastJoinRetry)
+
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if !errors.Is(err, gatewayErr) || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("expected a wrapped transient error, got %v", err)
+	}
+}
+
+// TestJoinVoiceUserNotInChannel tests that a user error is not retried
+func TestJoinVoiceUserNotInChannel(t *testing.T) {
+	calls := 0
+	_, err := common.JoinVoiceWithRetry(func() (*discordgo.VoiceConnection, error) {
+		calls++
+		return nil, common.ErrNotInVoiceChannel
+	}, fastJoinRetry)
+
+	if calls != 1 {
+		t.Errorf("expected a single attempt, got %d", calls)
+	}
+	if err != common.ErrNotInVoiceChannel {
+		t.Errorf("expected ErrNotInVoiceChannel unchanged, got %v", err)
+	}
+}
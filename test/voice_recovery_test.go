@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/latoulicious/HKTM/pkg/common"
+	"github.com/latoulicious/HKTM/pkg/pipeline"
+)
+
+// playerVoiceSession is a pipeline.VoiceSession that drives a player the
+// way the bot's rejoin does, recording each step
+type playerVoiceSession struct {
+	player *common.AudioPipeline
+
+	mu      sync.Mutex
+	calls   []string
+	resumed time.Duration
+}
+
+func (s *playerVoiceSession) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *playerVoiceSession) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *playerVoiceSession) Position() time.Duration { return s.player.Position() }
+
+func (s *playerVoiceSession) Disconnect() error {
+	s.record("disconnect")
+	return s.player.Pause()
+}
+
+func (s *playerVoiceSession) Join(ctx context.Context) error {
+	s.record("join")
+	return nil
+}
+
+func (s *playerVoiceSession) ResumeAt(position time.Duration) error {
+	s.record("resume")
+	s.mu.Lock()
+	s.resumed = position
+	s.mu.Unlock()
+	if err := s.player.Seek(position); err != nil {
+		return err
+	}
+	return s.player.Resume()
+}
+
+// playWithVoiceSession starts a pipeline that plays through the streamer
+// made for it and reconnects through a playerVoiceSession, unless
+// withSession is false
+func playWithVoiceSession(t *testing.T, withSession bool, streamer func(*common.AudioPipeline) common.Streamer) (*common.AudioPipeline, *playerVoiceSession) {
+	t.Helper()
+	config := pipeline.DefaultPipelineConfig()
+	config.Recovery.Backoff.InitialDelay = 10 * time.Millisecond
+	common.SetStreamConfig(config)
+	t.Cleanup(func() { common.SetStreamConfig(nil) })
+
+	player := common.NewAudioPipeline(nil)
+	session := &playerVoiceSession{player: player}
+	if withSession {
+		player.SetVoiceSession(session)
+	}
+	player.SetStreamer(streamer(player))
+	if err := player.PlayStream("https://stream.example/track"); err != nil {
+		t.Fatalf("PlayStream failed: %v", err)
+	}
+	t.Cleanup(player.Stop)
+	return player, session
+}
+
+// TestVoiceSessionInvalidReconnects tests that a 4006 close reconnects voice
+// and resumes the track where it was instead of restarting it
+func TestVoiceSessionInvalidReconnects(t *testing.T) {
+	var calls int32
+	player, session := playWithVoiceSession(t, true, func(player *common.AudioPipeline) common.Streamer {
+		return func(ctx context.Context, streamURL string) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				for i := 0; i < 50; i++ {
+					player.RecordFrame()
+				}
+				return &pipeline.VoiceCloseError{Code: pipeline.VoiceCloseSessionNoLongerValid, Reason: "Session is no longer valid"}
+			}
+			return nil
+		}
+	})
+
+	outcome := waitForOutcome(t, player, 2*time.Second)
+	if outcome.Reason != common.OutcomeCompleted || outcome.Recoveries != 1 {
+		t.Fatalf("Expected completion after 1 recovery, got %s after %d (%v)", outcome.Reason, outcome.Recoveries, outcome.Err)
+	}
+
+	got := session.Calls()
+	if len(got) != 3 || got[0] != "disconnect" || got[1] != "join" || got[2] != "resume" {
+		t.Errorf("Expected disconnect, join and resume, got %v", got)
+	}
+	if session.resumed != time.Second {
+		t.Errorf("Expected to resume at 1s, got %v", session.resumed)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("Expected the stream to restart once, got %d starts", calls)
+	}
+}
+
+// TestVoiceCloseEndsPlayback tests that voice closes a reconnect can't
+// recover from end playback instead of restarting the stream
+func TestVoiceCloseEndsPlayback(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        int
+		withSession bool
+	}{
+		{"disconnected", pipeline.VoiceCloseDisconnected, true},
+		{"no session", pipeline.VoiceCloseSessionNoLongerValid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			player, session := playWithVoiceSession(t, tt.withSession, func(*common.AudioPipeline) common.Streamer {
+				return func(ctx context.Context, streamURL string) error {
+					atomic.AddInt32(&calls, 1)
+					return &pipeline.VoiceCloseError{Code: tt.code}
+				}
+			})
+
+			outcome := waitForOutcome(t, player, 2*time.Second)
+			if outcome.Reason != common.OutcomeError || outcome.Recoveries != 0 {
+				t.Errorf("Expected an error without recoveries, got %s after %d", outcome.Reason, outcome.Recoveries)
+			}
+			if got := session.Calls(); len(got) != 0 {
+				t.Errorf("Expected no reconnect, got %v", got)
+			}
+			if atomic.LoadInt32(&calls) != 1 {
+				t.Errorf("Expected a single stream start, got %d", calls)
+			}
+		})
+	}
+}